@@ -592,9 +592,10 @@ func (a *Agent) writeCode(payload *InitPayload) error {
 	var filename string
 	switch payload.Runtime {
 	case "python3.11":
-		filename = "handler.py"
+		// 解释型运行时写入 handler 指定的入口文件（如 src/main.handler -> src/main.py）
+		filename = handlerEntryFile(payload.Handler) + ".py"
 	case "nodejs20":
-		filename = "handler.js"
+		filename = handlerEntryFile(payload.Handler) + ".js"
 	case "go1.24":
 		filename = "handler.go"
 	case "wasm":
@@ -604,9 +605,26 @@ func (a *Agent) writeCode(payload *InitPayload) error {
 	}
 
 	path := filepath.Join(FunctionDir, filename)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
 	return os.WriteFile(path, []byte(payload.Code), 0644)
 }
 
+// handlerEntryFile 返回处理函数入口点中的入口文件路径（不含扩展名）。
+// 入口点格式为 "[目录/]文件.函数名"，未指定文件或路径不合法时为 "handler"。
+func handlerEntryFile(handler string) string {
+	i := strings.LastIndex(handler, ".")
+	if i <= 0 {
+		return "handler"
+	}
+	file := handler[:i]
+	if filepath.IsAbs(file) || strings.Contains(file, "..") {
+		return "handler"
+	}
+	return file
+}
+
 // setupLayers 处理函数层的解压和环境配置
 // 将层内容解压到 LayersDir，并设置相应的环境变量
 //
@@ -796,14 +814,15 @@ import sys
 import json
 sys.path.insert(0, '%s')
 
-# 导入处理函数
+# 导入处理函数，入口点格式为 [目录/]文件.函数名
+import importlib
 parts = '%s'.rsplit('.', 1)
 if len(parts) == 2:
     module_name, func_name = parts
 else:
     module_name, func_name = 'handler', parts[0]
 
-module = __import__(module_name)
+module = importlib.import_module(module_name.replace('/', '.'))
 handler = getattr(module, func_name)
 
 # 从标准输入读取输入数据
@@ -980,10 +999,11 @@ func (r *NodeRuntime) Init(config *InitPayload) error {
 const fs = require('fs');
 const path = require('path');
 
-// 加载处理函数
-const parts = '%s'.split('.');
-const modulePath = path.join('%s', parts[0] + '.js');
-const handlerName = parts[1] || 'handler';
+// 加载处理函数，入口点格式为 [目录/]文件.函数名
+const handlerPath = '%s';
+const dot = handlerPath.lastIndexOf('.');
+const modulePath = path.join('%s', (dot > 0 ? handlerPath.slice(0, dot) : 'handler') + '.js');
+const handlerName = dot > 0 ? handlerPath.slice(dot + 1) : handlerPath;
 const handler = require(modulePath)[handlerName];
 
 // 从标准输入读取输入数据
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"github.com/oriys/nimbus/internal/config"
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// billingRates 将配置中的计费单价转换为计费汇总使用的单价。
+func billingRates(cfg config.BillingConfig) domain.BillingRates {
+	return domain.BillingRates{
+		PricePerGBSecond: cfg.PricePerGBSecond,
+		PricePerRequest:  cfg.PricePerRequest,
+		Currency:         cfg.Currency,
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"github.com/oriys/nimbus/internal/config"
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// platformLimits 根据运行模式从配置中取出平台级限制，用于展示函数的生效限制。
+// Docker 模式使用容器池的 tmpfs 大小和最大调用次数，Firecracker 模式不提供临时存储。
+func platformLimits(cfg *config.Config) domain.PlatformLimits {
+	if cfg.Runtime.Mode == "docker" {
+		return domain.PlatformLimits{
+			EphemeralStorageMB: cfg.Docker.Pool.TmpfsSizeMB,
+			MaxReuse:           cfg.Docker.Pool.MaxInvocations,
+		}
+	}
+	return domain.PlatformLimits{MaxReuse: cfg.Pool.MaxInvocations}
+}
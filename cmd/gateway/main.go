@@ -16,10 +16,13 @@ import (
 	"time"
 
 	"github.com/oriys/nimbus/internal/api"
+	"github.com/oriys/nimbus/internal/auth"
 	"github.com/oriys/nimbus/internal/config"
 	"github.com/oriys/nimbus/internal/docker"
 	"github.com/oriys/nimbus/internal/firecracker"
+	"github.com/oriys/nimbus/internal/leader"
 	"github.com/oriys/nimbus/internal/metrics"
+	"github.com/oriys/nimbus/internal/outbound"
 	"github.com/oriys/nimbus/internal/scheduler"
 	"github.com/oriys/nimbus/internal/storage"
 	"github.com/oriys/nimbus/internal/telemetry"
@@ -35,6 +38,8 @@ func main() {
 	// 解析命令行参数，获取配置文件路径
 	// 默认配置文件路径为 /etc/nimbus/config.yaml
 	configPath := flag.String("config", "/etc/nimbus/config.yaml", "Path to config file")
+	// 安全模式：崩溃循环后排查时禁用所有触发器，只保留管理 API
+	safeMode := flag.Bool("safe-mode", false, "Start with cron, workflow recovery and webhooks disabled")
 	flag.Parse()
 
 	// 设置日志记录器
@@ -58,6 +63,14 @@ func main() {
 
 	logger.WithField("mode", cfg.Runtime.Mode).Info("Starting Nimbus Gateway")
 
+	// 命令行参数与配置任一开启即进入安全模式
+	if *safeMode {
+		cfg.Runtime.SafeMode = true
+	}
+	if cfg.Runtime.SafeMode {
+		logSafeMode(logger)
+	}
+
 	// 初始化遥测系统 (OpenTelemetry)
 	// 遥测系统用于收集分布式追踪和指标数据
 	var tel *telemetry.Telemetry
@@ -147,6 +160,18 @@ func main() {
 		}()
 	}
 
+	// 单例后台任务通过 Redis 租约选举领导者，多副本部署时只在一个实例上运行
+	leaderCtx, leaderCancel := context.WithCancel(context.Background())
+	defer leaderCancel()
+	leaseTTL := cfg.Scheduler.LeaderLeaseTTL
+
+	// 启动函数延迟汇总任务，统计接口读取预汇总的延迟分位数
+	if cfg.Metrics.LatencyRollupInterval > 0 {
+		go leader.NewElector(redisStore, "latency-rollup", leaseTTL, logger).Run(leaderCtx, func(ctx context.Context) {
+			pgStore.RunLatencyRollup(ctx, cfg.Metrics.LatencyRollupInterval, logger)
+		})
+	}
+
 	// 初始化调度器和运行时管理器
 	// 根据配置选择使用 Docker 模式或 Firecracker 模式
 	var sched api.Scheduler
@@ -168,6 +193,7 @@ func main() {
 			logger.WithError(err).Fatal("Failed to initialize network manager")
 		}
 		defer networkMgr.Shutdown()
+		networkMgr.StartHealthCheck(cfg.Network.HealthCheckInterval)
 
 		// 初始化 Firecracker 虚拟机管理器
 		machinesMgr := firecracker.NewMachineManager(cfg.Firecracker, networkMgr, logger)
@@ -197,14 +223,30 @@ func main() {
 	if stopper, ok := sched.(interface{ Stop() error }); ok {
 		defer stopper.Stop()
 	}
+	// 熔断状态由所有实例共享，只有领导者恢复冷却结束的熔断函数
+	if recoverer, ok := sched.(interface{ RunCircuitRecovery(ctx context.Context) }); ok {
+		go leader.NewElector(redisStore, "circuit-recovery", leaseTTL, logger).Run(leaderCtx, recoverer.RunCircuitRecovery)
+	}
 
 	// 初始化定时任务管理器
-	// CronManager 负责处理函数的定时触发
-	cronMgr := scheduler.NewCronManager(pgStore, sched.InvokeAsync, logger)
-	if err := cronMgr.Start(); err != nil {
-		logger.WithError(err).Error("Failed to start cron manager")
+	// CronManager 负责处理函数的定时触发；安全模式下不启动
+	var cronMgr *scheduler.CronManager
+	if !cfg.Runtime.SafeMode {
+		cronMgr = scheduler.NewCronManager(pgStore, sched.InvokeAsync, logger)
+		// 只有领导者触发定时任务；成为领导者时重新加载，获取其他实例上修改的定时配置
+		cronElector := leader.NewElector(redisStore, "cron", leaseTTL, logger)
+		cronMgr.SetLeaderCheck(cronElector.IsLeader)
+		go cronElector.Run(leaderCtx, func(ctx context.Context) {
+			if err := cronMgr.ReloadAll(); err != nil {
+				logger.WithError(err).Error("Failed to reload cron tasks")
+			}
+			<-ctx.Done()
+		})
+		if err := cronMgr.Start(); err != nil {
+			logger.WithError(err).Error("Failed to start cron manager")
+		}
+		defer cronMgr.Stop()
 	}
-	defer cronMgr.Stop()
 
 	// 初始化工作流引擎
 	var workflowEngine *workflow.Engine
@@ -214,9 +256,13 @@ func main() {
 			Workers:          cfg.Workflow.Workers,
 			QueueSize:        cfg.Workflow.QueueSize,
 			DefaultTimeout:   cfg.Workflow.DefaultTimeout,
-			RecoveryEnabled:  cfg.Workflow.RecoveryEnabled,
+			RecoveryEnabled:  cfg.Workflow.RecoveryEnabled && !cfg.Runtime.SafeMode, // 安全模式下不恢复未完成的执行
 			RecoveryInterval: cfg.Workflow.RecoveryInterval,
 		}
+		// 只有领导者恢复未完成的执行，避免同一执行被多个实例重复恢复
+		recoveryElector := leader.NewElector(redisStore, "workflow-recovery", leaseTTL, logger)
+		workflowCfg.IsLeader = recoveryElector.IsLeader
+		go recoveryElector.Campaign(leaderCtx)
 		workflowEngine = workflow.NewEngine(workflowCfg, pgStore, sched, logger)
 		if err := workflowEngine.Start(); err != nil {
 			logger.WithError(err).Error("Failed to start workflow engine")
@@ -230,6 +276,26 @@ func main() {
 	// 初始化 API 处理器和路由
 	// 处理器包含所有 API 端点的业务逻辑
 	handler := api.NewHandler(pgStore, redisStore, sched, cronMgr, logger)
+	handler.SetBuildLimits(cfg.Build.MaxConcurrent, cfg.Build.RuntimeMaxConcurrent)
+	handler.SetDebugBuildLimit(cfg.Build.DebugMaxConcurrent)
+	handler.SetErrorStackTraces(cfg.Server.ErrorStackTraces, cfg.Server.StackTraceDepth)
+	handler.SetMaxPayloadBytes(cfg.Server.MaxPayloadBytes)
+	handler.SetAllowedOrigins(cfg.Server.CORSAllowedOrigins)
+	handler.SetCompileCache(cfg.Build.CacheTTL, cfg.Build.CacheMaxMB)
+	handler.SetHandlerCheckModes(cfg.Build.HandlerCheck)
+	handler.SetLayerLimits(cfg.Layers.MaxPerFunction, cfg.Layers.MaxTotalUnpackedMB)
+
+	// 出站通知客户端：投递结果指标仅在启用指标时上报
+	var outboundRecorder outbound.Recorder
+	if m != nil {
+		outboundRecorder = m
+	}
+	handler.SetOutboundClient(outbound.New(outbound.OptionsFromConfig(cfg.Outbound), outboundRecorder, logger))
+	handler.SetAdmissionWebhook(cfg.Admission.URL, cfg.Admission.Timeout, cfg.Admission.FailOpen)
+	handler.SetSafeMode(cfg.Runtime.SafeMode)
+	handler.SetRuntimePolicies(runtimePolicies(cfg.Environments))
+	handler.SetBillingRates(billingRates(cfg.Billing))
+	handler.SetPlatformLimits(platformLimits(cfg))
 
 	// 恢复未完成的编译任务
 	// 在服务重启时，检查并重新触发所有处于 creating/updating/building 状态的函数编译
@@ -238,11 +304,20 @@ func main() {
 	// 加载默认函数模板
 	api.SeedDefaultTemplates(pgStore, logger)
 
+	// 启用认证时，识别请求携带的 API Key 或 JWT，用于 API Key 的标签作用域授权
+	var authMiddleware *auth.Middleware
+	if cfg.Auth.Enabled {
+		jwtManager := auth.NewJWTManager(cfg.Auth.JWTSecret, cfg.Auth.JWTExpiration)
+		authMiddleware = auth.NewMiddleware(jwtManager, cfg.Auth.APIKeyHeader, auth.NewStoreKeyValidator(pgStore), true)
+		logger.Info("API authentication enabled")
+	}
+
 	router := api.NewRouter(&api.RouterConfig{
 		Handler:         handler,
 		WorkflowHandler: workflowHandler,
 		Logger:          logger,
 		WebFS:           nil, // 前端静态文件，可通过 embed 嵌入
+		Auth:            authMiddleware,
 	})
 
 	// 如果指标端口与主服务端口不同，单独启动指标服务器
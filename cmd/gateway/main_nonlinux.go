@@ -14,9 +14,12 @@ import (
 	"time"
 
 	"github.com/oriys/nimbus/internal/api"
+	"github.com/oriys/nimbus/internal/auth"
 	"github.com/oriys/nimbus/internal/config"
 	"github.com/oriys/nimbus/internal/docker"
+	"github.com/oriys/nimbus/internal/leader"
 	"github.com/oriys/nimbus/internal/metrics"
+	"github.com/oriys/nimbus/internal/outbound"
 	"github.com/oriys/nimbus/internal/scheduler"
 	"github.com/oriys/nimbus/internal/storage"
 	"github.com/oriys/nimbus/internal/workflow"
@@ -26,6 +29,7 @@ import (
 
 func main() {
 	configPath := flag.String("config", "/etc/nimbus/config.yaml", "Path to config file")
+	safeMode := flag.Bool("safe-mode", false, "Start with cron, workflow recovery and webhooks disabled")
 	flag.Parse()
 
 	// Setup logger
@@ -43,6 +47,13 @@ func main() {
 		logger.SetLevel(logrus.DebugLevel)
 	}
 
+	if *safeMode {
+		cfg.Runtime.SafeMode = true
+	}
+	if cfg.Runtime.SafeMode {
+		logSafeMode(logger)
+	}
+
 	if cfg.Runtime.Mode != "docker" {
 		logger.WithField("mode", cfg.Runtime.Mode).Fatal("Firecracker mode is only supported on Linux; set runtime.mode=docker")
 	}
@@ -99,6 +110,18 @@ func main() {
 		}()
 	}
 
+	// 单例后台任务通过 Redis 租约选举领导者，多副本部署时只在一个实例上运行
+	leaderCtx, leaderCancel := context.WithCancel(context.Background())
+	defer leaderCancel()
+	leaseTTL := cfg.Scheduler.LeaderLeaseTTL
+
+	// 启动函数延迟汇总任务，统计接口读取预汇总的延迟分位数
+	if cfg.Metrics.LatencyRollupInterval > 0 {
+		go leader.NewElector(redisStore, "latency-rollup", leaseTTL, logger).Run(leaderCtx, func(ctx context.Context) {
+			pgStore.RunLatencyRollup(ctx, cfg.Metrics.LatencyRollupInterval, logger)
+		})
+	}
+
 	// Docker mode - simpler setup, no KVM required
 	dockerMgr := docker.NewManager(cfg.Docker, m, logger)
 	sched := scheduler.NewDockerScheduler(cfg.Scheduler, pgStore, redisStore, dockerMgr, m, logger)
@@ -109,13 +132,27 @@ func main() {
 		logger.WithError(err).Fatal("Failed to start scheduler")
 	}
 	defer sched.Stop()
+	// 熔断状态由所有实例共享，只有领导者恢复冷却结束的熔断函数
+	go leader.NewElector(redisStore, "circuit-recovery", leaseTTL, logger).Run(leaderCtx, sched.RunCircuitRecovery)
 
-	// Initialize cron manager
-	cronMgr := scheduler.NewCronManager(pgStore, sched.InvokeAsync, logger)
-	if err := cronMgr.Start(); err != nil {
-		logger.WithError(err).Error("Failed to start cron manager")
+	// Initialize cron manager (disabled in safe mode)
+	var cronMgr *scheduler.CronManager
+	if !cfg.Runtime.SafeMode {
+		cronMgr = scheduler.NewCronManager(pgStore, sched.InvokeAsync, logger)
+		// 只有领导者触发定时任务；成为领导者时重新加载，获取其他实例上修改的定时配置
+		cronElector := leader.NewElector(redisStore, "cron", leaseTTL, logger)
+		cronMgr.SetLeaderCheck(cronElector.IsLeader)
+		go cronElector.Run(leaderCtx, func(ctx context.Context) {
+			if err := cronMgr.ReloadAll(); err != nil {
+				logger.WithError(err).Error("Failed to reload cron tasks")
+			}
+			<-ctx.Done()
+		})
+		if err := cronMgr.Start(); err != nil {
+			logger.WithError(err).Error("Failed to start cron manager")
+		}
+		defer cronMgr.Stop()
 	}
-	defer cronMgr.Stop()
 
 	// Initialize workflow engine
 	var workflowEngine *workflow.Engine
@@ -125,9 +162,13 @@ func main() {
 			Workers:          cfg.Workflow.Workers,
 			QueueSize:        cfg.Workflow.QueueSize,
 			DefaultTimeout:   cfg.Workflow.DefaultTimeout,
-			RecoveryEnabled:  cfg.Workflow.RecoveryEnabled,
+			RecoveryEnabled:  cfg.Workflow.RecoveryEnabled && !cfg.Runtime.SafeMode,
 			RecoveryInterval: cfg.Workflow.RecoveryInterval,
 		}
+		// 只有领导者恢复未完成的执行，避免同一执行被多个实例重复恢复
+		recoveryElector := leader.NewElector(redisStore, "workflow-recovery", leaseTTL, logger)
+		workflowCfg.IsLeader = recoveryElector.IsLeader
+		go recoveryElector.Campaign(leaderCtx)
 		workflowEngine = workflow.NewEngine(workflowCfg, pgStore, sched, logger)
 		if err := workflowEngine.Start(); err != nil {
 			logger.WithError(err).Error("Failed to start workflow engine")
@@ -140,6 +181,25 @@ func main() {
 
 	// Initialize API handler
 	handler := api.NewHandler(pgStore, redisStore, sched, cronMgr, logger)
+	handler.SetBuildLimits(cfg.Build.MaxConcurrent, cfg.Build.RuntimeMaxConcurrent)
+	handler.SetDebugBuildLimit(cfg.Build.DebugMaxConcurrent)
+	handler.SetErrorStackTraces(cfg.Server.ErrorStackTraces, cfg.Server.StackTraceDepth)
+	handler.SetMaxPayloadBytes(cfg.Server.MaxPayloadBytes)
+	handler.SetAllowedOrigins(cfg.Server.CORSAllowedOrigins)
+	handler.SetCompileCache(cfg.Build.CacheTTL, cfg.Build.CacheMaxMB)
+	handler.SetHandlerCheckModes(cfg.Build.HandlerCheck)
+
+	// 出站通知客户端：投递结果指标仅在启用指标时上报
+	var outboundRecorder outbound.Recorder
+	if m != nil {
+		outboundRecorder = m
+	}
+	handler.SetOutboundClient(outbound.New(outbound.OptionsFromConfig(cfg.Outbound), outboundRecorder, logger))
+	handler.SetAdmissionWebhook(cfg.Admission.URL, cfg.Admission.Timeout, cfg.Admission.FailOpen)
+	handler.SetSafeMode(cfg.Runtime.SafeMode)
+	handler.SetRuntimePolicies(runtimePolicies(cfg.Environments))
+	handler.SetBillingRates(billingRates(cfg.Billing))
+	handler.SetPlatformLimits(platformLimits(cfg))
 
 	// 恢复未完成的编译任务
 	handler.RecoverPendingCompileTasks()
@@ -147,11 +207,20 @@ func main() {
 	// 加载默认函数模板
 	api.SeedDefaultTemplates(pgStore, logger)
 
+	// 启用认证时，识别请求携带的 API Key 或 JWT，用于 API Key 的标签作用域授权
+	var authMiddleware *auth.Middleware
+	if cfg.Auth.Enabled {
+		jwtManager := auth.NewJWTManager(cfg.Auth.JWTSecret, cfg.Auth.JWTExpiration)
+		authMiddleware = auth.NewMiddleware(jwtManager, cfg.Auth.APIKeyHeader, auth.NewStoreKeyValidator(pgStore), true)
+		logger.Info("API authentication enabled")
+	}
+
 	router := api.NewRouter(&api.RouterConfig{
 		Handler:         handler,
 		WorkflowHandler: workflowHandler,
 		Logger:          logger,
 		WebFS:           nil, // 前端静态文件，可通过 embed 嵌入
+		Auth:            authMiddleware,
 	})
 
 	var metricsServer *http.Server
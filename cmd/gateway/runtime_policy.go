@@ -0,0 +1,22 @@
+package main
+
+import (
+	"github.com/oriys/nimbus/internal/config"
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// runtimePolicies 将配置中的环境策略转换为按环境名称索引的运行时准入策略。
+func runtimePolicies(envs map[string]config.EnvironmentPolicyConfig) map[string]domain.RuntimePolicy {
+	policies := make(map[string]domain.RuntimePolicy, len(envs))
+	for name, env := range envs {
+		var policy domain.RuntimePolicy
+		for _, rt := range env.AllowedRuntimes {
+			policy.AllowedRuntimes = append(policy.AllowedRuntimes, domain.Runtime(rt))
+		}
+		for _, rt := range env.BlockedRuntimes {
+			policy.BlockedRuntimes = append(policy.BlockedRuntimes, domain.Runtime(rt))
+		}
+		policies[name] = policy
+	}
+	return policies
+}
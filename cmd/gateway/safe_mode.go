@@ -0,0 +1,13 @@
+package main
+
+import "github.com/sirupsen/logrus"
+
+// logSafeMode 醒目地记录网关以安全模式启动。
+// 安全模式用于错误部署导致崩溃循环后的排查：定时任务不触发、未完成的工作流执行不恢复、
+// Webhook 返回 503，管理 API 照常可用，便于修复函数后再以正常模式重启。
+func logSafeMode(logger *logrus.Logger) {
+	logger.WithFields(logrus.Fields{
+		"safe_mode": true,
+		"disabled":  []string{"cron", "workflow_recovery", "webhooks"},
+	}).Warn("==================== SAFE MODE ACTIVE: triggers are disabled, only the control-plane API is serving ====================")
+}
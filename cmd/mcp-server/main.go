@@ -295,7 +295,7 @@ func newToolFunctionCreate() mcp.Tool {
 		mcp.WithDestructiveHintAnnotation(false),
 		mcp.WithString("name", mcp.Description("函数名，1-64 字符"), mcp.Required(), mcp.MinLength(1), mcp.MaxLength(64)),
 		mcp.WithString("description", mcp.Description("函数描述（可选）")),
-		mcp.WithString("runtime", mcp.Description("运行时"), mcp.Required(), mcp.Enum("python3.11", "nodejs20", "go1.24", "wasm")),
+		mcp.WithString("runtime", mcp.Description("运行时"), mcp.Required(), mcp.Enum("python3.11", "nodejs20", "bun1", "go1.24", "wasm")),
 		mcp.WithString("handler", mcp.Description("处理器入口，例如 handler.main / handler.handler"), mcp.Required()),
 		mcp.WithString("code", mcp.Description("函数代码内容"), mcp.Required(), mcp.MinLength(1)),
 		mcp.WithNumber("memory_mb", mcp.Description("内存，128-3072"), mcp.Min(128), mcp.Max(3072), mcp.MultipleOf(1)),
@@ -376,7 +376,7 @@ func newToolFunctionCreateFromDescription() mcp.Tool {
 		mcp.WithDestructiveHintAnnotation(false),
 		mcp.WithString("description", mcp.Description("自然语言描述（会写入函数 description，并用于生成示例代码）"), mcp.Required(), mcp.MinLength(1)),
 		mcp.WithString("name", mcp.Description("函数名（可选；不填则自动生成），1-64 字符"), mcp.MinLength(1), mcp.MaxLength(64)),
-		mcp.WithString("runtime", mcp.Description("运行时（可选，默认 python3.11）"), mcp.Enum("python3.11", "nodejs20", "go1.24", "wasm")),
+		mcp.WithString("runtime", mcp.Description("运行时（可选，默认 python3.11）"), mcp.Enum("python3.11", "nodejs20", "bun1", "go1.24", "wasm")),
 		mcp.WithString("handler", mcp.Description("处理器入口（可选；不填则按运行时给默认值）")),
 		mcp.WithNumber("memory_mb", mcp.Description("内存，128-3072"), mcp.Min(128), mcp.Max(3072), mcp.MultipleOf(1)),
 		mcp.WithNumber("timeout_sec", mcp.Description("超时秒数，1-300"), mcp.Min(1), mcp.Max(300), mcp.MultipleOf(1)),
@@ -0,0 +1,201 @@
+// Package cmd 提供 nimbus 命令行工具的所有子命令实现。
+// 本文件实现 alias 命令，用于管理函数别名及其版本流量权重（金丝雀、蓝绿发布）。
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage function aliases",
+	Long: `Create, list, update, and delete function aliases.
+
+An alias routes traffic to one or more published versions by weight.
+Weights must sum to 100.
+
+Examples:
+  # Point "prod" at version 3
+  nimbus alias create hello prod --version 3
+
+  # Shift 10% of "prod" traffic to version 4
+  nimbus alias update hello prod --weights 3=90,4=10
+
+  # Invoke through the alias
+  nimbus invoke hello --alias prod --data '{"name": "World"}'`,
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:   "list <function>",
+	Short: "List aliases of a function",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runAliasList,
+}
+
+var aliasCreateCmd = &cobra.Command{
+	Use:   "create <function> <alias> (--version <n> | --weights <v=w,...>)",
+	Short: "Create an alias",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runAliasCreate,
+}
+
+var aliasUpdateCmd = &cobra.Command{
+	Use:   "update <function> <alias> [--version <n> | --weights <v=w,...>] [--description <text>]",
+	Short: "Update an alias",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runAliasUpdate,
+}
+
+var aliasDeleteCmd = &cobra.Command{
+	Use:   "delete <function> <alias>",
+	Short: "Delete an alias",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runAliasDelete,
+}
+
+var (
+	aliasVersion int    // 别名指向的单个版本（100% 流量）
+	aliasWeights string // 版本权重，格式为 "版本=权重,版本=权重"
+	aliasDesc    string // 别名描述
+)
+
+func init() {
+	rootCmd.AddCommand(aliasCmd)
+	aliasCmd.AddCommand(aliasListCmd)
+	aliasCmd.AddCommand(aliasCreateCmd)
+	aliasCmd.AddCommand(aliasUpdateCmd)
+	aliasCmd.AddCommand(aliasDeleteCmd)
+
+	for _, c := range []*cobra.Command{aliasCreateCmd, aliasUpdateCmd} {
+		c.Flags().IntVar(&aliasVersion, "version", 0, "Route 100% of traffic to this version")
+		c.Flags().StringVarP(&aliasWeights, "weights", "w", "", "Version weights, e.g. 3=90,4=10 (must sum to 100)")
+		c.Flags().StringVarP(&aliasDesc, "description", "d", "", "Alias description")
+	}
+}
+
+// parseAliasWeights 解析 --version 或 --weights 标志为路由配置。
+// 两者都未设置时 ok 为 false；设置了时会在本地校验权重，尽早返回错误。
+func parseAliasWeights(version int, weights string) (cfg AliasRoutingConfig, ok bool, err error) {
+	switch {
+	case version != 0 && weights != "":
+		return cfg, false, fmt.Errorf("--version and --weights cannot be used together")
+	case version != 0:
+		cfg.Weights = []AliasWeight{{Version: version, Weight: 100}}
+	case weights != "":
+		for _, part := range strings.Split(weights, ",") {
+			v, w, found := strings.Cut(strings.TrimSpace(part), "=")
+			if !found {
+				return cfg, false, fmt.Errorf("invalid weight %q: expected <version>=<weight>", part)
+			}
+			ver, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				return cfg, false, fmt.Errorf("invalid version in %q: %w", part, err)
+			}
+			weight, err := strconv.Atoi(strings.TrimSpace(w))
+			if err != nil {
+				return cfg, false, fmt.Errorf("invalid weight in %q: %w", part, err)
+			}
+			cfg.Weights = append(cfg.Weights, AliasWeight{Version: ver, Weight: weight})
+		}
+	default:
+		return cfg, false, nil
+	}
+	if err := ValidateRoutingWeights(cfg); err != nil {
+		return cfg, false, err
+	}
+	return cfg, true, nil
+}
+
+// formatAliasWeights 将路由配置格式化为 "v3=90%, v4=10%"。
+func formatAliasWeights(cfg AliasRoutingConfig) string {
+	parts := make([]string, 0, len(cfg.Weights))
+	for _, w := range cfg.Weights {
+		parts = append(parts, fmt.Sprintf("v%d=%d%%", w.Version, w.Weight))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func runAliasList(cmd *cobra.Command, args []string) error {
+	client := NewClient()
+	aliases, err := client.ListFunctionAliases(args[0])
+	if err != nil {
+		return err
+	}
+
+	if len(aliases) == 0 {
+		cmd.Println("No aliases found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tROUTING\tDESCRIPTION\tUPDATED")
+	for _, a := range aliases {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			a.Name, formatAliasWeights(a.RoutingConfig), a.Description, a.UpdatedAt.Format("2006-01-02 15:04:05"))
+	}
+	return w.Flush()
+}
+
+func runAliasCreate(cmd *cobra.Command, args []string) error {
+	cfg, ok, err := parseAliasWeights(aliasVersion, aliasWeights)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("either --version or --weights is required")
+	}
+
+	client := NewClient()
+	alias, err := client.CreateFunctionAlias(args[0], &CreateAliasRequest{
+		Name:          args[1],
+		Description:   aliasDesc,
+		RoutingConfig: cfg,
+	})
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("✅ Alias '%s' created (%s)\n", alias.Name, formatAliasWeights(alias.RoutingConfig))
+	return nil
+}
+
+func runAliasUpdate(cmd *cobra.Command, args []string) error {
+	cfg, ok, err := parseAliasWeights(aliasVersion, aliasWeights)
+	if err != nil {
+		return err
+	}
+
+	req := &UpdateAliasRequest{}
+	if ok {
+		req.RoutingConfig = &cfg
+	}
+	if cmd.Flags().Changed("description") {
+		req.Description = &aliasDesc
+	}
+	if req.RoutingConfig == nil && req.Description == nil {
+		return fmt.Errorf("nothing to update: specify --version, --weights, or --description")
+	}
+
+	client := NewClient()
+	alias, err := client.UpdateFunctionAlias(args[0], args[1], req)
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("✅ Alias '%s' updated (%s)\n", alias.Name, formatAliasWeights(alias.RoutingConfig))
+	return nil
+}
+
+func runAliasDelete(cmd *cobra.Command, args []string) error {
+	client := NewClient()
+	if err := client.DeleteFunctionAlias(args[0], args[1]); err != nil {
+		return err
+	}
+	cmd.Println("✅ Alias deleted.")
+	return nil
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestParseAliasWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int
+		weights string
+		want    int // 期望的权重条数，-1 表示期望错误
+	}{
+		{name: "none", want: 0},
+		{name: "single version", version: 3, want: 1},
+		{name: "canary split", weights: "3=90, 4=10", want: 2},
+		{name: "sum below 100", weights: "3=90,4=5", want: -1},
+		{name: "duplicate version", weights: "3=50,3=50", want: -1},
+		{name: "malformed", weights: "3:100", want: -1},
+		{name: "both flags", version: 3, weights: "3=100", want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, ok, err := parseAliasWeights(tt.version, tt.weights)
+			if tt.want < 0 {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", cfg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != (tt.want > 0) || len(cfg.Weights) != tt.want {
+				t.Errorf("got ok=%v weights=%+v, want %d weights", ok, cfg.Weights, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateFunctionAliasValidatesWeightsLocally(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	defer viper.Set("api_url", "")
+
+	_, err := NewClient().CreateFunctionAlias("hello", &CreateAliasRequest{
+		Name:          "prod",
+		RoutingConfig: AliasRoutingConfig{Weights: []AliasWeight{{Version: 1, Weight: 60}, {Version: 2, Weight: 30}}},
+	})
+	if err == nil {
+		t.Fatal("expected weight validation error")
+	}
+	if called {
+		t.Error("request should not be sent when weights are invalid")
+	}
+}
+
+func TestAliasList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/functions/hello/aliases" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"aliases": []map[string]interface{}{
+				{
+					"name":           "prod",
+					"routing_config": map[string]interface{}{"weights": []map[string]int{{"version": 3, "weight": 90}, {"version": 4, "weight": 10}}},
+					"updated_at":     "2026-01-26T00:00:00Z",
+				},
+			},
+			"total": 1,
+		})
+	}))
+	defer server.Close()
+
+	viper.Set("api_url", server.URL)
+	defer viper.Set("api_url", "")
+
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetArgs([]string{"alias", "list", "hello"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !contains(buf.String(), "prod") || !contains(buf.String(), "v3=90%, v4=10%") {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+}
@@ -4,6 +4,7 @@
 // Client 封装了所有与 API 服务器的交互逻辑，包括：
 //   - 函数的 CRUD 操作（创建、读取、更新、删除）
 //   - 函数调用（同步和异步）
+//   - 函数别名管理与按别名调用
 //   - 调用记录查询
 //   - 系统状态查询
 //   - 工作流管理与执行
@@ -20,6 +21,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -261,11 +263,12 @@ type QuotaUsage struct {
 
 // PoolStats 表示虚拟机池的统计信息。
 type PoolStats struct {
-	Runtime  string `json:"runtime"`
-	WarmVMs  int    `json:"warm_vms"`
-	BusyVMs  int    `json:"busy_vms"`
-	TotalVMs int    `json:"total_vms"`
-	MaxVMs   int    `json:"max_vms"`
+	Runtime    string `json:"runtime"`
+	WarmVMs    int    `json:"warm_vms"`
+	BusyVMs    int    `json:"busy_vms"`
+	TotalVMs   int    `json:"total_vms"`
+	MaxVMs     int    `json:"max_vms"`
+	PinnedWarm int    `json:"pinned_warm"`
 }
 
 // SystemStatus 表示系统整体状态信息。
@@ -429,7 +432,17 @@ func (c *Client) DeleteFunction(idOrName string) error {
 }
 
 func (c *Client) InvokeFunction(idOrName string, payload json.RawMessage) (*InvokeResponse, error) {
-	req, err := http.NewRequest("POST", c.baseURL+"/api/v1/functions/"+idOrName+"/invoke", bytes.NewReader(payload))
+	return c.invoke("/api/v1/functions/"+idOrName+"/invoke", payload)
+}
+
+// InvokeFunctionAlias 同步调用函数别名指向的版本，按别名的路由权重选择版本。
+func (c *Client) InvokeFunctionAlias(idOrName, alias string, payload json.RawMessage) (*InvokeResponse, error) {
+	return c.invoke("/api/v1/functions/"+idOrName+"/invoke?alias="+url.QueryEscape(alias), payload)
+}
+
+// invoke 发送同步调用请求。函数执行失败时服务端仍返回带 request_id 的调用结果，此时不视为请求错误。
+func (c *Client) invoke(path string, payload json.RawMessage) (*InvokeResponse, error) {
+	req, err := http.NewRequest("POST", c.baseURL+path, bytes.NewReader(payload))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -469,6 +482,15 @@ func (c *Client) InvokeFunctionAsync(idOrName string, payload json.RawMessage) (
 	return &resp, nil
 }
 
+// InvokeFunctionAliasAsync 异步调用函数别名指向的版本。
+func (c *Client) InvokeFunctionAliasAsync(idOrName, alias string, payload json.RawMessage) (*AsyncInvokeResponse, error) {
+	var resp AsyncInvokeResponse
+	if err := c.do("POST", "/api/v1/functions/"+idOrName+"/async?alias="+url.QueryEscape(alias), payload, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 func (c *Client) GetInvocation(id string) (*Invocation, error) {
 	var inv Invocation
 	if err := c.do("GET", "/api/v1/invocations/"+id, nil, &inv); err != nil {
@@ -491,6 +513,108 @@ func (c *Client) ListInvocations(functionID string, limit int) ([]Invocation, er
 	return result.Invocations, nil
 }
 
+// ====== 别名操作方法 ======
+
+// AliasWeight 表示别名路由到单个版本的流量权重（百分比）。
+type AliasWeight struct {
+	Version int `json:"version"`
+	Weight  int `json:"weight"`
+}
+
+// AliasRoutingConfig 表示别名的流量路由配置。
+type AliasRoutingConfig struct {
+	Weights []AliasWeight `json:"weights"`
+}
+
+// FunctionAlias 表示函数别名。
+type FunctionAlias struct {
+	ID            string             `json:"id"`
+	FunctionID    string             `json:"function_id"`
+	Name          string             `json:"name"`
+	Description   string             `json:"description,omitempty"`
+	RoutingConfig AliasRoutingConfig `json:"routing_config"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
+// CreateAliasRequest 表示创建别名的 API 请求体。
+type CreateAliasRequest struct {
+	Name          string             `json:"name"`
+	Description   string             `json:"description,omitempty"`
+	RoutingConfig AliasRoutingConfig `json:"routing_config"`
+}
+
+// UpdateAliasRequest 表示更新别名的 API 请求体，未设置的字段保持不变。
+type UpdateAliasRequest struct {
+	Description   *string             `json:"description,omitempty"`
+	RoutingConfig *AliasRoutingConfig `json:"routing_config,omitempty"`
+}
+
+// ValidateRoutingWeights 在发送请求前校验路由权重：至少一个版本，版本号为正且不重复，
+// 每个权重在 0-100 之间且总和为 100。服务端执行相同的校验，这里用于更快地给出错误。
+func ValidateRoutingWeights(cfg AliasRoutingConfig) error {
+	if len(cfg.Weights) == 0 {
+		return fmt.Errorf("routing config must contain at least one version")
+	}
+	seen := make(map[int]bool, len(cfg.Weights))
+	total := 0
+	for _, w := range cfg.Weights {
+		if w.Version <= 0 {
+			return fmt.Errorf("invalid version %d: must be positive", w.Version)
+		}
+		if seen[w.Version] {
+			return fmt.Errorf("version %d appears more than once", w.Version)
+		}
+		seen[w.Version] = true
+		if w.Weight < 0 || w.Weight > 100 {
+			return fmt.Errorf("invalid weight %d for version %d: must be between 0 and 100", w.Weight, w.Version)
+		}
+		total += w.Weight
+	}
+	if total != 100 {
+		return fmt.Errorf("routing weights must sum to 100, got %d", total)
+	}
+	return nil
+}
+
+func (c *Client) ListFunctionAliases(idOrName string) ([]FunctionAlias, error) {
+	var result struct {
+		Aliases []FunctionAlias `json:"aliases"`
+	}
+	if err := c.do("GET", "/api/v1/functions/"+idOrName+"/aliases", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Aliases, nil
+}
+
+func (c *Client) CreateFunctionAlias(idOrName string, req *CreateAliasRequest) (*FunctionAlias, error) {
+	if err := ValidateRoutingWeights(req.RoutingConfig); err != nil {
+		return nil, err
+	}
+	var alias FunctionAlias
+	if err := c.do("POST", "/api/v1/functions/"+idOrName+"/aliases", req, &alias); err != nil {
+		return nil, err
+	}
+	return &alias, nil
+}
+
+func (c *Client) UpdateFunctionAlias(idOrName, name string, req *UpdateAliasRequest) (*FunctionAlias, error) {
+	if req.RoutingConfig != nil {
+		if err := ValidateRoutingWeights(*req.RoutingConfig); err != nil {
+			return nil, err
+		}
+	}
+	var alias FunctionAlias
+	if err := c.do("PUT", "/api/v1/functions/"+idOrName+"/aliases/"+url.PathEscape(name), req, &alias); err != nil {
+		return nil, err
+	}
+	return &alias, nil
+}
+
+func (c *Client) DeleteFunctionAlias(idOrName, name string) error {
+	return c.do("DELETE", "/api/v1/functions/"+idOrName+"/aliases/"+url.PathEscape(name), nil, nil)
+}
+
 // ====== 工作流操作方法 ====== 
 
 func (c *Client) ListWorkflows() ([]Workflow, error) {
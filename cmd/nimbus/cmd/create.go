@@ -28,7 +28,7 @@ var createCmd = &cobra.Command{
 Examples:
   # Create from inline code
   nimbus create hello --runtime python3.11 --handler main.handler \
-    --code 'def handler(event): return {"message": "Hello"}'
+    --code 'def handler(event, context): return {"message": "Hello"}'
 
   # Create from file
   nimbus create hello --runtime python3.11 --handler main.handler --file handler.py
@@ -40,7 +40,10 @@ Examples:
   echo '{"name": "World"}' | nimbus invoke hello
 
   # Invoke asynchronously
-  nimbus invoke hello --data '{"name": "World"}' --async`,
+  nimbus invoke hello --data '{"name": "World"}' --async
+
+  # Invoke the version(s) an alias routes to
+  nimbus invoke hello --alias prod --data '{"name": "World"}'`,
 	Args: cobra.ExactArgs(1),
 	RunE: runInvoke,
 }
@@ -50,6 +53,7 @@ var (
 	invokeData  string // JSON 格式的调用参数
 	invokeFile  string // 包含 JSON 参数的文件路径
 	invokeAsync bool   // 是否使用异步调用模式
+	invokeAlias string // 按别名调用时的别名名称
 )
 
 // init 注册 invoke 命令并设置命令行标志。
@@ -59,6 +63,7 @@ func init() {
 	invokeCmd.Flags().StringVarP(&invokeData, "data", "d", "", "JSON payload")
 	invokeCmd.Flags().StringVarP(&invokeFile, "file", "f", "", "JSON payload file")
 	invokeCmd.Flags().BoolVarP(&invokeAsync, "async", "a", false, "Invoke asynchronously")
+	invokeCmd.Flags().StringVar(&invokeAlias, "alias", "", "Invoke through the named alias")
 }
 
 // runInvoke 是 invoke 命令的执行函数。
@@ -118,7 +123,13 @@ func runInvoke(cmd *cobra.Command, args []string) error {
 	printer := NewPrinter()
 
 	if invokeAsync {
-		resp, err := client.InvokeFunctionAsync(name, payload)
+		var resp *AsyncInvokeResponse
+		var err error
+		if invokeAlias != "" {
+			resp, err = client.InvokeFunctionAliasAsync(name, invokeAlias, payload)
+		} else {
+			resp, err = client.InvokeFunctionAsync(name, payload)
+		}
 		if err != nil {
 			return err
 		}
@@ -130,7 +141,13 @@ func runInvoke(cmd *cobra.Command, args []string) error {
 
 	// Synchronous invocation
 	start := time.Now()
-	resp, err := client.InvokeFunction(name, payload)
+	var resp *InvokeResponse
+	var err error
+	if invokeAlias != "" {
+		resp, err = client.InvokeFunctionAlias(name, invokeAlias, payload)
+	} else {
+		resp, err = client.InvokeFunction(name, payload)
+	}
 	if err != nil {
 		return err
 	}
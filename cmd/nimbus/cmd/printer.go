@@ -297,11 +297,12 @@ func (p *Printer) printStatusDetail(status *SystemStatus) error {
 	if len(status.PoolStats) > 0 {
 		fmt.Fprintln(p.writer, "\nVM Pool Stats:")
 		w := tabwriter.NewWriter(p.writer, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "RUNTIME\tWARM\tBUSY\tTOTAL\tMAX")
+		fmt.Fprintln(w, "RUNTIME\tWARM\tPINNED\tBUSY\tTOTAL\tMAX")
 		for _, ps := range status.PoolStats {
-			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n",
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\n",
 				ps.Runtime,
 				ps.WarmVMs,
+				ps.PinnedWarm,
 				ps.BusyVMs,
 				ps.TotalVMs,
 				ps.MaxVMs,
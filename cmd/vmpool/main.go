@@ -8,6 +8,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"os"
 	"os/signal"
@@ -57,6 +58,10 @@ func main() {
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to initialize network manager")
 	}
+	defer networkMgr.Shutdown()
+
+	// 启动网络健康检查：回收崩溃虚拟机遗留的 TAP 设备，网桥丢失时自动重建
+	networkMgr.StartHealthCheck(cfg.Network.HealthCheckInterval)
 
 	// 初始化 Firecracker 虚拟机管理器
 	// 虚拟机管理器封装了 Firecracker API，提供虚拟机创建、启动、停止等操作
@@ -75,7 +80,7 @@ func main() {
 
 	// 启动状态监控 HTTP 服务器
 	// 提供健康检查和统计信息接口
-	srv := startStatsServer(pool, logger)
+	srv := startStatsServer(pool, networkMgr, logger)
 
 	logger.Info("VM pool service started")
 
@@ -104,34 +109,46 @@ func main() {
 
 // startStatsServer 启动状态监控 HTTP 服务器
 // 提供以下端点：
-//   - /health: 健康检查端点
-//   - /stats: 虚拟机池统计信息
+//   - /health: 健康检查端点，网桥异常时返回 503
+//   - /stats: 虚拟机池和网络资源统计信息
 //
 // 参数:
 //   - pool: 虚拟机池实例
+//   - networkMgr: 网络管理器，提供网桥健康状态和 IP/TAP 使用情况
 //   - logger: 日志记录器
 //
 // 返回:
 //   - *http.Server: HTTP 服务器实例，用于后续关闭
-func startStatsServer(pool *vmpool.Pool, logger *logrus.Logger) *http.Server {
+func startStatsServer(pool *vmpool.Pool, networkMgr *firecracker.NetworkManager, logger *logrus.Logger) *http.Server {
 	// 使用 chi 路由器
 	r := chi.NewRouter()
 
 	// 健康检查端点
 	// Kubernetes 和负载均衡器使用此端点检测服务是否正常
+	// 网桥丢失且无法重建时虚拟机没有网络，返回 503 使负载均衡器摘除本实例
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status":"healthy"}`))
+		netStats := networkMgr.Stats()
+		status, code := "healthy", http.StatusOK
+		if !netStats.BridgeHealthy {
+			status, code = "unhealthy", http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  status,
+			"network": netStats,
+		})
 	})
 
 	// 统计信息端点
-	// 返回各运行时的虚拟机池状态
+	// 返回各运行时的虚拟机池状态，以及网络 IP/TAP 的使用情况
 	r.Get("/stats", func(w http.ResponseWriter, r *http.Request) {
 		stats := pool.GetStats()
+		netStats, _ := json.Marshal(networkMgr.Stats())
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		// 简单的 JSON 编码
-		w.Write([]byte(`{"pool_stats":` + formatStats(stats) + `}`))
+		w.Write([]byte(`{"pool_stats":` + formatStats(stats) + `,"network":` + string(netStats) + `}`))
 	})
 
 	// 配置 HTTP 服务器
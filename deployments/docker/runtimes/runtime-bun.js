@@ -0,0 +1,127 @@
+#!/usr/bin/env bun
+/**
+ * Function runtime for Bun 1.x
+ * Reads function code and payload from stdin, executes, outputs result to stdout.
+ * The code may be JavaScript or TypeScript, as an ES module or CommonJS;
+ * it is written to a temporary .ts file and loaded with Bun's native transpiler.
+ */
+
+const fs = require('fs');
+const os = require('os');
+const path = require('path');
+
+// Progress frames are written to stderr and stripped by the executor
+const PROGRESS_FRAME_PREFIX = '__NIMBUS_PROGRESS__ ';
+
+function reportProgress(percent, message, data) {
+    const frame = { percent };
+    if (message) frame.message = message;
+    if (data !== undefined) frame.data = data;
+    process.stderr.write(PROGRESS_FRAME_PREFIX + JSON.stringify(frame) + '\n');
+}
+
+// Resolve an exported function from named exports or a CommonJS module.exports
+function resolveExport(mod, name) {
+    if (typeof mod[name] === 'function') return mod[name];
+    if (mod.default && typeof mod.default[name] === 'function') return mod.default[name];
+    return undefined;
+}
+
+// Extensions tried, in order, when locating a multi-file function's entry file
+const ENTRY_EXTENSIONS = ['.ts', '.js', '.mjs', '.cjs'];
+
+// Write a multi-file function to a working directory; returns the directory
+function writeFunctionFiles(files) {
+    const root = fs.mkdtempSync(path.join(os.tmpdir(), 'nimbus-fn-'));
+    for (const [relPath, content] of Object.entries(files)) {
+        const dest = path.resolve(root, relPath);
+        if (!dest.startsWith(root + path.sep)) {
+            throw new Error(`Invalid function file path '${relPath}'`);
+        }
+        fs.mkdirSync(path.dirname(dest), { recursive: true });
+        fs.writeFileSync(dest, content);
+    }
+    return root;
+}
+
+async function main() {
+    const input = await Bun.stdin.text();
+
+    try {
+        const data = JSON.parse(input);
+        const handlerPath = data.handler || 'handler';
+        const code = data.code || '';
+        const payload = data.payload || {};
+        const envVars = data.env || {};
+        const files = data.files || {};
+
+        // Set environment variables
+        Object.assign(process.env, envVars);
+
+        // Parse handler ([dir/]file.function format); the entry file defaults to "handler"
+        const dot = handlerPath.lastIndexOf('.');
+        const entryName = dot >= 0 ? handlerPath.slice(0, dot) : 'handler';
+        const funcName = dot >= 0 ? handlerPath.slice(dot + 1) : handlerPath;
+
+        // Load the code and resolve the handler
+        // Failures here mean the function could not even start (init_error)
+        let handler;
+        try {
+            let mod;
+            if (Object.keys(files).length > 0) {
+                // Multi-file functions import the entry file named by the handler
+                // (e.g. src/index.handler -> src/index.ts); relative imports resolve against it
+                const entry = ENTRY_EXTENSIONS.map(ext => entryName + ext).find(name => name in files);
+                if (!entry) {
+                    throw new Error(`Handler file '${entryName}' not found in function files`);
+                }
+                mod = await import(path.join(writeFunctionFiles(files), entry));
+            } else {
+                const file = path.join(os.tmpdir(), `nimbus-handler-${process.pid}-${Date.now()}.ts`);
+                fs.writeFileSync(file, code);
+                try {
+                    mod = await import(file);
+                } finally {
+                    fs.rmSync(file, { force: true });
+                }
+            }
+
+            handler = resolveExport(mod, funcName);
+            if (typeof handler !== 'function') {
+                throw new Error(`Handler function '${funcName}' not found or not a function`);
+            }
+        } catch (error) {
+            console.error(JSON.stringify({
+                error: error.message,
+                error_type: 'init_error',
+                stack: error.stack
+            }));
+            process.exit(1);
+        }
+
+        // Execute handler (support async)
+        const context = {
+            functionName: process.env.FUNCTION_NAME || 'unknown',
+            reportProgress,
+            // Milliseconds left before the invocation is killed (NIMBUS_DEADLINE_MS)
+            getRemainingTimeInMillis() {
+                const deadline = Number(process.env.NIMBUS_DEADLINE_MS);
+                if (!deadline) return Number(process.env.NIMBUS_TIMEOUT_MS) || 0;
+                return Math.max(0, deadline - Date.now());
+            },
+        };
+        const result = await handler(payload, context);
+
+        // Output result
+        console.log(JSON.stringify(result));
+
+    } catch (error) {
+        console.error(JSON.stringify({
+            error: error.message,
+            stack: error.stack
+        }));
+        process.exit(1);
+    }
+}
+
+main();
@@ -40,24 +40,24 @@ func main() {
 	// Decode binary
 	binary, err := base64.StdEncoding.DecodeString(input.Code)
 	if err != nil {
-		fatal("failed to decode binary: " + err.Error())
+		fatalInit("failed to decode binary: " + err.Error())
 	}
 
 	// Write binary to temp file
 	tmpFile, err := os.CreateTemp("", "handler-*")
 	if err != nil {
-		fatal("failed to create temp file: " + err.Error())
+		fatalInit("failed to create temp file: " + err.Error())
 	}
 	defer os.Remove(tmpFile.Name())
 
 	if _, err := tmpFile.Write(binary); err != nil {
-		fatal("failed to write binary: " + err.Error())
+		fatalInit("failed to write binary: " + err.Error())
 	}
 	tmpFile.Close()
 
 	// Make executable
 	if err := os.Chmod(tmpFile.Name(), 0755); err != nil {
-		fatal("failed to chmod: " + err.Error())
+		fatalInit("failed to chmod: " + err.Error())
 	}
 
 	// Execute
@@ -77,3 +77,9 @@ func fatal(msg string) {
 	fmt.Fprintf(os.Stderr, `{"error":%q}`, msg)
 	os.Exit(1)
 }
+
+// fatalInit reports a failure that happened before the handler could start.
+func fatalInit(msg string) {
+	fmt.Fprintf(os.Stderr, `{"error":%q,"error_type":"init_error"}`, msg)
+	os.Exit(1)
+}
@@ -4,7 +4,35 @@
  * Reads function code and payload from stdin, executes, outputs result to stdout.
  */
 
+const fs = require('fs');
+const os = require('os');
+const path = require('path');
 const vm = require('vm');
+const { createRequire } = require('module');
+
+// Progress frames are written to stderr and stripped by the executor
+const PROGRESS_FRAME_PREFIX = '__NIMBUS_PROGRESS__ ';
+
+function reportProgress(percent, message, data) {
+    const frame = { percent };
+    if (message) frame.message = message;
+    if (data !== undefined) frame.data = data;
+    process.stderr.write(PROGRESS_FRAME_PREFIX + JSON.stringify(frame) + '\n');
+}
+
+// Write a multi-file function to a working directory; returns the directory
+function writeFunctionFiles(files) {
+    const root = fs.mkdtempSync(path.join(os.tmpdir(), 'nimbus-fn-'));
+    for (const [relPath, content] of Object.entries(files)) {
+        const dest = path.resolve(root, relPath);
+        if (!dest.startsWith(root + path.sep)) {
+            throw new Error(`Invalid function file path '${relPath}'`);
+        }
+        fs.mkdirSync(path.dirname(dest), { recursive: true });
+        fs.writeFileSync(dest, content);
+    }
+    return root;
+}
 
 async function main() {
     let input = '';
@@ -20,13 +48,16 @@ async function main() {
         const code = data.code || '';
         const payload = data.payload || {};
         const envVars = data.env || {};
+        const initName = data.init_handler || '';
+        const files = data.files || {};
 
         // Set environment variables
         Object.assign(process.env, envVars);
 
-        // Parse handler
-        const parts = handlerPath.split('.');
-        const funcName = parts.length > 1 ? parts[parts.length - 1] : handlerPath;
+        // Parse handler ([dir/]file.function format); the entry file defaults to "handler"
+        const dot = handlerPath.lastIndexOf('.');
+        const entryName = dot >= 0 ? handlerPath.slice(0, dot) : 'handler';
+        const funcName = dot >= 0 ? handlerPath.slice(dot + 1) : handlerPath;
 
         // Create sandbox with module.exports
         const sandbox = {
@@ -43,18 +74,63 @@ async function main() {
             Promise: Promise,
         };
 
-        // Execute the code
-        vm.runInNewContext(code, sandbox);
+        // Execute the code and resolve the handler
+        // Failures here mean the function could not even start (init_error)
+        let handler;
+        let initResult;
+        try {
+            // Multi-file functions run the entry file named by the handler
+            // (e.g. src/index.handler -> src/index.js); relative requires resolve against it
+            let source = code;
+            if (Object.keys(files).length > 0) {
+                const entry = entryName + '.js';
+                if (!(entry in files)) {
+                    throw new Error(`Handler file '${entry}' not found in function files`);
+                }
+                const root = writeFunctionFiles(files);
+                sandbox.require = createRequire(path.join(root, entry));
+                source = files[entry];
+            }
+            vm.runInNewContext(source, sandbox);
 
-        // Get handler from module.exports or exports
-        let handler = sandbox.module.exports[funcName] || sandbox.exports[funcName] || sandbox[funcName];
+            // Get handler from module.exports or exports
+            handler = sandbox.module.exports[funcName] || sandbox.exports[funcName] || sandbox[funcName];
 
-        if (typeof handler !== 'function') {
-            throw new Error(`Handler function '${funcName}' not found or not a function`);
+            if (typeof handler !== 'function') {
+                throw new Error(`Handler function '${funcName}' not found or not a function`);
+            }
+
+            // Run the init handler once per process; its (awaited) return value
+            // is exposed to every handler call as context.init
+            if (initName) {
+                const init = sandbox.module.exports[initName] || sandbox.exports[initName] || sandbox[initName];
+                if (typeof init !== 'function') {
+                    throw new Error(`Init handler '${initName}' not found or not a function`);
+                }
+                initResult = await init();
+            }
+        } catch (error) {
+            console.error(JSON.stringify({
+                error: error.message,
+                error_type: 'init_error',
+                stack: error.stack
+            }));
+            process.exit(1);
         }
 
         // Execute handler (support async)
-        const result = await handler(payload);
+        const context = {
+            functionName: process.env.FUNCTION_NAME || 'unknown',
+            init: initResult,
+            reportProgress,
+            // Milliseconds left before the invocation is killed (NIMBUS_DEADLINE_MS)
+            getRemainingTimeInMillis() {
+                const deadline = Number(process.env.NIMBUS_DEADLINE_MS);
+                if (!deadline) return Number(process.env.NIMBUS_TIMEOUT_MS) || 0;
+                return Math.max(0, deadline - Date.now());
+            },
+        };
+        const result = await handler(payload, context);
 
         // Output result
         console.log(JSON.stringify(result));
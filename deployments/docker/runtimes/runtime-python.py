@@ -5,8 +5,36 @@ Reads function code and payload from stdin, executes, outputs result to stdout.
 """
 import sys
 import json
+import os
+import tempfile
+import time
 import traceback
 
+# Progress frames are written to stderr and stripped by the executor
+PROGRESS_FRAME_PREFIX = '__NIMBUS_PROGRESS__ '
+
+def report_progress(percent, message='', data=None):
+    """Report execution progress (0-100) for long-running invocations."""
+    frame = {"percent": percent}
+    if message:
+        frame["message"] = message
+    if data is not None:
+        frame["data"] = data
+    print(PROGRESS_FRAME_PREFIX + json.dumps(frame), file=sys.stderr, flush=True)
+
+def write_function_files(files):
+    """Write a multi-file function to a working directory and make it importable."""
+    root = tempfile.mkdtemp(prefix='nimbus-fn-')
+    for rel_path, content in files.items():
+        dest = os.path.normpath(os.path.join(root, rel_path))
+        if not dest.startswith(root + os.sep):
+            raise ValueError(f"Invalid function file path '{rel_path}'")
+        os.makedirs(os.path.dirname(dest), exist_ok=True)
+        with open(dest, 'w') as f:
+            f.write(content)
+    sys.path.insert(0, root)
+    return root
+
 def main():
     try:
         # Read input from stdin
@@ -16,27 +44,55 @@ def main():
         code = input_data.get('code', '')
         payload = input_data.get('payload', {})
         env_vars = input_data.get('env', {})
+        init_name = input_data.get('init_handler', '')
+        files = input_data.get('files') or {}
 
         # Set environment variables
-        import os
         for key, value in env_vars.items():
             os.environ[key] = value
 
-        # Parse handler (module.function format)
+        # Parse handler ([dir/]file.function format); the entry file defaults to "handler"
         if '.' in handler_path:
             module_name, func_name = handler_path.rsplit('.', 1)
         else:
             module_name, func_name = 'handler', handler_path
 
         # Create a namespace and execute the code
-        namespace = {}
-        exec(code, namespace)
+        # Failures here mean the function could not even start (init_error)
+        try:
+            # Multi-file functions run the entry file named by the handler
+            # (e.g. src/main.handler -> src/main.py); the other files are importable
+            namespace = {}
+            if files:
+                entry = module_name + '.py'
+                if entry not in files:
+                    raise ValueError(f"Handler file '{entry}' not found in function files")
+                root = write_function_files(files)
+                code = files[entry]
+                namespace['__file__'] = os.path.join(root, entry)
+            exec(code, namespace)
 
-        # Get the handler function
-        if func_name not in namespace:
-            raise ValueError(f"Handler function '{func_name}' not found in code")
+            # Get the handler function
+            if func_name not in namespace:
+                raise ValueError(f"Handler function '{func_name}' not found in code")
 
-        handler = namespace[func_name]
+            handler = namespace[func_name]
+
+            # Run the init handler once per process; its return value is
+            # exposed to every handler call as context.init
+            init_result = None
+            if init_name:
+                if init_name not in namespace:
+                    raise ValueError(f"Init handler '{init_name}' not found in code")
+                init_result = namespace[init_name]()
+        except Exception as e:
+            error_response = {
+                "error": str(e),
+                "error_type": "init_error",
+                "traceback": traceback.format_exc()
+            }
+            print(json.dumps(error_response), file=sys.stderr)
+            sys.exit(1)
 
         # Create a simple context object
         class Context:
@@ -47,6 +103,17 @@ def main():
                 self.aws_request_id = 'uuid-placeholder'
                 self.log_group_name = '/aws/lambda/' + self.function_name
                 self.log_stream_name = 'date/[$LATEST]uuid'
+                self.init = init_result
+
+            def report_progress(self, percent, message='', data=None):
+                report_progress(percent, message, data)
+
+            def get_remaining_time_in_millis(self):
+                """Milliseconds left before the invocation is killed (NIMBUS_DEADLINE_MS)."""
+                deadline = os.environ.get('NIMBUS_DEADLINE_MS')
+                if not deadline:
+                    return int(os.environ.get('NIMBUS_TIMEOUT_MS', '0'))
+                return max(0, int(deadline) - int(time.time() * 1000))
 
         # Execute the handler
         result = handler(payload, Context())
@@ -38,7 +38,7 @@ func main() {
 	// Decode wasm binary
 	wasmBytes, err := base64.StdEncoding.DecodeString(input.Code)
 	if err != nil {
-		fatal("failed to decode wasm: " + err.Error())
+		fatalInit("failed to decode wasm: " + err.Error())
 	}
 
 	// Execute wasm
@@ -115,5 +115,11 @@ func fatal(msg string) {
 	os.Exit(1)
 }
 
+// fatalInit reports a failure that happened before the handler could start.
+func fatalInit(msg string) {
+	fmt.Fprintf(os.Stderr, `{"error":%q,"error_type":"init_error"}`, msg)
+	os.Exit(1)
+}
+
 // Ensure api.Module is used (for compilation)
 var _ api.Module
@@ -147,6 +147,7 @@ if [ "$SKIP_IMAGES" = false ]; then
     # 构建所有运行时镜像
     build_image "function-runtime-python:latest" "Dockerfile.python3.11"
     build_image "function-runtime-nodejs:latest" "Dockerfile.nodejs20"
+    build_image "function-runtime-bun:latest" "Dockerfile.bun1"
     build_image "function-runtime-go:latest" "Dockerfile.go1.24"
     build_image "function-runtime-wasm:latest" "Dockerfile.wasm"
 
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/auth"
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// 准入校验的变更类型
+const (
+	admissionCreate = "create"
+	admissionUpdate = "update"
+)
+
+// maxAdmissionResponseSize 策略端点响应体的最大读取字节数
+const maxAdmissionResponseSize = 64 << 10
+
+// admissionWebhook 函数配置准入 Webhook 客户端。
+// 创建和更新函数在持久化之前将拟写入的配置发送到外部策略端点，由其决定放行或拒绝。
+type admissionWebhook struct {
+	url      string
+	failOpen bool // 策略端点不可用时是否放行
+	client   *http.Client
+}
+
+// admissionReview 发送给策略端点的请求体。
+type admissionReview struct {
+	Operation string           `json:"operation"`          // create 或 update
+	Function  *domain.Function `json:"function"`           // 拟写入的函数配置
+	Previous  *domain.Function `json:"previous,omitempty"` // 更新前的函数配置，仅 update 时提供
+	User      string           `json:"user,omitempty"`     // 发起变更的用户 ID
+}
+
+// admissionResult 策略端点的响应体。
+type admissionResult struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// SetAdmissionWebhook 设置函数配置准入 Webhook，url 为空时不启用。
+//
+// 参数：
+//   - url: 策略端点地址
+//   - timeout: 单次校验请求的超时时间
+//   - failOpen: 策略端点不可用时是否放行，false 表示拒绝变更
+func (h *Handler) SetAdmissionWebhook(url string, timeout time.Duration, failOpen bool) {
+	if url == "" {
+		h.admission = nil
+		return
+	}
+	h.admission = &admissionWebhook{
+		url:      url,
+		failOpen: failOpen,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// checkAdmission 在函数配置持久化之前调用准入 Webhook。
+// 未配置 Webhook 或策略放行时返回 true；策略拒绝时写入 400 响应并返回 false。
+// 策略端点不可用时按 fail_open 配置放行，或写入 503 响应并返回 false。
+func (h *Handler) checkAdmission(w http.ResponseWriter, r *http.Request, operation string, fn, previous *domain.Function) bool {
+	if h.admission == nil {
+		return true
+	}
+
+	review := &admissionReview{
+		Operation: operation,
+		Function:  admissionSnapshot(fn),
+		Previous:  admissionSnapshot(previous),
+	}
+	if user := auth.GetUser(r.Context()); user != nil {
+		review.User = user.UserID
+	}
+
+	result, err := h.admission.review(r.Context(), review)
+	if err != nil {
+		if h.admission.failOpen {
+			h.logWarn(r, "checkAdmission", "准入校验失败，按 fail-open 放行", logrus.Fields{
+				"function": fn.Name,
+				"error":    err.Error(),
+			})
+			return true
+		}
+		h.logError(r, "checkAdmission", "准入校验失败，拒绝变更", err, logrus.Fields{"function": fn.Name})
+		writeErrorWithContext(w, r, http.StatusServiceUnavailable, "admission webhook unavailable: "+err.Error())
+		return false
+	}
+	if !result.Allowed {
+		h.logWarn(r, "checkAdmission", "函数配置被策略拒绝", logrus.Fields{
+			"function":  fn.Name,
+			"operation": operation,
+			"reason":    result.Reason,
+		})
+		msg := "function config denied by policy"
+		if result.Reason != "" {
+			msg += ": " + result.Reason
+		}
+		writeErrorWithContext(w, r, http.StatusBadRequest, msg)
+		return false
+	}
+	return true
+}
+
+// review 将变更发送到策略端点并解析其决定。
+// 网络错误、非 2xx 响应和无法解析的响应体都视为策略端点不可用。
+func (a *admissionWebhook) review(ctx context.Context, review *admissionReview) (*admissionResult, error) {
+	body, err := json.Marshal(review)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAdmissionResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("policy endpoint returned status %d", resp.StatusCode)
+	}
+
+	var result admissionResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("invalid policy response: %w", err)
+	}
+	return &result, nil
+}
+
+// admissionSnapshot 返回发送给策略端点的函数配置副本。
+// 代码和编译产物不发送，环境变量只保留变量名，避免将密钥泄露给外部端点。
+func admissionSnapshot(fn *domain.Function) *domain.Function {
+	if fn == nil {
+		return nil
+	}
+	snapshot := *fn
+	snapshot.Code = ""
+	snapshot.Binary = ""
+	if fn.EnvVars != nil {
+		snapshot.EnvVars = make(map[string]string, len(fn.EnvVars))
+		for k := range fn.EnvVars {
+			snapshot.EnvVars[k] = ""
+		}
+	}
+	return &snapshot
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+func TestCheckAdmissionPolicyUnavailable(t *testing.T) {
+	// 已关闭的端点模拟策略服务不可用（连接被拒绝）
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	downURL := down.URL
+	down.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	tests := []struct {
+		name       string
+		url        string
+		failOpen   bool
+		wantAdmit  bool
+		wantStatus int
+	}{
+		{name: "unreachable fail-closed", url: downURL, wantStatus: http.StatusServiceUnavailable},
+		{name: "unreachable fail-open", url: downURL, failOpen: true, wantAdmit: true, wantStatus: http.StatusOK},
+		{name: "5xx fail-closed", url: failing.URL, wantStatus: http.StatusServiceUnavailable},
+		{name: "5xx fail-open", url: failing.URL, failOpen: true, wantAdmit: true, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{}
+			h.SetAdmissionWebhook(tt.url, time.Second, tt.failOpen)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/functions", nil)
+			admitted := h.checkAdmission(w, r, admissionCreate, &domain.Function{Name: "demo"}, nil)
+			if admitted != tt.wantAdmit {
+				t.Errorf("checkAdmission() = %v, want %v", admitted, tt.wantAdmit)
+			}
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestCheckAdmissionDecision(t *testing.T) {
+	var got admissionReview
+	policy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode review: %v", err)
+		}
+		if got.Function.Name == "denied" {
+			writeJSON(w, http.StatusOK, admissionResult{Allowed: false, Reason: "name not allowed"})
+			return
+		}
+		writeJSON(w, http.StatusOK, admissionResult{Allowed: true})
+	}))
+	defer policy.Close()
+
+	h := &Handler{}
+	h.SetAdmissionWebhook(policy.URL, time.Second, false)
+
+	// 放行，且发送给策略端点的配置不包含代码和环境变量值
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/functions", nil)
+	fn := &domain.Function{Name: "allowed", Code: "secret code", EnvVars: map[string]string{"TOKEN": "s3cret"}}
+	if !h.checkAdmission(w, r, admissionCreate, fn, nil) {
+		t.Fatalf("checkAdmission() = false, want true (status %d)", w.Code)
+	}
+	if got.Operation != admissionCreate || got.Function.Code != "" || got.Function.EnvVars["TOKEN"] != "" {
+		t.Errorf("review = %+v, want create without code or env values", got)
+	}
+	if _, ok := got.Function.EnvVars["TOKEN"]; !ok {
+		t.Error("review env vars should keep variable names")
+	}
+
+	// 拒绝时返回 400 并带上策略原因
+	w = httptest.NewRecorder()
+	if h.checkAdmission(w, r, admissionUpdate, &domain.Function{Name: "denied"}, fn) {
+		t.Fatal("checkAdmission() = true, want false for denied config")
+	}
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if got.Previous == nil || got.Previous.Name != "allowed" {
+		t.Errorf("review previous = %+v, want previous config on update", got.Previous)
+	}
+}
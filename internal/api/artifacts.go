@@ -0,0 +1,53 @@
+package api
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// GetInvocationArtifact 下载函数执行时写入 /output 目录的产物文件。
+// HTTP端点: GET /api/v1/invocations/{id}/artifacts/{name}
+//
+// {name} 是文件相对 /output 的路径，可包含子目录（如 thumbs/a.png）。
+// 响应以附件形式返回文件内容，Content-Type 为收集时推断的类型。
+//
+// 返回值：
+//   - 200: 成功，返回文件内容
+//   - 400: 产物名称无效
+//   - 404: 调用记录或产物不存在
+func (h *Handler) GetInvocationArtifact(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	name, err := url.PathUnescape(chi.URLParam(r, "*"))
+	if err != nil || !domain.ValidArtifactName(name) {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "invalid artifact name")
+		return
+	}
+
+	artifact, err := h.store.GetInvocationArtifact(id, name)
+	if err == domain.ErrArtifactNotFound {
+		writeErrorWithContext(w, r, http.StatusNotFound, "artifact not found: "+name)
+		return
+	}
+	if err != nil {
+		h.logError(r, "GetInvocationArtifact", "查询调用产物失败", err, logrus.Fields{"invocation_id": id, "name": name})
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to get artifact: "+err.Error())
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", artifact.ContentType)
+	header.Set("Content-Length", strconv.Itoa(len(artifact.Data)))
+	// 产物内容由函数生成，以附件形式下载，禁止浏览器嗅探后按 HTML 渲染
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(name)}))
+	header.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	w.Write(artifact.Data)
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// asyncResultPollInterval 长轮询期间重新读取调用记录的间隔
+const asyncResultPollInterval = 500 * time.Millisecond
+
+// GetAsyncResult 查询异步调用的结果。
+// HTTP端点: GET /api/v1/async-results/{request_id}
+//
+// request_id 为异步调用（POST /api/v1/functions/{id}/async）返回的请求 ID，结果来自调用记录。
+//
+// 查询参数：
+//   - wait: 长轮询时长（如 30s 或 30，最长 domain.MaxAsyncResultWait），调用结束或等待超时后返回，
+//     客户端断开时停止等待
+//
+// 返回值：
+//   - 200: 返回 domain.AsyncResult，等待超时时返回当前状态（pending 或 running）
+//   - 400: wait 参数无效
+//   - 404: 请求 ID 不存在
+func (h *Handler) GetAsyncResult(w http.ResponseWriter, r *http.Request) {
+	requestID := chi.URLParam(r, "request_id")
+	wait, err := domain.ParseAsyncResultWait(r.URL.Query().Get("wait"))
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	res, ok := h.loadAsyncResult(w, r, requestID)
+	if !ok {
+		return
+	}
+	if res.Done() || wait == 0 {
+		writeJSON(w, http.StatusOK, res)
+		return
+	}
+
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	ticker := time.NewTicker(asyncResultPollInterval)
+	defer ticker.Stop()
+	for !res.Done() {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-deadline.C:
+			writeJSON(w, http.StatusOK, res)
+			return
+		case <-ticker.C:
+		}
+		if res, ok = h.loadAsyncResult(w, r, requestID); !ok {
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// loadAsyncResult 读取调用记录并转换为异步调用结果，失败时写入错误响应并返回 false。
+func (h *Handler) loadAsyncResult(w http.ResponseWriter, r *http.Request, requestID string) (*domain.AsyncResult, bool) {
+	inv, err := h.store.GetInvocationByID(requestID)
+	if err == domain.ErrInvocationNotFound {
+		writeErrorWithContext(w, r, http.StatusNotFound, "async result not found")
+		return nil, false
+	}
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to get async result")
+		return nil, false
+	}
+	return domain.NewAsyncResult(inv), true
+}
@@ -5,6 +5,7 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/oriys/nimbus/internal/auth"
@@ -38,8 +39,9 @@ type APIKeyStore interface {
 	//   - keyHash: 密钥的哈希值（安全存储）
 	//   - userID: 所属用户的ID
 	//   - role: 用户角色
+	//   - tagSelector: 标签选择器（为空表示可访问全部函数）
 	// 返回值: 可能的错误
-	CreateAPIKey(id, name, keyHash, userID, role string) error
+	CreateAPIKey(id, name, keyHash, userID, role string, tagSelector []string) error
 
 	// GetAPIKeyByHash 通过哈希值获取API密钥信息
 	// 参数：
@@ -68,6 +70,8 @@ type APIKeyInfo struct {
 	Role      string  `json:"role"`
 	CreatedAt string  `json:"created_at"`
 	ExpiresAt *string `json:"expires_at,omitempty"`
+	// TagSelector 标签选择器，非空时只能访问包含全部这些标签的函数
+	TagSelector []string `json:"tag_selector,omitempty"`
 }
 
 // NewAuthHandler 创建并返回一个新的AuthHandler实例。
@@ -86,8 +90,10 @@ func NewAuthHandler(jwt *auth.JWTManager, store APIKeyStore) *AuthHandler {
 //
 // 字段说明：
 //   - Name: API密钥的名称，用于标识该密钥的用途（如"production-key"、"test-key"）
+//   - TagSelector: 可选的标签选择器（如["team:payments"]），限制密钥只能访问带有这些标签的函数
 type CreateAPIKeyRequest struct {
-	Name string `json:"name"` // API密钥的名称
+	Name        string   `json:"name"`                   // API密钥的名称
+	TagSelector []string `json:"tag_selector,omitempty"` // 标签选择器（可选）
 }
 
 // CreateAPIKeyResponse 定义了创建API密钥的响应结构。
@@ -145,9 +151,12 @@ func (h *AuthHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 受标签作用域限制的密钥不能创建权限更宽的密钥，继承其选择器
+	tagSelector := mergeTagSelector(req.TagSelector, user.TagSelector)
+
 	// 生成唯一ID并保存API密钥记录
 	id := uuid.New().String()
-	if err := h.store.CreateAPIKey(id, req.Name, hash, user.UserID, user.Role); err != nil {
+	if err := h.store.CreateAPIKey(id, req.Name, hash, user.UserID, user.Role, tagSelector); err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to create key")
 		return
 	}
@@ -248,11 +257,32 @@ func (h *AuthHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
 		if key.ExpiresAt != nil {
 			result[i]["expires_at"] = key.ExpiresAt
 		}
+		if len(key.TagSelector) > 0 {
+			result[i]["tag_selector"] = key.TagSelector
+		}
 	}
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{"api_keys": result})
 }
 
+// mergeTagSelector 合并请求的标签选择器与调用者自身的选择器（去重）。
+// 结果至少包含调用者的全部选择器标签，从而保证新密钥的访问范围不会超出调用者。
+func mergeTagSelector(requested, inherited []string) []string {
+	seen := make(map[string]bool, len(requested)+len(inherited))
+	var merged []string
+	for _, list := range [][]string{inherited, requested} {
+		for _, tag := range list {
+			tag = strings.TrimSpace(tag)
+			if tag == "" || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
+
 // DeleteAPIKey 删除指定的API密钥。
 // HTTP端点: DELETE /api/v1/auth/apikeys/{id}
 //
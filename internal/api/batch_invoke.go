@@ -0,0 +1,244 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// batchCall 是批量调用中一次调度器调用的返回值
+type batchCall struct {
+	resp *domain.InvokeResponse
+	err  error
+}
+
+// BatchInvokeFunction 用多个载荷批量同步调用同一函数，以有限并发执行并按载荷顺序返回各次调用的结果。
+// HTTP端点: POST /api/v1/functions/{id}/batch-invoke
+//
+// 请求体: {"payloads": [...], "max_parallel": 10, "fail_fast": false}
+//
+// 调用前的检查（函数状态、弃用、调用环境）与同步调用相同；请求体整体受函数的请求体大小上限约束，
+// 每个载荷各消耗一个限流令牌，令牌不足的载荷记为失败（status_code 429）。
+// 每个调用各自受函数超时约束。fail_fast 为 true 时第一个调用失败后不再发起新的调用。
+// 请求上下文取消（客户端断开或网关请求超时）时不再等待执行中的调用，立即返回已完成的部分结果。
+//
+// 返回值：
+//   - 200: 返回 domain.BatchInvokeResponse，单个调用的失败不影响状态码
+//   - 400: 请求体无效或函数不可调用
+//   - 413: 请求体超过大小上限
+func (h *Handler) BatchInvokeFunction(w http.ResponseWriter, r *http.Request) {
+	fn, ok := h.lookupFunction(w, r)
+	if !ok {
+		return
+	}
+	if writePausedError(w, r, fn) {
+		return
+	}
+	if !fn.Status.CanInvoke() {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "function is not active, current status: "+string(fn.Status))
+		return
+	}
+	if !h.checkDeprecation(w, r, fn, "BatchInvokeFunction") {
+		return
+	}
+	if !h.checkInvokeEnvironment(w, r, fn) {
+		return
+	}
+	// 调用所在环境的函数配置对每个载荷生效，环境配置了 active_alias 时按别名路由
+	envCfg, ok := h.resolveEnvConfig(w, r, fn)
+	if !ok {
+		return
+	}
+	alias, ok := h.resolveEnvAlias(w, r, fn, "", envCfg)
+	if !ok {
+		return
+	}
+	setEffectiveConfigHeader(w, r, fn, envCfg, alias)
+
+	if !h.limitPayload(w, r, fn) {
+		return
+	}
+	var req domain.BatchInvokeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if writePayloadTooLargeError(w, r, err) {
+			return
+		}
+		writeErrorWithContext(w, r, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if err := req.Normalize(); err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	costTags, ok := parseCostTags(w, r)
+	if !ok {
+		return
+	}
+
+	h.logInfo(r, "BatchInvokeFunction", "开始批量调用", logrus.Fields{
+		"function":     fn.Name,
+		"payloads":     len(req.Payloads),
+		"max_parallel": req.MaxParallel,
+		"fail_fast":    req.FailFast,
+	})
+
+	result := h.runBatchInvoke(r, fn, &req, domain.InvokeRequest{
+		FunctionID: fn.ID,
+		CostTags:   costTags,
+		Alias:      alias,
+		EnvConfig:  envCfg,
+		CallChain:  callChainFromRequest(r),
+	})
+
+	h.logInfo(r, "BatchInvokeFunction", "批量调用完成", logrus.Fields{
+		"function":       fn.Name,
+		"succeeded":      result.Succeeded,
+		"failed":         result.Failed,
+		"skipped":        result.Skipped,
+		"cancelled":      result.Cancelled,
+		"stopped_reason": result.StoppedReason,
+	})
+	writeJSON(w, http.StatusOK, result)
+}
+
+// runBatchInvoke 以 req.MaxParallel 个工作协程依次调用各载荷并汇总结果。
+// fail_fast 触发或请求上下文取消后不再发起新的调用，未开始的载荷记为 skipped。
+// ctx 派生自请求上下文，fail_fast 触发时取消，用于停止分发载荷。
+// base 是各次调用共用的调用请求，每个载荷复制一份并填入载荷。
+func (h *Handler) runBatchInvoke(r *http.Request, fn *domain.Function, req *domain.BatchInvokeRequest, base domain.InvokeRequest) *domain.BatchInvokeResponse {
+	ctx, stop := context.WithCancel(r.Context())
+	defer stop()
+
+	results := make([]domain.BatchInvokeResult, len(req.Payloads))
+	for i := range results {
+		results[i] = domain.BatchInvokeResult{Index: i, Status: domain.BatchItemSkipped, Error: "invocation not started"}
+	}
+
+	// 每个下标只由一个工作协程写入，wg.Wait 之后读取，无需加锁
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < req.MaxParallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indexes {
+				if ctx.Err() != nil {
+					continue
+				}
+				results[idx] = h.invokeBatchItem(r, fn, idx, req.Payloads[idx], base)
+				if req.FailFast && results[idx].Status == domain.BatchItemFailed {
+					stop()
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := range req.Payloads {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	resp := &domain.BatchInvokeResponse{Results: results}
+	for _, res := range results {
+		switch res.Status {
+		case domain.BatchItemSucceeded:
+			resp.Succeeded++
+		case domain.BatchItemFailed:
+			resp.Failed++
+		case domain.BatchItemSkipped:
+			resp.Skipped++
+		case domain.BatchItemCancelled:
+			resp.Cancelled++
+		}
+	}
+	switch {
+	case r.Context().Err() != nil:
+		resp.StoppedReason = domain.BatchStoppedCancelled
+	case resp.Skipped > 0:
+		resp.StoppedReason = domain.BatchStoppedFailFast
+	}
+	return resp
+}
+
+// invokeBatchItem 通过调度器同步调用一个载荷。请求上下文在调用返回前取消时不再等待，
+// 结果记为 cancelled；调用在调度器中继续执行，结果照常记录在调用记录中。
+// fail_fast 只阻止发起新的调用，已开始的调用仍等待其结果。
+func (h *Handler) invokeBatchItem(r *http.Request, fn *domain.Function, idx int, payload json.RawMessage, base domain.InvokeRequest) domain.BatchInvokeResult {
+	res := domain.BatchInvokeResult{Index: idx}
+	if !h.allowBatchItem(r, fn) {
+		res.Status = domain.BatchItemFailed
+		res.StatusCode = http.StatusTooManyRequests
+		res.Error = domain.ErrRateLimitExceeded.Error()
+		return res
+	}
+	if len(payload) == 0 {
+		payload = json.RawMessage("{}")
+	}
+
+	requestID := generateRequestID()
+	broadcastInvocationStart(fn, domain.LogSourceAPI, requestID, payload)
+	start := time.Now()
+	done := make(chan batchCall, 1)
+	invokeReq := base
+	invokeReq.Payload = payload
+	go func() {
+		resp, err := h.scheduler.Invoke(&invokeReq)
+		broadcastInvocationResult(fn, domain.LogSourceAPI, requestID, payload, resp, err, time.Since(start).Milliseconds())
+		done <- batchCall{resp: resp, err: err}
+	}()
+
+	var call batchCall
+	select {
+	case call = <-done:
+	case <-r.Context().Done():
+		res.Status = domain.BatchItemCancelled
+		res.Error = "request cancelled before the invocation finished"
+		res.DurationMs = time.Since(start).Milliseconds()
+		return res
+	}
+
+	if call.err != nil {
+		res.Status = domain.BatchItemFailed
+		res.Error = call.err.Error()
+		res.DurationMs = time.Since(start).Milliseconds()
+		return res
+	}
+	res.RequestID = call.resp.RequestID
+	res.StatusCode = call.resp.StatusCode
+	res.Body = call.resp.Body
+	res.Error = call.resp.Error
+	res.DurationMs = call.resp.DurationMs
+	res.Status = domain.BatchItemSucceeded
+	if call.resp.Error != "" || call.resp.StatusCode < 200 || call.resp.StatusCode > 299 {
+		res.Status = domain.BatchItemFailed
+	}
+	return res
+}
+
+// allowBatchItem 为批量调用中的一个载荷消耗函数的限流令牌。
+// 未配置限流时放行；Redis 不可用时与单次调用一样降级为不限流并记录警告。
+func (h *Handler) allowBatchItem(r *http.Request, fn *domain.Function) bool {
+	if fn.RateLimit == nil || h.redis == nil {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), rateLimitCheckTimeout)
+	defer cancel()
+	status, err := h.redis.TakeRateLimitToken(ctx, rateLimitKey(r, fn), fn.RateLimit)
+	if err != nil {
+		h.logWarn(r, "BatchInvokeFunction", "限流检查失败，放行请求", logrus.Fields{"function": fn.Name, "error": err.Error()})
+		return true
+	}
+	return status.Allowed
+}
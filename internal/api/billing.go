@@ -0,0 +1,179 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// usagePeriods 是用量统计支持的时间段及对应的小时数
+var usagePeriods = map[string]int{
+	"1h":  1,
+	"6h":  6,
+	"24h": 24,
+	"7d":  168,
+	"30d": 720,
+}
+
+// parseCostTags 解析 X-Nimbus-Cost-Tags 请求头。格式无效时写入 400 响应并返回 false。
+func parseCostTags(w http.ResponseWriter, r *http.Request) (map[string]string, bool) {
+	tags, err := domain.ParseCostTags(r.Header.Get(domain.HeaderCostTags))
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	return tags, true
+}
+
+// GetBillingUsage 按成本标签或函数汇总调用次数和计费时长，用于内部成本分摊。
+// HTTP端点: GET /api/v1/billing/usage?group_by=tag&period=7d&tag_key=team
+//
+// 查询参数：
+//   - group_by: 分组方式，tag（默认）或 function
+//   - period: 统计时间段，1h、6h、24h（默认）、7d 或 30d
+//   - tag_key: 按标签分组时只统计该标签键（可选）
+func (h *Handler) GetBillingUsage(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	groupBy := query.Get("group_by")
+	if groupBy == "" {
+		groupBy = domain.UsageGroupByTag
+	}
+	if groupBy != domain.UsageGroupByTag && groupBy != domain.UsageGroupByFunction {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "group_by must be tag or function")
+		return
+	}
+
+	period := query.Get("period")
+	if period == "" {
+		period = "24h"
+	}
+	periodHours, ok := usagePeriods[period]
+	if !ok {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "period must be one of 1h, 6h, 24h, 7d, 30d")
+		return
+	}
+
+	report, err := h.store.GetUsageReport(periodHours, groupBy, query.Get("tag_key"))
+	if err != nil {
+		h.logError(r, "GetBillingUsage", "查询用量失败", err, logrus.Fields{"group_by": groupBy, "period": period})
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to get usage: "+err.Error())
+		return
+	}
+	report.Period = period
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// maxEstimateInvocationsPerDay 是成本预估允许的每日调用次数上限
+const maxEstimateInvocationsPerDay = 1_000_000_000
+
+// GetFunctionCostEstimate 按假设的每日调用量预估函数的月度用量。
+// HTTP端点: GET /api/v1/functions/{id}/cost-estimate?invocations_per_day=10000&period=7d
+//
+// 查询参数：
+//   - invocations_per_day: 假设的每日调用次数，必填
+//   - period: 观测平均计费时长的时间段，1h、6h、24h、7d（默认）或 30d
+//
+// 平均计费时长取观测窗口内已完成调用的平均值，没有样本时按最小计费时长（100ms）估算，
+// 响应中的 assumptions 说明了预估使用的全部假设。
+func (h *Handler) GetFunctionCostEstimate(w http.ResponseWriter, r *http.Request) {
+	fn, ok := h.lookupFunction(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+
+	perDay, err := strconv.ParseInt(query.Get("invocations_per_day"), 10, 64)
+	if err != nil || perDay <= 0 || perDay > maxEstimateInvocationsPerDay {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "invocations_per_day must be an integer between 1 and 1000000000")
+		return
+	}
+
+	period := query.Get("period")
+	if period == "" {
+		period = "7d"
+	}
+	periodHours, ok := usagePeriods[period]
+	if !ok {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "period must be one of 1h, 6h, 24h, 7d, 30d")
+		return
+	}
+
+	since := time.Now().Add(-time.Duration(periodHours) * time.Hour)
+	samples, avgBilledMs, err := h.store.GetBilledTimeStats(fn.ID, since)
+	if err != nil {
+		h.logError(r, "GetFunctionCostEstimate", "查询计费时长失败", err, logrus.Fields{"function": fn.Name})
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to get billed time: "+err.Error())
+		return
+	}
+
+	estimate := domain.EstimateCost(fn, perDay, samples, avgBilledMs)
+	estimate.Assumptions.SamplePeriod = period
+	writeJSON(w, http.StatusOK, estimate)
+}
+
+// SetBillingRates 设置计费单价，需在处理请求之前调用。单价均为 0 时计费汇总不计算费用。
+func (h *Handler) SetBillingRates(rates domain.BillingRates) {
+	h.billingRates = rates
+}
+
+// GetFunctionBilling 汇总函数在一段时间内的调用次数、GB-秒、平均执行时长和费用。
+// HTTP端点: GET /api/v1/functions/{id}/billing?period=30d&group_by=day
+//
+// 查询参数：
+//   - period: 统计时间段，1h、6h、24h、7d 或 30d（默认）
+//   - group_by: 为 day 时同时返回按天（UTC）的用量，用于趋势图
+//
+// GB-秒按函数当前配置的内存计算；配置了计费单价时返回 cost 和 rates。
+func (h *Handler) GetFunctionBilling(w http.ResponseWriter, r *http.Request) {
+	fn, ok := h.lookupFunction(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+
+	period := query.Get("period")
+	if period == "" {
+		period = "30d"
+	}
+	periodHours, ok := usagePeriods[period]
+	if !ok {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "period must be one of 1h, 6h, 24h, 7d, 30d")
+		return
+	}
+	groupBy := query.Get("group_by")
+	if groupBy != "" && groupBy != domain.BillingGroupByDay {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "group_by must be day")
+		return
+	}
+	byDay := groupBy == domain.BillingGroupByDay
+
+	now := time.Now()
+	since := now.Add(-time.Duration(periodHours) * time.Hour)
+	total, days, err := h.store.GetFunctionBillingUsage(fn.ID, since, byDay)
+	if err != nil {
+		h.logError(r, "GetFunctionBilling", "查询计费用量失败", err, logrus.Fields{"function": fn.Name, "period": period})
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to get billing usage: "+err.Error())
+		return
+	}
+
+	summary := &domain.FunctionBillingSummary{
+		FunctionID:   fn.ID,
+		FunctionName: fn.Name,
+		Period:       period,
+		Since:        since,
+		MemoryMB:     fn.MemoryMB,
+		Total:        total,
+	}
+	if byDay {
+		summary.Days = domain.FillBillingDays(days, since, now)
+	}
+	summary.ApplyRates(h.billingRates)
+
+	writeJSON(w, http.StatusOK, summary)
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// buildTimeout 是单个编译任务（包括排队等待编译槽位）的最长时间
+const buildTimeout = 5 * time.Minute
+
+// errBuildCancelled 是用户取消编译任务时 context 的取消原因
+var errBuildCancelled = errors.New("cancelled by user")
+
+// runningBuild 是本实例上正在执行的一个编译任务
+type runningBuild struct {
+	cancel    context.CancelCauseFunc // 取消编译 context
+	cancelled bool                    // 是否已被用户取消
+}
+
+// buildRegistry 记录本实例上正在执行的编译任务，用于取消编译。零值可直接使用。
+type buildRegistry struct {
+	mu     sync.Mutex
+	builds map[string]*runningBuild // 任务 ID -> 编译任务
+}
+
+// start 登记编译任务并返回编译使用的 context，超时时间为 timeout。
+// 返回的 cancel 函数释放 context 资源，不会将任务标记为已取消。
+func (b *buildRegistry) start(taskID string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancelCause := context.WithCancelCause(context.Background())
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+
+	b.mu.Lock()
+	if b.builds == nil {
+		b.builds = make(map[string]*runningBuild)
+	}
+	b.builds[taskID] = &runningBuild{cancel: cancelCause}
+	b.mu.Unlock()
+
+	return ctx, func() {
+		cancel()
+		cancelCause(context.Canceled)
+	}
+}
+
+// finish 注销编译任务，返回任务是否已被用户取消。任务已注销时返回 false。
+// 编译结束后必须先调用 finish 再处理编译结果：注销之后的取消请求会被拒绝，
+// 保证任务不会在被确认取消之后又被部署。
+func (b *buildRegistry) finish(taskID string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	build, ok := b.builds[taskID]
+	if !ok {
+		return false
+	}
+	delete(b.builds, taskID)
+	return build.cancelled
+}
+
+// cancel 取消正在执行的编译任务。任务不在本实例上执行（或已结束）时返回 false。
+func (b *buildRegistry) cancel(taskID string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	build, ok := b.builds[taskID]
+	if !ok {
+		return false
+	}
+	build.cancelled = true
+	build.cancel(errBuildCancelled)
+	return true
+}
+
+// completeTaskCancelled 将被用户取消的编译任务标记为失败，并恢复函数状态。
+// restoreStatus 为空表示函数从未部署过，恢复为 failed；否则恢复为编译前的状态，
+// 继续使用上一次部署的二进制。
+func (h *Handler) completeTaskCancelled(taskID, functionID string, restoreStatus domain.FunctionStatus) {
+	completedAt := time.Now()
+	h.store.UpdateFunctionTask(&domain.FunctionTask{
+		ID:          taskID,
+		Status:      domain.FunctionTaskFailed,
+		Error:       errBuildCancelled.Error(),
+		CompletedAt: &completedAt,
+	})
+	if restoreStatus == "" {
+		h.store.UpdateFunctionStatus(functionID, domain.FunctionStatusFailed, "编译已被用户取消", "")
+	} else {
+		h.store.UpdateFunctionStatus(functionID, restoreStatus, "编译已被用户取消，继续使用上一次部署的版本", "")
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"function_id": functionID,
+		"task_id":     taskID,
+		"status":      restoreStatus,
+	}).Warn("编译任务已被用户取消")
+}
+
+// CancelFunctionTask 取消正在执行的编译任务。
+// HTTP端点: POST /api/v1/tasks/{id}/cancel
+//
+// 功能说明：
+//   - 取消编译 context，编译容器被强制删除，编译槽位立即释放
+//   - 任务标记为 failed（"cancelled by user"），函数恢复为编译前的状态
+//   - 只能取消在本实例上执行的 pending/running 任务，已结束的任务返回 409
+func (h *Handler) CancelFunctionTask(w http.ResponseWriter, r *http.Request) {
+	taskID := chi.URLParam(r, "id")
+	if taskID == "" {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "task id required")
+		return
+	}
+
+	task, err := h.store.GetFunctionTask(taskID)
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusNotFound, "task not found")
+		return
+	}
+	if task.Status != domain.FunctionTaskPending && task.Status != domain.FunctionTaskRunning {
+		writeErrorWithContext(w, r, http.StatusConflict, "task already finished with status: "+string(task.Status))
+		return
+	}
+
+	if !h.builds.cancel(taskID) {
+		h.logWarn(r, "CancelFunctionTask", "任务不在本实例上执行", logrus.Fields{"task_id": taskID, "status": task.Status})
+		writeErrorWithContext(w, r, http.StatusConflict, "task is not building on this instance or has already finished")
+		return
+	}
+
+	h.logInfo(r, "CancelFunctionTask", "编译任务取消中", logrus.Fields{"task_id": taskID, "function_id": task.FunctionID})
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"task_id":     taskID,
+		"function_id": task.FunctionID,
+		"status":      "cancelling",
+	})
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// CompareFunctionVersions 对比函数两个版本在统计时间段内的调用数、错误率和延迟分位数，
+// 并按阈值给出候选版本能否推广的结论（金丝雀分析）。
+// HTTP端点: GET /api/v1/functions/{id}/compare?baseline=3&candidate=5&period=1h
+//
+// 查询参数：
+//   - baseline: 基线版本号，必填
+//   - candidate: 候选版本号，必填
+//   - period: 统计时间段，1h（默认）、6h、24h、7d 或 30d
+//   - max_error_rate_delta: 错误率最多高出的百分点，默认 1
+//   - max_p95_increase_pct: P95 延迟最多增加的百分比，默认 20
+//   - min_invocations: 每个版本得出结论所需的最少调用数，默认 20
+func (h *Handler) CompareFunctionVersions(w http.ResponseWriter, r *http.Request) {
+	fn, ok := h.lookupFunction(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+
+	baseline, err := parseCompareVersion(query, "baseline", fn)
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	candidate, err := parseCompareVersion(query, "candidate", fn)
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if baseline == candidate {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "baseline and candidate must be different versions")
+		return
+	}
+
+	period := query.Get("period")
+	if period == "" {
+		period = "1h"
+	}
+	periodHours, ok := usagePeriods[period]
+	if !ok {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "period must be one of 1h, 6h, 24h, 7d, 30d")
+		return
+	}
+
+	thresholds, err := parseCanaryThresholds(query)
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	since := time.Now().Add(-time.Duration(periodHours) * time.Hour)
+	stats, err := h.store.GetVersionStats(fn.ID, []int{baseline, candidate}, since)
+	if err != nil {
+		h.logError(r, "CompareFunctionVersions", "查询版本统计失败", err, logrus.Fields{
+			"function":  fn.Name,
+			"baseline":  baseline,
+			"candidate": candidate,
+		})
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to get version stats: "+err.Error())
+		return
+	}
+
+	report := domain.NewCanaryReport(fn, versionStatsOrEmpty(stats, baseline), versionStatsOrEmpty(stats, candidate), thresholds)
+	report.Period = period
+	report.Since = since
+	writeJSON(w, http.StatusOK, report)
+}
+
+// parseCompareVersion 解析版本对比的版本号参数，版本号必须是函数已有的版本。
+func parseCompareVersion(query url.Values, name string, fn *domain.Function) (int, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return 0, fmt.Errorf("%s version is required", name)
+	}
+	version, err := strconv.Atoi(raw)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("%s must be a positive version number", name)
+	}
+	if version > fn.Version {
+		return 0, fmt.Errorf("%s version %d does not exist, latest version is %d", name, version, fn.Version)
+	}
+	return version, nil
+}
+
+// parseCanaryThresholds 解析查询参数中的金丝雀分析阈值，未指定的阈值使用默认值。
+func parseCanaryThresholds(query url.Values) (domain.CanaryThresholds, error) {
+	t := domain.DefaultCanaryThresholds()
+	if raw := query.Get("max_error_rate_delta"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 {
+			return t, fmt.Errorf("max_error_rate_delta must be a non-negative number")
+		}
+		t.MaxErrorRateDelta = v
+	}
+	if raw := query.Get("max_p95_increase_pct"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 {
+			return t, fmt.Errorf("max_p95_increase_pct must be a non-negative number")
+		}
+		t.MaxP95IncreasePct = v
+	}
+	if raw := query.Get("min_invocations"); raw != "" {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || v < 0 {
+			return t, fmt.Errorf("min_invocations must be a non-negative integer")
+		}
+		t.MinInvocations = v
+	}
+	return t, nil
+}
+
+// versionStatsOrEmpty 返回版本的统计，统计时间段内没有调用时返回调用数为 0 的统计。
+func versionStatsOrEmpty(stats map[int]*domain.VersionStats, version int) domain.VersionStats {
+	if s, ok := stats[version]; ok {
+		return *s
+	}
+	return domain.VersionStats{Version: version}
+}
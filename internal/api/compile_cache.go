@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/compiler"
+)
+
+// InvalidateCompileCacheRequest 清除编译缓存的请求体
+type InvalidateCompileCacheRequest struct {
+	// Runtime 要清除缓存的运行时，为空表示清除全部缓存
+	Runtime string `json:"runtime,omitempty"`
+}
+
+// compileLayerHashes 返回函数挂载的层内容哈希（按加载顺序），作为编译缓存键的一部分。
+// 层版本无法读取时使用层 ID 和版本号代替，保证层变化时缓存键仍然变化。
+func (h *Handler) compileLayerHashes(functionID string) []string {
+	layers, err := h.store.GetFunctionLayers(functionID)
+	if err != nil {
+		h.logger.WithError(err).WithField("function_id", functionID).Warn("获取函数层失败，编译缓存键不包含层")
+		return nil
+	}
+	hashes := make([]string, 0, len(layers))
+	for _, fl := range layers {
+		lv, err := h.store.GetLayerVersion(fl.LayerID, fl.LayerVersion)
+		if err != nil || lv.ContentHash == "" {
+			hashes = append(hashes, fl.LayerID+"@"+strconv.Itoa(fl.LayerVersion))
+			continue
+		}
+		hashes = append(hashes, lv.ContentHash)
+	}
+	return hashes
+}
+
+// InvalidateCompileCache 清除编译缓存。
+// HTTP端点: POST /api/v1/admin/compile-cache/invalidate
+//
+// 请求体可选，指定 runtime 时只清除该运行时的缓存，否则清除全部缓存。
+// 编译缓存键已包含编译镜像摘要和层内容哈希，该接口用于缓存键无法感知的变更（如镜像内的工具链配置被原地修改）。
+func (h *Handler) InvalidateCompileCache(w http.ResponseWriter, r *http.Request) {
+	var req InvalidateCompileCacheRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if req.Runtime != "" && !compiler.IsCompiledRuntime(req.Runtime) {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "runtime does not support compilation: "+req.Runtime)
+		return
+	}
+
+	removed := h.compiler.InvalidateCache(req.Runtime)
+	h.logInfo(r, "InvalidateCompileCache", "已清除编译缓存", logrus.Fields{
+		"runtime": req.Runtime,
+		"removed": removed,
+	})
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"runtime":     req.Runtime,
+		"invalidated": removed,
+		"cache":       h.compiler.CacheStats(),
+	})
+}
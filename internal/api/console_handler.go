@@ -88,6 +88,43 @@ func BroadcastLog(log LogMessage) {
 	}
 }
 
+// broadcastInvocationStart 广播调用开始日志，消息中带有调用来源。
+func broadcastInvocationStart(fn *domain.Function, source, requestID string, input json.RawMessage) {
+	BroadcastLog(LogMessage{
+		Timestamp:    time.Now(),
+		Level:        "INFO",
+		FunctionID:   fn.ID,
+		FunctionName: fn.Name,
+		Message:      "函数调用开始 (" + source + ")",
+		RequestID:    requestID,
+		Input:        input,
+		Source:       source,
+	})
+}
+
+// broadcastInvocationResult 广播调用完成日志，err 不为 nil 时广播调用失败日志。
+func broadcastInvocationResult(fn *domain.Function, source, requestID string, input json.RawMessage, resp *domain.InvokeResponse, err error, durationMs int64) {
+	log := LogMessage{
+		Timestamp:    time.Now(),
+		Level:        "INFO",
+		FunctionID:   fn.ID,
+		FunctionName: fn.Name,
+		Message:      "函数调用完成 (" + source + ")",
+		RequestID:    requestID,
+		Input:        input,
+		DurationMs:   durationMs,
+		Source:       source,
+	}
+	if err != nil {
+		log.Level = "ERROR"
+		log.Message = "函数调用失败 (" + source + ")"
+		log.Error = err.Error()
+	} else {
+		log.Output = resp.Body
+	}
+	BroadcastLog(log)
+}
+
 // ConsoleHandler 处理 Web 控制台相关的 API 请求
 type ConsoleHandler struct {
 	handler *Handler
@@ -218,6 +255,7 @@ func (c *ConsoleHandler) ListLogs(w http.ResponseWriter, r *http.Request) {
 		FunctionName: strings.TrimSpace(q.Get("function_name")),
 		RequestID:    strings.TrimSpace(q.Get("request_id")),
 		Level:        strings.TrimSpace(q.Get("level")),
+		Source:       strings.TrimSpace(q.Get("source")),
 		Before:       before,
 		After:        after,
 		Limit:        limit,
@@ -429,11 +467,12 @@ func (c *ConsoleHandler) GetRecentInvocations(w http.ResponseWriter, r *http.Req
 
 // PoolStats 虚拟机池统计
 type PoolStats struct {
-	Runtime  string `json:"runtime"`
-	WarmVMs  int    `json:"warm_vms"`
-	BusyVMs  int    `json:"busy_vms"`
-	TotalVMs int    `json:"total_vms"`
-	MaxVMs   int    `json:"max_vms"`
+	Runtime    string `json:"runtime"`
+	WarmVMs    int    `json:"warm_vms"`
+	BusyVMs    int    `json:"busy_vms"`
+	TotalVMs   int    `json:"total_vms"`
+	MaxVMs     int    `json:"max_vms"`
+	PinnedWarm int    `json:"pinned_warm"` // 由函数 keep_warm 固定常驻的热实例数
 }
 
 // SystemStatusResponse 系统状态响应
@@ -466,6 +505,17 @@ func (c *ConsoleHandler) GetSystemStatus(w http.ResponseWriter, r *http.Request)
 		{Runtime: "go1.24", WarmVMs: 1, BusyVMs: 0, TotalVMs: 1, MaxVMs: 10},
 	}
 
+	// 常驻预热目标数来自函数的 keep_warm 配置，按运行时汇总
+	if targets, err := c.store.ListKeepWarmTargets(); err == nil {
+		pinned := make(map[string]int)
+		for _, t := range targets {
+			pinned[string(t.Runtime)] += t.Count
+		}
+		for i := range poolStats {
+			poolStats[i].PinnedWarm = pinned[poolStats[i].Runtime]
+		}
+	}
+
 	response := SystemStatusResponse{
 		Status:    status,
 		Version:   "1.0.0",
@@ -745,6 +795,9 @@ func (c *ConsoleHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
 		if key.ExpiresAt != nil {
 			result[i]["expires_at"] = key.ExpiresAt.Format(time.RFC3339)
 		}
+		if len(key.TagSelector) > 0 {
+			result[i]["tag_selector"] = key.TagSelector
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -754,7 +807,8 @@ func (c *ConsoleHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
 // CreateAPIKey 创建新的 API Key
 func (c *ConsoleHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Name string `json:"name"`
+		Name        string   `json:"name"`
+		TagSelector []string `json:"tag_selector,omitempty"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "invalid request", http.StatusBadRequest)
@@ -765,6 +819,7 @@ func (c *ConsoleHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "name is required", http.StatusBadRequest)
 		return
 	}
+	tagSelector := mergeTagSelector(req.TagSelector, nil)
 
 	// 使用默认用户（控制台无认证）
 	userID := "console-user"
@@ -782,7 +837,7 @@ func (c *ConsoleHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 	id := randomUUID()
 
 	// 保存到数据库
-	if err := c.store.CreateAPIKey(id, req.Name, hash, userID, role); err != nil {
+	if err := c.store.CreateAPIKey(id, req.Name, hash, userID, role, tagSelector); err != nil {
 		c.logger.WithError(err).Error("Failed to create API key")
 		http.Error(w, "failed to create api key", http.StatusInternalServerError)
 		return
@@ -791,9 +846,10 @@ func (c *ConsoleHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"id":      id,
-		"name":    req.Name,
-		"api_key": key, // 仅返回一次
+		"id":           id,
+		"name":         req.Name,
+		"api_key":      key, // 仅返回一次
+		"tag_selector": tagSelector,
 	})
 }
 
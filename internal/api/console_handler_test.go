@@ -0,0 +1,40 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+func TestBroadcastInvocationLogs(t *testing.T) {
+	prev := globalLogBroadcaster
+	globalLogBroadcaster = NewLogBroadcaster()
+	defer func() { globalLogBroadcaster = prev }()
+
+	ch := make(chan LogMessage, 4)
+	globalLogBroadcaster.Subscribe(ch)
+	defer globalLogBroadcaster.Unsubscribe(ch)
+
+	fn := &domain.Function{ID: "fn-1", Name: "demo"}
+	input := json.RawMessage(`{"n":1}`)
+
+	broadcastInvocationStart(fn, domain.LogSourceRoute, "req-1", input)
+	broadcastInvocationResult(fn, domain.LogSourceRoute, "req-1", input, &domain.InvokeResponse{Body: json.RawMessage(`"ok"`)}, nil, 12)
+	broadcastInvocationResult(fn, domain.LogSourceWebhook, "req-2", input, nil, errors.New("boom"), 5)
+
+	start, done, failed := <-ch, <-ch, <-ch
+	if start.Source != domain.LogSourceRoute || start.Level != "INFO" || start.Message != "函数调用开始 (route)" || string(start.Input) != `{"n":1}` {
+		t.Errorf("start log = %+v, want INFO route start with input", start)
+	}
+	if done.Source != domain.LogSourceRoute || done.Level != "INFO" || string(done.Output) != `"ok"` || done.DurationMs != 12 {
+		t.Errorf("result log = %+v, want INFO route result with output and duration", done)
+	}
+	if failed.Source != domain.LogSourceWebhook || failed.Level != "ERROR" || failed.Error != "boom" || failed.Message != "函数调用失败 (webhook)" {
+		t.Errorf("failure log = %+v, want ERROR webhook failure", failed)
+	}
+	if start.FunctionID != fn.ID || failed.RequestID != "req-2" {
+		t.Errorf("logs should carry function and request IDs: %+v, %+v", start, failed)
+	}
+}
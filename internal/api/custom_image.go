@@ -0,0 +1,24 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// checkCustomImage 校验函数使用的自定义镜像在运维方配置的白名单内（部署配置 docker.custom_images）。
+// 未使用自定义镜像时直接通过；调度器不支持自定义镜像或镜像不在白名单内时写入 400 响应并返回 false。
+func (h *Handler) checkCustomImage(w http.ResponseWriter, r *http.Request, image string) bool {
+	if image == "" {
+		return true
+	}
+	checker, ok := h.scheduler.(CustomImageChecker)
+	if !ok || !checker.CustomImageAllowed(image) {
+		h.logWarn(r, "checkCustomImage", "自定义镜像不在白名单内", logrus.Fields{"image": image})
+		writeErrorWithContext(w, r, http.StatusBadRequest, domain.ErrCustomImageNotAllowed.Error()+": "+image)
+		return false
+	}
+	return true
+}
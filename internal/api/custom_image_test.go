@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// customImageScheduler 只允许 allowed 中的自定义镜像
+type customImageScheduler struct {
+	MockScheduler
+	allowed map[string]bool
+}
+
+func (s *customImageScheduler) CustomImageAllowed(image string) bool { return s.allowed[image] }
+
+func TestCheckCustomImage(t *testing.T) {
+	sched := &customImageScheduler{allowed: map[string]bool{"registry.example.com/team/app:1.0": true}}
+	tests := []struct {
+		name       string
+		scheduler  Scheduler
+		image      string
+		wantOK     bool
+		wantStatus int
+	}{
+		{name: "runtime image", scheduler: sched, wantOK: true, wantStatus: http.StatusOK},
+		{name: "listed image", scheduler: sched, image: "registry.example.com/team/app:1.0", wantOK: true, wantStatus: http.StatusOK},
+		{name: "unlisted image", scheduler: sched, image: "alpine:3", wantStatus: http.StatusBadRequest},
+		{name: "scheduler without custom images", scheduler: &MockScheduler{}, image: "registry.example.com/team/app:1.0", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{scheduler: tt.scheduler}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/functions", nil)
+			if got := h.checkCustomImage(w, r, tt.image); got != tt.wantOK {
+				t.Errorf("checkCustomImage() = %v, want %v", got, tt.wantOK)
+			}
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if !tt.wantOK && !strings.Contains(w.Body.String(), domain.ErrCustomImageNotAllowed.Error()) {
+				t.Errorf("body = %s, want allowlist error", w.Body.String())
+			}
+		})
+	}
+}
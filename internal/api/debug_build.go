@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+const (
+	// defaultDebugBuildConcurrency 默认同时进行的调试容器编译数
+	defaultDebugBuildConcurrency = 2
+	// debugBuildWaitTimeout 等待调试编译槽位的最长时间
+	debugBuildWaitTimeout = 5 * time.Minute
+)
+
+// needsDebugBuild 判断运行时的调试容器是否在启动时编译用户代码。
+// Go 和 Rust 在容器内编译后才启动调试器，编译占用大量 CPU；解释型运行时直接启动。
+func needsDebugBuild(runtime domain.Runtime) bool {
+	return runtime == domain.RuntimeGo124 || runtime == domain.RuntimeWasm
+}
+
+// SetBuildConcurrency 设置同时进行的调试容器编译数上限，超出的调试会话排队等待。
+// 调试编译使用独立的槽位，不占用函数部署的编译槽位。需在处理请求之前调用。
+//
+// 参数:
+//   - limit: 并发上限，<= 0 表示不限制
+func (h *DebugHandler) SetBuildConcurrency(limit int) {
+	if limit <= 0 {
+		h.buildSlots = nil
+		return
+	}
+	h.buildSlots = make(chan struct{}, limit)
+}
+
+// acquireBuildSlot 占用一个调试编译槽位。槽位已满时向前端发送等待进度并阻塞，
+// 直到有槽位释放或等待超过 debugBuildWaitTimeout。
+//
+// 返回值:
+//   - func(): 释放槽位的函数
+//   - error: 等待超时时返回错误
+func (h *DebugHandler) acquireBuildSlot(conn *websocket.Conn) (func(), error) {
+	if h.buildSlots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case h.buildSlots <- struct{}{}:
+		return h.releaseBuildSlot, nil
+	default:
+	}
+
+	ahead := h.buildQueued.Add(1) - 1
+	defer h.buildQueued.Add(-1)
+	h.sendMessage(conn, map[string]interface{}{
+		"type":    "control",
+		"event":   "progress",
+		"message": fmt.Sprintf("等待编译槽位（前面还有 %d 个调试会话排队）...", ahead),
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), debugBuildWaitTimeout)
+	defer cancel()
+	select {
+	case h.buildSlots <- struct{}{}:
+		return h.releaseBuildSlot, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for debug build slot: %w", ctx.Err())
+	}
+}
+
+// releaseBuildSlot 释放一个调试编译槽位
+func (h *DebugHandler) releaseBuildSlot() {
+	<-h.buildSlots
+}
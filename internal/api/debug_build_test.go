@@ -0,0 +1,114 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+func TestNeedsDebugBuild(t *testing.T) {
+	for runtime, want := range map[domain.Runtime]bool{
+		domain.RuntimeGo124:     true,
+		domain.RuntimeWasm:      true,
+		domain.RuntimePython311: false,
+		domain.RuntimeNodeJS20:  false,
+	} {
+		if got := needsDebugBuild(runtime); got != want {
+			t.Errorf("needsDebugBuild(%s) = %v, want %v", runtime, got, want)
+		}
+	}
+}
+
+// debugConnPair 返回一对已建立的 WebSocket 连接：服务端连接交给调试处理器发送消息，客户端连接用于读取
+func debugConnPair(t *testing.T) (server, client *websocket.Conn) {
+	t.Helper()
+	conns := make(chan *websocket.Conn, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		conns <- conn
+	}))
+	t.Cleanup(srv.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	server = <-conns
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return server, client
+}
+
+func TestAcquireBuildSlot(t *testing.T) {
+	h := &DebugHandler{logger: logrus.New()}
+	h.SetBuildConcurrency(1)
+	server, client := debugConnPair(t)
+
+	release, err := h.acquireBuildSlot(server)
+	if err != nil {
+		t.Fatalf("first acquireBuildSlot() error = %v", err)
+	}
+
+	// 槽位已满：第二个会话收到排队进度并阻塞，直到第一个会话释放槽位
+	acquired := make(chan func(), 1)
+	go func() {
+		release2, err := h.acquireBuildSlot(server)
+		if err != nil {
+			t.Errorf("second acquireBuildSlot() error = %v", err)
+			return
+		}
+		acquired <- release2
+	}()
+
+	client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var progress map[string]interface{}
+	if err := client.ReadJSON(&progress); err != nil {
+		t.Fatalf("read progress: %v", err)
+	}
+	if progress["event"] != "progress" || !strings.Contains(progress["message"].(string), "前面还有 0 个") {
+		t.Errorf("progress = %v, want queue position 0", progress)
+	}
+	select {
+	case <-acquired:
+		t.Fatal("second session acquired a slot while the first still held it")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release()
+	select {
+	case release2 := <-acquired:
+		release2()
+	case <-time.After(5 * time.Second):
+		t.Fatal("second session did not acquire the released slot")
+	}
+	if n := len(h.buildSlots); n != 0 {
+		t.Errorf("slots in use = %d, want 0", n)
+	}
+}
+
+func TestAcquireBuildSlotUnlimited(t *testing.T) {
+	h := &DebugHandler{logger: logrus.New()}
+	h.SetBuildConcurrency(0)
+
+	// 不限制时不占用槽位，也不向连接发送消息
+	for i := 0; i < 3; i++ {
+		release, err := h.acquireBuildSlot(nil)
+		if err != nil {
+			t.Fatalf("acquireBuildSlot() error = %v", err)
+		}
+		defer release()
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"os/exec"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -43,6 +44,10 @@ type DebugHandler struct {
 	// 调试容器管理
 	debugContainers   map[string]string // session_id -> container_id
 	debugContainersMu sync.RWMutex
+
+	// 调试编译并发控制，nil 表示不限制
+	buildSlots  chan struct{}
+	buildQueued atomic.Int32 // 排队等待编译槽位的会话数
 }
 
 // AgentConnectionPool 定义与 Agent 通信的接口
@@ -284,6 +289,17 @@ func (h *DebugHandler) handleControlMessage(session *debug.Session, conn *websoc
 		// Launch 模式：启动带 debugpy 的容器
 		session.SetState(debug.StateConnected)
 
+		// 需要在容器内编译的运行时先占用编译槽位，直到调试器就绪（编译完成）或启动失败
+		if needsDebugBuild(fn.Runtime) {
+			release, err := h.acquireBuildSlot(conn)
+			if err != nil {
+				h.logger.WithError(err).WithField("session_id", session.ID).Warn("Timed out waiting for debug build slot")
+				h.sendError(conn, "等待编译槽位超时，请稍后重试")
+				return
+			}
+			defer release()
+		}
+
 		// 启动调试容器
 		debugPort, err := h.launchDebugContainer(session.ID, fn, ctrl.Payload, ctrl.StopOnEntry)
 		if err != nil {
@@ -740,6 +756,15 @@ func (h *DebugHandler) GetSessionManager() *debug.Manager {
 	return h.sessionMgr
 }
 
+// debugEntryPoint 返回调试容器中写入用户代码的入口文件（不含扩展名）和处理函数名。
+// 入口点格式无效的历史函数回退到 handler 文件中的 handler 函数。
+func debugEntryPoint(fn *domain.Function) (string, string) {
+	if domain.ValidateHandler(fn.Runtime, fn.Handler) != nil {
+		return domain.DefaultHandlerFile, "handler"
+	}
+	return domain.ParseHandler(fn.Handler)
+}
+
 // launchDebugContainer 启动带调试器的容器
 func (h *DebugHandler) launchDebugContainer(sessionID string, fn *domain.Function, payload json.RawMessage, stopOnEntry bool) (int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
@@ -763,18 +788,21 @@ func (h *DebugHandler) launchDebugContainer(sessionID string, fn *domain.Functio
 		imageName = "function-runtime-python-debug:latest"
 		containerDebugPort = 5678
 		// 使用 python -m debugpy 命令行模式启动调试
-		// 将用户代码写入 handler.py
-		dockerCmd = fmt.Sprintf(`cat > /tmp/handler.py << 'EOFPY'
-%s
+		// 将用户代码写入 handler 指定的入口文件（如 src/main.handler -> /tmp/src/main.py）
+		entryFile, symbol := debugEntryPoint(fn)
+		dockerCmd = fmt.Sprintf(`mkdir -p "$(dirname /tmp/%[1]s.py)"
+cat > /tmp/%[1]s.py << 'EOFPY'
+%[3]s
 EOFPY
 
 cat > /tmp/debug_runner.py << 'EOFDEBUG'
+import importlib
 import sys
 import json
 import os
 sys.path.insert(0, '/tmp')
 
-from handler import handler
+handler = getattr(importlib.import_module('%[4]s'), '%[2]s')
 
 input_json = os.environ.get('FUNCTION_INPUT', '{}')
 try:
@@ -786,7 +814,7 @@ result = handler(input_data)
 print(json.dumps(result))
 EOFDEBUG
 
-python -m debugpy --listen 0.0.0.0:5678 --wait-for-client /tmp/debug_runner.py`, fn.Code)
+python -m debugpy --listen 0.0.0.0:5678 --wait-for-client /tmp/debug_runner.py`, entryFile, symbol, fn.Code, strings.ReplaceAll(entryFile, "/", "."))
 		envVars = []string{
 			fmt.Sprintf("FUNCTION_INPUT=%s", string(payload)),
 			"PYTHONUNBUFFERED=1",
@@ -797,20 +825,22 @@ python -m debugpy --listen 0.0.0.0:5678 --wait-for-client /tmp/debug_runner.py`,
 		imageName = "function-runtime-nodejs-debug:latest"
 		containerDebugPort = 9229
 		// 使用自定义的 DAP-to-CDP 桥接服务器
-		// 1. 将用户代码写入 handler.js
+		// 1. 将用户代码写入 handler 指定的入口文件（如 src/index.handler -> /tmp/src/index.js）
 		// 2. 创建运行器脚本
 		// 3. 启动 DAP 服务器
-		dockerCmd = fmt.Sprintf(`cat > /tmp/handler.js << 'EOFJS'
-%s
+		entryFile, symbol := debugEntryPoint(fn)
+		dockerCmd = fmt.Sprintf(`mkdir -p "$(dirname /tmp/%[1]s.js)"
+cat > /tmp/%[1]s.js << 'EOFJS'
+%[3]s
 EOFJS
 
 cat > /tmp/debug_runner.js << 'EOFDEBUG'
-const handler = require('/tmp/handler.js');
+const handler = require('/tmp/%[1]s.js');
 const inputJson = process.env.FUNCTION_INPUT || '{}';
 let inputData = {};
 try { inputData = JSON.parse(inputJson); } catch(e) {}
 
-const fn = handler.handler || handler.default || handler;
+const fn = handler['%[2]s'] || handler.default || handler;
 if (typeof fn === 'function') {
     Promise.resolve(fn(inputData)).then(r => console.log(JSON.stringify(r)))
     .catch(e => { console.error(JSON.stringify({error: e.message})); process.exit(1); });
@@ -821,7 +851,7 @@ if (typeof fn === 'function') {
 EOFDEBUG
 
 # 启动 DAP 服务器
-DAP_PORT=%d node /opt/dap-server/node-dap-server.js`, fn.Code, containerDebugPort)
+DAP_PORT=%[4]d node /opt/dap-server/node-dap-server.js`, entryFile, symbol, fn.Code, containerDebugPort)
 		envVars = []string{
 			fmt.Sprintf("FUNCTION_INPUT=%s", string(payload)),
 		}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// checkDeprecation 为已弃用函数的调用设置 Deprecation（RFC 9745）和 Sunset（RFC 8594）响应头，
+// 并记录警告日志，方便运维人员找出仍在调用弃用函数的客户端。
+// 函数已过下线时间且配置为拒绝调用时写入 410 响应并返回 false；未弃用或允许调用时返回 true。
+func (h *Handler) checkDeprecation(w http.ResponseWriter, r *http.Request, fn *domain.Function, method string) bool {
+	d := fn.Deprecation
+	if d == nil {
+		return true
+	}
+
+	w.Header().Set("Deprecation", "@"+strconv.FormatInt(d.DeprecatedAt.Unix(), 10))
+	if d.SunsetAt != nil {
+		w.Header().Set("Sunset", d.SunsetAt.UTC().Format(http.TimeFormat))
+	}
+
+	now := time.Now()
+	fields := logrus.Fields{
+		"function":   fn.Name,
+		"user_agent": r.UserAgent(),
+		"remote":     r.RemoteAddr,
+	}
+	if d.SunsetAt != nil {
+		fields["sunset_at"] = d.SunsetAt.Format(time.RFC3339)
+	}
+
+	if d.Rejects(now) {
+		h.logWarn(r, method, "函数已过下线时间，拒绝调用", fields)
+		writeJSON(w, http.StatusGone, map[string]interface{}{
+			"error":      domain.ErrFunctionSunset.Error(),
+			"message":    d.Message,
+			"sunset_at":  d.SunsetAt,
+			"request_id": middleware.GetReqID(r.Context()),
+		})
+		return false
+	}
+
+	h.logWarn(r, method, "调用已弃用的函数", fields)
+	return true
+}
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+const (
+	// dlqRetryBackoffBase 是批量重试中调用失败后的初始退避时间，连续失败时翻倍
+	dlqRetryBackoffBase = 200 * time.Millisecond
+	// dlqRetryBackoffMax 是批量重试退避时间的上限
+	dlqRetryBackoffMax = 5 * time.Second
+)
+
+// BulkRetryDLQMessages 按函数、状态和时间范围批量重试死信消息，用于修复问题后恢复积压的失败调用。
+// HTTP端点: POST /api/v1/dlq/bulk-retry
+//
+// 消息按进入队列的时间正序以有限并发重试：成功的消息标记为 resolved，失败的消息增加重试计数并保持 pending。
+// 每个工作协程在调用失败后按指数退避等待，避免对刚恢复的下游造成冲击。
+// 请求必须包含 "confirm": true，否则返回 400 和符合条件的消息数，便于先确认影响范围。
+//
+// 返回值：
+//   - 200: 成功，返回重试汇总
+//   - 400: 条件无效或未确认
+//   - 404: 指定的函数不存在
+func (h *Handler) BulkRetryDLQMessages(w http.ResponseWriter, r *http.Request) {
+	var req domain.DLQBulkRetryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if err := req.Normalize(); err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.FunctionID != "" {
+		fn, err := h.store.GetFunctionByID(req.FunctionID)
+		if errors.Is(err, domain.ErrFunctionNotFound) {
+			fn, err = h.store.GetFunctionByName(req.FunctionID)
+		}
+		if errors.Is(err, domain.ErrFunctionNotFound) {
+			writeErrorWithContext(w, r, http.StatusNotFound, "function not found: "+req.FunctionID)
+			return
+		}
+		if err != nil {
+			writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to get function: "+err.Error())
+			return
+		}
+		req.FunctionID = fn.ID
+	}
+
+	messages, matched, err := h.store.ListDLQMessagesForRetry(&req)
+	if err != nil {
+		h.logError(r, "BulkRetryDLQMessages", "查询死信消息失败", err, logrus.Fields{"function_id": req.FunctionID})
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to list DLQ messages: "+err.Error())
+		return
+	}
+
+	if !req.Confirm {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":      "bulk retry requires \"confirm\": true",
+			"matched":    matched,
+			"limit":      req.Limit,
+			"request_id": middleware.GetReqID(r.Context()),
+		})
+		return
+	}
+
+	h.logInfo(r, "BulkRetryDLQMessages", "开始批量重试死信消息", logrus.Fields{
+		"function_id": req.FunctionID,
+		"status":      req.Status,
+		"matched":     matched,
+		"batch":       len(messages),
+		"concurrency": req.Concurrency,
+	})
+
+	result := h.bulkRetryDLQ(r.Context(), messages, req.Concurrency)
+	result.Matched = matched
+	result.Remaining = matched - len(messages)
+
+	h.auditLog(r, "dlq_bulk_retry", "dlq", req.FunctionID, "", map[string]interface{}{
+		"status":   req.Status,
+		"matched":  result.Matched,
+		"resolved": result.Resolved,
+		"failed":   result.Failed,
+		"skipped":  result.Skipped,
+	})
+	h.logInfo(r, "BulkRetryDLQMessages", "批量重试完成", logrus.Fields{
+		"attempted": result.Attempted,
+		"resolved":  result.Resolved,
+		"failed":    result.Failed,
+		"skipped":   result.Skipped,
+		"remaining": result.Remaining,
+	})
+	writeJSON(w, http.StatusOK, result)
+}
+
+// bulkRetryDLQ 以 concurrency 个工作协程重试消息并汇总结果。
+// ctx 取消后不再发起新的调用，剩余消息计为跳过。
+func (h *Handler) bulkRetryDLQ(ctx context.Context, messages []*domain.DeadLetterMessage, concurrency int) *domain.DLQBulkRetryResult {
+	result := &domain.DLQBulkRetryResult{}
+	var mu sync.Mutex
+	fail := func(msg *domain.DeadLetterMessage, reason string, skipped bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if skipped {
+			result.Skipped++
+		} else {
+			result.Failed++
+		}
+		if len(result.Failures) < domain.MaxDLQBulkRetryFailures {
+			result.Failures = append(result.Failures, domain.DLQRetryFailure{ID: msg.ID, FunctionID: msg.FunctionID, Error: reason})
+		}
+	}
+
+	// 同一批消息通常属于少数几个函数，缓存函数查询结果
+	var fnMu sync.Mutex
+	functions := make(map[string]*domain.Function)
+	lookup := func(id string) (*domain.Function, error) {
+		fnMu.Lock()
+		defer fnMu.Unlock()
+		if fn, ok := functions[id]; ok {
+			return fn, nil
+		}
+		fn, err := h.store.GetFunctionByID(id)
+		if err != nil {
+			return nil, err
+		}
+		functions[id] = fn
+		return fn, nil
+	}
+
+	queue := make(chan *domain.DeadLetterMessage)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			backoff := time.Duration(0)
+			for msg := range queue {
+				if backoff > 0 {
+					select {
+					case <-ctx.Done():
+					case <-time.After(backoff):
+					}
+				}
+				if ctx.Err() != nil {
+					fail(msg, "request canceled", true)
+					continue
+				}
+
+				fn, err := lookup(msg.FunctionID)
+				if err != nil {
+					fail(msg, "function not found (may have been deleted)", true)
+					continue
+				}
+				if !fn.Status.CanInvoke() {
+					fail(msg, "function is not active: "+string(fn.Status), true)
+					continue
+				}
+
+				mu.Lock()
+				result.Attempted++
+				mu.Unlock()
+
+				if reason := h.retryDLQMessage(msg, fn); reason != "" {
+					fail(msg, reason, false)
+					if backoff == 0 {
+						backoff = dlqRetryBackoffBase
+					} else if backoff *= 2; backoff > dlqRetryBackoffMax {
+						backoff = dlqRetryBackoffMax
+					}
+					continue
+				}
+				backoff = 0
+				mu.Lock()
+				result.Resolved++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, msg := range messages {
+		queue <- msg
+	}
+	close(queue)
+	wg.Wait()
+	return result
+}
+
+// retryDLQMessage 以原始载荷重新调用函数并更新消息状态：成功时标记为 resolved，失败时增加重试计数并恢复为 pending。
+//
+// 返回值:
+//   - string: 失败原因，成功时为空
+func (h *Handler) retryDLQMessage(msg *domain.DeadLetterMessage, fn *domain.Function) string {
+	now := time.Now()
+	msg.Status = domain.DLQStatusRetrying
+	msg.LastRetryAt = &now
+	msg.RetryCount++
+	h.store.UpdateDLQMessage(msg)
+
+	resp, err := h.scheduler.Invoke(&domain.InvokeRequest{
+		FunctionID: fn.ID,
+		Payload:    msg.Payload,
+	})
+	reason := ""
+	switch {
+	case err != nil:
+		reason = err.Error()
+	case resp.Error != "":
+		reason = resp.Error
+	}
+
+	if reason != "" {
+		msg.Status = domain.DLQStatusPending
+		msg.Error = reason
+	} else {
+		resolvedAt := time.Now()
+		msg.Status = domain.DLQStatusResolved
+		msg.ResolvedAt = &resolvedAt
+	}
+	h.store.UpdateDLQMessage(msg)
+	return reason
+}
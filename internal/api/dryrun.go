@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// dryRunInvoke 处理 dry_run=true 的调用请求，返回调度预演结果。
+// 预演经过维护窗口、限流、并发槽位和执行环境池的检查，但不创建调用记录、
+// 不消耗限流令牌、不占用执行环境，也不执行函数。
+func (h *Handler) dryRunInvoke(w http.ResponseWriter, r *http.Request, fn *domain.Function) {
+	runner, ok := h.scheduler.(DryRunner)
+	if !ok {
+		writeErrorWithContext(w, r, http.StatusNotImplemented, "scheduler does not support dry run")
+		return
+	}
+
+	result, err := runner.DryRun(&domain.InvokeRequest{FunctionID: fn.ID})
+	if err != nil {
+		h.logError(r, "dryRunInvoke", "调度预演失败", err, logrus.Fields{"function": fn.Name})
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to dry run invocation: "+err.Error())
+		return
+	}
+
+	// 限流按请求身份区分令牌桶，在 API 层查询；维护窗口优先于限流
+	if fn.RateLimit != nil && h.redis != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), rateLimitCheckTimeout)
+		status, err := h.redis.PeekRateLimitToken(ctx, rateLimitKey(r, fn), fn.RateLimit)
+		cancel()
+		if err != nil {
+			h.logWarn(r, "dryRunInvoke", "查询限流状态失败", logrus.Fields{
+				"function_id": fn.ID,
+				"error":       err.Error(),
+			})
+		} else {
+			result.RateLimit = status
+			if !status.Allowed && result.Throttle != domain.DryRunThrottleMaintenance {
+				result.Throttle = domain.DryRunThrottleRateLimit
+				result.WouldExecute = false
+				result.ColdStart = false
+			}
+		}
+	}
+
+	h.logDebug(r, "dryRunInvoke", "调度预演完成", logrus.Fields{
+		"function":      fn.Name,
+		"would_execute": result.WouldExecute,
+		"throttle":      result.Throttle,
+	})
+	writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// checkAllowedEnvironments 校验函数允许调用环境列表中的环境均已创建，且各环境的运行时策略允许函数的运行时。
+// 存在未知环境或运行时被禁止时写入 400 响应并返回 false。
+func (h *Handler) checkAllowedEnvironments(w http.ResponseWriter, r *http.Request, names []string, runtime domain.Runtime) bool {
+	for _, name := range names {
+		if _, err := h.store.GetEnvironmentByName(name); err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, "environment not found: "+name)
+			return false
+		}
+		if !h.checkRuntimePolicy(w, r, name, runtime, http.StatusBadRequest) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkDeployEnvironment 在创建或更新函数时，若请求通过 X-Nimbus-Environment 请求头或 env 查询参数指定了环境，
+// 检查该环境的运行时策略允许函数的运行时。未指定环境时不做检查；不允许时写入 400 响应并返回 false。
+func (h *Handler) checkDeployEnvironment(w http.ResponseWriter, r *http.Request, runtime domain.Runtime) bool {
+	name := r.Header.Get(domain.HeaderEnvironment)
+	if name == "" {
+		name = r.URL.Query().Get("env")
+	}
+	if name == "" {
+		return true
+	}
+	return h.checkRuntimePolicy(w, r, name, runtime, http.StatusBadRequest)
+}
+
+// checkRuntimePolicy 检查环境的运行时策略是否允许指定的运行时，环境未配置策略时允许。
+// 不允许时以 status 写入包含策略内容的响应并返回 false。
+func (h *Handler) checkRuntimePolicy(w http.ResponseWriter, r *http.Request, env string, runtime domain.Runtime, status int) bool {
+	policy, ok := h.runtimePolicies[env]
+	if !ok || policy.Allows(runtime) {
+		return true
+	}
+	h.logWarn(r, "checkRuntimePolicy", "运行时被环境策略禁止", logrus.Fields{
+		"environment": env,
+		"runtime":     runtime,
+	})
+	writeJSON(w, status, map[string]interface{}{
+		"error":       domain.ErrRuntimeNotAllowed.Error() + ": " + string(runtime) + " in " + env,
+		"environment": env,
+		"runtime":     runtime,
+		"policy":      policy,
+		"request_id":  middleware.GetReqID(r.Context()),
+	})
+	return false
+}
+
+// resolveInvokeEnvironment 解析调用请求所在的环境。
+// 优先使用 X-Nimbus-Environment 请求头，其次是 env 查询参数，都未指定时使用默认环境。
+// 指定的环境不存在时写入 400 响应并返回 false。
+func (h *Handler) resolveInvokeEnvironment(w http.ResponseWriter, r *http.Request) (string, bool) {
+	name := r.Header.Get(domain.HeaderEnvironment)
+	if name == "" {
+		name = r.URL.Query().Get("env")
+	}
+	if name == "" {
+		env, err := h.store.GetDefaultEnvironment()
+		if err != nil {
+			writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to resolve environment: "+err.Error())
+			return "", false
+		}
+		return env.Name, true
+	}
+	if _, err := h.store.GetEnvironmentByName(name); err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "environment not found: "+name)
+		return "", false
+	}
+	return name, true
+}
+
+// checkInvokeEnvironment 在调用前检查函数是否允许在请求的环境中调用，以及环境的运行时策略是否允许函数的运行时。
+// 函数未限制调用环境且没有配置运行时策略时不做解析；不允许时写入 403 响应并返回 false。
+func (h *Handler) checkInvokeEnvironment(w http.ResponseWriter, r *http.Request, fn *domain.Function) bool {
+	if len(fn.AllowedEnvironments) == 0 && len(h.runtimePolicies) == 0 {
+		return true
+	}
+	env, ok := h.resolveInvokeEnvironment(w, r)
+	if !ok {
+		return false
+	}
+	if !fn.EnvironmentAllowed(env) {
+		h.logWarn(r, "checkInvokeEnvironment", "函数不允许在该环境中调用", logrus.Fields{
+			"function":    fn.Name,
+			"environment": env,
+			"allowed":     fn.AllowedEnvironments,
+		})
+		writeErrorWithContext(w, r, http.StatusForbidden, domain.ErrEnvironmentNotAllowed.Error()+": "+env)
+		return false
+	}
+	return h.checkRuntimePolicy(w, r, env, fn.Runtime, http.StatusForbidden)
+}
+
+// resolveEnvConfig 解析调用请求通过 X-Nimbus-Environment 请求头或 env 查询参数指定的环境，
+// 返回函数在该环境下的配置，调度器据此覆盖函数的内存、超时和环境变量。
+// 未指定环境时返回 nil；函数在该环境下没有配置时返回不含覆盖的配置。
+// 指定的环境不存在时写入 400 响应并返回 false。
+func (h *Handler) resolveEnvConfig(w http.ResponseWriter, r *http.Request, fn *domain.Function) (*domain.FunctionEnvConfig, bool) {
+	name := r.Header.Get(domain.HeaderEnvironment)
+	if name == "" {
+		name = r.URL.Query().Get("env")
+	}
+	if name == "" {
+		return nil, true
+	}
+	env, err := h.store.GetEnvironmentByName(name)
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "environment not found: "+name)
+		return nil, false
+	}
+	cfg, err := h.store.GetFunctionEnvConfig(fn.ID, env.ID)
+	if err != nil {
+		// 函数在该环境下没有配置时使用函数自身的配置
+		cfg = &domain.FunctionEnvConfig{FunctionID: fn.ID, EnvironmentID: env.ID, EnvironmentName: env.Name}
+	}
+	return cfg, true
+}
+
+// resolveEnvAlias 在调用方未指定别名或槽位时使用环境配置的 active_alias。
+// 环境配置的别名不存在时写入 404 响应并返回 false。
+func (h *Handler) resolveEnvAlias(w http.ResponseWriter, r *http.Request, fn *domain.Function, alias string, cfg *domain.FunctionEnvConfig) (string, bool) {
+	if alias != "" || cfg == nil || cfg.ActiveAlias == "" {
+		return alias, true
+	}
+	if _, err := h.store.GetFunctionAlias(fn.ID, cfg.ActiveAlias); err != nil {
+		writeErrorWithContext(w, r, http.StatusNotFound, "alias "+cfg.ActiveAlias+" configured for environment "+cfg.EnvironmentName+" not found")
+		return "", false
+	}
+	return cfg.ActiveAlias, true
+}
+
+// setEffectiveConfigHeader 在请求携带 X-Nimbus-Debug: 1 且指定了环境时，
+// 通过 X-Nimbus-Effective-Config 响应头返回按环境覆盖后生效的配置（不含环境变量取值）。
+func setEffectiveConfigHeader(w http.ResponseWriter, r *http.Request, fn *domain.Function, cfg *domain.FunctionEnvConfig, alias string) {
+	if cfg == nil || r.Header.Get(domain.HeaderDebug) != "1" {
+		return
+	}
+	data, err := json.Marshal(domain.NewEffectiveInvokeConfig(fn, cfg, alias))
+	if err != nil {
+		return
+	}
+	w.Header().Set(domain.HeaderEffectiveConfig, string(data))
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// execLogsHeartbeat 是实时输出流的心跳间隔，避免代理因长时间无数据断开连接
+const execLogsHeartbeat = 15 * time.Second
+
+// StreamExecLogs 以 Server-Sent Events 实时推送执行中调用的容器原始输出。
+// HTTP端点: GET /api/v1/functions/{id}/exec-logs/{requestId}/stream
+//
+// 事件：
+//   - output: 一段 stdout/stderr 输出，数据为 domain.ExecOutputChunk
+//   - end: 调用执行结束
+//
+// 调用不属于该函数时返回 404；调用已结束时返回 409；
+// 调用尚未开始执行或不在本节点执行时返回 404。
+func (h *Handler) StreamExecLogs(w http.ResponseWriter, r *http.Request) {
+	fn, ok := h.lookupFunction(w, r)
+	if !ok {
+		return
+	}
+	requestID := chi.URLParam(r, "requestId")
+
+	inv, err := h.store.GetInvocationByID(requestID)
+	if err != nil || inv.FunctionID != fn.ID {
+		writeErrorWithContext(w, r, http.StatusNotFound, "invocation not found: "+requestID)
+		return
+	}
+	if inv.Status != domain.InvocationStatusPending && inv.Status != domain.InvocationStatusRunning {
+		writeErrorWithContext(w, r, http.StatusConflict, "invocation already finished with status "+string(inv.Status))
+		return
+	}
+
+	streamer, ok := h.scheduler.(ExecOutputStreamer)
+	if !ok {
+		writeErrorWithContext(w, r, http.StatusNotImplemented, "live output streaming is not supported by this scheduler")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	chunks, cancel, err := streamer.SubscribeExecOutput(requestID)
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	defer cancel()
+
+	h.logInfo(r, "StreamExecLogs", "开始推送调用实时输出", logrus.Fields{"function": fn.Name, "request_id": requestID})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(execLogsHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case chunk, ok := <-chunks:
+			if !ok {
+				fmt.Fprint(w, "event: end\ndata: {}\n\n")
+				flusher.Flush()
+				return
+			}
+			data, _ := json.Marshal(chunk)
+			fmt.Fprintf(w, "event: output\ndata: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
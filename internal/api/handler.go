@@ -15,17 +15,22 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/google/uuid"
+	"github.com/oriys/nimbus/internal/auth"
 	"github.com/oriys/nimbus/internal/compiler"
 	"github.com/oriys/nimbus/internal/domain"
+	"github.com/oriys/nimbus/internal/outbound"
 	"github.com/oriys/nimbus/internal/scheduler"
 	"github.com/oriys/nimbus/internal/storage"
 	"github.com/sirupsen/logrus"
@@ -48,6 +53,28 @@ type Handler struct {
 	compiler    *compiler.Compiler
 	cronManager *scheduler.CronManager
 	logger      *logrus.Logger
+	builds      buildRegistry     // 本实例上正在执行的编译任务，用于取消编译
+	outbound    *outbound.Client  // 出站通知客户端，用于告警通知等外部投递
+	admission   *admissionWebhook // 函数配置准入 Webhook，nil 表示不启用
+	wsConns     wsRegistry        // 本实例上的函数调用 WebSocket 连接
+	safeMode    bool              // 安全模式，Webhook 返回 503
+
+	maxLayers         int   // 单个函数最多挂载的层数，<= 0 表示不限制
+	maxLayersUnpacked int64 // 单个函数挂载的层解压总大小上限（字节），<= 0 表示不限制
+
+	debugBuildLimit int // 同时进行的调试容器编译数上限，<= 0 表示不限制
+
+	handlerChecks map[string]string // 按运行时的部署时入口函数检查模式，nil 表示使用默认模式
+
+	maxPayloadBytes int64 // 调用请求体的全局大小上限，函数未单独设置时使用，<= 0 表示使用 defaultMaxPayloadBytes
+
+	allowedOrigins []string // 允许跨域访问的来源，为空时 CORS 允许所有来源、WebSocket 只允许同源页面
+
+	runtimePolicies map[string]domain.RuntimePolicy // 按环境名称索引的运行时准入策略
+
+	billingRates domain.BillingRates // 计费单价，用于函数计费汇总
+
+	platformLimits domain.PlatformLimits // 平台级限制，用于展示函数的生效限制
 }
 
 // Scheduler 定义了函数调度器的接口。
@@ -68,6 +95,99 @@ type Scheduler interface {
 	InvokeAsync(req *domain.InvokeRequest) (string, error)
 }
 
+// WorkerScaler 定义了支持运行时调整工作协程数量的调度器接口（可选实现）。
+type WorkerScaler interface {
+	// ScaleWorkers 将工作协程数量调整为 n
+	ScaleWorkers(n int) error
+	// WorkerStats 返回工作协程池和队列的当前状态
+	WorkerStats() scheduler.WorkerStats
+}
+
+// ScalingReporter 定义了能够导出扩缩容信号的调度器接口（可选实现）。
+type ScalingReporter interface {
+	// ScalingMetrics 返回排队与执行中的调用数及其按运行时的分布
+	ScalingMetrics() scheduler.ScalingMetrics
+}
+
+// ImageReporter 定义了能够报告运行时镜像可用性的调度器接口（可选实现）。
+type ImageReporter interface {
+	// MissingImages 返回本地缺失的运行时镜像（运行时 -> 镜像）以及已配置的运行时总数
+	MissingImages() (map[string]string, int)
+}
+
+// DryRunner 定义了支持调度预演的调度器接口（可选实现）。
+type DryRunner interface {
+	// DryRun 预演一次调用的调度过程，不执行函数
+	DryRun(req *domain.InvokeRequest) (*domain.DryRunResult, error)
+}
+
+// PausedResumer 定义了能够排空函数暂停队列的调度器接口（可选实现）。
+type PausedResumer interface {
+	// ResumePaused 在后台按入队顺序提交函数暂停期间积压的异步调用
+	ResumePaused(functionID string)
+}
+
+// RuntimeImageLister 定义了能够列出运行时镜像的调度器接口（可选实现）。
+type RuntimeImageLister interface {
+	// RuntimeImages 返回各运行时配置的镜像（运行时 -> 镜像）
+	RuntimeImages() map[string]string
+}
+
+// DataVolumeLister 定义了能够列出已注册共享数据卷的调度器接口（可选实现）。
+// 不实现该接口的调度器不支持挂载数据卷。
+type DataVolumeLister interface {
+	// DataVolumes 返回运维方注册的共享数据卷名称
+	DataVolumes() []string
+}
+
+// CustomImageChecker 定义了能够检查自定义镜像白名单的调度器接口（可选实现）。
+// 不实现该接口的调度器不支持自定义镜像。
+type CustomImageChecker interface {
+	// CustomImageAllowed 检查镜像是否在运维方配置的白名单内
+	CustomImageAllowed(image string) bool
+}
+
+// Diagnoser 定义了支持执行环境诊断的调度器接口（可选实现）。
+type Diagnoser interface {
+	// Diagnose 在函数的执行容器中运行内置探针，返回实际执行环境信息
+	Diagnose(ctx context.Context, fn *domain.Function) (*domain.FunctionDiagnostics, error)
+}
+
+// AliasCacheInvalidator 定义了缓存别名路由配置的调度器接口（可选实现）。
+// 别名修改或蓝绿切换后调用，使新配置立即生效。
+type AliasCacheInvalidator interface {
+	// InvalidateAliases 使函数所有别名的路由缓存失效
+	InvalidateAliases(functionID string)
+}
+
+// ShadowConfigInvalidator 定义了缓存影子流量配置的调度器接口（可选实现）。
+// 影子配置修改或删除后调用，使新配置立即生效。
+type ShadowConfigInvalidator interface {
+	// InvalidateShadowConfig 使函数的影子流量配置缓存失效
+	InvalidateShadowConfig(functionID string)
+}
+
+// InvokeGate 定义了能够在不执行函数的情况下检查调用闸门的调度器接口（可选实现）。
+// 命中响应缓存的调用不经过调度器执行，返回缓存前通过该接口检查。
+type InvokeGate interface {
+	// CheckInvoke 检查紧急停止开关、维护窗口和最大并发数，不允许调用时返回对应错误
+	CheckInvoke(fn *domain.Function) error
+}
+
+// WarmProvisioner 定义了支持预置常驻预热实例的调度器接口（可选实现）。
+type WarmProvisioner interface {
+	// ReconcileKeepWarm 立即按函数的 keep_warm 配置协调常驻预热实例，不等待协调完成
+	ReconcileKeepWarm()
+	// ProvisionStats 返回各规格执行环境池的常驻与按需预热实例数
+	ProvisionStats() ([]domain.ProvisionedPool, error)
+}
+
+// ExecOutputStreamer 定义了支持订阅调用实时容器输出的调度器接口（可选实现）。
+type ExecOutputStreamer interface {
+	// SubscribeExecOutput 订阅执行中调用的 stdout/stderr，调用结束时关闭通道
+	SubscribeExecOutput(requestID string) (<-chan domain.ExecOutputChunk, func(), error)
+}
+
 // NewHandler 创建并返回一个新的Handler实例。
 //
 // 参数：
@@ -87,9 +207,67 @@ func NewHandler(store *storage.PostgresStore, redis *storage.RedisStore, schedul
 		compiler:    compiler.NewCompiler(),
 		cronManager: cronManager,
 		logger:      logger,
+		outbound:    outbound.New(outbound.DefaultOptions(), nil, logger),
+
+		maxLayers:         domain.DefaultMaxLayersPerFunction,
+		maxLayersUnpacked: domain.DefaultMaxLayersUnpackedMB << 20,
+		debugBuildLimit:   defaultDebugBuildConcurrency,
 	}
 }
 
+// SetOutboundClient 设置出站通知客户端，替换默认配置的客户端。
+func (h *Handler) SetOutboundClient(client *outbound.Client) {
+	h.outbound = client
+}
+
+// SetBuildLimits 设置按运行时的并发编译上限，需在处理请求和恢复编译任务之前调用。
+//
+// 参数：
+//   - defaultLimit: 未单独配置的运行时的上限，<= 0 表示不限制
+//   - limits: 按运行时覆盖的上限
+func (h *Handler) SetBuildLimits(defaultLimit int, limits map[string]int) {
+	h.compiler.SetBuildLimits(defaultLimit, limits)
+}
+
+// SetDebugBuildLimit 设置同时进行的调试容器编译数上限（Go、Rust 调试会话启动时在容器内编译），
+// 需在创建路由之前调用。
+//
+// 参数：
+//   - limit: 并发上限，<= 0 表示不限制
+func (h *Handler) SetDebugBuildLimit(limit int) {
+	h.debugBuildLimit = limit
+}
+
+// SetCompileCache 设置编译缓存的有效期和内存上限，需在处理请求之前调用。
+//
+// 参数：
+//   - ttl: 缓存条目的有效期，<= 0 表示禁用编译缓存
+//   - maxMB: 缓存占用内存的上限（MB），<= 0 表示禁用编译缓存
+func (h *Handler) SetCompileCache(ttl time.Duration, maxMB int) {
+	h.compiler.SetCache(ttl, int64(maxMB)<<20)
+}
+
+// SetSafeMode 设置安全模式。安全模式下 Webhook 返回 503，就绪探针响应中附带 safe_mode 标记。
+func (h *Handler) SetSafeMode(enabled bool) {
+	h.safeMode = enabled
+}
+
+// SetRuntimePolicies 设置按环境的运行时准入策略，需在处理请求之前调用。
+//
+// 参数：
+//   - policies: 按环境名称索引的运行时策略，未配置的环境不限制
+func (h *Handler) SetRuntimePolicies(policies map[string]domain.RuntimePolicy) {
+	h.runtimePolicies = policies
+}
+
+// SetPlatformLimits 设置平台级限制，用于在函数详情中展示生效限制，需在处理请求之前调用。
+//
+// 参数：
+//   - limits: 平台级限制，WebSocket 消息大小由网关自身决定，无需设置
+func (h *Handler) SetPlatformLimits(limits domain.PlatformLimits) {
+	h.platformLimits = limits
+}
+
 // RecoverPendingCompileTasks 恢复未完成的编译任务
 // 在服务启动时调用，检查并重新触发所有处于 creating/updating/building 状态的函数编译
 func (h *Handler) RecoverPendingCompileTasks() {
@@ -138,7 +316,7 @@ func (h *Handler) RecoverPendingCompileTasks() {
 		h.store.CreateFunctionTask(task)
 
 		// 异步执行编译
-		go h.processCreateFunctionTask(fn.ID, taskID)
+		go h.processCreateFunctionTask(fn.ID, taskID, "")
 
 		h.logger.WithFields(logrus.Fields{
 			"function": fn.Name,
@@ -191,6 +369,34 @@ func (h *Handler) CreateFunction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 校验数据卷均已由运维方注册
+	if !h.checkDataVolumes(w, r, req.DataVolumes) {
+		return
+	}
+
+	// 校验自定义镜像在运维方配置的白名单内
+	if !h.checkCustomImage(w, r, req.CustomImage) {
+		return
+	}
+
+	// 校验允许调用的环境均已创建，且环境的运行时策略允许该运行时
+	if !h.checkAllowedEnvironments(w, r, req.AllowedEnvironments, req.Runtime) {
+		return
+	}
+	if !h.checkDeployEnvironment(w, r, req.Runtime) {
+		return
+	}
+
+	// 校验预留并发总和不超过调度器容量
+	if !h.checkReservedConcurrency(w, r, "", req.ReservedConcurrency) {
+		return
+	}
+
+	// 受标签作用域限制的 API Key 创建的函数自动带上选择器标签，保证创建后仍可访问
+	if user := auth.GetUser(r.Context()); user != nil && len(user.TagSelector) > 0 {
+		req.Tags = mergeTagSelector(req.Tags, user.TagSelector)
+	}
+
 	// 计算代码的SHA256哈希值，用于版本控制和变更检测
 	hash := sha256.Sum256([]byte(req.Code))
 	codeHash := hex.EncodeToString(hash[:])
@@ -200,25 +406,52 @@ func (h *Handler) CreateFunction(w http.ResponseWriter, r *http.Request) {
 
 	// 构建函数对象，初始状态为 creating
 	fn := &domain.Function{
-		Name:           req.Name,
-		Description:    req.Description,
-		Tags:           req.Tags,
-		Runtime:        req.Runtime,
-		Handler:        req.Handler,
-		Code:           req.Code,
-		Binary:         req.Binary,
-		CodeHash:       codeHash,
-		MemoryMB:       req.MemoryMB,
-		TimeoutSec:     req.TimeoutSec,
-		MaxConcurrency: req.MaxConcurrency,
-		EnvVars:        req.EnvVars,
-		CronExpression: req.CronExpression,
-		HTTPPath:       req.HTTPPath,
-		HTTPMethods:    req.HTTPMethods,
-		Status:         domain.FunctionStatusCreating,
-		StatusMessage:  "函数正在创建中",
-		TaskID:         taskID,
-		Version:        1,
+		Name:                req.Name,
+		Description:         req.Description,
+		Tags:                req.Tags,
+		Runtime:             req.Runtime,
+		Handler:             req.Handler,
+		Code:                req.Code,
+		Binary:              req.Binary,
+		CodeHash:            codeHash,
+		MemoryMB:            req.MemoryMB,
+		TimeoutSec:          req.TimeoutSec,
+		MaxConcurrency:      req.MaxConcurrency,
+		EnvVars:             req.EnvVars,
+		CronExpression:      req.CronExpression,
+		HTTPPath:            req.HTTPPath,
+		HTTPMethods:         req.HTTPMethods,
+		Status:              domain.FunctionStatusCreating,
+		StatusMessage:       "函数正在创建中",
+		ReservedConcurrency: req.ReservedConcurrency,
+		KeepWarm:            req.KeepWarm,
+		WarmupPayload:       req.WarmupPayload,
+		InitHandler:         req.InitHandler,
+		EmptyResponse:       req.EmptyResponse,
+		RateLimit:           req.RateLimit,
+		ResponseCache:       req.ResponseCache,
+		BuildEnv:            req.BuildEnv,
+		BuildArgs:           req.BuildArgs,
+		MaintenanceWindows:  req.MaintenanceWindows,
+		DataVolumes:         req.DataVolumes,
+		AllowedEnvironments: req.AllowedEnvironments,
+		VersionRetention:    req.VersionRetention,
+		MaxReuse:            req.MaxReuse,
+		StopGracePeriodSec:  req.StopGracePeriodSec,
+		MaxPayloadBytes:     req.MaxPayloadBytes,
+		CircuitBreaker:      req.CircuitBreaker,
+		CustomImage:         req.CustomImage,
+		CustomCommand:       req.CustomCommand,
+		WorkingDir:          req.WorkingDir,
+		Args:                req.Args,
+		Priority:            req.Priority,
+		TaskID:              taskID,
+		Version:             1,
+	}
+
+	// 外部策略校验拟创建的函数配置
+	if !h.checkAdmission(w, r, admissionCreate, fn, nil) {
+		return
 	}
 
 	// 保存函数到数据库（状态为 creating）
@@ -246,7 +479,7 @@ func (h *Handler) CreateFunction(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 异步处理编译任务
-	go h.processCreateFunctionTask(fn.ID, taskID)
+	go h.processCreateFunctionTask(fn.ID, taskID, "")
 
 	h.logInfo(r, "CreateFunction", "函数已创建，编译任务已提交", logrus.Fields{"name": fn.Name, "id": fn.ID, "task_id": taskID})
 
@@ -256,7 +489,13 @@ func (h *Handler) CreateFunction(w http.ResponseWriter, r *http.Request) {
 
 // processCreateFunctionTask 异步处理函数创建任务
 // 流程：源代码已在 CreateFunction 中保存 → 编译 → 更新二进制和状态
-func (h *Handler) processCreateFunctionTask(functionID, taskID string) {
+// restoreStatus 是编译被用户取消时恢复的函数状态，为空表示恢复为 failed
+func (h *Handler) processCreateFunctionTask(functionID, taskID string, restoreStatus domain.FunctionStatus) {
+	// 登记编译任务，支持通过 POST /tasks/{id}/cancel 取消
+	ctx, cancel := h.builds.start(taskID, buildTimeout)
+	defer cancel()
+	defer h.builds.finish(taskID)
+
 	// 更新任务状态为 running
 	now := time.Now()
 	h.store.UpdateFunctionTask(&domain.FunctionTask{
@@ -284,12 +523,17 @@ func (h *Handler) processCreateFunctionTask(functionID, taskID string) {
 		h.store.UpdateFunctionStatus(functionID, domain.FunctionStatusBuilding, "正在编译源代码", taskID)
 
 		// 执行编译（使用带超时的 context）
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-		defer cancel()
 		compileResp, err := h.compiler.Compile(ctx, &compiler.CompileRequest{
-			Runtime: string(fn.Runtime),
-			Code:    fn.Code,
+			Runtime:   string(fn.Runtime),
+			Code:      fn.Code,
+			Layers:    h.compileLayerHashes(fn.ID),
+			BuildEnv:  fn.BuildEnv,
+			BuildArgs: fn.BuildArgs,
 		})
+		if h.builds.finish(taskID) {
+			h.completeTaskCancelled(taskID, functionID, restoreStatus)
+			return
+		}
 		if err != nil {
 			h.completeTaskWithError(taskID, functionID, "compilation error: "+err.Error())
 			return
@@ -311,6 +555,26 @@ func (h *Handler) processCreateFunctionTask(functionID, taskID string) {
 			"task_id":     taskID,
 		}).Info("编译完成，二进制已保存")
 	}
+	if h.builds.finish(taskID) {
+		h.completeTaskCancelled(taskID, functionID, restoreStatus)
+		return
+	}
+
+	// 静态检查解释型运行时的入口函数，避免部署后第一次调用才发现入口配置错误
+	warning, err := h.checkHandlerSignature(fn)
+	if err != nil {
+		h.completeTaskWithError(taskID, functionID, "handler check failed: "+err.Error())
+		return
+	}
+	var taskOutput json.RawMessage
+	if warning != "" {
+		h.logger.WithFields(logrus.Fields{
+			"function_id": functionID,
+			"task_id":     taskID,
+			"warning":     warning,
+		}).Warn("入口函数检查未通过，继续部署")
+		taskOutput, _ = json.Marshal(map[string][]string{"warnings": {warning}})
+	}
 
 	// 更新函数状态为 active
 	if err := h.store.SetFunctionDeployed(functionID); err != nil {
@@ -332,6 +596,7 @@ func (h *Handler) processCreateFunctionTask(functionID, taskID string) {
 	h.store.UpdateFunctionTask(&domain.FunctionTask{
 		ID:          taskID,
 		Status:      domain.FunctionTaskCompleted,
+		Output:      taskOutput,
 		CompletedAt: &completedAt,
 	})
 
@@ -403,40 +668,114 @@ func (h *Handler) GetFunction(w http.ResponseWriter, r *http.Request) {
 
 	h.logDebug(r, "GetFunction", "查询成功", logrus.Fields{"function": fn.Name, "id": fn.ID})
 
+	limits, ok := h.functionLimits(w, r, fn)
+	if !ok {
+		return
+	}
+
 	// 构建响应，包含代码大小信息
 	response := map[string]interface{}{
-		"id":              fn.ID,
-		"name":            fn.Name,
-		"description":     fn.Description,
-		"tags":            fn.Tags,
-		"pinned":          fn.Pinned,
-		"runtime":         fn.Runtime,
-		"handler":         fn.Handler,
-		"code":            fn.Code,
-		"binary":          fn.Binary,
-		"code_hash":       fn.CodeHash,
-		"memory_mb":       fn.MemoryMB,
-		"timeout_sec":     fn.TimeoutSec,
-		"max_concurrency": fn.MaxConcurrency,
-		"env_vars":        fn.EnvVars,
-		"status":          fn.Status,
-		"status_message":  fn.StatusMessage,
-		"task_id":         fn.TaskID,
-		"version":         fn.Version,
-		"cron_expression": fn.CronExpression,
-		"http_path":       fn.HTTPPath,
-		"http_methods":    fn.HTTPMethods,
-		"webhook_enabled": fn.WebhookEnabled,
-		"webhook_key":     fn.WebhookKey,
-		"last_deployed_at": fn.LastDeployedAt,
-		"created_at":      fn.CreatedAt,
-		"updated_at":      fn.UpdatedAt,
-		"code_size":       len(fn.Code),
-		"code_size_limit": domain.MaxCodeSize,
+		"id":                    fn.ID,
+		"name":                  fn.Name,
+		"description":           fn.Description,
+		"tags":                  fn.Tags,
+		"pinned":                fn.Pinned,
+		"runtime":               fn.Runtime,
+		"handler":               fn.Handler,
+		"code":                  fn.Code,
+		"binary":                fn.Binary,
+		"code_hash":             fn.CodeHash,
+		"memory_mb":             fn.MemoryMB,
+		"timeout_sec":           fn.TimeoutSec,
+		"max_concurrency":       fn.MaxConcurrency,
+		"reserved_concurrency":  fn.ReservedConcurrency,
+		"keep_warm":             fn.KeepWarm,
+		"warmup_payload":        fn.WarmupPayload,
+		"deprecation":           fn.Deprecation,
+		"init_handler":          fn.InitHandler,
+		"empty_response":        fn.EmptyResponse,
+		"rate_limit":            fn.RateLimit,
+		"response_cache":        fn.ResponseCache,
+		"build_env":             fn.BuildEnv,
+		"build_args":            fn.BuildArgs,
+		"maintenance_windows":   fn.MaintenanceWindows,
+		"data_volumes":          fn.DataVolumes,
+		"allowed_environments":  fn.AllowedEnvironments,
+		"version_retention":     fn.VersionRetention,
+		"max_reuse":             fn.MaxReuse,
+		"stop_grace_period_sec": fn.StopGracePeriodSec,
+		"max_payload_bytes":     fn.MaxPayloadBytes,
+		"circuit_breaker":       fn.CircuitBreaker,
+		"custom_image":          fn.CustomImage,
+		"custom_command":        fn.CustomCommand,
+		"working_dir":           fn.WorkingDir,
+		"args":                  fn.Args,
+		"priority":              fn.Priority,
+		"live_slot":             fn.LiveSlot,
+		"env_vars":              fn.EnvVars,
+		"status":                fn.Status,
+		"status_message":        fn.StatusMessage,
+		"task_id":               fn.TaskID,
+		"version":               fn.Version,
+		"cron_expression":       fn.CronExpression,
+		"http_path":             fn.HTTPPath,
+		"http_methods":          fn.HTTPMethods,
+		"webhook_enabled":       fn.WebhookEnabled,
+		"webhook_key":           fn.WebhookKey,
+		"last_deployed_at":      fn.LastDeployedAt,
+		"created_at":            fn.CreatedAt,
+		"updated_at":            fn.UpdatedAt,
+		"code_size":             len(fn.Code),
+		"code_size_limit":       domain.MaxCodeSize,
+		"limits":                limits,
+		"in_flight":             h.functionInFlight(r, fn),
 	}
 	writeJSON(w, http.StatusOK, response)
 }
 
+// functionInFlight 返回函数当前执行中的调用数。只有设置了最大并发数的函数会跟踪执行中的调用，
+// 未设置、未配置 Redis 或查询失败时返回 nil。
+func (h *Handler) functionInFlight(r *http.Request, fn *domain.Function) *int64 {
+	if fn.MaxConcurrency <= 0 || h.redis == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 200*time.Millisecond)
+	defer cancel()
+	count, err := h.redis.CountConcurrencySlots(ctx, fn.ID)
+	if err != nil {
+		h.logWarn(r, "GetFunction", "查询执行中调用数失败", logrus.Fields{"function": fn.Name, "error": err.Error()})
+		return nil
+	}
+	return &count
+}
+
+// functionLimits 计算函数的生效限制。请求通过 X-Nimbus-Environment 请求头或 env 查询参数指定了环境时，
+// 应用函数在该环境下的内存和超时覆盖。指定的环境不存在时写入 404 响应并返回 false。
+func (h *Handler) functionLimits(w http.ResponseWriter, r *http.Request, fn *domain.Function) (*domain.FunctionLimits, bool) {
+	platform := h.platformLimits
+	platform.MaxWebSocketMessageBytes = wsMaxMessageSize
+
+	name := r.Header.Get(domain.HeaderEnvironment)
+	if name == "" {
+		name = r.URL.Query().Get("env")
+	}
+	if name == "" {
+		return fn.EffectiveLimits(platform, nil), true
+	}
+
+	env, err := h.store.GetEnvironmentByName(name)
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusNotFound, "environment not found: "+name)
+		return nil, false
+	}
+	envCfg, err := h.store.GetFunctionEnvConfig(fn.ID, env.ID)
+	if err != nil {
+		// 函数在该环境下没有配置时使用函数自身的限制
+		envCfg = &domain.FunctionEnvConfig{FunctionID: fn.ID, EnvironmentID: env.ID, EnvironmentName: env.Name}
+	}
+	return fn.EffectiveLimits(platform, envCfg), true
+}
+
 // ListFunctions 处理获取函数列表的请求。
 // HTTP端点: GET /api/v1/functions
 //
@@ -457,16 +796,7 @@ func (h *Handler) ListFunctions(w http.ResponseWriter, r *http.Request) {
 	h.logDebug(r, "ListFunctions", "查询函数列表", nil)
 
 	// 解析分页参数
-	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-
-	// 设置limit的默认值和最大值限制
-	if limit <= 0 {
-		limit = 20
-	}
-	if limit > 100 {
-		limit = 100
-	}
+	offset, limit := parsePagination(r)
 
 	// 解析筛选参数
 	filter := &domain.FunctionFilter{
@@ -479,6 +809,16 @@ func (h *Handler) ListFunctions(w http.ResponseWriter, r *http.Request) {
 	if tagsParam := r.URL.Query().Get("tags"); tagsParam != "" {
 		filter.Tags = strings.Split(tagsParam, ",")
 	}
+	// 受标签作用域限制的 API Key 只能看到带有其选择器标签的函数
+	if user := auth.GetUser(r.Context()); user != nil && len(user.TagSelector) > 0 {
+		filter.Tags = mergeTagSelector(filter.Tags, user.TagSelector)
+	}
+
+	query := r.URL.Query()
+	if query.Has("cursor") || query.Has("before") {
+		h.listFunctionsByCursor(w, r, filter, limit)
+		return
+	}
 
 	// 检查是否有筛选条件
 	hasFilter := filter.Name != "" || len(filter.Tags) > 0 || filter.Runtime != "" || filter.Status != ""
@@ -500,7 +840,25 @@ func (h *Handler) ListFunctions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 获取所有函数的基础统计（最近24小时）
+	h.logDebug(r, "ListFunctions", "查询成功", logrus.Fields{"total": total, "count": len(functions)})
+	// 返回分页结果
+	writePaginated(w, r, "functions", h.withBasicStats(r, functions), total, offset, limit)
+}
+
+// functionWithStats 是函数列表中的一项，包含最近24小时的基础统计
+type functionWithStats struct {
+	*domain.Function
+	Invocations   int64   `json:"invocations,omitempty"`
+	SuccessRate   float64 `json:"success_rate,omitempty"`
+	AvgLatencyMs  float64 `json:"avg_latency_ms,omitempty"`
+	ErrorCount    int64   `json:"error_count,omitempty"`
+	CodeSize      int     `json:"code_size"`
+	CodeSizeLimit int     `json:"code_size_limit"`
+	Deprecated    bool    `json:"deprecated,omitempty"`
+}
+
+// withBasicStats 为函数列表附加最近24小时的基础统计，统计查询失败时只返回函数信息。
+func (h *Handler) withBasicStats(r *http.Request, functions []*domain.Function) []functionWithStats {
 	stats, err := h.store.GetAllFunctionsBasicStats(24)
 	if err != nil {
 		h.logError(r, "ListFunctions", "获取函数统计失败", err, nil)
@@ -508,23 +866,13 @@ func (h *Handler) ListFunctions(w http.ResponseWriter, r *http.Request) {
 		stats = make(map[string]*storage.FunctionBasicStats)
 	}
 
-	// 构建响应，包含统计数据
-	type FunctionWithStats struct {
-		*domain.Function
-		Invocations  int64   `json:"invocations,omitempty"`
-		SuccessRate  float64 `json:"success_rate,omitempty"`
-		AvgLatencyMs float64 `json:"avg_latency_ms,omitempty"`
-		ErrorCount   int64   `json:"error_count,omitempty"`
-		CodeSize     int     `json:"code_size"`
-		CodeSizeLimit int    `json:"code_size_limit"`
-	}
-
-	functionsWithStats := make([]FunctionWithStats, len(functions))
+	result := make([]functionWithStats, len(functions))
 	for i, fn := range functions {
-		fws := FunctionWithStats{
+		fws := functionWithStats{
 			Function:      fn,
 			CodeSize:      len(fn.Code),
 			CodeSizeLimit: domain.MaxCodeSize,
+			Deprecated:    fn.Deprecation != nil,
 		}
 		if s, ok := stats[fn.ID]; ok {
 			fws.Invocations = s.Invocations
@@ -532,19 +880,161 @@ func (h *Handler) ListFunctions(w http.ResponseWriter, r *http.Request) {
 			fws.AvgLatencyMs = s.AvgLatencyMs
 			fws.ErrorCount = s.ErrorCount
 		}
-		functionsWithStats[i] = fws
+		result[i] = fws
 	}
+	return result
+}
 
-	h.logDebug(r, "ListFunctions", "查询成功", logrus.Fields{"total": total, "count": len(functions)})
-	// 返回分页结果
+// listFunctionsByCursor 以游标分页返回函数列表，由 ListFunctions 在请求包含 cursor 或 before 参数时调用。
+// 函数按创建时间倒序排列，翻页期间其他函数的创建、删除不会导致重复或遗漏。
+//
+// 查询参数：
+//   - cursor: 上一页响应中的 next_cursor，返回更早创建的函数；为空值（?cursor=）时从第一页开始
+//   - before: 上一页响应中的 prev_cursor，返回更晚创建的函数，与 cursor 互斥
+//
+// 响应中 next_cursor / prev_cursor 为空表示该方向上没有更多函数。
+func (h *Handler) listFunctionsByCursor(w http.ResponseWriter, r *http.Request, filter *domain.FunctionFilter, limit int) {
+	query := r.URL.Query()
+	if query.Get("cursor") != "" && query.Get("before") != "" {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "cursor and before are mutually exclusive")
+		return
+	}
+
+	var after, before *domain.FunctionCursor
+	var err error
+	if v := query.Get("cursor"); v != "" {
+		after, err = domain.ParseFunctionCursor(v)
+	} else if v := query.Get("before"); v != "" {
+		before, err = domain.ParseFunctionCursor(v)
+	}
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	functions, hasMore, total, err := h.store.ListFunctionsByCursor(filter, after, before, limit)
+	if err != nil {
+		h.logError(r, "ListFunctions", "游标查询函数列表失败", err, logrus.Fields{"limit": limit})
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to list functions: "+err.Error())
+		return
+	}
+
+	// hasMore 只说明翻页方向上是否还有函数：向后翻页时，带游标说明本页之前还有函数；
+	// 向前翻页时，来源页就在本页之后
+	hasNext, hasPrev := hasMore, after != nil
+	if before != nil {
+		hasNext, hasPrev = true, hasMore
+	}
+	nextCursor, prevCursor := "", ""
+	if len(functions) > 0 {
+		if hasNext {
+			nextCursor = domain.NewFunctionCursor(functions[len(functions)-1]).Encode()
+		}
+		if hasPrev {
+			prevCursor = domain.NewFunctionCursor(functions[0]).Encode()
+		}
+	}
+
+	h.logDebug(r, "ListFunctions", "游标查询成功", logrus.Fields{"total": total, "count": len(functions)})
 	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"functions": functionsWithStats,
-		"total":     total,
-		"offset":    offset,
-		"limit":     limit,
+		"functions":   h.withBasicStats(r, functions),
+		"total":       total,
+		"limit":       limit,
+		"has_more":    nextCursor != "",
+		"next_cursor": nextCursor,
+		"prev_cursor": prevCursor,
 	})
 }
 
+const (
+	// defaultSearchContextLines 代码搜索命中默认附带的上下文行数
+	defaultSearchContextLines = 2
+	// maxSearchContextLines 代码搜索命中附带的上下文行数上限
+	maxSearchContextLines = 10
+)
+
+// SearchFunctions 处理跨函数代码搜索的请求。
+// HTTP端点: GET /api/v1/functions/search
+//
+// 功能说明：
+//   - 在所有函数的代码中搜索字符串（大小写不敏感），用于下线共享依赖前的影响分析
+//   - 返回每个函数的命中行及其上下文
+//
+// 查询参数：
+//   - q: 搜索字符串（必填）
+//   - include: 额外搜索的字段，逗号分隔，可选 handler、env
+//   - context: 每处命中附带的上下文行数（默认 2，最大 10）
+//   - tags / runtime: 限定搜索范围
+//   - offset / limit: 分页参数，按函数分页
+//
+// 返回值：
+//   - 200: 成功，返回 results 列表及分页信息
+//   - 400: 缺少搜索字符串
+func (h *Handler) SearchFunctions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := &domain.CodeSearchQuery{
+		Query:   strings.TrimSpace(query.Get("q")),
+		Runtime: domain.Runtime(query.Get("runtime")),
+	}
+	if q.Query == "" {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "search query (q) required")
+		return
+	}
+	for _, field := range strings.Split(query.Get("include"), ",") {
+		switch strings.TrimSpace(field) {
+		case "handler":
+			q.IncludeHandler = true
+		case "env":
+			q.IncludeEnv = true
+		}
+	}
+	if tagsParam := query.Get("tags"); tagsParam != "" {
+		q.Tags = strings.Split(tagsParam, ",")
+	}
+	// 受标签作用域限制的 API Key 只能搜索带有其选择器标签的函数
+	if user := auth.GetUser(r.Context()); user != nil && len(user.TagSelector) > 0 {
+		q.Tags = mergeTagSelector(q.Tags, user.TagSelector)
+	}
+
+	contextLines := defaultSearchContextLines
+	if v := query.Get("context"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeErrorWithContext(w, r, http.StatusBadRequest, "context must be a non-negative integer")
+			return
+		}
+		contextLines = min(n, maxSearchContextLines)
+	}
+
+	offset, limit := parsePagination(r)
+	functions, total, err := h.store.SearchFunctions(q, offset, limit)
+	if err != nil {
+		h.logError(r, "SearchFunctions", "搜索函数代码失败", err, logrus.Fields{"q": q.Query})
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to search functions")
+		return
+	}
+
+	results := make([]*domain.CodeSearchResult, 0, len(functions))
+	for _, fn := range functions {
+		results = append(results, domain.FindCodeMatches(fn, q, contextLines))
+	}
+
+	h.logDebug(r, "SearchFunctions", "搜索完成", logrus.Fields{"q": q.Query, "total": total})
+	writePaginated(w, r, "results", results, total, offset, limit)
+}
+
+// UpdateFunctionResponse 是更新函数接口的响应结构。
+// 在函数完整信息的基础上附加本次更新的字段差异和是否触发重新编译。
+type UpdateFunctionResponse struct {
+	*domain.Function
+	// Changes 本次更新中发生变化的字段及其新旧值
+	Changes map[string]domain.FieldChange `json:"changes"`
+	// RecompileTriggered 本次更新是否触发了异步重新编译
+	RecompileTriggered bool `json:"recompile_triggered"`
+	// Warnings 不阻止更新的检查警告，如 warn 模式下的入口函数检查结果
+	Warnings []string `json:"warnings,omitempty"`
+}
+
 // UpdateFunction 处理更新函数配置的请求。
 // HTTP端点: PUT /api/v1/functions/{id}
 //
@@ -606,6 +1096,9 @@ func (h *Handler) UpdateFunction(w http.ResponseWriter, r *http.Request) {
 
 	h.logDebug(r, "UpdateFunction", "更新参数", logrus.Fields{"function": fn.Name, "id": fn.ID, "request_id": requestID})
 
+	// 保留更新前的副本，用于计算变更差异
+	before := *fn
+
 	// 按需更新各个字段（部分更新模式）
 	if req.Description != nil {
 		fn.Description = *req.Description
@@ -614,6 +1107,10 @@ func (h *Handler) UpdateFunction(w http.ResponseWriter, r *http.Request) {
 		fn.Tags = *req.Tags
 	}
 	if req.Handler != nil {
+		if err := domain.ValidateHandler(fn.Runtime, *req.Handler); err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
 		fn.Handler = *req.Handler
 	}
 	needRecompile := false
@@ -643,6 +1140,207 @@ func (h *Handler) UpdateFunction(w http.ResponseWriter, r *http.Request) {
 	if req.MaxConcurrency != nil {
 		fn.MaxConcurrency = *req.MaxConcurrency
 	}
+	if req.ReservedConcurrency != nil {
+		fn.ReservedConcurrency = *req.ReservedConcurrency
+	}
+	if req.KeepWarm != nil {
+		if err := domain.ValidateKeepWarm(*req.KeepWarm); err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		fn.KeepWarm = *req.KeepWarm
+	}
+	if req.WarmupPayload != nil {
+		payload := req.WarmupPayload
+		if string(payload) == "null" {
+			payload = nil
+		}
+		if err := domain.ValidateWarmupPayload(payload); err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		fn.WarmupPayload = payload
+	}
+	if req.InitHandler != nil {
+		if err := domain.ValidateInitHandler(fn.Runtime, *req.InitHandler); err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		fn.InitHandler = *req.InitHandler
+	}
+	if req.EmptyResponse != nil {
+		if err := domain.ValidateEmptyResponse(*req.EmptyResponse); err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		fn.EmptyResponse = *req.EmptyResponse
+	}
+	if req.MaintenanceWindows != nil {
+		if err := domain.ValidateMaintenanceWindows(*req.MaintenanceWindows); err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		fn.MaintenanceWindows = *req.MaintenanceWindows
+	}
+	if req.Deprecation != nil {
+		if req.Deprecation.IsZero() {
+			fn.Deprecation = nil
+		} else {
+			if err := req.Deprecation.Validate(); err != nil {
+				writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+				return
+			}
+			// 弃用时间由服务端记录，修改已有弃用配置时保留首次弃用的时间
+			deprecation := *req.Deprecation
+			deprecation.DeprecatedAt = time.Now()
+			if fn.Deprecation != nil {
+				deprecation.DeprecatedAt = fn.Deprecation.DeprecatedAt
+			}
+			fn.Deprecation = &deprecation
+		}
+	}
+	if req.DataVolumes != nil {
+		if err := domain.ValidateDataVolumes(*req.DataVolumes); err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		if !h.checkDataVolumes(w, r, *req.DataVolumes) {
+			return
+		}
+		fn.DataVolumes = *req.DataVolumes
+	}
+	if req.AllowedEnvironments != nil {
+		if err := domain.ValidateAllowedEnvironments(*req.AllowedEnvironments); err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		if !h.checkAllowedEnvironments(w, r, *req.AllowedEnvironments, fn.Runtime) {
+			return
+		}
+		fn.AllowedEnvironments = *req.AllowedEnvironments
+	}
+	if !h.checkDeployEnvironment(w, r, fn.Runtime) {
+		return
+	}
+	if req.VersionRetention != nil {
+		if err := domain.ValidateVersionRetention(*req.VersionRetention); err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		fn.VersionRetention = *req.VersionRetention
+	}
+	if req.MaxReuse != nil {
+		if err := domain.ValidateMaxReuse(*req.MaxReuse); err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		fn.MaxReuse = *req.MaxReuse
+	}
+	if req.StopGracePeriodSec != nil {
+		if err := domain.ValidateStopGracePeriod(*req.StopGracePeriodSec); err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		fn.StopGracePeriodSec = *req.StopGracePeriodSec
+	}
+	if req.MaxPayloadBytes != nil {
+		if err := domain.ValidateMaxPayloadBytes(*req.MaxPayloadBytes); err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		fn.MaxPayloadBytes = *req.MaxPayloadBytes
+	}
+	if req.CircuitBreaker != nil {
+		if req.CircuitBreaker.IsZero() {
+			fn.CircuitBreaker = nil
+		} else {
+			if err := req.CircuitBreaker.Validate(); err != nil {
+				writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+				return
+			}
+			cb := *req.CircuitBreaker
+			fn.CircuitBreaker = &cb
+		}
+	}
+	if req.CustomImage != nil || req.CustomCommand != nil || req.WorkingDir != nil || req.Args != nil {
+		if req.CustomImage != nil {
+			fn.CustomImage = *req.CustomImage
+		}
+		if req.CustomCommand != nil {
+			fn.CustomCommand = *req.CustomCommand
+		}
+		if req.WorkingDir != nil {
+			fn.WorkingDir = *req.WorkingDir
+		}
+		if req.Args != nil {
+			fn.Args = *req.Args
+		}
+		if err := domain.ValidateCustomImage(fn.CustomImage, fn.CustomCommand, fn.WorkingDir, fn.Args); err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		if !h.checkCustomImage(w, r, fn.CustomImage) {
+			return
+		}
+	}
+	if req.Priority != nil {
+		if err := domain.ValidatePriority(*req.Priority); err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		fn.Priority = *req.Priority
+	}
+	if req.RateLimit != nil {
+		if req.RateLimit.RequestsPerSecond == 0 {
+			// requests_per_second 为 0 表示取消限流
+			fn.RateLimit = nil
+		} else {
+			if err := req.RateLimit.Validate(); err != nil {
+				writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+				return
+			}
+			fn.RateLimit = req.RateLimit
+		}
+	}
+	if req.ResponseCache != nil {
+		if req.ResponseCache.TTLSeconds == 0 {
+			// ttl_seconds 为 0 表示取消响应缓存
+			fn.ResponseCache = nil
+		} else {
+			if err := req.ResponseCache.Validate(); err != nil {
+				writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+				return
+			}
+			fn.ResponseCache = req.ResponseCache
+		}
+	}
+	if req.BuildEnv != nil || req.BuildArgs != nil {
+		buildEnv, buildArgs := fn.BuildEnv, fn.BuildArgs
+		if req.BuildEnv != nil {
+			buildEnv = *req.BuildEnv
+		}
+		if req.BuildArgs != nil {
+			buildArgs = *req.BuildArgs
+		}
+		if err := domain.ValidateBuildConfig(fn.Runtime, buildEnv, buildArgs); err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		// 编译参数变更后重新编译，使产物与保存的配置一致
+		if !maps.Equal(buildEnv, fn.BuildEnv) || !slices.Equal(buildArgs, fn.BuildArgs) {
+			needRecompile = true
+		}
+		fn.BuildEnv, fn.BuildArgs = buildEnv, buildArgs
+	}
+	if req.MaxConcurrency != nil || req.ReservedConcurrency != nil {
+		if err := domain.ValidateReservedConcurrency(fn.ReservedConcurrency, fn.MaxConcurrency); err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		if fn.ReservedConcurrency > before.ReservedConcurrency && !h.checkReservedConcurrency(w, r, fn.ID, fn.ReservedConcurrency) {
+			return
+		}
+	}
 	if req.EnvVars != nil {
 		fn.EnvVars = *req.EnvVars
 	}
@@ -656,12 +1354,21 @@ func (h *Handler) UpdateFunction(w http.ResponseWriter, r *http.Request) {
 		fn.CronExpression = *req.CronExpression
 	}
 	if req.HTTPPath != nil {
+		if err := domain.ValidateHTTPPath(*req.HTTPPath); err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
 		fn.HTTPPath = *req.HTTPPath
 	}
 	if req.HTTPMethods != nil {
 		fn.HTTPMethods = *req.HTTPMethods
 	}
 
+	// 外部策略校验更新后的函数配置
+	if !h.checkAdmission(w, r, admissionUpdate, fn, &before) {
+		return
+	}
+
 	// 如果代码更新且是需要编译的运行时，异步处理
 	if needRecompile && compiler.IsSourceCode(string(fn.Runtime), fn.Code) {
 		h.logInfo(r, "UpdateFunction", "代码变更，异步重新编译", logrus.Fields{"function": fn.Name, "runtime": fn.Runtime})
@@ -697,15 +1404,33 @@ func (h *Handler) UpdateFunction(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// 异步处理编译任务
-		go h.processUpdateFunctionTask(fn.ID, taskID)
+		go h.processUpdateFunctionTask(fn.ID, taskID, before.Status)
 
 		h.logInfo(r, "UpdateFunction", "函数已更新，编译任务已提交", logrus.Fields{"function": fn.Name, "id": fn.ID, "task_id": taskID})
 
 		// 返回 200 OK，源代码已保存，编译在后台进行
-		writeJSON(w, http.StatusOK, fn)
+		writeJSON(w, http.StatusOK, &UpdateFunctionResponse{
+			Function:           fn,
+			Changes:            domain.DiffFunctions(&before, fn),
+			RecompileTriggered: true,
+		})
 		return
 	}
 
+	// 代码或入口变更时静态检查解释型运行时的入口函数
+	var warnings []string
+	if needRecompile || req.Handler != nil {
+		warning, err := h.checkHandlerSignature(fn)
+		if err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, "handler check failed: "+err.Error())
+			return
+		}
+		if warning != "" {
+			h.logWarn(r, "UpdateFunction", "入口函数检查未通过", logrus.Fields{"function": fn.Name, "warning": warning})
+			warnings = append(warnings, "handler check: "+warning)
+		}
+	}
+
 	// 如果代码有变更但不需要编译，直接创建版本快照
 	if needRecompile {
 		latestVersion, _ := h.store.GetLatestFunctionVersion(fn.ID)
@@ -737,14 +1462,29 @@ func (h *Handler) UpdateFunction(w http.ResponseWriter, r *http.Request) {
 		h.cronManager.AddOrUpdateFunction(fn)
 		h.logDebug(r, "UpdateFunction", "同步定时任务", logrus.Fields{"function": fn.Name, "cron": fn.CronExpression})
 	}
+	// 常驻实例数或其所在的规格变化时立即协调，不等待下一个协调周期
+	if fn.KeepWarm != before.KeepWarm || (fn.KeepWarm > 0 && fn.MemoryMB != before.MemoryMB) {
+		h.reconcileKeepWarm()
+	}
 
 	h.logInfo(r, "UpdateFunction", "函数更新成功", logrus.Fields{"function": fn.Name, "id": fn.ID})
-	writeJSON(w, http.StatusOK, fn)
+	writeJSON(w, http.StatusOK, &UpdateFunctionResponse{
+		Function:           fn,
+		Changes:            domain.DiffFunctions(&before, fn),
+		RecompileTriggered: false,
+		Warnings:           warnings,
+	})
 }
 
 // processUpdateFunctionTask 异步处理函数更新任务
 // 流程：源代码已在 UpdateFunction 中保存 → 编译 → 更新二进制和状态
-func (h *Handler) processUpdateFunctionTask(functionID, taskID string) {
+// restoreStatus 是更新前的函数状态，编译被用户取消时恢复为该状态
+func (h *Handler) processUpdateFunctionTask(functionID, taskID string, restoreStatus domain.FunctionStatus) {
+	// 登记编译任务，支持通过 POST /tasks/{id}/cancel 取消
+	ctx, cancel := h.builds.start(taskID, buildTimeout)
+	defer cancel()
+	defer h.builds.finish(taskID)
+
 	// 更新任务状态为 running
 	now := time.Now()
 	h.store.UpdateFunctionTask(&domain.FunctionTask{
@@ -770,12 +1510,17 @@ func (h *Handler) processUpdateFunctionTask(functionID, taskID string) {
 	h.store.UpdateFunctionStatus(functionID, domain.FunctionStatusBuilding, "正在编译源代码", taskID)
 
 	// 执行编译（使用带超时的 context）
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
 	compileResp, err := h.compiler.Compile(ctx, &compiler.CompileRequest{
-		Runtime: string(fn.Runtime),
-		Code:    fn.Code,
+		Runtime:   string(fn.Runtime),
+		Code:      fn.Code,
+		Layers:    h.compileLayerHashes(fn.ID),
+		BuildEnv:  fn.BuildEnv,
+		BuildArgs: fn.BuildArgs,
 	})
+	if h.builds.finish(taskID) {
+		h.completeTaskCancelled(taskID, functionID, restoreStatus)
+		return
+	}
 	if err != nil {
 		h.completeTaskWithError(taskID, functionID, "compilation error: "+err.Error())
 		return
@@ -891,6 +1636,10 @@ func (h *Handler) DeleteFunction(w http.ResponseWriter, r *http.Request) {
 		h.cronManager.RemoveFunction(fn.ID)
 		h.logDebug(r, "DeleteFunction", "移除定时任务", logrus.Fields{"function": fn.Name})
 	}
+	// 释放函数预置的常驻实例
+	if fn.KeepWarm > 0 {
+		h.reconcileKeepWarm()
+	}
 
 	h.logInfo(r, "DeleteFunction", "函数删除成功", logrus.Fields{"function": fn.Name, "id": fn.ID})
 	// 返回204 No Content表示删除成功
@@ -924,6 +1673,7 @@ func (h *Handler) BulkDeleteFunctions(w http.ResponseWriter, r *http.Request) {
 		Success: make([]string, 0),
 		Failed:  make([]domain.BulkOperationFailure, 0),
 	}
+	keepWarmChanged := false // 是否有预置了常驻实例的函数被删除或改变状态
 
 	for _, id := range req.IDs {
 		// 查找函数
@@ -945,6 +1695,14 @@ func (h *Handler) BulkDeleteFunctions(w http.ResponseWriter, r *http.Request) {
 			})
 			continue
 		}
+		// 检查 API Key 的标签作用域
+		if !auth.GetUser(r.Context()).CanAccessTags(fn.Tags) {
+			result.Failed = append(result.Failed, domain.BulkOperationFailure{
+				ID:    fn.ID,
+				Error: "forbidden: api key is not scoped to this function",
+			})
+			continue
+		}
 
 		// 执行删除
 		if err := h.store.DeleteFunction(fn.ID); err != nil {
@@ -959,10 +1717,17 @@ func (h *Handler) BulkDeleteFunctions(w http.ResponseWriter, r *http.Request) {
 		if h.cronManager != nil {
 			h.cronManager.RemoveFunction(fn.ID)
 		}
+		if fn.KeepWarm > 0 {
+			keepWarmChanged = true
+		}
 
 		result.Success = append(result.Success, fn.ID)
 		h.logDebug(r, "BulkDeleteFunctions", "删除成功", logrus.Fields{"id": fn.ID, "name": fn.Name})
 	}
+	// 释放被删除函数预置的常驻实例
+	if keepWarmChanged {
+		h.reconcileKeepWarm()
+	}
 
 	h.logInfo(r, "BulkDeleteFunctions", "批量删除完成", logrus.Fields{
 		"success_count": len(result.Success),
@@ -1002,6 +1767,7 @@ func (h *Handler) BulkUpdateFunctions(w http.ResponseWriter, r *http.Request) {
 		Success: make([]string, 0),
 		Failed:  make([]domain.BulkOperationFailure, 0),
 	}
+	keepWarmChanged := false // 是否有预置了常驻实例的函数被删除或改变状态
 
 	for _, id := range req.IDs {
 		// 查找函数
@@ -1023,8 +1789,17 @@ func (h *Handler) BulkUpdateFunctions(w http.ResponseWriter, r *http.Request) {
 			})
 			continue
 		}
+		// 检查 API Key 的标签作用域
+		if !auth.GetUser(r.Context()).CanAccessTags(fn.Tags) {
+			result.Failed = append(result.Failed, domain.BulkOperationFailure{
+				ID:    fn.ID,
+				Error: "forbidden: api key is not scoped to this function",
+			})
+			continue
+		}
 
 		// 更新状态
+		resuming := false
 		if req.Status != "" {
 			// 验证状态转换是否合法
 			switch req.Status {
@@ -1044,6 +1819,14 @@ func (h *Handler) BulkUpdateFunctions(w http.ResponseWriter, r *http.Request) {
 					})
 					continue
 				}
+			case domain.FunctionStatusPaused:
+				if !fn.Status.CanPause() && fn.Status != domain.FunctionStatusPaused {
+					result.Failed = append(result.Failed, domain.BulkOperationFailure{
+						ID:    fn.ID,
+						Error: fmt.Sprintf("cannot change status from %s to %s", fn.Status, req.Status),
+					})
+					continue
+				}
 			case domain.FunctionStatusInactive:
 				// 允许将任何状态设置为 inactive
 			default:
@@ -1053,6 +1836,7 @@ func (h *Handler) BulkUpdateFunctions(w http.ResponseWriter, r *http.Request) {
 				})
 				continue
 			}
+			resuming = fn.Status.CanOnline() && req.Status == domain.FunctionStatusActive
 			fn.Status = req.Status
 		}
 
@@ -1070,9 +1854,20 @@ func (h *Handler) BulkUpdateFunctions(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
+		if resuming {
+			h.resumePaused(r, fn)
+		}
+		if req.Status != "" && fn.KeepWarm > 0 {
+			keepWarmChanged = true
+		}
+
 		result.Success = append(result.Success, fn.ID)
 		h.logDebug(r, "BulkUpdateFunctions", "更新成功", logrus.Fields{"id": fn.ID, "name": fn.Name})
 	}
+	// 状态变化后立即按可调用函数协调常驻实例（下线的函数释放，上线的函数补齐）
+	if keepWarmChanged {
+		h.reconcileKeepWarm()
+	}
 
 	h.logInfo(r, "BulkUpdateFunctions", "批量更新完成", logrus.Fields{
 		"success_count": len(result.Success),
@@ -1198,6 +1993,8 @@ func (h *Handler) CloneFunction(w http.ResponseWriter, r *http.Request) {
 		TimeoutSec:     sourceFn.TimeoutSec,
 		EnvVars:        envVars,
 		CronExpression: sourceFn.CronExpression,
+		BuildEnv:       sourceFn.BuildEnv,
+		BuildArgs:      sourceFn.BuildArgs,
 		HTTPPath:       "", // HTTP路径需要用户重新配置，避免冲突
 		HTTPMethods:    httpMethods,
 		Status:         domain.FunctionStatusCreating,
@@ -1244,7 +2041,7 @@ func (h *Handler) CloneFunction(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 异步处理任务
-	go h.processCreateFunctionTask(newFn.ID, taskID)
+	go h.processCreateFunctionTask(newFn.ID, taskID, "")
 
 	h.logInfo(r, "CloneFunction", "函数克隆任务已提交", logrus.Fields{
 		"source":  sourceFn.Name,
@@ -1263,6 +2060,68 @@ func (h *Handler) CloneFunction(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// invokeOptions 从调用请求中解析出的同步调用选项，HTTP 调用和 WebSocket 调用共用。
+type invokeOptions struct {
+	alias    string                    // 蓝绿槽位或别名，空表示线上版本
+	envCfg   *domain.FunctionEnvConfig // 调用所在环境的函数配置，未指定环境时为 nil
+	layers   []domain.LayerOverride    // 本次调用临时使用的层
+	costTags map[string]string         // 调用方附加的成本标签
+}
+
+// resolveInvokeOptions 解析同步调用的公共选项：蓝绿槽位或别名、环境准入检查、
+// 环境配置及其 active_alias、临时层和成本标签。
+// 解析失败时写入错误响应并返回 false。
+func (h *Handler) resolveInvokeOptions(w http.ResponseWriter, r *http.Request, fn *domain.Function) (*invokeOptions, bool) {
+	// 解析指定的蓝绿槽位或别名，未指定时使用线上槽位
+	alias, ok := h.resolveInvokeAlias(w, r, fn)
+	if !ok {
+		return nil, false
+	}
+
+	// 检查函数是否允许在请求的环境中调用
+	if !h.checkInvokeEnvironment(w, r, fn) {
+		return nil, false
+	}
+
+	// 解析调用所在环境的函数配置，调用方未指定别名时使用环境的 active_alias
+	envCfg, ok := h.resolveEnvConfig(w, r, fn)
+	if !ok {
+		return nil, false
+	}
+	if alias, ok = h.resolveEnvAlias(w, r, fn, alias, envCfg); !ok {
+		return nil, false
+	}
+	setEffectiveConfigHeader(w, r, fn, envCfg, alias)
+
+	// 解析本次调用临时使用的层（仅非生产环境）
+	layers, ok := h.resolveLayerOverrides(w, r, fn)
+	if !ok {
+		return nil, false
+	}
+
+	// 解析调用方附加的成本标签
+	costTags, ok := parseCostTags(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	return &invokeOptions{alias: alias, envCfg: envCfg, layers: layers, costTags: costTags}, true
+}
+
+// newRequest 按调用选项构建同步调用请求，路由键和调用链从 HTTP 请求头继承。
+func (o *invokeOptions) newRequest(r *http.Request, fn *domain.Function, payload json.RawMessage) *domain.InvokeRequest {
+	return &domain.InvokeRequest{
+		FunctionID: fn.ID,
+		Payload:    payload,
+		CostTags:   o.costTags,
+		Alias:      o.alias,
+		RoutingKey: r.Header.Get(domain.HeaderRoutingKey),
+		Layers:     o.layers,
+		EnvConfig:  o.envCfg,
+		CallChain:  callChainFromRequest(r),
+	}
+}
+
 // InvokeFunction 处理同步调用函数的请求。
 // HTTP端点: POST /api/v1/functions/{id}/invoke
 //
@@ -1304,6 +2163,11 @@ func (h *Handler) InvokeFunction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 暂停的函数不接受同步调用
+	if writePausedError(w, r, fn) {
+		return
+	}
+
 	// 检查函数状态，只有Active状态的函数才能被调用
 	if !fn.Status.CanInvoke() {
 		h.logWarn(r, "InvokeFunction", "函数状态不可用", logrus.Fields{
@@ -1314,9 +2178,43 @@ func (h *Handler) InvokeFunction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 解析请求体作为函数输入载荷
+	// 已弃用的函数设置弃用响应头，过了下线时间可能被拒绝
+	if !h.checkDeprecation(w, r, fn, "InvokeFunction") {
+		return
+	}
+
+	// 调度预演：只报告调用会如何被调度，不消耗限流令牌也不执行函数
+	if r.URL.Query().Get("dry_run") == "true" {
+		h.dryRunInvoke(w, r, fn)
+		return
+	}
+
+	// 解析槽位或别名、调用环境、临时层和成本标签
+	opts, ok := h.resolveInvokeOptions(w, r, fn)
+	if !ok {
+		return
+	}
+
+	// 检查函数限流配置，并设置 X-RateLimit-* 响应头
+	if !h.checkRateLimit(w, r, fn) {
+		return
+	}
+
+	// 解析管道调用的后续函数（?then=），执行前检查每个函数都可以调用
+	pipeFns, ok := h.resolvePipe(w, r)
+	if !ok {
+		return
+	}
+
+	// 解析请求体作为函数输入载荷，请求体超过大小上限时返回 413
+	if !h.limitPayload(w, r, fn) {
+		return
+	}
 	var payload json.RawMessage
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && err.Error() != "EOF" {
+		if writePayloadTooLargeError(w, r, err) {
+			return
+		}
 		h.logError(r, "InvokeFunction", "解析请求体失败", err, logrus.Fields{"function": fn.Name})
 		writeErrorWithContext(w, r, http.StatusBadRequest, "invalid request body: "+err.Error())
 		return
@@ -1337,22 +2235,23 @@ func (h *Handler) InvokeFunction(w http.ResponseWriter, r *http.Request) {
 	})
 
 	// 广播调用开始日志
-	BroadcastLog(LogMessage{
-		Timestamp:    time.Now(),
-		Level:        "INFO",
-		FunctionID:   fn.ID,
-		FunctionName: fn.Name,
-		Message:      "函数调用开始",
-		RequestID:    requestID,
-		Input:        payload,
-	})
+	broadcastInvocationStart(fn, domain.LogSourceAPI, requestID, payload)
 
 	// 构建调用请求
-	req := &domain.InvokeRequest{
-		FunctionID: fn.ID,
-		Payload:    payload,
-		Async:      false,
-		SessionKey: r.URL.Query().Get("session_key"), // 支持有状态函数的会话标识
+	req := opts.newRequest(r, fn, payload)
+	req.SessionKey = r.URL.Query().Get("session_key") // 支持有状态函数的会话标识
+	if len(pipeFns) > 0 {
+		req.Pipe = newInvocationPipe(fn, pipeFns)
+	}
+
+	// 响应缓存：只用于未指定槽位、环境、临时层、会话和管道的调用，命中时直接返回缓存的响应
+	var cacheKey string
+	if opts.alias == "" && opts.envCfg == nil && opts.layers == nil && req.SessionKey == "" && len(pipeFns) == 0 {
+		var done bool
+		cacheKey, done = h.serveResponseCache(w, r, fn, requestID, payload, h.lookupResponseCache)
+		if done {
+			return
+		}
 	}
 
 	// 记录开始时间
@@ -1372,21 +2271,23 @@ func (h *Handler) InvokeFunction(w http.ResponseWriter, r *http.Request) {
 			"duration_ms": durationMs,
 		})
 		// 广播错误日志
-		BroadcastLog(LogMessage{
-			Timestamp:    time.Now(),
-			Level:        "ERROR",
-			FunctionID:   fn.ID,
-			FunctionName: fn.Name,
-			Message:      "函数调用失败",
-			RequestID:    requestID,
-			Input:        payload,
-			Error:        err.Error(),
-			DurationMs:   durationMs,
-		})
-		// 返回带堆栈的错误响应
+		broadcastInvocationResult(fn, domain.LogSourceAPI, requestID, payload, nil, err, durationMs)
+		if writeKillSwitchError(w, r, err) {
+			return
+		}
+		if writeMaintenanceError(w, r, err) {
+			return
+		}
+		if writeRecursionError(w, r, err) {
+			return
+		}
+		if writeLayerOverrideError(w, r, err) {
+			return
+		}
+		// 返回错误响应，配置允许时附带堆栈
 		writeJSON(w, http.StatusInternalServerError, map[string]interface{}{
 			"error":       err.Error(),
-			"stack":       getStackTrace(0),
+			"stack":       responseStackTrace(0),
 			"request_id":  requestID,
 			"function":    fn.Name,
 			"duration_ms": durationMs,
@@ -1395,19 +2296,17 @@ func (h *Handler) InvokeFunction(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 广播调用完成日志
-	BroadcastLog(LogMessage{
-		Timestamp:    time.Now(),
-		Level:        "INFO",
-		FunctionID:   fn.ID,
-		FunctionName: fn.Name,
-		Message:      "函数调用完成",
-		RequestID:    requestID,
-		Input:        payload,
-		Output:       resp.Body,
-		DurationMs:   durationMs,
-	})
+	broadcastInvocationResult(fn, domain.LogSourceAPI, requestID, payload, resp, nil, durationMs)
+
+	// 管道调用：将输出依次传给后续函数
+	if len(pipeFns) > 0 {
+		h.continuePipe(w, r, req, resp, pipeFns)
+		return
+	}
+	h.storeResponseCache(r, fn, cacheKey, resp)
 
 	// 返回函数执行结果
+	setInvocationHeaders(w, r, resp)
 	writeJSON(w, resp.StatusCode, resp)
 }
 
@@ -1449,14 +2348,29 @@ func (h *Handler) InvokeFunctionAsync(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 检查函数状态，只有Active状态的函数才能被调用
-	if !fn.Status.CanInvoke() {
+	// 暂停的函数仍接受异步调用，调用进入暂停队列
+	if !fn.Status.CanInvokeAsync() {
 		writeError(w, http.StatusBadRequest, "function is not active, current status: "+string(fn.Status))
 		return
 	}
+	if !h.checkDeprecation(w, r, fn, "InvokeFunctionAsync") {
+		return
+	}
+
+	// 检查函数限流配置，并设置 X-RateLimit-* 响应头
+	if !h.checkRateLimit(w, r, fn) {
+		return
+	}
 
-	// 解析请求体作为函数输入载荷
+	// 解析请求体作为函数输入载荷，请求体超过大小上限时返回 413
+	if !h.limitPayload(w, r, fn) {
+		return
+	}
 	var payload json.RawMessage
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && err.Error() != "EOF" {
+		if writePayloadTooLargeError(w, r, err) {
+			return
+		}
 		writeError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
@@ -1465,17 +2379,61 @@ func (h *Handler) InvokeFunctionAsync(w http.ResponseWriter, r *http.Request) {
 		payload = json.RawMessage("{}")
 	}
 
+	// 检查函数是否允许在请求的环境中调用
+	if !h.checkInvokeEnvironment(w, r, fn) {
+		return
+	}
+
+	// 解析指定的蓝绿槽位或别名
+	alias, ok := h.resolveInvokeAlias(w, r, fn)
+	if !ok {
+		return
+	}
+
+	// 解析调用所在环境的函数配置，调用方未指定别名时使用环境的 active_alias
+	envCfg, ok := h.resolveEnvConfig(w, r, fn)
+	if !ok {
+		return
+	}
+	if alias, ok = h.resolveEnvAlias(w, r, fn, alias, envCfg); !ok {
+		return
+	}
+	setEffectiveConfigHeader(w, r, fn, envCfg, alias)
+
+	// 解析调用方附加的成本标签
+	costTags, ok := parseCostTags(w, r)
+	if !ok {
+		return
+	}
+
 	// 构建异步调用请求
 	req := &domain.InvokeRequest{
 		FunctionID: fn.ID,
 		Payload:    payload,
 		Async:      true,
 		SessionKey: r.URL.Query().Get("session_key"), // 支持有状态函数的会话标识
+		CostTags:   costTags,
+		Alias:      alias,
+		RoutingKey: r.Header.Get(domain.HeaderRoutingKey),
+		EnvConfig:  envCfg,
+		CallChain:  callChainFromRequest(r),
 	}
 
 	// 通过调度器提交异步执行请求
 	requestID, err := h.scheduler.InvokeAsync(req)
 	if err != nil {
+		// 异步队列不可用（如 Redis 故障）时返回 503，提示客户端稍后重试
+		if errors.Is(err, domain.ErrAsyncQueueUnavailable) {
+			h.logWarn(r, "InvokeFunctionAsync", "异步队列不可用", logrus.Fields{"function_id": req.FunctionID, "error": err.Error()})
+			writeError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		if writeKillSwitchError(w, r, err) {
+			return
+		}
+		if writeRecursionError(w, r, err) {
+			return
+		}
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -1484,6 +2442,7 @@ func (h *Handler) InvokeFunctionAsync(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusAccepted, map[string]string{
 		"request_id": requestID,
 		"status":     "accepted",
+		"result_url": "/api/v1/async-results/" + requestID,
 	})
 }
 
@@ -1493,6 +2452,7 @@ func (h *Handler) InvokeFunctionAsync(w http.ResponseWriter, r *http.Request) {
 // 功能说明：
 //   - 查询指定ID的函数调用记录
 //   - 包含调用的输入、输出、状态和执行时间等信息
+//   - 函数写入 /output 的产物列表（含下载地址）
 //
 // 路径参数：
 //   - id: 调用记录的唯一标识符
@@ -1518,10 +2478,117 @@ func (h *Handler) GetInvocation(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to get invocation")
 		return
 	}
+	// 附加函数写入 /output 的产物列表（含下载地址）
+	if artifacts, err := h.store.ListInvocationArtifacts(id); err != nil {
+		h.logWarn(r, "GetInvocation", "查询调用产物失败", logrus.Fields{"invocation_id": id, "error": err.Error()})
+	} else {
+		inv.Artifacts = artifacts
+	}
 
 	writeJSON(w, http.StatusOK, inv)
 }
 
+// BatchGetInvocations 处理批量获取调用记录的请求。
+// HTTP端点: POST /api/v1/invocations/batch-get
+//
+// 功能说明：
+//   - 一次查询返回多个调用记录，减少仪表板和扇出调用方轮询异步结果的往返次数
+//   - 结果按请求中的 ID 顺序返回，不存在的 ID 标记为 found=false，不影响其他 ID
+//
+// 请求体：{"ids": ["...", "..."]}，最多 domain.MaxBatchGetInvocations 个
+//
+// 返回值：
+//   - 200: 成功，返回每个 ID 的查询结果
+//   - 400: 请求体无效、ids 为空或超过数量上限
+func (h *Handler) BatchGetInvocations(w http.ResponseWriter, r *http.Request) {
+	var req domain.BatchGetInvocationsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if len(req.IDs) == 0 {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "ids is required and cannot be empty")
+		return
+	}
+	if len(req.IDs) > domain.MaxBatchGetInvocations {
+		writeErrorWithContext(w, r, http.StatusBadRequest, fmt.Sprintf("too many ids: at most %d per request", domain.MaxBatchGetInvocations))
+		return
+	}
+
+	h.writeBatchGetInvocations(w, r, req.IDs, h.store.GetInvocationsByIDs, h.functionTagsByID)
+}
+
+// writeBatchGetInvocations 使用 fetch 批量查询调用记录，按 API Key 的标签作用域过滤后按请求顺序写入结果。
+// 作用域外的调用记录与不存在的记录一样标记为 found=false。
+//
+// 参数:
+//   - ids: 请求的调用记录 ID
+//   - fetch: 按 ID 批量查询调用记录
+//   - functionTags: 查询函数的标签，用于标签作用域过滤
+func (h *Handler) writeBatchGetInvocations(w http.ResponseWriter, r *http.Request, ids []string, fetch func(ids []string) (map[string]*domain.Invocation, error), functionTags func(functionID string) []string) {
+	invocations, err := fetch(ids)
+	if err != nil {
+		h.logError(r, "BatchGetInvocations", "批量查询调用记录失败", err, logrus.Fields{"count": len(ids)})
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to get invocations: "+err.Error())
+		return
+	}
+	// 带标签作用域的 API Key 只能获取匹配标签的函数的调用记录
+	scopeInvocations(auth.GetUser(r.Context()), invocations, functionTags)
+
+	results := make([]domain.BatchGetInvocationResult, 0, len(ids))
+	missing := 0
+	for _, id := range ids {
+		inv, ok := invocations[id]
+		if !ok {
+			missing++
+		}
+		results = append(results, domain.BatchGetInvocationResult{ID: id, Found: ok, Invocation: inv})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"results": results,
+		"found":   len(results) - missing,
+		"missing": missing,
+	})
+}
+
+// GetInvocationProgress 处理获取调用执行进度的请求。
+// HTTP端点: GET /api/v1/invocations/{id}/progress
+//
+// 功能说明：
+//   - 返回函数在执行过程中通过运行时进度 API 上报的最新进度
+//   - 适用于长时间运行的异步调用展示进度条，无需等待最终结果
+//
+// 路径参数：
+//   - id: 调用记录的唯一标识符
+//
+// 返回值：
+//   - 200: 成功，返回调用状态和最新进度（未上报时 progress 为 null）
+//   - 404: 调用记录不存在
+func (h *Handler) GetInvocationProgress(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "invocation id required")
+		return
+	}
+
+	inv, err := h.store.GetInvocationByID(id)
+	if err == domain.ErrInvocationNotFound {
+		writeError(w, http.StatusNotFound, "invocation not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get invocation")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"invocation_id": inv.ID,
+		"status":        inv.Status,
+		"progress":      inv.Progress,
+	})
+}
+
 // ReplayInvocation 处理重放调用记录的请求。
 // HTTP端点: POST /api/v1/invocations/{id}/replay
 //
@@ -1574,6 +2641,9 @@ func (h *Handler) ReplayInvocation(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 检查函数状态
+	if writePausedError(w, r, fn) {
+		return
+	}
 	if !fn.Status.CanInvoke() {
 		h.logWarn(r, "ReplayInvocation", "函数当前状态不可调用", logrus.Fields{
 			"function": fn.Name,
@@ -1595,6 +2665,7 @@ func (h *Handler) ReplayInvocation(w http.ResponseWriter, r *http.Request) {
 	req := &domain.InvokeRequest{
 		FunctionID: fn.ID,
 		Payload:    inv.Input,
+		CostTags:   inv.CostTags, // 重放沿用原调用的成本标签
 	}
 
 	// 执行函数调用
@@ -1607,6 +2678,12 @@ func (h *Handler) ReplayInvocation(w http.ResponseWriter, r *http.Request) {
 			"original_invocation": id,
 			"duration_ms":         durationMs,
 		})
+		if writeKillSwitchError(w, r, err) {
+			return
+		}
+		if writeMaintenanceError(w, r, err) {
+			return
+		}
 		writeJSON(w, http.StatusInternalServerError, map[string]interface{}{
 			"error":                err.Error(),
 			"request_id":           requestID,
@@ -1676,16 +2753,7 @@ func (h *Handler) ListInvocations(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 解析分页参数
-	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-
-	// 设置limit的默认值和最大值限制
-	if limit <= 0 {
-		limit = 20
-	}
-	if limit > 100 {
-		limit = 100
-	}
+	offset, limit := parsePagination(r)
 
 	// 查询该函数的调用记录
 	invocations, total, err := h.store.ListInvocationsByFunction(fn.ID, offset, limit)
@@ -1695,12 +2763,7 @@ func (h *Handler) ListInvocations(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 返回分页结果
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"invocations": invocations,
-		"total":       total,
-		"offset":      offset,
-		"limit":       limit,
-	})
+	writePaginated(w, r, "invocations", invocations, total, offset, limit)
 }
 
 // ListAllInvocations 处理获取所有调用记录列表的请求。
@@ -1733,8 +2796,18 @@ func (h *Handler) ListAllInvocations(w http.ResponseWriter, r *http.Request) {
 		limit = 100
 	}
 
-	// 查询所有调用记录
-	invocations, total, err := h.store.ListAllInvocations(status, offset, limit)
+	// 查询所有调用记录；带标签作用域的 API Key 只能看到匹配标签的函数的调用记录
+	var invocations []*domain.Invocation
+	var total int
+	var err error
+	if user := auth.GetUser(r.Context()); user != nil && len(user.TagSelector) > 0 {
+		invocations, total, err = h.store.SearchInvocations(&domain.InvocationSearchQuery{
+			Status:       domain.InvocationStatus(status),
+			FunctionTags: user.TagSelector,
+		}, offset, limit)
+	} else {
+		invocations, total, err = h.store.ListAllInvocations(status, offset, limit)
+	}
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to list invocations")
 		return
@@ -1767,18 +2840,87 @@ func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
 // 功能说明：
 //   - 检查服务是否已准备好接收流量
 //   - 验证数据库连接是否正常
+//   - 检查 Redis 连接，Redis 不可用时服务降级（同步调用仍可用，异步调用可能返回503）
+//   - Docker 模式下检查运行时镜像，部分镜像缺失时服务降级，全部缺失时未就绪
 //   - 用于Kubernetes的readiness probe
 //
 // 返回值：
-//   - 200: 服务就绪
-//   - 503: 服务未就绪（如数据库连接失败）
+//   - 200: 服务就绪（status 为 ready 或 degraded）
+//   - 503: 服务未就绪（如数据库连接失败、所有运行时镜像均缺失）
 func (h *Handler) Ready(w http.ResponseWriter, r *http.Request) {
 	// 检查数据库连接
 	if err := h.store.Ping(); err != nil {
 		writeError(w, http.StatusServiceUnavailable, "database not ready")
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+
+	var redis redisPinger
+	if h.redis != nil {
+		redis = h.redis
+	}
+	code, resp := h.readiness(r, redis)
+	writeJSON(w, code, resp)
+}
+
+// redisPinger 是就绪检查所需的 Redis 能力
+type redisPinger interface {
+	Ping(ctx context.Context) error
+}
+
+// readiness 在数据库可用的前提下汇总 Redis 与运行时镜像的检查结果。
+// Redis 不可用时限流、响应缓存、并发计数等功能降级为放行，服务仍可接收流量，因此返回 200 并标记为 degraded。
+//
+// 参数:
+//   - r: HTTP 请求
+//   - redis: Redis 客户端，未配置时为 nil
+//
+// 返回值:
+//   - int: HTTP 状态码
+//   - map[string]interface{}: 响应体
+func (h *Handler) readiness(r *http.Request, redis redisPinger) (int, map[string]interface{}) {
+	checks := map[string]string{"database": "ok"}
+	status := "ready"
+
+	// 检查 Redis 连接，不可用时不摘除流量，仅标记为降级
+	if redis != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
+		if err := redis.Ping(ctx); err != nil {
+			h.logWarn(r, "Ready", "Redis 不可用，服务降级运行", logrus.Fields{"error": err.Error()})
+			checks["redis"] = "unavailable"
+			status = "degraded"
+		} else {
+			checks["redis"] = "ok"
+		}
+	}
+
+	resp := map[string]interface{}{
+		"status": status,
+		"checks": checks,
+	}
+	if h.safeMode {
+		resp["safe_mode"] = true
+	}
+
+	// 检查运行时镜像：缺失的运行时无法调用，其余运行时仍可正常服务
+	if reporter, ok := h.scheduler.(ImageReporter); ok {
+		missing, total := reporter.MissingImages()
+		switch {
+		case len(missing) == 0:
+			checks["images"] = "ok"
+		case len(missing) >= total:
+			checks["images"] = "missing"
+			resp["status"] = "not_ready"
+			resp["missing_images"] = missing
+			return http.StatusServiceUnavailable, resp
+		default:
+			checks["images"] = "partial"
+			resp["status"] = "degraded"
+			resp["missing_images"] = missing
+		}
+	}
+
+	return http.StatusOK, resp
 }
 
 // Live 处理Kubernetes存活探针请求。
@@ -1804,15 +2946,23 @@ func (h *Handler) Live(w http.ResponseWriter, r *http.Request) {
 // 返回值：
 //   - functions: 系统中的函数总数
 //   - invocations: 累计调用次数
+//   - warm_pools: 各规格容器池的常驻与按需预热实例数（仅 Docker 池化模式）
 func (h *Handler) Stats(w http.ResponseWriter, r *http.Request) {
 	// 获取函数和调用的统计数量
 	fnCount, _ := h.store.CountFunctions()
 	invCount, _ := h.store.CountInvocations()
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
+	stats := map[string]interface{}{
 		"functions":   fnCount,
 		"invocations": invCount,
-	})
+	}
+	// Docker 池化模式下附带各规格容器池的常驻与按需预热实例数
+	if p, ok := h.scheduler.(WarmProvisioner); ok {
+		if pools, err := p.ProvisionStats(); err == nil {
+			stats["warm_pools"] = pools
+		}
+	}
+	writeJSON(w, http.StatusOK, stats)
 }
 
 // generateRequestID 生成唯一的请求ID
@@ -1846,12 +2996,49 @@ type ErrorResponse struct {
 	TraceID   string `json:"trace_id,omitempty"`   // 链路追踪ID
 }
 
-// getStackTrace 获取当前调用堆栈信息。
+// defaultStackTraceDepth 是堆栈跟踪的默认最大帧数
+const defaultStackTraceDepth = 32
+
+var (
+	// stackInResponse 控制错误响应是否附带堆栈跟踪。默认关闭：堆栈会向客户端暴露服务端文件路径，
+	// 且每次错误都要遍历调用栈；关闭时堆栈仍记录在服务端错误日志中
+	stackInResponse atomic.Bool
+	// stackTraceDepth 是堆栈跟踪的最大帧数
+	stackTraceDepth atomic.Int32
+)
+
+func init() {
+	stackTraceDepth.Store(defaultStackTraceDepth)
+}
+
+// SetErrorStackTraces 设置错误响应是否附带堆栈跟踪以及堆栈的最大帧数，对所有处理器生效。
+// 需在处理请求之前调用。
+//
+// 参数：
+//   - inResponse: 是否在错误响应中返回堆栈（建议仅在开发环境开启）
+//   - maxDepth: 堆栈最大帧数，<= 0 时使用默认值 32
+func (h *Handler) SetErrorStackTraces(inResponse bool, maxDepth int) {
+	if maxDepth <= 0 {
+		maxDepth = defaultStackTraceDepth
+	}
+	stackInResponse.Store(inResponse)
+	stackTraceDepth.Store(int32(maxDepth))
+}
+
+// responseStackTrace 在错误响应允许附带堆栈时返回堆栈跟踪，否则返回空字符串且不遍历调用栈。
+// skip 参数指定跳过的调用层数（不包含 responseStackTrace 自身）。
+func responseStackTrace(skip int) string {
+	if !stackInResponse.Load() {
+		return ""
+	}
+	return getStackTrace(skip + 1)
+}
+
+// getStackTrace 获取当前调用堆栈信息，最多 stackTraceDepth 帧。
 // skip 参数指定跳过的调用层数（不包含 getStackTrace 自身）。
 func getStackTrace(skip int) string {
-	const maxDepth = 32
-	var pcs [maxDepth]uintptr
-	n := runtime.Callers(skip+2, pcs[:]) // +2 跳过 Callers 和 getStackTrace
+	pcs := make([]uintptr, stackTraceDepth.Load())
+	n := runtime.Callers(skip+2, pcs) // +2 跳过 Callers 和 getStackTrace
 	if n == 0 {
 		return ""
 	}
@@ -1893,8 +3080,8 @@ func getStackTrace(skip int) string {
 //   - 自动从请求上下文中提取 request_id
 //   - 便于客户端统一处理错误响应
 func writeError(w http.ResponseWriter, status int, message string) {
-	// 获取堆栈信息
-	stack := getStackTrace(1)
+	// 获取堆栈信息（仅在配置允许时附带）
+	stack := responseStackTrace(1)
 
 	// 尝试从响应头获取 request_id（由 middleware.RequestID 设置）
 	requestID := w.Header().Get("X-Request-Id")
@@ -1924,8 +3111,8 @@ func writeError(w http.ResponseWriter, status int, message string) {
 //   - 从请求上下文中提取 request_id 和 trace_id
 //   - 便于客户端统一处理错误响应和调试
 func writeErrorWithContext(w http.ResponseWriter, r *http.Request, status int, message string) {
-	// 获取堆栈信息
-	stack := getStackTrace(1)
+	// 获取堆栈信息（仅在配置允许时附带）
+	stack := responseStackTrace(1)
 
 	// 从请求上下文获取 request_id
 	requestID := middleware.GetReqID(r.Context())
@@ -1971,7 +3158,7 @@ func (h *Handler) CompileCode(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 验证运行时
-	if req.Runtime != "go1.24" && req.Runtime != "wasm" && req.Runtime != "rust1.75" {
+	if !compiler.IsCompiledRuntime(req.Runtime) {
 		writeError(w, http.StatusBadRequest, "only go1.24, wasm and rust1.75 runtimes support compilation")
 		return
 	}
@@ -1997,13 +3184,65 @@ func (h *Handler) CompileCode(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, resp)
 }
 
+// CheckCode 只检查源代码而不生成编译产物，返回编译器给出的结构化诊断。
+// HTTP端点: POST /api/v1/compile/check
+//
+// 请求体与 POST /api/v1/compile 相同。Go 以 go build 类型检查并运行 go vet，
+// Rust 以 rustc --emit=metadata 检查；结果不写入编译缓存，与编译共用并发编译槽位。
+//
+// 返回值：
+//   - 200: 返回 compiler.CheckResponse，代码有错误时 success 为 false，诊断见 diagnostics
+//   - 400: 请求体无效、运行时不支持编译或编译参数不在白名单中
+//   - 500: 无法执行检查（如等待编译槽位时请求被取消）
+func (h *Handler) CheckCode(w http.ResponseWriter, r *http.Request) {
+	var req compiler.CompileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if !compiler.IsCompiledRuntime(req.Runtime) {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "only go1.24, wasm and rust1.75 runtimes support compilation")
+		return
+	}
+	if req.Code == "" {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "code is required")
+		return
+	}
+	if err := domain.ValidateBuildConfig(domain.Runtime(req.Runtime), req.BuildEnv, req.BuildArgs); err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.compiler.Check(r.Context(), &req)
+	if err != nil {
+		h.logError(r, "CheckCode", "代码检查失败", err, logrus.Fields{"runtime": req.Runtime})
+		writeErrorWithContext(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GetBuildStats 返回各运行时的并发编译情况。
+// HTTP端点: GET /api/v1/compile/stats
+//
+// 每个运行时返回并发上限、正在编译数和排队等待数，用于观察批量部署时的构建积压；
+// cache 为编译缓存的条目数、占用大小和命中情况。
+func (h *Handler) GetBuildStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"runtimes": h.compiler.BuildStats(),
+		"cache":    h.compiler.CacheStats(),
+	})
+}
+
 // HandleCustomRoute 处理自定义 HTTP 路由请求。
+// 静态路径优先精确匹配；未命中时再匹配带路径参数的路由模板（如 "/orders/{orderId}"），
+// 提取的参数通过 InvokeRequest.PathParameters 传给函数。
 func (h *Handler) HandleCustomRoute(w http.ResponseWriter, r *http.Request) {
 	path := r.URL.Path
 	method := r.Method
 
 	// 查找匹配该路径的函数
-	fn, err := h.store.GetFunctionByPath(path)
+	fn, pathParams, err := h.lookupRoute(path)
 	if err == domain.ErrFunctionNotFound {
 		http.NotFound(w, r)
 		return
@@ -2013,9 +3252,12 @@ func (h *Handler) HandleCustomRoute(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 检查函数状态，只有Active状态的函数才能被调用
-	if !fn.Status.CanInvoke() {
-		writeError(w, http.StatusBadRequest, "function is not active, current status: "+string(fn.Status))
+	if !checkFunctionScope(w, r, fn) {
+		return
+	}
+
+	// 检查函数状态：函数存在但暂时不可调用时返回 503 而不是 404
+	if writeUnavailableError(w, r, fn) {
 		return
 	}
 
@@ -2034,10 +3276,25 @@ func (h *Handler) HandleCustomRoute(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// 读取请求体作为函数输入
+	if !h.checkDeprecation(w, r, fn, "HandleCustomRoute") {
+		return
+	}
+
+	// 检查函数限流配置，并设置 X-RateLimit-* 响应头
+	if !h.checkRateLimit(w, r, fn) {
+		return
+	}
+
+	// 读取请求体作为函数输入，请求体超过大小上限时返回 413
+	if !h.limitPayload(w, r, fn) {
+		return
+	}
 	var payload json.RawMessage
 	if r.Body != nil {
-		body, _ := io.ReadAll(r.Body)
+		body, err := io.ReadAll(r.Body)
+		if writePayloadTooLargeError(w, r, err) {
+			return
+		}
 		if len(body) > 0 {
 			payload = json.RawMessage(body)
 		}
@@ -2046,18 +3303,54 @@ func (h *Handler) HandleCustomRoute(w http.ResponseWriter, r *http.Request) {
 		payload = json.RawMessage("{}")
 	}
 
+	// 检查函数是否允许在请求的环境中调用
+	if !h.checkInvokeEnvironment(w, r, fn) {
+		return
+	}
+
+	// 解析调用方附加的成本标签
+	costTags, ok := parseCostTags(w, r)
+	if !ok {
+		return
+	}
+
 	// 同步执行函数
 	req := &domain.InvokeRequest{
-		FunctionID: fn.ID,
-		Payload:    payload,
-		Async:      false,
+		FunctionID:     fn.ID,
+		Payload:        payload,
+		Async:          false,
+		PathParameters: pathParams,
+		CostTags:       costTags,
+		CallChain:      callChainFromRequest(r),
 	}
 
+	// 广播调用开始与结束日志，使实时日志流包含自定义路由的调用
+	requestID := generateRequestID()
+	broadcastInvocationStart(fn, domain.LogSourceRoute, requestID, payload)
+	startTime := time.Now()
 	resp, err := h.scheduler.Invoke(req)
+	broadcastInvocationResult(fn, domain.LogSourceRoute, requestID, payload, resp, err, time.Since(startTime).Milliseconds())
 	if err != nil {
+		if writeKillSwitchError(w, r, err) {
+			return
+		}
+		if writeMaintenanceError(w, r, err) {
+			return
+		}
+		if writeRecursionError(w, r, err) {
+			return
+		}
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	setInvocationHeaders(w, r, resp)
+
+	// 函数没有输出且未配置默认响应体时返回真正的空响应，而不是 JSON null
+	if len(resp.Body) == 0 {
+		w.Header().Set("Content-Length", "0")
+		w.WriteHeader(resp.StatusCode)
+		return
+	}
 
 	// 尝试解析 Lambda 样式的响应格式 (含 statusCode 和 body)
 	var lambdaResp struct {
@@ -2070,6 +3363,12 @@ func (h *Handler) HandleCustomRoute(w http.ResponseWriter, r *http.Request) {
 		for k, v := range lambdaResp.Headers {
 			w.Header().Set(k, v)
 		}
+		// 调用元数据头由平台设置，不允许被函数返回的同名响应头覆盖
+		setInvocationHeaders(w, r, resp)
+		// 函数通过 X-Nimbus-Cache-Control 覆盖本次响应的缓存策略
+		if resp.Directives != nil && resp.Directives.CacheControl != "" {
+			w.Header().Set("Cache-Control", resp.Directives.CacheControl)
+		}
 		writeJSON(w, lambdaResp.StatusCode, lambdaResp.Body)
 		return
 	}
@@ -2078,6 +3377,164 @@ func (h *Handler) HandleCustomRoute(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, resp.StatusCode, resp.Body)
 }
 
+// lookupRoute 查找与请求路径匹配的自定义路由函数。
+// 静态路径精确匹配优先，其次按 domain.MatchRoute 的优先级匹配路由模板。
+//
+// 返回值:
+//   - *domain.Function: 匹配的函数
+//   - map[string]string: 路由模板提取的路径参数，静态路由为 nil
+//   - error: 没有匹配时返回 domain.ErrFunctionNotFound
+func (h *Handler) lookupRoute(path string) (*domain.Function, map[string]string, error) {
+	fn, err := h.store.GetFunctionByPath(path)
+	if err != domain.ErrFunctionNotFound {
+		return fn, nil, err
+	}
+
+	routes, err := h.store.ListRouteTemplates()
+	if err != nil {
+		return nil, nil, err
+	}
+	route, params := domain.MatchRoute(routes, path)
+	if route == nil {
+		return nil, nil, domain.ErrFunctionNotFound
+	}
+	fn, err = h.store.GetFunctionByID(route.FunctionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fn, params, nil
+}
+
+// lookupFunction 根据路径参数 id（函数ID或名称）查找函数。
+// 查找失败时写入 404/500 错误响应并返回 false。
+func (h *Handler) lookupFunction(w http.ResponseWriter, r *http.Request) (*domain.Function, bool) {
+	idOrName := chi.URLParam(r, "id")
+	fn, err := h.store.GetFunctionByID(idOrName)
+	if err == domain.ErrFunctionNotFound {
+		fn, err = h.store.GetFunctionByName(idOrName)
+	}
+	if err == domain.ErrFunctionNotFound {
+		writeErrorWithContext(w, r, http.StatusNotFound, "function not found: "+idOrName)
+		return nil, false
+	}
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to get function: "+err.Error())
+		return nil, false
+	}
+	return fn, true
+}
+
+// checkReservedConcurrency 校验设置预留并发后，所有函数的预留总和不超过调度器容量（工作协程数量）。
+// 校验失败时写入错误响应并返回 false；调度器不支持查询容量时跳过校验。
+//
+// 参数：
+//   - functionID: 正在设置预留的函数 ID，新建函数时为空
+//   - reserved: 该函数新的预留并发数
+func (h *Handler) checkReservedConcurrency(w http.ResponseWriter, r *http.Request, functionID string, reserved int) bool {
+	if reserved <= 0 {
+		return true
+	}
+	scaler, ok := h.scheduler.(WorkerScaler)
+	if !ok {
+		return true
+	}
+
+	others, err := h.store.SumReservedConcurrency(functionID)
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to check reserved concurrency: "+err.Error())
+		return false
+	}
+	capacity := scaler.WorkerStats().ConfiguredWorkers
+	if others+reserved > capacity {
+		h.logWarn(r, "checkReservedConcurrency", "预留并发总和超出调度器容量", logrus.Fields{
+			"function_id": functionID,
+			"reserved":    reserved,
+			"others":      others,
+			"capacity":    capacity,
+		})
+		writeErrorWithContext(w, r, http.StatusConflict, fmt.Sprintf(
+			"%s: requested %d, already reserved %d, capacity %d",
+			domain.ErrReservedConcurrencyExceedsCapacity.Error(), reserved, others, capacity))
+		return false
+	}
+	return true
+}
+
+// resolveFunctionTags 解析请求路径中目标函数的标签，用于 API Key 标签作用域授权。
+func (h *Handler) resolveFunctionTags(r *http.Request) ([]string, error) {
+	idOrName := chi.URLParam(r, "id")
+	fn, err := h.store.GetFunctionByID(idOrName)
+	if err == domain.ErrFunctionNotFound {
+		fn, err = h.store.GetFunctionByName(idOrName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fn.Tags, nil
+}
+
+// errTagScopeForbidden 带标签作用域的 API Key 访问不匹配的函数时返回的错误信息
+const errTagScopeForbidden = "forbidden: api key is not scoped to this function"
+
+// checkFunctionScope 检查调用方 API Key 的标签作用域是否允许访问函数，不允许时返回 403。
+// 用于不在 /functions/{id} 路由下、由处理器自行定位函数的入口（自定义路由、Webhook）。
+//
+// 返回值:
+//   - bool: 允许访问返回 true
+func checkFunctionScope(w http.ResponseWriter, r *http.Request, fn *domain.Function) bool {
+	if auth.GetUser(r.Context()).CanAccessTags(fn.Tags) {
+		return true
+	}
+	writeErrorWithContext(w, r, http.StatusForbidden, errTagScopeForbidden)
+	return false
+}
+
+// invocationTagsResolver 返回按路径参数 param 指定的调用记录解析所属函数标签的解析器，
+// 用于调用记录相关路由的 API Key 标签作用域授权。调用记录不存在时返回错误，由处理器返回 404。
+func (h *Handler) invocationTagsResolver(param string) auth.FunctionTagsResolver {
+	return func(r *http.Request) ([]string, error) {
+		inv, err := h.store.GetInvocationByID(chi.URLParam(r, param))
+		if err != nil {
+			return nil, err
+		}
+		return h.functionTagsByID(inv.FunctionID), nil
+	}
+}
+
+// functionTagsByID 返回函数的标签。函数已删除或查询失败时返回 nil，
+// 此时带标签作用域的 API Key 不能访问该函数的调用记录。
+func (h *Handler) functionTagsByID(functionID string) []string {
+	fn, err := h.store.GetFunctionByID(functionID)
+	if err != nil {
+		return nil
+	}
+	return fn.Tags
+}
+
+// scopeInvocations 从批量查询结果中移除调用方 API Key 的标签作用域不允许访问的调用记录。
+// 被移除的记录与不存在的记录一样返回未找到，不暴露其存在。
+//
+// 参数:
+//   - user: 当前用户，nil 或未设置选择器时不做过滤
+//   - invocations: 调用 ID 到调用记录的映射，原地过滤
+//   - functionTags: 查询函数标签的函数，每个函数只查询一次
+func scopeInvocations(user *auth.UserContext, invocations map[string]*domain.Invocation, functionTags func(functionID string) []string) {
+	if user == nil || len(user.TagSelector) == 0 {
+		return
+	}
+	allowed := make(map[string]bool)
+	for id, inv := range invocations {
+		ok, seen := allowed[inv.FunctionID]
+		if !seen {
+			ok = user.CanAccessTags(functionTags(inv.FunctionID))
+			allowed[inv.FunctionID] = ok
+		}
+		if !ok {
+			delete(invocations, id)
+		}
+	}
+}
+
 // ========== 日志辅助方法 ==========
 
 // logInfo 记录信息级别日志
@@ -2473,6 +3930,9 @@ func (h *Handler) CreateFunctionAlias(w http.ResponseWriter, r *http.Request) {
 		writeErrorWithContext(w, r, http.StatusBadRequest, "routing weights must sum to 100")
 		return
 	}
+	if !checkSlotAlias(w, r, req.Name, req.RoutingConfig) {
+		return
+	}
 
 	alias := &domain.FunctionAlias{
 		FunctionID:    fn.ID,
@@ -2534,6 +3994,9 @@ func (h *Handler) UpdateFunctionAlias(w http.ResponseWriter, r *http.Request) {
 			writeErrorWithContext(w, r, http.StatusBadRequest, "routing weights must sum to 100")
 			return
 		}
+		if !checkSlotAlias(w, r, aliasName, *req.RoutingConfig) {
+			return
+		}
 		alias.RoutingConfig = *req.RoutingConfig
 	}
 
@@ -2541,6 +4004,7 @@ func (h *Handler) UpdateFunctionAlias(w http.ResponseWriter, r *http.Request) {
 		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to update alias: "+err.Error())
 		return
 	}
+	h.invalidateAliasCache(fn.ID)
 
 	h.logInfo(r, "UpdateFunctionAlias", "别名更新成功", logrus.Fields{"function": fn.Name, "alias": aliasName})
 	writeJSON(w, http.StatusOK, alias)
@@ -2565,10 +4029,17 @@ func (h *Handler) DeleteFunctionAlias(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 线上槽位的别名承接全部流量，不允许删除
+	if aliasName == fn.LiveSlot {
+		writeErrorWithContext(w, r, http.StatusConflict, "cannot delete the live slot alias, swap to the other slot first")
+		return
+	}
+
 	if err := h.store.DeleteFunctionAlias(fn.ID, aliasName); err != nil {
 		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to delete alias: "+err.Error())
 		return
 	}
+	h.invalidateAliasCache(fn.ID)
 
 	h.logInfo(r, "DeleteFunctionAlias", "别名删除成功", logrus.Fields{"function": fn.Name, "alias": aliasName})
 	w.WriteHeader(http.StatusNoContent)
@@ -2579,14 +4050,7 @@ func (h *Handler) DeleteFunctionAlias(w http.ResponseWriter, r *http.Request) {
 // ListLayers 获取所有层。
 // HTTP端点: GET /api/v1/layers
 func (h *Handler) ListLayers(w http.ResponseWriter, r *http.Request) {
-	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	if limit <= 0 {
-		limit = 20
-	}
-	if limit > 100 {
-		limit = 100
-	}
+	offset, limit := parsePagination(r)
 
 	layers, total, err := h.store.ListLayers(offset, limit)
 	if err != nil {
@@ -2594,12 +4058,7 @@ func (h *Handler) ListLayers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"layers": layers,
-		"total":  total,
-		"offset": offset,
-		"limit":  limit,
-	})
+	writePaginated(w, r, "layers", layers, total, offset, limit)
 }
 
 // CreateLayer 创建层。
@@ -2716,6 +4175,13 @@ func (h *Handler) CreateLayerVersion(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 计算解压后的总大小，用于校验函数挂载层的解压总大小上限
+	unpackedSize, err := domain.ZipUnpackedSize(content)
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// 计算哈希
 	hash := sha256.Sum256(content)
 	contentHash := hex.EncodeToString(hash[:])
@@ -2723,10 +4189,11 @@ func (h *Handler) CreateLayerVersion(w http.ResponseWriter, r *http.Request) {
 	// 创建新版本
 	newVersion := layer.LatestVersion + 1
 	lv := &domain.LayerVersion{
-		LayerID:     layer.ID,
-		Version:     newVersion,
-		ContentHash: contentHash,
-		SizeBytes:   int64(len(content)),
+		LayerID:           layer.ID,
+		Version:           newVersion,
+		ContentHash:       contentHash,
+		SizeBytes:         int64(len(content)),
+		UnpackedSizeBytes: unpackedSize,
 	}
 
 	if err := h.store.CreateLayerVersion(lv, content); err != nil {
@@ -2799,6 +4266,11 @@ func (h *Handler) SetFunctionLayers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 校验层数、解压总大小和运行时兼容性，避免函数在执行时才失败
+	if !h.validateFunctionLayers(w, r, fn, layers) {
+		return
+	}
+
 	// 设置顺序
 	for i := range layers {
 		layers[i].Order = i
@@ -3052,6 +4524,10 @@ func (h *Handler) OfflineFunction(w http.ResponseWriter, r *http.Request) {
 		h.cronManager.RemoveFunction(fn.ID)
 		h.logDebug(r, "OfflineFunction", "移除定时任务", logrus.Fields{"function": fn.Name})
 	}
+	// 释放函数预置的常驻实例
+	if fn.KeepWarm > 0 {
+		h.reconcileKeepWarm()
+	}
 
 	// 重新获取函数
 	fn, _ = h.store.GetFunctionByID(fn.ID)
@@ -3064,8 +4540,9 @@ func (h *Handler) OfflineFunction(w http.ResponseWriter, r *http.Request) {
 // HTTP端点: POST /api/v1/functions/{id}/online
 //
 // 功能说明：
-//   - 将函数状态从 offline 改为 active
+//   - 将函数状态从 offline 或 paused 改为 active
 //   - 上线后的函数可以被调用
+//   - 暂停期间积压的异步调用按入队顺序执行
 func (h *Handler) OnlineFunction(w http.ResponseWriter, r *http.Request) {
 	idOrName := chi.URLParam(r, "id")
 	if idOrName == "" {
@@ -3106,12 +4583,19 @@ func (h *Handler) OnlineFunction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 排空暂停队列（暂停后又下线的函数，队列在上线时同样恢复执行）
+	h.resumePaused(r, fn)
+
 	// 恢复定时任务
 	if h.cronManager != nil && fn.CronExpression != "" {
 		fn.Status = domain.FunctionStatusActive // 临时设置状态以便 cronManager 使用
 		h.cronManager.AddOrUpdateFunction(fn)
 		h.logDebug(r, "OnlineFunction", "恢复定时任务", logrus.Fields{"function": fn.Name, "cron": fn.CronExpression})
 	}
+	// 补齐函数预置的常驻实例
+	if fn.KeepWarm > 0 {
+		h.reconcileKeepWarm()
+	}
 
 	// 重新获取函数
 	fn, _ = h.store.GetFunctionByID(fn.ID)
@@ -3192,7 +4676,7 @@ func (h *Handler) RecompileFunction(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 异步执行编译
-	go h.processCreateFunctionTask(fn.ID, taskID)
+	go h.processCreateFunctionTask(fn.ID, taskID, fn.Status)
 
 	h.logInfo(r, "RecompileFunction", "重新编译任务已提交", logrus.Fields{"function": fn.Name, "id": fn.ID, "task_id": taskID})
 
@@ -3416,7 +4900,7 @@ func (h *Handler) ImportFunction(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 异步处理函数创建
-	go h.processCreateFunctionTask(fn.ID, taskID)
+	go h.processCreateFunctionTask(fn.ID, taskID, "")
 
 	h.logInfo(r, "ImportFunction", "函数导入成功", logrus.Fields{"function": fn.Name, "id": fn.ID})
 	writeJSON(w, http.StatusAccepted, map[string]interface{}{
@@ -3444,15 +4928,7 @@ func (h *Handler) ImportFunction(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) ListDLQMessages(w http.ResponseWriter, r *http.Request) {
 	functionID := r.URL.Query().Get("function_id")
 	status := r.URL.Query().Get("status")
-	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-
-	if limit <= 0 {
-		limit = 20
-	}
-	if limit > 100 {
-		limit = 100
-	}
+	offset, limit := parsePagination(r)
 
 	messages, total, err := h.store.ListDLQMessages(functionID, status, offset, limit)
 	if err != nil {
@@ -3461,12 +4937,7 @@ func (h *Handler) ListDLQMessages(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"messages": messages,
-		"total":    total,
-		"offset":   offset,
-		"limit":    limit,
-	})
+	writePaginated(w, r, "messages", messages, total, offset, limit)
 }
 
 // GetDLQMessage 获取死信消息详情。
@@ -3523,6 +4994,9 @@ func (h *Handler) RetryDLQMessage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 检查函数状态
+	if writePausedError(w, r, fn) {
+		return
+	}
 	if !fn.Status.CanInvoke() {
 		writeErrorWithContext(w, r, http.StatusBadRequest, "function is not active: "+string(fn.Status))
 		return
@@ -3743,6 +5217,149 @@ func (h *Handler) GetRetentionStats(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, stats)
 }
 
+// GetSchedulerWorkers 获取调度器工作协程状态。
+// HTTP端点: GET /api/v1/scheduler/workers
+//
+// 返回值：配置的协程数、正在处理任务的协程数、最大协程数以及队列深度
+func (h *Handler) GetSchedulerWorkers(w http.ResponseWriter, r *http.Request) {
+	scaler, ok := h.scheduler.(WorkerScaler)
+	if !ok {
+		writeErrorWithContext(w, r, http.StatusNotImplemented, "scheduler does not support worker scaling")
+		return
+	}
+	writeJSON(w, http.StatusOK, scaler.WorkerStats())
+}
+
+// GetScalingMetrics 导出供外部自动扩缩容器（Kubernetes HPA/KEDA）使用的扩缩容信号。
+// HTTP端点: GET /api/v1/scaling-metrics
+//
+// 查询参数:
+//   - runtime: 只返回该运行时的负载，结果字段平铺在顶层，便于 KEDA metrics-api 的 valueLocation 直接取值
+//   - target_utilization: 计算 desired_replicas 使用的目标利用率，取值 (0, 1]，默认 1
+func (h *Handler) GetScalingMetrics(w http.ResponseWriter, r *http.Request) {
+	reporter, ok := h.scheduler.(ScalingReporter)
+	if !ok {
+		writeErrorWithContext(w, r, http.StatusNotImplemented, "scheduler does not support scaling metrics")
+		return
+	}
+
+	target := 1.0
+	if v := r.URL.Query().Get("target_utilization"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f <= 0 || f > 1 {
+			writeErrorWithContext(w, r, http.StatusBadRequest, "target_utilization must be in (0, 1]")
+			return
+		}
+		target = f
+	}
+
+	m := reporter.ScalingMetrics()
+	if rt := r.URL.Query().Get("runtime"); rt != "" {
+		load := scheduler.RuntimeLoad{Runtime: rt}
+		for _, l := range m.Runtimes {
+			if l.Runtime == rt {
+				load = l
+			}
+		}
+		// 单运行时视图：以该运行时的负载计算利用率和期望实例数
+		m = scheduler.ScalingMetrics{
+			Workers:  m.Workers,
+			Queued:   load.Queued,
+			InFlight: load.InFlight,
+			Load:     load.Load,
+			Runtimes: []scheduler.RuntimeLoad{load},
+		}
+		if m.Workers > 0 {
+			m.Utilization = float64(m.Load) / float64(m.Workers)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"workers":            m.Workers,
+		"queued":             m.Queued,
+		"in_flight":          m.InFlight,
+		"load":               m.Load,
+		"utilization":        m.Utilization,
+		"target_utilization": target,
+		"desired_replicas":   m.DesiredReplicas(target),
+		"runtimes":           m.Runtimes,
+	})
+}
+
+// ScaleSchedulerWorkers 在运行时调整调度器工作协程数量。
+// HTTP端点: PUT /api/v1/scheduler/workers
+//
+// 请求体：{"workers": 20}，取值范围为 [1, max_workers]
+// 缩容时多余的协程会在处理完当前任务后退出，不影响正在执行的调用。
+func (h *Handler) ScaleSchedulerWorkers(w http.ResponseWriter, r *http.Request) {
+	scaler, ok := h.scheduler.(WorkerScaler)
+	if !ok {
+		writeErrorWithContext(w, r, http.StatusNotImplemented, "scheduler does not support worker scaling")
+		return
+	}
+
+	var req struct {
+		Workers int `json:"workers"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	before := scaler.WorkerStats()
+	if err := scaler.ScaleWorkers(req.Workers); err != nil {
+		if errors.Is(err, scheduler.ErrInvalidWorkerCount) {
+			writeErrorWithContext(w, r, http.StatusBadRequest, fmt.Sprintf("workers must be between 1 and %d", before.MaxWorkers))
+			return
+		}
+		if errors.Is(err, domain.ErrReservedConcurrencyExceedsCapacity) {
+			writeErrorWithContext(w, r, http.StatusConflict, fmt.Sprintf("workers must not be below total reserved concurrency %d", before.ReservedCapacity))
+			return
+		}
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to scale workers: "+err.Error())
+		return
+	}
+
+	h.logInfo(r, "ScaleSchedulerWorkers", "调度器工作协程数量已调整", logrus.Fields{
+		"previous":     before.ConfiguredWorkers,
+		"workers":      req.Workers,
+		"queue_length": before.QueueLength,
+	})
+	writeJSON(w, http.StatusOK, scaler.WorkerStats())
+}
+
+// DiagnoseFunction 在函数的执行容器中运行内置探针，返回实际执行环境诊断信息。
+// HTTP端点: POST /api/v1/functions/{id}/diagnostics
+//
+// 返回解析后的环境变量（值已脱敏）、内存上限、CPU 数量、tmpfs 容量、
+// 挂载的层、网络模式和运行时版本，探针不会执行用户代码。
+func (h *Handler) DiagnoseFunction(w http.ResponseWriter, r *http.Request) {
+	diagnoser, ok := h.scheduler.(Diagnoser)
+	if !ok {
+		writeErrorWithContext(w, r, http.StatusNotImplemented, "scheduler does not support diagnostics")
+		return
+	}
+
+	fn, ok := h.lookupFunction(w, r)
+	if !ok {
+		return
+	}
+
+	diag, err := diagnoser.Diagnose(r.Context(), fn)
+	if err != nil {
+		if errors.Is(err, scheduler.ErrDiagnosticsUnsupported) {
+			writeErrorWithContext(w, r, http.StatusNotImplemented, err.Error())
+			return
+		}
+		h.logError(r, "DiagnoseFunction", "执行环境诊断失败", err, logrus.Fields{"function": fn.Name})
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to diagnose function: "+err.Error())
+		return
+	}
+
+	h.logInfo(r, "DiagnoseFunction", "执行环境诊断完成", logrus.Fields{"function": fn.Name})
+	writeJSON(w, http.StatusOK, diag)
+}
+
 // RunRetentionCleanup 执行保留策略清理。
 // HTTP端点: POST /api/v1/retention/cleanup
 func (h *Handler) RunRetentionCleanup(w http.ResponseWriter, r *http.Request) {
@@ -3751,6 +5368,7 @@ func (h *Handler) RunRetentionCleanup(w http.ResponseWriter, r *http.Request) {
 	// 获取保留天数设置
 	logRetentionDays := 30 // 默认值
 	dlqRetentionDays := 90 // 默认值
+	versionRetention := 20 // 默认值，0 表示保留全部版本
 
 	if setting, err := h.store.GetSystemSetting("log_retention_days"); err == nil {
 		if days, err := strconv.Atoi(setting.Value); err == nil && days > 0 {
@@ -3762,6 +5380,11 @@ func (h *Handler) RunRetentionCleanup(w http.ResponseWriter, r *http.Request) {
 			dlqRetentionDays = days
 		}
 	}
+	if setting, err := h.store.GetSystemSetting("version_retention_count"); err == nil {
+		if n, err := strconv.Atoi(setting.Value); err == nil && n >= 0 {
+			versionRetention = n
+		}
+	}
 
 	// 清理调用记录
 	invocationsDeleted, err := h.store.CleanupOldInvocations(logRetentionDays)
@@ -3781,18 +5404,27 @@ func (h *Handler) RunRetentionCleanup(w http.ResponseWriter, r *http.Request) {
 		h.logError(r, "RunRetentionCleanup", "清理任务记录失败", err, nil)
 	}
 
+	// 压缩函数版本（别名引用的版本始终保留）
+	versionsDeleted, err := h.store.CompactFunctionVersions(versionRetention)
+	if err != nil {
+		h.logError(r, "RunRetentionCleanup", "压缩函数版本失败", err, nil)
+	}
+
 	h.logInfo(r, "RunRetentionCleanup", "保留策略清理完成", logrus.Fields{
 		"invocations_deleted": invocationsDeleted,
 		"dlq_deleted":         dlqDeleted,
 		"tasks_deleted":       tasksDeleted,
+		"versions_deleted":    versionsDeleted,
 	})
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"invocations_deleted": invocationsDeleted,
-		"dlq_deleted":         dlqDeleted,
-		"tasks_deleted":       tasksDeleted,
-		"log_retention_days":  logRetentionDays,
-		"dlq_retention_days":  dlqRetentionDays,
+		"invocations_deleted":     invocationsDeleted,
+		"dlq_deleted":             dlqDeleted,
+		"tasks_deleted":           tasksDeleted,
+		"versions_deleted":        versionsDeleted,
+		"log_retention_days":      logRetentionDays,
+		"dlq_retention_days":      dlqRetentionDays,
+		"version_retention_count": versionRetention,
 	})
 }
 
@@ -3834,15 +5466,7 @@ func (h *Handler) ListAuditLogs(w http.ResponseWriter, r *http.Request) {
 	action := r.URL.Query().Get("action")
 	resourceType := r.URL.Query().Get("resource_type")
 	resourceID := r.URL.Query().Get("resource_id")
-	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-
-	if limit <= 0 {
-		limit = 20
-	}
-	if limit > 100 {
-		limit = 100
-	}
+	offset, limit := parsePagination(r)
 
 	logs, total, err := h.store.ListAuditLogs(action, resourceType, resourceID, offset, limit)
 	if err != nil {
@@ -3850,12 +5474,7 @@ func (h *Handler) ListAuditLogs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"logs":   logs,
-		"total":  total,
-		"offset": offset,
-		"limit":  limit,
-	})
+	writePaginated(w, r, "logs", logs, total, offset, limit)
 }
 
 // GetAuditLogActions 获取所有审计操作类型。
@@ -4040,6 +5659,12 @@ func (h *Handler) RegenerateWebhookKey(w http.ResponseWriter, r *http.Request) {
 // HandleWebhook 处理 Webhook 触发的函数调用。
 // HTTP端点: POST /webhook/{key}
 func (h *Handler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	// 安全模式下不处理任何 Webhook 触发
+	if h.safeMode {
+		writeErrorWithContext(w, r, http.StatusServiceUnavailable, "webhooks are disabled: gateway is running in safe mode")
+		return
+	}
+
 	webhookKey := chi.URLParam(r, "key")
 
 	// 根据 webhook key 获取函数
@@ -4052,17 +5677,33 @@ func (h *Handler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to get function: "+err.Error())
 		return
 	}
+	if !checkFunctionScope(w, r, fn) {
+		return
+	}
 
-	// 检查函数状态
-	if !fn.Status.CanInvoke() {
-		writeErrorWithContext(w, r, http.StatusServiceUnavailable, fmt.Sprintf("function is not available (status: %s)", fn.Status))
+	// 检查函数状态：函数存在但暂时不可调用时返回 503 而不是 404
+	if writeUnavailableError(w, r, fn) {
+		return
+	}
+	if !h.checkDeprecation(w, r, fn, "HandleWebhook") {
 		return
 	}
 
-	// 读取请求体作为 payload
+	// 检查函数限流配置，并设置 X-RateLimit-* 响应头
+	if !h.checkRateLimit(w, r, fn) {
+		return
+	}
+
+	// 读取请求体作为 payload，请求体超过大小上限时返回 413
+	if !h.limitPayload(w, r, fn) {
+		return
+	}
 	var payload interface{}
 	if r.Body != nil && r.ContentLength != 0 {
 		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			if writePayloadTooLargeError(w, r, err) {
+				return
+			}
 			// 如果不是 JSON，尝试读取为字符串
 			payload = map[string]interface{}{
 				"raw_body": err.Error(),
@@ -4083,20 +5724,42 @@ func (h *Handler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 	// 将 payload 转换为 JSON
 	payloadBytes, _ := json.Marshal(webhookPayload)
 
+	// 检查函数是否允许在请求的环境中调用
+	if !h.checkInvokeEnvironment(w, r, fn) {
+		return
+	}
+
 	// 构建调用请求
+	costTags, ok := parseCostTags(w, r)
+	if !ok {
+		return
+	}
 	req := &domain.InvokeRequest{
 		FunctionID: fn.ID,
 		Payload:    payloadBytes,
 		Async:      false,
+		CostTags:   costTags,
+		Trigger:    domain.TriggerWebhook,
 	}
 
-	// 通过调度器同步执行函数
+	// 通过调度器同步执行函数，并广播调用开始与结束日志
+	requestID := generateRequestID()
+	broadcastInvocationStart(fn, domain.LogSourceWebhook, requestID, payloadBytes)
+	startTime := time.Now()
 	resp, err := h.scheduler.Invoke(req)
+	broadcastInvocationResult(fn, domain.LogSourceWebhook, requestID, payloadBytes, resp, err, time.Since(startTime).Milliseconds())
 	if err != nil {
+		if writeKillSwitchError(w, r, err) {
+			return
+		}
+		if writeMaintenanceError(w, r, err) {
+			return
+		}
 		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to invoke function: "+err.Error())
 		return
 	}
 
+	setInvocationHeaders(w, r, resp)
 	writeJSON(w, http.StatusOK, resp)
 }
 
@@ -4134,19 +5797,10 @@ func (h *Handler) ListTemplates(w http.ResponseWriter, r *http.Request) {
 	h.logDebug(r, "ListTemplates", "查询模板列表", nil)
 
 	// 解析分页参数
-	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, limit := parsePagination(r)
 	category := r.URL.Query().Get("category")
 	runtimeFilter := r.URL.Query().Get("runtime")
 
-	// 设置limit的默认值和最大值限制
-	if limit <= 0 {
-		limit = 20
-	}
-	if limit > 100 {
-		limit = 100
-	}
-
 	templates, total, err := h.store.ListTemplates(offset, limit, category, runtimeFilter)
 	if err != nil {
 		h.logError(r, "ListTemplates", "查询模板列表失败", err, nil)
@@ -4156,12 +5810,7 @@ func (h *Handler) ListTemplates(w http.ResponseWriter, r *http.Request) {
 
 	h.logDebug(r, "ListTemplates", "查询成功", logrus.Fields{"count": len(templates), "total": total})
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"templates": templates,
-		"total":     total,
-		"offset":    offset,
-		"limit":     limit,
-	})
+	writePaginated(w, r, "templates", templates, total, offset, limit)
 }
 
 // GetTemplate 处理获取单个模板详情的请求。
@@ -4423,15 +6072,7 @@ func (h *Handler) CreateFunctionFromTemplate(w http.ResponseWriter, r *http.Requ
 	}
 
 	// 替换模板变量
-	code := template.Code
-	for _, v := range template.Variables {
-		placeholder := "{{" + v.Name + "}}"
-		value := v.Default
-		if val, ok := req.Variables[v.Name]; ok {
-			value = val
-		}
-		code = strings.ReplaceAll(code, placeholder, value)
-	}
+	code := template.Render(req.Variables)
 
 	// 设置内存和超时
 	memoryMB := template.DefaultMemory
@@ -4494,7 +6135,7 @@ func (h *Handler) CreateFunctionFromTemplate(w http.ResponseWriter, r *http.Requ
 	}
 
 	// 异步处理任务
-	go h.processCreateFunctionTask(fn.ID, taskID)
+	go h.processCreateFunctionTask(fn.ID, taskID, "")
 
 	// 记录审计日志
 	h.auditLog(r, "function_create_from_template", "function", fn.ID, fn.Name, map[string]interface{}{
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/compiler"
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// SetHandlerCheckModes 设置按运行时的部署时入口函数检查模式，需在处理请求和恢复编译任务之前调用。
+// 未配置的运行时使用 compiler.DefaultHandlerCheckModes，不合法的模式被忽略。
+//
+// 参数：
+//   - modes: 运行时名称到检查模式（strict/warn/off）的映射
+func (h *Handler) SetHandlerCheckModes(modes map[string]string) {
+	merged := make(map[string]string, len(compiler.DefaultHandlerCheckModes)+len(modes))
+	for runtime, mode := range compiler.DefaultHandlerCheckModes {
+		merged[runtime] = mode
+	}
+	for runtime, mode := range modes {
+		if !compiler.ValidHandlerCheckMode(mode) {
+			h.logger.WithFields(logrus.Fields{"runtime": runtime, "mode": mode}).Warn("Ignoring invalid handler check mode")
+			continue
+		}
+		merged[runtime] = mode
+	}
+	h.handlerChecks = merged
+}
+
+// checkHandlerSignature 按函数运行时配置的检查模式静态检查入口函数。
+//
+// 返回值:
+//   - string: warn 模式下检查不通过的警告信息，通过或未检查时为空
+//   - error: strict 模式下检查不通过时返回错误，部署应失败
+func (h *Handler) checkHandlerSignature(fn *domain.Function) (string, error) {
+	modes := h.handlerChecks
+	if modes == nil {
+		modes = compiler.DefaultHandlerCheckModes
+	}
+	mode := modes[string(fn.Runtime)]
+	if mode == "" || mode == compiler.HandlerCheckOff {
+		return "", nil
+	}
+	err := compiler.CheckHandler(string(fn.Runtime), fn.Code, fn.Handler)
+	if err == nil {
+		return "", nil
+	}
+	if mode == compiler.HandlerCheckWarn {
+		return err.Error(), nil
+	}
+	return "", err
+}
@@ -3,11 +3,16 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/oriys/nimbus/internal/auth"
 	"github.com/oriys/nimbus/internal/domain"
 )
 
@@ -282,3 +287,277 @@ func TestLive(t *testing.T) {
 		t.Errorf("Live() status = %s, want alive", resp["status"])
 	}
 }
+
+// fakeRedisPinger 是就绪检查使用的模拟 Redis
+type fakeRedisPinger struct {
+	err error
+}
+
+func (p fakeRedisPinger) Ping(ctx context.Context) error {
+	return p.err
+}
+
+// TestReadinessRedisDegraded 测试 Redis 不可用时的就绪检查。
+//
+// 测试内容：
+//   - Redis 不可用时依赖 Redis 的功能降级放行，服务仍返回 200 并标记为 degraded
+//   - Redis 正常或未配置时返回 ready
+func TestReadinessRedisDegraded(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+	h := &Handler{}
+
+	code, resp := h.readiness(req, fakeRedisPinger{err: errors.New("connection refused")})
+	if code != http.StatusOK {
+		t.Errorf("readiness() status = %d, want %d", code, http.StatusOK)
+	}
+	if resp["status"] != "degraded" {
+		t.Errorf("readiness() status = %v, want degraded", resp["status"])
+	}
+	if checks := resp["checks"].(map[string]string); checks["redis"] != "unavailable" {
+		t.Errorf("redis check = %q, want unavailable", checks["redis"])
+	}
+
+	code, resp = h.readiness(req, fakeRedisPinger{})
+	if code != http.StatusOK || resp["status"] != "ready" {
+		t.Errorf("readiness() with healthy redis = %d %v, want 200 ready", code, resp["status"])
+	}
+
+	code, resp = h.readiness(req, nil)
+	if _, ok := resp["checks"].(map[string]string)["redis"]; ok || code != http.StatusOK || resp["status"] != "ready" {
+		t.Errorf("readiness() without redis = %d %v, want 200 ready without redis check", code, resp)
+	}
+}
+
+// TestSafeMode 测试安全模式。
+//
+// 测试内容：
+//   - 安全模式下 Webhook 触发返回 503，不查询函数
+//   - 就绪探针响应附带 safe_mode 标记，关闭后不再附带
+func TestSafeMode(t *testing.T) {
+	h := &Handler{}
+	h.SetSafeMode(true)
+
+	w := httptest.NewRecorder()
+	h.HandleWebhook(w, httptest.NewRequest(http.MethodPost, "/webhook/abc", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("HandleWebhook() status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+	if _, resp := h.readiness(req, nil); resp["safe_mode"] != true {
+		t.Errorf("readiness() safe_mode = %v, want true", resp["safe_mode"])
+	}
+
+	h.SetSafeMode(false)
+	if _, resp := h.readiness(req, nil); resp["safe_mode"] != nil {
+		t.Errorf("readiness() safe_mode = %v, want absent outside safe mode", resp["safe_mode"])
+	}
+}
+
+// TestBuildRegistry 测试编译任务的登记与取消。
+//
+// 测试内容：
+//   - 取消正在执行的任务会以 errBuildCancelled 取消编译 context
+//   - finish 返回任务是否已被取消，注销后的任务不能再被取消
+func TestBuildRegistry(t *testing.T) {
+	var b buildRegistry
+
+	if b.cancel("unknown") {
+		t.Error("cancel() of unknown task = true, want false")
+	}
+
+	ctx, release := b.start("task-1", time.Minute)
+	defer release()
+	if !b.cancel("task-1") {
+		t.Fatal("cancel() of running task = false, want true")
+	}
+	if !errors.Is(context.Cause(ctx), errBuildCancelled) {
+		t.Errorf("context cause = %v, want errBuildCancelled", context.Cause(ctx))
+	}
+	if !b.finish("task-1") {
+		t.Error("finish() of cancelled task = false, want true")
+	}
+	if b.finish("task-1") || b.cancel("task-1") {
+		t.Error("finished task should no longer be cancellable")
+	}
+
+	// 正常结束的任务：finish 返回 false，之后的取消请求被拒绝
+	_, release2 := b.start("task-2", time.Minute)
+	defer release2()
+	if b.finish("task-2") {
+		t.Error("finish() of completed task = true, want false")
+	}
+	if b.cancel("task-2") {
+		t.Error("cancel() after finish = true, want false")
+	}
+}
+
+// TestMergeTagSelector 测试标签选择器合并：保留调用者的选择器，去除空白和重复标签。
+func TestMergeTagSelector(t *testing.T) {
+	got := mergeTagSelector([]string{" env:prod ", "team:payments", ""}, []string{"team:payments"})
+	want := []string{"team:payments", "env:prod"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("mergeTagSelector() = %v, want %v", got, want)
+	}
+	if got := mergeTagSelector(nil, nil); len(got) != 0 {
+		t.Errorf("mergeTagSelector(nil, nil) = %v, want empty", got)
+	}
+}
+
+// TestCheckFunctionScope 测试自定义路由和 Webhook 使用的函数标签作用域检查。
+func TestCheckFunctionScope(t *testing.T) {
+	fn := &domain.Function{ID: "fn-1", Tags: []string{"team:search"}}
+	scoped := &auth.UserContext{UserID: "u1", TagSelector: []string{"team:payments"}}
+
+	tests := []struct {
+		name string
+		user *auth.UserContext
+		want int
+	}{
+		{"anonymous", nil, http.StatusOK},
+		{"unscoped key", &auth.UserContext{UserID: "u2"}, http.StatusOK},
+		{"scoped key on other team's function", scoped, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/hooks/search", nil)
+		if tt.user != nil {
+			req = req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, tt.user))
+		}
+		w := httptest.NewRecorder()
+		if ok := checkFunctionScope(w, req, fn); ok != (tt.want == http.StatusOK) {
+			t.Errorf("%s: checkFunctionScope() = %v", tt.name, ok)
+		}
+		if tt.want != http.StatusOK && w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+// TestScopeInvocations 测试批量获取调用记录时按 API Key 标签作用域过滤，每个函数只查询一次标签。
+func TestScopeInvocations(t *testing.T) {
+	newInvocations := func() map[string]*domain.Invocation {
+		return map[string]*domain.Invocation{
+			"inv-1": {ID: "inv-1", FunctionID: "payments-api"},
+			"inv-2": {ID: "inv-2", FunctionID: "search-api"},
+			"inv-3": {ID: "inv-3", FunctionID: "payments-api"},
+			"inv-4": {ID: "inv-4", FunctionID: "deleted"},
+		}
+	}
+	lookups := map[string]int{}
+	tagsOf := func(functionID string) []string {
+		lookups[functionID]++
+		switch functionID {
+		case "payments-api":
+			return []string{"team:payments"}
+		case "search-api":
+			return []string{"team:search"}
+		}
+		return nil
+	}
+
+	invocations := newInvocations()
+	scopeInvocations(&auth.UserContext{TagSelector: []string{"team:payments"}}, invocations, tagsOf)
+	if len(invocations) != 2 || invocations["inv-1"] == nil || invocations["inv-3"] == nil {
+		t.Errorf("scoped result = %v, want inv-1 and inv-3", invocations)
+	}
+	if lookups["payments-api"] != 1 {
+		t.Errorf("function tags looked up %d times, want 1", lookups["payments-api"])
+	}
+
+	// 未设置选择器时不过滤
+	invocations = newInvocations()
+	scopeInvocations(&auth.UserContext{}, invocations, tagsOf)
+	scopeInvocations(nil, invocations, tagsOf)
+	if len(invocations) != 4 {
+		t.Errorf("unscoped result has %d invocations, want 4", len(invocations))
+	}
+}
+
+// TestBatchGetInvocationsTagScope 测试带标签作用域的 API Key 批量获取调用记录：
+// 作用域外函数的记录与不存在的记录一样返回 found=false，不返回记录内容。
+func TestBatchGetInvocationsTagScope(t *testing.T) {
+	fetch := func(ids []string) (map[string]*domain.Invocation, error) {
+		all := map[string]*domain.Invocation{
+			"inv-in":  {ID: "inv-in", FunctionID: "payments-api"},
+			"inv-out": {ID: "inv-out", FunctionID: "search-api"},
+		}
+		found := make(map[string]*domain.Invocation)
+		for _, id := range ids {
+			if inv, ok := all[id]; ok {
+				found[id] = inv
+			}
+		}
+		return found, nil
+	}
+	tagsOf := func(functionID string) []string {
+		if functionID == "payments-api" {
+			return []string{"team:payments"}
+		}
+		return []string{"team:search"}
+	}
+
+	h := &Handler{}
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/invocations/batch-get", nil)
+	r = r.WithContext(context.WithValue(r.Context(), auth.UserContextKey, &auth.UserContext{TagSelector: []string{"team:payments"}}))
+	w := httptest.NewRecorder()
+	h.writeBatchGetInvocations(w, r, []string{"inv-in", "inv-out", "inv-missing"}, fetch, tagsOf)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var resp struct {
+		Results []domain.BatchGetInvocationResult `json:"results"`
+		Found   int                               `json:"found"`
+		Missing int                               `json:"missing"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Found != 1 || resp.Missing != 2 || len(resp.Results) != 3 {
+		t.Fatalf("response = %+v, want 1 found and 2 missing", resp)
+	}
+	if r := resp.Results[0]; r.ID != "inv-in" || !r.Found || r.Invocation == nil {
+		t.Errorf("in-scope result = %+v, want found with invocation", r)
+	}
+	if r := resp.Results[1]; r.ID != "inv-out" || r.Found || r.Invocation != nil {
+		t.Errorf("out-of-scope result = %+v, want not found without invocation", r)
+	}
+}
+
+// TestErrorStackTraces 测试错误响应中的堆栈跟踪默认关闭，开启后按配置的帧数截断。
+func TestErrorStackTraces(t *testing.T) {
+	h := &Handler{}
+	t.Cleanup(func() { h.SetErrorStackTraces(false, 0) })
+
+	writeErr := func() ErrorResponse {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/functions/missing", nil)
+		writeErrorWithContext(w, r, http.StatusNotFound, "function not found")
+		var resp ErrorResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode error response: %v", err)
+		}
+		return resp
+	}
+
+	// 默认不向客户端暴露堆栈
+	if resp := writeErr(); resp.Stack != "" || resp.Error != "function not found" {
+		t.Errorf("default response = %+v, want error without stack", resp)
+	}
+
+	// 开启后堆栈从调用 writeErrorWithContext 的位置开始，最多 maxDepth 帧
+	h.SetErrorStackTraces(true, 2)
+	resp := writeErr()
+	if !strings.Contains(resp.Stack, "TestErrorStackTraces") {
+		t.Errorf("stack = %q, want caller frames", resp.Stack)
+	}
+	if frames := strings.Count(resp.Stack, "\n\t"); frames == 0 || frames > 2 {
+		t.Errorf("stack frames = %d, want 1-2", frames)
+	}
+
+	// 帧数 <= 0 时使用默认值
+	h.SetErrorStackTraces(true, 0)
+	if depth := stackTraceDepth.Load(); depth != defaultStackTraceDepth {
+		t.Errorf("stack depth = %d, want default %d", depth, defaultStackTraceDepth)
+	}
+}
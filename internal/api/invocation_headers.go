@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// concurrencyRetryAfterSeconds 是函数达到最大并发数时 429 响应的 Retry-After 秒数
+const concurrencyRetryAfterSeconds = 1
+
+// invocationHeaderNames 是调用元数据响应头列表，用于 CORS 的 Access-Control-Expose-Headers
+var invocationHeaderNames = strings.Join([]string{
+	domain.HeaderInvocationID, domain.HeaderColdStart, domain.HeaderDurationMs, domain.HeaderBilledMs,
+	domain.HeaderResponseCache, domain.HeaderMeta,
+}, ", ")
+
+// setInvocationHeaders 根据调用结果设置调用元数据响应头（调用 ID、冷启动、执行耗时、计费时长），
+// 不改变响应体结构。函数通过响应信封返回了元数据且请求携带 X-Nimbus-Include-Meta: true 时，
+// 同时以 X-Nimbus-Meta 返回元数据。调用因并发限制被拒绝（429）时设置 Retry-After。需在写入状态码之前调用。
+func setInvocationHeaders(w http.ResponseWriter, r *http.Request, resp *domain.InvokeResponse) {
+	header := w.Header()
+	header.Set(domain.HeaderInvocationID, resp.RequestID)
+	header.Set(domain.HeaderColdStart, strconv.FormatBool(resp.ColdStart))
+	header.Set(domain.HeaderDurationMs, strconv.FormatInt(resp.DurationMs, 10))
+	header.Set(domain.HeaderBilledMs, strconv.FormatInt(resp.BilledTimeMs, 10))
+	if resp.StatusCode == http.StatusTooManyRequests {
+		header.Set("Retry-After", strconv.Itoa(concurrencyRetryAfterSeconds))
+	}
+	if len(resp.Meta) > 0 {
+		if include, _ := strconv.ParseBool(r.Header.Get(domain.HeaderIncludeMeta)); include {
+			header.Set(domain.HeaderMeta, string(resp.Meta))
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+func TestSetInvocationHeaders(t *testing.T) {
+	resp := &domain.InvokeResponse{
+		RequestID:    "inv-1",
+		StatusCode:   http.StatusOK,
+		ColdStart:    true,
+		DurationMs:   42,
+		BilledTimeMs: 100,
+		Meta:         json.RawMessage(`{"trace":"abc"}`),
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/functions/demo/invoke", nil)
+	setInvocationHeaders(w, r, resp)
+
+	want := map[string]string{
+		domain.HeaderInvocationID: "inv-1",
+		domain.HeaderColdStart:    "true",
+		domain.HeaderDurationMs:   "42",
+		domain.HeaderBilledMs:     "100",
+	}
+	for name, value := range want {
+		if got := w.Header().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+	// 未请求时不返回元数据，非 429 响应不设置 Retry-After
+	if got := w.Header().Get(domain.HeaderMeta); got != "" {
+		t.Errorf("%s = %q, want empty without %s", domain.HeaderMeta, got, domain.HeaderIncludeMeta)
+	}
+	if got := w.Header().Get("Retry-After"); got != "" {
+		t.Errorf("Retry-After = %q, want empty", got)
+	}
+
+	// 请求携带 X-Nimbus-Include-Meta: true 时返回元数据；429 响应设置 Retry-After
+	resp.StatusCode = http.StatusTooManyRequests
+	w = httptest.NewRecorder()
+	r.Header.Set(domain.HeaderIncludeMeta, "true")
+	setInvocationHeaders(w, r, resp)
+	if got := w.Header().Get(domain.HeaderMeta); got != `{"trace":"abc"}` {
+		t.Errorf("%s = %q, want function meta", domain.HeaderMeta, got)
+	}
+	if got := w.Header().Get("Retry-After"); got == "" {
+		t.Error("Retry-After missing on 429 response")
+	}
+}
+
+func TestCORSExposesInvocationHeaders(t *testing.T) {
+	h := &Handler{}
+	w := httptest.NewRecorder()
+	h.corsMiddleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).
+		ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/functions", nil))
+
+	exposed := w.Header().Get("Access-Control-Expose-Headers")
+	for _, name := range []string{domain.HeaderInvocationID, domain.HeaderColdStart, domain.HeaderDurationMs, domain.HeaderBilledMs} {
+		if !strings.Contains(exposed, name) {
+			t.Errorf("Access-Control-Expose-Headers = %q, missing %s", exposed, name)
+		}
+	}
+}
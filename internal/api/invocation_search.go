@@ -0,0 +1,100 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/auth"
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// SearchInvocations 按函数、状态、时间范围和文本内容搜索调用记录。
+// HTTP端点: GET /api/v1/invocations/search
+//
+// 查询参数：
+//   - function: 函数 ID 或名称
+//   - status: 调用状态
+//   - since, until: 创建时间范围（RFC 3339），since 含、until 不含
+//   - error_contains: 错误信息包含的文本（大小写不敏感）
+//   - payload_contains: 输入或输出包含的文本（大小写不敏感），需要开启 storage.postgres.invocation_payload_search
+//   - offset, limit: 分页参数
+//
+// 返回值：
+//   - 200: 成功，返回分页的调用记录，按创建时间倒序
+//   - 400: 搜索条件无效，或未开启载荷搜索时指定了 payload_contains
+//   - 404: 指定的函数不存在
+func (h *Handler) SearchInvocations(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := &domain.InvocationSearchQuery{
+		Status:          domain.InvocationStatus(query.Get("status")),
+		ErrorContains:   strings.TrimSpace(query.Get("error_contains")),
+		PayloadContains: strings.TrimSpace(query.Get("payload_contains")),
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"since", &q.Since}, {"until", &q.Until}} {
+		v := strings.TrimSpace(query.Get(p.name))
+		if v == "" {
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, "invalid '"+p.name+"' timestamp, expected RFC 3339")
+			return
+		}
+		*p.dst = &ts
+	}
+
+	if ref := strings.TrimSpace(query.Get("function")); ref != "" {
+		fn, err := h.store.GetFunctionByID(ref)
+		if errors.Is(err, domain.ErrFunctionNotFound) {
+			fn, err = h.store.GetFunctionByName(ref)
+		}
+		if errors.Is(err, domain.ErrFunctionNotFound) {
+			writeErrorWithContext(w, r, http.StatusNotFound, "function not found: "+ref)
+			return
+		}
+		if err != nil {
+			writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to get function: "+err.Error())
+			return
+		}
+		q.FunctionID = fn.ID
+	}
+
+	// 带标签作用域的 API Key 只能搜索匹配标签的函数的调用记录
+	if user := auth.GetUser(r.Context()); user != nil && len(user.TagSelector) > 0 {
+		q.FunctionTags = user.TagSelector
+	}
+
+	if err := q.Validate(); err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if q.PayloadContains != "" && !h.store.InvocationPayloadSearchEnabled() {
+		writeErrorWithContext(w, r, http.StatusBadRequest, domain.ErrInvocationPayloadSearchDisabled.Error())
+		return
+	}
+
+	offset, limit := parsePagination(r)
+	invocations, total, err := h.store.SearchInvocations(q, offset, limit)
+	if err != nil {
+		h.logError(r, "SearchInvocations", "搜索调用记录失败", err, logrus.Fields{"function_id": q.FunctionID})
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to search invocations")
+		return
+	}
+
+	h.logDebug(r, "SearchInvocations", "搜索完成", logrus.Fields{
+		"function_id":      q.FunctionID,
+		"status":           q.Status,
+		"error_contains":   q.ErrorContains != "",
+		"payload_contains": q.PayloadContains != "",
+		"total":            total,
+	})
+	writePaginated(w, r, "invocations", invocations, total, offset, limit)
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// invokeStreamBuffer 是流式调用输出的缓冲块数，客户端消费过慢时丢弃新输出，不阻塞函数执行
+const invokeStreamBuffer = 256
+
+// invokeStreamResult 是流式调用中调度器返回的结果
+type invokeStreamResult struct {
+	resp *domain.InvokeResponse
+	err  error
+}
+
+// InvokeFunctionStream 同步调用函数，并以 Server-Sent Events 边执行边推送容器输出。
+// HTTP端点: POST /api/v1/functions/{id}/invoke-stream
+//
+// 事件：
+//   - output: 一段 stdout/stderr 输出，数据为 domain.ExecOutputChunk
+//   - result: 调用结束，数据为调用结果（含 status_code、billed_time_ms 等），之后关闭连接
+//   - error: 调度失败（如队列已满、维护窗口），数据为 {"error": ...}，之后关闭连接
+//
+// 调用前的检查（函数状态、环境、限流等）与同步调用相同，失败时返回普通 JSON 错误响应。
+// 流不受网关 60 秒请求超时和写超时限制，持续到函数完成或超时。
+// 客户端断开后（最迟在下一次心跳时发现）不再推送，调用继续执行直到完成或超时，结果照常记录在调用记录中。
+func (h *Handler) InvokeFunctionStream(w http.ResponseWriter, r *http.Request) {
+	fn, ok := h.lookupFunction(w, r)
+	if !ok {
+		return
+	}
+	if writePausedError(w, r, fn) {
+		return
+	}
+	if !fn.Status.CanInvoke() {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "function is not active, current status: "+string(fn.Status))
+		return
+	}
+	if !h.checkDeprecation(w, r, fn, "InvokeFunctionStream") {
+		return
+	}
+	alias, ok := h.resolveInvokeAlias(w, r, fn)
+	if !ok {
+		return
+	}
+	if !h.checkInvokeEnvironment(w, r, fn) {
+		return
+	}
+	envCfg, ok := h.resolveEnvConfig(w, r, fn)
+	if !ok {
+		return
+	}
+	if alias, ok = h.resolveEnvAlias(w, r, fn, alias, envCfg); !ok {
+		return
+	}
+	setEffectiveConfigHeader(w, r, fn, envCfg, alias)
+	layers, ok := h.resolveLayerOverrides(w, r, fn)
+	if !ok {
+		return
+	}
+	if !h.checkRateLimit(w, r, fn) {
+		return
+	}
+
+	if !h.limitPayload(w, r, fn) {
+		return
+	}
+	var payload json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && err.Error() != "EOF" {
+		if writePayloadTooLargeError(w, r, err) {
+			return
+		}
+		writeErrorWithContext(w, r, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if payload == nil {
+		payload = json.RawMessage("{}")
+	}
+	costTags, ok := parseCostTags(w, r)
+	if !ok {
+		return
+	}
+
+	if _, ok := w.(http.Flusher); !ok {
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+	rc := http.NewResponseController(w)
+	// 长时间运行的函数可能超过服务器写超时，流式响应不设写截止时间
+	rc.SetWriteDeadline(time.Time{})
+
+	// 通道不关闭：调度器超时返回后执行协程可能仍在输出，发送方只做非阻塞发送
+	chunks := make(chan domain.ExecOutputChunk, invokeStreamBuffer)
+	req := &domain.InvokeRequest{
+		FunctionID: fn.ID,
+		Payload:    payload,
+		SessionKey: r.URL.Query().Get("session_key"),
+		CostTags:   costTags,
+		Alias:      alias,
+		RoutingKey: r.Header.Get(domain.HeaderRoutingKey),
+		Layers:     layers,
+		EnvConfig:  envCfg,
+		CallChain:  callChainFromRequest(r),
+		OutputSink: func(chunk domain.ExecOutputChunk) {
+			select {
+			case chunks <- chunk:
+			default:
+			}
+		},
+	}
+
+	requestID := generateRequestID()
+	broadcastInvocationStart(fn, domain.LogSourceAPI, requestID, payload)
+	h.logInfo(r, "InvokeFunctionStream", "开始流式调用函数", logrus.Fields{"function": fn.Name, "request_id": requestID})
+
+	startTime := time.Now()
+	done := make(chan invokeStreamResult, 1)
+	go func() {
+		resp, err := h.scheduler.Invoke(req)
+		done <- invokeStreamResult{resp: resp, err: err}
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	rc.Flush()
+
+	writeOutput := func(chunk domain.ExecOutputChunk) {
+		data, _ := json.Marshal(chunk)
+		fmt.Fprintf(w, "event: output\ndata: %s\n\n", data)
+	}
+	disconnected := func() {
+		h.logInfo(r, "InvokeFunctionStream", "客户端断开，停止推送", logrus.Fields{"function": fn.Name, "request_id": requestID})
+	}
+
+	heartbeat := time.NewTicker(execLogsHeartbeat)
+	defer heartbeat.Stop()
+	ctxDone := r.Context().Done()
+	for {
+		select {
+		case <-ctxDone:
+			// 请求超时中间件到期不代表客户端断开，继续推送，之后通过写入失败发现断开
+			if r.Context().Err() == context.DeadlineExceeded {
+				ctxDone = nil
+				continue
+			}
+			disconnected()
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			if rc.Flush() != nil {
+				disconnected()
+				return
+			}
+		case chunk := <-chunks:
+			writeOutput(chunk)
+			if rc.Flush() != nil {
+				disconnected()
+				return
+			}
+		case res := <-done:
+			// 先推送结果到达前已缓冲的输出，保证输出在结果之前
+			for drained := false; !drained; {
+				select {
+				case chunk := <-chunks:
+					writeOutput(chunk)
+				default:
+					drained = true
+				}
+			}
+
+			durationMs := time.Since(startTime).Milliseconds()
+			broadcastInvocationResult(fn, domain.LogSourceAPI, requestID, payload, res.resp, res.err, durationMs)
+			if res.err != nil {
+				h.logError(r, "InvokeFunctionStream", "函数调用失败", res.err, logrus.Fields{"function": fn.Name, "request_id": requestID})
+				data, _ := json.Marshal(map[string]string{"error": res.err.Error()})
+				fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
+			} else {
+				data, _ := json.Marshal(res.resp)
+				fmt.Fprintf(w, "event: result\ndata: %s\n\n", data)
+			}
+			rc.Flush()
+			return
+		}
+	}
+}
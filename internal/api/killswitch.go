@@ -0,0 +1,239 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/auth"
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// killSwitchTimeout 是读写紧急停止开关的 Redis 超时
+const killSwitchTimeout = 2 * time.Second
+
+// writeKillSwitchError 在调用因紧急停止开关被拒绝时写入 503 响应，并返回 true。
+// 响应包含 Retry-After 头（距离开关自动解除的秒数）以及开关的作用范围和原因；
+// err 不是紧急停止错误时不写入任何内容并返回 false。
+func writeKillSwitchError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var kerr *domain.KillSwitchError
+	if !errors.As(err, &kerr) {
+		return false
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(kerr.RetryAfter(time.Now())))
+	writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
+		"error":      domain.ErrKillSwitchEngaged.Error(),
+		"scope":      kerr.Switch.Scope,
+		"reason":     kerr.Switch.Reason,
+		"expires_at": kerr.Switch.ExpiresAt,
+		"request_id": middleware.GetReqID(r.Context()),
+	})
+	return true
+}
+
+// ==================== 全局紧急停止开关 ====================
+
+// EngageGlobalKillSwitch 开启全局紧急停止开关，拒绝平台上所有函数的调用。
+// HTTP端点: POST /api/v1/admin/kill-switch
+//
+// 请求体可选：reason 为开启原因，ttl_seconds 为有效期（默认 3600，最长 604800），到期自动解除。
+func (h *Handler) EngageGlobalKillSwitch(w http.ResponseWriter, r *http.Request) {
+	h.engageKillSwitch(w, r, nil)
+}
+
+// ReleaseGlobalKillSwitch 解除全局紧急停止开关。
+// HTTP端点: DELETE /api/v1/admin/kill-switch
+func (h *Handler) ReleaseGlobalKillSwitch(w http.ResponseWriter, r *http.Request) {
+	h.releaseKillSwitch(w, r, nil)
+}
+
+// GetGlobalKillSwitch 获取全局紧急停止开关的状态。
+// HTTP端点: GET /api/v1/admin/kill-switch
+func (h *Handler) GetGlobalKillSwitch(w http.ResponseWriter, r *http.Request) {
+	h.getKillSwitch(w, r, nil)
+}
+
+// ==================== 函数级紧急停止开关 ====================
+
+// EngageFunctionKillSwitch 开启函数级紧急停止开关，拒绝该函数的所有调用。
+// HTTP端点: POST /api/v1/functions/{id}/kill-switch
+//
+// 与下线不同，开关不修改函数状态，只在调用路径最开始拒绝调用，并在有效期结束后自动解除。
+func (h *Handler) EngageFunctionKillSwitch(w http.ResponseWriter, r *http.Request) {
+	fn, ok := h.lookupFunction(w, r)
+	if !ok {
+		return
+	}
+	h.engageKillSwitch(w, r, fn)
+}
+
+// ReleaseFunctionKillSwitch 解除函数级紧急停止开关。
+// HTTP端点: DELETE /api/v1/functions/{id}/kill-switch
+func (h *Handler) ReleaseFunctionKillSwitch(w http.ResponseWriter, r *http.Request) {
+	fn, ok := h.lookupFunction(w, r)
+	if !ok {
+		return
+	}
+	h.releaseKillSwitch(w, r, fn)
+}
+
+// GetFunctionKillSwitch 获取函数级紧急停止开关的状态。
+// HTTP端点: GET /api/v1/functions/{id}/kill-switch
+func (h *Handler) GetFunctionKillSwitch(w http.ResponseWriter, r *http.Request) {
+	fn, ok := h.lookupFunction(w, r)
+	if !ok {
+		return
+	}
+	h.getKillSwitch(w, r, fn)
+}
+
+// ==================== 内部实现 ====================
+
+// engageKillSwitch 开启全局（fn 为 nil）或函数级紧急停止开关并记录审计日志。
+func (h *Handler) engageKillSwitch(w http.ResponseWriter, r *http.Request, fn *domain.Function) {
+	// 开关保存在 Redis 中，由所有网关实例共享
+	if h.redis == nil {
+		writeErrorWithContext(w, r, http.StatusNotImplemented, "kill switch requires redis")
+		return
+	}
+
+	var req domain.EngageKillSwitchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	ttl, err := req.TTL()
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	now := time.Now()
+	ks := &domain.KillSwitch{
+		Scope:     domain.KillSwitchScopeGlobal,
+		Reason:    req.Reason,
+		EngagedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	if fn != nil {
+		ks.Scope = domain.KillSwitchScopeFunction
+		ks.FunctionID = fn.ID
+	}
+	if user := auth.GetUser(r.Context()); user != nil {
+		ks.EngagedBy = user.UserID
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), killSwitchTimeout)
+	defer cancel()
+	if err := h.redis.SetKillSwitch(ctx, ks); err != nil {
+		h.logError(r, "EngageKillSwitch", "开启紧急停止开关失败", err, killSwitchLogFields(ks, fn))
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to engage kill switch: "+err.Error())
+		return
+	}
+
+	h.logWarn(r, "EngageKillSwitch", "紧急停止开关已开启", killSwitchLogFields(ks, fn))
+	resourceType, resourceID, resourceName := killSwitchResource(fn)
+	h.auditLog(r, "kill_switch_engage", resourceType, resourceID, resourceName, map[string]interface{}{
+		"scope":      ks.Scope,
+		"reason":     ks.Reason,
+		"expires_at": ks.ExpiresAt,
+	})
+	writeJSON(w, http.StatusOK, ks)
+}
+
+// releaseKillSwitch 解除全局（fn 为 nil）或函数级紧急停止开关并记录审计日志。
+func (h *Handler) releaseKillSwitch(w http.ResponseWriter, r *http.Request, fn *domain.Function) {
+	if h.redis == nil {
+		writeErrorWithContext(w, r, http.StatusNotImplemented, "kill switch requires redis")
+		return
+	}
+
+	functionID := ""
+	if fn != nil {
+		functionID = fn.ID
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), killSwitchTimeout)
+	defer cancel()
+	released, err := h.redis.ClearKillSwitch(ctx, functionID)
+	if err != nil {
+		h.logError(r, "ReleaseKillSwitch", "解除紧急停止开关失败", err, logrus.Fields{"function_id": functionID})
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to release kill switch: "+err.Error())
+		return
+	}
+
+	if released {
+		h.logInfo(r, "ReleaseKillSwitch", "紧急停止开关已解除", logrus.Fields{"function_id": functionID})
+		resourceType, resourceID, resourceName := killSwitchResource(fn)
+		h.auditLog(r, "kill_switch_release", resourceType, resourceID, resourceName, nil)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"engaged":  false,
+		"released": released,
+	})
+}
+
+// getKillSwitch 返回全局（fn 为 nil）或函数级紧急停止开关的状态。
+// 函数级查询同时返回全局开关，便于判断函数的调用是否被拒绝。
+func (h *Handler) getKillSwitch(w http.ResponseWriter, r *http.Request, fn *domain.Function) {
+	if h.redis == nil {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"engaged": false})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), killSwitchTimeout)
+	defer cancel()
+	global, err := h.redis.GetKillSwitch(ctx, "")
+	if err != nil {
+		h.logError(r, "GetKillSwitch", "查询紧急停止开关失败", err, nil)
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to get kill switch: "+err.Error())
+		return
+	}
+	if fn == nil {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"engaged": global != nil,
+			"switch":  global,
+		})
+		return
+	}
+
+	own, err := h.redis.GetKillSwitch(ctx, fn.ID)
+	if err != nil {
+		h.logError(r, "GetKillSwitch", "查询紧急停止开关失败", err, logrus.Fields{"function": fn.Name})
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to get kill switch: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"engaged": own != nil || global != nil,
+		"switch":  own,
+		"global":  global,
+	})
+}
+
+// killSwitchResource 返回紧急停止开关审计日志的资源类型、ID 和名称。
+func killSwitchResource(fn *domain.Function) (string, string, string) {
+	if fn == nil {
+		return "platform", domain.KillSwitchScopeGlobal, ""
+	}
+	return "function", fn.ID, fn.Name
+}
+
+// killSwitchLogFields 返回紧急停止开关的日志字段。
+func killSwitchLogFields(ks *domain.KillSwitch, fn *domain.Function) logrus.Fields {
+	fields := logrus.Fields{
+		"scope":      ks.Scope,
+		"reason":     ks.Reason,
+		"expires_at": ks.ExpiresAt,
+	}
+	if fn != nil {
+		fields["function"] = fn.Name
+	}
+	return fields
+}
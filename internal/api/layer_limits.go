@@ -0,0 +1,87 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// SetLayerLimits 设置单个函数挂载层的数量和解压总大小上限，需在处理请求之前调用。
+//
+// 参数：
+//   - maxCount: 最多挂载的层数，<= 0 表示不限制
+//   - maxUnpackedMB: 所有层解压后的总大小上限（MB），<= 0 表示不限制
+func (h *Handler) SetLayerLimits(maxCount, maxUnpackedMB int) {
+	h.maxLayers = maxCount
+	h.maxLayersUnpacked = int64(maxUnpackedMB) << 20
+}
+
+// validateFunctionLayers 校验函数要挂载的层：层和版本必须存在、层必须兼容函数的运行时，
+// 层数和解压总大小不能超过上限。校验通过时将层名称解析为层 ID 并补全层名称。
+// 校验失败时写入错误响应并返回 false。
+func (h *Handler) validateFunctionLayers(w http.ResponseWriter, r *http.Request, fn *domain.Function, layers []domain.FunctionLayer) bool {
+	if h.maxLayers > 0 && len(layers) > h.maxLayers {
+		writeErrorWithContext(w, r, http.StatusBadRequest, fmt.Sprintf("%s: %d layers, at most %d allowed", domain.ErrTooManyLayers, len(layers), h.maxLayers))
+		return false
+	}
+
+	var total int64
+	for i, fl := range layers {
+		layer, err := h.store.GetLayerByID(fl.LayerID)
+		if err != nil {
+			layer, err = h.store.GetLayerByName(fl.LayerID)
+		}
+		if err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, "layer not found: "+fl.LayerID)
+			return false
+		}
+		if !layerSupportsRuntime(layer, fn.Runtime) {
+			writeErrorWithContext(w, r, http.StatusBadRequest, fmt.Sprintf("layer %s is not compatible with runtime %s", layer.Name, fn.Runtime))
+			return false
+		}
+		lv, err := h.store.GetLayerVersion(layer.ID, fl.LayerVersion)
+		if err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, fmt.Sprintf("layer %s version %d not found", layer.Name, fl.LayerVersion))
+			return false
+		}
+		total += h.layerUnpackedSize(r, lv)
+		layers[i].LayerID = layer.ID
+		layers[i].LayerName = layer.Name
+	}
+
+	if h.maxLayersUnpacked > 0 && total > h.maxLayersUnpacked {
+		h.logWarn(r, "validateFunctionLayers", "函数层解压总大小超过上限", logrus.Fields{
+			"function":       fn.Name,
+			"unpacked_bytes": total,
+			"limit_bytes":    h.maxLayersUnpacked,
+		})
+		writeErrorWithContext(w, r, http.StatusBadRequest, fmt.Sprintf("%s: %d MB unpacked, at most %d MB allowed", domain.ErrLayersTooLarge, (total+(1<<20)-1)>>20, h.maxLayersUnpacked>>20))
+		return false
+	}
+	return true
+}
+
+// layerUnpackedSize 返回层版本解压后的总大小。
+// 早于解压大小字段创建的版本没有记录该值，此时从层内容的 ZIP 中央目录计算。
+func (h *Handler) layerUnpackedSize(r *http.Request, lv *domain.LayerVersion) int64 {
+	if lv.UnpackedSizeBytes > 0 {
+		return lv.UnpackedSizeBytes
+	}
+	content, err := h.store.GetLayerVersionContent(lv.LayerID, lv.Version)
+	if err != nil {
+		h.logWarn(r, "layerUnpackedSize", "读取层内容失败，按压缩大小计算", logrus.Fields{
+			"layer_id": lv.LayerID,
+			"version":  lv.Version,
+			"error":    err.Error(),
+		})
+		return lv.SizeBytes
+	}
+	size, err := domain.ZipUnpackedSize(content)
+	if err != nil {
+		return lv.SizeBytes
+	}
+	return size
+}
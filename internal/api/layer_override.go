@@ -0,0 +1,81 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// resolveLayerOverrides 解析调用请求的 X-Nimbus-Layers 请求头，将层名称解析为层 ID 并校验版本。
+// 生产环境中不允许覆盖层，用于在不修改函数配置、不重新部署的情况下测试新的层版本。
+// 未指定请求头时返回 nil；校验失败时写入错误响应并返回 false。
+func (h *Handler) resolveLayerOverrides(w http.ResponseWriter, r *http.Request, fn *domain.Function) ([]domain.LayerOverride, bool) {
+	overrides, err := domain.ParseLayerOverrides(r.Header.Get(domain.HeaderLayers))
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	if overrides == nil {
+		return nil, true
+	}
+
+	env, ok := h.resolveInvokeEnvironment(w, r)
+	if !ok {
+		return nil, false
+	}
+	if env == domain.ProductionEnvironment {
+		h.logWarn(r, "resolveLayerOverrides", "生产环境不允许覆盖函数层", logrus.Fields{"function": fn.Name})
+		writeErrorWithContext(w, r, http.StatusForbidden, "layer override is not allowed in environment "+env)
+		return nil, false
+	}
+
+	for i, o := range overrides {
+		layer, err := h.store.GetLayerByID(o.Layer)
+		if err != nil {
+			layer, err = h.store.GetLayerByName(o.Layer)
+		}
+		if err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, "layer not found: "+o.Layer)
+			return nil, false
+		}
+		if !layerSupportsRuntime(layer, fn.Runtime) {
+			writeErrorWithContext(w, r, http.StatusBadRequest, fmt.Sprintf("layer %s is not compatible with runtime %s", layer.Name, fn.Runtime))
+			return nil, false
+		}
+		if _, err := h.store.GetLayerVersion(layer.ID, o.Version); err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, fmt.Sprintf("layer %s version %d not found", layer.Name, o.Version))
+			return nil, false
+		}
+		overrides[i].Layer = layer.ID
+	}
+
+	h.logInfo(r, "resolveLayerOverrides", "本次调用覆盖函数层", logrus.Fields{
+		"function":    fn.Name,
+		"environment": env,
+		"layers":      overrides,
+	})
+	return overrides, true
+}
+
+// layerSupportsRuntime 判断层是否兼容函数的运行时。
+func layerSupportsRuntime(layer *domain.Layer, runtime domain.Runtime) bool {
+	for _, rt := range layer.CompatibleRuntimes {
+		if rt == string(runtime) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeLayerOverrideError 在调度器不支持覆盖层时写入 501 响应并返回 true。
+func writeLayerOverrideError(w http.ResponseWriter, r *http.Request, err error) bool {
+	if !errors.Is(err, domain.ErrLayerOverrideUnsupported) {
+		return false
+	}
+	writeErrorWithContext(w, r, http.StatusNotImplemented, err.Error())
+	return true
+}
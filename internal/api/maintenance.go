@@ -0,0 +1,31 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// writeMaintenanceError 在调用因函数处于维护窗口被拒绝时写入 503 响应，并返回 true。
+// 响应包含 Retry-After 头（距离维护结束的秒数）以及维护原因和结束时间；
+// err 不是维护错误时不写入任何内容并返回 false。
+func writeMaintenanceError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var merr *domain.MaintenanceError
+	if !errors.As(err, &merr) {
+		return false
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(merr.RetryAfter(time.Now())))
+	writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
+		"error":      domain.ErrFunctionInMaintenance.Error(),
+		"reason":     merr.Reason,
+		"until":      merr.Until,
+		"request_id": middleware.GetReqID(r.Context()),
+	})
+	return true
+}
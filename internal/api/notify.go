@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+	"github.com/oriys/nimbus/internal/outbound"
+)
+
+// errChannelNotDeliverable 表示通知渠道类型不支持 HTTP 投递或缺少 url 配置
+var errChannelNotDeliverable = errors.New("channel does not support http delivery or has no url configured")
+
+// notificationBody 按渠道类型构造通知请求体。
+func notificationBody(ch *domain.NotificationChannel, title, message string) ([]byte, error) {
+	switch ch.Type {
+	case domain.NotificationChannelSlack:
+		return json.Marshal(map[string]string{"text": title + "\n" + message})
+	case domain.NotificationChannelDingtalk:
+		return json.Marshal(map[string]interface{}{
+			"msgtype": "text",
+			"text":    map[string]string{"content": title + "\n" + message},
+		})
+	case domain.NotificationChannelWebhook:
+		return json.Marshal(map[string]interface{}{
+			"channel":   ch.Name,
+			"title":     title,
+			"message":   message,
+			"timestamp": time.Now(),
+		})
+	default:
+		return nil, errChannelNotDeliverable
+	}
+}
+
+// deliverNotification 通过出站客户端向通知渠道发送一条消息。
+// 重试、超时和按目标主机的熔断由出站客户端统一处理。
+func (h *Handler) deliverNotification(ctx context.Context, ch *domain.NotificationChannel, title, message string) (*outbound.Response, error) {
+	target := ch.Config["url"]
+	if target == "" {
+		return nil, errChannelNotDeliverable
+	}
+	body, err := notificationBody(ch, title, message)
+	if err != nil {
+		return nil, err
+	}
+	return h.outbound.PostJSON(ctx, target, body, nil)
+}
+
+// TestNotificationChannel 向通知渠道发送一条测试消息，用于验证渠道配置。
+// POST /api/v1/alerts/channels/{id}/test
+func (h *Handler) TestNotificationChannel(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	ch, err := h.store.GetNotificationChannel(id)
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusNotFound, "notification channel not found")
+		return
+	}
+
+	resp, err := h.deliverNotification(r.Context(), ch, "Nimbus 测试通知", "通知渠道 "+ch.Name+" 配置正确")
+	if errors.Is(err, errChannelNotDeliverable) {
+		writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		h.logWarn(r, "TestNotificationChannel", "测试通知投递失败", logrus.Fields{"channel": ch.ID, "error": err.Error()})
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		writeErrorWithContext(w, r, http.StatusBadGateway, fmt.Sprintf("delivery failed (status %d): %v", status, err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"delivered":   true,
+		"status_code": resp.StatusCode,
+		"attempts":    resp.Attempts,
+	})
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// GetFunctionOpenAPI 导出单个函数自定义 HTTP 路由的 OpenAPI 3 文档。
+// HTTP端点: GET /api/v1/functions/{id}/openapi
+//
+// 返回值：
+//   - 200: 成功，返回 OpenAPI 文档，info.version 为函数的当前版本号
+//   - 400: 函数没有配置自定义 HTTP 路由
+//   - 404: 函数不存在
+func (h *Handler) GetFunctionOpenAPI(w http.ResponseWriter, r *http.Request) {
+	fn, ok := h.lookupFunction(w, r)
+	if !ok {
+		return
+	}
+	if fn.HTTPPath == "" {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "function has no custom HTTP route (http_path)")
+		return
+	}
+
+	doc := domain.BuildOpenAPIDocument(fn.Name, strconv.Itoa(fn.Version), []*domain.Function{fn})
+	doc.Info.Description = fn.Description
+	doc.Servers = openAPIServers(r)
+	writeJSON(w, http.StatusOK, doc)
+}
+
+// GetOpenAPI 导出所有函数自定义 HTTP 路由的 OpenAPI 3 文档。
+// HTTP端点: GET /api/v1/openapi
+func (h *Handler) GetOpenAPI(w http.ResponseWriter, r *http.Request) {
+	functions, err := h.store.ListRouteFunctions()
+	if err != nil {
+		h.logError(r, "GetOpenAPI", "查询自定义路由函数失败", err, nil)
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to list route functions: "+err.Error())
+		return
+	}
+
+	doc := domain.BuildOpenAPIDocument("Nimbus functions", "1.0.0", functions)
+	doc.Servers = openAPIServers(r)
+	h.logDebug(r, "GetOpenAPI", "导出 OpenAPI 文档", logrus.Fields{"functions": len(functions), "paths": len(doc.Paths)})
+	writeJSON(w, http.StatusOK, doc)
+}
+
+// openAPIServers 以当前请求的地址作为文档的 servers，自定义路由挂载在网关根路径下。
+func openAPIServers(r *http.Request) []domain.OpenAPIServer {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return []domain.OpenAPIServer{{URL: scheme + "://" + r.Host}}
+}
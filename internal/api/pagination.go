@@ -0,0 +1,104 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ==================== 分页辅助函数 ====================
+
+const (
+	// defaultPageLimit 未指定 limit 时的默认每页数量
+	defaultPageLimit = 20
+	// maxPageLimit 每页数量上限
+	maxPageLimit = 100
+)
+
+// parsePagination 解析请求中的 offset/limit 分页参数。
+// limit 默认为 20，最大为 100；offset 为负数时按 0 处理。
+func parsePagination(r *http.Request) (offset, limit int) {
+	offset, _ = strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, _ = strconv.Atoi(r.URL.Query().Get("limit"))
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	return offset, limit
+}
+
+// writePaginated 以统一格式写入分页列表响应。
+//
+// 响应体包含列表字段（键名由 key 指定）以及 total/offset/limit/has_more，
+// 同时按 RFC 5988 设置 Link 响应头（first、prev、next、last），
+// 链接保留原请求的其他查询参数，通用 HTTP 客户端可直接据此翻页。
+//
+// 参数：
+//   - key: 列表在响应体中的字段名，如 "functions"
+//   - items: 当前页数据
+//   - total: 符合条件的总数
+//   - offset, limit: 当前分页参数
+func writePaginated(w http.ResponseWriter, r *http.Request, key string, items interface{}, total, offset, limit int) {
+	if link := paginationLinks(r, total, offset, limit); link != "" {
+		w.Header().Set("Link", link)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		key:        items,
+		"total":    total,
+		"offset":   offset,
+		"limit":    limit,
+		"has_more": offset+limit < total,
+	})
+}
+
+// paginationLinks 构建 Link 响应头的值。
+// 没有可导航的页面时返回空字符串。
+func paginationLinks(r *http.Request, total, offset, limit int) string {
+	if limit <= 0 {
+		return ""
+	}
+
+	// 最后一页的起始偏移量
+	lastOffset := 0
+	if total > 0 {
+		lastOffset = ((total - 1) / limit) * limit
+	}
+
+	var links []string
+	add := func(rel string, off int) {
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, pageURL(r, off, limit), rel))
+	}
+
+	add("first", 0)
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		add("prev", prev)
+	}
+	if offset+limit < total {
+		add("next", offset+limit)
+	}
+	add("last", lastOffset)
+
+	return strings.Join(links, ", ")
+}
+
+// pageURL 基于当前请求生成指定偏移量的分页链接，保留其他查询参数。
+func pageURL(r *http.Request, offset, limit int) string {
+	query := r.URL.Query()
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+
+	u := *r.URL
+	u.RawQuery = query.Encode()
+	return u.RequestURI()
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestParsePagination 测试分页参数的默认值与边界处理。
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantOffset int
+		wantLimit  int
+	}{
+		{"", 0, 20},
+		{"?offset=40&limit=10", 40, 10},
+		{"?offset=-5&limit=500", 0, 100},
+		{"?limit=abc", 0, 20},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/functions"+tt.query, nil)
+		offset, limit := parsePagination(r)
+		if offset != tt.wantOffset || limit != tt.wantLimit {
+			t.Errorf("parsePagination(%q) = (%d, %d), want (%d, %d)", tt.query, offset, limit, tt.wantOffset, tt.wantLimit)
+		}
+	}
+}
+
+// TestWritePaginated 测试分页响应体与 Link 响应头。
+func TestWritePaginated(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/layers?offset=10&limit=10&runtime=go1.24", nil)
+	w := httptest.NewRecorder()
+
+	writePaginated(w, r, "layers", []string{"a"}, 45, 10, 10)
+
+	wantLink := `</api/v1/layers?limit=10&offset=0&runtime=go1.24>; rel="first", ` +
+		`</api/v1/layers?limit=10&offset=0&runtime=go1.24>; rel="prev", ` +
+		`</api/v1/layers?limit=10&offset=20&runtime=go1.24>; rel="next", ` +
+		`</api/v1/layers?limit=10&offset=40&runtime=go1.24>; rel="last"`
+	if got := w.Header().Get("Link"); got != wantLink {
+		t.Errorf("Link = %q, want %q", got, wantLink)
+	}
+
+	var resp map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp["total"] != float64(45) || resp["offset"] != float64(10) || resp["limit"] != float64(10) || resp["has_more"] != true {
+		t.Errorf("unexpected pagination fields: %v", resp)
+	}
+	if _, ok := resp["layers"]; !ok {
+		t.Errorf("response missing items key: %v", resp)
+	}
+}
+
+// TestPaginationLinksLastPage 测试最后一页不返回 next 链接。
+func TestPaginationLinksLastPage(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/templates?offset=0&limit=20", nil)
+	got := paginationLinks(r, 5, 0, 20)
+	want := `</api/v1/templates?limit=20&offset=0>; rel="first", </api/v1/templates?limit=20&offset=0>; rel="last"`
+	if got != want {
+		t.Errorf("paginationLinks() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// pausedBacklogTimeout 查询暂停队列长度的超时时间
+const pausedBacklogTimeout = 2 * time.Second
+
+// writePausedError 在函数已暂停时写入 503 响应并返回 true，函数未暂停时不写入任何内容并返回 false。
+// 用于同步调用入口：暂停期间只有异步调用会被接受并放入暂停队列。
+func writePausedError(w http.ResponseWriter, r *http.Request, fn *domain.Function) bool {
+	if fn.Status != domain.FunctionStatusPaused {
+		return false
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(fn.Status.RetryAfterSeconds()))
+	writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
+		"error":      domain.ErrFunctionPaused.Error(),
+		"hint":       "use async invocation to queue requests until the function is resumed",
+		"request_id": middleware.GetReqID(r.Context()),
+	})
+	return true
+}
+
+// writeUnavailableError 在函数存在但当前状态不接受同步调用时写入 503 响应（带 Retry-After）并返回 true，
+// 函数可调用时不写入任何内容并返回 false。
+// 用于自定义路由和 Webhook 入口：404 只表示路径或密钥没有对应的函数，
+// 函数下线、构建中、已暂停等暂时不可用的情况返回 503，客户端和 CDN 可据此重试而不缓存 404。
+func writeUnavailableError(w http.ResponseWriter, r *http.Request, fn *domain.Function) bool {
+	if writePausedError(w, r, fn) {
+		return true
+	}
+	if fn.Status.CanInvoke() {
+		return false
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(fn.Status.RetryAfterSeconds()))
+	writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
+		"error":      domain.ErrFunctionUnavailable.Error(),
+		"status":     fn.Status,
+		"request_id": middleware.GetReqID(r.Context()),
+	})
+	return true
+}
+
+// PauseFunction 暂停函数。
+// HTTP端点: POST /api/v1/functions/{id}/pause
+//
+// 功能说明：
+//   - 将函数状态从 active/degraded 改为 paused
+//   - 暂停期间同步调用返回 503，异步调用（包括定时触发）被接受并放入暂停队列
+//   - 通过 POST /resume（或 /online）恢复后，暂停队列中的调用按入队顺序执行
+func (h *Handler) PauseFunction(w http.ResponseWriter, r *http.Request) {
+	fn, ok := h.lookupFunction(w, r)
+	if !ok {
+		return
+	}
+
+	if !fn.Status.CanPause() {
+		h.logWarn(r, "PauseFunction", "函数状态不允许暂停", logrus.Fields{
+			"function": fn.Name,
+			"status":   fn.Status,
+		})
+		writeErrorWithContext(w, r, http.StatusBadRequest, "function cannot be paused in current status: "+string(fn.Status))
+		return
+	}
+	// 暂停队列保存在 Redis 中，Redis 不可用时暂停会导致异步调用被拒绝
+	if h.redis == nil {
+		writeErrorWithContext(w, r, http.StatusNotImplemented, "pausing requires redis for the paused queue")
+		return
+	}
+
+	if err := h.store.UpdateFunctionStatus(fn.ID, domain.FunctionStatusPaused, "函数已暂停，异步调用排队中", ""); err != nil {
+		h.logError(r, "PauseFunction", "更新函数状态失败", err, logrus.Fields{"function": fn.Name})
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to pause function: "+err.Error())
+		return
+	}
+
+	fn, _ = h.store.GetFunctionByID(fn.ID)
+
+	h.logInfo(r, "PauseFunction", "函数暂停成功", logrus.Fields{"function": fn.Name, "id": fn.ID})
+	writeJSON(w, http.StatusOK, fn)
+}
+
+// GetPausedBacklog 返回函数暂停队列中积压的异步调用数量。
+// HTTP端点: GET /api/v1/functions/{id}/paused-backlog
+func (h *Handler) GetPausedBacklog(w http.ResponseWriter, r *http.Request) {
+	fn, ok := h.lookupFunction(w, r)
+	if !ok {
+		return
+	}
+
+	var backlog int64
+	if h.redis != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), pausedBacklogTimeout)
+		defer cancel()
+		n, err := h.redis.PausedQueueLen(ctx, fn.ID)
+		if err != nil {
+			h.logError(r, "GetPausedBacklog", "查询暂停队列失败", err, logrus.Fields{"function": fn.Name})
+			writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to get paused backlog: "+err.Error())
+			return
+		}
+		backlog = n
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"function_id": fn.ID,
+		"status":      fn.Status,
+		"backlog":     backlog,
+	})
+}
+
+// resumePaused 在函数恢复为 active 后通知调度器排空暂停队列，队列为空时排空立即结束。
+func (h *Handler) resumePaused(r *http.Request, fn *domain.Function) {
+	resumer, ok := h.scheduler.(PausedResumer)
+	if !ok {
+		h.logWarn(r, "resumePaused", "调度器不支持排空暂停队列", logrus.Fields{"function": fn.Name})
+		return
+	}
+	resumer.ResumePaused(fn.ID)
+	h.logDebug(r, "resumePaused", "开始排空暂停队列", logrus.Fields{"function": fn.Name, "id": fn.ID})
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// defaultMaxPayloadBytes 是未设置全局上限时调用请求体的大小上限（6MB）
+const defaultMaxPayloadBytes = 6 << 20
+
+// SetMaxPayloadBytes 设置调用请求体（同步、异步、Webhook、自定义路由）的全局大小上限，
+// 函数通过 max_payload_bytes 单独设置时以函数设置为准。
+//
+// 参数：
+//   - n: 上限字节数，<= 0 时使用默认的 6MB
+func (h *Handler) SetMaxPayloadBytes(n int64) {
+	h.maxPayloadBytes = n
+}
+
+// payloadLimit 返回函数调用请求体的大小上限：函数设置优先，其次为全局设置。
+func (h *Handler) payloadLimit(fn *domain.Function) int64 {
+	if fn.MaxPayloadBytes > 0 {
+		return fn.MaxPayloadBytes
+	}
+	if h.maxPayloadBytes > 0 {
+		return h.maxPayloadBytes
+	}
+	return defaultMaxPayloadBytes
+}
+
+// limitPayload 限制调用请求体的大小，必须在读取请求体之前调用。
+// 请求声明的 Content-Length 已超过上限时直接写入 413 响应并返回 false；
+// 否则用 http.MaxBytesReader 包装请求体，分块传输的请求体读取超过上限时返回 *http.MaxBytesError，
+// 由 writePayloadTooLargeError 处理。
+func (h *Handler) limitPayload(w http.ResponseWriter, r *http.Request, fn *domain.Function) bool {
+	limit := h.payloadLimit(fn)
+	if r.ContentLength > limit {
+		writePayloadTooLarge(w, r, limit)
+		return false
+	}
+	if r.Body != nil {
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+	}
+	return true
+}
+
+// writePayloadTooLargeError 在读取请求体因超过大小上限失败时写入 413 响应，并返回 true；
+// err 不是请求体超限错误时不写入任何内容并返回 false。
+func writePayloadTooLargeError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var mbe *http.MaxBytesError
+	if !errors.As(err, &mbe) {
+		return false
+	}
+	writePayloadTooLarge(w, r, mbe.Limit)
+	return true
+}
+
+// writePayloadTooLarge 写入请求体超限的 413 响应，包含上限和请求声明的大小。
+// 分块传输的请求没有 Content-Length，此时不返回 size_bytes。
+func writePayloadTooLarge(w http.ResponseWriter, r *http.Request, limit int64) {
+	resp := map[string]interface{}{
+		"error":       domain.ErrPayloadTooLarge.Error(),
+		"limit_bytes": limit,
+		"request_id":  middleware.GetReqID(r.Context()),
+	}
+	if r.ContentLength > 0 {
+		resp["size_bytes"] = r.ContentLength
+	}
+	// 请求体没有读完，响应后关闭连接，避免继续接收剩余的数据
+	w.Header().Set("Connection", "close")
+	writeJSON(w, http.StatusRequestEntityTooLarge, resp)
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// readPayload 按调用接口的方式限制并读取请求体，成功时返回 200 和读取的字节数
+func readPayload(h *Handler, fn *domain.Function) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !h.limitPayload(w, r, fn) {
+			return
+		}
+		var payload json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && err != io.EOF {
+			if writePayloadTooLargeError(w, r, err) {
+				return
+			}
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]int{"read": len(payload)})
+	}
+}
+
+// jsonString 返回长度为 n 字节的 JSON 字符串
+func jsonString(n int) string {
+	return `"` + strings.Repeat("a", n-2) + `"`
+}
+
+func TestLimitPayload(t *testing.T) {
+	tests := []struct {
+		name       string
+		global     int64
+		fnLimit    int64
+		body       string
+		chunked    bool
+		wantStatus int
+		wantLimit  int64
+		wantSize   bool
+	}{
+		{name: "within global limit", global: 64, body: jsonString(64), wantStatus: http.StatusOK},
+		{name: "content length over global limit", global: 64, body: jsonString(65), wantStatus: http.StatusRequestEntityTooLarge, wantLimit: 64, wantSize: true},
+		{name: "chunked body over global limit", global: 64, body: jsonString(65), chunked: true, wantStatus: http.StatusRequestEntityTooLarge, wantLimit: 64},
+		{name: "function limit overrides global", global: 64, fnLimit: 128, body: jsonString(100), wantStatus: http.StatusOK},
+		{name: "over function limit", global: 1024, fnLimit: 16, body: jsonString(32), wantStatus: http.StatusRequestEntityTooLarge, wantLimit: 16, wantSize: true},
+		{name: "default limit", body: jsonString(1024), wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{}
+			h.SetMaxPayloadBytes(tt.global)
+			fn := &domain.Function{ID: "fn-1", MaxPayloadBytes: tt.fnLimit}
+
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/functions/fn-1/invoke", strings.NewReader(tt.body))
+			if tt.chunked {
+				r.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			readPayload(h, fn)(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusRequestEntityTooLarge {
+				return
+			}
+
+			// 413 响应包含上限和请求声明的大小，并关闭连接
+			var resp struct {
+				Error      string `json:"error"`
+				LimitBytes int64  `json:"limit_bytes"`
+				SizeBytes  *int64 `json:"size_bytes"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response %s: %v", w.Body, err)
+			}
+			if resp.Error != domain.ErrPayloadTooLarge.Error() || resp.LimitBytes != tt.wantLimit {
+				t.Errorf("response = %+v, want %q with limit %d", resp, domain.ErrPayloadTooLarge, tt.wantLimit)
+			}
+			if tt.wantSize && (resp.SizeBytes == nil || *resp.SizeBytes != int64(len(tt.body))) {
+				t.Errorf("size_bytes = %v, want %d", resp.SizeBytes, len(tt.body))
+			}
+			if !tt.wantSize && resp.SizeBytes != nil {
+				t.Errorf("size_bytes = %d, want omitted for chunked body", *resp.SizeBytes)
+			}
+			if w.Header().Get("Connection") != "close" {
+				t.Errorf("Connection header = %q, want close", w.Header().Get("Connection"))
+			}
+		})
+	}
+}
+
+func TestWritePayloadTooLargeErrorIgnoresOtherErrors(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	if writePayloadTooLargeError(w, r, io.ErrUnexpectedEOF) {
+		t.Error("writePayloadTooLargeError() = true for a non-size error")
+	}
+	if writePayloadTooLargeError(w, r, nil) {
+		t.Error("writePayloadTooLargeError() = true for nil error")
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("response body = %s, want nothing written", w.Body)
+	}
+}
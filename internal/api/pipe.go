@@ -0,0 +1,189 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/auth"
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// pipeStep 是管道调用中一个步骤的执行结果摘要。
+type pipeStep struct {
+	Function   string `json:"function"`
+	RequestID  string `json:"request_id"`
+	StatusCode int    `json:"status_code"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// pipeSummary 是管道调用的执行摘要，附加在最终响应上。
+type pipeSummary struct {
+	ID        string     `json:"id"`
+	Chain     []string   `json:"chain"`
+	Steps     []pipeStep `json:"steps"`
+	Completed bool       `json:"completed"` // 所有函数都执行成功时为 true
+}
+
+// pipeResponse 是管道调用的响应：最后一个执行的函数的调用结果，以及管道执行摘要。
+type pipeResponse struct {
+	*domain.InvokeResponse
+	Pipe *pipeSummary `json:"pipe"`
+}
+
+// resolvePipe 解析 then 查询参数指定的后续函数，并在执行前检查每个函数都可以被同步调用。
+// 没有 then 参数时返回 nil；检查失败时已写入错误响应并返回 false。
+//
+// 参数:
+//   - w: HTTP 响应写入器
+//   - r: HTTP 请求
+//
+// 返回值:
+//   - []*domain.Function: 依次执行的后续函数
+//   - bool: 检查是否通过
+func (h *Handler) resolvePipe(w http.ResponseWriter, r *http.Request) ([]*domain.Function, bool) {
+	return h.resolvePipeFunctions(w, r, h.findFunction)
+}
+
+// findFunction 按 ID 或名称查询函数，不存在时返回 domain.ErrFunctionNotFound。
+func (h *Handler) findFunction(idOrName string) (*domain.Function, error) {
+	fn, err := h.store.GetFunctionByID(idOrName)
+	if err == domain.ErrFunctionNotFound {
+		fn, err = h.store.GetFunctionByName(idOrName)
+	}
+	return fn, err
+}
+
+// resolvePipeFunctions 使用 find 查询管道的每个后续函数并执行 resolvePipe 的检查。
+// 带标签作用域的 API Key 只能串联作用域内的函数，作用域外的函数与不存在的函数一样返回 404。
+func (h *Handler) resolvePipeFunctions(w http.ResponseWriter, r *http.Request, find func(idOrName string) (*domain.Function, error)) ([]*domain.Function, bool) {
+	names, err := domain.ParsePipeChain(r.URL.Query().Get("then"))
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	fns := make([]*domain.Function, 0, len(names))
+	user := auth.GetUser(r.Context())
+	for _, idOrName := range names {
+		fn, err := find(idOrName)
+		if err == domain.ErrFunctionNotFound || (err == nil && !user.CanAccessTags(fn.Tags)) {
+			writeErrorWithContext(w, r, http.StatusNotFound, "pipe function not found: "+idOrName)
+			return nil, false
+		}
+		if err != nil {
+			h.logError(r, "resolvePipe", "查询管道函数失败", err, logrus.Fields{"function": idOrName})
+			writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to get function: "+err.Error())
+			return nil, false
+		}
+		if writePausedError(w, r, fn) {
+			return nil, false
+		}
+		if !fn.Status.CanInvoke() {
+			writeErrorWithContext(w, r, http.StatusBadRequest, "pipe function "+fn.Name+" is not active, current status: "+string(fn.Status))
+			return nil, false
+		}
+		if !h.checkInvokeEnvironment(w, r, fn) || !h.checkRateLimit(w, r, fn) {
+			return nil, false
+		}
+		fns = append(fns, fn)
+	}
+	return fns, true
+}
+
+// newInvocationPipe 创建管道第一个步骤的步骤信息。
+func newInvocationPipe(first *domain.Function, rest []*domain.Function) *domain.InvocationPipe {
+	chain := make([]string, 0, len(rest)+1)
+	chain = append(chain, first.Name)
+	for _, fn := range rest {
+		chain = append(chain, fn.Name)
+	}
+	return &domain.InvocationPipe{ID: uuid.New().String(), Chain: chain}
+}
+
+// continuePipe 在管道第一个函数执行成功后，依次执行后续函数，前一个函数的输出作为后一个函数的输入。
+// 某个函数执行失败时管道停止，返回该函数的调用结果；所有函数执行成功时返回最后一个函数的调用结果。
+//
+// 参数:
+//   - w: HTTP 响应写入器
+//   - r: HTTP 请求
+//   - first: 第一个函数的调用请求
+//   - resp: 第一个函数的调用结果
+//   - rest: 依次执行的后续函数
+func (h *Handler) continuePipe(w http.ResponseWriter, r *http.Request, first *domain.InvokeRequest, resp *domain.InvokeResponse, rest []*domain.Function) {
+	pipe := first.Pipe
+	summary := &pipeSummary{ID: pipe.ID, Chain: pipe.Chain}
+	summary.Steps = append(summary.Steps, newPipeStep(pipe.Chain[0], resp))
+
+	for i, fn := range rest {
+		if pipeStepFailed(resp) {
+			break
+		}
+		payload := resp.Body
+		if len(payload) == 0 {
+			payload = json.RawMessage("{}")
+		}
+		req := &domain.InvokeRequest{
+			FunctionID: fn.ID,
+			Payload:    payload,
+			SessionKey: first.SessionKey,
+			CostTags:   first.CostTags,
+			CallChain:  first.CallChain,
+			Pipe: &domain.InvocationPipe{
+				ID:                   pipe.ID,
+				Chain:                pipe.Chain,
+				Step:                 i + 1,
+				PreviousInvocationID: resp.RequestID,
+			},
+		}
+		next, err := h.scheduler.Invoke(req)
+		if err != nil {
+			h.logError(r, "continuePipe", "管道函数调用失败", err, logrus.Fields{
+				"pipe_id":  pipe.ID,
+				"function": fn.Name,
+				"step":     i + 1,
+			})
+			if writeKillSwitchError(w, r, err) || writeMaintenanceError(w, r, err) || writeRecursionError(w, r, err) {
+				return
+			}
+			summary.Steps = append(summary.Steps, pipeStep{Function: fn.Name, StatusCode: http.StatusInternalServerError, Error: err.Error()})
+			writeJSON(w, http.StatusInternalServerError, map[string]interface{}{
+				"error":    err.Error(),
+				"function": fn.Name,
+				"pipe":     summary,
+			})
+			return
+		}
+		resp = next
+		summary.Steps = append(summary.Steps, newPipeStep(fn.Name, resp))
+	}
+
+	summary.Completed = len(summary.Steps) == len(pipe.Chain) && !pipeStepFailed(resp)
+	h.logInfo(r, "continuePipe", "管道调用完成", logrus.Fields{
+		"pipe_id":   pipe.ID,
+		"chain":     pipe.Chain,
+		"steps":     len(summary.Steps),
+		"completed": summary.Completed,
+	})
+	setInvocationHeaders(w, r, resp)
+	writeJSON(w, resp.StatusCode, pipeResponse{InvokeResponse: resp, Pipe: summary})
+}
+
+// newPipeStep 根据调用结果创建步骤摘要。
+func newPipeStep(function string, resp *domain.InvokeResponse) pipeStep {
+	return pipeStep{
+		Function:   function,
+		RequestID:  resp.RequestID,
+		StatusCode: resp.StatusCode,
+		DurationMs: resp.DurationMs,
+		Error:      resp.Error,
+	}
+}
+
+// pipeStepFailed 判断管道步骤是否执行失败，失败后不再执行后续函数。
+func pipeStepFailed(resp *domain.InvokeResponse) bool {
+	return resp.Error != "" || resp.StatusCode >= http.StatusBadRequest
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/oriys/nimbus/internal/auth"
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+func TestResolvePipeFunctionsTagScope(t *testing.T) {
+	fns := map[string]*domain.Function{
+		"resize":  {ID: "fn-resize", Name: "resize", Status: domain.FunctionStatusActive, Tags: []string{"team:media"}},
+		"upload":  {ID: "fn-upload", Name: "upload", Status: domain.FunctionStatusActive, Tags: []string{"team:media"}},
+		"billing": {ID: "fn-billing", Name: "billing", Status: domain.FunctionStatusActive, Tags: []string{"team:payments"}},
+	}
+	find := func(idOrName string) (*domain.Function, error) {
+		if fn, ok := fns[idOrName]; ok {
+			return fn, nil
+		}
+		return nil, domain.ErrFunctionNotFound
+	}
+	scoped := &auth.UserContext{UserID: "u1", Role: auth.RoleUser, TagSelector: []string{"team:media"}}
+
+	tests := []struct {
+		name      string
+		then      string
+		user      *auth.UserContext
+		wantOK    bool
+		wantCount int
+		wantCode  int
+	}{
+		{name: "all stages in scope", then: "resize,upload", user: scoped, wantOK: true, wantCount: 2},
+		{name: "out-of-scope stage", then: "resize,billing", user: scoped, wantCode: http.StatusNotFound},
+		{name: "missing stage", then: "resize,missing", user: scoped, wantCode: http.StatusNotFound},
+		{name: "unscoped key", then: "resize,billing", user: &auth.UserContext{UserID: "u2", Role: auth.RoleAdmin}, wantOK: true, wantCount: 2},
+		{name: "no pipe", user: scoped, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{}
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/functions/thumbnail/invoke?then="+tt.then, nil)
+			r = r.WithContext(context.WithValue(r.Context(), auth.UserContextKey, tt.user))
+			w := httptest.NewRecorder()
+
+			got, ok := h.resolvePipeFunctions(w, r, find)
+			if ok != tt.wantOK {
+				t.Fatalf("resolvePipeFunctions() ok = %v, want %v (status %d)", ok, tt.wantOK, w.Code)
+			}
+			if !ok && w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if ok && len(got) != tt.wantCount {
+				t.Errorf("resolved %d functions, want %d", len(got), tt.wantCount)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// reconcileKeepWarm 通知调度器立即协调常驻预热实例，调度器不支持常驻预热时为空操作。
+// 函数的 keep_warm 变化、函数被删除或下线后调用，使预置的实例立即创建或释放。
+func (h *Handler) reconcileKeepWarm() {
+	if p, ok := h.scheduler.(WarmProvisioner); ok {
+		p.ReconcileKeepWarm()
+	}
+}
+
+// ProvisionFunction 为函数预置常驻预热实例（预置并发），降低延迟敏感函数的冷启动。
+// HTTP端点: POST /api/v1/functions/{id}/provision
+//
+// 请求体: {"count": N}，N 写入函数的 keep_warm，0 表示取消预置。
+// 预置的实例属于函数的运行时/内存规格容器池，同规格函数的预置数累加；
+// 这些实例不受空闲回收影响，但仍按容器最大存活时间和复用次数轮换，轮换后由后台补齐。
+// 实例在后台创建，响应返回容器池的当前状态。
+//
+// 返回值：
+//   - 200: 返回 domain.ProvisionResponse
+//   - 400: count 缺失或超出范围，或函数挂载了共享数据卷
+//   - 404: 函数不存在
+//   - 501: 调度器不支持预置（需要 Docker 池化模式）
+func (h *Handler) ProvisionFunction(w http.ResponseWriter, r *http.Request) {
+	provisioner, ok := h.scheduler.(WarmProvisioner)
+	if !ok {
+		writeErrorWithContext(w, r, http.StatusNotImplemented, "scheduler does not support provisioned concurrency")
+		return
+	}
+	if _, err := provisioner.ProvisionStats(); err != nil {
+		writeErrorWithContext(w, r, http.StatusNotImplemented, err.Error())
+		return
+	}
+
+	fn, ok := h.lookupFunction(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.ProvisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if req.Count == nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "count is required")
+		return
+	}
+	// 挂载了共享数据卷的函数使用独立的容器池，常驻实例无法为其所用
+	if *req.Count > 0 && len(fn.DataVolumes) > 0 {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "functions with data volumes cannot use provisioned concurrency")
+		return
+	}
+	if err := domain.ValidateKeepWarm(*req.Count); err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if fn.KeepWarm != *req.Count {
+		previous := fn.KeepWarm
+		fn.KeepWarm = *req.Count
+		if err := h.store.UpdateFunction(fn); err != nil {
+			h.logError(r, "ProvisionFunction", "更新常驻实例数失败", err, logrus.Fields{"function": fn.Name})
+			writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to update function: "+err.Error())
+			return
+		}
+		h.logInfo(r, "ProvisionFunction", "更新常驻实例数", logrus.Fields{
+			"function": fn.Name,
+			"previous": previous,
+			"count":    fn.KeepWarm,
+		})
+	}
+	provisioner.ReconcileKeepWarm()
+
+	resp := domain.ProvisionResponse{
+		FunctionID: fn.ID,
+		Count:      fn.KeepWarm,
+		Pool:       domain.ProvisionedPool{Runtime: fn.Runtime, MemoryMB: fn.MemoryMB},
+	}
+	if stats, err := provisioner.ProvisionStats(); err == nil {
+		for _, p := range stats {
+			if p.Runtime == fn.Runtime && p.MemoryMB == fn.MemoryMB {
+				resp.Pool = p
+				break
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/auth"
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// rateLimitCheckTimeout 限流检查访问 Redis 的超时时间，超时后放行请求
+const rateLimitCheckTimeout = 200 * time.Millisecond
+
+// checkRateLimit 根据函数的限流配置消耗一个令牌，并设置 X-RateLimit-* 响应头。
+// 超出限流时写入 429 响应并返回 false；函数未配置限流或 Redis 不可用时直接放行。
+//
+// 响应头：
+//   - X-RateLimit-Limit: 令牌桶容量
+//   - X-RateLimit-Remaining: 本次请求后剩余的令牌数
+//   - X-RateLimit-Reset: 令牌桶补满所需的秒数
+//   - Retry-After: 仅在 429 响应中设置，下一个令牌可用前需等待的秒数
+func (h *Handler) checkRateLimit(w http.ResponseWriter, r *http.Request, fn *domain.Function) bool {
+	if fn.RateLimit == nil || h.redis == nil {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), rateLimitCheckTimeout)
+	defer cancel()
+
+	status, err := h.redis.TakeRateLimitToken(ctx, rateLimitKey(r, fn), fn.RateLimit)
+	if err != nil {
+		// 限流依赖 Redis，不可用时降级为不限流，避免影响正常调用
+		h.logWarn(r, "checkRateLimit", "限流检查失败，放行请求", logrus.Fields{
+			"function_id": fn.ID,
+			"error":       err.Error(),
+		})
+		return true
+	}
+
+	setRateLimitHeaders(w, status)
+	if status.Allowed {
+		return true
+	}
+
+	retryAfter := int(math.Ceil(1 / fn.RateLimit.RequestsPerSecond))
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	h.logWarn(r, "checkRateLimit", "调用超出限流", logrus.Fields{
+		"function_id": fn.ID,
+		"limit":       status.Limit,
+	})
+	writeErrorWithContext(w, r, http.StatusTooManyRequests, domain.ErrRateLimitExceeded.Error())
+	return false
+}
+
+// rateLimitKey 返回请求对应的令牌桶标识。
+// 配置了按调用方计数时，已认证请求使用独立的令牌桶，匿名请求共享函数级令牌桶。
+func rateLimitKey(r *http.Request, fn *domain.Function) string {
+	if fn.RateLimit.PerKey {
+		if user := auth.GetUser(r.Context()); user != nil && user.UserID != "" {
+			return fn.ID + ":" + user.UserID
+		}
+	}
+	return fn.ID
+}
+
+// setRateLimitHeaders 将令牌桶状态写入 X-RateLimit-* 响应头。
+func setRateLimitHeaders(w http.ResponseWriter, status *domain.RateLimitStatus) {
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(status.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(status.Remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.Itoa(status.ResetSec))
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// callChainFromRequest 解析函数嵌套调用时通过 X-Nimbus-Call-Chain 请求头传回的上游调用链。
+func callChainFromRequest(r *http.Request) []string {
+	return domain.ParseCallChain(r.Header.Get(domain.HeaderCallChain))
+}
+
+// writeRecursionError 在调用因疑似无限递归被拒绝时写入 508 响应，并返回 true。
+// 响应包含拒绝原因（depth 或 cycle）及对应的计数和上限；
+// err 不是递归错误时不写入任何内容并返回 false。
+func writeRecursionError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var rerr *domain.RecursionError
+	if !errors.As(err, &rerr) {
+		return false
+	}
+
+	writeJSON(w, http.StatusLoopDetected, map[string]interface{}{
+		"error":      domain.ErrRecursionLimitExceeded.Error(),
+		"detail":     rerr.Error(),
+		"reason":     rerr.Reason,
+		"count":      rerr.Count,
+		"limit":      rerr.Limit,
+		"request_id": middleware.GetReqID(r.Context()),
+	})
+	return true
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// responseCacheTimeout 是读写响应缓存的 Redis 超时，超时视为未命中
+const responseCacheTimeout = 100 * time.Millisecond
+
+// serveResponseCache 在同步调用执行前查询响应缓存，命中时直接写出缓存的响应。
+//
+// 命中缓存不经过调度器执行，因此查询前先通过调度器的 InvokeGate 检查调用闸门：
+// 紧急停止开关和维护窗口拒绝调用时写出 503；函数已达到最大并发数时跳过缓存，
+// 交由调度器按并发限制排队或拒绝。熔断的函数状态不可调用，已在此之前被拒绝。
+//
+// 参数:
+//   - w: HTTP 响应写入器
+//   - r: HTTP 请求
+//   - fn: 被调用的函数
+//   - requestID: 本次调用的请求 ID，用于广播被拒绝的调用
+//   - payload: 请求载荷
+//   - lookup: 查询响应缓存的函数，通常为 h.lookupResponseCache
+//
+// 返回值:
+//   - string: 缓存键，为空表示本次调用不使用响应缓存
+//   - bool: 是否已写出响应（命中缓存或调用被拒绝）
+func (h *Handler) serveResponseCache(w http.ResponseWriter, r *http.Request, fn *domain.Function, requestID string, payload json.RawMessage,
+	lookup func(*http.Request, *domain.Function, json.RawMessage) (string, *domain.InvokeResponse)) (string, bool) {
+	if fn.ResponseCache == nil {
+		return "", false
+	}
+
+	if gate, ok := h.scheduler.(InvokeGate); ok {
+		if err := gate.CheckInvoke(fn); err != nil {
+			if errors.Is(err, domain.ErrConcurrencyLimitExceeded) {
+				return "", false
+			}
+			broadcastInvocationResult(fn, domain.LogSourceAPI, requestID, payload, nil, err, 0)
+			if writeKillSwitchError(w, r, err) || writeMaintenanceError(w, r, err) {
+				return "", true
+			}
+			// 其他错误交由调度器执行路径处理
+			return "", false
+		}
+	}
+
+	key, cached := lookup(r, fn, payload)
+	if cached != nil {
+		w.Header().Set(domain.HeaderResponseCache, "hit")
+		setInvocationHeaders(w, r, cached)
+		writeJSON(w, cached.StatusCode, cached)
+		return key, true
+	}
+	if key != "" {
+		w.Header().Set(domain.HeaderResponseCache, "miss")
+	}
+	return key, false
+}
+
+// lookupResponseCache 计算同步调用的响应缓存键并查询缓存。
+// 函数未配置响应缓存、未配置 Redis 时返回空缓存键；Redis 出错时按未命中处理。
+//
+// 参数:
+//   - r: HTTP 请求
+//   - fn: 被调用的函数
+//   - payload: 请求载荷
+//
+// 返回值:
+//   - string: 缓存键，为空表示本次调用不使用响应缓存
+//   - *domain.InvokeResponse: 命中时返回缓存的响应，否则返回 nil
+func (h *Handler) lookupResponseCache(r *http.Request, fn *domain.Function, payload json.RawMessage) (string, *domain.InvokeResponse) {
+	if fn.ResponseCache == nil || h.redis == nil {
+		return "", nil
+	}
+	key := fn.ResponseCache.ResponseCacheKey(fn, payload)
+
+	ctx, cancel := context.WithTimeout(r.Context(), responseCacheTimeout)
+	defer cancel()
+	resp, err := h.redis.GetCachedResponse(ctx, key)
+	if err != nil {
+		h.logWarn(r, "lookupResponseCache", "查询响应缓存失败，按未命中处理", logrus.Fields{
+			"function": fn.Name,
+			"error":    err.Error(),
+		})
+		return key, nil
+	}
+	if resp != nil {
+		// 命中缓存时没有执行函数，不产生冷启动和计费
+		resp.ColdStart = false
+		resp.BilledTimeMs = 0
+	}
+	return key, resp
+}
+
+// storeResponseCache 缓存执行成功的同步调用响应，失败的响应不缓存。
+func (h *Handler) storeResponseCache(r *http.Request, fn *domain.Function, key string, resp *domain.InvokeResponse) {
+	if key == "" || resp.Error != "" || resp.StatusCode >= http.StatusBadRequest {
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), responseCacheTimeout)
+	defer cancel()
+	ttl := time.Duration(fn.ResponseCache.TTLSeconds) * time.Second
+	if err := h.redis.CacheResponse(ctx, key, resp, ttl); err != nil {
+		h.logWarn(r, "storeResponseCache", "写入响应缓存失败", logrus.Fields{
+			"function": fn.Name,
+			"error":    err.Error(),
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// gateScheduler 是实现了 InvokeGate 的调度器，CheckInvoke 返回预设错误
+type gateScheduler struct {
+	gateErr error
+}
+
+func (s *gateScheduler) Invoke(*domain.InvokeRequest) (*domain.InvokeResponse, error) {
+	return nil, nil
+}
+func (s *gateScheduler) InvokeAsync(*domain.InvokeRequest) (string, error) { return "", nil }
+func (s *gateScheduler) CheckInvoke(*domain.Function) error                { return s.gateErr }
+
+func TestServeResponseCacheGates(t *testing.T) {
+	killed := &domain.KillSwitchError{Switch: &domain.KillSwitch{Scope: "function", FunctionID: "fn-1", Reason: "incident"}}
+	maintenance := &domain.MaintenanceError{Reason: "migration", Until: time.Now().Add(time.Hour)}
+
+	tests := []struct {
+		name       string
+		scheduler  Scheduler
+		wantDone   bool
+		wantLookup bool
+		wantStatus int
+	}{
+		{name: "kill switch engaged", scheduler: &gateScheduler{gateErr: killed}, wantDone: true, wantStatus: http.StatusServiceUnavailable},
+		{name: "in maintenance", scheduler: &gateScheduler{gateErr: maintenance}, wantDone: true, wantStatus: http.StatusServiceUnavailable},
+		// 达到最大并发数时跳过缓存，由调度器排队或拒绝
+		{name: "concurrency saturated", scheduler: &gateScheduler{gateErr: domain.ErrConcurrencyLimitExceeded}},
+		{name: "gates pass", scheduler: &gateScheduler{}, wantDone: true, wantLookup: true, wantStatus: http.StatusOK},
+		{name: "scheduler without gate", scheduler: &MockScheduler{}, wantDone: true, wantLookup: true, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{scheduler: tt.scheduler}
+			fn := &domain.Function{ID: "fn-1", Name: "demo", ResponseCache: &domain.ResponseCacheConfig{TTLSeconds: 60}}
+
+			// 缓存中已有该调用的成功响应
+			looked := false
+			lookup := func(*http.Request, *domain.Function, json.RawMessage) (string, *domain.InvokeResponse) {
+				looked = true
+				return "cache-key", &domain.InvokeResponse{RequestID: "cached", StatusCode: http.StatusOK, Body: json.RawMessage(`"ok"`)}
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/functions/demo/invoke", nil)
+			_, done := h.serveResponseCache(w, r, fn, "req-1", json.RawMessage(`{}`), lookup)
+			if done != tt.wantDone {
+				t.Errorf("done = %v, want %v", done, tt.wantDone)
+			}
+			if looked != tt.wantLookup {
+				t.Errorf("cache looked up = %v, want %v", looked, tt.wantLookup)
+			}
+			if tt.wantDone && w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if hit := w.Header().Get(domain.HeaderResponseCache) == "hit"; hit != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("%s header = %q", domain.HeaderResponseCache, w.Header().Get(domain.HeaderResponseCache))
+			}
+		})
+	}
+}
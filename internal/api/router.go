@@ -5,10 +5,12 @@ package api
 import (
 	"io/fs"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/oriys/nimbus/internal/auth"
 	"github.com/oriys/nimbus/internal/telemetry"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
@@ -28,6 +30,8 @@ type RouterConfig struct {
 	Logger *logrus.Logger
 	// WebFS 前端静态文件系统（可选，用于嵌入前端资源）
 	WebFS fs.FS
+	// Auth 认证中间件（可选，用于识别 API Key 并执行标签作用域授权，为 nil 时不做身份识别）
+	Auth *auth.Middleware
 }
 
 // NewRouter 创建并配置HTTP路由器。
@@ -85,7 +89,7 @@ func NewRouter(cfg *RouterConfig) *chi.Mux {
 	r.Use(middleware.Timeout(60 * time.Second))
 
 	// CORS中间件：处理跨域请求
-	r.Use(corsMiddleware)
+	r.Use(h.corsMiddleware)
 
 	// 健康检查端点 - 用于负载均衡器和Kubernetes探针
 	r.Get("/health", h.Health)           // 基本健康检查
@@ -95,18 +99,31 @@ func NewRouter(cfg *RouterConfig) *chi.Mux {
 	// Prometheus指标端点 - 暴露应用程序指标供监控系统采集
 	r.Handle("/metrics", promhttp.Handler())
 
+	// identify 在启用认证时识别请求携带的 API Key 或 JWT，供标签作用域授权使用；
+	// 不强制认证，未携带凭证的请求按原有方式处理
+	identify := func(next http.Handler) http.Handler {
+		if cfg.Auth == nil {
+			return next
+		}
+		return cfg.Auth.Identify(next)
+	}
+
 	// Webhook 触发端点 - 外部系统通过此 URL 触发函数
 	// POST /webhook/{key} - 通过 Webhook 密钥触发函数
-	r.Post("/webhook/{key}", h.HandleWebhook)
+	r.With(identify).Post("/webhook/{key}", h.HandleWebhook)
 
 	// API v1 路由组
 	r.Route("/api/v1", func(r chi.Router) {
+		r.Use(identify)
+
 		// 函数管理路由组
 		r.Route("/functions", func(r chi.Router) {
 			// POST /api/v1/functions - 创建新函数
 			r.Post("/", h.CreateFunction)
 			// GET /api/v1/functions - 获取函数列表
 			r.Get("/", h.ListFunctions)
+			// GET /api/v1/functions/search - 跨函数搜索代码
+			r.Get("/search", h.SearchFunctions)
 			// POST /api/v1/functions/import - 导入函数
 			r.Post("/import", h.ImportFunction)
 			// POST /api/v1/functions/bulk-delete - 批量删除函数
@@ -118,6 +135,11 @@ func NewRouter(cfg *RouterConfig) *chi.Mux {
 
 			// 单个函数的操作路由组
 			r.Route("/{id}", func(r chi.Router) {
+				// 限制带标签作用域的 API Key 只能访问匹配标签的函数
+				if cfg.Auth != nil {
+					r.Use(cfg.Auth.ScopeFunctionTags(h.resolveFunctionTags))
+				}
+
 				// GET /api/v1/functions/{id} - 获取函数详情
 				r.Get("/", h.GetFunction)
 				// PUT /api/v1/functions/{id} - 更新函数
@@ -130,14 +152,46 @@ func NewRouter(cfg *RouterConfig) *chi.Mux {
 				r.Post("/invoke", h.InvokeFunction)
 				// POST /api/v1/functions/{id}/async - 异步调用函数
 				r.Post("/async", h.InvokeFunctionAsync)
+				// POST /api/v1/functions/{id}/provision - 预置常驻预热实例（写入 keep_warm）
+				r.Post("/provision", h.ProvisionFunction)
+				// POST /api/v1/functions/{id}/invoke-stream - 同步调用函数，以 SSE 推送执行期间的输出和最终结果
+				r.Post("/invoke-stream", h.InvokeFunctionStream)
+				// POST /api/v1/functions/{id}/batch-invoke - 用多个载荷批量同步调用函数
+				r.Post("/batch-invoke", h.BatchInvokeFunction)
+				// GET /api/v1/functions/{id}/ws - 通过 WebSocket 持续调用函数，每条消息即一次调用
+				r.Get("/ws", h.InvokeFunctionWS)
+				// POST /api/v1/functions/{id}/ws/{connectionId}/messages - 向函数的 WebSocket 连接推送消息
+				r.Post("/ws/{connectionId}/messages", h.PushFunctionWSMessage)
 				// GET /api/v1/functions/{id}/invocations - 获取函数的调用记录
 				r.Get("/invocations", h.ListInvocations)
+				// POST /api/v1/functions/{id}/diagnostics - 获取函数执行环境诊断信息
+				r.Post("/diagnostics", h.DiagnoseFunction)
 
 				// 函数状态管理路由
 				// POST /api/v1/functions/{id}/offline - 下线函数
 				r.Post("/offline", h.OfflineFunction)
 				// POST /api/v1/functions/{id}/online - 上线函数
 				r.Post("/online", h.OnlineFunction)
+				// POST /api/v1/functions/{id}/pause - 暂停函数（异步调用排队，恢复后执行）
+				r.Post("/pause", h.PauseFunction)
+				// POST /api/v1/functions/{id}/resume - 恢复暂停的函数并执行积压的调用
+				r.Post("/resume", h.OnlineFunction)
+				// GET /api/v1/functions/{id}/paused-backlog - 获取暂停队列积压数量
+				r.Get("/paused-backlog", h.GetPausedBacklog)
+				// GET /api/v1/functions/{id}/openapi - 导出函数自定义路由的 OpenAPI 文档
+				r.Get("/openapi", h.GetFunctionOpenAPI)
+				// 紧急停止开关（需要 admin 角色）
+				r.Group(func(r chi.Router) {
+					if cfg.Auth != nil {
+						r.Use(cfg.Auth.RequireRole(auth.RoleAdmin))
+					}
+					// POST /api/v1/functions/{id}/kill-switch - 开启函数级紧急停止开关
+					r.Post("/kill-switch", h.EngageFunctionKillSwitch)
+					// DELETE /api/v1/functions/{id}/kill-switch - 解除函数级紧急停止开关
+					r.Delete("/kill-switch", h.ReleaseFunctionKillSwitch)
+					// GET /api/v1/functions/{id}/kill-switch - 获取函数级和全局紧急停止开关状态
+					r.Get("/kill-switch", h.GetFunctionKillSwitch)
+				})
 				// POST /api/v1/functions/{id}/recompile - 重新编译函数
 				r.Post("/recompile", h.RecompileFunction)
 				// POST /api/v1/functions/{id}/pin - 置顶/取消置顶函数
@@ -165,6 +219,13 @@ func NewRouter(cfg *RouterConfig) *chi.Mux {
 					r.Get("/{version}", h.GetFunctionVersion)
 					// POST /api/v1/functions/{id}/versions/{version}/rollback - 回滚到指定版本
 					r.Post("/{version}/rollback", h.RollbackFunction)
+					// GET /api/v1/functions/{id}/versions/{version}/binary - 下载指定版本的编译产物（需要 admin、user 或 read 角色）
+					r.Group(func(r chi.Router) {
+						if cfg.Auth != nil {
+							r.Use(cfg.Auth.RequireRole(auth.RoleAdmin, auth.RoleUser, auth.RoleRead))
+						}
+						r.Get("/{version}/binary", h.GetFunctionVersionBinary)
+					})
 				})
 
 				// 别名管理路由组
@@ -175,10 +236,39 @@ func NewRouter(cfg *RouterConfig) *chi.Mux {
 					r.Post("/", h.CreateFunctionAlias)
 					// PUT /api/v1/functions/{id}/aliases/{name} - 更新函数别名
 					r.Put("/{name}", h.UpdateFunctionAlias)
+					// POST /api/v1/functions/{id}/aliases/{name}/invoke - 按别名权重选择版本同步调用
+					r.Post("/{name}/invoke", h.InvokeFunctionAlias)
 					// DELETE /api/v1/functions/{id}/aliases/{name} - 删除函数别名
 					r.Delete("/{name}", h.DeleteFunctionAlias)
 				})
 
+				// GET /api/v1/functions/{id}/exec-logs/{requestId}/stream - 实时推送执行中调用的容器输出（SSE）
+				r.Get("/exec-logs/{requestId}/stream", h.StreamExecLogs)
+
+				// GET /api/v1/functions/{id}/cost-estimate - 按假设调用量预估月度用量
+				r.Get("/cost-estimate", h.GetFunctionCostEstimate)
+
+				// GET /api/v1/functions/{id}/billing - 汇总函数的调用次数、GB-秒和费用
+				r.Get("/billing", h.GetFunctionBilling)
+
+				// GET /api/v1/functions/{id}/compare - 对比两个版本的调用指标（金丝雀分析）
+				r.Get("/compare", h.CompareFunctionVersions)
+
+				// POST /api/v1/functions/{id}/swap - 切换蓝绿部署的线上槽位
+				r.Post("/swap", h.SwapFunctionSlot)
+
+				// 影子流量路由组
+				r.Route("/shadow", func(r chi.Router) {
+					// GET /api/v1/functions/{id}/shadow - 获取影子流量配置
+					r.Get("/", h.GetShadowConfig)
+					// PUT /api/v1/functions/{id}/shadow - 设置影子流量配置
+					r.Put("/", h.UpdateShadowConfig)
+					// DELETE /api/v1/functions/{id}/shadow - 删除影子流量配置
+					r.Delete("/", h.DeleteShadowConfig)
+					// GET /api/v1/functions/{id}/shadow/results - 获取影子调用对比结果
+					r.Get("/results", h.ListShadowResults)
+				})
+
 				// 层管理路由组（函数级别）
 				r.Route("/layers", func(r chi.Router) {
 					// GET /api/v1/functions/{id}/layers - 获取函数的层
@@ -216,22 +306,94 @@ func NewRouter(cfg *RouterConfig) *chi.Mux {
 		r.Route("/invocations", func(r chi.Router) {
 			// GET /api/v1/invocations - 获取所有调用记录列表
 			r.Get("/", h.ListAllInvocations)
-			// GET /api/v1/invocations/{id} - 获取调用记录详情
-			r.Get("/{id}", h.GetInvocation)
-			// POST /api/v1/invocations/{id}/replay - 重放调用
-			r.Post("/{id}/replay", h.ReplayInvocation)
+			// GET /api/v1/invocations/search - 按条件和文本内容搜索调用记录
+			r.Get("/search", h.SearchInvocations)
+			// POST /api/v1/invocations/batch-get - 批量获取调用记录
+			r.Post("/batch-get", h.BatchGetInvocations)
+
+			// 单个调用记录的路由，限制带标签作用域的 API Key 只能访问匹配标签的函数的调用记录
+			r.Group(func(r chi.Router) {
+				if cfg.Auth != nil {
+					r.Use(cfg.Auth.ScopeFunctionTags(h.invocationTagsResolver("id")))
+				}
+				// GET /api/v1/invocations/{id} - 获取调用记录详情
+				r.Get("/{id}", h.GetInvocation)
+				// GET /api/v1/invocations/{id}/progress - 获取调用的最新执行进度
+				r.Get("/{id}/progress", h.GetInvocationProgress)
+				// GET /api/v1/invocations/{id}/artifacts/{name} - 下载调用产物文件
+				r.Get("/{id}/artifacts/*", h.GetInvocationArtifact)
+				// POST /api/v1/invocations/{id}/replay - 重放调用
+				r.Post("/{id}/replay", h.ReplayInvocation)
+			})
+		})
+
+		// GET /api/v1/async-results/{request_id} - 查询异步调用的结果，支持 wait 长轮询
+		r.Group(func(r chi.Router) {
+			if cfg.Auth != nil {
+				r.Use(cfg.Auth.ScopeFunctionTags(h.invocationTagsResolver("request_id")))
+			}
+			r.Get("/async-results/{request_id}", h.GetAsyncResult)
 		})
 
 		// GET /api/v1/stats - 获取系统统计信息
 		r.Get("/stats", h.Stats)
 
+		// 调度器管理路由组
+		r.Route("/scheduler", func(r chi.Router) {
+			// GET /api/v1/scheduler/workers - 获取工作协程与队列状态
+			r.Get("/workers", h.GetSchedulerWorkers)
+			// PUT /api/v1/scheduler/workers - 运行时调整工作协程数量
+			r.Put("/workers", h.ScaleSchedulerWorkers)
+		})
+
+		// GET /api/v1/scaling-metrics - 导出供外部自动扩缩容器使用的负载信号
+		r.Get("/scaling-metrics", h.GetScalingMetrics)
+
+		// GET /api/v1/runtimes - 获取各运行时的处理函数契约
+		r.Get("/runtimes", h.ListRuntimes)
+		// GET /api/v1/data-volumes - 获取可供函数挂载的共享数据卷
+		r.Get("/data-volumes", h.ListDataVolumes)
+
+		// GET /api/v1/billing/usage - 按成本标签或函数汇总调用用量
+		r.Get("/billing/usage", h.GetBillingUsage)
+
 		// POST /api/v1/compile - 编译源代码
 		r.Post("/compile", h.CompileCode)
+		// POST /api/v1/compile/check - 检查源代码并返回诊断，不生成编译产物
+		r.Post("/compile/check", h.CheckCode)
+		// GET /api/v1/compile/stats - 获取各运行时的并发编译与排队情况
+		r.Get("/compile/stats", h.GetBuildStats)
+
+		// GET /api/v1/openapi - 导出所有自定义路由的 OpenAPI 文档
+		r.Get("/openapi", h.GetOpenAPI)
+
+		// 平台管理路由组
+		r.Route("/admin", func(r chi.Router) {
+			// POST /api/v1/admin/compile-cache/invalidate - 按运行时或全部清除编译缓存
+			r.Post("/compile-cache/invalidate", h.InvalidateCompileCache)
+			// POST /api/v1/admin/templates/validate - 对所有模板执行试编译，返回仍能构建的模板
+			r.Post("/templates/validate", h.ValidateTemplates)
+
+			// 全局紧急停止开关（需要 admin 角色）
+			r.Group(func(r chi.Router) {
+				if cfg.Auth != nil {
+					r.Use(cfg.Auth.RequireRole(auth.RoleAdmin))
+				}
+				// POST /api/v1/admin/kill-switch - 开启全局紧急停止开关
+				r.Post("/kill-switch", h.EngageGlobalKillSwitch)
+				// DELETE /api/v1/admin/kill-switch - 解除全局紧急停止开关
+				r.Delete("/kill-switch", h.ReleaseGlobalKillSwitch)
+				// GET /api/v1/admin/kill-switch - 获取全局紧急停止开关状态
+				r.Get("/kill-switch", h.GetGlobalKillSwitch)
+			})
+		})
 
 		// 任务管理路由组
 		r.Route("/tasks", func(r chi.Router) {
 			// GET /api/v1/tasks/{id} - 获取任务状态
 			r.Get("/{id}", h.GetFunctionTask)
+			// POST /api/v1/tasks/{id}/cancel - 取消正在执行的编译任务
+			r.Post("/{id}/cancel", h.CancelFunctionTask)
 		})
 
 		// 层管理路由组
@@ -260,12 +422,18 @@ func NewRouter(cfg *RouterConfig) *chi.Mux {
 
 		// 死信队列 (DLQ) 管理路由组
 		r.Route("/dlq", func(r chi.Router) {
+			// 死信消息跨多个函数，带标签作用域的 API Key 不能访问
+			if cfg.Auth != nil {
+				r.Use(cfg.Auth.DenyTagScoped())
+			}
 			// GET /api/v1/dlq - 获取死信消息列表
 			r.Get("/", h.ListDLQMessages)
 			// GET /api/v1/dlq/stats - 获取死信队列统计
 			r.Get("/stats", h.GetDLQStats)
 			// DELETE /api/v1/dlq - 清空死信队列
 			r.Delete("/", h.PurgeDLQMessages)
+			// POST /api/v1/dlq/bulk-retry - 按条件批量重试死信消息
+			r.Post("/bulk-retry", h.BulkRetryDLQMessages)
 			// GET /api/v1/dlq/{id} - 获取死信消息详情
 			r.Get("/{id}", h.GetDLQMessage)
 			// POST /api/v1/dlq/{id}/retry - 重试死信消息
@@ -308,6 +476,16 @@ func NewRouter(cfg *RouterConfig) *chi.Mux {
 			r.Get("/", h.ListTemplates)
 			// POST /api/v1/templates - 创建模板
 			r.Post("/", h.CreateTemplate)
+			// GET /api/v1/templates/export/{id} - 导出可分享的模板文档
+			r.Get("/export/{id}", h.ExportTemplate)
+			// 从远程目录导入模板（服务端发起请求，需要 admin 角色）
+			r.Group(func(r chi.Router) {
+				if cfg.Auth != nil {
+					r.Use(cfg.Auth.RequireRole(auth.RoleAdmin))
+				}
+				// POST /api/v1/templates/import-from-url - 下载模板文档并创建模板
+				r.Post("/import-from-url", h.ImportTemplatesFromURL)
+			})
 
 			r.Route("/{id}", func(r chi.Router) {
 				// GET /api/v1/templates/{id} - 获取模板详情
@@ -352,6 +530,8 @@ func NewRouter(cfg *RouterConfig) *chi.Mux {
 				r.Post("/", h.CreateNotificationChannel)
 				// DELETE /api/v1/alerts/channels/{id} - 删除通知渠道
 				r.Delete("/{id}", h.DeleteNotificationChannel)
+				// POST /api/v1/alerts/channels/{id}/test - 发送测试通知
+				r.Post("/{id}/test", h.TestNotificationChannel)
 			})
 		})
 
@@ -377,6 +557,10 @@ func NewRouter(cfg *RouterConfig) *chi.Mux {
 		if cfg.WorkflowHandler != nil {
 			wh := cfg.WorkflowHandler
 			r.Route("/workflows", func(r chi.Router) {
+				// 工作流可编排任意函数，带标签作用域的 API Key 不能访问
+				if cfg.Auth != nil {
+					r.Use(cfg.Auth.DenyTagScoped())
+				}
 				// POST /api/v1/workflows - 创建工作流
 				r.Post("/", wh.CreateWorkflow)
 				// GET /api/v1/workflows - 获取工作流列表
@@ -393,10 +577,15 @@ func NewRouter(cfg *RouterConfig) *chi.Mux {
 					r.Post("/executions", wh.StartExecution)
 					// GET /api/v1/workflows/{id}/executions - 获取执行列表
 					r.Get("/executions", wh.ListExecutions)
+					// GET /api/v1/workflows/{id}/executions/{execId}/invocations - 获取执行发起的函数调用
+					r.Get("/executions/{execId}/invocations", wh.ListExecutionInvocations)
 				})
 			})
 
 			r.Route("/executions", func(r chi.Router) {
+				if cfg.Auth != nil {
+					r.Use(cfg.Auth.DenyTagScoped())
+				}
 				// GET /api/v1/executions - 获取所有执行列表
 				r.Get("/", wh.ListAllExecutions)
 
@@ -429,6 +618,7 @@ func NewRouter(cfg *RouterConfig) *chi.Mux {
 	if cfg.Logger != nil {
 		consoleHandler := NewConsoleHandler(h, h.store, cfg.Logger)
 		debugHandler := NewDebugHandler(h.store, cfg.Logger)
+		debugHandler.SetBuildConcurrency(h.debugBuildLimit)
 		r.Route("/api", func(r chi.Router) {
 			consoleHandler.RegisterRoutes(r)
 			debugHandler.RegisterRoutes(r)
@@ -462,7 +652,7 @@ func NewRouter(cfg *RouterConfig) *chi.Mux {
 	}
 
 	// 注册 NotFound 处理器，用于匹配自定义函数路由
-	r.NotFound(h.HandleCustomRoute)
+	r.NotFound(identify(http.HandlerFunc(h.HandleCustomRoute)).ServeHTTP)
 
 	return r
 }
@@ -470,9 +660,11 @@ func NewRouter(cfg *RouterConfig) *chi.Mux {
 // corsMiddleware 是处理跨域资源共享(CORS)的中间件。
 //
 // 功能说明：
-//   - 设置允许所有来源的跨域请求（Access-Control-Allow-Origin: *）
+//   - 未配置 server.cors_allowed_origins 时允许所有来源的跨域请求（Access-Control-Allow-Origin: *）
+//   - 配置后只对列表中的来源回显 Origin，其他来源不返回 Access-Control-Allow-Origin
 //   - 允许的HTTP方法：GET, POST, PUT, DELETE, OPTIONS
 //   - 允许的请求头：Content-Type, Authorization
+//   - 暴露调用元数据响应头（X-Nimbus-Invocation-Id 等）给浏览器
 //   - 处理预检请求（OPTIONS方法）
 //
 // 参数：
@@ -483,13 +675,18 @@ func NewRouter(cfg *RouterConfig) *chi.Mux {
 //
 // 安全提示：
 //
-//	生产环境中应考虑限制Access-Control-Allow-Origin为特定域名
+//	生产环境中应通过 server.cors_allowed_origins 限制为特定域名
 //	而不是使用通配符"*"，以提高安全性
-func corsMiddleware(next http.Handler) http.Handler {
+func (h *Handler) corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// 设置CORS响应头
-		// 允许所有来源访问（生产环境建议设置为特定域名）
-		w.Header().Set("Access-Control-Allow-Origin", "*")
+		// 未配置允许的来源时允许所有来源访问
+		if len(h.allowedOrigins) == 0 {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else if origin := r.Header.Get("Origin"); origin != "" && h.originListed(origin) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Add("Vary", "Origin")
+		}
 
 		// 允许的HTTP方法
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
@@ -497,6 +694,9 @@ func corsMiddleware(next http.Handler) http.Handler {
 		// 允许的请求头
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
+		// 允许浏览器读取的调用元数据响应头
+		w.Header().Set("Access-Control-Expose-Headers", invocationHeaderNames)
+
 		// 处理预检请求（OPTIONS方法）
 		// 浏览器在发送跨域请求前会先发送OPTIONS请求来检查服务器是否允许
 		if r.Method == "OPTIONS" {
@@ -508,3 +708,19 @@ func corsMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// SetAllowedOrigins 设置允许跨域访问的来源列表（如 https://console.example.com），
+// 同时用于函数 WebSocket 连接的 Origin 校验。为空时 CORS 允许所有来源。
+func (h *Handler) SetAllowedOrigins(origins []string) {
+	h.allowedOrigins = origins
+}
+
+// originListed 判断来源是否在允许的来源列表中，列表中的 "*" 匹配任意来源。
+func (h *Handler) originListed(origin string) bool {
+	for _, allowed := range h.allowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
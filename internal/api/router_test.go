@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/auth"
+)
+
+// roleKeys 是按原始 Key 返回指定角色用户的 API Key 验证器
+type roleKeys map[string]string
+
+func (k roleKeys) ValidateAPIKey(key string) (*auth.UserContext, error) {
+	role, ok := k[key]
+	if !ok {
+		return nil, auth.ErrAPIKeyNotFound
+	}
+	return &auth.UserContext{UserID: key, Role: role, Method: "apikey"}, nil
+}
+
+// TestRouterVersionBinaryRoles 测试下载版本编译产物的角色授权。
+// 使用无效版本号，通过角色检查的请求由处理器返回 400，不访问存储。
+func TestRouterVersionBinaryRoles(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	router := NewRouter(&RouterConfig{
+		Handler: &Handler{},
+		Logger:  logger,
+		Auth: auth.NewMiddleware(nil, "X-API-Key", roleKeys{
+			"admin-key": auth.RoleAdmin,
+			"user-key":  auth.RoleUser,
+			"read-key":  auth.RoleRead,
+			"guest-key": "guest",
+		}, true),
+	})
+
+	tests := []struct {
+		name string
+		key  string
+		want int
+	}{
+		{name: "admin", key: "admin-key", want: http.StatusBadRequest},
+		{name: "user", key: "user-key", want: http.StatusBadRequest},
+		{name: "read", key: "read-key", want: http.StatusBadRequest},
+		{name: "other role", key: "guest-key", want: http.StatusForbidden},
+		{name: "anonymous", want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/functions/demo/versions/latest/binary", nil)
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// ListRuntimes 返回所有受支持运行时的输入输出契约。
+// HTTP端点: GET /api/v1/runtimes
+//
+// 每个运行时包含处理函数签名、stdin 输入信封的 JSON Schema、输出和错误约定、
+// 是否由平台编译、是否支持调试、注入的环境变量以及处理函数示例；
+// Docker 运行模式下还包含实际使用的运行时镜像。
+func (h *Handler) ListRuntimes(w http.ResponseWriter, r *http.Request) {
+	var images map[string]string
+	if lister, ok := h.scheduler.(RuntimeImageLister); ok {
+		images = lister.RuntimeImages()
+	}
+
+	contracts := domain.RuntimeContracts()
+	for i := range contracts {
+		contracts[i].DebugSupported = isDebugSupported(contracts[i].Runtime)
+		contracts[i].Image = images[string(contracts[i].Runtime)]
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"runtimes": contracts,
+	})
+}
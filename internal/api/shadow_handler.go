@@ -0,0 +1,183 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/oriys/nimbus/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// ==================== 影子流量管理处理器 ====================
+
+// UpdateShadowConfigRequest 表示设置影子流量配置的请求。
+type UpdateShadowConfigRequest struct {
+	// TargetFunctionID 影子目标函数（ID或名称），为空表示主函数自身
+	TargetFunctionID string `json:"target_function_id,omitempty"`
+	// TargetVersion 影子目标版本号，0 表示目标函数当前代码
+	TargetVersion int `json:"target_version,omitempty"`
+	// Percentage 回放到影子目标的流量百分比（0-100）
+	Percentage int `json:"percentage"`
+	// Enabled 是否启用，默认启用
+	Enabled *bool `json:"enabled,omitempty"`
+	// TimeoutMs 影子调用超时（毫秒），0 表示使用调度器默认值
+	TimeoutMs int `json:"timeout_ms,omitempty"`
+}
+
+// invalidateShadowConfig 使调度器中函数影子流量配置的缓存失效，调度器不缓存影子配置时为空操作。
+func (h *Handler) invalidateShadowConfig(functionID string) {
+	if inv, ok := h.scheduler.(ShadowConfigInvalidator); ok {
+		inv.InvalidateShadowConfig(functionID)
+	}
+}
+
+// GetShadowConfig 获取函数的影子流量配置。
+// HTTP端点: GET /api/v1/functions/{id}/shadow
+func (h *Handler) GetShadowConfig(w http.ResponseWriter, r *http.Request) {
+	fn, ok := h.lookupFunction(w, r)
+	if !ok {
+		return
+	}
+
+	cfg, err := h.store.GetShadowConfig(fn.ID)
+	if errors.Is(err, domain.ErrShadowConfigNotFound) {
+		writeErrorWithContext(w, r, http.StatusNotFound, "shadow config not found")
+		return
+	}
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to get shadow config: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, cfg)
+}
+
+// UpdateShadowConfig 创建或更新函数的影子流量配置。
+// HTTP端点: PUT /api/v1/functions/{id}/shadow
+//
+// 同步调用时按 percentage 比例将请求在后台回放到影子目标，
+// 影子调用的输出被丢弃，仅记录对比结果，不影响真实响应。
+func (h *Handler) UpdateShadowConfig(w http.ResponseWriter, r *http.Request) {
+	fn, ok := h.lookupFunction(w, r)
+	if !ok || !checkFunctionScope(w, r, fn) {
+		return
+	}
+
+	var req UpdateShadowConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	cfg := &domain.ShadowConfig{
+		FunctionID:    fn.ID,
+		TargetVersion: req.TargetVersion,
+		Percentage:    req.Percentage,
+		Enabled:       req.Enabled == nil || *req.Enabled,
+		TimeoutMs:     req.TimeoutMs,
+	}
+
+	// 解析影子目标函数，支持 ID 或名称
+	target := fn
+	if req.TargetFunctionID != "" && req.TargetFunctionID != fn.ID && req.TargetFunctionID != fn.Name {
+		var err error
+		target, err = h.store.GetFunctionByID(req.TargetFunctionID)
+		if err == domain.ErrFunctionNotFound {
+			target, err = h.store.GetFunctionByName(req.TargetFunctionID)
+		}
+		if err == domain.ErrFunctionNotFound {
+			writeErrorWithContext(w, r, http.StatusBadRequest, "shadow target function not found: "+req.TargetFunctionID)
+			return
+		}
+		if err != nil {
+			writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to get shadow target: "+err.Error())
+			return
+		}
+		// 影子调用以目标函数身份执行，目标同样必须在 API Key 的标签作用域内
+		if !checkFunctionScope(w, r, target) {
+			return
+		}
+		cfg.TargetFunctionID = target.ID
+	}
+
+	if err := cfg.Validate(); err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// 校验目标版本存在
+	if cfg.TargetVersion > 0 {
+		if _, err := h.store.GetFunctionVersion(target.ID, cfg.TargetVersion); err != nil {
+			writeErrorWithContext(w, r, http.StatusBadRequest, "shadow target version not found: "+strconv.Itoa(cfg.TargetVersion))
+			return
+		}
+	}
+
+	if err := h.store.SaveShadowConfig(cfg); err != nil {
+		h.logError(r, "UpdateShadowConfig", "保存影子流量配置失败", err, logrus.Fields{"function": fn.Name})
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to save shadow config: "+err.Error())
+		return
+	}
+	h.invalidateShadowConfig(fn.ID)
+
+	h.logInfo(r, "UpdateShadowConfig", "影子流量配置已更新", logrus.Fields{
+		"function":       fn.Name,
+		"target":         target.Name,
+		"target_version": cfg.TargetVersion,
+		"percentage":     cfg.Percentage,
+		"enabled":        cfg.Enabled,
+		"timeout_ms":     cfg.TimeoutMs,
+	})
+	h.auditLog(r, "shadow_config.update", "function", fn.ID, fn.Name, nil)
+	writeJSON(w, http.StatusOK, cfg)
+}
+
+// DeleteShadowConfig 删除函数的影子流量配置。
+// HTTP端点: DELETE /api/v1/functions/{id}/shadow
+func (h *Handler) DeleteShadowConfig(w http.ResponseWriter, r *http.Request) {
+	fn, ok := h.lookupFunction(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.DeleteShadowConfig(fn.ID); err != nil {
+		if errors.Is(err, domain.ErrShadowConfigNotFound) {
+			writeErrorWithContext(w, r, http.StatusNotFound, "shadow config not found")
+			return
+		}
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to delete shadow config: "+err.Error())
+		return
+	}
+
+	h.invalidateShadowConfig(fn.ID)
+
+	h.logInfo(r, "DeleteShadowConfig", "影子流量配置已删除", logrus.Fields{"function": fn.Name})
+	h.auditLog(r, "shadow_config.delete", "function", fn.ID, fn.Name, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListShadowResults 获取函数的影子调用对比结果。
+// HTTP端点: GET /api/v1/functions/{id}/shadow/results
+//
+// 查询参数：
+//   - mismatch: 为 true 时只返回与主调用不一致的结果
+//   - offset/limit: 分页参数，limit 默认 20，最大 100
+func (h *Handler) ListShadowResults(w http.ResponseWriter, r *http.Request) {
+	fn, ok := h.lookupFunction(w, r)
+	if !ok {
+		return
+	}
+
+	offset, limit := parsePagination(r)
+	mismatchOnly := r.URL.Query().Get("mismatch") == "true"
+
+	results, total, err := h.store.ListShadowResults(fn.ID, mismatchOnly, offset, limit)
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to list shadow results: "+err.Error())
+		return
+	}
+
+	writePaginated(w, r, "results", results, total, offset, limit)
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// invalidateAliasCache 使调度器中函数别名的路由缓存失效，调度器不缓存别名时为空操作。
+func (h *Handler) invalidateAliasCache(functionID string) {
+	if inv, ok := h.scheduler.(AliasCacheInvalidator); ok {
+		inv.InvalidateAliases(functionID)
+	}
+}
+
+// checkSlotAlias 校验蓝绿槽位别名（blue/green）的路由配置。
+// 槽位别名必须将 100% 流量指向单个版本，校验失败时写入 400 响应并返回 false。
+func checkSlotAlias(w http.ResponseWriter, r *http.Request, name string, cfg domain.RoutingConfig) bool {
+	if !domain.IsDeploymentSlot(name) {
+		return true
+	}
+	if _, err := domain.SlotVersion(cfg); err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+// parseSlotParam 解析调用请求的 slot 查询参数，用于在切换前直接调用指定槽位（如冒烟测试 green）。
+// 参数值不是 blue/green 时写入 400 响应并返回 false。
+func parseSlotParam(w http.ResponseWriter, r *http.Request) (string, bool) {
+	slot := r.URL.Query().Get("slot")
+	if slot != "" && !domain.IsDeploymentSlot(slot) {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "slot must be blue or green")
+		return "", false
+	}
+	return slot, true
+}
+
+// resolveInvokeAlias 解析调用请求指定的别名：slot 查询参数指定蓝绿槽位，alias 查询参数指定任意别名
+// （如金丝雀别名），两者不能同时使用。别名不存在时写入 404 响应并返回 false，未指定时返回空字符串。
+func (h *Handler) resolveInvokeAlias(w http.ResponseWriter, r *http.Request, fn *domain.Function) (string, bool) {
+	slot, ok := parseSlotParam(w, r)
+	if !ok {
+		return "", false
+	}
+	alias := r.URL.Query().Get("alias")
+	if slot != "" && alias != "" {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "slot and alias cannot be used together")
+		return "", false
+	}
+	if slot != "" {
+		if _, err := h.store.GetFunctionAlias(fn.ID, slot); err != nil {
+			writeErrorWithContext(w, r, http.StatusNotFound, "slot alias "+slot+" not found")
+			return "", false
+		}
+		return slot, true
+	}
+	if alias != "" {
+		if _, err := h.store.GetFunctionAlias(fn.ID, alias); err != nil {
+			writeErrorWithContext(w, r, http.StatusNotFound, "alias "+alias+" not found")
+			return "", false
+		}
+	}
+	return alias, true
+}
+
+// InvokeFunctionAlias 按别名同步调用函数，按别名的 routing_config.weights 选择版本。
+// HTTP端点: POST /api/v1/functions/{id}/aliases/{name}/invoke
+//
+// 等价于 POST /api/v1/functions/{id}/invoke?alias={name}，响应的 routed_version 为选中的版本；
+// 请求携带 X-Routing-Key 时按路由键一致地选择版本，相同路由键总是命中同一版本。
+func (h *Handler) InvokeFunctionAlias(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("slot") != "" {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "slot cannot be used with alias invoke")
+		return
+	}
+	q := r.URL.Query()
+	q.Set("alias", chi.URLParam(r, "name"))
+	r.URL.RawQuery = q.Encode()
+	h.InvokeFunction(w, r)
+}
+
+// SwapFunctionSlot 切换函数蓝绿部署的线上槽位。
+// HTTP端点: POST /api/v1/functions/{id}/swap
+//
+// 请求体可选，{"to": "blue"|"green"} 指定目标槽位，省略时切换到另一个槽位；
+// 首次切换（尚未启用蓝绿部署）默认切换到 blue。目标槽位别名必须存在且指向单个已发布版本。
+// 线上槽位以比较并交换方式更新，并发切换时返回 409。
+func (h *Handler) SwapFunctionSlot(w http.ResponseWriter, r *http.Request) {
+	fn, ok := h.lookupFunction(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.SwapSlotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	target := req.To
+	if target == "" {
+		target = domain.OtherSlot(fn.LiveSlot)
+	}
+	if !domain.IsDeploymentSlot(target) {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "to must be blue or green")
+		return
+	}
+	if target == fn.LiveSlot {
+		writeErrorWithContext(w, r, http.StatusConflict, "slot "+target+" is already live")
+		return
+	}
+
+	// 目标槽位必须指向单个已发布版本
+	alias, err := h.store.GetFunctionAlias(fn.ID, target)
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusConflict, "slot alias "+target+" not found")
+		return
+	}
+	version, err := domain.SlotVersion(alias.RoutingConfig)
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusConflict, err.Error())
+		return
+	}
+	if _, err := h.store.GetFunctionVersion(fn.ID, version); err != nil {
+		writeErrorWithContext(w, r, http.StatusConflict, "slot "+target+" points to a missing version")
+		return
+	}
+
+	// 记录切换前的线上版本，便于审计和回滚
+	previousVersion := 0
+	if fn.LiveSlot != "" {
+		if prev, err := h.store.GetFunctionAlias(fn.ID, fn.LiveSlot); err == nil {
+			previousVersion, _ = domain.SlotVersion(prev.RoutingConfig)
+		}
+	}
+
+	if err := h.store.SetFunctionLiveSlot(fn.ID, fn.LiveSlot, target); err != nil {
+		if errors.Is(err, domain.ErrSlotSwapConflict) {
+			writeErrorWithContext(w, r, http.StatusConflict, err.Error())
+			return
+		}
+		h.logError(r, "SwapFunctionSlot", "切换线上槽位失败", err, logrus.Fields{"function": fn.Name})
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to swap slot: "+err.Error())
+		return
+	}
+	h.invalidateAliasCache(fn.ID)
+
+	h.auditLog(r, "function_slot_swap", "function", fn.ID, fn.Name, map[string]interface{}{
+		"from":             fn.LiveSlot,
+		"to":               target,
+		"previous_version": previousVersion,
+		"version":          version,
+	})
+	h.logInfo(r, "SwapFunctionSlot", "蓝绿槽位切换成功", logrus.Fields{
+		"function": fn.Name,
+		"from":     fn.LiveSlot,
+		"to":       target,
+		"version":  version,
+	})
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"function_id":      fn.ID,
+		"previous_slot":    fn.LiveSlot,
+		"live_slot":        target,
+		"previous_version": previousVersion,
+		"version":          version,
+	})
+}
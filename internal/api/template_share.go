@@ -0,0 +1,185 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// templateFetchTimeout 从远程目录下载模板文档的超时时间
+const templateFetchTimeout = 10 * time.Second
+
+// templateFetchClient 下载远程模板文档的 HTTP 客户端
+var templateFetchClient = &http.Client{Timeout: templateFetchTimeout}
+
+// ImportTemplatesFromURLRequest 从远程目录导入模板的请求
+type ImportTemplatesFromURLRequest struct {
+	// URL 是模板文档地址（http/https），文档可以是单个模板或模板文档
+	URL string `json:"url"`
+	// Overwrite 为 true 时覆盖同名的已有模板，否则同名模板导致整个导入失败
+	Overwrite bool `json:"overwrite,omitempty"`
+}
+
+// ExportTemplate 导出可分享的模板文档，文档可通过 import-from-url 导入到其他部署。
+// HTTP端点: GET /api/v1/templates/export/{id}
+//
+// 返回值：
+//   - 200: 成功，返回 kind 为 nimbus.template/v1 的模板文档
+//   - 404: 模板不存在
+func (h *Handler) ExportTemplate(w http.ResponseWriter, r *http.Request) {
+	idOrName := chi.URLParam(r, "id")
+
+	template, err := h.store.GetTemplateByID(idOrName)
+	if err == domain.ErrTemplateNotFound {
+		template, err = h.store.GetTemplateByName(idOrName)
+	}
+	if err == domain.ErrTemplateNotFound {
+		writeErrorWithContext(w, r, http.StatusNotFound, "template not found: "+idOrName)
+		return
+	}
+	if err != nil {
+		h.logError(r, "ExportTemplate", "查询模板失败", err, logrus.Fields{"template": idOrName})
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to get template: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", template.Name+".template.json"))
+	writeJSON(w, http.StatusOK, domain.NewTemplateDocument(template))
+}
+
+// ImportTemplatesFromURL 从远程目录下载模板文档并创建模板。
+// HTTP端点: POST /api/v1/templates/import-from-url
+//
+// 文档中的所有模板先全部校验（运行时、变量默认值等），任一无效时不创建任何模板。
+//
+// 返回值：
+//   - 201: 成功，返回导入的模板列表
+//   - 400: URL 无效，或文档格式、模板内容无效
+//   - 409: 存在同名模板且未指定 overwrite
+//   - 502: 下载文档失败
+func (h *Handler) ImportTemplatesFromURL(w http.ResponseWriter, r *http.Request) {
+	var req ImportTemplatesFromURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "url must be an absolute http or https URL")
+		return
+	}
+
+	h.logInfo(r, "ImportTemplatesFromURL", "开始导入远程模板", logrus.Fields{"url": req.URL, "overwrite": req.Overwrite})
+
+	data, err := fetchTemplateDocument(r, req.URL)
+	if err != nil {
+		h.logWarn(r, "ImportTemplatesFromURL", "下载模板文档失败", logrus.Fields{"url": req.URL, "error": err.Error()})
+		writeErrorWithContext(w, r, http.StatusBadGateway, "failed to fetch template document: "+err.Error())
+		return
+	}
+
+	templates, err := domain.ParseTemplateDocument(data)
+	if err != nil {
+		h.logWarn(r, "ImportTemplatesFromURL", "模板文档无效", logrus.Fields{"url": req.URL, "error": err.Error()})
+		writeErrorWithContext(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// 先检查所有名称冲突，避免导入一半后失败
+	existing := make(map[string]*domain.Template, len(templates))
+	for _, t := range templates {
+		if found, err := h.store.GetTemplateByName(t.Name); err == nil {
+			if !req.Overwrite {
+				writeErrorWithContext(w, r, http.StatusConflict, "template with this name already exists: "+t.Name)
+				return
+			}
+			existing[t.Name] = found
+		} else if !errors.Is(err, domain.ErrTemplateNotFound) {
+			h.logError(r, "ImportTemplatesFromURL", "查询模板失败", err, logrus.Fields{"name": t.Name})
+			writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to get template: "+err.Error())
+			return
+		}
+	}
+
+	imported := make([]*domain.Template, 0, len(templates))
+	for _, t := range templates {
+		template := &domain.Template{
+			Name:           t.Name,
+			DisplayName:    t.DisplayName,
+			Description:    t.Description,
+			Category:       t.Category,
+			Runtime:        t.Runtime,
+			Handler:        t.Handler,
+			Code:           t.Code,
+			Variables:      t.Variables,
+			DefaultMemory:  t.DefaultMemory,
+			DefaultTimeout: t.DefaultTimeout,
+			Tags:           t.Tags,
+			Icon:           t.Icon,
+		}
+		if old := existing[t.Name]; old != nil {
+			// 覆盖时保留本地的 ID、创建时间和热门标记
+			template.ID = old.ID
+			template.CreatedAt = old.CreatedAt
+			template.Popular = old.Popular
+			err = h.store.UpdateTemplate(template)
+		} else {
+			err = h.store.CreateTemplate(template)
+		}
+		if err != nil {
+			h.logError(r, "ImportTemplatesFromURL", "保存模板失败", err, logrus.Fields{"name": t.Name})
+			writeErrorWithContext(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to save template %s (%d of %d imported): %v", t.Name, len(imported), len(templates), err))
+			return
+		}
+		imported = append(imported, template)
+
+		h.auditLog(r, "template_import", "template", template.ID, template.Name, map[string]interface{}{
+			"source":      req.URL,
+			"runtime":     template.Runtime,
+			"overwritten": existing[t.Name] != nil,
+		})
+	}
+
+	h.logInfo(r, "ImportTemplatesFromURL", "远程模板导入完成", logrus.Fields{"url": req.URL, "count": len(imported)})
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"source":    req.URL,
+		"templates": imported,
+		"total":     len(imported),
+	})
+}
+
+// fetchTemplateDocument 下载模板文档，超过 MaxTemplateDocumentBytes 的文档被拒绝。
+func fetchTemplateDocument(r *http.Request, target string) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(r.Context(), http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+
+	resp, err := templateFetchClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, domain.MaxTemplateDocumentBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > domain.MaxTemplateDocumentBytes {
+		return nil, fmt.Errorf("document exceeds %d bytes", domain.MaxTemplateDocumentBytes)
+	}
+	return data, nil
+}
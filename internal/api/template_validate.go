@@ -0,0 +1,115 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/compiler"
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// templateValidatePageSize 是批量校验时分页读取模板的页大小
+const templateValidatePageSize = 100
+
+// ValidateTemplates 对所有模板执行试编译，检查模板当前能否成功创建函数。
+// HTTP端点: POST /api/v1/admin/templates/validate
+//
+// 每个模板使用变量默认值（必填且无默认值的变量按类型填入示例值）渲染代码，
+// 先按创建函数的规则校验运行时、入口点和代码大小，编译型运行时再执行一次编译。
+// 整个过程不创建函数，也不写入任何数据。可通过 ?runtime= 只校验指定运行时的模板。
+func (h *Handler) ValidateTemplates(w http.ResponseWriter, r *http.Request) {
+	runtimeFilter := r.URL.Query().Get("runtime")
+	h.logInfo(r, "ValidateTemplates", "开始校验模板", logrus.Fields{"runtime": runtimeFilter})
+
+	var templates []*domain.Template
+	for offset := 0; ; offset += templateValidatePageSize {
+		page, total, err := h.store.ListTemplates(offset, templateValidatePageSize, "", runtimeFilter)
+		if err != nil {
+			h.logError(r, "ValidateTemplates", "查询模板列表失败", err, nil)
+			writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to list templates: "+err.Error())
+			return
+		}
+		templates = append(templates, page...)
+		if len(page) == 0 || offset+len(page) >= total {
+			break
+		}
+	}
+
+	report := &domain.TemplateValidationReport{Results: []domain.TemplateValidationResult{}}
+	for _, tpl := range templates {
+		if r.Context().Err() != nil {
+			writeErrorWithContext(w, r, http.StatusRequestTimeout, "template validation cancelled")
+			return
+		}
+		report.Add(h.validateTemplate(r, tpl))
+	}
+
+	h.logInfo(r, "ValidateTemplates", "模板校验完成", logrus.Fields{
+		"total":  report.Total,
+		"passed": report.Passed,
+		"failed": report.Failed,
+	})
+	h.auditLog(r, "template_validate", "template", "", "", map[string]interface{}{
+		"runtime": runtimeFilter,
+		"total":   report.Total,
+		"failed":  report.Failed,
+	})
+	writeJSON(w, http.StatusOK, report)
+}
+
+// validateTemplate 渲染单个模板并执行配置校验和试编译。
+func (h *Handler) validateTemplate(r *http.Request, tpl *domain.Template) domain.TemplateValidationResult {
+	start := time.Now()
+	result := domain.TemplateValidationResult{
+		TemplateID: tpl.ID,
+		Name:       tpl.Name,
+		Runtime:    tpl.Runtime,
+	}
+	fail := func(stage, msg string) domain.TemplateValidationResult {
+		result.Stage = stage
+		result.Error = msg
+		result.DurationMs = time.Since(start).Milliseconds()
+		h.logWarn(r, "ValidateTemplates", "模板校验失败", logrus.Fields{
+			"template": tpl.Name,
+			"stage":    stage,
+			"error":    msg,
+		})
+		return result
+	}
+
+	code := tpl.Render(tpl.SampleVariables())
+
+	// 与从模板创建函数使用相同的校验规则
+	req := &domain.CreateFunctionRequest{
+		Name:       tpl.Name,
+		Runtime:    tpl.Runtime,
+		Handler:    tpl.Handler,
+		Code:       code,
+		MemoryMB:   tpl.DefaultMemory,
+		TimeoutSec: tpl.DefaultTimeout,
+	}
+	if err := req.Validate(); err != nil {
+		return fail(domain.TemplateValidationStageConfig, err.Error())
+	}
+
+	if compiler.IsCompiledRuntime(string(tpl.Runtime)) && compiler.IsSourceCode(string(tpl.Runtime), code) {
+		result.Compiled = true
+		resp, err := h.compiler.Compile(r.Context(), &compiler.CompileRequest{
+			Runtime: string(tpl.Runtime),
+			Code:    code,
+		})
+		if err != nil {
+			return fail(domain.TemplateValidationStageCompile, err.Error())
+		}
+		if !resp.Success {
+			result.Output = resp.Output
+			return fail(domain.TemplateValidationStageCompile, resp.Error)
+		}
+	}
+
+	result.OK = true
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result
+}
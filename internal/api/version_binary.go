@@ -0,0 +1,101 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// GetFunctionVersionBinary 下载函数指定版本的编译产物，用于核对线上运行的产物或在本地复现问题。
+// 默认返回原始字节（application/octet-stream），?encoding=base64 时返回 base64 文本。
+// 代码哈希、产物哈希和构建信息通过响应头返回。
+// HTTP端点: GET /api/v1/functions/{id}/versions/{version}/binary
+//
+// 返回值：
+//   - 200: 编译产物
+//   - 400: 版本号或 encoding 参数无效
+//   - 404: 函数或版本不存在，或该版本没有编译产物（解释型运行时）
+func (h *Handler) GetFunctionVersionBinary(w http.ResponseWriter, r *http.Request) {
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "invalid version number")
+		return
+	}
+	encoding := r.URL.Query().Get("encoding")
+	if encoding != "" && encoding != "base64" && encoding != "raw" {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "invalid encoding: must be raw or base64")
+		return
+	}
+
+	fn, ok := h.lookupFunction(w, r)
+	if !ok {
+		return
+	}
+
+	v, err := h.store.GetFunctionVersion(fn.ID, version)
+	if err == domain.ErrFunctionNotFound {
+		writeErrorWithContext(w, r, http.StatusNotFound, "version not found")
+		return
+	}
+	if err != nil {
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to get version: "+err.Error())
+		return
+	}
+	if v.Binary == "" {
+		writeErrorWithContext(w, r, http.StatusNotFound, fmt.Sprintf("version %d has no compiled binary", version))
+		return
+	}
+
+	binary, err := base64.StdEncoding.DecodeString(v.Binary)
+	if err != nil {
+		h.logError(r, "GetFunctionVersionBinary", "版本编译产物解码失败", err, logrus.Fields{"function": fn.Name, "version": version})
+		writeErrorWithContext(w, r, http.StatusInternalServerError, "failed to decode binary: "+err.Error())
+		return
+	}
+	sum := sha256.Sum256(binary)
+
+	// 代码哈希与构建信息
+	header := w.Header()
+	header.Set("X-Nimbus-Code-Hash", v.CodeHash)
+	header.Set("X-Nimbus-Binary-Sha256", hex.EncodeToString(sum[:]))
+	header.Set("X-Nimbus-Runtime", string(fn.Runtime))
+	header.Set("X-Nimbus-Handler", v.Handler)
+	header.Set("X-Nimbus-Function-Version", strconv.Itoa(v.Version))
+	header.Set("X-Nimbus-Built-At", v.CreatedAt.UTC().Format(time.RFC3339))
+
+	h.logInfo(r, "GetFunctionVersionBinary", "下载版本编译产物", logrus.Fields{
+		"function": fn.Name,
+		"version":  version,
+		"size":     len(binary),
+		"encoding": encoding,
+	})
+
+	if encoding == "base64" {
+		header.Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(v.Binary))
+		return
+	}
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set("Content-Length", strconv.Itoa(len(binary)))
+	header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s-v%d%s", fn.Name, v.Version, binaryExtension(fn.Runtime)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(binary)
+}
+
+// binaryExtension 返回运行时编译产物的文件扩展名。
+func binaryExtension(runtime domain.Runtime) string {
+	if runtime == domain.RuntimeWasm {
+		return ".wasm"
+	}
+	return ""
+}
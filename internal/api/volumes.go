@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// registeredDataVolumes 返回调度器已注册的共享数据卷名称，调度器不支持挂载数据卷时返回 nil。
+func (h *Handler) registeredDataVolumes() []string {
+	lister, ok := h.scheduler.(DataVolumeLister)
+	if !ok {
+		return nil
+	}
+	return lister.DataVolumes()
+}
+
+// checkDataVolumes 校验请求挂载的数据卷均已由运维方注册（部署配置 docker.data_volumes）。
+// 存在未注册的数据卷时写入 400 响应并返回 false。
+func (h *Handler) checkDataVolumes(w http.ResponseWriter, r *http.Request, names []string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	registered := make(map[string]struct{})
+	for _, name := range h.registeredDataVolumes() {
+		registered[name] = struct{}{}
+	}
+	for _, name := range names {
+		if _, ok := registered[name]; !ok {
+			h.logWarn(r, "checkDataVolumes", "数据卷未注册", logrus.Fields{"volume": name})
+			writeErrorWithContext(w, r, http.StatusBadRequest, domain.ErrDataVolumeNotRegistered.Error()+": "+name)
+			return false
+		}
+	}
+	return true
+}
+
+// ListDataVolumes 列出可供函数挂载的共享数据卷。
+// HTTP端点: GET /api/v1/data-volumes
+//
+// 数据卷由运维方在部署配置中注册，只返回名称和容器内挂载路径，不暴露宿主机路径。
+func (h *Handler) ListDataVolumes(w http.ResponseWriter, r *http.Request) {
+	names := h.registeredDataVolumes()
+	volumes := make([]map[string]string, 0, len(names))
+	for _, name := range names {
+		volumes = append(volumes, map[string]string{
+			"name":       name,
+			"mount_path": domain.DataVolumeMountDir + "/" + name,
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data_volumes": volumes,
+	})
+}
@@ -326,6 +326,40 @@ func (h *WorkflowHandler) GetExecutionHistory(w http.ResponseWriter, r *http.Req
 	})
 }
 
+// ListExecutionInvocations 获取工作流执行发起的函数调用记录
+// GET /api/v1/workflows/{id}/executions/{execId}/invocations
+func (h *WorkflowHandler) ListExecutionInvocations(w http.ResponseWriter, r *http.Request) {
+	workflowID := chi.URLParam(r, "id")
+	execID := chi.URLParam(r, "execId")
+
+	exec, err := h.store.GetExecutionByID(execID)
+	if err != nil {
+		if err == domain.ErrExecutionNotFound {
+			h.writeError(w, http.StatusNotFound, "execution not found", err)
+		} else {
+			h.writeError(w, http.StatusInternalServerError, "failed to get execution", err)
+		}
+		return
+	}
+	// 执行必须属于路径中的工作流
+	if exec.WorkflowID != workflowID {
+		h.writeError(w, http.StatusNotFound, "execution not found", domain.ErrExecutionNotFound)
+		return
+	}
+
+	invocations, err := h.store.ListInvocationsByWorkflowExecution(execID)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, "failed to list execution invocations", err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"execution_id": execID,
+		"invocations":  invocations,
+		"total":        len(invocations),
+	})
+}
+
 // getPagination 获取分页参数
 func (h *WorkflowHandler) getPagination(r *http.Request) (offset, limit int) {
 	offset = 0
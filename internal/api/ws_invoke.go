@@ -0,0 +1,321 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+const (
+	// wsMaxMessageSize 客户端单条消息（即单次调用载荷）的最大字节数
+	wsMaxMessageSize = 1 << 20
+	// wsWriteTimeout 向客户端写入单条消息的超时时间
+	wsWriteTimeout = 10 * time.Second
+	// wsPingInterval 服务端发送 ping 的间隔
+	wsPingInterval = 30 * time.Second
+	// wsPongWait 等待客户端 pong（或任意消息）的最长时间，超时后关闭连接
+	wsPongWait = 2 * wsPingInterval
+	// wsMaxPending 单个连接上已接收、等待调用的消息数上限，超出时新消息直接返回 error 消息
+	wsMaxPending = 16
+)
+
+// 函数 WebSocket 连接上发给客户端的消息类型
+const (
+	wsFrameConnected = "connected" // 连接建立，携带连接 ID
+	wsFrameResult    = "result"    // 一条入站消息对应的调用结果
+	wsFramePush      = "push"      // 通过推送接口主动发送给连接的消息
+	wsFrameError     = "error"     // 入站消息未能调用函数（如超出限流）
+)
+
+// errWSTooManyPending 连接上等待调用的消息已达上限
+const errWSTooManyPending = "too many pending messages on this connection"
+
+// wsFrame 函数 WebSocket 连接上发给客户端的消息。
+type wsFrame struct {
+	Type         string          `json:"type"`
+	ConnectionID string          `json:"connection_id,omitempty"`
+	RequestID    string          `json:"request_id,omitempty"`
+	StatusCode   int             `json:"status_code,omitempty"`
+	Body         json.RawMessage `json:"body,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	DurationMs   int64           `json:"duration_ms,omitempty"`
+	Data         json.RawMessage `json:"data,omitempty"`
+}
+
+// wsInvocationPayload 每条入站消息作为调用载荷传给函数时的结构。
+// 函数可以使用 connection_id 通过推送接口向该连接主动发送消息。
+type wsInvocationPayload struct {
+	ConnectionID string          `json:"connection_id"`
+	Message      json.RawMessage `json:"message"`
+}
+
+// wsConnection 一条函数调用 WebSocket 连接。
+type wsConnection struct {
+	id         string
+	functionID string
+	conn       *websocket.Conn
+
+	writeMu sync.Mutex // gorilla/websocket 不支持并发写
+}
+
+// send 向客户端写入一条消息。
+func (c *wsConnection) send(frame *wsFrame) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return c.conn.WriteJSON(frame)
+}
+
+// ping 向客户端发送 ping 控制帧。
+func (c *wsConnection) ping() error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout))
+}
+
+// wsRegistry 本实例上的函数调用 WebSocket 连接，按连接 ID 索引。
+type wsRegistry struct {
+	mu    sync.Mutex
+	conns map[string]*wsConnection
+}
+
+func (r *wsRegistry) add(c *wsConnection) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conns == nil {
+		r.conns = make(map[string]*wsConnection)
+	}
+	r.conns[c.id] = c
+}
+
+func (r *wsRegistry) remove(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.conns, id)
+}
+
+func (r *wsRegistry) get(id string) *wsConnection {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.conns[id]
+}
+
+// InvokeFunctionWS 通过 WebSocket 持续调用函数。
+// HTTP端点: GET /api/v1/functions/{id}/ws
+//
+// 连接建立后，客户端发送的每条消息都作为一次同步调用执行，调用结果以 result 消息返回。
+// 连接 ID 作为调用的 session_key，并随载荷传给函数，函数可以通过
+// POST /api/v1/functions/{id}/ws/{connectionId}/messages 向该连接主动推送消息。
+// 槽位或别名、环境、临时层、成本标签和调用链在建立连接时按与同步调用相同的规则解析，对连接上的所有消息生效。
+func (h *Handler) InvokeFunctionWS(w http.ResponseWriter, r *http.Request) {
+	fn, ok := h.lookupFunction(w, r)
+	if !ok {
+		return
+	}
+	if writePausedError(w, r, fn) {
+		return
+	}
+	if !fn.Status.CanInvoke() {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "function is not active, current status: "+string(fn.Status))
+		return
+	}
+	if !h.checkDeprecation(w, r, fn, "InvokeFunctionWS") {
+		return
+	}
+	opts, ok := h.resolveInvokeOptions(w, r, fn)
+	if !ok {
+		return
+	}
+
+	h.serveFunctionWS(w, r, fn, opts)
+}
+
+// serveFunctionWS 将请求升级为 WebSocket 连接，并把每条入站消息作为一次调用执行。
+// 读循环只负责接收消息和处理 pong，调用由单独的协程按接收顺序依次执行，
+// 长时间运行的调用不会阻塞心跳；等待调用的消息超过 wsMaxPending 条时新消息直接返回 error 消息。
+func (h *Handler) serveFunctionWS(w http.ResponseWriter, r *http.Request, fn *domain.Function, opts *invokeOptions) {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  4096,
+		WriteBufferSize: 4096,
+		CheckOrigin:     h.checkWSOrigin,
+	}
+	// 弃用等响应头随升级响应一并返回
+	conn, err := upgrader.Upgrade(w, r, w.Header())
+	if err != nil {
+		h.logWarn(r, "InvokeFunctionWS", "WebSocket 升级失败", logrus.Fields{"function": fn.Name, "error": err.Error()})
+		return
+	}
+	defer conn.Close()
+
+	c := &wsConnection{id: uuid.New().String(), functionID: fn.ID, conn: conn}
+	h.wsConns.add(c)
+	defer h.wsConns.remove(c.id)
+
+	h.logInfo(r, "InvokeFunctionWS", "函数 WebSocket 连接已建立", logrus.Fields{"function": fn.Name, "connection_id": c.id})
+	if err := c.send(&wsFrame{Type: wsFrameConnected, ConnectionID: c.id}); err != nil {
+		return
+	}
+
+	conn.SetReadLimit(wsMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(wsPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := c.ping(); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	// 调用协程：按接收顺序依次调用，连接关闭后丢弃尚未开始的消息
+	pending := make(chan []byte, wsMaxPending)
+	defer close(pending)
+	go func() {
+		for msg := range pending {
+			select {
+			case <-done:
+				continue
+			default:
+			}
+			if err := c.send(h.invokeWSMessage(r, fn, opts, c.id, msg)); err != nil {
+				conn.Close() // 使读循环退出
+			}
+		}
+	}()
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				h.logWarn(r, "InvokeFunctionWS", "函数 WebSocket 连接异常断开", logrus.Fields{"connection_id": c.id, "error": err.Error()})
+			}
+			h.logInfo(r, "InvokeFunctionWS", "函数 WebSocket 连接已关闭", logrus.Fields{"function": fn.Name, "connection_id": c.id})
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+
+		select {
+		case pending <- msg:
+		default:
+			if err := c.send(&wsFrame{Type: wsFrameError, Error: errWSTooManyPending}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// checkWSOrigin 校验函数 WebSocket 连接的 Origin 请求头。
+// 浏览器的跨站 WebSocket 连接不受 CORS 预检约束，因此不能放行所有来源：
+// 不带 Origin 的非浏览器客户端放行；配置了允许的来源时只放行列表中的来源，
+// 未配置时只放行与请求 Host 同源的页面。
+func (h *Handler) checkWSOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if len(h.allowedOrigins) > 0 {
+		return h.originListed(origin)
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// invokeWSMessage 将一条入站消息作为调用执行，返回发给客户端的结果消息。
+// 不是合法 JSON 的消息作为 JSON 字符串传给函数。
+// 每条消息按函数的限流配置消耗一个令牌；连接的生命周期可能超过请求超时，因此不使用请求的 context。
+// Redis 不可用时降级为不限流并记录警告。
+func (h *Handler) invokeWSMessage(r *http.Request, fn *domain.Function, opts *invokeOptions, connID string, msg []byte) *wsFrame {
+	if fn.RateLimit != nil && h.redis != nil {
+		rlCtx, cancel := context.WithTimeout(context.Background(), rateLimitCheckTimeout)
+		status, err := h.redis.TakeRateLimitToken(rlCtx, fn.ID, fn.RateLimit)
+		cancel()
+		if err != nil {
+			h.logWarn(r, "InvokeFunctionWS", "限流检查失败，放行请求", logrus.Fields{"function": fn.Name, "error": err.Error()})
+		} else if !status.Allowed {
+			return &wsFrame{Type: wsFrameError, Error: domain.ErrRateLimitExceeded.Error()}
+		}
+	}
+
+	message := json.RawMessage(msg)
+	if !json.Valid(msg) {
+		message, _ = json.Marshal(string(msg))
+	}
+	payload, err := json.Marshal(wsInvocationPayload{ConnectionID: connID, Message: message})
+	if err != nil {
+		return &wsFrame{Type: wsFrameError, Error: err.Error()}
+	}
+
+	req := opts.newRequest(r, fn, payload)
+	req.SessionKey = connID
+	resp, err := h.scheduler.Invoke(req)
+	if err != nil {
+		return &wsFrame{Type: wsFrameError, Error: err.Error()}
+	}
+	return &wsFrame{
+		Type:       wsFrameResult,
+		RequestID:  resp.RequestID,
+		StatusCode: resp.StatusCode,
+		Body:       resp.Body,
+		Error:      resp.Error,
+		DurationMs: resp.DurationMs,
+	}
+}
+
+// PushFunctionWSMessage 向函数的 WebSocket 连接主动推送一条消息。
+// HTTP端点: POST /api/v1/functions/{id}/ws/{connectionId}/messages
+//
+// 请求体为任意 JSON，原样作为 push 消息的 data 发送。
+// 连接不存在、已关闭或不属于该函数时返回 404；连接只登记在建立它的实例上。
+func (h *Handler) PushFunctionWSMessage(w http.ResponseWriter, r *http.Request) {
+	fn, ok := h.lookupFunction(w, r)
+	if !ok {
+		return
+	}
+	connID := chi.URLParam(r, "connectionId")
+	c := h.wsConns.get(connID)
+	if c == nil || c.functionID != fn.ID {
+		writeErrorWithContext(w, r, http.StatusNotFound, "websocket connection not found: "+connID)
+		return
+	}
+
+	var data json.RawMessage
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, wsMaxMessageSize)).Decode(&data); err != nil {
+		writeErrorWithContext(w, r, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if err := c.send(&wsFrame{Type: wsFramePush, Data: data}); err != nil {
+		h.logWarn(r, "PushFunctionWSMessage", "推送 WebSocket 消息失败", logrus.Fields{"connection_id": connID, "error": err.Error()})
+		writeErrorWithContext(w, r, http.StatusGone, "websocket connection closed: "+connID)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"connection_id": connID,
+		"delivered":     true,
+	})
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+func TestCheckWSOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		host    string
+		origin  string
+		want    bool
+	}{
+		{name: "non-browser client without origin", host: "api.example.com", want: true},
+		{name: "same origin without allow list", host: "api.example.com", origin: "https://api.example.com", want: true},
+		{name: "cross origin without allow list", host: "api.example.com", origin: "https://evil.example.com", want: false},
+		{name: "listed origin", allowed: []string{"https://console.example.com"}, host: "api.example.com", origin: "https://console.example.com", want: true},
+		{name: "unlisted origin", allowed: []string{"https://console.example.com"}, host: "api.example.com", origin: "https://api.example.com", want: false},
+		{name: "wildcard", allowed: []string{"*"}, host: "api.example.com", origin: "https://evil.example.com", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{}
+			h.SetAllowedOrigins(tt.allowed)
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/functions/demo/ws", nil)
+			r.Host = tt.host
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if got := h.checkWSOrigin(r); got != tt.want {
+				t.Errorf("checkWSOrigin() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// blockingScheduler 在 release 关闭前阻塞所有调用，并将载荷原样作为响应体返回
+type blockingScheduler struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+
+	mu   sync.Mutex
+	reqs []*domain.InvokeRequest
+}
+
+func (s *blockingScheduler) Invoke(req *domain.InvokeRequest) (*domain.InvokeResponse, error) {
+	s.mu.Lock()
+	s.reqs = append(s.reqs, req)
+	s.mu.Unlock()
+	s.once.Do(func() { close(s.started) })
+	<-s.release
+	return &domain.InvokeResponse{RequestID: "inv", StatusCode: 200, Body: req.Payload}, nil
+}
+
+func (s *blockingScheduler) InvokeAsync(*domain.InvokeRequest) (string, error) { return "", nil }
+
+func TestServeFunctionWS(t *testing.T) {
+	s := &blockingScheduler{started: make(chan struct{}), release: make(chan struct{})}
+	h := &Handler{scheduler: s}
+	fn := &domain.Function{ID: "fn-1", Name: "demo"}
+	opts := &invokeOptions{costTags: map[string]string{"team": "payments"}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.serveFunctionWS(w, r, fn, opts)
+	}))
+	defer srv.Close()
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	// 跨站页面的连接被拒绝
+	if _, resp, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": {"https://evil.example.com"}}); err == nil {
+		t.Fatal("cross-origin dial succeeded, want handshake rejected")
+	} else if resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("cross-origin dial response = %v, want 403", resp)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var connected wsFrame
+	if err := conn.ReadJSON(&connected); err != nil || connected.Type != wsFrameConnected {
+		t.Fatalf("first frame = %+v (err %v), want connected", connected, err)
+	}
+
+	// 第一条消息的调用阻塞期间读循环仍在接收：队列占满后的消息立即返回 error 消息
+	conn.WriteMessage(websocket.TextMessage, []byte(`0`))
+	<-s.started
+	for i := 1; i <= wsMaxPending+1; i++ {
+		conn.WriteJSON(i)
+	}
+	var rejected wsFrame
+	if err := conn.ReadJSON(&rejected); err != nil || rejected.Type != wsFrameError || rejected.Error != errWSTooManyPending {
+		t.Fatalf("frame while invoking = %+v (err %v), want %q error", rejected, err, errWSTooManyPending)
+	}
+
+	// 放行后按接收顺序返回结果
+	close(s.release)
+	for i := 0; i <= wsMaxPending; i++ {
+		var frame wsFrame
+		if err := conn.ReadJSON(&frame); err != nil {
+			t.Fatalf("read result %d: %v", i, err)
+		}
+		var payload wsInvocationPayload
+		if err := json.Unmarshal(frame.Body, &payload); err != nil {
+			t.Fatalf("result %d body = %s: %v", i, frame.Body, err)
+		}
+		if frame.Type != wsFrameResult || string(payload.Message) != jsonInt(i) || payload.ConnectionID != connected.ConnectionID {
+			t.Fatalf("result %d = %+v (payload %+v), want message %d in order", i, frame, payload, i)
+		}
+	}
+
+	// 每次调用使用连接 ID 作为会话，并携带连接建立时解析的成本标签
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, req := range s.reqs {
+		if req.SessionKey != connected.ConnectionID || req.CostTags["team"] != "payments" || req.FunctionID != fn.ID {
+			t.Errorf("request = %+v, want session key, cost tags and function from the connection", req)
+		}
+	}
+}
+
+func jsonInt(i int) string {
+	data, _ := json.Marshal(i)
+	return string(data)
+}
@@ -31,6 +31,56 @@ type APIKey struct {
 	CreatedAt time.Time
 	// ExpiresAt API Key 的过期时间，nil 表示永不过期
 	ExpiresAt *time.Time
+	// TagSelector 标签选择器，非空时该 Key 只能访问包含全部这些标签的函数
+	TagSelector []string
+}
+
+// APIKeyLookup 定义了 API Key 验证所需的存储查询接口。
+type APIKeyLookup interface {
+	// GetAPIKeyByHash 根据哈希值查询 API Key，返回密钥 ID、用户 ID 和角色
+	GetAPIKeyByHash(keyHash string) (string, string, string, error)
+	// GetAPIKeyTagSelector 获取 API Key 的标签选择器
+	GetAPIKeyTagSelector(id string) ([]string, error)
+}
+
+// StoreKeyValidator 是基于存储的 APIKeyValidator 实现。
+type StoreKeyValidator struct {
+	// store API Key 存储查询接口
+	store APIKeyLookup
+}
+
+// NewStoreKeyValidator 创建基于存储的 API Key 验证器。
+// 参数:
+//   - store: API Key 存储查询接口
+//
+// 返回:
+//   - *StoreKeyValidator: 验证器实例
+func NewStoreKeyValidator(store APIKeyLookup) *StoreKeyValidator {
+	return &StoreKeyValidator{store: store}
+}
+
+// ValidateAPIKey 验证 API Key 并加载其标签选择器。
+// 参数:
+//   - key: 原始 API Key
+//
+// 返回:
+//   - *UserContext: 验证成功时返回用户上下文
+//   - error: Key 不存在、已过期或查询失败时返回错误
+func (v *StoreKeyValidator) ValidateAPIKey(key string) (*UserContext, error) {
+	id, userID, role, err := v.store.GetAPIKeyByHash(HashAPIKey(key))
+	if err != nil {
+		return nil, ErrAPIKeyNotFound
+	}
+	selector, err := v.store.GetAPIKeyTagSelector(id)
+	if err != nil {
+		return nil, err
+	}
+	return &UserContext{
+		UserID:      userID,
+		Role:        role,
+		Method:      "apikey",
+		TagSelector: selector,
+	}, nil
 }
 
 // GenerateAPIKey 生成一个新的 API Key。
@@ -28,8 +28,37 @@ type UserContext struct {
 	Role string
 	// Method 认证方式，可能的值为 "jwt" 或 "apikey"
 	Method string
+	// TagSelector API Key 的标签选择器，非空时只能访问包含全部这些标签的函数
+	TagSelector []string
 }
 
+// CanAccessTags 检查当前用户是否可以访问携带指定标签的函数。
+// 未设置标签选择器的用户拥有完全访问权限；否则函数必须包含选择器中的全部标签。
+// 参数:
+//   - tags: 目标函数的标签列表
+//
+// 返回:
+//   - bool: 允许访问返回 true
+func (u *UserContext) CanAccessTags(tags []string) bool {
+	if u == nil || len(u.TagSelector) == 0 {
+		return true
+	}
+	owned := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		owned[t] = true
+	}
+	for _, t := range u.TagSelector {
+		if !owned[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// FunctionTagsResolver 从请求中解析目标函数的标签。
+// 用于标签作用域授权，返回的错误会使授权检查跳过（由后续处理器决定响应）。
+type FunctionTagsResolver func(r *http.Request) ([]string, error)
+
 // APIKeyValidator 定义了 API Key 验证器的接口。
 // 任何实现此接口的类型都可以用于验证 API Key。
 type APIKeyValidator interface {
@@ -89,48 +118,159 @@ func (m *Middleware) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
-		// 首先尝试 API Key 认证
-		// 从请求头中获取 API Key
-		if apiKey := r.Header.Get(m.apiKeyHeader); apiKey != "" {
-			// 检查是否配置了 API Key 验证器
-			if m.keyValidator != nil {
-				// 验证 API Key 的有效性
-				if user, err := m.keyValidator.ValidateAPIKey(apiKey); err == nil {
-					// API Key 验证成功，将用户信息存入 context
-					ctx := context.WithValue(r.Context(), UserContextKey, user)
-					// 使用新的 context 继续处理请求
-					next.ServeHTTP(w, r.WithContext(ctx))
-					return
-				}
+		if user := m.identify(r); user != nil {
+			// 认证成功，将用户信息存入 context 后继续处理请求
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), UserContextKey, user)))
+			return
+		}
+
+		// 所有认证方式都失败，返回 401 未授权错误
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+	})
+}
+
+// Identify 是一个只识别身份、不强制认证的 HTTP 中间件。
+// 请求携带有效的 API Key 或 JWT 时，将用户信息存入 context，供标签作用域等授权检查使用；
+// 未携带凭证或凭证无效时按匿名请求放行，不返回 401。
+// 参数:
+//   - next: 下一个要执行的 HTTP 处理器
+//
+// 返回:
+//   - http.Handler: 包装后的 HTTP 处理器
+func (m *Middleware) Identify(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if user := m.identify(r); user != nil {
+			r = r.WithContext(context.WithValue(r.Context(), UserContextKey, user))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// identify 依次尝试 API Key 和 JWT Bearer Token 认证，返回认证成功的用户，均失败时返回 nil。
+func (m *Middleware) identify(r *http.Request) *UserContext {
+	// 首先尝试 API Key 认证
+	// 从请求头中获取 API Key
+	if apiKey := r.Header.Get(m.apiKeyHeader); apiKey != "" {
+		// 检查是否配置了 API Key 验证器
+		if m.keyValidator != nil {
+			// 验证 API Key 的有效性
+			if user, err := m.keyValidator.ValidateAPIKey(apiKey); err == nil {
+				return user
 			}
 		}
+	}
 
-		// API Key 认证失败或未提供，尝试 JWT Bearer Token 认证
-		// 获取 Authorization 头
-		authHeader := r.Header.Get("Authorization")
-		// 检查是否为 Bearer Token 格式
-		if strings.HasPrefix(authHeader, "Bearer ") {
-			// 提取令牌字符串（移除 "Bearer " 前缀）
-			token := strings.TrimPrefix(authHeader, "Bearer ")
-			// 验证 JWT 令牌
-			if claims, err := m.jwt.Validate(token); err == nil {
-				// JWT 验证成功，构建用户上下文
-				user := &UserContext{
-					UserID: claims.UserID,
-					Role:   claims.Role,
-					Method: "jwt",
-				}
-				// 将用户信息存入 context
-				ctx := context.WithValue(r.Context(), UserContextKey, user)
-				// 使用新的 context 继续处理请求
-				next.ServeHTTP(w, r.WithContext(ctx))
-				return
+	// API Key 认证失败或未提供，尝试 JWT Bearer Token 认证
+	// 获取 Authorization 头
+	authHeader := r.Header.Get("Authorization")
+	// 检查是否为 Bearer Token 格式
+	if strings.HasPrefix(authHeader, "Bearer ") && m.jwt != nil {
+		// 提取令牌字符串（移除 "Bearer " 前缀）
+		token := strings.TrimPrefix(authHeader, "Bearer ")
+		// 验证 JWT 令牌
+		if claims, err := m.jwt.Validate(token); err == nil {
+			// JWT 验证成功，构建用户上下文
+			return &UserContext{
+				UserID: claims.UserID,
+				Role:   claims.Role,
+				Method: "jwt",
 			}
 		}
+	}
+	return nil
+}
 
-		// 所有认证方式都失败，返回 401 未授权错误
-		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
-	})
+// ScopeFunctionTags 返回一个按函数标签进行授权的 HTTP 中间件。
+// 带有标签选择器的 API Key 只能读取、修改或调用包含全部选择器标签的函数，
+// 不满足条件时返回 403；未设置选择器的请求不受影响。
+// 参数:
+//   - resolve: 目标函数标签解析器
+//
+// 返回:
+//   - func(http.Handler) http.Handler: 授权中间件
+func (m *Middleware) ScopeFunctionTags(resolve FunctionTagsResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := GetUser(r.Context())
+			if !m.enabled || user == nil || len(user.TagSelector) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// 无法解析目标函数时交由后续处理器返回 404 等错误
+			tags, err := resolve(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !user.CanAccessTags(tags) {
+				http.Error(w, `{"error":"forbidden: api key is not scoped to this function"}`, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// 角色常量定义
+const (
+	// RoleAdmin 是管理员角色，拥有全部权限
+	RoleAdmin = "admin"
+	// RoleUser 是普通用户角色，未指定角色时签发的令牌默认使用该角色
+	RoleUser = "user"
+	// RoleRead 是只读角色，可以读取函数及其产物等敏感数据
+	RoleRead = "read"
+)
+
+// RequireRole 返回一个按用户角色进行授权的 HTTP 中间件。
+// 已认证用户的角色不在允许列表中时返回 403；认证未启用时不做检查。
+// 参数:
+//   - roles: 允许访问的角色列表
+//
+// 返回:
+//   - func(http.Handler) http.Handler: 授权中间件
+func (m *Middleware) RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !m.enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+			user := GetUser(r.Context())
+			if user != nil {
+				for _, role := range roles {
+					if user.Role == role {
+						next.ServeHTTP(w, r)
+						return
+					}
+				}
+			}
+			http.Error(w, `{"error":"forbidden: role not allowed"}`, http.StatusForbidden)
+		})
+	}
+}
+
+// DenyTagScoped 返回一个拒绝带标签作用域的 API Key 的 HTTP 中间件。
+// 用于工作流、死信队列等跨多个函数的资源：这些资源无法按单个函数的标签授权，
+// 作用域受限的 Key 访问时返回 403；未设置选择器的请求不受影响。
+//
+// 返回:
+//   - func(http.Handler) http.Handler: 授权中间件
+func (m *Middleware) DenyTagScoped() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if user := GetUser(r.Context()); m.enabled && user != nil && len(user.TagSelector) > 0 {
+				http.Error(w, `{"error":"forbidden: api key is scoped to tagged functions"}`, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
 // GetUser 从请求上下文中提取已认证的用户信息。
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// staticKeys 是按原始 Key 返回固定用户的 APIKeyValidator
+type staticKeys map[string]*UserContext
+
+func (k staticKeys) ValidateAPIKey(key string) (*UserContext, error) {
+	if user, ok := k[key]; ok {
+		return user, nil
+	}
+	return nil, ErrAPIKeyNotFound
+}
+
+func newTestMiddleware() *Middleware {
+	return NewMiddleware(nil, "X-API-Key", staticKeys{
+		"full":     {UserID: "u1", Role: RoleAdmin, Method: "apikey"},
+		"payments": {UserID: "u2", Role: RoleAdmin, Method: "apikey", TagSelector: []string{"team:payments"}},
+	}, true)
+}
+
+// serve 依次经过中间件执行请求，返回状态码和处理器看到的用户
+func serve(h func(http.Handler) http.Handler, key string, inner ...func(http.Handler) http.Handler) (int, *UserContext) {
+	var seen *UserContext
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = GetUser(r.Context())
+	})
+	for i := len(inner) - 1; i >= 0; i-- {
+		handler = inner[i](handler)
+	}
+	handler = h(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/functions/fn-1", nil)
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w.Code, seen
+}
+
+func TestCanAccessTags(t *testing.T) {
+	var anonymous *UserContext
+	if !anonymous.CanAccessTags(nil) {
+		t.Error("nil user should have full access")
+	}
+	if !(&UserContext{}).CanAccessTags(nil) {
+		t.Error("user without selector should have full access")
+	}
+
+	scoped := &UserContext{TagSelector: []string{"team:payments", "env:prod"}}
+	tests := []struct {
+		tags []string
+		want bool
+	}{
+		{[]string{"team:payments", "env:prod", "critical"}, true},
+		{[]string{"team:payments"}, false},
+		{[]string{"team:search", "env:prod"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := scoped.CanAccessTags(tt.tags); got != tt.want {
+			t.Errorf("CanAccessTags(%v) = %v, want %v", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	m := newTestMiddleware()
+
+	// 不携带或携带无效凭证的请求按匿名请求放行，不返回 401
+	for _, key := range []string{"", "unknown"} {
+		code, user := serve(m.Identify, key)
+		if code != http.StatusOK || user != nil {
+			t.Errorf("Identify(%q) = %d %+v, want 200 anonymous", key, code, user)
+		}
+	}
+
+	code, user := serve(m.Identify, "payments")
+	if code != http.StatusOK || user == nil || user.UserID != "u2" {
+		t.Fatalf("Identify(payments) = %d %+v, want user u2", code, user)
+	}
+
+	// Authenticate 仍然强制认证
+	if code, _ := serve(m.Authenticate, ""); code != http.StatusUnauthorized {
+		t.Errorf("Authenticate without key = %d, want 401", code)
+	}
+}
+
+func TestScopeFunctionTags(t *testing.T) {
+	m := newTestMiddleware()
+	tags := []string{"team:search"}
+	var resolveErr error
+	scope := m.ScopeFunctionTags(func(r *http.Request) ([]string, error) { return tags, resolveErr })
+
+	if code, _ := serve(m.Identify, "payments", scope); code != http.StatusForbidden {
+		t.Errorf("scoped key on other team's function = %d, want 403", code)
+	}
+	if code, _ := serve(m.Identify, "full", scope); code != http.StatusOK {
+		t.Errorf("unscoped key = %d, want 200", code)
+	}
+	if code, _ := serve(m.Identify, "", scope); code != http.StatusOK {
+		t.Errorf("anonymous request = %d, want 200", code)
+	}
+
+	tags = []string{"team:payments", "tier:gold"}
+	if code, _ := serve(m.Identify, "payments", scope); code != http.StatusOK {
+		t.Errorf("scoped key on matching function = %d, want 200", code)
+	}
+
+	// 目标不存在时交由处理器返回 404
+	resolveErr = errors.New("not found")
+	if code, _ := serve(m.Identify, "payments", scope); code != http.StatusOK {
+		t.Errorf("unresolvable target = %d, want passthrough", code)
+	}
+}
+
+func TestDenyTagScoped(t *testing.T) {
+	m := newTestMiddleware()
+	deny := m.DenyTagScoped()
+
+	if code, _ := serve(m.Identify, "payments", deny); code != http.StatusForbidden {
+		t.Errorf("scoped key = %d, want 403", code)
+	}
+	if code, _ := serve(m.Identify, "full", deny); code != http.StatusOK {
+		t.Errorf("unscoped key = %d, want 200", code)
+	}
+
+	// 认证未启用时不做检查
+	disabled := NewMiddleware(nil, "X-API-Key", nil, false)
+	ctx := context.WithValue(context.Background(), UserContextKey, &UserContext{TagSelector: []string{"team:payments"}})
+	w := httptest.NewRecorder()
+	disabled.DenyTagScoped()(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).
+		ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	if w.Code != http.StatusOK {
+		t.Errorf("disabled middleware = %d, want 200", w.Code)
+	}
+}
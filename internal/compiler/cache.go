@@ -0,0 +1,253 @@
+package compiler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 编译缓存默认配置
+const (
+	defaultCacheTTL      = 24 * time.Hour
+	defaultCacheMaxBytes = 256 << 20
+)
+
+// CacheStats 描述编译缓存的使用情况
+type CacheStats struct {
+	Entries   int   `json:"entries"`    // 缓存的编译产物数量
+	SizeBytes int64 `json:"size_bytes"` // 缓存占用的字节数（base64 编码后）
+	Hits      int64 `json:"hits"`       // 命中次数
+	Misses    int64 `json:"misses"`     // 未命中次数
+}
+
+// CacheKey 计算编译缓存键。
+// 缓存键由运行时、编译镜像及其摘要、源代码哈希、函数挂载的层内容哈希（按加载顺序）和编译参数组合而成，
+// 任一部分变化（如升级编译镜像、层发布新版本、修改编译参数）都会得到新的缓存键，不会复用旧的编译产物。
+//
+// 参数:
+//   - runtime: 运行时名称
+//   - image: 编译镜像名称
+//   - imageDigest: 编译镜像的摘要（本地镜像 ID）
+//   - code: 源代码
+//   - layers: 层内容哈希
+//   - build: 编译环境变量和编译参数（按固定顺序展开）
+//
+// 返回值:
+//   - string: 十六进制编码的 SHA-256 缓存键
+func CacheKey(runtime, image, imageDigest, code string, layers, build []string) string {
+	codeHash := sha256.Sum256([]byte(code))
+	h := sha256.New()
+	for _, part := range []string{runtime, image, imageDigest, hex.EncodeToString(codeHash[:])} {
+		h.Write([]byte(part))
+		h.Write([]byte{'\n'})
+	}
+	for _, layer := range layers {
+		h.Write([]byte("layer:" + layer))
+		h.Write([]byte{'\n'})
+	}
+	for _, part := range build {
+		h.Write([]byte("build:" + part))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// compileCache 按缓存键保存编译成功的产物。
+// 超过 TTL 的条目在读取时失效；总大小超过上限时淘汰最久未使用的条目。
+type compileCache struct {
+	ttl      time.Duration
+	maxBytes int64
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	size    int64
+	hits    int64
+	misses  int64
+	now     func() time.Time
+}
+
+// cacheEntry 单个缓存的编译产物
+type cacheEntry struct {
+	runtime  string
+	binary   string
+	storedAt time.Time
+	lastUsed time.Time
+}
+
+// newCompileCache 创建编译缓存，ttl 或 maxBytes <= 0 时返回 nil（不缓存）。
+func newCompileCache(ttl time.Duration, maxBytes int64) *compileCache {
+	if ttl <= 0 || maxBytes <= 0 {
+		return nil
+	}
+	return &compileCache{
+		ttl:      ttl,
+		maxBytes: maxBytes,
+		entries:  make(map[string]*cacheEntry),
+		now:      time.Now,
+	}
+}
+
+// get 返回缓存的编译产物，未命中或已过期时返回 false。
+func (c *compileCache) get(key string) (string, bool) {
+	if c == nil || key == "" {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	e, ok := c.entries[key]
+	if ok && now.Sub(e.storedAt) > c.ttl {
+		c.remove(key, e)
+		ok = false
+	}
+	if !ok {
+		c.misses++
+		return "", false
+	}
+	e.lastUsed = now
+	c.hits++
+	return e.binary, true
+}
+
+// put 缓存编译产物，超过总大小上限时淘汰最久未使用的条目。
+// 单个产物超过上限时不缓存。
+func (c *compileCache) put(key, runtime, binary string) {
+	if c == nil || key == "" || int64(len(binary)) > c.maxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if old, ok := c.entries[key]; ok {
+		c.remove(key, old)
+	}
+	now := c.now()
+	c.entries[key] = &cacheEntry{runtime: runtime, binary: binary, storedAt: now, lastUsed: now}
+	c.size += int64(len(binary))
+
+	for c.size > c.maxBytes {
+		var oldestKey string
+		var oldest *cacheEntry
+		for k, e := range c.entries {
+			if oldest == nil || e.lastUsed.Before(oldest.lastUsed) {
+				oldestKey, oldest = k, e
+			}
+		}
+		c.remove(oldestKey, oldest)
+	}
+}
+
+// invalidate 删除指定运行时的全部缓存条目，runtime 为空时清空整个缓存。
+// 返回删除的条目数。
+func (c *compileCache) invalidate(runtime string) int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for k, e := range c.entries {
+		if runtime == "" || e.runtime == runtime {
+			c.remove(k, e)
+			removed++
+		}
+	}
+	return removed
+}
+
+// stats 返回缓存的使用情况
+func (c *compileCache) stats() CacheStats {
+	if c == nil {
+		return CacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{Entries: len(c.entries), SizeBytes: c.size, Hits: c.hits, Misses: c.misses}
+}
+
+// remove 删除缓存条目，调用方需持有锁
+func (c *compileCache) remove(key string, e *cacheEntry) {
+	delete(c.entries, key)
+	c.size -= int64(len(e.binary))
+}
+
+// SetCache 设置编译缓存的有效期和总大小上限，任一值 <= 0 时禁用缓存。
+// 需在开始编译前调用。
+//
+// 参数:
+//   - ttl: 缓存条目的有效期
+//   - maxBytes: 缓存产物的总大小上限
+func (c *Compiler) SetCache(ttl time.Duration, maxBytes int64) {
+	c.cache = newCompileCache(ttl, maxBytes)
+}
+
+// InvalidateCache 清除指定运行时的编译缓存，runtime 为空时清除全部缓存。
+// 用于在编译工具链或基础镜像升级后避免复用旧的编译产物。
+//
+// 返回值:
+//   - int: 清除的条目数
+func (c *Compiler) InvalidateCache(runtime string) int {
+	return c.cache.invalidate(runtime)
+}
+
+// CacheStats 返回编译缓存的使用情况
+func (c *Compiler) CacheStats() CacheStats {
+	return c.cache.stats()
+}
+
+// cacheKey 计算编译请求的缓存键。
+// 编译镜像不存在或无法获取摘要时返回空字符串（不使用缓存）。
+func (c *Compiler) cacheKey(ctx context.Context, req *CompileRequest) string {
+	if c.cache == nil {
+		return ""
+	}
+	image := toolchainImage(ctx, req.Runtime)
+	digest := imageDigest(ctx, image)
+	if digest == "" {
+		return ""
+	}
+	return CacheKey(req.Runtime, image, digest, req.Code, req.Layers, buildFingerprint(req))
+}
+
+// buildFingerprint 按固定顺序展开编译请求的环境变量（按变量名排序）和编译参数，用于计算缓存键
+func buildFingerprint(req *CompileRequest) []string {
+	parts := buildEnvArgs(req.BuildEnv)
+	for _, arg := range req.BuildArgs {
+		parts = append(parts, "arg", arg)
+	}
+	return parts
+}
+
+// toolchainImage 返回运行时使用的编译镜像
+func toolchainImage(ctx context.Context, runtime string) string {
+	switch runtime {
+	case "go1.24":
+		return goImage
+	case "wasm":
+		return rustWasmImage
+	case "rust1.75":
+		return rustNativeImage(rustTargetArch(ctx))
+	default:
+		return ""
+	}
+}
+
+// imageDigest 返回本地镜像的 ID（内容摘要），镜像不存在时返回空字符串
+func imageDigest(ctx context.Context, image string) string {
+	if image == "" {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "docker", "image", "inspect", "--format", "{{.Id}}", image).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
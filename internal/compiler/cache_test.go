@@ -0,0 +1,81 @@
+package compiler
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCacheKey(t *testing.T) {
+	base := CacheKey("go1.24", goImage, "sha256:aaa", "package main", []string{"h1", "h2"}, nil)
+	if base != CacheKey("go1.24", goImage, "sha256:aaa", "package main", []string{"h1", "h2"}, nil) {
+		t.Fatalf("cache key is not deterministic")
+	}
+	for name, key := range map[string]string{
+		"image digest": CacheKey("go1.24", goImage, "sha256:bbb", "package main", []string{"h1", "h2"}, nil),
+		"code":         CacheKey("go1.24", goImage, "sha256:aaa", "package main // v2", []string{"h1", "h2"}, nil),
+		"layer hash":   CacheKey("go1.24", goImage, "sha256:aaa", "package main", []string{"h1", "h3"}, nil),
+		"layer order":  CacheKey("go1.24", goImage, "sha256:aaa", "package main", []string{"h2", "h1"}, nil),
+		"no layers":    CacheKey("go1.24", goImage, "sha256:aaa", "package main", nil, nil),
+		"runtime":      CacheKey("wasm", goImage, "sha256:aaa", "package main", []string{"h1", "h2"}, nil),
+		"build args":   CacheKey("go1.24", goImage, "sha256:aaa", "package main", []string{"h1", "h2"}, []string{"arg", "-trimpath"}),
+	} {
+		if key == base {
+			t.Errorf("changing %s did not change the cache key", name)
+		}
+	}
+}
+
+func TestCompileCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := newCompileCache(time.Hour, 10)
+	c.now = func() time.Time { return now }
+
+	c.put("go-a", "go1.24", "aaaa")
+	c.put("wasm-a", "wasm", "bbbb")
+	now = now.Add(time.Second)
+	if bin, ok := c.get("go-a"); !ok || bin != "aaaa" {
+		t.Fatalf("get go-a = %q, %v", bin, ok)
+	}
+
+	// 超过总大小上限时淘汰最久未使用的条目（wasm-a）
+	now = now.Add(time.Second)
+	c.put("go-b", "go1.24", "cccc")
+	if _, ok := c.get("wasm-a"); ok {
+		t.Fatalf("least recently used entry was not evicted")
+	}
+	if s := c.stats(); s.Entries != 2 || s.SizeBytes != 8 {
+		t.Fatalf("stats = %+v, want 2 entries / 8 bytes", s)
+	}
+
+	// 超过单个缓存上限的产物不缓存
+	c.put("huge", "go1.24", strings.Repeat("x", 11))
+	if _, ok := c.get("huge"); ok {
+		t.Fatalf("oversized binary was cached")
+	}
+
+	// 按运行时清除
+	c.put("wasm-b", "wasm", "dd")
+	if n := c.invalidate("go1.24"); n != 2 {
+		t.Fatalf("invalidate go1.24 removed %d entries, want 2", n)
+	}
+	if _, ok := c.get("wasm-b"); !ok {
+		t.Fatalf("invalidating go1.24 removed a wasm entry")
+	}
+
+	// 过期条目失效
+	now = now.Add(2 * time.Hour)
+	if _, ok := c.get("wasm-b"); ok {
+		t.Fatalf("expired entry was returned")
+	}
+	if s := c.stats(); s.Entries != 0 || s.SizeBytes != 0 {
+		t.Fatalf("stats after expiry = %+v, want empty", s)
+	}
+
+	// 禁用缓存时所有操作都是空操作
+	var disabled *compileCache
+	disabled.put("k", "go1.24", "a")
+	if _, ok := disabled.get("k"); ok || disabled.invalidate("") != 0 {
+		t.Fatalf("disabled cache returned entries")
+	}
+}
@@ -0,0 +1,238 @@
+package compiler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 诊断严重级别
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityNote    = "note"
+)
+
+// goVetMarker 分隔 Go 检查输出中 go build 和 go vet 两个阶段，之前的诊断为错误，之后的为警告
+const goVetMarker = "__nimbus_go_vet__"
+
+// Diagnostic 编译检查诊断信息，用于在编辑器中标注代码
+type Diagnostic struct {
+	File     string `json:"file"`             // 源文件名，如 main.go
+	Line     int    `json:"line"`             // 行号（从 1 开始）
+	Column   int    `json:"column,omitempty"` // 列号（从 1 开始），编译器未给出时为 0
+	Severity string `json:"severity"`         // error、warning 或 note
+	Code     string `json:"code,omitempty"`   // 编译器错误码，如 Rust 的 E0425
+	Message  string `json:"message"`          // 诊断信息
+}
+
+// CheckResponse 编译检查响应
+type CheckResponse struct {
+	Success     bool         `json:"success"`          // 是否没有错误级别的诊断（警告不影响）
+	Diagnostics []Diagnostic `json:"diagnostics"`      // 按编译器输出顺序排列的诊断
+	Error       string       `json:"error,omitempty"`  // 无法执行检查时的错误信息（如编译镜像不存在）
+	Output      string       `json:"output,omitempty"` // 编译器原始输出
+}
+
+// Check 只检查源代码（Go: go build 类型检查 + go vet；Rust: rustc --emit=metadata），
+// 不生成编译产物也不写入编译缓存，将编译器输出解析为结构化诊断。
+// 与编译共用按运行时的并发编译槽位。
+func (c *Compiler) Check(ctx context.Context, req *CompileRequest) (*CheckResponse, error) {
+	if !IsCompiledRuntime(req.Runtime) {
+		return &CheckResponse{
+			Success: false,
+			Error:   fmt.Sprintf("unsupported runtime for compilation: %s", req.Runtime),
+		}, nil
+	}
+
+	release, err := c.limiter.acquire(ctx, req.Runtime)
+	if err != nil {
+		return nil, fmt.Errorf("waiting for %s build slot: %w", req.Runtime, err)
+	}
+	defer release()
+
+	switch req.Runtime {
+	case "go1.24":
+		return c.checkGo(ctx, req)
+	default:
+		return c.checkRust(ctx, req)
+	}
+}
+
+// checkGo 以 go build -gcflags=-e 报告全部类型错误，通过后再运行 go vet 报告可疑代码
+func (c *Compiler) checkGo(ctx context.Context, req *CompileRequest) (*CheckResponse, error) {
+	if !imageExists(ctx, goImage) {
+		return &CheckResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Docker image %s not found locally. Please pull the image first: docker pull %s", goImage, goImage),
+		}, nil
+	}
+
+	tmpDir, err := os.MkdirTemp("/tmp", "nimbus-go-check-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(req.Code), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write source: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module handler\n\ngo 1.24\n"), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write go.mod: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	// 用户编译参数只传给 go build；产物写到 /dev/null
+	build := append([]string{"go", "build", "-gcflags=-e"}, req.BuildArgs...)
+	script := shellJoin(append(build, "-o", "/dev/null", ".")) + " && echo " + goVetMarker + " && go vet ."
+	args := append([]string{"-v", tmpDir + ":/work", "-w", "/work"}, buildEnvArgs(req.BuildEnv)...)
+	args = append(args, "-e", "CGO_ENABLED=0", "-e", "GOOS=linux", goImage, "sh", "-c", script)
+
+	output, err := buildContainerCommand(ctx, args...).CombinedOutput()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("check timed out or was cancelled: %w", ctx.Err())
+	}
+	return newCheckResponse(parseGoDiagnostics(string(output)), string(output), err), nil
+}
+
+// checkRust 以 rustc --emit=metadata 只做类型检查，不生成代码
+func (c *Compiler) checkRust(ctx context.Context, req *CompileRequest) (*CheckResponse, error) {
+	timeout := c.timeout
+	if req.Runtime == "wasm" && timeout < 5*time.Minute {
+		timeout = 5 * time.Minute
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	srcName := "main.rs"
+	image := rustNativeImage(rustTargetArch(ctx))
+	rustc := []string{"rustc", "--edition=2021"}
+	if req.Runtime == "wasm" {
+		srcName = "handler.rs"
+		image = rustWasmImage
+		rustc = append(rustc, "--target", "wasm32-unknown-unknown", "--crate-type=cdylib")
+	}
+	if !imageExists(ctx, image) {
+		return &CheckResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Docker image %s not found locally", image),
+		}, nil
+	}
+
+	tmpDir, err := os.MkdirTemp("/tmp", "nimbus-rust-check-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := os.WriteFile(filepath.Join(tmpDir, srcName), []byte(req.Code), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write source: %w", err)
+	}
+
+	args := append([]string{"-v", tmpDir + ":/work", "-w", "/work"}, buildEnvArgs(req.BuildEnv)...)
+	args = append(args, image)
+	args = append(args, rustc...)
+	args = append(args, req.BuildArgs...)
+	args = append(args, "--error-format=short", "--emit=metadata", srcName, "-o", "check.rmeta")
+
+	output, err := buildContainerCommand(ctx, args...).CombinedOutput()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("check timed out or was cancelled: %w", ctx.Err())
+	}
+	return newCheckResponse(parseRustDiagnostics(string(output)), string(output), err), nil
+}
+
+// newCheckResponse 根据诊断构造检查响应，没有错误级别的诊断时视为成功。
+// 检查命令失败却没有解析出任何诊断时（如编译容器无法启动、输出格式无法识别），视为检查失败。
+func newCheckResponse(diags []Diagnostic, output string, runErr error) *CheckResponse {
+	resp := &CheckResponse{Success: true, Diagnostics: diags, Output: output}
+	if resp.Diagnostics == nil {
+		resp.Diagnostics = []Diagnostic{}
+	}
+	if runErr != nil && len(diags) == 0 {
+		resp.Success = false
+		resp.Error = fmt.Sprintf("check failed: %v", runErr)
+		return resp
+	}
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			resp.Success = false
+			break
+		}
+	}
+	return resp
+}
+
+// shellJoin 将参数拼接为 sh -c 命令行，每个参数用单引号转义
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
+
+// goDiagnosticPattern 匹配 Go 工具链的诊断行，如 "./main.go:6:2: declared and not used: x"，
+// go vet 的类型检查错误带有 "vet: " 前缀
+var goDiagnosticPattern = regexp.MustCompile(`^(?:vet: )?(?:\./)?(\S+?\.go):(\d+)(?::(\d+))?: (.*)$`)
+
+// parseGoDiagnostics 解析 Go 检查的输出。goVetMarker 之前（go build）的诊断为错误，
+// 之后（go vet）的为警告；以制表符开头的行是上一条诊断的续行（如 have/want 说明）。
+func parseGoDiagnostics(output string) []Diagnostic {
+	var diags []Diagnostic
+	severity := SeverityError
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == goVetMarker {
+			severity = SeverityWarning
+			continue
+		}
+		if strings.HasPrefix(line, "\t") && len(diags) > 0 {
+			diags[len(diags)-1].Message += "\n" + strings.TrimSpace(line)
+			continue
+		}
+		m := goDiagnosticPattern.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		d := Diagnostic{File: m[1], Severity: severity, Message: m[4]}
+		d.Line, _ = strconv.Atoi(m[2])
+		d.Column, _ = strconv.Atoi(m[3])
+		// go vet 在类型检查失败时以 "vet: " 报告错误
+		if strings.HasPrefix(line, "vet: ") {
+			d.Severity = SeverityError
+		}
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+// rustDiagnosticPattern 匹配 rustc --error-format=short 的诊断行，
+// 如 "main.rs:3:5: error[E0425]: cannot find value `x` in this scope"
+var rustDiagnosticPattern = regexp.MustCompile(`^(\S+?\.rs):(\d+):(\d+): (error|warning|note|help)(?:\[(\w+)\])?: (.*)$`)
+
+// parseRustDiagnostics 解析 rustc 短格式输出。没有位置的汇总行（如 "error: aborting due to ..."）被忽略，
+// help 视为 note。
+func parseRustDiagnostics(output string) []Diagnostic {
+	var diags []Diagnostic
+	for _, line := range strings.Split(output, "\n") {
+		m := rustDiagnosticPattern.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		d := Diagnostic{File: m[1], Severity: m[4], Code: m[5], Message: m[6]}
+		if d.Severity == "help" {
+			d.Severity = SeverityNote
+		}
+		d.Line, _ = strconv.Atoi(m[2])
+		d.Column, _ = strconv.Atoi(m[3])
+		diags = append(diags, d)
+	}
+	return diags
+}
@@ -0,0 +1,90 @@
+package compiler
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseGoDiagnostics(t *testing.T) {
+	output := "# handler\n" +
+		"./main.go:6:2: declared and not used: x\n" +
+		"./main.go:9:14: cannot use s (variable of type string) as int value in argument to f\n" +
+		"\thave (string)\n" +
+		"\twant (int)\n" +
+		goVetMarker + "\n" +
+		"# handler\n" +
+		"./main.go:12:2: fmt.Printf format %d has arg s of wrong type string\n" +
+		"vet: main.go:15:3: undefined: y\n"
+
+	diags := parseGoDiagnostics(output)
+	if len(diags) != 4 {
+		t.Fatalf("got %d diagnostics, want 4: %+v", len(diags), diags)
+	}
+
+	want := []Diagnostic{
+		{File: "main.go", Line: 6, Column: 2, Severity: SeverityError, Message: "declared and not used: x"},
+		{File: "main.go", Line: 9, Column: 14, Severity: SeverityError, Message: "cannot use s (variable of type string) as int value in argument to f\nhave (string)\nwant (int)"},
+		{File: "main.go", Line: 12, Column: 2, Severity: SeverityWarning, Message: "fmt.Printf format %d has arg s of wrong type string"},
+		{File: "main.go", Line: 15, Column: 3, Severity: SeverityError, Message: "undefined: y"},
+	}
+	for i, w := range want {
+		if diags[i] != w {
+			t.Errorf("diagnostic %d = %+v, want %+v", i, diags[i], w)
+		}
+	}
+}
+
+func TestParseRustDiagnostics(t *testing.T) {
+	output := "main.rs:3:5: error[E0425]: cannot find value `x` in this scope\n" +
+		"main.rs:1:5: warning: unused import: `std::io`\n" +
+		"main.rs:3:5: help: a local variable with a similar name exists: `y`\n" +
+		"error: aborting due to 1 previous error; 1 warning emitted\n"
+
+	diags := parseRustDiagnostics(output)
+	want := []Diagnostic{
+		{File: "main.rs", Line: 3, Column: 5, Severity: SeverityError, Code: "E0425", Message: "cannot find value `x` in this scope"},
+		{File: "main.rs", Line: 1, Column: 5, Severity: SeverityWarning, Message: "unused import: `std::io`"},
+		{File: "main.rs", Line: 3, Column: 5, Severity: SeverityNote, Message: "a local variable with a similar name exists: `y`"},
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d: %+v", len(diags), len(want), diags)
+	}
+	for i, w := range want {
+		if diags[i] != w {
+			t.Errorf("diagnostic %d = %+v, want %+v", i, diags[i], w)
+		}
+	}
+}
+
+func TestNewCheckResponse(t *testing.T) {
+	warning := Diagnostic{File: "main.go", Line: 1, Severity: SeverityWarning, Message: "w"}
+	failure := Diagnostic{File: "main.go", Line: 2, Severity: SeverityError, Message: "e"}
+	runErr := errors.New("exit status 1")
+
+	tests := []struct {
+		name        string
+		diags       []Diagnostic
+		runErr      error
+		wantSuccess bool
+		wantError   bool
+	}{
+		{"clean", nil, nil, true, false},
+		{"warnings only", []Diagnostic{warning}, runErr, true, false},
+		{"errors", []Diagnostic{warning, failure}, runErr, false, false},
+		{"failed without diagnostics", nil, runErr, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := newCheckResponse(tt.diags, "", tt.runErr)
+			if resp.Success != tt.wantSuccess {
+				t.Errorf("Success = %v, want %v", resp.Success, tt.wantSuccess)
+			}
+			if (resp.Error != "") != tt.wantError {
+				t.Errorf("Error = %q, wantError %v", resp.Error, tt.wantError)
+			}
+			if resp.Diagnostics == nil {
+				t.Error("Diagnostics should never be nil")
+			}
+		})
+	}
+}
@@ -9,14 +9,40 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
+)
+
+// 编译镜像
+const (
+	goImage       = "golang:1.24-alpine"
+	rustWasmImage = "nimbus-rust-wasm-compiler:latest"
 )
 
 // CompileRequest 编译请求
 type CompileRequest struct {
-	Runtime string `json:"runtime"` // go1.24 或 wasm
-	Code    string `json:"code"`    // 源代码
+	Runtime   string            `json:"runtime"`              // go1.24 或 wasm
+	Code      string            `json:"code"`                 // 源代码
+	Layers    []string          `json:"layers,omitempty"`     // 函数挂载的层内容哈希（按加载顺序），参与编译缓存键
+	BuildEnv  map[string]string `json:"build_env,omitempty"`  // 注入编译容器的环境变量（调用方负责按白名单校验）
+	BuildArgs []string          `json:"build_args,omitempty"` // 追加到编译命令的参数（调用方负责按白名单校验）
+}
+
+// buildEnvArgs 将编译环境变量转换为按变量名排序的 docker run -e 参数
+func buildEnvArgs(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	args := make([]string, 0, 2*len(keys))
+	for _, k := range keys {
+		args = append(args, "-e", k+"="+env[k])
+	}
+	return args
 }
 
 // CompileResponse 编译响应
@@ -25,20 +51,40 @@ type CompileResponse struct {
 	Success bool   `json:"success"`          // 是否成功
 	Error   string `json:"error,omitempty"`  // 错误信息
 	Output  string `json:"output,omitempty"` // 编译输出
+	Cached  bool   `json:"cached,omitempty"` // 是否命中编译缓存
 }
 
 // Compiler 编译器服务
 type Compiler struct {
 	timeout time.Duration
+	limiter *buildLimiter // 按运行时的并发编译限制
+	cache   *compileCache // 编译产物缓存，nil 表示不缓存
 }
 
-// NewCompiler 创建编译器
+// NewCompiler 创建编译器，默认不限制并发编译数，并使用默认的编译缓存配置
 func NewCompiler() *Compiler {
 	return &Compiler{
 		timeout: 60 * time.Second,
+		limiter: newBuildLimiter(0, nil),
+		cache:   newCompileCache(defaultCacheTTL, defaultCacheMaxBytes),
 	}
 }
 
+// SetBuildLimits 设置按运行时的并发编译上限，超出上限的编译排队等待。
+// 需在开始编译前调用。
+//
+// 参数:
+//   - defaultLimit: 未单独配置的运行时的上限，<= 0 表示不限制
+//   - limits: 按运行时覆盖的上限
+func (c *Compiler) SetBuildLimits(defaultLimit int, limits map[string]int) {
+	c.limiter = newBuildLimiter(defaultLimit, limits)
+}
+
+// BuildStats 返回各运行时的并发编译情况（上限、正在编译数和排队数）
+func (c *Compiler) BuildStats() []BuildStats {
+	return c.limiter.stats()
+}
+
 // imageExists checks if a Docker image is available locally
 func imageExists(ctx context.Context, image string) bool {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -47,15 +93,57 @@ func imageExists(ctx context.Context, image string) bool {
 	return cmd.Run() == nil
 }
 
-// Compile 编译源代码
+// buildContainerCommand 构建以 docker run --rm 运行编译容器的命令（args 为 run 之后的参数）。
+// ctx 取消（编译超时或用户取消构建）时强制删除编译容器，而不只是结束 docker 客户端进程，
+// 避免编译容器在后台继续占用资源。
+func buildContainerCommand(ctx context.Context, args ...string) *exec.Cmd {
+	name := "nimbus-build-" + uuid.New().String()
+	cmd := exec.CommandContext(ctx, "docker", append([]string{"run", "--rm", "--name", name}, args...)...)
+	cmd.Cancel = func() error {
+		_ = exec.Command("docker", "rm", "-f", name).Run()
+		return cmd.Process.Kill()
+	}
+	return cmd
+}
+
+// Compile 编译源代码。
+// 运行时的并发编译数已达上限时排队等待槽位，等待期间 ctx 取消时返回错误。
 func (c *Compiler) Compile(ctx context.Context, req *CompileRequest) (*CompileResponse, error) {
+	if !IsCompiledRuntime(req.Runtime) {
+		return &CompileResponse{
+			Success: false,
+			Error:   fmt.Sprintf("unsupported runtime for compilation: %s", req.Runtime),
+		}, nil
+	}
+
+	// 相同源代码、编译镜像和层的编译结果直接复用，不占用编译槽位
+	key := c.cacheKey(ctx, req)
+	if binary, ok := c.cache.get(key); ok {
+		return &CompileResponse{Success: true, Binary: binary, Cached: true}, nil
+	}
+
+	release, err := c.limiter.acquire(ctx, req.Runtime)
+	if err != nil {
+		return nil, fmt.Errorf("waiting for %s build slot: %w", req.Runtime, err)
+	}
+	defer release()
+
+	resp, err := c.compile(ctx, req)
+	if err == nil && resp.Success {
+		c.cache.put(key, req.Runtime, resp.Binary)
+	}
+	return resp, err
+}
+
+// compile 按运行时调用对应的编译流程
+func (c *Compiler) compile(ctx context.Context, req *CompileRequest) (*CompileResponse, error) {
 	switch req.Runtime {
 	case "go1.24":
-		return c.compileGo(ctx, req.Code)
+		return c.compileGo(ctx, req)
 	case "wasm":
-		return c.compileRustWasm(ctx, req.Code)
+		return c.compileRustWasm(ctx, req)
 	case "rust1.75":
-		return c.compileRust(ctx, req.Code)
+		return c.compileRust(ctx, req)
 	default:
 		return &CompileResponse{
 			Success: false,
@@ -65,9 +153,8 @@ func (c *Compiler) Compile(ctx context.Context, req *CompileRequest) (*CompileRe
 }
 
 // compileGo 编译 Go 代码
-func (c *Compiler) compileGo(ctx context.Context, code string) (*CompileResponse, error) {
+func (c *Compiler) compileGo(ctx context.Context, req *CompileRequest) (*CompileResponse, error) {
 	// Check if the Docker image exists locally
-	const goImage = "golang:1.24-alpine"
 	if !imageExists(ctx, goImage) {
 		return &CompileResponse{
 			Success: false,
@@ -84,7 +171,7 @@ func (c *Compiler) compileGo(ctx context.Context, code string) (*CompileResponse
 
 	// 写入源代码
 	srcFile := filepath.Join(tmpDir, "main.go")
-	if err := os.WriteFile(srcFile, []byte(code), 0644); err != nil {
+	if err := os.WriteFile(srcFile, []byte(req.Code), 0644); err != nil {
 		return nil, fmt.Errorf("failed to write source: %w", err)
 	}
 
@@ -109,15 +196,17 @@ func (c *Compiler) compileGo(ctx context.Context, code string) (*CompileResponse
 	}
 
 	// 使用 Docker 编译 Go
-	cmd := exec.CommandContext(ctx, "docker", "run", "--rm",
-		"-v", tmpDir+":/work",
-		"-w", "/work",
+	// 用户编译环境变量在平台变量之前传入，GOOS/GOARCH/CGO_ENABLED 始终由平台决定
+	args := append([]string{"-v", tmpDir + ":/work", "-w", "/work"}, buildEnvArgs(req.BuildEnv)...)
+	args = append(args,
 		"-e", "CGO_ENABLED=0",
 		"-e", "GOOS=linux",
 		"-e", "GOARCH="+goarch,
 		goImage,
-		"go", "build", "-o", "handler", "main.go",
+		"go", "build",
 	)
+	args = append(args, req.BuildArgs...)
+	cmd := buildContainerCommand(ctx, append(args, "-o", "handler", "main.go")...)
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -143,9 +232,8 @@ func (c *Compiler) compileGo(ctx context.Context, code string) (*CompileResponse
 }
 
 // compileRustWasm 编译 Rust 代码到 WebAssembly
-func (c *Compiler) compileRustWasm(ctx context.Context, code string) (*CompileResponse, error) {
+func (c *Compiler) compileRustWasm(ctx context.Context, req *CompileRequest) (*CompileResponse, error) {
 	// Use pre-built image with wasm32-unknown-unknown target already installed
-	const rustWasmImage = "nimbus-rust-wasm-compiler:latest"
 	if !imageExists(ctx, rustWasmImage) {
 		return &CompileResponse{
 			Success: false,
@@ -162,7 +250,7 @@ func (c *Compiler) compileRustWasm(ctx context.Context, code string) (*CompileRe
 
 	// 写入源代码
 	srcFile := filepath.Join(tmpDir, "handler.rs")
-	if err := os.WriteFile(srcFile, []byte(code), 0644); err != nil {
+	if err := os.WriteFile(srcFile, []byte(req.Code), 0644); err != nil {
 		return nil, fmt.Errorf("failed to write source: %w", err)
 	}
 
@@ -178,14 +266,14 @@ func (c *Compiler) compileRustWasm(ctx context.Context, code string) (*CompileRe
 	defer cancel()
 
 	// 使用 Docker 编译 Rust - target is pre-installed in the image
-	cmd := exec.CommandContext(ctx, "docker", "run", "--rm",
-		"-v", tmpDir+":/work",
-		"-w", "/work",
+	args := append([]string{"-v", tmpDir + ":/work", "-w", "/work"}, buildEnvArgs(req.BuildEnv)...)
+	args = append(args,
 		rustWasmImage,
 		"rustc", "--edition=2021", "--target", "wasm32-unknown-unknown",
 		"-O", "-C", "panic=abort", "--crate-type=cdylib",
-		"handler.rs", "-o", "handler.wasm",
 	)
+	args = append(args, req.BuildArgs...)
+	cmd := buildContainerCommand(ctx, append(args, "handler.rs", "-o", "handler.wasm")...)
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -210,7 +298,7 @@ func (c *Compiler) compileRustWasm(ctx context.Context, code string) (*CompileRe
 }
 
 // compileRust 编译 Rust 代码到原生二进制
-func (c *Compiler) compileRust(ctx context.Context, code string) (*CompileResponse, error) {
+func (c *Compiler) compileRust(ctx context.Context, req *CompileRequest) (*CompileResponse, error) {
 	// 创建临时目录 - use /tmp to ensure Docker can access it on macOS
 	tmpDir, err := os.MkdirTemp("/tmp", "nimbus-rust-native-compile-")
 	if err != nil {
@@ -220,7 +308,7 @@ func (c *Compiler) compileRust(ctx context.Context, code string) (*CompileRespon
 
 	// 写入源代码
 	srcFile := filepath.Join(tmpDir, "main.rs")
-	if err := os.WriteFile(srcFile, []byte(code), 0644); err != nil {
+	if err := os.WriteFile(srcFile, []byte(req.Code), 0644); err != nil {
 		return nil, fmt.Errorf("failed to write source: %w", err)
 	}
 
@@ -232,19 +320,11 @@ func (c *Compiler) compileRust(ctx context.Context, code string) (*CompileRespon
 	defer cancel()
 
 	// 检测目标架构（Docker Server 的架构）
-	rustArch := "aarch64" // 默认 arm64 (Apple Silicon)
-	archCmd := exec.CommandContext(ctx, "docker", "version", "--format", "{{.Server.Arch}}")
-	if archOut, err := archCmd.Output(); err == nil {
-		arch := strings.TrimSpace(string(archOut))
-		if arch == "x86_64" || arch == "amd64" {
-			rustArch = "x86_64"
-		}
-	}
-
+	rustArch := rustTargetArch(ctx)
 	target := rustArch + "-unknown-linux-musl"
 
 	// Check if the Docker image exists locally to avoid long pull timeouts
-	rustImage := "messense/rust-musl-cross:" + rustArch + "-musl"
+	rustImage := rustNativeImage(rustArch)
 	if !imageExists(ctx, rustImage) {
 		return &CompileResponse{
 			Success: false,
@@ -253,12 +333,10 @@ func (c *Compiler) compileRust(ctx context.Context, code string) (*CompileRespon
 	}
 
 	// 使用 Docker 编译 Rust (musl 静态链接以便在 alpine 运行)
-	cmd := exec.CommandContext(ctx, "docker", "run", "--rm",
-		"-v", tmpDir+":/work",
-		"-w", "/work",
-		"messense/rust-musl-cross:"+rustArch+"-musl",
-		"rustc", "--target", target, "-C", "opt-level=3", "main.rs", "-o", "handler",
-	)
+	args := append([]string{"-v", tmpDir + ":/work", "-w", "/work"}, buildEnvArgs(req.BuildEnv)...)
+	args = append(args, rustImage, "rustc", "--target", target, "-C", "opt-level=3")
+	args = append(args, req.BuildArgs...)
+	cmd := buildContainerCommand(ctx, append(args, "main.rs", "-o", "handler")...)
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -282,6 +360,34 @@ func (c *Compiler) compileRust(ctx context.Context, code string) (*CompileRespon
 	}, nil
 }
 
+// rustTargetArch 返回 Docker Server 架构对应的 Rust 目标架构（x86_64 或 aarch64）
+func rustTargetArch(ctx context.Context) string {
+	rustArch := "aarch64" // 默认 arm64 (Apple Silicon)
+	archCmd := exec.CommandContext(ctx, "docker", "version", "--format", "{{.Server.Arch}}")
+	if archOut, err := archCmd.Output(); err == nil {
+		arch := strings.TrimSpace(string(archOut))
+		if arch == "x86_64" || arch == "amd64" {
+			rustArch = "x86_64"
+		}
+	}
+	return rustArch
+}
+
+// rustNativeImage 返回编译原生 Rust 二进制使用的 musl 交叉编译镜像
+func rustNativeImage(rustArch string) string {
+	return "messense/rust-musl-cross:" + rustArch + "-musl"
+}
+
+// IsCompiledRuntime 检查运行时是否需要将源代码编译后执行（go1.24、wasm、rust1.75）
+func IsCompiledRuntime(runtime string) bool {
+	switch runtime {
+	case "go1.24", "wasm", "rust1.75":
+		return true
+	default:
+		return false
+	}
+}
+
 // IsSourceCode 检测代码是否是源代码（而非 base64 二进制）
 func IsSourceCode(runtime, code string) bool {
 	switch runtime {
@@ -291,6 +397,9 @@ func IsSourceCode(runtime, code string) bool {
 	case "wasm", "rust1.75":
 		// Rust 源代码通常包含 fn 或 pub
 		return strings.Contains(code, "fn ") || strings.Contains(code, "#[no_mangle]") || strings.Contains(code, "pub ")
+	case "python3.11", "nodejs20", "bun1":
+		// 解释型运行时（Bun 原生转译 TypeScript），代码直接执行，无需编译
+		return false
 	default:
 		return false
 	}
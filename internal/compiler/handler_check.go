@@ -0,0 +1,190 @@
+package compiler
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// 入口检查模式
+const (
+	HandlerCheckStrict = "strict" // 检查不通过时部署失败
+	HandlerCheckWarn   = "warn"   // 检查不通过时只记录警告，部署继续
+	HandlerCheckOff    = "off"    // 不检查
+)
+
+// DefaultHandlerCheckModes 是各解释型运行时默认的入口检查模式。
+// Bun 支持 TypeScript、ES 模块默认导出等多种导出方式，静态检查容易误报，默认只警告。
+var DefaultHandlerCheckModes = map[string]string{
+	"python3.11": HandlerCheckStrict,
+	"nodejs20":   HandlerCheckStrict,
+	"bun1":       HandlerCheckWarn,
+}
+
+// ValidHandlerCheckMode 检查入口检查模式是否合法。
+func ValidHandlerCheckMode(mode string) bool {
+	return mode == HandlerCheckStrict || mode == HandlerCheckWarn || mode == HandlerCheckOff
+}
+
+// pythonHandlerArgs 是 Python 运行时调用入口函数时传入的位置参数个数：handler(event, context)
+const pythonHandlerArgs = 2
+
+// CheckHandler 对解释型运行时的函数代码做轻量静态检查，确认入口函数存在且签名合理，
+// 在部署时发现入口配置错误，而不是等到第一次调用才失败。
+// 编译型运行时和无法静态判断的写法（如由装饰器、赋值或导入得到的入口函数）不报错。
+//
+// 参数:
+//   - runtime: 运行时名称
+//   - code: 函数源代码
+//   - handler: 入口点，如 "handler"、"src/main.handler"
+//
+// 返回值:
+//   - error: 入口函数不存在或签名与运行时的调用方式不符时返回说明原因的错误
+func CheckHandler(runtime, code, handler string) error {
+	_, name := domain.ParseHandler(handler)
+	switch runtime {
+	case "python3.11":
+		return checkPythonHandler(code, name)
+	case "nodejs20", "bun1":
+		return checkJSHandler(code, name)
+	default:
+		return nil
+	}
+}
+
+// checkPythonHandler 查找 "def <name>(...)"，要求其能接受 (event, context) 两个位置参数。
+func checkPythonHandler(code, name string) error {
+	def := regexp.MustCompile(`(?m)^[ \t]*(?:async[ \t]+)?def[ \t]+` + regexp.QuoteMeta(name) + `[ \t]*\(`)
+	loc := def.FindStringIndex(code)
+	if loc == nil {
+		// 入口可能由赋值、导入或类定义得到，无法判断签名，只要名称被定义就放行
+		defined := regexp.MustCompile(`(?m)^(?:` + regexp.QuoteMeta(name) + `[ \t]*=|(?:from[ \t]+\S+[ \t]+)?import[ \t].*\b` + regexp.QuoteMeta(name) + `\b|class[ \t]+` + regexp.QuoteMeta(name) + `\b)`)
+		if defined.MatchString(code) {
+			return nil
+		}
+		return fmt.Errorf("handler function '%s' not found: expected \"def %s(event, context):\"", name, name)
+	}
+
+	// 装饰器可能改变函数签名，不检查参数
+	if hasDecorator(code[:loc[0]]) {
+		return nil
+	}
+	params, ok := splitParams(code[loc[1]:])
+	if !ok {
+		return nil
+	}
+	positional, required := 0, 0
+	for _, p := range params {
+		switch {
+		case p == "/":
+			continue
+		case p == "*" || strings.HasPrefix(p, "**"):
+			// 之后只有仅限关键字参数
+			return pythonArity(name, positional, required)
+		case strings.HasPrefix(p, "*"):
+			// *args 可以接收任意多个位置参数
+			positional = pythonHandlerArgs
+			return pythonArity(name, positional, required)
+		}
+		positional++
+		if !strings.Contains(p, "=") {
+			required++
+		}
+	}
+	return pythonArity(name, positional, required)
+}
+
+// hasDecorator 判断 def 之前的最后一个非空行是否为装饰器。
+func hasDecorator(before string) bool {
+	lines := strings.Split(before, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			return strings.HasPrefix(line, "@")
+		}
+	}
+	return false
+}
+
+// pythonArity 检查 Python 入口函数的位置参数个数能否接收 handler(event, context) 调用。
+func pythonArity(name string, positional, required int) error {
+	if positional < pythonHandlerArgs {
+		return fmt.Errorf("handler function '%s' accepts %d positional parameter(s), but the runtime calls %s(event, context)", name, positional, name)
+	}
+	if required > pythonHandlerArgs {
+		return fmt.Errorf("handler function '%s' requires %d parameters, but the runtime only passes (event, context)", name, required)
+	}
+	return nil
+}
+
+// splitParams 从参数列表的左括号之后开始，按顶层逗号拆分参数，直到匹配的右括号。
+// 参数中的括号、方括号、花括号（类型注解、默认值）和字符串按嵌套跳过。
+func splitParams(s string) ([]string, bool) {
+	var params []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"':
+			quote = c
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if c != ')' {
+				return nil, false
+			}
+			if p := strings.TrimSpace(s[start:i]); p != "" {
+				params = append(params, p)
+			}
+			return params, true
+		case ',':
+			if depth == 0 {
+				if p := strings.TrimSpace(s[start:i]); p != "" {
+					params = append(params, p)
+				}
+				start = i + 1
+			}
+		case '#':
+			// 跳过行内注释
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		}
+	}
+	return nil, false
+}
+
+// checkJSHandler 查找 JavaScript/TypeScript 中名为 name 的函数声明、变量声明或导出。
+// JavaScript 不校验实参个数，只检查入口是否存在。
+func checkJSHandler(code, name string) error {
+	n := regexp.QuoteMeta(name)
+	patterns := []string{
+		`\bfunction\s*\*?\s*` + n + `\s*\(`,      // function handler(...) / async function handler(...)
+		`\b(?:var|let|const)\s+` + n + `\s*[=:]`, // const handler = ... / let handler: Handler = ...
+		`\bexports\.` + n + `\s*=`,               // exports.handler = / module.exports.handler =
+		`\bexports\[\s*['"]` + n + `['"]\s*\]\s*=`,
+		`\bmodule\.exports\s*=\s*\{[^}]*\b` + n + `\b`, // module.exports = { handler }
+		`\bexport\s*\{[^}]*\b` + n + `\b`,              // export { handler }
+		`\bexport\s+default\b`,                         // 默认导出的对象可能包含入口函数
+	}
+	for _, p := range patterns {
+		if regexp.MustCompile(p).MatchString(code) {
+			return nil
+		}
+	}
+	return fmt.Errorf("handler function '%s' not found: expected a function named '%s' declared or exported by the code", name, name)
+}
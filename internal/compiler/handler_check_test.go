@@ -0,0 +1,37 @@
+package compiler
+
+import "testing"
+
+func TestCheckHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		runtime string
+		handler string
+		code    string
+		wantErr bool
+	}{
+		{"python ok", "python3.11", "handler", "def handler(event, context):\n    return event\n", false},
+		{"python async with annotations", "python3.11", "main.run", "async def run(event: dict[str, int], ctx=None) -> dict:\n    pass\n", false},
+		{"python varargs", "python3.11", "handler", "def handler(*args):\n    pass\n", false},
+		{"python default with parens", "python3.11", "handler", "def handler(event, context=dict(a=1), extra=(1, 2)):\n    pass\n", false},
+		{"python assigned", "python3.11", "handler", "from lib import make\nhandler = make()\n", false},
+		{"python decorated", "python3.11", "handler", "@wrap\ndef handler(event):\n    pass\n", false},
+		{"python missing", "python3.11", "handler", "def main(event, context):\n    pass\n", true},
+		{"python one param", "python3.11", "handler", "def handler(event):\n    pass\n", true},
+		{"python too many required", "python3.11", "handler", "def handler(event, context, db):\n    pass\n", true},
+		{"python keyword only", "python3.11", "handler", "def handler(event, *, context):\n    pass\n", true},
+		{"node exports", "nodejs20", "handler", "exports.handler = async (event) => event;\n", false},
+		{"node module exports object", "nodejs20", "index.main", "function run() {}\nmodule.exports = { main: run };\n", false},
+		{"node function declaration", "nodejs20", "handler", "async function handler(event, context) {}\n", false},
+		{"node missing", "nodejs20", "handler", "exports.main = () => 1;\n", true},
+		{"bun export default", "bun1", "handler", "export default { handler(e) { return e } }\n", false},
+		{"bun export const", "bun1", "handler", "export const handler = (e: unknown) => e;\n", false},
+		{"compiled runtime skipped", "go1.24", "handler", "package main\n", false},
+	}
+	for _, tt := range tests {
+		err := CheckHandler(tt.runtime, tt.code, tt.handler)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: CheckHandler() = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package compiler
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// BuildStats 描述单个运行时的并发编译情况
+type BuildStats struct {
+	Runtime string `json:"runtime"` // 运行时名称
+	Limit   int    `json:"limit"`   // 最大并发编译数，0 表示不限制
+	Running int    `json:"running"` // 正在编译的数量
+	Queued  int    `json:"queued"`  // 排队等待编译槽位的数量
+}
+
+// buildLimiter 按运行时限制并发编译数。
+// 每个运行时使用独立的信号量，重型运行时的编译排队不会占用其他运行时的槽位。
+type buildLimiter struct {
+	defaultLimit int            // 未单独配置的运行时的上限，<= 0 表示不限制
+	limits       map[string]int // 运行时 -> 上限
+
+	mu      sync.Mutex
+	slots   map[string]chan struct{} // 运行时 -> 信号量，首次编译时按上限创建
+	running map[string]int           // 运行时 -> 正在编译的数量
+	queued  map[string]int           // 运行时 -> 排队等待的数量
+}
+
+// newBuildLimiter 创建并发编译限制器。
+//
+// 参数:
+//   - defaultLimit: 未单独配置的运行时的上限，<= 0 表示不限制
+//   - limits: 按运行时覆盖的上限，值 <= 0 表示该运行时不限制
+func newBuildLimiter(defaultLimit int, limits map[string]int) *buildLimiter {
+	l := &buildLimiter{
+		defaultLimit: defaultLimit,
+		limits:       make(map[string]int, len(limits)),
+		slots:        make(map[string]chan struct{}),
+		running:      make(map[string]int),
+		queued:       make(map[string]int),
+	}
+	for runtime, limit := range limits {
+		l.limits[runtime] = limit
+	}
+	return l
+}
+
+// limit 返回运行时生效的并发上限，0 表示不限制。
+func (l *buildLimiter) limit(runtime string) int {
+	limit, ok := l.limits[runtime]
+	if !ok {
+		limit = l.defaultLimit
+	}
+	if limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+// acquire 为运行时占用一个编译槽位，槽位已满时阻塞等待，直到有槽位释放或 ctx 取消。
+//
+// 返回值:
+//   - func(): 释放槽位的函数
+//   - error: 等待期间 ctx 取消时返回 ctx.Err()
+func (l *buildLimiter) acquire(ctx context.Context, runtime string) (func(), error) {
+	l.mu.Lock()
+	sem, ok := l.slots[runtime]
+	if !ok {
+		if limit := l.limit(runtime); limit > 0 {
+			sem = make(chan struct{}, limit)
+		}
+		l.slots[runtime] = sem
+	}
+	l.queued[runtime]++
+	l.mu.Unlock()
+
+	if sem != nil {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			l.mu.Lock()
+			l.queued[runtime]--
+			l.mu.Unlock()
+			return nil, ctx.Err()
+		}
+	}
+
+	l.mu.Lock()
+	l.queued[runtime]--
+	l.running[runtime]++
+	l.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.running[runtime]--
+			l.mu.Unlock()
+			if sem != nil {
+				<-sem
+			}
+		})
+	}, nil
+}
+
+// stats 返回已配置上限或发生过编译的运行时的并发编译情况，按运行时名称排序。
+func (l *buildLimiter) stats() []BuildStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	runtimes := make(map[string]struct{}, len(l.limits)+len(l.slots))
+	for runtime := range l.limits {
+		runtimes[runtime] = struct{}{}
+	}
+	for runtime := range l.slots {
+		runtimes[runtime] = struct{}{}
+	}
+
+	stats := make([]BuildStats, 0, len(runtimes))
+	for runtime := range runtimes {
+		stats = append(stats, BuildStats{
+			Runtime: runtime,
+			Limit:   l.limit(runtime),
+			Running: l.running[runtime],
+			Queued:  l.queued[runtime],
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Runtime < stats[j].Runtime })
+	return stats
+}
@@ -0,0 +1,83 @@
+package compiler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBuildLimiter(t *testing.T) {
+	l := newBuildLimiter(1, map[string]int{"rust1.75": 2, "wasm": -1})
+
+	// rust1.75 最多 2 个并发编译，第 3 个排队等待
+	r1, err := l.acquire(context.Background(), "rust1.75")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.acquire(context.Background(), "rust1.75"); err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		release, err := l.acquire(context.Background(), "rust1.75")
+		if err == nil {
+			release()
+		}
+		close(acquired)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if s := statsFor(l, "rust1.75"); s.Queued == 1 && s.Running == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("rust1.75 stats = %+v, want 2 running / 1 queued", statsFor(l, "rust1.75"))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// rust1.75 排队不影响其他运行时
+	release, err := l.acquire(context.Background(), "go1.24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	release() // 重复释放无效
+
+	// 释放一个槽位后排队的编译继续执行
+	r1()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("queued build did not acquire a slot after release")
+	}
+
+	// 默认上限为 1：槽位被占用时等待可被取消
+	hold, _ := l.acquire(context.Background(), "go1.24")
+	defer hold()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(ctx, "go1.24"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want deadline exceeded", err)
+	}
+	if s := statsFor(l, "go1.24"); s.Queued != 0 || s.Running != 1 || s.Limit != 1 {
+		t.Errorf("go1.24 stats = %+v, want limit 1 / 1 running / 0 queued", s)
+	}
+
+	// 负数上限表示不限制
+	if s := statsFor(l, "wasm"); s.Limit != 0 {
+		t.Errorf("wasm limit = %d, want 0 (unlimited)", s.Limit)
+	}
+}
+
+func statsFor(l *buildLimiter, runtime string) BuildStats {
+	for _, s := range l.stats() {
+		if s.Runtime == runtime {
+			return s
+		}
+	}
+	return BuildStats{Runtime: runtime}
+}
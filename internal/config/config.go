@@ -4,6 +4,7 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
 	"strings"
 	"time"
@@ -44,8 +45,20 @@ type Config struct {
 	Workflow WorkflowConfig `yaml:"workflow"`
 	// Snapshot 函数级快照配置
 	Snapshot SnapshotConfig `yaml:"snapshot"`
+	// Build 源代码编译配置，包括按运行时的并发编译上限
+	Build BuildConfig `yaml:"build"`
+	// Layers 函数层配置，包括单个函数的层数和解压总大小上限
+	Layers LayersConfig `yaml:"layers"`
+	// Billing 计费单价配置，用于函数计费汇总中的费用计算
+	Billing BillingConfig `yaml:"billing"`
 	// State 有状态函数配置
 	State StateConfig `yaml:"state"`
+	// Outbound 出站通知（告警通知、回调等）的 HTTP 客户端配置
+	Outbound OutboundConfig `yaml:"outbound"`
+	// Admission 函数配置准入 Webhook，用于接入外部策略校验
+	Admission AdmissionConfig `yaml:"admission"`
+	// Environments 按环境的平台策略，键为环境名称（如 prod）
+	Environments map[string]EnvironmentPolicyConfig `yaml:"environments,omitempty"`
 }
 
 // RuntimeMode 运行时模式配置结构体。
@@ -54,6 +67,11 @@ type RuntimeMode struct {
 	// Mode 运行时模式，可选值为 "firecracker"（微虚拟机）或 "docker"（容器）
 	// 默认值：docker
 	Mode string `yaml:"mode"`
+	// SafeMode 安全模式，用于错误部署导致崩溃循环后的排查恢复：
+	// 不启动定时任务、不恢复未完成的工作流执行，Webhook 返回 503，管理 API 照常可用；
+	// 也可通过命令行参数 --safe-mode 开启
+	// 默认值：false
+	SafeMode bool `yaml:"safe_mode"`
 }
 
 // DockerConfig Docker 容器运行时配置结构体。
@@ -68,6 +86,28 @@ type DockerConfig struct {
 	Images map[string]string `yaml:"images,omitempty"`
 	// Pool Docker 容器池配置
 	Pool DockerPoolConfig `yaml:"pool"`
+	// DefaultEmptyResponse 函数成功执行但没有输出时返回的默认响应体（JSON 文本，如 "{}"）
+	// 默认值：空（返回空响应体），函数可通过 empty_response 单独覆盖
+	DefaultEmptyResponse string `yaml:"default_empty_response"`
+	// DataVolumes 共享数据卷注册表，键为数据卷名称，值为宿主机上的绝对路径
+	// 函数只能挂载此处注册的数据卷，挂载为只读，容器内路径为 /opt/data/<名称>
+	// 默认值：空（不允许挂载任何数据卷）
+	DataVolumes map[string]string `yaml:"data_volumes,omitempty"`
+	// CustomImages 允许函数使用的自定义镜像白名单（custom_image），以 * 结尾的项按前缀匹配，
+	// 如 "registry.example.com/team/*"，其余项要求与镜像引用完全相同
+	// 默认值：空（不允许任何自定义镜像）
+	CustomImages []string `yaml:"custom_images,omitempty"`
+	// LayerCacheMaxMB 层解压缓存目录（/tmp/nimbus-layers）的最大总大小（MB），
+	// 超出时按最近最少使用淘汰未被容器挂载的已解压层
+	// 默认值：2048，负数表示不限制
+	LayerCacheMaxMB int `yaml:"layer_cache_max_mb"`
+	// LayerCacheSweepInterval 层解压缓存淘汰检查的周期
+	// 默认值：5 分钟
+	LayerCacheSweepInterval time.Duration `yaml:"layer_cache_sweep_interval"`
+	// ArtifactsMaxMB 单次调用收集的产物文件（函数写入容器内 /output 目录的文件）的最大总大小（MB），
+	// 超出部分的文件被丢弃
+	// 默认值：10，负数表示不收集产物（不挂载 /output）
+	ArtifactsMaxMB int `yaml:"artifacts_max_mb"`
 }
 
 // DockerPoolConfig Docker 容器池配置结构体。
@@ -78,6 +118,16 @@ type DockerPoolConfig struct {
 	// MaxTotal 容器池中容器的最大总数
 	// 默认值：与 Scheduler.Workers 相同，如果未设置则为 10
 	MaxTotal int `yaml:"max_total"`
+	// RuntimeMaxTotal 按运行时限制容器总数（跨所有内存规格），防止单个运行时的突发流量占满容器配额
+	// 键为运行时名称（如 python3.11），未配置的运行时仅受 GlobalMaxTotal 限制
+	RuntimeMaxTotal map[string]int `yaml:"runtime_max_total,omitempty"`
+	// GlobalMaxTotal 所有运行时容器总数的上限
+	// 默认值：0（不限制）
+	GlobalMaxTotal int `yaml:"global_max_total"`
+	// QueueTimeoutSec 池已满时等待可用容器的最长时间（秒），超时后调用以 503 快速失败，
+	// 避免排队耗尽函数自身的执行超时
+	// 默认值：0（不单独限制，最多等待至函数超时）
+	QueueTimeoutSec int `yaml:"queue_timeout_sec"`
 	// MinWarm 最小预热容器数量，保持随时可用的容器数
 	// 默认值：0
 	MinWarm int `yaml:"min_warm"`
@@ -87,6 +137,12 @@ type DockerPoolConfig struct {
 	// MaxContainerAge 容器的最大存活时间，超过后将被回收
 	// 默认值：1 小时
 	MaxContainerAge time.Duration `yaml:"max_container_age"`
+	// WarmIdleTimeout 预热容器的最长空闲时间，空闲超过该时间的容器将被回收（与存活时间无关）
+	// 函数 keep_warm 固定常驻的容器不受影响
+	// 默认值：0（不按空闲时间回收）
+	WarmIdleTimeout time.Duration `yaml:"warm_idle_timeout"`
+	// RuntimeWarmIdleTimeout 按运行时覆盖 WarmIdleTimeout，键为运行时名称（如 python3.11）
+	RuntimeWarmIdleTimeout map[string]time.Duration `yaml:"runtime_warm_idle_timeout,omitempty"`
 	// TmpfsSizeMB 容器 tmpfs 挂载的大小（MB），用于临时文件存储
 	// 默认值：64 MB
 	TmpfsSizeMB int `yaml:"tmpfs_size_mb"`
@@ -111,6 +167,22 @@ type ServerConfig struct {
 	// ShutdownTimeout 优雅关闭超时时间
 	// 默认值：30 秒
 	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// ErrorStackTraces 是否在 API 错误响应中返回堆栈跟踪。堆栈会暴露服务端文件路径，
+	// 生产环境应保持关闭；关闭时堆栈只记录在服务端错误日志中
+	// 默认值：false
+	ErrorStackTraces bool `yaml:"error_stack_traces"`
+	// StackTraceDepth 堆栈跟踪（错误响应和错误日志）的最大帧数
+	// 默认值：32
+	StackTraceDepth int `yaml:"stack_trace_depth"`
+	// MaxPayloadBytes 调用请求体（同步、异步、Webhook、自定义路由）的大小上限（字节），
+	// 超出时返回 413；函数可通过 max_payload_bytes 单独设置
+	// 默认值：6MB
+	MaxPayloadBytes int64 `yaml:"max_payload_bytes"`
+	// CORSAllowedOrigins 允许跨域访问的来源列表（如 https://console.example.com），
+	// 同时用于函数 WebSocket 连接的 Origin 校验；为空时 CORS 允许所有来源，
+	// 函数 WebSocket 只接受同源页面和不带 Origin 的非浏览器客户端
+	// 默认值：空
+	CORSAllowedOrigins []string `yaml:"cors_allowed_origins"`
 }
 
 // AuthConfig 认证配置结构体。
@@ -168,6 +240,8 @@ type NetworkConfig struct {
 	UseNAT bool `yaml:"use_nat"`
 	// ExternalInterface 外部网络接口名称，用于 NAT 出口
 	ExternalInterface string `yaml:"external_interface"`
+	// HealthCheckInterval 网络健康检查间隔：回收孤立的 TAP 设备并检查网桥，默认 30 秒
+	HealthCheckInterval time.Duration `yaml:"health_check_interval"`
 }
 
 // PoolConfig 虚拟机/容器池配置结构体。
@@ -213,10 +287,13 @@ type RuntimeConfig struct {
 // SchedulerConfig 调度器配置结构体。
 // 定义了函数调度和执行相关的设置。
 type SchedulerConfig struct {
-	// Workers 工作线程数，决定并发执行函数的能力
+	// Workers 工作线程数，决定并发执行函数的能力，可通过管理接口在运行时调整
 	// 默认值：10
 	Workers int `yaml:"workers"`
-	// QueueSize 请求队列大小
+	// MaxWorkers 运行时扩容允许的最大工作线程数
+	// 默认值：100（若 Workers 更大则与 Workers 相同）
+	MaxWorkers int `yaml:"max_workers"`
+	// QueueSize 请求队列大小，每个调用优先级（high/normal/low）各一条此容量的队列
 	// 默认值：1000
 	QueueSize int `yaml:"queue_size"`
 	// DefaultTimeout 函数执行默认超时时间
@@ -225,6 +302,42 @@ type SchedulerConfig struct {
 	// MaxRetries 失败重试最大次数
 	// 默认值：3
 	MaxRetries int `yaml:"max_retries"`
+	// InitFailureThreshold 连续初始化失败多少次后将函数标记为 degraded
+	// 默认值：3
+	InitFailureThreshold int `yaml:"init_failure_threshold"`
+	// PlatformRetries 调用遇到瞬时平台故障（获取实例超时、容器启动失败等）时的最大重试次数，
+	// 函数代码抛出的异常从不重试；设为负数禁用
+	// 默认值：2
+	PlatformRetries int `yaml:"platform_retries"`
+	// PlatformRetryBackoff 平台故障首次重试前的等待时间，之后每次翻倍
+	// 默认值：100 毫秒
+	PlatformRetryBackoff time.Duration `yaml:"platform_retry_backoff"`
+	// PlatformRetryRate 整个调度器每秒允许的平台故障重试次数，避免故障期间重试放大负载
+	// 默认值：10
+	PlatformRetryRate float64 `yaml:"platform_retry_rate"`
+	// LeaderLeaseTTL 单例后台任务（延迟汇总、定时触发、工作流恢复）领导者租约的时长，
+	// 多副本部署时这些任务只在持有租约的实例上运行，领导者退出后最长经过此时长由其他实例接管
+	// 默认值：15 秒
+	LeaderLeaseTTL time.Duration `yaml:"leader_lease_ttl"`
+	// MaxCallDepth 函数嵌套调用链的最大深度（根调用为 1），超出时拒绝调用；设为负数禁用
+	// 默认值：16
+	MaxCallDepth int `yaml:"max_call_depth"`
+	// MaxFunctionRepeats 同一函数在一条调用链中最多出现的次数，用于拦截自调用和短循环；设为负数禁用
+	// 默认值：5
+	MaxFunctionRepeats int `yaml:"max_function_repeats"`
+	// ShadowWorkers 执行影子流量回放的后台工作协程数
+	// 默认值：4
+	ShadowWorkers int `yaml:"shadow_workers"`
+	// ShadowQueueSize 影子回放队列容量，队列已满时新的影子调用被丢弃，不阻塞真实调用
+	// 默认值：256
+	ShadowQueueSize int `yaml:"shadow_queue_size"`
+	// ShadowTimeout 影子调用的默认超时，函数的影子配置可通过 timeout_ms 单独指定
+	// 默认值：10 秒
+	ShadowTimeout time.Duration `yaml:"shadow_timeout"`
+	// ConcurrencyQueueTimeout 同步调用超出函数 max_concurrency 时等待槽位释放的最长时间，
+	// 为 0 时立即以 429 拒绝；异步调用始终等待
+	// 默认值：0
+	ConcurrencyQueueTimeout time.Duration `yaml:"concurrency_queue_timeout"`
 }
 
 // StorageConfig 存储配置结构体。
@@ -252,6 +365,9 @@ type PostgresConfig struct {
 	Password string `yaml:"password"`
 	// MaxConnections 最大连接数
 	MaxConnections int `yaml:"max_connections"`
+	// InvocationPayloadSearch 是否开启调用记录输入输出的子串搜索。
+	// 开启时为 input/output 创建三元组索引，占用额外的存储空间并增加写入开销，默认关闭
+	InvocationPayloadSearch bool `yaml:"invocation_payload_search"`
 }
 
 // RedisConfig Redis 缓存配置结构体。
@@ -289,6 +405,10 @@ type MetricsConfig struct {
 	Enabled bool `yaml:"enabled"`
 	// Namespace 指标命名空间前缀
 	Namespace string `yaml:"namespace"`
+	// LatencyRollupInterval 函数延迟分位数汇总任务的执行间隔，
+	// 汇总结果按小时写入 function_latency_rollups 表供统计接口快速读取；负数表示禁用汇总
+	// 默认值：5 分钟
+	LatencyRollupInterval time.Duration `yaml:"latency_rollup_interval"`
 }
 
 // TelemetryConfig 遥测配置结构体。
@@ -354,6 +474,104 @@ type SnapshotConfig struct {
 	MaxSnapshotsPerFunction int `yaml:"max_snapshots_per_function"`
 }
 
+// OutboundConfig 出站通知 HTTP 客户端配置结构体。
+// 平台向外部地址发送的所有通知共用该配置，失败时按指数退避重试，
+// 对连续失败的目标主机熔断一段时间。
+type OutboundConfig struct {
+	// ConnectTimeout 建立连接的超时时间
+	// 默认值：3s
+	ConnectTimeout time.Duration `yaml:"connect_timeout"`
+	// Timeout 单次请求的总超时时间
+	// 默认值：10s
+	Timeout time.Duration `yaml:"timeout"`
+	// MaxRetries 失败后的最大重试次数，负数表示不重试
+	// 默认值：3
+	MaxRetries int `yaml:"max_retries"`
+	// BaseBackoff 首次重试前的退避时间，之后每次翻倍并加入随机抖动
+	// 默认值：500ms
+	BaseBackoff time.Duration `yaml:"base_backoff"`
+	// MaxBackoff 单次退避时间上限
+	// 默认值：10s
+	MaxBackoff time.Duration `yaml:"max_backoff"`
+	// BreakerThreshold 同一目标主机连续失败多少次后熔断，负数表示不熔断
+	// 默认值：5
+	BreakerThreshold int `yaml:"breaker_threshold"`
+	// BreakerCooldown 熔断持续时间，之后放行一次试探请求
+	// 默认值：30s
+	BreakerCooldown time.Duration `yaml:"breaker_cooldown"`
+}
+
+// AdmissionConfig 函数配置准入 Webhook 配置结构体。
+// 配置后，创建和更新函数在持久化之前先将拟写入的配置 POST 到外部策略端点，
+// 由其决定放行或拒绝，平台团队无需修改代码即可实施治理策略。
+type AdmissionConfig struct {
+	// URL 策略端点地址（http/https），为空表示不启用
+	URL string `yaml:"url"`
+	// Timeout 单次校验请求的超时时间
+	// 默认值：5s
+	Timeout time.Duration `yaml:"timeout"`
+	// FailOpen 策略端点不可用（网络错误、超时、非 2xx 响应）时是否放行
+	// 默认值：false，即拒绝变更（fail-closed）
+	FailOpen bool `yaml:"fail_open"`
+}
+
+// EnvironmentPolicyConfig 环境策略配置结构体。
+// 平台团队据此控制各环境中可以部署和运行的运行时（如 prod 禁止实验性的 wasm）。
+type EnvironmentPolicyConfig struct {
+	// AllowedRuntimes 该环境允许部署和调用的运行时，为空表示不限制
+	AllowedRuntimes []string `yaml:"allowed_runtimes,omitempty"`
+	// BlockedRuntimes 该环境禁止的运行时，优先于 AllowedRuntimes
+	BlockedRuntimes []string `yaml:"blocked_runtimes,omitempty"`
+}
+
+// BuildConfig 源代码编译配置结构体。
+// 不同工具链的资源消耗差异很大（cargo build 远重于 go build），
+// 按运行时限制并发编译数，避免重型运行时的批量部署拖垮主机。
+type BuildConfig struct {
+	// MaxConcurrent 未单独配置的运行时的最大并发编译数，超出的编译排队等待；负数表示不限制
+	// 默认值：4
+	MaxConcurrent int `yaml:"max_concurrent"`
+	// RuntimeMaxConcurrent 按运行时覆盖 MaxConcurrent，键为运行时名称（如 rust1.75）
+	// 默认值：go1.24 为 8，rust1.75 和 wasm 为 2
+	RuntimeMaxConcurrent map[string]int `yaml:"runtime_max_concurrent,omitempty"`
+	// DebugMaxConcurrent 同时进行的调试容器编译数（Go、Rust 调试会话启动时在容器内编译），
+	// 与部署编译的槽位相互独立，超出的调试会话排队等待；负数表示不限制
+	// 默认值：2
+	DebugMaxConcurrent int `yaml:"debug_max_concurrent"`
+	// CacheTTL 编译缓存条目的有效期，负数表示禁用编译缓存
+	// 默认值：24h
+	CacheTTL time.Duration `yaml:"cache_ttl"`
+	// CacheMaxMB 编译缓存占用内存的上限（MB），超出时淘汰最久未使用的产物；负数表示禁用编译缓存
+	// 默认值：256
+	CacheMaxMB int `yaml:"cache_max_mb"`
+	// HandlerCheck 按运行时设置部署时的入口函数静态检查模式，键为运行时名称：
+	// strict（检查不通过时部署失败）、warn（只记录警告）、off（不检查）
+	// 默认值：python3.11 和 nodejs20 为 strict，bun1 为 warn
+	HandlerCheck map[string]string `yaml:"handler_check,omitempty"`
+}
+
+// LayersConfig 函数层配置结构体。
+// 层过多或解压后过大时函数会在执行时以难以排查的方式失败，设置函数层时按此处上限校验。
+type LayersConfig struct {
+	// MaxPerFunction 单个函数最多挂载的层数；负数表示不限制
+	// 默认值：5
+	MaxPerFunction int `yaml:"max_per_function"`
+	// MaxTotalUnpackedMB 单个函数挂载的所有层解压后的总大小上限（MB）；负数表示不限制
+	// 默认值：250
+	MaxTotalUnpackedMB int `yaml:"max_total_unpacked_mb"`
+}
+
+// BillingConfig 计费单价配置结构体。
+// 单价均为 0 时计费汇总只返回用量（调用次数、GB-秒），不计算费用。
+type BillingConfig struct {
+	// PricePerGBSecond 每 GB-秒的单价
+	PricePerGBSecond float64 `yaml:"price_per_gb_second"`
+	// PricePerRequest 每次调用的单价
+	PricePerRequest float64 `yaml:"price_per_request"`
+	// Currency 费用的货币单位（如 USD），仅用于展示
+	Currency string `yaml:"currency"`
+}
+
 // StateConfig 有状态函数配置结构体。
 // 用于配置函数状态管理功能。
 type StateConfig struct {
@@ -475,6 +693,18 @@ func (c *Config) applyDefaults() {
 	if c.Docker.Pool.MaxTotal <= 0 {
 		c.Docker.Pool.MaxTotal = 10
 	}
+	// 全局容器上限不能为负数
+	if c.Docker.Pool.GlobalMaxTotal < 0 {
+		c.Docker.Pool.GlobalMaxTotal = 0
+	}
+	// 无输出默认响应体必须为合法 JSON，否则忽略
+	if c.Docker.DefaultEmptyResponse != "" && c.Docker.DefaultEmptyResponse != "none" && !json.Valid([]byte(c.Docker.DefaultEmptyResponse)) {
+		c.Docker.DefaultEmptyResponse = ""
+	}
+	// 排队超时不能为负数
+	if c.Docker.Pool.QueueTimeoutSec < 0 {
+		c.Docker.Pool.QueueTimeoutSec = 0
+	}
 	// 最小预热数量不能为负数
 	if c.Docker.Pool.MinWarm < 0 {
 		c.Docker.Pool.MinWarm = 0
@@ -491,10 +721,36 @@ func (c *Config) applyDefaults() {
 	if c.Docker.Pool.MaxContainerAge == 0 {
 		c.Docker.Pool.MaxContainerAge = time.Hour
 	}
+	// 空闲回收时间不能为负数
+	if c.Docker.Pool.WarmIdleTimeout < 0 {
+		c.Docker.Pool.WarmIdleTimeout = 0
+	}
+	// 层解压缓存上限默认为 2048 MB，淘汰检查周期默认为 5 分钟
+	if c.Docker.LayerCacheMaxMB == 0 {
+		c.Docker.LayerCacheMaxMB = 2048
+	}
+	if c.Docker.LayerCacheSweepInterval <= 0 {
+		c.Docker.LayerCacheSweepInterval = 5 * time.Minute
+	}
+	// 调用产物总大小上限默认为 10 MB
+	if c.Docker.ArtifactsMaxMB == 0 {
+		c.Docker.ArtifactsMaxMB = 10
+	}
 	// tmpfs 大小默认为 64 MB
 	if c.Docker.Pool.TmpfsSizeMB == 0 {
 		c.Docker.Pool.TmpfsSizeMB = 64
 	}
+	// 计费单价不能为负数
+	if c.Billing.PricePerGBSecond < 0 {
+		c.Billing.PricePerGBSecond = 0
+	}
+	if c.Billing.PricePerRequest < 0 {
+		c.Billing.PricePerRequest = 0
+	}
+	// 网络健康检查间隔默认为 30 秒
+	if c.Network.HealthCheckInterval <= 0 {
+		c.Network.HealthCheckInterval = 30 * time.Second
+	}
 	// HTTP 端口默认为 8080
 	if c.Server.HTTPPort == 0 {
 		c.Server.HTTPPort = 8080
@@ -511,6 +767,13 @@ func (c *Config) applyDefaults() {
 	if c.Server.ShutdownTimeout == 0 {
 		c.Server.ShutdownTimeout = 30 * time.Second
 	}
+	if c.Server.StackTraceDepth == 0 {
+		c.Server.StackTraceDepth = 32
+	}
+	// 调用请求体大小上限默认为 6MB
+	if c.Server.MaxPayloadBytes == 0 {
+		c.Server.MaxPayloadBytes = 6 << 20
+	}
 	// Firecracker 启动超时默认为 10 秒
 	if c.Firecracker.BootTimeout == 0 {
 		c.Firecracker.BootTimeout = 10 * time.Second
@@ -519,6 +782,13 @@ func (c *Config) applyDefaults() {
 	if c.Scheduler.Workers == 0 {
 		c.Scheduler.Workers = 10
 	}
+	// 最大工作线程数默认为 100，且不小于 Workers
+	if c.Scheduler.MaxWorkers == 0 {
+		c.Scheduler.MaxWorkers = 100
+	}
+	if c.Scheduler.MaxWorkers < c.Scheduler.Workers {
+		c.Scheduler.MaxWorkers = c.Scheduler.Workers
+	}
 	// 调度器队列大小默认为 1000
 	if c.Scheduler.QueueSize == 0 {
 		c.Scheduler.QueueSize = 1000
@@ -531,6 +801,40 @@ func (c *Config) applyDefaults() {
 	if c.Scheduler.MaxRetries == 0 {
 		c.Scheduler.MaxRetries = 3
 	}
+	// 连续初始化失败阈值默认为 3
+	if c.Scheduler.InitFailureThreshold == 0 {
+		c.Scheduler.InitFailureThreshold = 3
+	}
+	// 平台故障默认重试 2 次，首次退避 100 毫秒，全局每秒最多重试 10 次
+	if c.Scheduler.PlatformRetries == 0 {
+		c.Scheduler.PlatformRetries = 2
+	}
+	if c.Scheduler.PlatformRetryBackoff == 0 {
+		c.Scheduler.PlatformRetryBackoff = 100 * time.Millisecond
+	}
+	if c.Scheduler.PlatformRetryRate == 0 {
+		c.Scheduler.PlatformRetryRate = 10
+	}
+	if c.Scheduler.LeaderLeaseTTL == 0 {
+		c.Scheduler.LeaderLeaseTTL = 15 * time.Second
+	}
+	// 调用链最大深度默认为 16，同一函数默认最多出现 5 次
+	if c.Scheduler.MaxCallDepth == 0 {
+		c.Scheduler.MaxCallDepth = 16
+	}
+	if c.Scheduler.MaxFunctionRepeats == 0 {
+		c.Scheduler.MaxFunctionRepeats = 5
+	}
+	// 影子回放默认 4 个工作协程、队列容量 256、超时 10 秒
+	if c.Scheduler.ShadowWorkers <= 0 {
+		c.Scheduler.ShadowWorkers = 4
+	}
+	if c.Scheduler.ShadowQueueSize <= 0 {
+		c.Scheduler.ShadowQueueSize = 256
+	}
+	if c.Scheduler.ShadowTimeout <= 0 {
+		c.Scheduler.ShadowTimeout = 10 * time.Second
+	}
 	// JWT 过期时间默认为 24 小时
 	if c.Auth.JWTExpiration == 0 {
 		c.Auth.JWTExpiration = 24 * time.Hour
@@ -551,6 +855,10 @@ func (c *Config) applyDefaults() {
 	if c.Telemetry.SampleRate == 0 {
 		c.Telemetry.SampleRate = 0.1
 	}
+	// 延迟分位数汇总默认每 5 分钟执行一次
+	if c.Metrics.LatencyRollupInterval == 0 {
+		c.Metrics.LatencyRollupInterval = 5 * time.Minute
+	}
 	// 环境标识默认为 development
 	if c.Telemetry.Environment == "" {
 		c.Telemetry.Environment = "development"
@@ -592,4 +900,58 @@ func (c *Config) applyDefaults() {
 	if c.Snapshot.MaxSnapshotsPerFunction == 0 {
 		c.Snapshot.MaxSnapshotsPerFunction = 3
 	}
+	// 并发编译数默认为每个运行时 4 个，Rust 工具链较重，默认只允许 2 个
+	if c.Build.MaxConcurrent == 0 {
+		c.Build.MaxConcurrent = 4
+	}
+	if c.Build.RuntimeMaxConcurrent == nil {
+		c.Build.RuntimeMaxConcurrent = map[string]int{
+			"go1.24":   8,
+			"rust1.75": 2,
+			"wasm":     2,
+		}
+	}
+	if c.Build.DebugMaxConcurrent == 0 {
+		c.Build.DebugMaxConcurrent = 2
+	}
+	// 编译缓存默认保留 24 小时，最多占用 256MB
+	if c.Build.CacheTTL == 0 {
+		c.Build.CacheTTL = 24 * time.Hour
+	}
+	if c.Build.CacheMaxMB == 0 {
+		c.Build.CacheMaxMB = 256
+	}
+	// 函数层默认最多 5 个，解压总大小不超过 250MB
+	if c.Layers.MaxPerFunction == 0 {
+		c.Layers.MaxPerFunction = 5
+	}
+	if c.Layers.MaxTotalUnpackedMB == 0 {
+		c.Layers.MaxTotalUnpackedMB = 250
+	}
+	// 出站通知默认连接超时 3 秒、总超时 10 秒，最多重试 3 次，连续失败 5 次熔断 30 秒
+	if c.Outbound.ConnectTimeout == 0 {
+		c.Outbound.ConnectTimeout = 3 * time.Second
+	}
+	if c.Outbound.Timeout == 0 {
+		c.Outbound.Timeout = 10 * time.Second
+	}
+	if c.Outbound.MaxRetries == 0 {
+		c.Outbound.MaxRetries = 3
+	}
+	if c.Outbound.BaseBackoff == 0 {
+		c.Outbound.BaseBackoff = 500 * time.Millisecond
+	}
+	if c.Outbound.MaxBackoff == 0 {
+		c.Outbound.MaxBackoff = 10 * time.Second
+	}
+	if c.Outbound.BreakerThreshold == 0 {
+		c.Outbound.BreakerThreshold = 5
+	}
+	if c.Outbound.BreakerCooldown == 0 {
+		c.Outbound.BreakerCooldown = 30 * time.Second
+	}
+	// 准入 Webhook 默认超时 5 秒
+	if c.Admission.Timeout == 0 {
+		c.Admission.Timeout = 5 * time.Second
+	}
 }
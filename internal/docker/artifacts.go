@@ -0,0 +1,172 @@
+package docker
+
+import (
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// artifactsDir 是调用产物目录的宿主机根目录，每个池化容器或一次性调用在其中使用独立的子目录，
+// 以读写方式挂载到容器内的 domain.ArtifactOutputDir
+const artifactsDir = "/tmp/nimbus-artifacts"
+
+// newArtifactDir 创建一个用于挂载到容器 /output 的宿主机目录。未启用产物收集时返回空字符串。
+// 目录权限为 0777，容器以非 root 用户运行时也能写入。
+func (m *Manager) newArtifactDir() (string, error) {
+	if m.artifactsMaxBytes <= 0 {
+		return "", nil
+	}
+	if err := os.MkdirAll(artifactsDir, 0755); err != nil {
+		return "", err
+	}
+	dir, err := os.MkdirTemp(artifactsDir, "out-")
+	if err != nil {
+		return "", err
+	}
+	if err := os.Chmod(dir, 0777); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// removeArtifactDir 删除产物目录，dir 为空时不做任何事。
+func removeArtifactDir(dir string) {
+	if dir != "" {
+		os.RemoveAll(dir)
+	}
+}
+
+// artifactMount 返回产物目录的 docker -v 挂载参数。
+func artifactMount(dir string) string {
+	return dir + ":" + domain.ArtifactOutputDir + ":rw"
+}
+
+// collectArtifacts 读取产物目录中的普通文件（包括子目录），按名称排序返回。
+// 符号链接和其他特殊文件被忽略；文件通过 os.Root 读取，即使容器内仍在运行的进程
+// 把路径替换为符号链接，也不会读取到目录之外的内容。
+// 文件数量超过 domain.MaxInvocationArtifacts 或总大小超过 maxBytes 时，超出的文件被丢弃。
+//
+// 返回值:
+//   - []domain.InvocationArtifact: 收集到的产物，包含文件内容
+//   - int: 被丢弃的文件数
+//   - error: 遍历目录失败时返回错误
+func collectArtifacts(dir string, maxBytes int64) ([]domain.InvocationArtifact, int, error) {
+	var names []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	sort.Strings(names)
+
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer root.Close()
+
+	var artifacts []domain.InvocationArtifact
+	var total int64
+	dropped := 0
+	for _, name := range names {
+		if !domain.ValidArtifactName(name) || len(artifacts) >= domain.MaxInvocationArtifacts {
+			dropped++
+			continue
+		}
+		data, ok := readArtifact(root, filepath.FromSlash(name), maxBytes-total)
+		if !ok {
+			dropped++
+			continue
+		}
+		total += int64(len(data))
+		artifacts = append(artifacts, domain.InvocationArtifact{
+			Name:        name,
+			Size:        int64(len(data)),
+			ContentType: artifactContentType(name, data),
+			Data:        data,
+		})
+	}
+	return artifacts, dropped, nil
+}
+
+// readArtifact 读取产物目录中的一个文件，文件大于 limit 字节、不是普通文件或读取失败时返回 false。
+func readArtifact(root *os.Root, name string, limit int64) ([]byte, bool) {
+	info, err := root.Lstat(name)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > limit {
+		return nil, false
+	}
+	f, err := root.Open(name)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil || int64(len(data)) > limit {
+		return nil, false
+	}
+	return data, true
+}
+
+// artifactContentType 按扩展名推断产物的 MIME 类型，无法识别时根据内容嗅探。
+func artifactContentType(name string, data []byte) string {
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		return ct
+	}
+	return http.DetectContentType(data)
+}
+
+// clearArtifactDir 删除产物目录中的所有内容，保留目录本身，供池化容器的下一次调用使用。
+func clearArtifactDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attachArtifacts 在函数成功执行后收集产物目录中的文件并附加到响应上。
+func (m *Manager) attachArtifacts(fn *domain.Function, dir string, resp *domain.InvokeResponse) {
+	if dir == "" || resp.StatusCode != 200 {
+		return
+	}
+	artifacts, dropped, err := collectArtifacts(dir, m.artifactsMaxBytes)
+	if err != nil {
+		m.logger.WithError(err).WithField("function_id", fn.ID).Warn("Failed to collect invocation artifacts")
+		return
+	}
+	if dropped > 0 {
+		m.logger.WithFields(logrus.Fields{
+			"function_id": fn.ID,
+			"collected":   len(artifacts),
+			"dropped":     dropped,
+		}).Warn("Some invocation artifacts were dropped (invalid name or size/count limit exceeded)")
+	}
+	resp.Artifacts = artifacts
+}
@@ -0,0 +1,52 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCollectArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, size int) {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("report.json", 10)
+	write("thumbs/a.png", 20)
+	write("thumbs/big.bin", 100)
+	outside := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(outside, []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	artifacts, dropped, err := collectArtifacts(dir, 50)
+	if err != nil {
+		t.Fatalf("collectArtifacts() = %v", err)
+	}
+	// 符号链接被忽略，big.bin 超出总大小上限被丢弃
+	if len(artifacts) != 2 || dropped != 1 {
+		t.Fatalf("collected %d, dropped %d; want 2 and 1", len(artifacts), dropped)
+	}
+	if artifacts[0].Name != "report.json" || artifacts[0].ContentType != "application/json" || artifacts[0].Size != 10 {
+		t.Errorf("artifacts[0] = %+v", artifacts[0])
+	}
+	if artifacts[1].Name != "thumbs/a.png" || artifacts[1].ContentType != "image/png" || len(artifacts[1].Data) != 20 {
+		t.Errorf("artifacts[1] = %+v", artifacts[1])
+	}
+
+	if err := clearArtifactDir(dir); err != nil {
+		t.Fatalf("clearArtifactDir() = %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("directory not empty after clear: %d entries", len(entries))
+	}
+}
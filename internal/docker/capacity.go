@@ -0,0 +1,79 @@
+package docker
+
+import "sync"
+
+// createBudget 跟踪跨容器池的容器配额占用。
+// 容器池按 "运行时:内存" 划分，但所有池共享宿主机资源；
+// 该结构在每个池自身的 MaxTotal 之外，额外施加按运行时和全局的上限，
+// 避免某个运行时的突发流量占满所有配额而饿死其他运行时。
+// 占用数包含已创建和正在创建中的容器。
+type createBudget struct {
+	mu         sync.Mutex
+	runtimeMax map[string]int // 运行时容器上限，未配置或 <=0 表示不限制
+	globalMax  int            // 全局容器上限，<=0 表示不限制
+	used       map[string]int // 各运行时当前占用数
+	total      int            // 全局当前占用数
+}
+
+// newCreateBudget 创建容器配额跟踪器。
+func newCreateBudget(runtimeMax map[string]int, globalMax int) *createBudget {
+	limits := make(map[string]int, len(runtimeMax))
+	for rt, n := range runtimeMax {
+		limits[rt] = n
+	}
+	return &createBudget{
+		runtimeMax: limits,
+		globalMax:  globalMax,
+		used:       make(map[string]int),
+	}
+}
+
+// tryAcquire 尝试为指定运行时占用一个容器配额。
+// 运行时上限和全局上限都未达到时返回 true。
+func (b *createBudget) tryAcquire(runtime string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if max := b.runtimeMax[runtime]; max > 0 && b.used[runtime] >= max {
+		return false
+	}
+	if b.globalMax > 0 && b.total >= b.globalMax {
+		return false
+	}
+	b.used[runtime]++
+	b.total++
+	return true
+}
+
+// release 归还指定运行时的一个容器配额。
+func (b *createBudget) release(runtime string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.used[runtime] > 0 {
+		b.used[runtime]--
+		b.total--
+	}
+}
+
+// reset 清空所有占用，在销毁全部容器后调用。
+func (b *createBudget) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.used = make(map[string]int)
+	b.total = 0
+}
+
+// utilization 返回运行时的占用数和生效的上限。
+// 运行时未配置上限时使用全局上限；两者都未配置时上限为 0。
+func (b *createBudget) utilization(runtime string) (used, limit int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	limit = b.runtimeMax[runtime]
+	if limit <= 0 {
+		limit = b.globalMax
+	}
+	return b.used[runtime], limit
+}
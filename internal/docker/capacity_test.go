@@ -0,0 +1,47 @@
+package docker
+
+import "testing"
+
+func TestCreateBudget(t *testing.T) {
+	b := newCreateBudget(map[string]int{"python3.11": 2}, 3)
+
+	if !b.tryAcquire("python3.11") || !b.tryAcquire("python3.11") {
+		t.Fatalf("expected first two python acquisitions to succeed")
+	}
+	if b.tryAcquire("python3.11") {
+		t.Fatalf("python acquisition beyond runtime cap succeeded")
+	}
+	if !b.tryAcquire("go1.24") {
+		t.Fatalf("go acquisition blocked by python burst")
+	}
+	if b.tryAcquire("go1.24") {
+		t.Fatalf("acquisition beyond global cap succeeded")
+	}
+
+	b.release("python3.11")
+	if !b.tryAcquire("go1.24") {
+		t.Fatalf("acquisition after release failed")
+	}
+
+	if used, limit := b.utilization("python3.11"); used != 1 || limit != 2 {
+		t.Fatalf("python utilization = %d/%d, want 1/2", used, limit)
+	}
+	if used, limit := b.utilization("go1.24"); used != 2 || limit != 3 {
+		t.Fatalf("go utilization = %d/%d, want 2/3", used, limit)
+	}
+
+	b.release("nodejs20") // 未占用的运行时不应使计数变为负数
+	b.reset()
+	if used, _ := b.utilization("go1.24"); used != 0 {
+		t.Fatalf("utilization after reset = %d, want 0", used)
+	}
+}
+
+func TestCreateBudgetUnlimited(t *testing.T) {
+	b := newCreateBudget(nil, 0)
+	for i := 0; i < 100; i++ {
+		if !b.tryAcquire("python3.11") {
+			t.Fatalf("unlimited budget rejected acquisition %d", i)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package docker
+
+import (
+	"fmt"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// CustomImageAllowed 检查镜像是否在部署配置 docker.custom_images 的白名单内。
+func (m *Manager) CustomImageAllowed(image string) bool {
+	return domain.CustomImageAllowed(image, m.customImages)
+}
+
+// functionImage 返回执行函数使用的镜像：设置了自定义镜像时为自定义镜像，否则为运行时镜像。
+// 自定义镜像在执行时再次检查白名单，运维方收紧白名单后已移出的镜像不再运行。
+func (m *Manager) functionImage(fn *domain.Function) (string, error) {
+	if fn.CustomImage != "" {
+		if !m.CustomImageAllowed(fn.CustomImage) {
+			return "", fmt.Errorf("%w: %s", domain.ErrCustomImageNotAllowed, fn.CustomImage)
+		}
+		return fn.CustomImage, nil
+	}
+	image, ok := m.images[string(fn.Runtime)]
+	if !ok {
+		return "", fmt.Errorf("unsupported runtime: %s", fn.Runtime)
+	}
+	return image, nil
+}
+
+// customImageArgs 返回自定义镜像函数的 docker run 参数，与 docker run 的语义一致：
+// 设置了 CustomCommand 时替换镜像的 ENTRYPOINT 和 CMD，Args 追加在命令之后；未设置时 Args 替换镜像的 CMD。
+// 选项使用 --name=value 形式，值不会被当作其他选项解析。
+//
+// 返回：
+//   - opts: 放在镜像之前的选项（--workdir、--entrypoint）
+//   - trailing: 放在镜像之后的命令其余部分和参数
+func customImageArgs(fn *domain.Function) (opts, trailing []string) {
+	if fn.CustomImage == "" {
+		return nil, nil
+	}
+	if fn.WorkingDir != "" {
+		opts = append(opts, "--workdir="+fn.WorkingDir)
+	}
+	if len(fn.CustomCommand) > 0 {
+		opts = append(opts, "--entrypoint="+fn.CustomCommand[0])
+		trailing = append(trailing, fn.CustomCommand[1:]...)
+	}
+	return opts, append(trailing, fn.Args...)
+}
@@ -0,0 +1,64 @@
+package docker
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+func TestFunctionImage(t *testing.T) {
+	m := &Manager{
+		images:       map[string]string{"python3.11": "function-runtime-python:latest"},
+		customImages: []string{"registry.example.com/team/*"},
+	}
+
+	if image, err := m.functionImage(&domain.Function{Runtime: "python3.11"}); err != nil || image != "function-runtime-python:latest" {
+		t.Errorf("runtime image = %q, %v", image, err)
+	}
+	fn := &domain.Function{Runtime: "python3.11", CustomImage: "registry.example.com/team/app:1.0"}
+	if image, err := m.functionImage(fn); err != nil || image != fn.CustomImage {
+		t.Errorf("custom image = %q, %v, want %q", image, err, fn.CustomImage)
+	}
+
+	// 白名单收紧后已移出的镜像在执行时被拒绝
+	fn.CustomImage = "docker.io/library/alpine:3"
+	if _, err := m.functionImage(fn); !errors.Is(err, domain.ErrCustomImageNotAllowed) {
+		t.Errorf("unlisted image error = %v, want ErrCustomImageNotAllowed", err)
+	}
+}
+
+func TestCustomImageArgs(t *testing.T) {
+	tests := []struct {
+		name         string
+		fn           domain.Function
+		wantOpts     []string
+		wantTrailing []string
+	}{
+		{
+			name: "runtime image ignores custom fields",
+			fn:   domain.Function{WorkingDir: "/srv", Args: []string{"-v"}},
+		},
+		{
+			name:         "args replace image CMD",
+			fn:           domain.Function{CustomImage: "app", WorkingDir: "/srv/app", Args: []string{"--port", "8080"}},
+			wantOpts:     []string{"--workdir=/srv/app"},
+			wantTrailing: []string{"--port", "8080"},
+		},
+		{
+			name:         "command replaces entrypoint and args follow it",
+			fn:           domain.Function{CustomImage: "app", CustomCommand: []string{"/bin/app", "serve"}, Args: []string{"--verbose"}},
+			wantOpts:     []string{"--entrypoint=/bin/app"},
+			wantTrailing: []string{"serve", "--verbose"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, trailing := customImageArgs(&tt.fn)
+			if !reflect.DeepEqual(opts, tt.wantOpts) || !reflect.DeepEqual(trailing, tt.wantTrailing) {
+				t.Errorf("customImageArgs() = %q, %q, want %q, %q", opts, trailing, tt.wantOpts, tt.wantTrailing)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// diagnosticsTimeout 诊断探针的最长执行时间
+const diagnosticsTimeout = 15 * time.Second
+
+// redactedValue 脱敏后的环境变量值
+const redactedValue = "******"
+
+// diagnosticProbe 内置诊断探针脚本，按 "##<段名>" 分段输出环境信息。
+// 仅依赖 busybox/coreutils 中的基础命令，所有运行时镜像都可执行。
+const diagnosticProbe = `echo "##env"; env
+echo "##memlimit"; cat /sys/fs/cgroup/memory.max 2>/dev/null || cat /sys/fs/cgroup/memory/memory.limit_in_bytes 2>/dev/null
+echo "##memtotal"; awk '/^MemTotal:/ {print $2}' /proc/meminfo 2>/dev/null
+echo "##cpus"; nproc 2>/dev/null || grep -c ^processor /proc/cpuinfo
+echo "##tmpfs"; df -k /tmp 2>/dev/null | awk 'NR==2 {print $2}'
+echo "##version"; %s
+`
+
+// runtimeVersionCmd 各运行时获取版本号的命令。
+// 编译型运行时（Go、Rust、WASM）执行的是预编译的 runtime 二进制，没有可查询的版本命令。
+var runtimeVersionCmd = map[string]string{
+	"python3.11": "python3 --version 2>&1",
+	"nodejs20":   "node --version 2>&1",
+	"bun1":       "bun --version 2>&1",
+}
+
+// safeEnvKeys 诊断输出中不脱敏的系统环境变量
+var safeEnvKeys = map[string]bool{
+	"PATH":       true,
+	"HOME":       true,
+	"HOSTNAME":   true,
+	"PWD":        true,
+	"LANG":       true,
+	"TZ":         true,
+	"PYTHONPATH": true,
+	"NODE_PATH":  true,
+}
+
+// Diagnose 在函数的执行容器中运行内置诊断探针，返回实际执行环境信息。
+// 与 Execute 使用相同的容器路径（池化模式使用 docker exec，否则或使用自定义镜像时使用一次性容器），
+// 因此结果反映的就是函数调用时所处的沙箱。
+// 参数：
+//   - ctx: 上下文
+//   - fn: 函数定义
+//   - layers: 函数层列表
+//
+// 返回：
+//   - *domain.FunctionDiagnostics: 诊断信息，环境变量值已脱敏
+//   - error: 探针执行失败时返回错误
+func (m *Manager) Diagnose(ctx context.Context, fn *domain.Function, layers []domain.RuntimeLayerInfo) (*domain.FunctionDiagnostics, error) {
+	image, err := m.functionImage(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	volumeMounts, layerEnvVars, releaseLayers, err := m.setupLayers(layers, string(fn.Runtime))
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup layers: %w", err)
+	}
+	defer releaseLayers()
+	volumes, err := m.resolveDataVolumes(fn.DataVolumes)
+	if err != nil {
+		return nil, err
+	}
+
+	// 与运行时一致：函数环境变量在前，层环境变量覆盖
+	env := make(map[string]string, len(fn.EnvVars)+len(layerEnvVars))
+	for k, v := range fn.EnvVars {
+		env[k] = v
+	}
+	for k, v := range layerEnvVars {
+		env[k] = v
+	}
+
+	script := fmt.Sprintf(diagnosticProbe, runtimeVersionCmd[string(fn.Runtime)])
+
+	probeCtx, cancel := context.WithTimeout(ctx, diagnosticsTimeout)
+	defer cancel()
+
+	var args []string
+	if m.poolCfg.Enabled && fn.CustomImage == "" {
+		pc, _, err := m.acquireContainer(probeCtx, string(fn.Runtime), fn.MemoryMB, volumes, image)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err := m.releaseContainer(context.Background(), pc, "", 0); err != nil {
+				m.logger.WithError(err).WithField("container_id", pc.ID).Warn("Failed to release docker container")
+			}
+		}()
+
+		args = []string{"exec"}
+		for k, v := range env {
+			args = append(args, "-e", fmt.Sprintf("%s=%s", k, v))
+		}
+		args = append(args, pc.ID, "/bin/sh", "-c", script)
+	} else {
+		dataMounts, err := m.dataVolumeMounts(volumes)
+		if err != nil {
+			return nil, err
+		}
+		args = m.oneOffRunArgs(fn.MemoryMB, append(volumeMounts, dataMounts...), env)
+		if fn.WorkingDir != "" {
+			args = append(args, "--workdir="+fn.WorkingDir)
+		}
+		args = append(args, "--entrypoint", "/bin/sh", image, "-c", script)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(probeCtx, "docker", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("diagnostic probe failed: %w: %s", err, truncateForError(stderr.Bytes(), 512))
+	}
+
+	diag := parseDiagnostics(stdout.Bytes())
+	// 池化容器中 /opt/layers 是所有函数共享的层缓存，不能直接列目录，只报告函数自身挂载的层
+	diag.Layers = attachedLayerIDs(layers)
+	diag.FunctionID = fn.ID
+	diag.Runtime = fn.Runtime
+	diag.Image = image
+	diag.NetworkMode = m.networkMode
+	diag.Pooled = m.poolCfg.Enabled
+	diag.CollectedAt = time.Now()
+	return diag, nil
+}
+
+// parseDiagnostics 解析诊断探针的分段输出。
+func parseDiagnostics(out []byte) *domain.FunctionDiagnostics {
+	diag := &domain.FunctionDiagnostics{
+		EnvVars: map[string]string{},
+	}
+
+	section := ""
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, "##") {
+			section = strings.TrimPrefix(line, "##")
+			continue
+		}
+		value := strings.TrimSpace(line)
+		if value == "" {
+			continue
+		}
+
+		switch section {
+		case "env":
+			key, val, ok := strings.Cut(line, "=")
+			if !ok {
+				continue
+			}
+			diag.EnvVars[key] = redactEnvValue(key, val)
+		case "memlimit":
+			// cgroup v2 未限制时输出 "max"，v1 未限制时为一个极大值
+			if n, err := strconv.ParseInt(value, 10, 64); err == nil && n < 1<<50 {
+				diag.MemoryLimitMB = n / (1024 * 1024)
+			}
+		case "memtotal":
+			if kb, err := strconv.ParseInt(value, 10, 64); err == nil {
+				diag.HostMemoryMB = kb / 1024
+			}
+		case "cpus":
+			if n, err := strconv.Atoi(value); err == nil {
+				diag.CPUCount = n
+			}
+		case "tmpfs":
+			if kb, err := strconv.ParseInt(value, 10, 64); err == nil {
+				diag.TmpfsSizeMB = kb / 1024
+			}
+		case "version":
+			if diag.RuntimeVersion == "" {
+				diag.RuntimeVersion = value
+			}
+		}
+	}
+
+	return diag
+}
+
+// attachedLayerIDs 返回函数挂载的层 ID，按加载顺序排列。
+func attachedLayerIDs(layers []domain.RuntimeLayerInfo) []string {
+	sorted := make([]domain.RuntimeLayerInfo, len(layers))
+	copy(sorted, layers)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Order < sorted[j].Order })
+
+	ids := make([]string, 0, len(sorted))
+	for _, layer := range sorted {
+		ids = append(ids, layer.LayerID)
+	}
+	return ids
+}
+
+// redactEnvValue 对环境变量值脱敏，仅保留已知的非敏感系统变量。
+func redactEnvValue(key, value string) string {
+	if safeEnvKeys[key] || value == "" {
+		return value
+	}
+	return redactedValue
+}
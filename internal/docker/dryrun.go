@@ -0,0 +1,61 @@
+package docker
+
+import (
+	"sort"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// PlanAcquire 预演 acquireContainer 对一次调用的分配决策，但不取出预热容器也不创建新容器。
+// 容器配额通过 tryAcquire 判断后立即归还，与实际获取使用相同的上限检查。
+// 池尚不存在时视为空池，不会因为预演而创建池。
+//
+// 参数:
+//   - runtime: 运行时
+//   - memoryMB: 内存规格（单位：MB）
+//   - dataVolumes: 函数挂载的共享数据卷名称
+//
+// 返回值:
+//   - *domain.PoolPlan: 分配决策及池的当前状态
+func (m *Manager) PlanAcquire(runtime string, memoryMB int, dataVolumes []string) *domain.PoolPlan {
+	volumes, err := m.resolveDataVolumes(dataVolumes)
+	if err != nil {
+		// 未注册的数据卷会在实际执行时失败，预演仍按请求的数据卷定位容器池
+		volumes = append([]string(nil), dataVolumes...)
+		sort.Strings(volumes)
+	}
+	key := poolKey(runtime, memoryMB, volumes)
+	plan := &domain.PoolPlan{
+		Pool:            key,
+		MaxInstances:    m.poolCfg.MaxTotal,
+		QueueTimeoutSec: m.poolCfg.QueueTimeoutSec,
+	}
+
+	m.mu.RLock()
+	pool := m.pools[key]
+	m.mu.RUnlock()
+
+	total := 0
+	if pool != nil {
+		plan.WarmInstances = len(pool.warm)
+		pool.mu.Lock()
+		total = len(pool.all)
+		plan.CreatingInstances = pool.creating
+		pool.mu.Unlock()
+		plan.BusyInstances = total - plan.WarmInstances
+		if plan.BusyInstances < 0 {
+			plan.BusyInstances = 0
+		}
+	}
+
+	switch {
+	case plan.WarmInstances > 0:
+		plan.Decision = domain.PoolDecisionWarm
+	case total+plan.CreatingInstances < m.poolCfg.MaxTotal && m.budget.tryAcquire(runtime):
+		m.budget.release(runtime)
+		plan.Decision = domain.PoolDecisionCold
+	default:
+		plan.Decision = domain.PoolDecisionQueue
+	}
+	return plan
+}
@@ -0,0 +1,90 @@
+package docker
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// warmIdleTimeout 返回运行时预热容器的空闲回收时间，0 表示不按空闲时间回收。
+func (m *Manager) warmIdleTimeout(runtime string) time.Duration {
+	if timeout, ok := m.poolCfg.RuntimeWarmIdleTimeout[runtime]; ok && timeout >= 0 {
+		return timeout
+	}
+	return m.poolCfg.WarmIdleTimeout
+}
+
+// ReapIdle 回收所有容器池中空闲超过 WarmIdleTimeout 的预热容器。
+// keep_warm 固定常驻的容器数量会被保留，不受空闲回收影响。
+//
+// 参数:
+//   - ctx: 上下文，用于控制容器删除
+//
+// 返回值:
+//   - int: 本次回收的容器数量
+func (m *Manager) ReapIdle(ctx context.Context) int {
+	if !m.poolCfg.Enabled {
+		return 0
+	}
+
+	m.mu.RLock()
+	pools := make([]*containerPool, 0, len(m.pools))
+	for _, pool := range m.pools {
+		pools = append(pools, pool)
+	}
+	m.mu.RUnlock()
+
+	total := 0
+	for _, pool := range pools {
+		timeout := m.warmIdleTimeout(pool.runtime)
+		if timeout <= 0 {
+			continue
+		}
+		if n := m.reapIdleWarm(ctx, pool, timeout); n > 0 {
+			total += n
+			m.logger.WithFields(logrus.Fields{
+				"runtime":   pool.runtime,
+				"memory_mb": pool.memoryMB,
+				"reaped":    n,
+			}).Debug("Reaped idle warm containers")
+			if m.metrics != nil {
+				m.metrics.RecordPoolIdleReaped(pool.runtime, n)
+			}
+			m.updatePoolMetrics(pool.runtime)
+		}
+	}
+	return total
+}
+
+// reapIdleWarm 检查预热队列中的空闲容器，销毁空闲超过 timeout 的容器，其余放回队列。
+// 队列中至少保留 pool.pinned 个容器。
+func (m *Manager) reapIdleWarm(ctx context.Context, pool *containerPool, timeout time.Duration) int {
+	pool.mu.Lock()
+	pinned := pool.pinned
+	pool.mu.Unlock()
+
+	reapable := len(pool.warm) - pinned
+	reaped := 0
+	for n := len(pool.warm); n > 0 && reaped < reapable; n-- {
+		var pc *pooledContainer
+		select {
+		case pc = <-pool.warm:
+		default:
+			return reaped
+		}
+
+		if time.Since(pc.LastUsed) <= timeout {
+			select {
+			case pool.warm <- pc:
+				continue
+			default:
+			}
+		} else {
+			reaped++
+		}
+		m.removeContainer(pool, pc)
+		_ = m.retireContainer(ctx, pc)
+	}
+	return reaped
+}
@@ -0,0 +1,252 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// imageExists 通过 docker image inspect 检查镜像是否存在于本地。
+// 变量形式便于测试替换。
+var imageExists = func(ctx context.Context, image string) bool {
+	return exec.CommandContext(ctx, "docker", "image", "inspect", image).Run() == nil
+}
+
+// CheckImages 检查所有已配置运行时的镜像是否存在于本地，并更新缺失镜像列表。
+// 镜像缺失不会阻止启动，只记录警告：其余运行时仍可正常调用。
+//
+// 参数:
+//   - ctx: 上下文，用于控制 docker 命令
+//
+// 返回值:
+//   - map[string]string: 缺失镜像的运行时到镜像名称的映射，全部存在时为空
+func (m *Manager) CheckImages(ctx context.Context) map[string]string {
+	// 多个运行时可能共用同一镜像，每个镜像只检查一次
+	present := make(map[string]bool)
+	missing := make(map[string]string)
+	for runtime, image := range m.images {
+		ok, checked := present[image]
+		if !checked {
+			ok = imageExists(ctx, image)
+			present[image] = ok
+		}
+		if !ok {
+			missing[runtime] = image
+		}
+	}
+
+	m.mu.Lock()
+	previous := m.missing
+	m.missing = missing
+	m.mu.Unlock()
+
+	for runtime, image := range missing {
+		if _, known := previous[runtime]; !known {
+			m.logger.WithFields(logrus.Fields{
+				"runtime": runtime,
+				"image":   image,
+			}).Warn("Runtime image not found locally; invocations of this runtime will fail until it is built or pulled")
+		}
+	}
+	for runtime, image := range previous {
+		if _, still := missing[runtime]; !still {
+			m.logger.WithFields(logrus.Fields{
+				"runtime": runtime,
+				"image":   image,
+			}).Info("Runtime image is now available")
+		}
+	}
+	return copyImages(missing)
+}
+
+// MissingImages 返回最近一次 CheckImages 发现的缺失镜像（运行时到镜像名称的映射）。
+func (m *Manager) MissingImages() map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return copyImages(m.missing)
+}
+
+// ==================== 镜像构建 ====================
+
+// DefaultImageBuildTimeout 是单个镜像构建的默认超时时间
+const DefaultImageBuildTimeout = 15 * time.Minute
+
+// MaxImageBuildConcurrency 是并行构建镜像数的上限
+const MaxImageBuildConcurrency = 8
+
+// maxImageBuildOutput 是构建失败时保留的输出末尾字节数
+const maxImageBuildOutput = 4096
+
+// buildImage 执行 docker build 并返回合并的输出。
+// 变量形式便于测试替换。
+var buildImage = func(ctx context.Context, image, dockerfile, contextDir string) ([]byte, error) {
+	return exec.CommandContext(ctx, "docker", "build", "-t", image, "-f", dockerfile, contextDir).CombinedOutput()
+}
+
+// ImageBuildOptions 是 BuildImages 的构建选项。
+type ImageBuildOptions struct {
+	// Timeout 是单个镜像的构建超时，<= 0 时使用 DefaultImageBuildTimeout
+	Timeout time.Duration
+	// Concurrency 是并行构建的镜像数，<= 0 时为 1（串行），最大 MaxImageBuildConcurrency
+	Concurrency int
+	// Runtimes 只构建指定运行时的镜像，为空表示构建所有已配置的运行时
+	Runtimes []string
+}
+
+// ImageBuildResult 是单个运行时镜像的构建结果。
+type ImageBuildResult struct {
+	// Runtime 是运行时名称
+	Runtime string `json:"runtime"`
+	// Image 是镜像名称
+	Image string `json:"image"`
+	// OK 表示构建成功
+	OK bool `json:"ok"`
+	// Error 是失败原因，成功时为空
+	Error string `json:"error,omitempty"`
+	// Output 是构建失败时 docker build 输出的末尾部分
+	Output string `json:"output,omitempty"`
+	// Duration 是构建耗时
+	Duration time.Duration `json:"duration"`
+}
+
+// BuildImages 构建运行时的 Docker 镜像。
+// 每个镜像的构建有独立的超时，单个镜像失败不会中断其余镜像的构建，结果逐个运行时返回。
+// 多个运行时共用同一镜像时只构建一次（使用名称排序后第一个运行时的 Dockerfile），结果对这些运行时相同。
+//
+// 参数：
+//   - ctx: 上下文，取消时停止所有构建
+//   - dockerfilesDir: 包含 Dockerfile 的目录，期望的命名格式为 Dockerfile.<runtime>，如 Dockerfile.python3.11
+//   - opts: 构建选项
+//
+// 返回值：
+//   - []ImageBuildResult: 按运行时名称排序的构建结果
+//   - error: 指定了未配置的运行时时返回错误，此时不构建任何镜像；构建失败不返回错误，见结果中的 OK
+func (m *Manager) BuildImages(ctx context.Context, dockerfilesDir string, opts ImageBuildOptions) ([]ImageBuildResult, error) {
+	runtimes := opts.Runtimes
+	if len(runtimes) == 0 {
+		for runtime := range m.images {
+			runtimes = append(runtimes, runtime)
+		}
+	}
+	var unknown []string
+	for _, runtime := range runtimes {
+		if _, ok := m.images[runtime]; !ok {
+			unknown = append(unknown, runtime)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown runtimes: %s", strings.Join(unknown, ", "))
+	}
+	sort.Strings(runtimes)
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultImageBuildTimeout
+	}
+	concurrency := min(max(opts.Concurrency, 1), MaxImageBuildConcurrency)
+
+	// 按镜像分组，每个镜像只构建一次
+	var images []string
+	byImage := make(map[string][]string)
+	for _, runtime := range runtimes {
+		image := m.images[runtime]
+		if _, seen := byImage[image]; !seen {
+			images = append(images, image)
+		}
+		byImage[image] = append(byImage[image], runtime)
+	}
+
+	built := make(map[string]ImageBuildResult, len(images))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	for _, image := range images {
+		wg.Add(1)
+		go func(image, runtime string) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				mu.Lock()
+				built[image] = ImageBuildResult{Image: image, Error: ctx.Err().Error()}
+				mu.Unlock()
+				return
+			}
+			defer func() { <-slots }()
+
+			res := m.buildOneImage(ctx, image, runtime, dockerfilesDir, timeout)
+			mu.Lock()
+			built[image] = res
+			mu.Unlock()
+		}(image, byImage[image][0])
+	}
+	wg.Wait()
+
+	results := make([]ImageBuildResult, 0, len(runtimes))
+	for _, runtime := range runtimes {
+		res := built[m.images[runtime]]
+		res.Runtime = runtime
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// buildOneImage 以 runtime 的 Dockerfile 构建单个镜像，超过 timeout 时终止构建。
+func (m *Manager) buildOneImage(ctx context.Context, image, runtime, dockerfilesDir string, timeout time.Duration) ImageBuildResult {
+	dockerfile := filepath.Join(dockerfilesDir, "Dockerfile."+runtime)
+	logger := m.logger.WithFields(logrus.Fields{"image": image, "dockerfile": dockerfile})
+	logger.Info("Building runtime image")
+
+	buildCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	output, err := buildImage(buildCtx, image, dockerfile, dockerfilesDir)
+	res := ImageBuildResult{Image: image, Duration: time.Since(start)}
+	if err != nil {
+		if errors.Is(buildCtx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("build timed out after %s", timeout)
+		}
+		res.Error = err.Error()
+		res.Output = tailOutput(output, maxImageBuildOutput)
+		logger.WithError(err).WithField("duration", res.Duration).Error("Failed to build runtime image")
+		return res
+	}
+	res.OK = true
+	logger.WithField("duration", res.Duration).Info("Built runtime image")
+	return res
+}
+
+// tailOutput 返回输出末尾最多 max 字节，构建失败的原因通常在最后。
+func tailOutput(b []byte, max int) string {
+	if len(b) > max {
+		b = b[len(b)-max:]
+	}
+	return string(b)
+}
+
+// RuntimeCount 返回已配置的运行时数量。
+func (m *Manager) RuntimeCount() int {
+	return len(m.images)
+}
+
+// RuntimeImages 返回各运行时配置的镜像（运行时到镜像名称的映射）。
+func (m *Manager) RuntimeImages() map[string]string {
+	return copyImages(m.images)
+}
+
+// copyImages 复制镜像映射，避免调用方修改内部状态。
+func copyImages(src map[string]string) map[string]string {
+	dst := make(map[string]string, len(src))
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
@@ -0,0 +1,274 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// SetKeepWarm 设置各运行时/内存规格的常驻预热目标，并立即协调容器池：
+// 回收已超过存活时间或复用次数上限的空闲容器，再补齐到目标数量。
+// 常驻容器不挂载共享数据卷，挂载了数据卷的函数使用独立的容器池，不受常驻预热影响。
+// 补齐受池上限和运行时/全局配额约束，配额不足时尽力而为，等待下一次协调。
+// 目标带有预热函数时，新建的容器先以各函数的预热载荷试执行，全部成功后才进入预热队列。
+//
+// 参数:
+//   - ctx: 上下文，用于控制容器创建
+//   - targets: 常驻预热目标，未出现的规格不再固定常驻
+func (m *Manager) SetKeepWarm(ctx context.Context, targets []domain.KeepWarmTarget) {
+	if !m.poolCfg.Enabled {
+		return
+	}
+
+	pinned := make(map[string]int)
+	pinnedPools := make(map[string]int)
+	for _, t := range targets {
+		pinned[string(t.Runtime)] += t.Count
+		pinnedPools[poolKey(string(t.Runtime), t.MemoryMB, nil)] += t.Count
+	}
+
+	m.mu.Lock()
+	previous := m.keepWarm
+	m.keepWarm = pinned
+	// 不再固定常驻的规格允许空闲回收
+	for key, pool := range m.pools {
+		if _, ok := pinnedPools[key]; !ok {
+			pool.mu.Lock()
+			pool.pinned = 0
+			pool.mu.Unlock()
+		}
+	}
+	m.mu.Unlock()
+
+	for _, t := range targets {
+		m.reconcileWarm(ctx, string(t.Runtime), t.MemoryMB, t.Count, t.Warmups)
+	}
+
+	// 不再固定常驻的运行时需要将指标清零
+	for runtime := range previous {
+		if _, ok := pinned[runtime]; !ok {
+			m.updatePoolMetrics(runtime)
+		}
+	}
+}
+
+// reconcileWarm 将指定规格容器池中的预热容器数量补齐到 want。
+// 新建的容器依次以 warmups 中各函数的预热载荷试执行，任一失败时销毁该容器并停止本轮补齐。
+func (m *Manager) reconcileWarm(ctx context.Context, runtime string, memoryMB, want int, warmups []*domain.Function) {
+	image, ok := m.images[runtime]
+	if !ok {
+		m.logger.WithField("runtime", runtime).Warn("Keep-warm target has unsupported runtime")
+		return
+	}
+
+	pool := m.getPool(runtime, memoryMB, nil)
+	pool.mu.Lock()
+	pool.pinned = want
+	pool.mu.Unlock()
+	m.evictExpiredWarm(ctx, pool)
+
+	for deficit := want - len(pool.warm); deficit > 0; deficit-- {
+		pool.mu.Lock()
+		canCreate := len(pool.all)+pool.creating < m.poolCfg.MaxTotal && m.budget.tryAcquire(runtime)
+		if canCreate {
+			pool.creating++
+		}
+		pool.mu.Unlock()
+		if !canCreate {
+			m.logger.WithFields(logrus.Fields{
+				"runtime":   runtime,
+				"memory_mb": memoryMB,
+				"missing":   deficit,
+			}).Debug("Keep-warm target limited by pool capacity")
+			break
+		}
+
+		pc, err := m.createContainer(ctx, runtime, memoryMB, nil, image)
+		pool.mu.Lock()
+		pool.creating--
+		if err == nil {
+			pool.all[pc.ID] = pc
+		}
+		pool.mu.Unlock()
+		if err != nil {
+			m.budget.release(runtime)
+			m.logger.WithError(err).WithField("runtime", runtime).Warn("Failed to create keep-warm container")
+			break
+		}
+		if fn, err := m.warmupContainer(ctx, pc, warmups); err != nil {
+			m.logger.WithError(err).WithFields(logrus.Fields{
+				"runtime":       runtime,
+				"container_id":  pc.ID,
+				"function_id":   fn.ID,
+				"function_name": fn.Name,
+			}).Warn("Keep-warm container failed warm-up, destroying it")
+			m.removeContainer(pool, pc)
+			m.recordRecycle(pc, recycleWarmupFailed)
+			_ = exec.CommandContext(ctx, "docker", "rm", "-f", pc.ID).Run()
+			break
+		}
+
+		select {
+		case pool.warm <- pc:
+		default:
+			m.removeContainer(pool, pc)
+			_ = exec.CommandContext(ctx, "docker", "rm", "-f", pc.ID).Run()
+		}
+	}
+
+	m.updatePoolMetrics(runtime)
+}
+
+// warmupContainer 在新建的常驻容器中依次以各函数的预热载荷执行一次函数，确认容器可以正常处理请求。
+// 试执行不创建调用记录、不计费，也不推送调用日志；函数环境中注入 domain.EnvWarmup。
+//
+// 返回值:
+//   - *domain.Function: 试执行失败的函数，成功时为 nil
+//   - error: 试执行失败（超时、运行时错误或函数返回错误）时返回错误信息
+func (m *Manager) warmupContainer(ctx context.Context, pc *pooledContainer, warmups []*domain.Function) (*domain.Function, error) {
+	execCmd, ok := m.execCmd[pc.Runtime]
+	if !ok {
+		return nil, nil
+	}
+	for _, fn := range warmups {
+		timeout := time.Duration(fn.TimeoutSec) * time.Second
+		cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+		deadline, _ := cmdCtx.Deadline()
+		env := domain.InvocationEnv(fn.EnvVars, deadline, timeout)
+		env[domain.EnvWarmup] = "1"
+		input, err := execInput(fn, fn.WarmupPayload, env)
+		if err != nil {
+			cancel()
+			return fn, err
+		}
+
+		args := append([]string{"exec", "-i", pc.ID}, execCmd...)
+		cmd := exec.CommandContext(cmdCtx, "docker", args...)
+		cmd.Stdin = bytes.NewReader(input)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		start := time.Now()
+		err = cmd.Run()
+		timedOut := cmdCtx.Err() == context.DeadlineExceeded
+		cancel()
+		if timedOut {
+			return fn, fmt.Errorf("warm-up timed out after %s", timeout)
+		}
+		if err != nil {
+			return fn, fmt.Errorf("warm-up failed: %v: %s", err, truncateForError(bytes.TrimSpace(stderr.Bytes()), 512))
+		}
+		m.logger.WithFields(logrus.Fields{
+			"container_id":  pc.ID,
+			"function_name": fn.Name,
+			"duration_ms":   time.Since(start).Milliseconds(),
+		}).Debug("Keep-warm container warm-up succeeded")
+	}
+	return nil, nil
+}
+
+// evictExpiredWarm 检查预热队列中的空闲容器，销毁已老化的容器，其余放回队列。
+// 只检查调用时队列中已有的容器，检查期间被取走的容器由 releaseContainer 负责回收。
+func (m *Manager) evictExpiredWarm(ctx context.Context, pool *containerPool) {
+	for n := len(pool.warm); n > 0; n-- {
+		var pc *pooledContainer
+		select {
+		case pc = <-pool.warm:
+		default:
+			return
+		}
+
+		reason := m.expiryReason(pc, 0)
+		if reason == "" {
+			select {
+			case pool.warm <- pc:
+				continue
+			default:
+				reason = recyclePoolFull
+			}
+		}
+		m.removeContainer(pool, pc)
+		m.recordRecycle(pc, reason)
+		_ = m.retireContainer(ctx, pc)
+	}
+}
+
+// 容器回收原因，用作指标标签
+const (
+	recycleHung           = "hung"    // 调用超时未返回，容器内可能残留挂起的进程
+	recycleCrashed        = "crashed" // 运行时进程崩溃或容器已退出
+	recycleMaxReuse       = "max_reuse"
+	recycleMaxInvocations = "max_invocations"
+	recycleMaxAge         = "max_age"
+	recyclePoolFull       = "pool_full"
+	recycleWarmupFailed   = "warmup_failed" // 常驻容器以预热载荷试执行失败
+	recycleArtifacts      = "artifacts"     // 产物目录无法清空，继续复用会把文件泄露给下一次调用
+)
+
+// expiryReason 返回容器需要销毁重建的原因，未过期时返回空字符串。
+// maxReuse > 0 时为刚执行完的函数配置的复用上限，低于容器池设置时优先生效。
+func (m *Manager) expiryReason(pc *pooledContainer, maxReuse int) string {
+	if maxReuse > 0 && maxReuse < m.poolCfg.MaxInvocations && pc.UseCount >= maxReuse {
+		return recycleMaxReuse
+	}
+	if pc.UseCount >= m.poolCfg.MaxInvocations {
+		return recycleMaxInvocations
+	}
+	if time.Since(pc.CreatedAt) > m.poolCfg.MaxContainerAge {
+		return recycleMaxAge
+	}
+	return ""
+}
+
+// ProvisionStats 返回各运行时/内存规格容器池中常驻（keep_warm 预置）与按需预热的容器数，
+// 按运行时和内存规格排序。挂载了共享数据卷的容器池不参与常驻预热，不在结果中。
+//
+// 返回值:
+//   - []domain.ProvisionedPool: 设置了常驻目标或仍有容器的规格
+//   - bool: 未启用池化模式时返回 false
+func (m *Manager) ProvisionStats() ([]domain.ProvisionedPool, bool) {
+	if !m.poolCfg.Enabled {
+		return nil, false
+	}
+
+	m.mu.RLock()
+	stats := make([]domain.ProvisionedPool, 0, len(m.pools))
+	for _, pool := range m.pools {
+		if len(pool.volumes) > 0 {
+			continue
+		}
+		warm := len(pool.warm)
+		pool.mu.Lock()
+		s := domain.ProvisionedPool{
+			Runtime:     domain.Runtime(pool.runtime),
+			MemoryMB:    pool.memoryMB,
+			Provisioned: pool.pinned,
+			Total:       len(pool.all),
+			Creating:    pool.creating,
+		}
+		pool.mu.Unlock()
+		if s.Provisioned == 0 && s.Total == 0 && s.Creating == 0 {
+			continue
+		}
+		// 空闲回收保留的是预热队列中的前 pinned 个容器，其余预热容器为按需创建
+		s.ProvisionedWarm = min(warm, s.Provisioned)
+		s.OnDemandWarm = warm - s.ProvisionedWarm
+		s.Busy = max(s.Total-warm, 0)
+		stats = append(stats, s)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Runtime != stats[j].Runtime {
+			return stats[i].Runtime < stats[j].Runtime
+		}
+		return stats[i].MemoryMB < stats[j].MemoryMB
+	})
+	return stats, true
+}
@@ -0,0 +1,193 @@
+package docker
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// layerCacheEntry 是一个已解压层的缓存记录。
+type layerCacheEntry struct {
+	size     int64     // 解压目录占用的字节数
+	lastUsed time.Time // 最近一次被调用使用的时间
+	refs     int       // 正在挂载该层的执行数，大于 0 时不会被淘汰
+}
+
+// layerCache 管理层解压缓存目录，记录每个已解压层的大小和访问时间，
+// 总大小超出上限时按最近最少使用淘汰未被挂载的层。
+type layerCache struct {
+	dir      string // 缓存目录
+	maxBytes int64  // 总大小上限，<= 0 表示不限制
+
+	mu      sync.Mutex                  // 保护 entries 和 size
+	entries map[string]*layerCacheEntry // 缓存键到缓存记录的映射
+	size    int64                       // 所有已解压层的总字节数
+}
+
+// newLayerCache 创建层解压缓存，并登记缓存目录中已存在的层（如上次运行遗留的层），
+// 以目录修改时间作为最近使用时间。
+func newLayerCache(dir string, maxBytes int64) *layerCache {
+	c := &layerCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		entries:  make(map[string]*layerCacheEntry),
+	}
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return c
+	}
+	for _, de := range dirEntries {
+		if !de.IsDir() {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		size := dirSize(filepath.Join(dir, de.Name()))
+		c.entries[de.Name()] = &layerCacheEntry{size: size, lastUsed: info.ModTime()}
+		c.size += size
+	}
+	return c
+}
+
+// acquire 返回缓存键对应的解压目录，不存在时调用 extract 解压，并增加该层的挂载引用。
+// 使用完毕后必须调用 release 释放引用。
+//
+// 参数:
+//   - key: 层缓存键
+//   - extract: 将层内容解压到指定目录的函数
+//
+// 返回值:
+//   - string: 解压目录
+//   - bool: 是否命中缓存
+//   - error: 解压失败时返回错误
+func (c *layerCache) acquire(key string, extract func(dir string) error) (string, bool, error) {
+	dir := filepath.Join(c.dir, key)
+
+	// 持锁解压，避免并发调用重复解压同一层，以及解压过程中被淘汰
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, hit := c.entries[key]
+	if !hit {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			if err := extract(dir); err != nil {
+				_ = os.RemoveAll(dir)
+				return "", false, err
+			}
+		} else {
+			hit = true
+		}
+		entry = &layerCacheEntry{size: dirSize(dir)}
+		c.entries[key] = entry
+		c.size += entry.size
+	}
+	entry.refs++
+	entry.lastUsed = time.Now()
+	return dir, hit, nil
+}
+
+// release 释放 acquire 增加的挂载引用，并刷新层的最近使用时间。
+func (c *layerCache) release(keys []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for _, key := range keys {
+		if entry, ok := c.entries[key]; ok && entry.refs > 0 {
+			entry.refs--
+			entry.lastUsed = now
+		}
+	}
+}
+
+// evict 在总大小超出上限时，按最近最少使用依次删除未被挂载的层，直到总大小不超过上限。
+//
+// 返回值:
+//   - int: 淘汰的层数
+//   - int64: 淘汰后的缓存总字节数
+func (c *layerCache) evict() (int, int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	evicted := 0
+	for c.maxBytes > 0 && c.size > c.maxBytes {
+		var oldestKey string
+		var oldest *layerCacheEntry
+		for key, entry := range c.entries {
+			if entry.refs > 0 {
+				continue
+			}
+			if oldest == nil || entry.lastUsed.Before(oldest.lastUsed) {
+				oldestKey, oldest = key, entry
+			}
+		}
+		// 剩余的层都在使用中，等待下一轮淘汰
+		if oldest == nil {
+			break
+		}
+		if err := os.RemoveAll(filepath.Join(c.dir, oldestKey)); err != nil {
+			break
+		}
+		delete(c.entries, oldestKey)
+		c.size -= oldest.size
+		evicted++
+	}
+	return evicted, c.size
+}
+
+// dirSize 返回目录下所有普通文件的总字节数。
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// EvictLayerCache 淘汰层解压缓存中超出容量上限的最近最少使用的层。
+//
+// 返回值:
+//   - int: 本次淘汰的层数
+func (m *Manager) EvictLayerCache() int {
+	if m.layers == nil {
+		return 0
+	}
+	evicted, size := m.layers.evict()
+	if evicted > 0 {
+		m.logger.WithFields(logrus.Fields{
+			"evicted":    evicted,
+			"size_bytes": size,
+		}).Info("Evicted layers from layer cache")
+	}
+	if m.metrics != nil {
+		m.metrics.RecordLayerCache(size, evicted)
+	}
+	return evicted
+}
+
+// runLayerCacheSweeper 按 interval 周期淘汰层解压缓存，直到 stop 关闭。
+func (m *Manager) runLayerCacheSweeper(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			m.EvictLayerCache()
+		}
+	}
+}
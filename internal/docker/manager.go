@@ -9,6 +9,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -43,15 +44,31 @@ const (
 // 支持两种执行模式：一次性容器模式和池化容器模式。
 // 池化模式可以复用容器，减少冷启动时间。
 type Manager struct {
-	mu          sync.RWMutex              // 保护并发访问的读写锁
-	images      map[string]string         // 运行时名称到镜像名称的映射，如 "python3.11" -> "function-runtime-python:latest"
-	execCmd     map[string][]string       // 运行时名称到执行命令的映射
-	networkMode string                    // Docker 网络模式，默认为 "none" 以增强安全性
-	poolCfg     config.DockerPoolConfig   // 容器池配置
-	pools       map[string]*containerPool // 容器池映射，键为 "运行时:内存" 格式
-	metrics     *metrics.Metrics          // 指标收集器
-	logger      *logrus.Logger            // 日志记录器
-	bufferPool  sync.Pool                 // 复用 bytes.Buffer，减少热路径分配
+	mu                sync.RWMutex              // 保护并发访问的读写锁
+	images            map[string]string         // 运行时名称到镜像名称的映射，如 "python3.11" -> "function-runtime-python:latest"
+	execCmd           map[string][]string       // 运行时名称到执行命令的映射
+	networkMode       string                    // Docker 网络模式，默认为 "none" 以增强安全性
+	poolCfg           config.DockerPoolConfig   // 容器池配置
+	pools             map[string]*containerPool // 容器池映射，键格式见 poolKey
+	budget            *createBudget             // 跨池的按运行时和全局容器配额
+	keepWarm          map[string]int            // 运行时到常驻预热目标数的映射，由 SetKeepWarm 设置
+	emptyResp         string                    // 函数无输出时的全局默认响应体
+	missing           map[string]string         // 本地不存在的运行时镜像（运行时 -> 镜像），由 CheckImages 更新
+	dataVolumes       map[string]string         // 已注册的共享数据卷（名称 -> 宿主机路径），函数只能挂载其中的数据卷
+	customImages      []string                  // 允许函数使用的自定义镜像白名单，见 custom_image.go
+	artifactsMaxBytes int64                     // 单次调用收集的产物总大小上限，不大于 0 时不收集产物，见 artifacts.go
+	metrics           *metrics.Metrics          // 指标收集器
+	logger            *logrus.Logger            // 日志记录器
+	bufferPool        sync.Pool                 // 复用 bytes.Buffer，减少热路径分配
+
+	pinMu sync.Mutex              // 保护 pins 的互斥锁
+	pins  map[string]*instancePin // 实例亲和提示到暂留容器的映射，见 pin.go
+
+	output outputHub // 执行中调用的实时输出订阅，见 output.go
+
+	layers    *layerCache   // 层解压缓存，见 layer_cache.go
+	sweepStop chan struct{} // 关闭时停止层解压缓存的后台淘汰
+	sweepOnce sync.Once     // 保证 sweepStop 只关闭一次
 }
 
 // pooledContainer 表示池中的一个容器实例。
@@ -64,25 +81,36 @@ type pooledContainer struct {
 	LastUsed  time.Time // 最后使用时间
 	UseCount  int       // 使用次数计数
 	Status    string    // 容器状态：warm（预热）或 busy（忙碌）
+	Volumes   []string  // 挂载的共享数据卷名称（已排序）
+	OutputDir string    // 挂载到容器 /output 的宿主机产物目录，未启用产物收集时为空
+
+	StopGraceSec int // 销毁时的停止宽限期（秒），取执行过的函数中声明的最大值，0 表示立即强制删除
 }
 
 // containerPool 表示特定运行时和内存配置的容器池。
 // 管理一组可复用的预热容器。
 type containerPool struct {
-	runtime  string // 运行时类型
-	memoryMB int    // 内存配置（MB）
+	runtime  string   // 运行时类型
+	memoryMB int      // 内存配置（MB）
+	volumes  []string // 挂载的共享数据卷名称（已排序），池中所有容器挂载相同的数据卷
 
 	warm chan *pooledContainer // 预热容器的缓冲通道
 
-	mu       sync.Mutex                  // 保护 all 和 creating 的互斥锁
+	mu       sync.Mutex                  // 保护 all、creating 和 pinned 的互斥锁
 	all      map[string]*pooledContainer // 所有容器的映射（包括预热和忙碌状态）
 	creating int                         // 正在创建中的容器数量
+	pinned   int                         // keep_warm 固定常驻的容器数，空闲回收时保留
 }
 
 // poolKey 生成容器池的唯一键。
-// 格式为 "运行时:内存MB"，如 "python3.11:128"
-func poolKey(runtime string, memoryMB int) string {
-	return runtime + ":" + strconv.Itoa(memoryMB)
+// 格式为 "运行时:内存MB"，如 "python3.11:128"；挂载了共享数据卷时追加 "+数据卷列表"，
+// 如 "python3.11:128+models,refdata"，挂载不同数据卷的函数不会复用同一容器。
+func poolKey(runtime string, memoryMB int, volumes []string) string {
+	key := runtime + ":" + strconv.Itoa(memoryMB)
+	if len(volumes) > 0 {
+		key += "+" + strings.Join(volumes, ",")
+	}
+	return key
 }
 
 // NewManager 创建新的 Docker 容器管理器。
@@ -106,6 +134,7 @@ func NewManager(cfg config.DockerConfig, m *metrics.Metrics, logger *logrus.Logg
 		"go1.24":     "function-runtime-go:latest",
 		"rust1.75":   "function-runtime-go:latest",
 		"wasm":       "function-runtime-wasm:latest",
+		"bun1":       "function-runtime-bun:latest",
 	}
 	// 用配置中的自定义镜像覆盖默认值
 	for runtime, image := range cfg.Images {
@@ -124,12 +153,20 @@ func NewManager(cfg config.DockerConfig, m *metrics.Metrics, logger *logrus.Logg
 			"go1.24":     {"/app/runtime"},
 			"rust1.75":   {"/app/runtime"},
 			"wasm":       {"/app/runtime"},
+			"bun1":       {"bun", "run", "/app/runtime.js"},
 		},
-		networkMode: networkMode,
-		poolCfg:     cfg.Pool,
-		pools:       make(map[string]*containerPool),
-		metrics:     m,
-		logger:      logger,
+		networkMode:       networkMode,
+		poolCfg:           cfg.Pool,
+		pools:             make(map[string]*containerPool),
+		budget:            newCreateBudget(cfg.Pool.RuntimeMaxTotal, cfg.Pool.GlobalMaxTotal),
+		keepWarm:          make(map[string]int),
+		emptyResp:         cfg.DefaultEmptyResponse,
+		dataVolumes:       make(map[string]string, len(cfg.DataVolumes)),
+		artifactsMaxBytes: int64(cfg.ArtifactsMaxMB) << 20,
+		layers:            newLayerCache(layerCacheDir, int64(cfg.LayerCacheMaxMB)<<20),
+		sweepStop:         make(chan struct{}),
+		metrics:           m,
+		logger:            logger,
 		bufferPool: sync.Pool{
 			New: func() interface{} {
 				return bytes.NewBuffer(make([]byte, 0, 4096)) // 预分配 4KB
@@ -137,6 +174,27 @@ func NewManager(cfg config.DockerConfig, m *metrics.Metrics, logger *logrus.Logg
 		},
 	}
 
+	// 自定义镜像白名单，忽略空白条目
+	for _, entry := range cfg.CustomImages {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			mgr.customImages = append(mgr.customImages, entry)
+		}
+	}
+
+	// 注册共享数据卷，名称不合法或路径不是绝对路径的条目被忽略
+	for name, hostPath := range cfg.DataVolumes {
+		if !domain.ValidDataVolumeName(name) || !filepath.IsAbs(hostPath) {
+			logger.WithFields(logrus.Fields{"name": name, "path": hostPath}).Warn("Ignoring invalid data volume")
+			continue
+		}
+		mgr.dataVolumes[name] = filepath.Clean(hostPath)
+	}
+
+	// 配置了容量上限时，后台周期淘汰层解压缓存中最近最少使用的层
+	if cfg.LayerCacheMaxMB > 0 && cfg.LayerCacheSweepInterval > 0 {
+		go mgr.runLayerCacheSweeper(cfg.LayerCacheSweepInterval, mgr.sweepStop)
+	}
+
 	// 如果启用了容器池，尝试清理之前运行遗留的陈旧容器
 	if mgr.poolCfg.Enabled {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -170,7 +228,8 @@ func (m *Manager) cleanupStaleContainers(ctx context.Context) error {
 	for _, id := range ids {
 		_ = exec.CommandContext(ctx, "docker", "rm", "-f", id).Run()
 	}
-	return nil
+	// 遗留容器的产物目录随容器一起清理
+	return os.RemoveAll(artifactsDir)
 }
 
 // Execute 在 Docker 容器中执行函数。
@@ -208,80 +267,69 @@ func (m *Manager) ExecuteWithLayers(ctx context.Context, fn *domain.Function, pa
 	return m.executePooled(ctx, fn, payload, layers)
 }
 
+// setCallChainEnv 向本次调用的环境变量注入调用链（NIMBUS_CALL_CHAIN），context 未携带调用链时不做任何操作。
+func setCallChainEnv(ctx context.Context, env map[string]string) {
+	if chain := domain.CallChainFromContext(ctx); len(chain) > 0 {
+		env[domain.EnvCallChain] = strings.Join(chain, ",")
+	}
+}
+
 // executeOneOff 使用一次性容器执行函数。
 // 每次调用都会创建新容器，执行完成后自动删除。
 // 适用于不需要频繁调用或需要完全隔离的场景。
 func (m *Manager) executeOneOff(ctx context.Context, fn *domain.Function, payload json.RawMessage, layers []domain.RuntimeLayerInfo) (*domain.InvokeResponse, error) {
 	startTime := time.Now()
 
-	// 获取运行时对应的 Docker 镜像
-	image, ok := m.images[string(fn.Runtime)]
-	if !ok {
-		return nil, fmt.Errorf("unsupported runtime: %s", fn.Runtime)
+	// 获取函数使用的 Docker 镜像（自定义镜像或运行时镜像）
+	image, err := m.functionImage(fn)
+	if err != nil {
+		return nil, err
 	}
 
-	envVars := fn.EnvVars
-	if envVars == nil {
-		envVars = map[string]string{}
-	}
+	// 创建带超时的上下文
+	cmdCtx, cancel := context.WithTimeout(ctx, time.Duration(fn.TimeoutSec)*time.Second)
+	defer cancel()
 
-	// 优先使用编译后的二进制，如果不存在则使用源代码
-	code := fn.Code
-	if fn.Binary != "" {
-		code = fn.Binary
-	}
+	// 注入调用截止时间，函数可据此在超时前优雅退出
+	deadline, _ := cmdCtx.Deadline()
+	envVars := domain.InvocationEnv(fn.EnvVars, deadline, time.Until(deadline))
+	setCallChainEnv(ctx, envVars)
 
-	// 准备函数代码和输入数据
-	input := map[string]interface{}{
-		"handler": fn.Handler,
-		"code":    code,
-		"payload": json.RawMessage(payload),
-		"env":     envVars,
-	}
-	inputJSON, err := json.Marshal(input)
+	inputJSON, err := execInput(fn, payload, envVars)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal input: %w", err)
+		return nil, err
 	}
 
 	// 设置层并获取卷挂载和环境变量
-	volumeMounts, layerEnvVars, err := m.setupLayers(layers, string(fn.Runtime))
+	volumeMounts, layerEnvVars, releaseLayers, err := m.setupLayers(layers, string(fn.Runtime))
 	if err != nil {
 		return nil, fmt.Errorf("failed to setup layers: %w", err)
 	}
-
-	// 构建 docker run 命令参数
-	args := []string{
-		"run", "--rm", // 运行后自动删除容器
-		"--network", m.networkMode, // 网络模式
-	}
-	// 仅在未禁用资源限制时添加 --memory 和 --cpus
-	// 在 Docker-in-Docker 环境中使用 cgroup v2 时可能需要禁用
-	if !m.poolCfg.DisableResourceLimits {
-		args = append(args, "--memory", fmt.Sprintf("%dm", fn.MemoryMB))
-		args = append(args, "--cpus", "1")
+	defer releaseLayers()
+	dataMounts, err := m.dataVolumeMounts(fn.DataVolumes)
+	if err != nil {
+		return nil, err
 	}
-
-	// 添加层卷挂载
-	for _, mount := range volumeMounts {
-		args = append(args, "-v", mount)
+	volumeMounts = append(volumeMounts, dataMounts...)
+	// 每次调用使用独立的产物目录，调用结束后删除
+	outputDir, err := m.newArtifactDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create artifact directory: %w", err)
 	}
-
-	// 添加层环境变量
-	for key, value := range layerEnvVars {
-		args = append(args, "-e", fmt.Sprintf("%s=%s", key, value))
+	if outputDir != "" {
+		defer os.RemoveAll(outputDir)
+		volumeMounts = append(volumeMounts, artifactMount(outputDir))
 	}
 
+	// 构建 docker run 命令参数，自定义镜像的工作目录、命令和参数分别放在镜像之前和之后
+	customOpts, customArgs := customImageArgs(fn)
+	args := m.oneOffRunArgs(fn.MemoryMB, volumeMounts, layerEnvVars)
+	args = append(args, customOpts...)
 	args = append(args,
-		"--read-only",                                                                 // 只读文件系统
-		"--tmpfs", fmt.Sprintf("/tmp:rw,exec,nosuid,size=%dm", m.poolCfg.TmpfsSizeMB), // 临时文件系统
-		"--security-opt", "no-new-privileges", // 安全选项：禁止提升权限
 		"-i", // 交互模式（用于传入输入数据）
 		image,
 	)
-
-	// 创建带超时的上下文
-	cmdCtx, cancel := context.WithTimeout(ctx, time.Duration(fn.TimeoutSec)*time.Second)
-	defer cancel()
+	args = append(args, customArgs...)
 
 	cmd := exec.CommandContext(cmdCtx, "docker", args...)
 	cmd.Stdin = bytes.NewReader(inputJSON)
@@ -296,10 +344,18 @@ func (m *Manager) executeOneOff(ctx context.Context, fn *domain.Function, payloa
 		m.bufferPool.Put(stderr)
 	}()
 
-	cmd.Stdout = stdout
-	cmd.Stderr = stderr
+	// 执行期间的输出同时发布给实时输出订阅者
+	endOutput := m.output.begin(domain.InvocationIDFromContext(ctx))
+	defer endOutput()
+	cmd.Stdout = m.output.tap(ctx, "stdout", stdout)
+	// 函数上报的进度帧从 stderr 中剥离并转发给调度器
+	stderrOut, flushProgress := stderrWriter(ctx, m.output.tap(ctx, "stderr", stderr))
+	cmd.Stderr = stderrOut
 
+	// docker run 会在同一条命令中创建容器并运行函数代码，无法区分失败发生在哪一步，按已开始运行处理
+	domain.MarkExecutionStarted(ctx)
 	err = cmd.Run()
+	flushProgress()
 	duration := time.Since(startTime)
 
 	// 记录详细的执行日志，方便调试
@@ -337,6 +393,9 @@ func (m *Manager) executeOneOff(ctx context.Context, fn *domain.Function, payloa
 		if cmdCtx.Err() == context.DeadlineExceeded {
 			resp.StatusCode = 504
 			resp.Error = "function timed out"
+			resp.ErrorType = domain.InvokeErrorTypeTimeout
+			// 保留超时前的输出，通常包含函数卡住位置附近的日志
+			resp.PartialOutput = domain.NewTimeoutPartialOutput(stdout.Bytes(), stderr.Bytes())
 		} else {
 			resp.StatusCode = 500
 			// 优先使用 stderr 内容，如果为空则使用 stdout 或 err 信息
@@ -349,6 +408,7 @@ func (m *Manager) executeOneOff(ctx context.Context, fn *domain.Function, payloa
 			} else {
 				resp.Error = fmt.Sprintf("execution failed: %v", err)
 			}
+			resp.ErrorType = detectErrorType(stderr.Bytes())
 		}
 		return resp, nil
 	}
@@ -366,6 +426,11 @@ func (m *Manager) executeOneOff(ctx context.Context, fn *domain.Function, payloa
 		resp.Body = body
 		resp.StatusCode = 200
 	}
+	// 成功但没有输出时使用函数或全局配置的默认响应体
+	if len(resp.Body) == 0 {
+		resp.Body = domain.ResolveEmptyResponse(fn.EmptyResponse, m.emptyResp)
+	}
+	m.attachArtifacts(fn, outputDir, resp)
 
 	m.logger.WithFields(logrus.Fields{
 		"function_id": fn.ID,
@@ -376,10 +441,49 @@ func (m *Manager) executeOneOff(ctx context.Context, fn *domain.Function, payloa
 	return resp, nil
 }
 
+// oneOffRunArgs 构建一次性容器的 docker run 公共参数（不含镜像和命令）。
+// 参数：
+//   - memoryMB: 容器内存限制（MB）
+//   - volumeMounts: 层和共享数据卷的挂载列表
+//   - env: 需要注入容器的环境变量
+func (m *Manager) oneOffRunArgs(memoryMB int, volumeMounts []string, env map[string]string) []string {
+	args := []string{
+		"run", "--rm", // 运行后自动删除容器
+		"--network", m.networkMode, // 网络模式
+	}
+	// 仅在未禁用资源限制时添加 --memory 和 --cpus
+	// 在 Docker-in-Docker 环境中使用 cgroup v2 时可能需要禁用
+	if !m.poolCfg.DisableResourceLimits {
+		args = append(args, "--memory", fmt.Sprintf("%dm", memoryMB))
+		args = append(args, "--cpus", "1")
+	}
+
+	// 添加层和共享数据卷挂载
+	for _, mount := range volumeMounts {
+		args = append(args, "-v", mount)
+	}
+
+	// 添加环境变量
+	for key, value := range env {
+		args = append(args, "-e", fmt.Sprintf("%s=%s", key, value))
+	}
+
+	return append(args,
+		"--read-only",                                                                 // 只读文件系统
+		"--tmpfs", fmt.Sprintf("/tmp:rw,exec,nosuid,size=%dm", m.poolCfg.TmpfsSizeMB), // 临时文件系统
+		"--security-opt", "no-new-privileges", // 安全选项：禁止提升权限
+	)
+}
+
 // executePooled 使用池化容器执行函数。
 // 从容器池获取预热容器执行，执行完成后归还到池中复用。
 // 可以显著减少冷启动时间。
 func (m *Manager) executePooled(ctx context.Context, fn *domain.Function, payload json.RawMessage, layers []domain.RuntimeLayerInfo) (*domain.InvokeResponse, error) {
+	// 自定义镜像不一定包含保持池化容器运行的 /bin/sh，始终使用一次性容器执行
+	if fn.CustomImage != "" {
+		return m.executeOneOff(ctx, fn, payload, layers)
+	}
+
 	startTime := time.Now()
 
 	// 获取运行时对应的镜像和执行命令
@@ -392,58 +496,84 @@ func (m *Manager) executePooled(ctx context.Context, fn *domain.Function, payloa
 		return nil, fmt.Errorf("unsupported runtime exec command: %s", fn.Runtime)
 	}
 
-	envVars := fn.EnvVars
-	if envVars == nil {
-		envVars = map[string]string{}
-	}
+	// 创建带超时的上下文，截止时间包含排队等待容器的时间
+	cmdCtx, cancel := context.WithTimeout(ctx, time.Duration(fn.TimeoutSec)*time.Second)
+	defer cancel()
 
 	// 设置层并获取卷挂载和环境变量
-	_, layerEnvVars, err := m.setupLayers(layers, string(fn.Runtime))
+	_, layerEnvVars, releaseLayers, err := m.setupLayers(layers, string(fn.Runtime))
 	if err != nil {
 		return nil, fmt.Errorf("failed to setup layers: %w", err)
 	}
+	defer releaseLayers()
 
-	// 合并层环境变量到函数环境变量
-	for key, value := range layerEnvVars {
+	// 合并层环境变量到函数环境变量（副本，不修改函数定义），并注入调用截止时间
+	envVars := make(map[string]string, len(fn.EnvVars)+len(layerEnvVars))
+	for key, value := range fn.EnvVars {
 		envVars[key] = value
 	}
-
-	// 优先使用编译后的二进制，如果不存在则使用源代码
-	code := fn.Code
-	if fn.Binary != "" {
-		code = fn.Binary
+	for key, value := range layerEnvVars {
+		envVars[key] = value
 	}
+	deadline, _ := cmdCtx.Deadline()
+	envVars = domain.InvocationEnv(envVars, deadline, time.Until(deadline))
+	setCallChainEnv(ctx, envVars)
 
-	// 准备函数代码和输入数据
-	input := map[string]interface{}{
-		"handler": fn.Handler,
-		"code":    code,
-		"payload": json.RawMessage(payload),
-		"env":     envVars,
-	}
-	inputJSON, err := json.Marshal(input)
+	inputJSON, err := execInput(fn, payload, envVars)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal input: %w", err)
+		return nil, err
 	}
 
-	// 创建带超时的上下文
-	cmdCtx, cancel := context.WithTimeout(ctx, time.Duration(fn.TimeoutSec)*time.Second)
-	defer cancel()
-
-	// 从池中获取容器
-	pc, coldStart, err := m.acquireContainer(cmdCtx, string(fn.Runtime), fn.MemoryMB, image)
+	// 从池中获取容器，挂载了共享数据卷的函数使用独立的池
+	volumes, err := m.resolveDataVolumes(fn.DataVolumes)
 	if err != nil {
 		return nil, err
 	}
+	// 携带实例亲和提示时优先复用上一次调用暂留的容器
+	pinKey := domain.InstancePinFromContext(ctx)
+	pk := poolKey(string(fn.Runtime), fn.MemoryMB, volumes)
+	acquireStart := time.Now()
+	pc, coldStart := m.takePinned(pinKey, pk), false
+	if pc == nil {
+		pc, coldStart, err = m.acquireContainer(cmdCtx, string(fn.Runtime), fn.MemoryMB, volumes, image)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// 热启动时获取容器的耗时即为排队等待时间（冷启动的容器创建耗时计入执行时长）
+	var queueWait time.Duration
+	if !coldStart {
+		queueWait = time.Since(acquireStart)
+	}
 
-	// 记录容器是否健康，用于决定是否归还到池中
-	healthy := true
+	// 函数声明了停止宽限期时，销毁容器前给函数进程留出清理时间
+	if fn.StopGracePeriodSec > pc.StopGraceSec {
+		pc.StopGraceSec = fn.StopGracePeriodSec
+	}
+
+	// 记录容器不健康的原因（为空表示健康），用于决定是否归还到池中
+	unhealthy := ""
 	defer func() {
-		if err := m.releaseContainer(context.Background(), pc, healthy); err != nil {
+		// 带亲和提示的健康容器暂留给下一次调用，不归还容器池
+		if pinKey != "" && unhealthy == "" && m.expiryReason(pc, fn.MaxReuse) == "" {
+			m.holdPinned(pinKey, pk, pc, fn.MaxReuse)
+			return
+		}
+		if err := m.releaseContainer(context.Background(), pc, unhealthy, fn.MaxReuse); err != nil {
 			m.logger.WithError(err).WithField("container_id", pc.ID).Warn("Failed to release docker container")
 		}
 	}()
 
+	// 池化容器的产物目录在调用之间复用，调用结束后清空，下一次调用看不到本次的文件
+	if pc.OutputDir != "" {
+		defer func() {
+			if err := clearArtifactDir(pc.OutputDir); err != nil {
+				m.logger.WithError(err).WithField("container_id", pc.ID).Warn("Failed to clear artifact directory")
+				unhealthy = recycleArtifacts
+			}
+		}()
+	}
+
 	// 使用 docker exec 在已运行的容器中执行函数
 	args := []string{"exec", "-i", pc.ID}
 	args = append(args, execCmd...)
@@ -461,11 +591,19 @@ func (m *Manager) executePooled(ctx context.Context, fn *domain.Function, payloa
 		m.bufferPool.Put(stderr)
 	}()
 
-	cmd.Stdout = stdout
-	cmd.Stderr = stderr
+	// 执行期间的输出同时发布给实时输出订阅者
+	endOutput := m.output.begin(domain.InvocationIDFromContext(ctx))
+	defer endOutput()
+	cmd.Stdout = m.output.tap(ctx, "stdout", stdout)
+	// 函数上报的进度帧从 stderr 中剥离并转发给调度器
+	stderrOut, flushProgress := stderrWriter(ctx, m.output.tap(ctx, "stderr", stderr))
+	cmd.Stderr = stderrOut
 
+	// 此后的失败可能发生在函数代码运行之后，调度器不再透明重试
+	domain.MarkExecutionStarted(ctx)
 	runErr := cmd.Run()
-	duration := time.Since(startTime)
+	flushProgress()
+	duration := time.Since(startTime) - queueWait
 
 	// 记录详细的执行日志，方便调试
 	m.logger.WithFields(logrus.Fields{
@@ -474,6 +612,7 @@ func (m *Manager) executePooled(ctx context.Context, fn *domain.Function, payloa
 		"container_id":  pc.ID,
 		"cold_start":    coldStart,
 		"duration_ms":   duration.Milliseconds(),
+		"queue_wait_ms": queueWait.Milliseconds(),
 		"stdout_len":    len(stdout.Bytes()),
 		"stderr_len":    len(stderr.Bytes()),
 		"stdout":        truncateForError(stdout.Bytes(), 500),
@@ -484,6 +623,7 @@ func (m *Manager) executePooled(ctx context.Context, fn *domain.Function, payloa
 	// 构建响应对象
 	resp := &domain.InvokeResponse{
 		DurationMs:   duration.Milliseconds(),
+		QueueWaitMs:  queueWait.Milliseconds(),
 		BilledTimeMs: ((duration.Milliseconds() + 99) / 100) * 100, // 向上取整到 100ms
 		ColdStart:    coldStart,
 	}
@@ -500,9 +640,22 @@ func (m *Manager) executePooled(ctx context.Context, fn *domain.Function, payloa
 		}).Error("Function execution failed in pooled container")
 
 		if cmdCtx.Err() == context.DeadlineExceeded {
+			// 调用挂起：在超时时间内没有返回。结束 docker exec 客户端不会结束容器内的进程，
+			// 因此销毁容器（连同挂起的进程），下一次调用使用新的容器
 			resp.StatusCode = 504
 			resp.Error = "function timed out"
-			healthy = false // 超时后容器可能有残留进程，标记为不健康
+			resp.ErrorType = domain.InvokeErrorTypeTimeout
+			resp.PartialOutput = domain.NewTimeoutPartialOutput(stdout.Bytes(), stderr.Bytes())
+			unhealthy = recycleHung
+		} else if execCrashed(runErr, stderr.Bytes()) {
+			// 运行时进程被信号终止（如 OOM、段错误）或容器已退出，容器不再可信
+			resp.StatusCode = 500
+			resp.Error = fmt.Sprintf("runtime process crashed: %v", runErr)
+			if stderrStr := strings.TrimSpace(stderr.String()); stderrStr != "" {
+				resp.Error += ": " + truncateForError([]byte(stderrStr), 512)
+			}
+			resp.ErrorType = domain.InvokeErrorTypeCrash
+			unhealthy = recycleCrashed
 		} else {
 			resp.StatusCode = 500
 			// 优先使用 stderr 内容，如果为空则使用 stdout 或 err 信息
@@ -515,6 +668,7 @@ func (m *Manager) executePooled(ctx context.Context, fn *domain.Function, payloa
 			} else {
 				resp.Error = fmt.Sprintf("execution failed: %v", runErr)
 			}
+			resp.ErrorType = detectErrorType(stderr.Bytes())
 		}
 		return resp, nil
 	}
@@ -532,6 +686,11 @@ func (m *Manager) executePooled(ctx context.Context, fn *domain.Function, payloa
 		resp.Body = body
 		resp.StatusCode = 200
 	}
+	// 成功但没有输出时使用函数或全局配置的默认响应体
+	if len(resp.Body) == 0 {
+		resp.Body = domain.ResolveEmptyResponse(fn.EmptyResponse, m.emptyResp)
+	}
+	m.attachArtifacts(fn, pc.OutputDir, resp)
 
 	m.logger.WithFields(logrus.Fields{
 		"function_id": fn.ID,
@@ -543,6 +702,32 @@ func (m *Manager) executePooled(ctx context.Context, fn *domain.Function, payloa
 	return resp, nil
 }
 
+// execInput 构造通过 stdin 传给池化容器内运行时的输入：函数代码、入口点、调用载荷和环境变量。
+func execInput(fn *domain.Function, payload json.RawMessage, envVars map[string]string) ([]byte, error) {
+	// 优先使用编译后的二进制，如果不存在则使用源代码
+	code := fn.Code
+	if fn.Binary != "" {
+		code = fn.Binary
+	}
+
+	// 准备函数代码和输入数据
+	input := map[string]interface{}{
+		"handler": fn.Handler,
+		"code":    code,
+		"payload": json.RawMessage(payload),
+		"env":     envVars,
+	}
+	// 初始化函数由运行时在进程启动时执行一次，结果通过 context 传给 handler
+	if fn.InitHandler != "" {
+		input["init_handler"] = fn.InitHandler
+	}
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal input: %w", err)
+	}
+	return inputJSON, nil
+}
+
 func extractJSONFromStdout(stdout []byte) (json.RawMessage, bool) {
 	trimmed := bytes.TrimSpace(stdout)
 	if len(trimmed) == 0 {
@@ -569,6 +754,34 @@ func extractJSONFromStdout(stdout []byte) (json.RawMessage, bool) {
 	return nil, false
 }
 
+// detectErrorType 从运行时 stderr 输出中解析错误分类。
+// 运行时在无法加载函数时会输出带 error_type 字段的 JSON（如 init_error），
+// 用户代码可能先打印其他日志，因此取最后一行合法 JSON。
+func detectErrorType(stderr []byte) string {
+	line, ok := extractJSONFromStdout(stderr)
+	if !ok || len(line) == 0 {
+		return ""
+	}
+	var payload struct {
+		ErrorType string `json:"error_type"`
+	}
+	if err := json.Unmarshal(line, &payload); err != nil {
+		return ""
+	}
+	return payload.ErrorType
+}
+
+// execCrashed 判断 docker exec 的失败是否为运行时进程崩溃，而不是函数代码返回的错误。
+// 进程被信号终止时退出码为 128+信号值（如 OOM 被 SIGKILL 终止为 137）；
+// 容器本身已退出时 docker exec 无法执行，stderr 包含 "is not running"。
+func execCrashed(runErr error, stderr []byte) bool {
+	var exitErr *exec.ExitError
+	if errors.As(runErr, &exitErr) && exitErr.ExitCode() > 128 {
+		return true
+	}
+	return bytes.Contains(stderr, []byte("is not running"))
+}
+
 func truncateForError(b []byte, max int) string {
 	b = bytes.TrimSpace(b)
 	if len(b) <= max {
@@ -577,10 +790,10 @@ func truncateForError(b []byte, max int) string {
 	return string(b[:max]) + "...(truncated)"
 }
 
-// getPool 获取或创建指定运行时和内存配置的容器池。
-// 线程安全。
-func (m *Manager) getPool(runtime string, memoryMB int) *containerPool {
-	key := poolKey(runtime, memoryMB)
+// getPool 获取或创建指定运行时、内存配置和共享数据卷的容器池。
+// volumes 必须是 resolveDataVolumes 返回的已排序名称。线程安全。
+func (m *Manager) getPool(runtime string, memoryMB int, volumes []string) *containerPool {
+	key := poolKey(runtime, memoryMB, volumes)
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -593,6 +806,7 @@ func (m *Manager) getPool(runtime string, memoryMB int) *containerPool {
 	p := &containerPool{
 		runtime:  runtime,
 		memoryMB: memoryMB,
+		volumes:  volumes,
 		warm:     make(chan *pooledContainer, m.poolCfg.MaxTotal), // 预热容器缓冲通道
 		all:      make(map[string]*pooledContainer),
 	}
@@ -602,12 +816,13 @@ func (m *Manager) getPool(runtime string, memoryMB int) *containerPool {
 
 // acquireContainer 从池中获取一个容器。
 // 优先获取预热容器（热启动），如果没有则创建新容器（冷启动）。
+// 池已满时等待容器归还，超过 QueueTimeoutSec 返回 domain.ErrQueueTimeout。
 // 返回：
 //   - *pooledContainer: 获取到的容器
 //   - bool: 是否为冷启动
 //   - error: 错误信息
-func (m *Manager) acquireContainer(ctx context.Context, runtime string, memoryMB int, image string) (*pooledContainer, bool, error) {
-	pool := m.getPool(runtime, memoryMB)
+func (m *Manager) acquireContainer(ctx context.Context, runtime string, memoryMB int, volumes []string, image string) (*pooledContainer, bool, error) {
+	pool := m.getPool(runtime, memoryMB, volumes)
 
 	// 快速路径：尝试获取预热容器
 	select {
@@ -621,9 +836,9 @@ func (m *Manager) acquireContainer(ctx context.Context, runtime string, memoryMB
 		// 没有预热容器可用
 	}
 
-	// 检查是否可以创建新容器（未达到上限）
+	// 检查是否可以创建新容器：需同时满足池上限、运行时上限和全局上限
 	pool.mu.Lock()
-	canCreate := len(pool.all)+pool.creating < m.poolCfg.MaxTotal
+	canCreate := len(pool.all)+pool.creating < m.poolCfg.MaxTotal && m.budget.tryAcquire(runtime)
 	if canCreate {
 		pool.creating++ // 增加正在创建计数，防止并发创建超出限制
 	}
@@ -631,7 +846,7 @@ func (m *Manager) acquireContainer(ctx context.Context, runtime string, memoryMB
 
 	if canCreate {
 		// 创建新容器（冷启动）
-		pc, err := m.createContainer(ctx, runtime, memoryMB, image)
+		pc, err := m.createContainer(ctx, runtime, memoryMB, volumes, image)
 		pool.mu.Lock()
 		pool.creating--
 		if err == nil {
@@ -642,13 +857,20 @@ func (m *Manager) acquireContainer(ctx context.Context, runtime string, memoryMB
 		}
 		pool.mu.Unlock()
 		if err != nil {
+			m.budget.release(runtime)
 			return nil, false, err
 		}
 		m.updatePoolMetrics(runtime)
 		return pc, true, nil // true 表示冷启动
 	}
 
-	// 池已满：等待预热容器变为可用
+	// 池已满：等待预热容器变为可用，配置了排队超时时最多等待该时长
+	var queueTimeout <-chan time.Time
+	if m.poolCfg.QueueTimeoutSec > 0 {
+		timer := time.NewTimer(time.Duration(m.poolCfg.QueueTimeoutSec) * time.Second)
+		defer timer.Stop()
+		queueTimeout = timer.C
+	}
 	select {
 	case pc := <-pool.warm:
 		pc.Status = "busy"
@@ -656,16 +878,19 @@ func (m *Manager) acquireContainer(ctx context.Context, runtime string, memoryMB
 		pc.UseCount++
 		m.updatePoolMetrics(runtime)
 		return pc, false, nil
+	case <-queueTimeout:
+		return nil, false, domain.ErrQueueTimeout
 	case <-ctx.Done():
 		return nil, false, ctx.Err()
 	}
 }
 
 // createContainer 创建一个新的 Docker 容器。
-// 容器创建后会启动并保持运行（使用 tail -f /dev/null）。
-func (m *Manager) createContainer(ctx context.Context, runtime string, memoryMB int, image string) (*pooledContainer, error) {
+// 容器创建后会启动并保持运行（由 poolKeepalive 作为 1 号进程）。
+// volumes 中的共享数据卷以只读方式挂载到 domain.DataVolumeMountDir 下。
+func (m *Manager) createContainer(ctx context.Context, runtime string, memoryMB int, volumes []string, image string) (*pooledContainer, error) {
 	// 保持容器运行的命令
-	keepalive := "tail -f /dev/null"
+	keepalive := poolKeepalive
 
 	// 确保层缓存目录存在
 	if err := os.MkdirAll(layerCacheDir, 0755); err != nil {
@@ -683,6 +908,22 @@ func (m *Manager) createContainer(ctx context.Context, runtime string, memoryMB
 		// 挂载层缓存目录（只读）
 		"-v", fmt.Sprintf("%s:/opt/layers:ro", layerCacheDir),
 	}
+	// 挂载共享数据卷（只读）
+	dataMounts, err := m.dataVolumeMounts(volumes)
+	if err != nil {
+		return nil, err
+	}
+	for _, mount := range dataMounts {
+		args = append(args, "-v", mount)
+	}
+	// 挂载容器独占的产物目录（读写）
+	outputDir, err := m.newArtifactDir()
+	if err != nil {
+		return nil, fmt.Errorf("%w: create artifact directory: %v", domain.ErrContainerStartFailed, err)
+	}
+	if outputDir != "" {
+		args = append(args, "-v", artifactMount(outputDir))
+	}
 	// 仅在未禁用资源限制时添加 --memory 和 --cpus
 	// 在 Docker-in-Docker 环境中使用 cgroup v2 时可能需要禁用
 	if !m.poolCfg.DisableResourceLimits {
@@ -701,11 +942,13 @@ func (m *Manager) createContainer(ctx context.Context, runtime string, memoryMB
 	cmd := exec.CommandContext(ctx, "docker", args...)
 	out, err := cmd.Output()
 	if err != nil {
-		return nil, err
+		removeArtifactDir(outputDir)
+		return nil, fmt.Errorf("%w: docker create: %v", domain.ErrContainerStartFailed, err)
 	}
 	id := strings.TrimSpace(string(out))
 	if id == "" {
-		return nil, fmt.Errorf("docker create returned empty container id")
+		removeArtifactDir(outputDir)
+		return nil, fmt.Errorf("%w: docker create returned empty container id", domain.ErrContainerStartFailed)
 	}
 
 	// 启动容器
@@ -713,7 +956,8 @@ func (m *Manager) createContainer(ctx context.Context, runtime string, memoryMB
 	if err := startCmd.Run(); err != nil {
 		// 启动失败，清理创建的容器
 		_ = exec.CommandContext(context.Background(), "docker", "rm", "-f", id).Run()
-		return nil, err
+		removeArtifactDir(outputDir)
+		return nil, fmt.Errorf("%w: docker start: %v", domain.ErrContainerStartFailed, err)
 	}
 
 	now := time.Now()
@@ -724,32 +968,38 @@ func (m *Manager) createContainer(ctx context.Context, runtime string, memoryMB
 		CreatedAt: now,
 		LastUsed:  now,
 		Status:    "warm",
+		Volumes:   volumes,
+		OutputDir: outputDir,
 	}, nil
 }
 
 // releaseContainer 释放容器回池中或销毁。
-// 根据容器的使用次数和存活时间决定是回收还是销毁。
+// 根据容器的健康状况、使用次数和存活时间决定是回收还是销毁。
 // 参数：
 //   - ctx: 上下文
 //   - pc: 要释放的容器
-//   - healthy: 容器是否健康（如果不健康则直接销毁）
-func (m *Manager) releaseContainer(ctx context.Context, pc *pooledContainer, healthy bool) error {
-	pool := m.getPool(pc.Runtime, pc.MemoryMB)
+//   - unhealthy: 容器不健康的原因（recycleHung/recycleCrashed），为空表示健康；不健康的容器直接销毁
+//   - maxReuse: 刚执行完的函数配置的容器复用上限，0 表示使用容器池设置
+func (m *Manager) releaseContainer(ctx context.Context, pc *pooledContainer, unhealthy string, maxReuse int) error {
+	pool := m.getPool(pc.Runtime, pc.MemoryMB, pc.Volumes)
 
 	// 决定是否需要销毁容器：
-	// 1. 容器不健康
-	// 2. 使用次数超过限制
+	// 1. 容器不健康（调用挂起或运行时进程崩溃）
+	// 2. 使用次数超过函数或容器池的限制
 	// 3. 存活时间超过限制
-	if !healthy || pc.UseCount >= m.poolCfg.MaxInvocations || time.Since(pc.CreatedAt) > m.poolCfg.MaxContainerAge {
-		pool.mu.Lock()
-		delete(pool.all, pc.ID)
-		pool.mu.Unlock()
-		m.updatePoolMetrics(pc.Runtime)
-		return exec.CommandContext(ctx, "docker", "rm", "-f", pc.ID).Run()
+	reason := unhealthy
+	if reason == "" {
+		reason = m.expiryReason(pc, maxReuse)
+	}
+	if reason != "" {
+		m.removeContainer(pool, pc)
+		m.recordRecycle(pc, reason)
+		return m.retireContainer(ctx, pc)
 	}
 
-	// 将容器标记为预热状态
+	// 将容器标记为预热状态，空闲时间从放回池中开始计算
 	pc.Status = "warm"
+	pc.LastUsed = time.Now()
 	pool.mu.Lock()
 	pool.mu.Unlock()
 
@@ -760,16 +1010,71 @@ func (m *Manager) releaseContainer(ctx context.Context, pc *pooledContainer, hea
 		return nil
 	default:
 		// 预热队列已满：销毁容器
-		pool.mu.Lock()
-		delete(pool.all, pc.ID)
-		pool.mu.Unlock()
-		m.updatePoolMetrics(pc.Runtime)
-		return exec.CommandContext(ctx, "docker", "rm", "-f", pc.ID).Run()
+		m.removeContainer(pool, pc)
+		m.recordRecycle(pc, recyclePoolFull)
+		return m.retireContainer(ctx, pc)
+	}
+}
+
+// poolKeepalive 是池化容器的 1 号进程。
+// 收到 SIGTERM（docker stop）时把信号转发给容器内的所有进程（包括 docker exec 启动的函数进程），
+// 并等待它们全部退出后再退出，使 docker stop 的宽限期对函数进程生效；docker rm -f 直接 SIGKILL，不受影响。
+const poolKeepalive = `trap 'kill -TERM -1 2>/dev/null; wait; while kill -0 -1 2>/dev/null; do sleep 0.1; done; exit 0' TERM; while :; do sleep 3600 & wait $!; done`
+
+// dockerContainerCommand 执行一条 docker 容器管理命令（stop、rm 等）。
+// 变量形式便于测试替换。
+var dockerContainerCommand = func(ctx context.Context, args ...string) error {
+	return exec.CommandContext(ctx, "docker", args...).Run()
+}
+
+// destroyContainer 销毁池化容器。
+// 容器声明了停止宽限期时先 docker stop --time=N（SIGTERM，宽限期后 SIGKILL），再删除容器；
+// 否则直接 docker rm -f 立即删除。
+func (m *Manager) destroyContainer(ctx context.Context, pc *pooledContainer) error {
+	defer removeArtifactDir(pc.OutputDir)
+	if pc.StopGraceSec > 0 {
+		if err := dockerContainerCommand(ctx, "stop", "--time", strconv.Itoa(pc.StopGraceSec), pc.ID); err != nil {
+			m.logger.WithError(err).WithField("container_id", pc.ID).Debug("Graceful docker stop failed, forcing removal")
+		}
+	}
+	return dockerContainerCommand(ctx, "rm", "-f", pc.ID)
+}
+
+// retireContainer 销毁已从池中移除的容器。需要优雅停止的容器在后台销毁，
+// 避免宽限期阻塞刚结束的调用返回结果。
+func (m *Manager) retireContainer(ctx context.Context, pc *pooledContainer) error {
+	if pc.StopGraceSec == 0 {
+		return m.destroyContainer(ctx, pc)
+	}
+	go func() {
+		if err := m.destroyContainer(context.Background(), pc); err != nil {
+			m.logger.WithError(err).WithField("container_id", pc.ID).Warn("Failed to remove docker container")
+		}
+	}()
+	return nil
+}
+
+// recordRecycle 记录容器回收原因并更新容器池指标。
+func (m *Manager) recordRecycle(pc *pooledContainer, reason string) {
+	if m.metrics != nil {
+		m.metrics.RecordContainerRecycle(pc.Runtime, reason)
+	}
+	m.updatePoolMetrics(pc.Runtime)
+}
+
+// removeContainer 将容器从池中移除并归还其配额。
+func (m *Manager) removeContainer(pool *containerPool, pc *pooledContainer) {
+	pool.mu.Lock()
+	_, ok := pool.all[pc.ID]
+	delete(pool.all, pc.ID)
+	pool.mu.Unlock()
+	if ok {
+		m.budget.release(pc.Runtime)
 	}
 }
 
 // updatePoolMetrics 更新容器池的 Prometheus 指标。
-// 统计指定运行时的预热、忙碌和总容器数。
+// 统计指定运行时的预热、忙碌和总容器数，以及常驻预热目标数。
 func (m *Manager) updatePoolMetrics(runtime string) {
 	if m.metrics == nil {
 		return
@@ -795,68 +1100,55 @@ func (m *Manager) updatePoolMetrics(runtime string) {
 		busy = 0
 	}
 	m.metrics.UpdatePoolStats(runtime, warm, busy, total)
-}
+	m.metrics.UpdatePoolPinnedWarm(runtime, m.keepWarm[runtime])
 
-// BuildImages 构建所有运行时的 Docker 镜像。
-// 参数：
-//   - ctx: 上下文
-//   - dockerfilesDir: 包含 Dockerfile 的目录
-//
-// 期望的 Dockerfile 命名格式为 Dockerfile.<runtime>，如 Dockerfile.python3.11
-func (m *Manager) BuildImages(ctx context.Context, dockerfilesDir string) error {
-	for runtime, image := range m.images {
-		dockerfile := fmt.Sprintf("%s/Dockerfile.%s", dockerfilesDir, runtime)
-		m.logger.Infof("Building image %s from %s", image, dockerfile)
-
-		cmd := exec.CommandContext(ctx, "docker", "build", "-t", image, "-f", dockerfile, dockerfilesDir)
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("failed to build %s: %w\n%s", image, err, output)
-		}
-		m.logger.Infof("Built image: %s", image)
-	}
-	return nil
+	used, limit := m.budget.utilization(runtime)
+	m.metrics.UpdatePoolUtilization(runtime, used, limit)
 }
 
 // setupLayers 设置函数层，返回卷挂载列表和环境变量。
 // 该函数会将层内容解压到主机缓存目录，并返回：
 //   - volumeMounts: Docker -v 参数格式的卷挂载列表
 //   - envVars: 需要设置的环境变量（如 PYTHONPATH、NODE_PATH）
+//   - release: 释放层的挂载引用，容器结束后必须调用，之后层才可能被缓存淘汰
 //   - error: 设置过程中的错误
-func (m *Manager) setupLayers(layers []domain.RuntimeLayerInfo, runtime string) ([]string, map[string]string, error) {
+func (m *Manager) setupLayers(layers []domain.RuntimeLayerInfo, runtime string) ([]string, map[string]string, func(), error) {
 	if len(layers) == 0 {
-		return nil, nil, nil
+		return nil, nil, func() {}, nil
 	}
 
 	// 确保缓存目录存在
 	if err := os.MkdirAll(layerCacheDir, 0755); err != nil {
-		return nil, nil, fmt.Errorf("failed to create layer cache directory: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to create layer cache directory: %w", err)
 	}
 
 	var volumeMounts []string
 	var pythonPaths, nodePaths []string
+	acquired := make([]string, 0, len(layers))
+	release := func() { m.layers.release(acquired) }
 
 	for _, layer := range layers {
 		// 使用内容哈希作为缓存键，避免重复解压
 		cacheKey := m.layerCacheKey(layer.LayerID, layer.Version, layer.Content)
-		layerDir := filepath.Join(layerCacheDir, cacheKey)
-
-		// 检查是否已缓存
-		if _, err := os.Stat(layerDir); os.IsNotExist(err) {
-			// 解压层内容
-			if err := m.extractZipToDir(layer.Content, layerDir); err != nil {
-				return nil, nil, fmt.Errorf("failed to extract layer %s: %w", layer.LayerID, err)
-			}
+		layerDir, hit, err := m.layers.acquire(cacheKey, func(dir string) error {
+			return m.extractZipToDir(layer.Content, dir)
+		})
+		if err != nil {
+			release()
+			return nil, nil, nil, fmt.Errorf("failed to extract layer %s: %w", layer.LayerID, err)
+		}
+		acquired = append(acquired, cacheKey)
+		if hit {
 			m.logger.WithFields(logrus.Fields{
 				"layer_id": layer.LayerID,
 				"version":  layer.Version,
-				"path":     layerDir,
-			}).Debug("Layer extracted to cache")
+			}).Debug("Layer found in cache")
 		} else {
 			m.logger.WithFields(logrus.Fields{
 				"layer_id": layer.LayerID,
 				"version":  layer.Version,
-			}).Debug("Layer found in cache")
+				"path":     layerDir,
+			}).Debug("Layer extracted to cache")
 		}
 
 		// 添加卷挂载
@@ -870,7 +1162,8 @@ func (m *Manager) setupLayers(layers []domain.RuntimeLayerInfo, runtime string)
 				filepath.Join(containerPath, "python"),
 				filepath.Join(containerPath, "python", "lib", "python3.11", "site-packages"),
 			)
-		case "nodejs20":
+		case "nodejs20", "bun1":
+			// Bun 兼容 Node 的模块解析，复用 nodejs/node_modules 层结构
 			nodePaths = append(nodePaths,
 				filepath.Join(containerPath, "nodejs", "node_modules"),
 			)
@@ -886,7 +1179,7 @@ func (m *Manager) setupLayers(layers []domain.RuntimeLayerInfo, runtime string)
 		envVars["NODE_PATH"] = strings.Join(nodePaths, ":")
 	}
 
-	return volumeMounts, envVars, nil
+	return volumeMounts, envVars, release, nil
 }
 
 // layerCacheKey 生成层的缓存键。
@@ -978,25 +1271,47 @@ func (m *Manager) extractZipToDir(content []byte, destDir string) error {
 // Cleanup 停止并删除由本管理器创建的所有池化容器。
 // 应在程序关闭时调用以确保资源释放。
 func (m *Manager) Cleanup(ctx context.Context) error {
+	if m.sweepStop != nil {
+		m.sweepOnce.Do(func() { close(m.sweepStop) })
+	}
 	if !m.poolCfg.Enabled {
 		return nil
 	}
 
+	// 停止所有暂留容器的空闲计时，容器随所在的池一起销毁
+	m.pinMu.Lock()
+	for key, pin := range m.pins {
+		pin.timer.Stop()
+		delete(m.pins, key)
+	}
+	m.pinMu.Unlock()
+
 	// 获取并重置所有池
 	m.mu.Lock()
 	pools := m.pools
 	m.pools = make(map[string]*containerPool)
 	m.mu.Unlock()
 
-	// 销毁所有池中的容器
+	// 销毁所有池中的容器，需要优雅停止的容器并行等待宽限期
+	var wg sync.WaitGroup
 	for _, p := range pools {
 		p.mu.Lock()
-		for id := range p.all {
-			_ = exec.CommandContext(ctx, "docker", "rm", "-f", id).Run()
+		for _, pc := range p.all {
+			if pc.StopGraceSec == 0 {
+				_ = m.destroyContainer(ctx, pc)
+				continue
+			}
+			wg.Add(1)
+			go func(pc *pooledContainer) {
+				defer wg.Done()
+				_ = m.destroyContainer(ctx, pc)
+			}(pc)
 		}
 		p.all = make(map[string]*pooledContainer)
 		p.mu.Unlock()
 	}
+	wg.Wait()
+	m.budget.reset()
 
 	// 额外清理：删除带有我们标签的所有陈旧容器
 	_ = m.cleanupStaleContainers(ctx)
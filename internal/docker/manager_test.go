@@ -1,6 +1,21 @@
 package docker
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/config"
+	"github.com/oriys/nimbus/internal/domain"
+)
 
 func TestExtractJSONFromStdout(t *testing.T) {
 	t.Run("empty", func(t *testing.T) {
@@ -52,3 +67,589 @@ func TestExtractJSONFromStdout(t *testing.T) {
 		}
 	})
 }
+
+func TestDetectErrorType(t *testing.T) {
+	t.Run("init error", func(t *testing.T) {
+		got := detectErrorType([]byte("{\"error\":\"boom\",\"error_type\":\"init_error\"}\n"))
+		if got != "init_error" {
+			t.Fatalf("got=%q, want %q", got, "init_error")
+		}
+	})
+
+	t.Run("logs then init error", func(t *testing.T) {
+		got := detectErrorType([]byte("warning: something\n{\"error\":\"boom\",\"error_type\":\"init_error\"}\n"))
+		if got != "init_error" {
+			t.Fatalf("got=%q, want %q", got, "init_error")
+		}
+	})
+
+	t.Run("runtime error without type", func(t *testing.T) {
+		got := detectErrorType([]byte("{\"error\":\"boom\"}"))
+		if got != "" {
+			t.Fatalf("got=%q, want empty", got)
+		}
+	})
+
+	t.Run("plain text", func(t *testing.T) {
+		got := detectErrorType([]byte("Traceback (most recent call last):\n  oops"))
+		if got != "" {
+			t.Fatalf("got=%q, want empty", got)
+		}
+	})
+}
+
+func TestExecCrashed(t *testing.T) {
+	exitErr := func(code string) error {
+		return exec.Command("sh", "-c", "exit "+code).Run()
+	}
+
+	t.Run("user error", func(t *testing.T) {
+		if execCrashed(exitErr("1"), []byte("{\"error\":\"boom\"}")) {
+			t.Fatalf("exit code 1 treated as crash")
+		}
+	})
+
+	t.Run("killed by signal", func(t *testing.T) {
+		if !execCrashed(exitErr("137"), nil) {
+			t.Fatalf("exit code 137 not treated as crash")
+		}
+	})
+
+	t.Run("container exited", func(t *testing.T) {
+		if !execCrashed(exitErr("1"), []byte("Error response from daemon: container abc is not running")) {
+			t.Fatalf("stopped container not treated as crash")
+		}
+	})
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	out := []byte(`##env
+PATH=/usr/local/bin:/usr/bin
+API_KEY=secret
+EMPTY=
+##memlimit
+134217728
+##memtotal
+8048000
+##cpus
+2
+##tmpfs
+65536
+##version
+Python 3.11.9
+`)
+	diag := parseDiagnostics(out)
+
+	if diag.EnvVars["PATH"] != "/usr/local/bin:/usr/bin" {
+		t.Errorf("PATH = %q, want unredacted", diag.EnvVars["PATH"])
+	}
+	if diag.EnvVars["API_KEY"] != redactedValue {
+		t.Errorf("API_KEY = %q, want redacted", diag.EnvVars["API_KEY"])
+	}
+	if v, ok := diag.EnvVars["EMPTY"]; !ok || v != "" {
+		t.Errorf("EMPTY = %q, %v, want empty string", v, ok)
+	}
+	if diag.MemoryLimitMB != 128 {
+		t.Errorf("MemoryLimitMB = %d, want 128", diag.MemoryLimitMB)
+	}
+	if diag.HostMemoryMB != 7859 {
+		t.Errorf("HostMemoryMB = %d, want 7859", diag.HostMemoryMB)
+	}
+	if diag.CPUCount != 2 {
+		t.Errorf("CPUCount = %d, want 2", diag.CPUCount)
+	}
+	if diag.TmpfsSizeMB != 64 {
+		t.Errorf("TmpfsSizeMB = %d, want 64", diag.TmpfsSizeMB)
+	}
+	if diag.RuntimeVersion != "Python 3.11.9" {
+		t.Errorf("RuntimeVersion = %q, want %q", diag.RuntimeVersion, "Python 3.11.9")
+	}
+}
+
+func TestAttachedLayerIDs(t *testing.T) {
+	got := attachedLayerIDs([]domain.RuntimeLayerInfo{
+		{LayerID: "utils", Order: 1},
+		{LayerID: "numpy", Order: 0},
+	})
+	if len(got) != 2 || got[0] != "numpy" || got[1] != "utils" {
+		t.Errorf("attachedLayerIDs() = %v, want [numpy utils]", got)
+	}
+	if got := attachedLayerIDs(nil); got == nil || len(got) != 0 {
+		t.Errorf("attachedLayerIDs(nil) = %#v, want empty slice", got)
+	}
+}
+
+func TestParseDiagnosticsUnlimitedMemory(t *testing.T) {
+	diag := parseDiagnostics([]byte("##memlimit\nmax\n"))
+	if diag.MemoryLimitMB != 0 {
+		t.Errorf("MemoryLimitMB = %d, want 0", diag.MemoryLimitMB)
+	}
+}
+
+func TestAcquireContainerQueueTimeout(t *testing.T) {
+	m := &Manager{
+		poolCfg: config.DockerPoolConfig{MaxTotal: 1, QueueTimeoutSec: 1},
+		pools:   make(map[string]*containerPool),
+		budget:  newCreateBudget(nil, 0),
+	}
+	// 池中已有一个忙碌容器，达到上限
+	pool := m.getPool("python3.11", 128, nil)
+	pool.all["busy"] = &pooledContainer{ID: "busy", Status: "busy"}
+
+	start := time.Now()
+	_, _, err := m.acquireContainer(context.Background(), "python3.11", 128, nil, "image")
+	if !errors.Is(err, domain.ErrQueueTimeout) {
+		t.Fatalf("expected ErrQueueTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("queue timeout took too long: %v", elapsed)
+	}
+}
+
+func TestExpiryReason(t *testing.T) {
+	m := &Manager{poolCfg: config.DockerPoolConfig{MaxInvocations: 10, MaxContainerAge: time.Hour}}
+	pc := &pooledContainer{CreatedAt: time.Now(), UseCount: 3}
+
+	if reason := m.expiryReason(pc, 0); reason != "" {
+		t.Errorf("expiryReason(pool default) = %q, want empty", reason)
+	}
+	if reason := m.expiryReason(pc, 3); reason != recycleMaxReuse {
+		t.Errorf("expiryReason(max_reuse=3) = %q, want %q", reason, recycleMaxReuse)
+	}
+	// 函数级上限高于容器池设置时以容器池为准
+	pc.UseCount = 10
+	if reason := m.expiryReason(pc, 50); reason != recycleMaxInvocations {
+		t.Errorf("expiryReason(max_reuse=50) = %q, want %q", reason, recycleMaxInvocations)
+	}
+	pc.UseCount, pc.CreatedAt = 0, time.Now().Add(-2*time.Hour)
+	if reason := m.expiryReason(pc, 0); reason != recycleMaxAge {
+		t.Errorf("expiryReason(aged) = %q, want %q", reason, recycleMaxAge)
+	}
+}
+
+func TestEvictExpiredWarm(t *testing.T) {
+	m := &Manager{
+		poolCfg: config.DockerPoolConfig{MaxTotal: 4, MaxInvocations: 10, MaxContainerAge: time.Hour},
+		pools:   make(map[string]*containerPool),
+		budget:  newCreateBudget(nil, 0),
+	}
+	pool := m.getPool("python3.11", 128, nil)
+	fresh := &pooledContainer{ID: "fresh", Runtime: "python3.11", MemoryMB: 128, CreatedAt: time.Now(), Status: "warm"}
+	aged := &pooledContainer{ID: "aged", Runtime: "python3.11", MemoryMB: 128, CreatedAt: time.Now().Add(-2 * time.Hour), Status: "warm"}
+	worn := &pooledContainer{ID: "worn", Runtime: "python3.11", MemoryMB: 128, CreatedAt: time.Now(), UseCount: 10, Status: "warm"}
+	for _, pc := range []*pooledContainer{fresh, aged, worn} {
+		pool.all[pc.ID] = pc
+		pool.warm <- pc
+	}
+
+	m.evictExpiredWarm(context.Background(), pool)
+
+	if len(pool.warm) != 1 {
+		t.Fatalf("warm=%d, want 1", len(pool.warm))
+	}
+	if pc := <-pool.warm; pc.ID != "fresh" {
+		t.Errorf("kept %q, want fresh", pc.ID)
+	}
+	if _, ok := pool.all["aged"]; ok {
+		t.Error("aged container should be removed")
+	}
+	if _, ok := pool.all["worn"]; ok {
+		t.Error("worn container should be removed")
+	}
+}
+
+func TestCheckImages(t *testing.T) {
+	available := map[string]bool{"function-runtime-python:latest": true}
+	inspected := make(map[string]int)
+	orig := imageExists
+	imageExists = func(_ context.Context, image string) bool {
+		inspected[image]++
+		return available[image]
+	}
+	defer func() { imageExists = orig }()
+
+	m := &Manager{
+		images: map[string]string{
+			"python3.11": "function-runtime-python:latest",
+			"go1.24":     "function-runtime-go:latest",
+			"rust1.75":   "function-runtime-go:latest",
+		},
+		logger: logrus.New(),
+	}
+
+	missing := m.CheckImages(context.Background())
+	if len(missing) != 2 || missing["go1.24"] != "function-runtime-go:latest" || missing["rust1.75"] == "" {
+		t.Fatalf("missing = %v, want go1.24 and rust1.75", missing)
+	}
+	if inspected["function-runtime-go:latest"] != 1 {
+		t.Errorf("shared image inspected %d times, want 1", inspected["function-runtime-go:latest"])
+	}
+
+	// 镜像拉取后重新检查即恢复
+	available["function-runtime-go:latest"] = true
+	m.CheckImages(context.Background())
+	if got := m.MissingImages(); len(got) != 0 {
+		t.Errorf("MissingImages() = %v, want empty", got)
+	}
+}
+
+func TestReapIdleWarm(t *testing.T) {
+	m := &Manager{
+		poolCfg: config.DockerPoolConfig{
+			Enabled:                true,
+			MaxTotal:               4,
+			WarmIdleTimeout:        time.Minute,
+			RuntimeWarmIdleTimeout: map[string]time.Duration{"nodejs20": 0},
+		},
+		pools:  make(map[string]*containerPool),
+		budget: newCreateBudget(nil, 0),
+		logger: logrus.New(),
+	}
+	if got := m.warmIdleTimeout("nodejs20"); got != 0 {
+		t.Errorf("nodejs20 idle timeout = %v, want 0 (overridden)", got)
+	}
+
+	pool := m.getPool("python3.11", 128, nil)
+	pool.pinned = 1
+	idle := time.Now().Add(-2 * time.Minute)
+	for _, pc := range []*pooledContainer{
+		{ID: "active", Runtime: "python3.11", MemoryMB: 128, LastUsed: time.Now()},
+		{ID: "idle1", Runtime: "python3.11", MemoryMB: 128, LastUsed: idle},
+		{ID: "idle2", Runtime: "python3.11", MemoryMB: 128, LastUsed: idle},
+	} {
+		pool.all[pc.ID] = pc
+		pool.warm <- pc
+	}
+
+	// 3 个预热容器中固定常驻 1 个，最多回收 2 个；只有空闲超时的会被回收
+	if n := m.ReapIdle(context.Background()); n != 2 {
+		t.Fatalf("reaped %d, want 2", n)
+	}
+	if _, ok := pool.all["active"]; !ok {
+		t.Error("active container should be kept")
+	}
+
+	// 剩余容器数等于固定常驻数时不再回收，即使已空闲超时
+	pool.all["active"].LastUsed = idle
+	if n := m.ReapIdle(context.Background()); n != 0 {
+		t.Errorf("reaped %d pinned containers, want 0", n)
+	}
+}
+
+func TestProvisionStats(t *testing.T) {
+	m := &Manager{
+		poolCfg: config.DockerPoolConfig{Enabled: true, MaxTotal: 4},
+		pools:   make(map[string]*containerPool),
+	}
+
+	// 常驻 2 个，预热队列中 3 个容器：2 个常驻、1 个按需，另有 1 个忙碌
+	pool := m.getPool("python3.11", 128, nil)
+	pool.pinned = 2
+	for _, id := range []string{"w1", "w2", "w3", "busy"} {
+		pc := &pooledContainer{ID: id}
+		pool.all[id] = pc
+		if id != "busy" {
+			pool.warm <- pc
+		}
+	}
+	// 只设置了常驻目标、尚未补齐的规格
+	m.getPool("nodejs20", 256, nil).pinned = 1
+	// 空池和挂载数据卷的池不出现
+	m.getPool("go1.24", 128, nil)
+	volPool := m.getPool("python3.11", 128, []string{"models"})
+	volPool.all["v"] = &pooledContainer{ID: "v"}
+
+	stats, ok := m.ProvisionStats()
+	if !ok {
+		t.Fatal("ProvisionStats should be available in pool mode")
+	}
+	want := []domain.ProvisionedPool{
+		{Runtime: "nodejs20", MemoryMB: 256, Provisioned: 1},
+		{Runtime: "python3.11", MemoryMB: 128, Provisioned: 2, ProvisionedWarm: 2, OnDemandWarm: 1, Busy: 1, Total: 4},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("stats = %+v, want %+v", stats, want)
+	}
+	for i := range want {
+		if stats[i] != want[i] {
+			t.Errorf("stats[%d] = %+v, want %+v", i, stats[i], want[i])
+		}
+	}
+
+	m.poolCfg.Enabled = false
+	if _, ok := m.ProvisionStats(); ok {
+		t.Error("ProvisionStats should be unavailable without pool mode")
+	}
+}
+
+func TestPlanAcquire(t *testing.T) {
+	m := &Manager{
+		poolCfg: config.DockerPoolConfig{MaxTotal: 2},
+		pools:   make(map[string]*containerPool),
+		budget:  newCreateBudget(map[string]int{"python3.11": 1}, 0),
+	}
+
+	// 池尚不存在：预演为冷启动，且不创建池、不占用配额
+	if plan := m.PlanAcquire("python3.11", 128, nil); plan.Decision != domain.PoolDecisionCold {
+		t.Fatalf("empty pool decision = %q, want cold", plan.Decision)
+	}
+	if len(m.pools) != 0 {
+		t.Error("PlanAcquire should not create the pool")
+	}
+	if used, _ := m.budget.utilization("python3.11"); used != 0 {
+		t.Errorf("budget used = %d, want 0", used)
+	}
+
+	pool := m.getPool("python3.11", 128, nil)
+	busy := &pooledContainer{ID: "busy", Status: "busy"}
+	warm := &pooledContainer{ID: "warm", Status: "warm"}
+	pool.all[busy.ID] = busy
+	pool.all[warm.ID] = warm
+	pool.warm <- warm
+
+	plan := m.PlanAcquire("python3.11", 128, nil)
+	if plan.Decision != domain.PoolDecisionWarm || plan.WarmInstances != 1 || plan.BusyInstances != 1 {
+		t.Errorf("plan = %+v, want warm with 1 warm / 1 busy", plan)
+	}
+	if len(pool.warm) != 1 {
+		t.Error("PlanAcquire should not take the warm container")
+	}
+
+	// 预热容器被取走后池已满，需要排队
+	<-pool.warm
+	if plan := m.PlanAcquire("python3.11", 128, nil); plan.Decision != domain.PoolDecisionQueue {
+		t.Errorf("full pool decision = %q, want queue", plan.Decision)
+	}
+
+	// 池未满但运行时配额已用尽，同样需要排队
+	delete(pool.all, "warm")
+	m.budget.tryAcquire("python3.11")
+	if plan := m.PlanAcquire("python3.11", 128, nil); plan.Decision != domain.PoolDecisionQueue {
+		t.Errorf("exhausted budget decision = %q, want queue", plan.Decision)
+	}
+}
+
+func TestDataVolumeMounts(t *testing.T) {
+	m := &Manager{
+		poolCfg:     config.DockerPoolConfig{MaxTotal: 2},
+		pools:       make(map[string]*containerPool),
+		dataVolumes: map[string]string{"models": "/srv/models", "refdata": "/srv/refdata"},
+	}
+
+	volumes, err := m.resolveDataVolumes([]string{"refdata", "models", "refdata"})
+	if err != nil {
+		t.Fatalf("resolveDataVolumes() error = %v", err)
+	}
+	if got := poolKey("python3.11", 128, volumes); got != "python3.11:128+models,refdata" {
+		t.Errorf("poolKey() = %q", got)
+	}
+	if got := poolKey("python3.11", 128, nil); got != "python3.11:128" {
+		t.Errorf("poolKey() without volumes = %q", got)
+	}
+
+	mounts, err := m.dataVolumeMounts([]string{"refdata", "models"})
+	if err != nil {
+		t.Fatalf("dataVolumeMounts() error = %v", err)
+	}
+	want := []string{"/srv/models:/opt/data/models:ro", "/srv/refdata:/opt/data/refdata:ro"}
+	if strings.Join(mounts, " ") != strings.Join(want, " ") {
+		t.Errorf("dataVolumeMounts() = %v, want %v", mounts, want)
+	}
+
+	// 未注册的数据卷不允许挂载
+	if _, err := m.dataVolumeMounts([]string{"secrets"}); !errors.Is(err, domain.ErrDataVolumeNotRegistered) {
+		t.Errorf("unregistered volume error = %v, want ErrDataVolumeNotRegistered", err)
+	}
+
+	// 挂载不同数据卷的函数使用不同的容器池
+	if m.getPool("python3.11", 128, volumes) == m.getPool("python3.11", 128, nil) {
+		t.Error("pools with different data volumes should not be shared")
+	}
+}
+
+func TestInstancePin(t *testing.T) {
+	m := &Manager{
+		poolCfg: config.DockerPoolConfig{MaxTotal: 4, MaxInvocations: 10, MaxContainerAge: time.Hour},
+		pools:   make(map[string]*containerPool),
+		budget:  newCreateBudget(nil, 0),
+	}
+	pool := m.getPool("python3.11", 128, nil)
+	pk := poolKey("python3.11", 128, nil)
+	pc := &pooledContainer{ID: "c1", Runtime: "python3.11", MemoryMB: 128, CreatedAt: time.Now(), UseCount: 1, Status: "busy"}
+	pool.all[pc.ID] = pc
+
+	m.holdPinned("exec-1", pk, pc, 0)
+	if got := m.takePinned("exec-2", pk); got != nil {
+		t.Fatalf("takePinned(other key) = %v, want nil", got)
+	}
+	if got := m.takePinned("exec-1", pk); got != pc || pc.UseCount != 2 || pc.Status != "busy" {
+		t.Fatalf("takePinned() = %v (use_count=%d), want pinned container reused", got, pc.UseCount)
+	}
+	if got := m.takePinned("exec-1", pk); got != nil {
+		t.Fatal("pinned container should only be handed out once")
+	}
+
+	m.holdPinned("exec-1", pk, pc, 0)
+	m.ReleaseInstancePin("exec-1")
+	if len(pool.warm) != 1 {
+		t.Fatalf("warm=%d after release, want 1", len(pool.warm))
+	}
+}
+
+func TestLayerCacheEvict(t *testing.T) {
+	dir := t.TempDir()
+	c := newLayerCache(dir, 250)
+	extract := func(dir string) error {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, "lib.py"), make([]byte, 100), 0644)
+	}
+
+	for _, key := range []string{"a", "b", "c"} {
+		if _, hit, err := c.acquire(key, extract); err != nil || hit {
+			t.Fatalf("acquire(%s) hit=%v err=%v, want extracted", key, hit, err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, hit, _ := c.acquire("a", extract); !hit {
+		t.Fatal("acquire(a) should hit the cache")
+	}
+	c.release([]string{"b"})
+	time.Sleep(time.Millisecond)
+	c.release([]string{"a", "a"})
+
+	// c 仍在挂载中不能淘汰；a 最近刚使用，最近最少使用的 b 被淘汰
+	if n, size := c.evict(); n != 1 || size != 200 {
+		t.Fatalf("evict() = %d, %d, want 1, 200", n, size)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b")); !os.IsNotExist(err) {
+		t.Error("layer b should be removed from disk")
+	}
+
+	// 重新加载时登记目录中已存在的层
+	if reloaded := newLayerCache(dir, 250); len(reloaded.entries) != 2 || reloaded.size != 200 {
+		t.Errorf("reloaded entries=%d size=%d, want 2, 200", len(reloaded.entries), reloaded.size)
+	}
+}
+
+func TestBuildImages(t *testing.T) {
+	var mu sync.Mutex
+	builds := make(map[string]int)
+	running, peak := 0, 0
+	orig := buildImage
+	buildImage = func(ctx context.Context, image, dockerfile, contextDir string) ([]byte, error) {
+		mu.Lock()
+		builds[image]++
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+		switch image {
+		case "broken:latest":
+			return []byte("step 3/5: RUN pip install\nerror: no such package"), errors.New("exit status 1")
+		case "hung:latest":
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		time.Sleep(20 * time.Millisecond)
+		return nil, nil
+	}
+	defer func() { buildImage = orig }()
+
+	m := &Manager{
+		images: map[string]string{
+			"python3.11": "python:latest",
+			"nodejs20":   "node:latest",
+			"go1.24":     "go:latest",
+			"rust1.75":   "go:latest",
+			"bun1":       "broken:latest",
+			"wasm":       "hung:latest",
+		},
+		logger: logrus.New(),
+	}
+
+	results, err := m.BuildImages(context.Background(), "/dockerfiles", ImageBuildOptions{Timeout: 100 * time.Millisecond, Concurrency: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 6 {
+		t.Fatalf("got %d results, want 6", len(results))
+	}
+	byRuntime := make(map[string]ImageBuildResult)
+	for _, r := range results {
+		byRuntime[r.Runtime] = r
+	}
+	if !byRuntime["python3.11"].OK || !byRuntime["go1.24"].OK || !byRuntime["rust1.75"].OK {
+		t.Errorf("expected successful builds: %+v", results)
+	}
+	if r := byRuntime["bun1"]; r.OK || !strings.Contains(r.Output, "no such package") {
+		t.Errorf("broken build = %+v", r)
+	}
+	if r := byRuntime["wasm"]; r.OK || !strings.Contains(r.Error, "timed out") {
+		t.Errorf("hung build = %+v", r)
+	}
+	if builds["go:latest"] != 1 {
+		t.Errorf("shared image built %d times, want 1", builds["go:latest"])
+	}
+	if peak > 2 {
+		t.Errorf("peak concurrency %d exceeds limit 2", peak)
+	}
+
+	// 只构建指定的运行时；未配置的运行时直接报错
+	builds = make(map[string]int)
+	if results, err := m.BuildImages(context.Background(), "/dockerfiles", ImageBuildOptions{Runtimes: []string{"nodejs20"}}); err != nil || len(results) != 1 || builds["node:latest"] != 1 {
+		t.Errorf("subset build = %+v, %v", results, err)
+	}
+	if _, err := m.BuildImages(context.Background(), "/dockerfiles", ImageBuildOptions{Runtimes: []string{"cobol"}}); err == nil {
+		t.Error("expected error for unknown runtime")
+	}
+}
+
+func TestRetireContainerStopGrace(t *testing.T) {
+	var mu sync.Mutex
+	var commands []string
+	removed := make(chan string, 2)
+	orig := dockerContainerCommand
+	dockerContainerCommand = func(ctx context.Context, args ...string) error {
+		mu.Lock()
+		commands = append(commands, strings.Join(args, " "))
+		mu.Unlock()
+		if args[0] == "rm" {
+			removed <- args[len(args)-1]
+		}
+		return nil
+	}
+	defer func() { dockerContainerCommand = orig }()
+
+	m := &Manager{logger: logrus.New()}
+
+	// 未声明宽限期：同步强制删除
+	if err := m.retireContainer(context.Background(), &pooledContainer{ID: "abrupt"}); err != nil {
+		t.Fatalf("retireContainer() error = %v", err)
+	}
+	if len(commands) != 1 || commands[0] != "rm -f abrupt" {
+		t.Errorf("commands = %v, want only rm -f", commands)
+	}
+	<-removed
+
+	// 声明了宽限期：后台先 docker stop --time=N，再删除
+	commands = nil
+	if err := m.retireContainer(context.Background(), &pooledContainer{ID: "graceful", StopGraceSec: 10}); err != nil {
+		t.Fatalf("retireContainer() error = %v", err)
+	}
+	select {
+	case <-removed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("graceful container was not removed")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(commands) != 2 || commands[0] != "stop --time 10 graceful" || commands[1] != "rm -f graceful" {
+		t.Errorf("commands = %v, want stop --time 10 then rm -f", commands)
+	}
+}
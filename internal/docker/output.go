@@ -0,0 +1,141 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// outputSubscriberBuffer 是每个订阅者的输出缓冲块数，订阅者消费过慢时丢弃新输出，不阻塞函数执行
+const outputSubscriberBuffer = 256
+
+// outputHub 管理执行中调用的实时输出订阅，按调用 ID 区分。
+// 零值可直接使用。
+type outputHub struct {
+	mu     sync.Mutex
+	active map[string]map[chan domain.ExecOutputChunk]struct{} // 执行中的调用 ID -> 订阅者
+}
+
+// begin 标记调用开始执行，返回执行结束时调用的函数，结束时关闭所有订阅者通道。
+// 调用 ID 为空时不做任何操作。
+func (h *outputHub) begin(id string) func() {
+	if id == "" {
+		return func() {}
+	}
+	h.mu.Lock()
+	if h.active == nil {
+		h.active = make(map[string]map[chan domain.ExecOutputChunk]struct{})
+	}
+	h.active[id] = make(map[chan domain.ExecOutputChunk]struct{})
+	h.mu.Unlock()
+
+	return func() {
+		h.mu.Lock()
+		subs := h.active[id]
+		delete(h.active, id)
+		h.mu.Unlock()
+		for ch := range subs {
+			close(ch)
+		}
+	}
+}
+
+// subscribe 订阅执行中调用的输出，返回输出通道和取消订阅函数。
+// 调用结束时通道被关闭；调用未在本节点执行中时返回 ErrExecOutputUnavailable。
+func (h *outputHub) subscribe(id string) (<-chan domain.ExecOutputChunk, func(), error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	subs, ok := h.active[id]
+	if !ok {
+		return nil, nil, domain.ErrExecOutputUnavailable
+	}
+	ch := make(chan domain.ExecOutputChunk, outputSubscriberBuffer)
+	subs[ch] = struct{}{}
+
+	cancel := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		// 调用已结束时通道已由 begin 返回的函数关闭
+		if subs, ok := h.active[id]; ok {
+			if _, ok := subs[ch]; ok {
+				delete(subs, ch)
+				close(ch)
+			}
+		}
+	}
+	return ch, cancel, nil
+}
+
+// publish 向调用的所有订阅者发布一段输出，订阅者缓冲区已满时丢弃。
+func (h *outputHub) publish(id, stream string, p []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	subs := h.active[id]
+	if len(subs) == 0 {
+		return
+	}
+	chunk := domain.ExecOutputChunk{Stream: stream, Data: string(p), Timestamp: time.Now()}
+	for ch := range subs {
+		select {
+		case ch <- chunk:
+		default:
+		}
+	}
+}
+
+// tap 返回同时写入 w、实时输出订阅者和流式调用输出接收函数的 Writer，
+// context 中既没有调用 ID 也没有输出接收函数时直接返回 w。
+func (h *outputHub) tap(ctx context.Context, stream string, w io.Writer) io.Writer {
+	writers := []io.Writer{w}
+	if id := domain.InvocationIDFromContext(ctx); id != "" {
+		writers = append(writers, &outputWriter{hub: h, id: id, stream: stream})
+	}
+	if sink := domain.OutputSinkFromContext(ctx); sink != nil {
+		writers = append(writers, sinkWriter{sink: sink, stream: stream})
+	}
+	if len(writers) == 1 {
+		return w
+	}
+	return io.MultiWriter(writers...)
+}
+
+// sinkWriter 将写入的内容交给流式调用的输出接收函数。
+type sinkWriter struct {
+	sink   func(domain.ExecOutputChunk)
+	stream string
+}
+
+// Write 实现 io.Writer。
+func (w sinkWriter) Write(p []byte) (int, error) {
+	w.sink(domain.ExecOutputChunk{Stream: w.stream, Data: string(p), Timestamp: time.Now()})
+	return len(p), nil
+}
+
+// outputWriter 将写入的内容发布给调用的订阅者。
+type outputWriter struct {
+	hub    *outputHub
+	id     string
+	stream string
+}
+
+// Write 实现 io.Writer。
+func (w *outputWriter) Write(p []byte) (int, error) {
+	w.hub.publish(w.id, w.stream, p)
+	return len(p), nil
+}
+
+// SubscribeExecOutput 订阅执行中调用的容器实时输出（stdout/stderr）。
+//
+// 参数:
+//   - requestID: 调度器分配的调用 ID
+//
+// 返回值:
+//   - <-chan domain.ExecOutputChunk: 输出通道，调用结束时关闭
+//   - func(): 取消订阅函数
+//   - error: 调用未在本节点执行中时返回 domain.ErrExecOutputUnavailable
+func (m *Manager) SubscribeExecOutput(requestID string) (<-chan domain.ExecOutputChunk, func(), error) {
+	return m.output.subscribe(requestID)
+}
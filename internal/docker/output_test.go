@@ -0,0 +1,32 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+func TestOutputTapForwardsToSink(t *testing.T) {
+	var hub outputHub
+	var buf bytes.Buffer
+	if w := hub.tap(context.Background(), "stdout", &buf); w != &buf {
+		t.Fatal("tap without invocation ID or sink should return the writer unchanged")
+	}
+
+	var chunks []domain.ExecOutputChunk
+	ctx := domain.WithOutputSink(context.Background(), func(c domain.ExecOutputChunk) {
+		chunks = append(chunks, c)
+	})
+	w := hub.tap(ctx, "stdout", &buf)
+	w.Write([]byte("line 1\n"))
+	w.Write([]byte("line 2\n"))
+
+	if buf.String() != "line 1\nline 2\n" {
+		t.Errorf("buffer = %q", buf.String())
+	}
+	if len(chunks) != 2 || chunks[0].Stream != "stdout" || chunks[1].Data != "line 2\n" {
+		t.Errorf("chunks = %+v", chunks)
+	}
+}
@@ -0,0 +1,95 @@
+package docker
+
+import (
+	"context"
+	"time"
+)
+
+// instancePinIdleTimeout 是固定实例在两次调用之间的最长保留时间，超时后归还容器池
+const instancePinIdleTimeout = 10 * time.Second
+
+// instancePin 表示与实例亲和提示绑定、暂不归还容器池的容器。
+type instancePin struct {
+	pc       *pooledContainer
+	poolKey  string      // 容器所属池的键，只有同一个池的调用才能复用
+	maxReuse int         // 最后一次执行的函数配置的复用上限，归还时使用
+	timer    *time.Timer // 空闲超时后归还容器池
+}
+
+// takePinned 取出与亲和提示绑定的容器。
+// 绑定的容器属于其他池（如函数内存规格不同）时将其归还容器池并返回 nil。
+func (m *Manager) takePinned(key, pk string) *pooledContainer {
+	if key == "" {
+		return nil
+	}
+	m.pinMu.Lock()
+	pin, ok := m.pins[key]
+	if ok {
+		delete(m.pins, key)
+	}
+	m.pinMu.Unlock()
+	if !ok || !pin.timer.Stop() {
+		// 未绑定，或空闲超时已触发、容器正在归还
+		return nil
+	}
+	if pin.poolKey != pk {
+		m.releasePin(pin)
+		return nil
+	}
+
+	pin.pc.Status = "busy"
+	pin.pc.LastUsed = time.Now()
+	pin.pc.UseCount++
+	return pin.pc
+}
+
+// holdPinned 将执行完的容器与亲和提示绑定，留给同一提示的下一次调用。
+// 该提示已绑定其他容器（如并行分支同时执行）时直接归还容器池。
+func (m *Manager) holdPinned(key, pk string, pc *pooledContainer, maxReuse int) {
+	pin := &instancePin{pc: pc, poolKey: pk, maxReuse: maxReuse}
+
+	m.pinMu.Lock()
+	if _, exists := m.pins[key]; exists {
+		m.pinMu.Unlock()
+		m.releasePin(pin)
+		return
+	}
+	if m.pins == nil {
+		m.pins = make(map[string]*instancePin)
+	}
+	pc.LastUsed = time.Now()
+	pin.timer = time.AfterFunc(instancePinIdleTimeout, func() {
+		m.pinMu.Lock()
+		if m.pins[key] == pin {
+			delete(m.pins, key)
+		}
+		m.pinMu.Unlock()
+		m.releasePin(pin)
+	})
+	m.pins[key] = pin
+	m.pinMu.Unlock()
+}
+
+// ReleaseInstancePin 立即将与亲和提示绑定的容器归还容器池。
+// 工作流执行结束时调用，未绑定时不做任何操作。
+//
+// 参数:
+//   - key: 实例亲和提示
+func (m *Manager) ReleaseInstancePin(key string) {
+	m.pinMu.Lock()
+	pin, ok := m.pins[key]
+	if ok {
+		delete(m.pins, key)
+	}
+	m.pinMu.Unlock()
+	if ok && pin.timer.Stop() {
+		m.releasePin(pin)
+	}
+}
+
+// releasePin 将绑定的容器归还容器池。
+func (m *Manager) releasePin(pin *instancePin) {
+	if err := m.releaseContainer(context.Background(), pin.pc, "", pin.maxReuse); err != nil {
+		m.logger.WithError(err).WithField("container_id", pin.pc.ID).Warn("Failed to release pinned docker container")
+	}
+}
@@ -0,0 +1,89 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// maxProgressFrameSize 单个进度帧的最大长度，超出的行按普通 stderr 输出处理
+const maxProgressFrameSize = 64 * 1024
+
+// progressWriter 包装函数的 stderr，将以 domain.ProgressFramePrefix 开头的行
+// 解析为进度事件交给 reporter，其余内容原样写入底层缓冲区。
+type progressWriter struct {
+	out      io.Writer
+	reporter domain.ProgressReporter
+	pending  []byte // 尚未遇到换行符的残留数据
+}
+
+// stderrWriter 返回用于接收函数 stderr 的 Writer 和结束时调用的 flush 函数。
+// context 中未设置进度回调时直接返回 stderr 本身。
+func stderrWriter(ctx context.Context, stderr io.Writer) (io.Writer, func()) {
+	reporter := domain.ProgressReporterFromContext(ctx)
+	if reporter == nil {
+		return stderr, func() {}
+	}
+	pw := &progressWriter{out: stderr, reporter: reporter}
+	return pw, pw.flush
+}
+
+// Write 实现 io.Writer，按行拆分并剥离进度帧。
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		idx := bytes.IndexByte(w.pending, '\n')
+		if idx < 0 {
+			break
+		}
+		w.handleLine(w.pending[:idx+1])
+		w.pending = w.pending[idx+1:]
+	}
+	// 过长且没有换行的数据不可能是进度帧，直接输出，避免无限缓冲
+	if len(w.pending) > maxProgressFrameSize {
+		w.out.Write(w.pending)
+		w.pending = nil
+	}
+	return len(p), nil
+}
+
+// flush 处理最后一行不以换行符结尾的残留数据。
+func (w *progressWriter) flush() {
+	if len(w.pending) > 0 {
+		w.handleLine(w.pending)
+		w.pending = nil
+	}
+}
+
+// handleLine 处理一行完整输出：合法的进度帧交给 reporter，其他内容写入底层缓冲区。
+func (w *progressWriter) handleLine(line []byte) {
+	if progress, ok := parseProgressFrame(line); ok {
+		w.reporter(progress)
+		return
+	}
+	w.out.Write(line)
+}
+
+// parseProgressFrame 解析形如 "__NIMBUS_PROGRESS__ {...}" 的进度帧。
+func parseProgressFrame(line []byte) (*domain.InvocationProgress, bool) {
+	trimmed := bytes.TrimRight(line, "\r\n")
+	if !bytes.HasPrefix(trimmed, []byte(domain.ProgressFramePrefix)) {
+		return nil, false
+	}
+	var progress domain.InvocationProgress
+	if err := json.Unmarshal(trimmed[len(domain.ProgressFramePrefix):], &progress); err != nil {
+		return nil, false
+	}
+	if progress.Percent < 0 {
+		progress.Percent = 0
+	}
+	if progress.Percent > 100 {
+		progress.Percent = 100
+	}
+	progress.UpdatedAt = time.Now()
+	return &progress, true
+}
@@ -0,0 +1,75 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+func TestStderrWriterStripsProgressFrames(t *testing.T) {
+	var reported []*domain.InvocationProgress
+	ctx := domain.WithProgressReporter(context.Background(), func(p *domain.InvocationProgress) {
+		reported = append(reported, p)
+	})
+
+	var stderr bytes.Buffer
+	w, flush := stderrWriter(ctx, &stderr)
+	// 帧被拆分到多次写入中，且最后一帧没有换行符
+	w.Write([]byte("warning: slow\n__NIMBUS_PRO"))
+	w.Write([]byte("GRESS__ {\"percent\":40,\"message\":\"step 2\"}\n__NIMBUS_PROGRESS__ not json\n"))
+	w.Write([]byte("__NIMBUS_PROGRESS__ {\"percent\":150}"))
+	flush()
+
+	if got, want := stderr.String(), "warning: slow\n__NIMBUS_PROGRESS__ not json\n"; got != want {
+		t.Errorf("stderr = %q, want %q", got, want)
+	}
+	if len(reported) != 2 {
+		t.Fatalf("reported %d progress events, want 2", len(reported))
+	}
+	if reported[0].Percent != 40 || reported[0].Message != "step 2" {
+		t.Errorf("first progress = %+v", reported[0])
+	}
+	if reported[1].Percent != 100 {
+		t.Errorf("percent should be clamped to 100, got %v", reported[1].Percent)
+	}
+}
+
+func TestStderrWriterWithoutReporter(t *testing.T) {
+	var stderr bytes.Buffer
+	w, flush := stderrWriter(context.Background(), &stderr)
+	if w != &stderr {
+		t.Fatal("expected the stderr buffer to be used directly without a reporter")
+	}
+	flush()
+}
+
+func TestOutputHub(t *testing.T) {
+	var hub outputHub
+	if _, _, err := hub.subscribe("inv-1"); err != domain.ErrExecOutputUnavailable {
+		t.Fatalf("subscribe() before begin error = %v, want ErrExecOutputUnavailable", err)
+	}
+
+	end := hub.begin("inv-1")
+	ch, cancel, err := hub.subscribe("inv-1")
+	if err != nil {
+		t.Fatalf("subscribe() error = %v", err)
+	}
+	defer cancel()
+
+	var stdout bytes.Buffer
+	w := hub.tap(domain.WithInvocationID(context.Background(), "inv-1"), "stdout", &stdout)
+	w.Write([]byte("hello\n"))
+	if stdout.String() != "hello\n" {
+		t.Errorf("tapped writer output = %q", stdout.String())
+	}
+	if chunk := <-ch; chunk.Stream != "stdout" || chunk.Data != "hello\n" {
+		t.Errorf("chunk = %+v", chunk)
+	}
+
+	end()
+	if _, ok := <-ch; ok {
+		t.Error("subscriber channel should be closed when the invocation ends")
+	}
+}
@@ -0,0 +1,58 @@
+package docker
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// DataVolumes 返回已注册的共享数据卷名称，按名称排序。
+func (m *Manager) DataVolumes() []string {
+	names := make([]string, 0, len(m.dataVolumes))
+	for name := range m.dataVolumes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveDataVolumes 检查函数请求的数据卷均已注册，并返回排序去重后的名称，用作容器池键的一部分。
+//
+// 返回值:
+//   - []string: 排序去重后的数据卷名称，未挂载任何数据卷时为 nil
+//   - error: 存在未注册的数据卷时返回包装了 domain.ErrDataVolumeNotRegistered 的错误
+func (m *Manager) resolveDataVolumes(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	seen := make(map[string]struct{}, len(names))
+	volumes := make([]string, 0, len(names))
+	for _, name := range names {
+		if _, ok := m.dataVolumes[name]; !ok {
+			return nil, fmt.Errorf("%w: %s", domain.ErrDataVolumeNotRegistered, name)
+		}
+		if _, dup := seen[name]; dup {
+			continue
+		}
+		seen[name] = struct{}{}
+		volumes = append(volumes, name)
+	}
+	sort.Strings(volumes)
+	return volumes, nil
+}
+
+// dataVolumeMounts 构建共享数据卷的只读挂载参数（docker -v 的值），
+// 每个数据卷挂载到 domain.DataVolumeMountDir/<名称>。
+func (m *Manager) dataVolumeMounts(names []string) ([]string, error) {
+	volumes, err := m.resolveDataVolumes(names)
+	if err != nil {
+		return nil, err
+	}
+	mounts := make([]string, 0, len(volumes))
+	for _, name := range volumes {
+		mounts = append(mounts, fmt.Sprintf("%s:%s:ro", m.dataVolumes[name], path.Join(domain.DataVolumeMountDir, name)))
+	}
+	return mounts, nil
+}
@@ -0,0 +1,64 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了调用产物相关的类型。
+package domain
+
+import (
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 调用产物的限制
+const (
+	// ArtifactOutputDir 是容器内的产物目录，函数成功执行后平台收集其中的文件
+	ArtifactOutputDir = "/output"
+	// MaxInvocationArtifacts 是单次调用收集的产物文件数量上限
+	MaxInvocationArtifacts = 100
+	// MaxArtifactNameLength 是产物名称（相对产物目录的路径）的最大长度
+	MaxArtifactNameLength = 255
+)
+
+// InvocationArtifact 表示函数执行时写入产物目录的一个文件，如生成的报表、缩略图。
+type InvocationArtifact struct {
+	// Name 是文件相对产物目录的路径，以 / 分隔，如 "thumbs/a.png"
+	Name string `json:"name"`
+	// Size 是文件大小（字节）
+	Size int64 `json:"size"`
+	// ContentType 是文件的 MIME 类型，按扩展名或内容推断
+	ContentType string `json:"content_type"`
+	// URL 是下载地址（相对网关根路径）
+	URL string `json:"url"`
+	// CreatedAt 是收集产物的时间
+	CreatedAt time.Time `json:"created_at"`
+	// Data 是文件内容，仅在收集和下载时使用，不出现在 JSON 中
+	Data []byte `json:"-"`
+}
+
+// ValidArtifactName 检查产物名称是否为安全的相对路径：不以 / 开头，不含空段、"." 或 ".." 段，
+// 不含控制字符和反斜杠，长度不超过 MaxArtifactNameLength。
+func ValidArtifactName(name string) bool {
+	if name == "" || len(name) > MaxArtifactNameLength || !utf8.ValidString(name) {
+		return false
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f || r == '\\' {
+			return false
+		}
+	}
+	for _, seg := range strings.Split(name, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// ArtifactURL 返回调用产物的下载地址，名称的每一段单独转义。
+func ArtifactURL(invocationID, name string) string {
+	segs := strings.Split(name, "/")
+	for i, seg := range segs {
+		segs[i] = url.PathEscape(seg)
+	}
+	return "/api/v1/invocations/" + invocationID + "/artifacts/" + strings.Join(segs, "/")
+}
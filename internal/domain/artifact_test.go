@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestArtifactName(t *testing.T) {
+	for _, name := range []string{"report.pdf", "thumbs/a b.png", "日报.csv"} {
+		if !ValidArtifactName(name) {
+			t.Errorf("ValidArtifactName(%q) = false", name)
+		}
+	}
+	for _, name := range []string{"", "/etc/passwd", "../x", "a/../b", "a//b", "a/", "./a", "a\\b", "a\nb", strings.Repeat("x", MaxArtifactNameLength+1)} {
+		if ValidArtifactName(name) {
+			t.Errorf("ValidArtifactName(%q) = true", name)
+		}
+	}
+
+	if got, want := ArtifactURL("inv1", "thumbs/a b.png"), "/api/v1/invocations/inv1/artifacts/thumbs/a%20b.png"; got != want {
+		t.Errorf("ArtifactURL() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,114 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了异步调用结果相关的类型。
+package domain
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// 异步调用结果的状态
+const (
+	// AsyncResultPending 表示调用在队列中等待执行（含函数暂停或维护窗口期间积压的调用）
+	AsyncResultPending = "pending"
+	// AsyncResultRunning 表示调用正在执行
+	AsyncResultRunning = "running"
+	// AsyncResultSucceeded 表示调用执行成功
+	AsyncResultSucceeded = "succeeded"
+	// AsyncResultFailed 表示调用失败、超时、被取消或被跳过
+	AsyncResultFailed = "failed"
+)
+
+// MaxAsyncResultWait 是查询异步调用结果时 wait 参数的上限，低于网关的请求超时
+const MaxAsyncResultWait = 50 * time.Second
+
+// AsyncResult 表示异步调用的结果，由调用记录转换而来。
+// 异步调用返回的 request_id 即调用记录 ID。
+type AsyncResult struct {
+	// RequestID 是异步调用返回的请求 ID
+	RequestID string `json:"request_id"`
+	// FunctionID 是被调用函数的 ID
+	FunctionID string `json:"function_id"`
+	// FunctionName 是被调用函数的名称
+	FunctionName string `json:"function_name"`
+	// Status 是调用状态：pending/running/succeeded/failed
+	Status string `json:"status"`
+	// InvocationStatus 是调用记录的原始状态，用于区分失败、超时、取消和跳过
+	InvocationStatus InvocationStatus `json:"invocation_status"`
+	// Output 是函数的返回结果（调用完成后返回）
+	Output json.RawMessage `json:"output,omitempty"`
+	// Error 是调用失败的原因
+	Error string `json:"error,omitempty"`
+	// DurationMs 是函数执行耗时（毫秒，调用完成后返回）
+	DurationMs int64 `json:"duration_ms,omitempty"`
+	// CreatedAt 是调用被接受的时间
+	CreatedAt time.Time `json:"created_at"`
+	// CompletedAt 是调用完成的时间
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Done 判断调用是否已结束（成功或失败）
+func (r *AsyncResult) Done() bool {
+	return r.Status == AsyncResultSucceeded || r.Status == AsyncResultFailed
+}
+
+// NewAsyncResult 将调用记录转换为异步调用结果，调用结束前不返回输出和耗时。
+//
+// 参数:
+//   - inv: 调用记录
+//
+// 返回值:
+//   - *AsyncResult: 异步调用结果
+func NewAsyncResult(inv *Invocation) *AsyncResult {
+	res := &AsyncResult{
+		RequestID:        inv.ID,
+		FunctionID:       inv.FunctionID,
+		FunctionName:     inv.FunctionName,
+		InvocationStatus: inv.Status,
+		CreatedAt:        inv.CreatedAt,
+	}
+	switch inv.Status {
+	case InvocationStatusPending:
+		res.Status = AsyncResultPending
+		return res
+	case InvocationStatusRunning:
+		res.Status = AsyncResultRunning
+		return res
+	case InvocationStatusSuccess:
+		res.Status = AsyncResultSucceeded
+	default:
+		res.Status = AsyncResultFailed
+		res.Error = inv.Error
+	}
+	res.Output = inv.Output
+	res.DurationMs = inv.DurationMs
+	res.CompletedAt = inv.CompletedAt
+	return res
+}
+
+// ParseAsyncResultWait 解析查询异步调用结果的 wait 参数，支持时长（如 "30s"）或秒数（如 "30"）。
+//
+// 参数:
+//   - s: wait 参数值，为空时不等待
+//
+// 返回值:
+//   - time.Duration: 等待时长
+//   - error: 格式无效、为负数或超过 MaxAsyncResultWait 时返回 ErrInvalidAsyncResultWait
+func ParseAsyncResultWait(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		secs, serr := strconv.Atoi(s)
+		if serr != nil {
+			return 0, ErrInvalidAsyncResultWait
+		}
+		d = time.Duration(secs) * time.Second
+	}
+	if d < 0 || d > MaxAsyncResultWait {
+		return 0, ErrInvalidAsyncResultWait
+	}
+	return d, nil
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestNewAsyncResult(t *testing.T) {
+	inv := NewInvocation("fn-1", "hello", TriggerHTTP, json.RawMessage(`{}`))
+	inv.ID = "req-1"
+
+	res := NewAsyncResult(inv)
+	if res.Status != AsyncResultPending || res.Done() {
+		t.Errorf("pending invocation: status = %q, done = %v", res.Status, res.Done())
+	}
+
+	inv.Start("docker", true)
+	inv.Output = json.RawMessage(`{"partial":true}`)
+	res = NewAsyncResult(inv)
+	if res.Status != AsyncResultRunning || res.Output != nil {
+		t.Errorf("running invocation: status = %q, output = %s", res.Status, res.Output)
+	}
+
+	inv.Complete(json.RawMessage(`{"ok":true}`), 0)
+	res = NewAsyncResult(inv)
+	if res.Status != AsyncResultSucceeded || !res.Done() || string(res.Output) != `{"ok":true}` || res.CompletedAt == nil {
+		t.Errorf("succeeded invocation: %+v", res)
+	}
+
+	for _, status := range []InvocationStatus{InvocationStatusFailed, InvocationStatusTimeout, InvocationStatusCancelled, InvocationStatusSkipped} {
+		inv.Status = status
+		inv.Error = "boom"
+		res = NewAsyncResult(inv)
+		if res.Status != AsyncResultFailed || res.InvocationStatus != status || res.Error != "boom" {
+			t.Errorf("%s invocation: status = %q, invocation_status = %q, error = %q", status, res.Status, res.InvocationStatus, res.Error)
+		}
+	}
+}
+
+func TestParseAsyncResultWait(t *testing.T) {
+	valid := map[string]time.Duration{
+		"":      0,
+		"30s":   30 * time.Second,
+		"30":    30 * time.Second,
+		"500ms": 500 * time.Millisecond,
+		"50s":   MaxAsyncResultWait,
+	}
+	for in, want := range valid {
+		got, err := ParseAsyncResultWait(in)
+		if err != nil || got != want {
+			t.Errorf("ParseAsyncResultWait(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"soon", "-1s", "-5", "51s", "2m"} {
+		if _, err := ParseAsyncResultWait(in); err != ErrInvalidAsyncResultWait {
+			t.Errorf("ParseAsyncResultWait(%q) error = %v, want ErrInvalidAsyncResultWait", in, err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了批量调用相关的类型。
+package domain
+
+import "encoding/json"
+
+// 批量调用的数量与并发限制
+const (
+	// MaxBatchInvokePayloads 是单次批量调用的载荷数量上限
+	MaxBatchInvokePayloads = 100
+	// DefaultBatchInvokeParallel 是未指定 max_parallel 时同时执行的调用数
+	DefaultBatchInvokeParallel = 10
+	// MaxBatchInvokeParallel 是 max_parallel 的上限
+	MaxBatchInvokeParallel = 50
+)
+
+// 批量调用中单项的状态
+const (
+	// BatchItemSucceeded 表示调用成功
+	BatchItemSucceeded = "succeeded"
+	// BatchItemFailed 表示调用失败（调度错误、函数错误或非 2xx 状态码）
+	BatchItemFailed = "failed"
+	// BatchItemSkipped 表示调用未开始（fail_fast 触发或请求已取消）
+	BatchItemSkipped = "skipped"
+	// BatchItemCancelled 表示请求取消时调用仍在执行，结果未返回，调用继续执行并照常记录
+	BatchItemCancelled = "cancelled"
+)
+
+// 批量调用提前结束的原因
+const (
+	// BatchStoppedFailFast 表示开启 fail_fast 后有调用失败
+	BatchStoppedFailFast = "fail_fast"
+	// BatchStoppedCancelled 表示请求上下文已取消（客户端断开或网关请求超时）
+	BatchStoppedCancelled = "cancelled"
+)
+
+// BatchInvokeRequest 表示用多个载荷批量同步调用同一函数的请求。
+type BatchInvokeRequest struct {
+	// Payloads 是各次调用的输入载荷，按顺序对应响应中的结果
+	Payloads []json.RawMessage `json:"payloads"`
+	// MaxParallel 是同时执行的调用数上限，0 表示使用 DefaultBatchInvokeParallel
+	MaxParallel int `json:"max_parallel,omitempty"`
+	// FailFast 为 true 时第一个调用失败后不再发起新的调用，尚未开始的调用标记为 skipped
+	FailFast bool `json:"fail_fast,omitempty"`
+}
+
+// Normalize 校验批量调用请求并填充默认并发数。
+//
+// 返回值:
+//   - error: 载荷数量或并发数超出范围时返回 ErrInvalidBatchInvoke
+func (r *BatchInvokeRequest) Normalize() error {
+	if len(r.Payloads) == 0 || len(r.Payloads) > MaxBatchInvokePayloads {
+		return ErrInvalidBatchInvoke
+	}
+	if r.MaxParallel < 0 || r.MaxParallel > MaxBatchInvokeParallel {
+		return ErrInvalidBatchInvoke
+	}
+	if r.MaxParallel == 0 {
+		r.MaxParallel = DefaultBatchInvokeParallel
+	}
+	if r.MaxParallel > len(r.Payloads) {
+		r.MaxParallel = len(r.Payloads)
+	}
+	return nil
+}
+
+// BatchInvokeResult 表示批量调用中单个载荷的调用结果。
+type BatchInvokeResult struct {
+	// Index 是载荷在请求 payloads 中的下标
+	Index int `json:"index"`
+	// Status 是调用状态：succeeded/failed/skipped/cancelled
+	Status string `json:"status"`
+	// RequestID 是调用记录 ID，调用未完成时为空
+	RequestID string `json:"request_id,omitempty"`
+	// StatusCode 是函数执行返回的状态码，调用未完成时为 0
+	StatusCode int `json:"status_code,omitempty"`
+	// Body 是函数的返回结果
+	Body json.RawMessage `json:"body,omitempty"`
+	// Error 是调用失败、跳过或取消的原因
+	Error string `json:"error,omitempty"`
+	// DurationMs 是调用耗时（毫秒），调用完成时为函数执行耗时，否则为等待的时长
+	DurationMs int64 `json:"duration_ms"`
+}
+
+// BatchInvokeResponse 表示批量调用的结果。
+type BatchInvokeResponse struct {
+	// Results 是各载荷的调用结果，顺序与请求的 payloads 一致
+	Results []BatchInvokeResult `json:"results"`
+	// Succeeded 是成功的调用数
+	Succeeded int `json:"succeeded"`
+	// Failed 是失败的调用数
+	Failed int `json:"failed"`
+	// Skipped 是未开始的调用数
+	Skipped int `json:"skipped"`
+	// Cancelled 是请求取消时仍在执行、未返回结果的调用数
+	Cancelled int `json:"cancelled"`
+	// StoppedReason 是批量调用提前结束的原因（fail_fast/cancelled），全部执行完时为空
+	StoppedReason string `json:"stopped_reason,omitempty"`
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBatchInvokeRequestNormalize(t *testing.T) {
+	req := &BatchInvokeRequest{Payloads: []json.RawMessage{json.RawMessage(`{}`), json.RawMessage(`{"n":1}`)}}
+	if err := req.Normalize(); err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	// 并发数不超过载荷数
+	if req.MaxParallel != 2 {
+		t.Errorf("MaxParallel = %d, want 2", req.MaxParallel)
+	}
+
+	tooMany := make([]json.RawMessage, MaxBatchInvokePayloads+1)
+	for _, bad := range []*BatchInvokeRequest{
+		{},
+		{Payloads: tooMany},
+		{Payloads: []json.RawMessage{json.RawMessage(`{}`)}, MaxParallel: -1},
+		{Payloads: []json.RawMessage{json.RawMessage(`{}`)}, MaxParallel: MaxBatchInvokeParallel + 1},
+	} {
+		if err := bad.Normalize(); err != ErrInvalidBatchInvoke {
+			t.Errorf("Normalize(%d payloads, max_parallel=%d) error = %v, want ErrInvalidBatchInvoke", len(bad.Payloads), bad.MaxParallel, err)
+		}
+	}
+}
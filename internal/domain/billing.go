@@ -0,0 +1,113 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了计费汇总相关的类型。
+package domain
+
+import "time"
+
+// BillingGroupByDay 是计费汇总按天分组的取值
+const BillingGroupByDay = "day"
+
+// BillingRates 是计费单价，均为 0 时不计算费用。
+type BillingRates struct {
+	// PricePerGBSecond 是每 GB-秒的单价
+	PricePerGBSecond float64 `json:"price_per_gb_second"`
+	// PricePerRequest 是每次调用的单价
+	PricePerRequest float64 `json:"price_per_request"`
+	// Currency 是货币单位
+	Currency string `json:"currency,omitempty"`
+}
+
+// Configured 判断是否配置了单价。
+func (r BillingRates) Configured() bool {
+	return r.PricePerGBSecond > 0 || r.PricePerRequest > 0
+}
+
+// BillingUsage 是一段时间内的调用用量。
+type BillingUsage struct {
+	// Date 是所属日期（UTC，YYYY-MM-DD），仅按天分组时设置
+	Date string `json:"date,omitempty"`
+	// Invocations 是调用次数
+	Invocations int64 `json:"invocations"`
+	// BilledTimeMs 是累计计费时长（单位：毫秒）
+	BilledTimeMs int64 `json:"billed_time_ms"`
+	// GBSeconds 是累计 GB-秒（计费秒数 × 内存 GB）
+	GBSeconds float64 `json:"gb_seconds"`
+	// AvgDurationMs 是已完成调用（success/failed/timeout）的平均执行时长（毫秒）
+	AvgDurationMs float64 `json:"avg_duration_ms"`
+	// Cost 是按单价计算的费用，未配置单价时省略
+	Cost *float64 `json:"cost,omitempty"`
+}
+
+// FunctionBillingSummary 是单个函数在一段时间内的计费汇总。
+type FunctionBillingSummary struct {
+	// FunctionID 是函数 ID
+	FunctionID string `json:"function_id"`
+	// FunctionName 是函数名称
+	FunctionName string `json:"function_name"`
+	// Period 是统计时间段（如 "30d"）
+	Period string `json:"period"`
+	// Since 是统计时间段的起点
+	Since time.Time `json:"since"`
+	// MemoryMB 是计算 GB-秒使用的内存大小（函数当前配置）
+	MemoryMB int `json:"memory_mb"`
+	// Total 是整个时间段的用量
+	Total BillingUsage `json:"total"`
+	// Days 是按天的用量（UTC），没有调用的日期补零，仅按天分组时返回
+	Days []BillingUsage `json:"days,omitempty"`
+	// Rates 是计算费用使用的单价，未配置单价时省略
+	Rates *BillingRates `json:"rates,omitempty"`
+}
+
+// GBSeconds 将计费时长（毫秒）按内存大小折算为 GB-秒。
+func GBSeconds(billedTimeMs int64, memoryMB int) float64 {
+	return float64(billedTimeMs) / 1000 * float64(memoryMB) / 1024
+}
+
+// ApplyRates 按内存大小计算汇总和每天的 GB-秒，配置了单价时计算费用。
+//
+// 参数:
+//   - rates: 计费单价
+func (s *FunctionBillingSummary) ApplyRates(rates BillingRates) {
+	apply := func(u *BillingUsage) {
+		u.GBSeconds = GBSeconds(u.BilledTimeMs, s.MemoryMB)
+		if rates.Configured() {
+			cost := u.GBSeconds*rates.PricePerGBSecond + float64(u.Invocations)*rates.PricePerRequest
+			u.Cost = &cost
+		}
+	}
+	apply(&s.Total)
+	for i := range s.Days {
+		apply(&s.Days[i])
+	}
+	if rates.Configured() {
+		s.Rates = &rates
+	}
+}
+
+// FillBillingDays 按日期补全按天用量，返回从 since 到 until（UTC，含首尾日期）每天一条的有序列表。
+//
+// 参数:
+//   - days: 有调用的日期的用量，Date 为 YYYY-MM-DD
+//   - since, until: 统计时间段
+//
+// 返回值:
+//   - []BillingUsage: 补零后的按天用量
+func FillBillingDays(days []BillingUsage, since, until time.Time) []BillingUsage {
+	byDate := make(map[string]BillingUsage, len(days))
+	for _, d := range days {
+		byDate[d.Date] = d
+	}
+
+	start := since.UTC().Truncate(24 * time.Hour)
+	end := until.UTC().Truncate(24 * time.Hour)
+	filled := make([]BillingUsage, 0, int(end.Sub(start)/(24*time.Hour))+1)
+	for day := start; !day.After(end); day = day.Add(24 * time.Hour) {
+		date := day.Format("2006-01-02")
+		u, ok := byDate[date]
+		if !ok {
+			u = BillingUsage{Date: date}
+		}
+		filled = append(filled, u)
+	}
+	return filled
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFunctionBillingSummary(t *testing.T) {
+	since := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 3, 4, 6, 0, 0, 0, time.UTC)
+	days := FillBillingDays([]BillingUsage{{Date: "2026-03-03", Invocations: 4, BilledTimeMs: 2000}}, since, until)
+	if len(days) != 4 || days[0].Date != "2026-03-01" || days[3].Date != "2026-03-04" {
+		t.Fatalf("days = %+v, want 2026-03-01..2026-03-04", days)
+	}
+	if days[2].Invocations != 4 || days[1].Invocations != 0 {
+		t.Errorf("days not filled in place: %+v", days)
+	}
+
+	s := &FunctionBillingSummary{MemoryMB: 512, Total: BillingUsage{Invocations: 4, BilledTimeMs: 2000}, Days: days}
+	s.ApplyRates(BillingRates{})
+	if s.Total.GBSeconds != 1 || s.Total.Cost != nil || s.Rates != nil {
+		t.Errorf("without rates: total = %+v, rates = %v", s.Total, s.Rates)
+	}
+
+	s.ApplyRates(BillingRates{PricePerGBSecond: 0.5, PricePerRequest: 0.25})
+	if s.Total.Cost == nil || *s.Total.Cost != 1.5 {
+		t.Errorf("total cost = %v, want 1.5", s.Total.Cost)
+	}
+	if s.Days[0].Cost == nil || *s.Days[0].Cost != 0 || *s.Days[2].Cost != 1.5 {
+		t.Errorf("daily cost not applied: %+v", s.Days)
+	}
+}
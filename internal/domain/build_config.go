@@ -0,0 +1,91 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了编译参数相关的类型。
+package domain
+
+import (
+	"regexp"
+	"strings"
+)
+
+// 编译参数数量和长度上限
+const (
+	// MaxBuildEnvVars 是单个函数允许的编译环境变量数量上限
+	MaxBuildEnvVars = 32
+	// MaxBuildArgs 是单个函数允许的编译参数数量上限
+	MaxBuildArgs = 16
+	// MaxBuildValueLength 是单个编译环境变量值或编译参数的最大长度
+	MaxBuildValueLength = 1024
+)
+
+// BuildEnvUserPrefix 是任何编译型运行时都允许的自定义编译环境变量前缀
+const BuildEnvUserPrefix = "BUILD_"
+
+// buildEnvNamePattern 是编译环境变量名的合法格式
+var buildEnvNamePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// buildEnvAllowlist 按运行时列出允许的编译环境变量，以 "_" 结尾的条目按前缀匹配。
+// GOOS、GOARCH、CGO_ENABLED 等决定产物能否在平台上运行的变量由平台控制，不允许覆盖。
+var buildEnvAllowlist = map[Runtime][]string{
+	RuntimeGo124: {"GOFLAGS", "GOEXPERIMENT", "GOPROXY", "GOPRIVATE", "GONOPROXY", "GONOSUMDB", "GOAMD64", "GOARM64"},
+	// Rust 代码可通过 env!("CARGO_PKG_VERSION") 等宏在编译期读取
+	RuntimeWasm: {"CARGO_"},
+}
+
+// buildArgAllowlist 按运行时列出允许的编译参数，以 "=" 结尾的条目按前缀匹配，其余须完全相同。
+// 只允许影响链接变量、构建标签和条件编译的参数，不允许修改输出路径和目标平台。
+var buildArgAllowlist = map[Runtime][]string{
+	RuntimeGo124: {"-ldflags=", "-tags=", "-trimpath"},
+	RuntimeWasm:  {"--cfg="},
+}
+
+// ValidateBuildConfig 验证编译环境变量和编译参数。
+// 只有编译型运行时（go1.24、wasm）可以配置，变量名和参数须在运行时的白名单内，
+// 以 BUILD_ 开头的自定义变量在所有编译型运行时都允许。
+//
+// 参数:
+//   - runtime: 函数运行时
+//   - env: 编译环境变量
+//   - args: 编译参数
+//
+// 返回值:
+//   - error: 配置不合法时返回 ErrInvalidBuildConfig
+func ValidateBuildConfig(runtime Runtime, env map[string]string, args []string) error {
+	if len(env) == 0 && len(args) == 0 {
+		return nil
+	}
+	envAllow, compiled := buildEnvAllowlist[runtime]
+	if !compiled || len(env) > MaxBuildEnvVars || len(args) > MaxBuildArgs {
+		return ErrInvalidBuildConfig
+	}
+	envAllow = append([]string{BuildEnvUserPrefix}, envAllow...)
+	for key, value := range env {
+		if !buildEnvNamePattern.MatchString(key) || !buildAllowed(key, envAllow, "_") || !validBuildValue(value) {
+			return ErrInvalidBuildConfig
+		}
+	}
+	for _, arg := range args {
+		if !buildAllowed(arg, buildArgAllowlist[runtime], "=") || !validBuildValue(arg) {
+			return ErrInvalidBuildConfig
+		}
+	}
+	return nil
+}
+
+// validBuildValue 检查编译环境变量值或编译参数的长度，且不包含换行等控制字符。
+func validBuildValue(v string) bool {
+	return len(v) <= MaxBuildValueLength && !strings.ContainsAny(v, "\r\n\x00")
+}
+
+// buildAllowed 检查值是否命中白名单：以 suffix 结尾的条目按前缀匹配，其余须完全相同。
+func buildAllowed(value string, allowlist []string, suffix string) bool {
+	for _, allowed := range allowlist {
+		if strings.HasSuffix(allowed, suffix) {
+			if strings.HasPrefix(value, allowed) && len(value) > len(allowed) {
+				return true
+			}
+		} else if value == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"testing"
+)
+
+// TestValidateBuildConfig 测试编译参数白名单：只允许编译型运行时，变量和参数须命中运行时白名单。
+func TestValidateBuildConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		runtime Runtime
+		env     map[string]string
+		args    []string
+		wantErr bool
+	}{
+		{name: "empty on interpreted runtime", runtime: RuntimePython311},
+		{name: "go ldflags and tags", runtime: RuntimeGo124, env: map[string]string{"GOFLAGS": "-mod=mod", "BUILD_CHANNEL": "beta"}, args: []string{"-ldflags=-X main.version=1.2.0", "-tags=prod", "-trimpath"}},
+		{name: "wasm cargo vars and cfg", runtime: RuntimeWasm, env: map[string]string{"CARGO_PKG_VERSION": "1.2.0"}, args: []string{`--cfg=feature="fast"`}},
+		{name: "interpreted runtime", runtime: RuntimeNodeJS20, env: map[string]string{"BUILD_X": "1"}, wantErr: true},
+		{name: "platform controlled variable", runtime: RuntimeGo124, env: map[string]string{"GOARCH": "386"}, wantErr: true},
+		{name: "bare prefix", runtime: RuntimeWasm, env: map[string]string{"CARGO_": "x"}, wantErr: true},
+		{name: "lowercase name", runtime: RuntimeGo124, env: map[string]string{"BUILD_x": "1"}, wantErr: true},
+		{name: "output flag", runtime: RuntimeGo124, args: []string{"-o=/tmp/x"}, wantErr: true},
+		{name: "go flag on wasm", runtime: RuntimeWasm, args: []string{"-trimpath"}, wantErr: true},
+		{name: "newline in value", runtime: RuntimeGo124, env: map[string]string{"BUILD_X": "a\nb"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBuildConfig(tt.runtime, tt.env, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateBuildConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
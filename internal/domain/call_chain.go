@@ -0,0 +1,110 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了调用链相关的类型。
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// HeaderCallChain 是函数嵌套调用时传递调用链的请求头，值为逗号分隔的函数 ID，从根调用开始依次排列
+const HeaderCallChain = "X-Nimbus-Call-Chain"
+
+// EnvCallChain 是注入函数环境的调用链（包括当前函数），函数调用其他函数时应原样放入 X-Nimbus-Call-Chain 请求头
+const EnvCallChain = "NIMBUS_CALL_CHAIN"
+
+// 递归拒绝原因
+const (
+	// RecursionReasonDepth 表示调用链超出最大深度
+	RecursionReasonDepth = "depth"
+	// RecursionReasonCycle 表示同一函数在调用链中出现次数过多
+	RecursionReasonCycle = "cycle"
+)
+
+// ParseCallChain 解析 X-Nimbus-Call-Chain 请求头，忽略空项。
+//
+// 参数:
+//   - header: 请求头的值，如 "fn-a,fn-b"
+//
+// 返回值:
+//   - []string: 从根调用开始的上游函数 ID，请求头为空时返回 nil
+func ParseCallChain(header string) []string {
+	var chain []string
+	for _, id := range strings.Split(header, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			chain = append(chain, id)
+		}
+	}
+	return chain
+}
+
+// RecursionError 表示调用因疑似无限递归被拒绝。
+// 可通过 errors.Is(err, ErrRecursionLimitExceeded) 判断。
+type RecursionError struct {
+	// Reason 是拒绝原因：depth 或 cycle
+	Reason string
+	// FunctionID 是被拒绝调用的函数 ID
+	FunctionID string
+	// Count 是调用链深度（depth）或该函数在调用链中出现的次数（cycle），包括本次调用
+	Count int
+	// Limit 是对应的上限
+	Limit int
+}
+
+// Error 实现 error 接口。
+func (e *RecursionError) Error() string {
+	if e.Reason == RecursionReasonCycle {
+		return fmt.Sprintf("%s: function %s appears %d times in the call chain (limit %d)",
+			ErrRecursionLimitExceeded.Error(), e.FunctionID, e.Count, e.Limit)
+	}
+	return fmt.Sprintf("%s: call depth %d exceeds limit %d", ErrRecursionLimitExceeded.Error(), e.Count, e.Limit)
+}
+
+// Is 使 errors.Is(err, ErrRecursionLimitExceeded) 返回 true。
+func (e *RecursionError) Is(target error) bool {
+	return target == ErrRecursionLimitExceeded
+}
+
+// CheckRecursion 检查以 chain 为上游调用链调用函数时是否疑似无限递归。
+//
+// 参数:
+//   - chain: 上游函数 ID，从根调用开始依次排列
+//   - functionID: 本次调用的函数 ID
+//   - maxDepth: 调用链最大深度（根调用为 1），小于等于 0 表示不限制
+//   - maxRepeats: 同一函数在调用链中最多出现的次数，小于等于 0 表示不限制
+//
+// 返回值:
+//   - error: 超出限制时返回 *RecursionError，否则返回 nil
+func CheckRecursion(chain []string, functionID string, maxDepth, maxRepeats int) error {
+	if depth := len(chain) + 1; maxDepth > 0 && depth > maxDepth {
+		return &RecursionError{Reason: RecursionReasonDepth, FunctionID: functionID, Count: depth, Limit: maxDepth}
+	}
+	if maxRepeats <= 0 {
+		return nil
+	}
+	repeats := 1
+	for _, id := range chain {
+		if id == functionID {
+			repeats++
+		}
+	}
+	if repeats > maxRepeats {
+		return &RecursionError{Reason: RecursionReasonCycle, FunctionID: functionID, Count: repeats, Limit: maxRepeats}
+	}
+	return nil
+}
+
+// callChainKey 是调用链在 context 中的键
+type callChainKey struct{}
+
+// WithCallChain 返回携带调用链（包括当前函数）的 context，执行器据此向函数环境注入 NIMBUS_CALL_CHAIN。
+func WithCallChain(ctx context.Context, chain []string) context.Context {
+	return context.WithValue(ctx, callChainKey{}, chain)
+}
+
+// CallChainFromContext 从 context 中取出调用链，未设置时返回 nil。
+func CallChainFromContext(ctx context.Context) []string {
+	chain, _ := ctx.Value(callChainKey{}).([]string)
+	return chain
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckRecursion(t *testing.T) {
+	chain := ParseCallChain(" a, b ,,a ")
+	if len(chain) != 3 || chain[0] != "a" || chain[2] != "a" {
+		t.Fatalf("ParseCallChain = %v", chain)
+	}
+
+	if err := CheckRecursion(chain, "c", 4, 3); err != nil {
+		t.Errorf("CheckRecursion within limits = %v, want nil", err)
+	}
+
+	// 调用链深度为 4，超出上限 3
+	err := CheckRecursion(chain, "c", 3, 0)
+	var rerr *RecursionError
+	if !errors.As(err, &rerr) || rerr.Reason != RecursionReasonDepth || rerr.Count != 4 {
+		t.Errorf("CheckRecursion depth = %v, want depth error with count 4", err)
+	}
+
+	// 函数 a 第三次出现，超出上限 2
+	err = CheckRecursion(chain, "a", 0, 2)
+	if !errors.Is(err, ErrRecursionLimitExceeded) || !errors.As(err, &rerr) || rerr.Reason != RecursionReasonCycle || rerr.Count != 3 {
+		t.Errorf("CheckRecursion cycle = %v, want cycle error with count 3", err)
+	}
+
+	// 上限小于等于 0 时不限制
+	if err := CheckRecursion(chain, "a", 0, 0); err != nil {
+		t.Errorf("CheckRecursion disabled = %v, want nil", err)
+	}
+}
@@ -0,0 +1,168 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了版本对比相关的类型。
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// 金丝雀分析结论
+const (
+	// CanaryVerdictPass 表示候选版本在所有阈值内，可以推广
+	CanaryVerdictPass = "pass"
+	// CanaryVerdictFail 表示候选版本超出至少一个阈值
+	CanaryVerdictFail = "fail"
+	// CanaryVerdictInconclusive 表示样本不足，无法得出结论
+	CanaryVerdictInconclusive = "inconclusive"
+)
+
+// 金丝雀分析的默认阈值
+const (
+	// DefaultCanaryMaxErrorRateDelta 是候选版本错误率比基线版本高出的最大百分点
+	DefaultCanaryMaxErrorRateDelta = 1.0
+	// DefaultCanaryMaxP95IncreasePct 是候选版本 P95 延迟比基线版本增加的最大百分比
+	DefaultCanaryMaxP95IncreasePct = 20.0
+	// DefaultCanaryMinInvocations 是每个版本得出结论所需的最少调用数
+	DefaultCanaryMinInvocations = 20
+)
+
+// CanaryThresholds 是金丝雀分析的判定阈值。
+type CanaryThresholds struct {
+	// MaxErrorRateDelta 是候选版本错误率比基线版本高出的最大百分点
+	MaxErrorRateDelta float64 `json:"max_error_rate_delta"`
+	// MaxP95IncreasePct 是候选版本 P95 延迟比基线版本增加的最大百分比
+	MaxP95IncreasePct float64 `json:"max_p95_increase_pct"`
+	// MinInvocations 是每个版本得出结论所需的最少调用数
+	MinInvocations int64 `json:"min_invocations"`
+}
+
+// DefaultCanaryThresholds 返回金丝雀分析的默认阈值。
+func DefaultCanaryThresholds() CanaryThresholds {
+	return CanaryThresholds{
+		MaxErrorRateDelta: DefaultCanaryMaxErrorRateDelta,
+		MaxP95IncreasePct: DefaultCanaryMaxP95IncreasePct,
+		MinInvocations:    DefaultCanaryMinInvocations,
+	}
+}
+
+// VersionStats 是一个函数版本在统计时间段内已完成调用的统计。
+type VersionStats struct {
+	// Version 是函数版本号
+	Version int `json:"version"`
+	// Invocations 是已完成的调用数（success/failed/timeout）
+	Invocations int64 `json:"invocations"`
+	// SuccessCount 是成功调用数
+	SuccessCount int64 `json:"success_count"`
+	// FailedCount 是失败调用数
+	FailedCount int64 `json:"failed_count"`
+	// TimeoutCount 是超时调用数
+	TimeoutCount int64 `json:"timeout_count"`
+	// ErrorRate 是失败和超时调用的占比（百分比）
+	ErrorRate float64 `json:"error_rate"`
+	// AvgLatencyMs 是平均执行时长（毫秒）
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+	// P50LatencyMs 是执行时长的 P50（毫秒）
+	P50LatencyMs float64 `json:"p50_latency_ms"`
+	// P95LatencyMs 是执行时长的 P95（毫秒）
+	P95LatencyMs float64 `json:"p95_latency_ms"`
+	// P99LatencyMs 是执行时长的 P99（毫秒）
+	P99LatencyMs float64 `json:"p99_latency_ms"`
+}
+
+// VersionStatsDelta 是候选版本相对基线版本的指标差值（候选 - 基线）。
+type VersionStatsDelta struct {
+	// ErrorRate 是错误率差值（百分点）
+	ErrorRate float64 `json:"error_rate"`
+	// AvgLatencyMs 是平均执行时长差值（毫秒）
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+	// P50LatencyMs 是 P50 差值（毫秒）
+	P50LatencyMs float64 `json:"p50_latency_ms"`
+	// P95LatencyMs 是 P95 差值（毫秒）
+	P95LatencyMs float64 `json:"p95_latency_ms"`
+	// P99LatencyMs 是 P99 差值（毫秒）
+	P99LatencyMs float64 `json:"p99_latency_ms"`
+	// P95IncreasePct 是 P95 相对基线版本的变化百分比，基线 P95 为 0 时为 0
+	P95IncreasePct float64 `json:"p95_increase_pct"`
+}
+
+// CanaryReport 是两个函数版本的对比报告及推广结论。
+type CanaryReport struct {
+	// FunctionID 是函数 ID
+	FunctionID string `json:"function_id"`
+	// FunctionName 是函数名称
+	FunctionName string `json:"function_name"`
+	// Period 是统计时间段（如 "1h"）
+	Period string `json:"period"`
+	// Since 是统计时间段的起点
+	Since time.Time `json:"since"`
+	// Baseline 是基线版本的统计
+	Baseline VersionStats `json:"baseline"`
+	// Candidate 是候选版本的统计
+	Candidate VersionStats `json:"candidate"`
+	// Delta 是候选版本相对基线版本的差值
+	Delta VersionStatsDelta `json:"delta"`
+	// Thresholds 是判定使用的阈值
+	Thresholds CanaryThresholds `json:"thresholds"`
+	// Verdict 是结论：pass、fail 或 inconclusive
+	Verdict string `json:"verdict"`
+	// Reasons 是得出 fail 或 inconclusive 结论的原因
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// NewCanaryReport 对比基线版本和候选版本的统计，按阈值得出推广结论。
+// 任一版本的调用数少于 MinInvocations 时结论为 inconclusive；
+// 否则错误率差值或 P95 增幅超出阈值时为 fail，都在阈值内时为 pass。
+//
+// 参数:
+//   - fn: 函数定义
+//   - baseline: 基线版本的统计
+//   - candidate: 候选版本的统计
+//   - thresholds: 判定阈值
+//
+// 返回值:
+//   - *CanaryReport: 对比报告（Period 和 Since 由调用方填充）
+func NewCanaryReport(fn *Function, baseline, candidate VersionStats, thresholds CanaryThresholds) *CanaryReport {
+	report := &CanaryReport{
+		FunctionID:   fn.ID,
+		FunctionName: fn.Name,
+		Baseline:     baseline,
+		Candidate:    candidate,
+		Delta: VersionStatsDelta{
+			ErrorRate:    candidate.ErrorRate - baseline.ErrorRate,
+			AvgLatencyMs: candidate.AvgLatencyMs - baseline.AvgLatencyMs,
+			P50LatencyMs: candidate.P50LatencyMs - baseline.P50LatencyMs,
+			P95LatencyMs: candidate.P95LatencyMs - baseline.P95LatencyMs,
+			P99LatencyMs: candidate.P99LatencyMs - baseline.P99LatencyMs,
+		},
+		Thresholds: thresholds,
+	}
+	if baseline.P95LatencyMs > 0 {
+		report.Delta.P95IncreasePct = report.Delta.P95LatencyMs / baseline.P95LatencyMs * 100
+	}
+
+	for _, s := range []VersionStats{baseline, candidate} {
+		if s.Invocations < thresholds.MinInvocations {
+			report.Reasons = append(report.Reasons, fmt.Sprintf(
+				"version %d has %d completed invocations, need at least %d", s.Version, s.Invocations, thresholds.MinInvocations))
+		}
+	}
+	if len(report.Reasons) > 0 {
+		report.Verdict = CanaryVerdictInconclusive
+		return report
+	}
+
+	if report.Delta.ErrorRate > thresholds.MaxErrorRateDelta {
+		report.Reasons = append(report.Reasons, fmt.Sprintf(
+			"error rate increased by %.2f points, limit %.2f", report.Delta.ErrorRate, thresholds.MaxErrorRateDelta))
+	}
+	if report.Delta.P95IncreasePct > thresholds.MaxP95IncreasePct {
+		report.Reasons = append(report.Reasons, fmt.Sprintf(
+			"p95 latency increased by %.1f%%, limit %.1f%%", report.Delta.P95IncreasePct, thresholds.MaxP95IncreasePct))
+	}
+	report.Verdict = CanaryVerdictPass
+	if len(report.Reasons) > 0 {
+		report.Verdict = CanaryVerdictFail
+	}
+	return report
+}
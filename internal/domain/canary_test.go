@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestNewCanaryReport(t *testing.T) {
+	fn := &Function{ID: "fn-1", Name: "checkout"}
+	thresholds := DefaultCanaryThresholds()
+	baseline := VersionStats{Version: 3, Invocations: 100, ErrorRate: 1, P95LatencyMs: 100}
+
+	report := NewCanaryReport(fn, baseline, VersionStats{Version: 5, Invocations: 100, ErrorRate: 1.5, P95LatencyMs: 110}, thresholds)
+	if report.Verdict != CanaryVerdictPass || report.Delta.P95IncreasePct != 10 {
+		t.Errorf("within thresholds: verdict=%s p95_increase=%v reasons=%v, want pass and 10", report.Verdict, report.Delta.P95IncreasePct, report.Reasons)
+	}
+
+	report = NewCanaryReport(fn, baseline, VersionStats{Version: 5, Invocations: 100, ErrorRate: 5, P95LatencyMs: 150}, thresholds)
+	if report.Verdict != CanaryVerdictFail || len(report.Reasons) != 2 {
+		t.Errorf("regressed candidate: verdict=%s reasons=%v, want fail with 2 reasons", report.Verdict, report.Reasons)
+	}
+
+	// 样本不足时不判定，即使指标已经超出阈值
+	report = NewCanaryReport(fn, baseline, VersionStats{Version: 5, Invocations: 5, ErrorRate: 50}, thresholds)
+	if report.Verdict != CanaryVerdictInconclusive || len(report.Reasons) != 1 {
+		t.Errorf("few samples: verdict=%s reasons=%v, want inconclusive", report.Verdict, report.Reasons)
+	}
+}
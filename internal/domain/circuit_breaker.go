@@ -0,0 +1,101 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了函数熔断相关的类型。
+package domain
+
+import "time"
+
+// 函数熔断配置的默认值和取值范围
+const (
+	// DefaultCircuitBreakerWindowSec 是统计错误率的默认窗口（秒）
+	DefaultCircuitBreakerWindowSec = 60
+	// DefaultCircuitBreakerMinInvocations 是窗口内触发熔断所需的默认最少调用数
+	DefaultCircuitBreakerMinInvocations = 10
+	// DefaultCircuitBreakerCooldownSec 是熔断后自动恢复前的默认冷却时间（秒）
+	DefaultCircuitBreakerCooldownSec = 300
+	// MaxCircuitBreakerWindowSec 是统计窗口的上限（秒）
+	MaxCircuitBreakerWindowSec = 3600
+	// MaxCircuitBreakerCooldownSec 是冷却时间的上限（秒）
+	MaxCircuitBreakerCooldownSec = 86400
+)
+
+// CircuitBreakerConfig 是函数级熔断配置。
+// 窗口内已完成调用的错误率（失败和超时）达到阈值时，函数被自动下线为 circuit_open 并产生告警；
+// 冷却时间结束后函数自动恢复为 active 并进入试探：下一次调用成功则恢复正常，失败则立即再次熔断。
+type CircuitBreakerConfig struct {
+	// ErrorRatePercent 是触发熔断的错误率阈值（百分比，1-100）
+	ErrorRatePercent int `json:"error_rate_percent"`
+	// WindowSec 是统计错误率的滑动窗口（秒），0 表示使用默认值 60
+	WindowSec int `json:"window_sec,omitempty"`
+	// MinInvocations 是窗口内触发熔断所需的最少调用数，0 表示使用默认值 10，避免少量调用误触发
+	MinInvocations int `json:"min_invocations,omitempty"`
+	// CooldownSec 是熔断后自动恢复前的冷却时间（秒），0 表示使用默认值 300
+	CooldownSec int `json:"cooldown_sec,omitempty"`
+}
+
+// IsZero 判断熔断配置是否为空，更新时用于关闭熔断。
+func (c *CircuitBreakerConfig) IsZero() bool {
+	return *c == CircuitBreakerConfig{}
+}
+
+// Validate 验证熔断配置：错误率阈值在 1-100 之间，窗口不超过 MaxCircuitBreakerWindowSec，
+// 冷却时间不超过 MaxCircuitBreakerCooldownSec，各项不能为负数。
+func (c *CircuitBreakerConfig) Validate() error {
+	if c.ErrorRatePercent < 1 || c.ErrorRatePercent > 100 {
+		return ErrInvalidCircuitBreaker
+	}
+	if c.WindowSec < 0 || c.WindowSec > MaxCircuitBreakerWindowSec {
+		return ErrInvalidCircuitBreaker
+	}
+	if c.MinInvocations < 0 || c.CooldownSec < 0 || c.CooldownSec > MaxCircuitBreakerCooldownSec {
+		return ErrInvalidCircuitBreaker
+	}
+	return nil
+}
+
+// Window 返回生效的统计窗口。
+func (c *CircuitBreakerConfig) Window() time.Duration {
+	if c.WindowSec <= 0 {
+		return DefaultCircuitBreakerWindowSec * time.Second
+	}
+	return time.Duration(c.WindowSec) * time.Second
+}
+
+// Cooldown 返回生效的冷却时间。
+func (c *CircuitBreakerConfig) Cooldown() time.Duration {
+	if c.CooldownSec <= 0 {
+		return DefaultCircuitBreakerCooldownSec * time.Second
+	}
+	return time.Duration(c.CooldownSec) * time.Second
+}
+
+// Trips 判断窗口内的调用结果是否达到熔断条件：调用数不少于最少调用数且错误率不低于阈值。
+//
+// 参数:
+//   - total: 窗口内已完成的调用数
+//   - failed: 其中失败或超时的调用数
+func (c *CircuitBreakerConfig) Trips(total, failed int) bool {
+	minInvocations := c.MinInvocations
+	if minInvocations <= 0 {
+		minInvocations = DefaultCircuitBreakerMinInvocations
+	}
+	if total == 0 || total < minInvocations {
+		return false
+	}
+	return failed*100 >= c.ErrorRatePercent*total
+}
+
+// 熔断状态
+const (
+	// CircuitStateOpen 表示函数已熔断，等待冷却时间结束
+	CircuitStateOpen = "open"
+	// CircuitStateProbing 表示冷却已结束，下一次调用的结果决定恢复正常还是再次熔断
+	CircuitStateProbing = "probing"
+)
+
+// CircuitState 是函数的熔断状态，按函数 ID 存放在 Redis 中，由所有网关实例共享。
+type CircuitState struct {
+	// State 是熔断状态：open 或 probing
+	State string `json:"state"`
+	// OpenedAt 是最近一次熔断的时间，冷却时间从此刻开始计算
+	OpenedAt time.Time `json:"opened_at"`
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerConfig(t *testing.T) {
+	cb := &CircuitBreakerConfig{ErrorRatePercent: 50}
+	if err := cb.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	if cb.Window() != time.Minute || cb.Cooldown() != 5*time.Minute {
+		t.Errorf("defaults: window=%s cooldown=%s", cb.Window(), cb.Cooldown())
+	}
+	// 少于最少调用数时不熔断，达到后按错误率判断
+	if cb.Trips(9, 9) {
+		t.Error("tripped below min_invocations")
+	}
+	if !cb.Trips(10, 5) || cb.Trips(10, 4) {
+		t.Error("50% threshold not applied at exactly 10 invocations")
+	}
+
+	for _, bad := range []CircuitBreakerConfig{
+		{},
+		{ErrorRatePercent: 101},
+		{ErrorRatePercent: 50, WindowSec: MaxCircuitBreakerWindowSec + 1},
+		{ErrorRatePercent: 50, CooldownSec: -1},
+		{ErrorRatePercent: 50, MinInvocations: -1},
+	} {
+		if err := bad.Validate(); !errors.Is(err, ErrInvalidCircuitBreaker) {
+			t.Errorf("%+v: err = %v, want ErrInvalidCircuitBreaker", bad, err)
+		}
+	}
+	if !(&CircuitBreakerConfig{}).IsZero() {
+		t.Error("empty config should be zero")
+	}
+
+	// 熔断的函数不可调用，但可以手动上线、下线和更新
+	s := FunctionStatusCircuitOpen
+	if s.CanInvoke() || s.CanInvokeAsync() || !s.CanOnline() || !s.CanOffline() || !s.CanUpdate() {
+		t.Errorf("unexpected transitions for %s", s)
+	}
+}
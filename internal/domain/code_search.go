@@ -0,0 +1,125 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了函数代码搜索相关的类型。
+package domain
+
+import (
+	"sort"
+	"strings"
+)
+
+// MaxCodeSearchMatches 是单个函数返回的最大命中数，避免高频字符串导致响应过大
+const MaxCodeSearchMatches = 20
+
+// CodeSearchQuery 表示跨函数的代码搜索条件。
+type CodeSearchQuery struct {
+	// Query 是搜索的字符串（大小写不敏感的子串匹配）
+	Query string `json:"q"`
+	// IncludeHandler 表示是否同时搜索入口点
+	IncludeHandler bool `json:"include_handler,omitempty"`
+	// IncludeEnv 表示是否同时搜索环境变量的名称和值
+	IncludeEnv bool `json:"include_env,omitempty"`
+	// Tags 限定只搜索带有全部指定标签的函数
+	Tags []string `json:"tags,omitempty"`
+	// Runtime 限定只搜索指定运行时的函数
+	Runtime Runtime `json:"runtime,omitempty"`
+}
+
+// CodeSearchMatch 表示函数中的一处搜索命中。
+type CodeSearchMatch struct {
+	// Field 是命中的字段：code / handler / env
+	Field string `json:"field"`
+	// Line 是命中所在的代码行号（从 1 开始，仅 code 字段）
+	Line int `json:"line,omitempty"`
+	// Key 是命中的环境变量名（仅 env 字段）
+	Key string `json:"key,omitempty"`
+	// Text 是命中的整行内容
+	Text string `json:"text"`
+	// Before 是命中行之前的上下文行
+	Before []string `json:"before,omitempty"`
+	// After 是命中行之后的上下文行
+	After []string `json:"after,omitempty"`
+}
+
+// CodeSearchResult 表示一个函数的搜索结果。
+type CodeSearchResult struct {
+	// FunctionID 是函数 ID
+	FunctionID string `json:"function_id"`
+	// FunctionName 是函数名称
+	FunctionName string `json:"function_name"`
+	// Runtime 是函数运行时
+	Runtime Runtime `json:"runtime"`
+	// Matches 是函数中的命中列表（最多 MaxCodeSearchMatches 条）
+	Matches []CodeSearchMatch `json:"matches"`
+	// Truncated 表示命中数超过上限，Matches 已被截断
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// FindCodeMatches 在函数中查找搜索字符串，返回带上下文的命中结果。
+// 匹配大小写不敏感；代码按行匹配，每处命中附带前后 contextLines 行。
+//
+// 参数:
+//   - fn: 要搜索的函数
+//   - q: 搜索条件
+//   - contextLines: 每处命中附带的上下文行数
+//
+// 返回值:
+//   - *CodeSearchResult: 搜索结果，没有命中时 Matches 为空
+func FindCodeMatches(fn *Function, q *CodeSearchQuery, contextLines int) *CodeSearchResult {
+	result := &CodeSearchResult{
+		FunctionID:   fn.ID,
+		FunctionName: fn.Name,
+		Runtime:      fn.Runtime,
+		Matches:      []CodeSearchMatch{},
+	}
+	needle := strings.ToLower(q.Query)
+	if needle == "" {
+		return result
+	}
+
+	add := func(m CodeSearchMatch) bool {
+		if len(result.Matches) >= MaxCodeSearchMatches {
+			result.Truncated = true
+			return false
+		}
+		result.Matches = append(result.Matches, m)
+		return true
+	}
+
+	lines := strings.Split(fn.Code, "\n")
+	for i, line := range lines {
+		if !strings.Contains(strings.ToLower(line), needle) {
+			continue
+		}
+		m := CodeSearchMatch{Field: "code", Line: i + 1, Text: line}
+		if contextLines > 0 {
+			m.Before = lines[max(0, i-contextLines):i]
+			m.After = lines[i+1 : min(len(lines), i+1+contextLines)]
+		}
+		if !add(m) {
+			return result
+		}
+	}
+
+	if q.IncludeHandler && strings.Contains(strings.ToLower(fn.Handler), needle) {
+		if !add(CodeSearchMatch{Field: "handler", Text: fn.Handler}) {
+			return result
+		}
+	}
+
+	if q.IncludeEnv {
+		keys := make([]string, 0, len(fn.EnvVars))
+		for k := range fn.EnvVars {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := fn.EnvVars[k]
+			if strings.Contains(strings.ToLower(k), needle) || strings.Contains(strings.ToLower(v), needle) {
+				if !add(CodeSearchMatch{Field: "env", Key: k, Text: k + "=" + v}) {
+					return result
+				}
+			}
+		}
+	}
+	return result
+}
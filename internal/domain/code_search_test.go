@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestFindCodeMatches(t *testing.T) {
+	fn := &Function{
+		ID:      "fn-1",
+		Name:    "orders",
+		Handler: "main.handler",
+		Code:    "import requests\n\ndef handler(event, ctx):\n    return requests.get('https://legacy.example.com/v1')\n",
+		EnvVars: map[string]string{"API_URL": "https://LEGACY.example.com", "DEBUG": "1"},
+	}
+
+	got := FindCodeMatches(fn, &CodeSearchQuery{Query: "legacy.example", IncludeEnv: true}, 1)
+	if len(got.Matches) != 2 {
+		t.Fatalf("got %d matches, want 2: %+v", len(got.Matches), got.Matches)
+	}
+	code := got.Matches[0]
+	if code.Field != "code" || code.Line != 4 {
+		t.Errorf("code match = %+v, want line 4", code)
+	}
+	if len(code.Before) != 1 || code.Before[0] != "def handler(event, ctx):" {
+		t.Errorf("before = %q", code.Before)
+	}
+	if len(code.After) != 1 || code.After[0] != "" {
+		t.Errorf("after = %q", code.After)
+	}
+	if env := got.Matches[1]; env.Field != "env" || env.Key != "API_URL" {
+		t.Errorf("env match = %+v", env)
+	}
+
+	// 未要求时不搜索入口点
+	if got := FindCodeMatches(fn, &CodeSearchQuery{Query: "main.handler"}, 0); len(got.Matches) != 0 {
+		t.Errorf("handler should not be searched without IncludeHandler: %+v", got.Matches)
+	}
+}
@@ -0,0 +1,88 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了成本预估相关的类型。
+package domain
+
+const (
+	// CostEstimateDaysPerMonth 是成本预估按月折算使用的天数
+	CostEstimateDaysPerMonth = 30
+	// MinBilledTimeMs 是单次调用的最小计费时长（毫秒）
+	MinBilledTimeMs = 100
+)
+
+// CostEstimate 表示按假设调用量预估的月度用量。
+type CostEstimate struct {
+	// FunctionID 是函数 ID
+	FunctionID string `json:"function_id"`
+	// FunctionName 是函数名称
+	FunctionName string `json:"function_name"`
+	// InvocationsPerDay 是假设的每日调用次数
+	InvocationsPerDay int64 `json:"invocations_per_day"`
+	// InvocationsPerMonth 是预估的每月调用次数
+	InvocationsPerMonth int64 `json:"invocations_per_month"`
+	// GBSecondsPerMonth 是按观测平均计费时长预估的每月 GB-秒
+	GBSecondsPerMonth float64 `json:"gb_seconds_per_month"`
+	// MaxGBSecondsPerMonth 是每次调用都执行到超时时的每月 GB-秒上限
+	MaxGBSecondsPerMonth float64 `json:"max_gb_seconds_per_month"`
+	// Assumptions 是预估使用的假设
+	Assumptions CostAssumptions `json:"assumptions"`
+}
+
+// CostAssumptions 是成本预估使用的假设，用于说明预估结果的来源。
+type CostAssumptions struct {
+	// MemoryMB 是函数配置的内存大小
+	MemoryMB int `json:"memory_mb"`
+	// TimeoutSec 是函数配置的超时时间，用于计算上限
+	TimeoutSec int `json:"timeout_sec"`
+	// AvgBilledTimeMs 是采用的平均单次计费时长（毫秒）
+	AvgBilledTimeMs float64 `json:"avg_billed_time_ms"`
+	// Basis 是平均计费时长的来源：observed 表示观测值，default 表示没有样本时使用最小计费时长
+	Basis string `json:"basis"`
+	// SampleInvocations 是观测窗口内已完成的调用数
+	SampleInvocations int64 `json:"sample_invocations"`
+	// SamplePeriod 是观测窗口（如 "7d"）
+	SamplePeriod string `json:"sample_period"`
+	// DaysPerMonth 是按月折算使用的天数
+	DaysPerMonth int `json:"days_per_month"`
+}
+
+// EstimateCost 按函数配置、观测到的平均计费时长和假设调用量预估月度用量。
+// 参数:
+//   - fn: 函数定义，使用其 MemoryMB 和 TimeoutSec
+//   - invocationsPerDay: 假设的每日调用次数
+//   - samples: 观测窗口内已完成的调用数
+//   - avgBilledMs: 观测窗口内的平均计费时长（毫秒），samples 为 0 时忽略
+//
+// 返回值:
+//   - *CostEstimate: 预估结果（SamplePeriod 由调用方填充）
+func EstimateCost(fn *Function, invocationsPerDay, samples int64, avgBilledMs float64) *CostEstimate {
+	basis := "observed"
+	if samples == 0 || avgBilledMs <= 0 {
+		basis = "default"
+		avgBilledMs = MinBilledTimeMs
+	}
+
+	perMonth := invocationsPerDay * CostEstimateDaysPerMonth
+	memoryGB := float64(fn.MemoryMB) / 1024
+	// 上限：每次调用都执行到超时，按计费单位向上取整
+	maxBilledMs := float64(((int64(fn.TimeoutSec)*1000 + 99) / 100) * 100)
+	if maxBilledMs < MinBilledTimeMs {
+		maxBilledMs = MinBilledTimeMs
+	}
+
+	return &CostEstimate{
+		FunctionID:           fn.ID,
+		FunctionName:         fn.Name,
+		InvocationsPerDay:    invocationsPerDay,
+		InvocationsPerMonth:  perMonth,
+		GBSecondsPerMonth:    float64(perMonth) * avgBilledMs / 1000 * memoryGB,
+		MaxGBSecondsPerMonth: float64(perMonth) * maxBilledMs / 1000 * memoryGB,
+		Assumptions: CostAssumptions{
+			MemoryMB:          fn.MemoryMB,
+			TimeoutSec:        fn.TimeoutSec,
+			AvgBilledTimeMs:   avgBilledMs,
+			Basis:             basis,
+			SampleInvocations: samples,
+			DaysPerMonth:      CostEstimateDaysPerMonth,
+		},
+	}
+}
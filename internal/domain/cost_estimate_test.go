@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestEstimateCost(t *testing.T) {
+	fn := &Function{ID: "fn-1", Name: "resize", MemoryMB: 512, TimeoutSec: 30}
+
+	est := EstimateCost(fn, 1000, 42, 250)
+	if est.InvocationsPerMonth != 30000 {
+		t.Errorf("InvocationsPerMonth = %d, want 30000", est.InvocationsPerMonth)
+	}
+	// 30000 * 0.25s * 0.5GB
+	if est.GBSecondsPerMonth != 3750 {
+		t.Errorf("GBSecondsPerMonth = %v, want 3750", est.GBSecondsPerMonth)
+	}
+	// 30000 * 30s * 0.5GB
+	if est.MaxGBSecondsPerMonth != 450000 {
+		t.Errorf("MaxGBSecondsPerMonth = %v, want 450000", est.MaxGBSecondsPerMonth)
+	}
+	if est.Assumptions.Basis != "observed" || est.Assumptions.SampleInvocations != 42 {
+		t.Errorf("Assumptions = %+v, want observed basis with 42 samples", est.Assumptions)
+	}
+
+	est = EstimateCost(fn, 1000, 0, 0)
+	if est.Assumptions.Basis != "default" || est.Assumptions.AvgBilledTimeMs != MinBilledTimeMs {
+		t.Errorf("Assumptions = %+v, want default basis with minimum billed time", est.Assumptions)
+	}
+}
@@ -0,0 +1,117 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了成本标签相关的类型。
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// HeaderCostTags 是调用方附加成本标签的请求头，格式为逗号分隔的 key=value，如 "team=search,env=prod"
+const HeaderCostTags = "X-Nimbus-Cost-Tags"
+
+// 成本标签的数量和长度上限，避免标签基数失控
+const (
+	// MaxCostTags 是单次调用的成本标签数量上限
+	MaxCostTags = 8
+	// MaxCostTagKeyLen 是标签键的最大长度
+	MaxCostTagKeyLen = 32
+	// MaxCostTagValueLen 是标签值的最大长度
+	MaxCostTagValueLen = 64
+)
+
+// validCostTagToken 检查标签键或值只包含字母、数字、'-'、'_'、'.'、'/' 或 ':'。
+func validCostTagToken(s string, maxLen int) bool {
+	if s == "" || len(s) > maxLen {
+		return false
+	}
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == '/', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ParseCostTags 解析 X-Nimbus-Cost-Tags 请求头。
+// 键不区分大小写（统一转为小写），同一个键出现多次时以最后一次为准，空白项被忽略。
+//
+// 参数:
+//   - header: 请求头的值
+//
+// 返回值:
+//   - map[string]string: 成本标签，请求头为空时返回 nil
+//   - error: 格式错误、数量或长度超过上限时返回 ErrInvalidCostTags
+func ParseCostTags(header string) (map[string]string, error) {
+	if strings.TrimSpace(header) == "" {
+		return nil, nil
+	}
+	tags := make(map[string]string)
+	for _, item := range strings.Split(header, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if !ok || !validCostTagToken(key, MaxCostTagKeyLen) || !validCostTagToken(value, MaxCostTagValueLen) {
+			return nil, ErrInvalidCostTags
+		}
+		tags[key] = value
+	}
+	if len(tags) > MaxCostTags {
+		return nil, ErrInvalidCostTags
+	}
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	return tags, nil
+}
+
+// 用量统计的分组方式
+const (
+	// UsageGroupByTag 按成本标签（key=value）分组，带多个标签的调用会计入每个标签
+	UsageGroupByTag = "tag"
+	// UsageGroupByFunction 按函数分组
+	UsageGroupByFunction = "function"
+)
+
+// UsageGroup 是用量统计中的一个分组。
+type UsageGroup struct {
+	// TagKey 是成本标签键（按标签分组时）
+	TagKey string `json:"tag_key,omitempty"`
+	// TagValue 是成本标签值（按标签分组时）
+	TagValue string `json:"tag_value,omitempty"`
+	// FunctionID 是函数 ID（按函数分组时）
+	FunctionID string `json:"function_id,omitempty"`
+	// FunctionName 是函数名称（按函数分组时）
+	FunctionName string `json:"function_name,omitempty"`
+	// Invocations 是调用次数
+	Invocations int64 `json:"invocations"`
+	// BilledTimeMs 是累计计费时长（单位：毫秒）
+	BilledTimeMs int64 `json:"billed_time_ms"`
+}
+
+// UsageReport 是某一时间段内按分组汇总的调用用量。
+type UsageReport struct {
+	// GroupBy 是分组方式
+	GroupBy string `json:"group_by"`
+	// Period 是统计时间段（如 "24h"、"7d"）
+	Period string `json:"period"`
+	// Since 是统计时间段的起点
+	Since time.Time `json:"since"`
+	// Groups 是各分组的用量，按计费时长降序排列
+	Groups []UsageGroup `json:"groups"`
+	// TotalInvocations 是时间段内的调用总数
+	TotalInvocations int64 `json:"total_invocations"`
+	// TotalBilledTimeMs 是时间段内的计费时长总和（单位：毫秒）
+	TotalBilledTimeMs int64 `json:"total_billed_time_ms"`
+	// UntaggedInvocations 是没有成本标签的调用数（按标签分组时）
+	UntaggedInvocations int64 `json:"untagged_invocations"`
+	// UntaggedBilledTimeMs 是没有成本标签的调用的计费时长（按标签分组时）
+	UntaggedBilledTimeMs int64 `json:"untagged_billed_time_ms"`
+}
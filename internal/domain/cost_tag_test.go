@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCostTags(t *testing.T) {
+	tags, err := ParseCostTags(" Team=search , cost_center=cc-1042,,team=ads ")
+	if err != nil {
+		t.Fatalf("ParseCostTags() error = %v", err)
+	}
+	if len(tags) != 2 || tags["team"] != "ads" || tags["cost_center"] != "cc-1042" {
+		t.Errorf("ParseCostTags() = %v", tags)
+	}
+
+	if tags, err := ParseCostTags(""); err != nil || tags != nil {
+		t.Errorf("ParseCostTags(\"\") = %v, %v, want nil, nil", tags, err)
+	}
+
+	tooMany := "a=1,b=2,c=3,d=4,e=5,f=6,g=7,h=8,i=9"
+	for _, header := range []string{"team", "team=", "=search", "team=a b", "team=" + strings.Repeat("x", MaxCostTagValueLen+1), tooMany} {
+		if _, err := ParseCostTags(header); err != ErrInvalidCostTags {
+			t.Errorf("ParseCostTags(%q) error = %v, want ErrInvalidCostTags", header, err)
+		}
+	}
+}
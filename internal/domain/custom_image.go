@@ -0,0 +1,97 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了自定义镜像相关的校验。
+package domain
+
+import (
+	"path"
+	"strings"
+)
+
+// 自定义镜像配置的取值范围
+const (
+	// MaxCustomImageLen 是自定义镜像引用的最大长度
+	MaxCustomImageLen = 255
+	// MaxCustomArgs 是自定义命令和参数各自允许的最大项数
+	MaxCustomArgs = 64
+	// MaxCustomArgLen 是自定义命令、参数中单项以及工作目录的最大长度
+	MaxCustomArgLen = 4096
+)
+
+// ValidCustomImage 检查镜像引用格式：以字母或数字开头，只包含字母、数字和 '.'、'_'、'/'、':'、'@'、'-'。
+// 镜像引用直接作为 docker 命令行参数，以 '-' 开头的值会被当作选项解析，限制字符集可避免参数注入。
+func ValidCustomImage(image string) bool {
+	if image == "" || len(image) > MaxCustomImageLen {
+		return false
+	}
+	for i, c := range image {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("._/:@-", c) && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateCustomImage 验证函数的自定义镜像配置：镜像引用格式合法；
+// 自定义命令、工作目录和参数只能与自定义镜像一起使用；工作目录必须是规范的绝对路径；
+// 命令和参数最多 MaxCustomArgs 项，每项不超过 MaxCustomArgLen 且不含 NUL 字符，命令的第一项不能为空。
+// 镜像是否在白名单内由部署配置决定，见 CustomImageAllowed。
+//
+// 参数:
+//   - image: 自定义镜像，为空表示使用运行时镜像
+//   - command: 替换镜像 ENTRYPOINT 和 CMD 的命令
+//   - workingDir: 容器内的工作目录
+//   - args: 追加在命令之后的参数
+//
+// 返回值:
+//   - error: 配置无效时返回 ErrInvalidCustomImage
+func ValidateCustomImage(image string, command []string, workingDir string, args []string) error {
+	if image == "" {
+		if len(command) > 0 || workingDir != "" || len(args) > 0 {
+			return ErrInvalidCustomImage
+		}
+		return nil
+	}
+	if !ValidCustomImage(image) {
+		return ErrInvalidCustomImage
+	}
+	if len(command) > 0 && command[0] == "" {
+		return ErrInvalidCustomImage
+	}
+	if workingDir != "" && (!validCustomArg(workingDir) || !path.IsAbs(workingDir) || path.Clean(workingDir) != workingDir) {
+		return ErrInvalidCustomImage
+	}
+	for _, list := range [][]string{command, args} {
+		if len(list) > MaxCustomArgs {
+			return ErrInvalidCustomImage
+		}
+		for _, arg := range list {
+			if !validCustomArg(arg) {
+				return ErrInvalidCustomImage
+			}
+		}
+	}
+	return nil
+}
+
+// validCustomArg 检查命令行参数的长度和字符：不超过 MaxCustomArgLen 且不含 NUL 字符。
+func validCustomArg(arg string) bool {
+	return len(arg) <= MaxCustomArgLen && !strings.ContainsRune(arg, 0)
+}
+
+// CustomImageAllowed 检查镜像是否匹配白名单中的某一项：以 '*' 结尾的项按前缀匹配
+// （如 "registry.example.com/team/*"），其余项要求完全相同。白名单为空时不允许任何自定义镜像。
+func CustomImageAllowed(image string, allowlist []string) bool {
+	for _, entry := range allowlist {
+		if prefix, ok := strings.CutSuffix(entry, "*"); ok {
+			if strings.HasPrefix(image, prefix) {
+				return true
+			}
+		} else if image == entry {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateCustomImage(t *testing.T) {
+	valid := []struct {
+		image      string
+		command    []string
+		workingDir string
+		args       []string
+	}{
+		{},
+		{image: "registry.example.com/team/app:1.2"},
+		{image: "app@sha256:abc", command: []string{"/bin/app", "--serve"}, workingDir: "/srv/app", args: []string{"--verbose", ""}},
+	}
+	for _, tt := range valid {
+		if err := ValidateCustomImage(tt.image, tt.command, tt.workingDir, tt.args); err != nil {
+			t.Errorf("ValidateCustomImage(%q, %q, %q, %q) error = %v", tt.image, tt.command, tt.workingDir, tt.args, err)
+		}
+	}
+
+	tooMany := make([]string, MaxCustomArgs+1)
+	invalid := []struct {
+		name       string
+		image      string
+		command    []string
+		workingDir string
+		args       []string
+	}{
+		{name: "option-like image", image: "--privileged"},
+		{name: "image with space", image: "app latest"},
+		{name: "image too long", image: strings.Repeat("a", MaxCustomImageLen+1)},
+		{name: "command without image", command: []string{"/bin/app"}},
+		{name: "working dir without image", workingDir: "/srv"},
+		{name: "args without image", args: []string{"-v"}},
+		{name: "empty command", image: "app", command: []string{""}},
+		{name: "relative working dir", image: "app", workingDir: "srv/app"},
+		{name: "unclean working dir", image: "app", workingDir: "/srv/../etc"},
+		{name: "NUL in args", image: "app", args: []string{"a\x00b"}},
+		{name: "too many args", image: "app", args: tooMany},
+		{name: "arg too long", image: "app", command: []string{strings.Repeat("a", MaxCustomArgLen+1)}},
+	}
+	for _, tt := range invalid {
+		if err := ValidateCustomImage(tt.image, tt.command, tt.workingDir, tt.args); err != ErrInvalidCustomImage {
+			t.Errorf("%s: error = %v, want ErrInvalidCustomImage", tt.name, err)
+		}
+	}
+}
+
+func TestCreateFunctionRequestCustomImage(t *testing.T) {
+	req := CreateFunctionRequest{Name: "byo", Runtime: "python3.11", Handler: "handler.main", Code: "def main(event): return {}", Args: []string{"--serve"}}
+	if err := req.Validate(); err != ErrInvalidCustomImage {
+		t.Errorf("Validate() without custom_image error = %v, want ErrInvalidCustomImage", err)
+	}
+
+	changes := DiffFunctions(&Function{}, &Function{CustomImage: "app", Args: []string{}})
+	if c, ok := changes["custom_image"]; !ok || c.Old != "" || c.New != "app" {
+		t.Errorf("custom_image change = %+v, want \"\" -> app", c)
+	}
+	if _, ok := changes["args"]; ok {
+		t.Error("nil and empty args should not be reported as a change")
+	}
+}
+
+func TestCustomImageAllowed(t *testing.T) {
+	allowlist := []string{"registry.example.com/team/*", "busybox:1.36"}
+	tests := []struct {
+		image string
+		want  bool
+	}{
+		{"registry.example.com/team/app:1.0", true},
+		{"busybox:1.36", true},
+		{"busybox:latest", false},
+		{"registry.example.com/other/app", false},
+	}
+	for _, tt := range tests {
+		if got := CustomImageAllowed(tt.image, allowlist); got != tt.want {
+			t.Errorf("CustomImageAllowed(%q) = %v, want %v", tt.image, got, tt.want)
+		}
+	}
+	if CustomImageAllowed("busybox:1.36", nil) {
+		t.Error("empty allowlist should reject every image")
+	}
+}
@@ -0,0 +1,49 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了共享数据卷相关的类型。
+package domain
+
+// DataVolumeMountDir 是共享数据卷在函数容器内的挂载根目录，数据卷挂载到 DataVolumeMountDir/<名称>
+const DataVolumeMountDir = "/opt/data"
+
+// MaxDataVolumes 是单个函数允许挂载的数据卷数量上限
+const MaxDataVolumes = 8
+
+// maxDataVolumeNameLen 是数据卷名称的最大长度
+const maxDataVolumeNameLen = 63
+
+// ValidDataVolumeName 检查数据卷名称格式：1-63 个小写字母、数字、'-' 或 '_'，且以字母或数字开头。
+// 名称直接作为容器内的挂载目录名，限制字符集可避免路径穿越。
+func ValidDataVolumeName(name string) bool {
+	if name == "" || len(name) > maxDataVolumeNameLen {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		case (c == '-' || c == '_') && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateDataVolumes 验证函数的数据卷配置。
+// 数量不能超过 MaxDataVolumes，名称必须符合 ValidDataVolumeName 且不能重复。
+// 名称是否已由运维方注册由调用方根据部署配置检查。
+func ValidateDataVolumes(names []string) error {
+	if len(names) > MaxDataVolumes {
+		return ErrInvalidDataVolume
+	}
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		if !ValidDataVolumeName(name) {
+			return ErrInvalidDataVolume
+		}
+		if _, dup := seen[name]; dup {
+			return ErrInvalidDataVolume
+		}
+		seen[name] = struct{}{}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestValidateDataVolumes(t *testing.T) {
+	if err := ValidateDataVolumes([]string{"models", "ref_data-2"}); err != nil {
+		t.Errorf("ValidateDataVolumes() error = %v", err)
+	}
+	for _, names := range [][]string{
+		{"Models"},
+		{"../etc"},
+		{"-models"},
+		{""},
+		{"models", "models"},
+		{"a", "b", "c", "d", "e", "f", "g", "h", "i"},
+	} {
+		if err := ValidateDataVolumes(names); err != ErrInvalidDataVolume {
+			t.Errorf("ValidateDataVolumes(%q) error = %v, want ErrInvalidDataVolume", names, err)
+		}
+	}
+}
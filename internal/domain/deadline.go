@@ -0,0 +1,36 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了调用截止时间相关的类型。
+package domain
+
+import (
+	"strconv"
+	"time"
+)
+
+// 执行器在每次调用时注入函数环境的截止时间变量
+const (
+	// EnvDeadlineMs 是调用硬截止时间（Unix 毫秒时间戳），超过后函数会被终止
+	EnvDeadlineMs = "NIMBUS_DEADLINE_MS"
+	// EnvTimeoutMs 是本次调用生效的超时时间（毫秒）
+	EnvTimeoutMs = "NIMBUS_TIMEOUT_MS"
+)
+
+// InvocationEnv 返回注入了截止时间变量的函数环境变量副本，不修改 base。
+// 截止时间变量由平台设置，会覆盖用户配置的同名变量。
+//
+// 参数:
+//   - base: 函数配置的环境变量，可为 nil
+//   - deadline: 调用硬截止时间
+//   - timeout: 本次调用生效的超时时间
+//
+// 返回值:
+//   - map[string]string: 新的环境变量映射
+func InvocationEnv(base map[string]string, deadline time.Time, timeout time.Duration) map[string]string {
+	env := make(map[string]string, len(base)+2)
+	for k, v := range base {
+		env[k] = v
+	}
+	env[EnvDeadlineMs] = strconv.FormatInt(deadline.UnixMilli(), 10)
+	env[EnvTimeoutMs] = strconv.FormatInt(timeout.Milliseconds(), 10)
+	return env
+}
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+// TestInvocationEnv 测试截止时间变量的注入：返回副本且平台变量覆盖同名用户变量。
+func TestInvocationEnv(t *testing.T) {
+	base := map[string]string{"APP_MODE": "prod", EnvTimeoutMs: "1"}
+	deadline := time.UnixMilli(1700000000123)
+
+	env := InvocationEnv(base, deadline, 30*time.Second)
+	if env[EnvDeadlineMs] != "1700000000123" || env[EnvTimeoutMs] != "30000" || env["APP_MODE"] != "prod" {
+		t.Errorf("env = %v", env)
+	}
+	if base[EnvTimeoutMs] != "1" || len(base) != 2 {
+		t.Errorf("base env was modified: %v", base)
+	}
+	if env := InvocationEnv(nil, deadline, time.Second); len(env) != 2 {
+		t.Errorf("nil base env = %v, want only deadline variables", env)
+	}
+}
@@ -0,0 +1,49 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了蓝绿部署相关的类型。
+package domain
+
+const (
+	// SlotBlue 是蓝绿部署的蓝色槽位，同时是保留别名名称
+	SlotBlue = "blue"
+	// SlotGreen 是蓝绿部署的绿色槽位，同时是保留别名名称
+	SlotGreen = "green"
+)
+
+// IsDeploymentSlot 判断名称是否为蓝绿部署槽位（blue/green）。
+func IsDeploymentSlot(name string) bool {
+	return name == SlotBlue || name == SlotGreen
+}
+
+// OtherSlot 返回另一个槽位。
+// 参数:
+//   - slot: 当前槽位，为空时视为尚未启用蓝绿部署
+//
+// 返回值:
+//   - string: 切换的目标槽位；未启用时返回 SlotBlue
+func OtherSlot(slot string) string {
+	if slot == SlotBlue {
+		return SlotGreen
+	}
+	return SlotBlue
+}
+
+// SlotVersion 返回槽位别名固定指向的版本号。
+// 槽位别名必须将 100% 流量指向单个版本，否则切换后的线上版本不确定。
+// 参数:
+//   - cfg: 槽位别名的路由配置
+//
+// 返回值:
+//   - int: 目标版本号
+//   - error: 路由配置不是单版本时返回 ErrInvalidSlotAlias
+func SlotVersion(cfg RoutingConfig) (int, error) {
+	if len(cfg.Weights) != 1 || cfg.Weights[0].Weight != 100 || cfg.Weights[0].Version <= 0 {
+		return 0, ErrInvalidSlotAlias
+	}
+	return cfg.Weights[0].Version, nil
+}
+
+// SwapSlotRequest 表示蓝绿切换请求，请求体可选。
+type SwapSlotRequest struct {
+	// To 是切换到的目标槽位（blue/green），为空时切换到另一个槽位
+	To string `json:"to,omitempty"`
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestDeploymentSlots(t *testing.T) {
+	if OtherSlot("") != SlotBlue || OtherSlot(SlotBlue) != SlotGreen || OtherSlot(SlotGreen) != SlotBlue {
+		t.Error("OtherSlot() returned unexpected slot")
+	}
+	if !IsDeploymentSlot("green") || IsDeploymentSlot("latest") {
+		t.Error("IsDeploymentSlot() returned unexpected result")
+	}
+
+	v, err := SlotVersion(RoutingConfig{Weights: []VersionWeight{{Version: 4, Weight: 100}}})
+	if err != nil || v != 4 {
+		t.Errorf("SlotVersion() = %d, %v, want 4, nil", v, err)
+	}
+	for _, cfg := range []RoutingConfig{
+		{},
+		{Weights: []VersionWeight{{Version: 3, Weight: 90}, {Version: 4, Weight: 10}}},
+		{Weights: []VersionWeight{{Version: 0, Weight: 100}}},
+	} {
+		if _, err := SlotVersion(cfg); err != ErrInvalidSlotAlias {
+			t.Errorf("SlotVersion(%v) error = %v, want ErrInvalidSlotAlias", cfg, err)
+		}
+	}
+}
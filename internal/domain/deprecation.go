@@ -0,0 +1,52 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了函数弃用相关的类型。
+package domain
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// MaxDeprecationMessageLength 是弃用说明的长度上限（字符数）
+const MaxDeprecationMessageLength = 512
+
+// FunctionDeprecation 描述函数的弃用与下线计划。
+// 弃用期间调用照常执行，响应携带 Deprecation（RFC 9745）和 Sunset（RFC 8594）响应头提醒调用方迁移；
+// 过了下线时间后，可选择以 410 拒绝调用。
+type FunctionDeprecation struct {
+	// Message 是弃用说明（如替代函数和迁移指引）
+	Message string `json:"message,omitempty"`
+	// DeprecatedAt 是函数被标记为弃用的时间，由服务端在首次设置弃用配置时记录
+	DeprecatedAt time.Time `json:"deprecated_at"`
+	// SunsetAt 是计划下线时间（可选）
+	SunsetAt *time.Time `json:"sunset_at,omitempty"`
+	// RejectAfterSunset 表示过了下线时间后拒绝调用（返回 410），需要设置 SunsetAt
+	RejectAfterSunset bool `json:"reject_after_sunset,omitempty"`
+}
+
+// IsZero 判断弃用配置是否为空（既没有说明也没有下线时间），更新时用于取消弃用。
+func (d *FunctionDeprecation) IsZero() bool {
+	return d.Message == "" && d.SunsetAt == nil
+}
+
+// Validate 验证弃用配置：需要说明或下线时间，说明不超过 MaxDeprecationMessageLength，
+// 拒绝调用需要下线时间。
+func (d *FunctionDeprecation) Validate() error {
+	if d.IsZero() || utf8.RuneCountInString(d.Message) > MaxDeprecationMessageLength {
+		return ErrInvalidDeprecation
+	}
+	if d.RejectAfterSunset && d.SunsetAt == nil {
+		return ErrInvalidDeprecation
+	}
+	return nil
+}
+
+// PastSunset 判断指定时间是否已过下线时间，未设置下线时间时返回 false。
+func (d *FunctionDeprecation) PastSunset(now time.Time) bool {
+	return d.SunsetAt != nil && !now.Before(*d.SunsetAt)
+}
+
+// Rejects 判断指定时间的调用是否应被拒绝（已过下线时间且配置为拒绝调用）。
+func (d *FunctionDeprecation) Rejects(now time.Time) bool {
+	return d.RejectAfterSunset && d.PastSunset(now)
+}
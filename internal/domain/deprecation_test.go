@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestFunctionDeprecation 测试弃用配置的校验与下线时间判断。
+func TestFunctionDeprecation(t *testing.T) {
+	sunset := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		d       FunctionDeprecation
+		wantErr bool
+	}{
+		{name: "message only", d: FunctionDeprecation{Message: "use v2"}},
+		{name: "sunset only", d: FunctionDeprecation{SunsetAt: &sunset}},
+		{name: "reject with sunset", d: FunctionDeprecation{SunsetAt: &sunset, RejectAfterSunset: true}},
+		{name: "empty", d: FunctionDeprecation{}, wantErr: true},
+		{name: "reject without sunset", d: FunctionDeprecation{Message: "use v2", RejectAfterSunset: true}, wantErr: true},
+		{name: "message too long", d: FunctionDeprecation{Message: strings.Repeat("弃", MaxDeprecationMessageLength+1)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.d.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	d := FunctionDeprecation{SunsetAt: &sunset, RejectAfterSunset: true}
+	if d.Rejects(sunset.Add(-time.Second)) {
+		t.Error("Rejects() before sunset = true, want false")
+	}
+	if !d.Rejects(sunset) {
+		t.Error("Rejects() at sunset = false, want true")
+	}
+	d.RejectAfterSunset = false
+	if !d.PastSunset(sunset) || d.Rejects(sunset) {
+		t.Error("without reject_after_sunset calls should only be flagged past sunset")
+	}
+}
@@ -0,0 +1,36 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了执行环境诊断相关的类型。
+package domain
+
+import "time"
+
+// FunctionDiagnostics 描述函数实际执行环境的诊断信息。
+// 由平台内置探针在函数容器中采集，不需要在用户代码中添加探测逻辑。
+type FunctionDiagnostics struct {
+	// FunctionID 是函数 ID
+	FunctionID string `json:"function_id"`
+	// Runtime 是函数运行时
+	Runtime Runtime `json:"runtime"`
+	// RuntimeVersion 是容器内运行时的实际版本（如 Python 3.11.9）
+	RuntimeVersion string `json:"runtime_version,omitempty"`
+	// Image 是执行容器使用的镜像
+	Image string `json:"image"`
+	// NetworkMode 是容器网络模式
+	NetworkMode string `json:"network_mode"`
+	// MemoryLimitMB 是容器内可见的内存上限（MB），0 表示未限制
+	MemoryLimitMB int64 `json:"memory_limit_mb"`
+	// HostMemoryMB 是容器内可见的主机内存总量（MB）
+	HostMemoryMB int64 `json:"host_memory_mb"`
+	// CPUCount 是容器内可用的 CPU 数量
+	CPUCount int `json:"cpu_count"`
+	// TmpfsSizeMB 是 /tmp 的容量（MB）
+	TmpfsSizeMB int64 `json:"tmpfs_size_mb"`
+	// Layers 是函数挂载的层 ID，按加载顺序排列
+	Layers []string `json:"layers"`
+	// EnvVars 是解析后的环境变量，敏感值已脱敏
+	EnvVars map[string]string `json:"env_vars"`
+	// Pooled 表示探针是否运行在池化容器中
+	Pooled bool `json:"pooled"`
+	// CollectedAt 是诊断信息采集时间
+	CollectedAt time.Time `json:"collected_at"`
+}
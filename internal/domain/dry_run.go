@@ -0,0 +1,77 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了调度预演相关的类型。
+package domain
+
+import "time"
+
+// 执行环境池对一次调用的分配决策
+const (
+	// PoolDecisionWarm 表示复用预热实例（热启动）
+	PoolDecisionWarm = "warm"
+	// PoolDecisionCold 表示创建新实例（冷启动）
+	PoolDecisionCold = "cold"
+	// PoolDecisionQueue 表示池已满，需要排队等待实例归还
+	PoolDecisionQueue = "queue"
+)
+
+// 调度预演中调用会被拒绝的原因
+const (
+	// DryRunThrottleMaintenance 表示函数处于维护窗口
+	DryRunThrottleMaintenance = "maintenance"
+	// DryRunThrottleRateLimit 表示调用超出函数限流
+	DryRunThrottleRateLimit = "rate_limit"
+	// DryRunThrottleConcurrency 表示没有可用的并发槽位
+	DryRunThrottleConcurrency = "concurrency_limit"
+	// DryRunThrottleQueueFull 表示调度器工作队列已满
+	DryRunThrottleQueueFull = "queue_full"
+)
+
+// PoolPlan 描述执行环境池对一次调用的分配决策及池的当前状态（不实际分配）。
+type PoolPlan struct {
+	// Pool 是执行环境池标识（运行时:内存MB）
+	Pool string `json:"pool"`
+	// Decision 是分配决策：warm、cold 或 queue
+	Decision string `json:"decision"`
+	// WarmInstances 是当前空闲的预热实例数
+	WarmInstances int `json:"warm_instances"`
+	// BusyInstances 是当前正在执行的实例数
+	BusyInstances int `json:"busy_instances"`
+	// CreatingInstances 是正在创建中的实例数
+	CreatingInstances int `json:"creating_instances"`
+	// MaxInstances 是池的实例上限
+	MaxInstances int `json:"max_instances"`
+	// QueueTimeoutSec 是排队等待实例的超时时间（秒），0 表示最多等待至函数超时
+	QueueTimeoutSec int `json:"queue_timeout_sec,omitempty"`
+}
+
+// DryRunResult 描述一次调用的调度预演结果：经过调度的各项检查但不执行函数。
+type DryRunResult struct {
+	// FunctionID 是函数 ID
+	FunctionID string `json:"function_id"`
+	// FunctionName 是函数名称
+	FunctionName string `json:"function_name"`
+	// Runtime 是运行时
+	Runtime Runtime `json:"runtime"`
+	// MemoryMB 是内存规格（单位：MB）
+	MemoryMB int `json:"memory_mb"`
+	// WouldExecute 表示调用是否会被执行（可能需要排队）
+	WouldExecute bool `json:"would_execute"`
+	// ColdStart 表示调用是否会冷启动
+	ColdStart bool `json:"cold_start"`
+	// Throttle 是调用会被拒绝的原因，为空表示不会被拒绝
+	Throttle string `json:"throttle,omitempty"`
+	// ConcurrencySlot 是会占用的并发槽位类型：reserved（预留）或 shared（共享）
+	ConcurrencySlot string `json:"concurrency_slot,omitempty"`
+	// Priority 是调用的调度优先级
+	Priority InvocationPriority `json:"priority"`
+	// QueueDepth 是调度器中该优先级工作队列等待的调用数
+	QueueDepth int `json:"queue_depth"`
+	// QueueCapacity 是调度器中该优先级工作队列的容量
+	QueueCapacity int `json:"queue_capacity"`
+	// Pool 是执行环境池的分配决策，执行器不支持时为空
+	Pool *PoolPlan `json:"pool,omitempty"`
+	// RateLimit 是函数令牌桶的当前状态（不消耗令牌），未配置限流时为空
+	RateLimit *RateLimitStatus `json:"rate_limit,omitempty"`
+	// MaintenanceUntil 是维护窗口结束时间，不在维护窗口内时为空
+	MaintenanceUntil *time.Time `json:"maintenance_until,omitempty"`
+}
@@ -31,17 +31,134 @@ var (
 	ErrInvalidTimeout = errors.New("invalid timeout: must be between 1 and 300 seconds")
 	// ErrInvalidCronExpression 表示定时任务表达式无效
 	ErrInvalidCronExpression = errors.New("invalid cron expression")
+	// ErrInvalidHTTPPath 表示自定义 HTTP 路由路径无效（路径参数必须是完整的 {name} 段且不能重名）
+	ErrInvalidHTTPPath = errors.New("invalid http_path: path parameters must be whole {name} segments with unique names")
+	// ErrInvalidReservedConcurrency 表示预留并发数无效（不能为负数，且不能超过最大并发数）
+	ErrInvalidReservedConcurrency = errors.New("invalid reserved concurrency: must be non-negative and not exceed max_concurrency")
+	// ErrReservedConcurrencyExceedsCapacity 表示所有函数的预留并发总和超出调度器容量
+	ErrReservedConcurrencyExceedsCapacity = errors.New("total reserved concurrency exceeds scheduler capacity")
+	// ErrInvalidRateLimit 表示限流配置无效（速率必须为正数，突发容量不能为负数）
+	ErrInvalidRateLimit = errors.New("invalid rate limit: requests_per_second must be positive and burst must be non-negative")
+	// ErrInvalidBuildConfig 表示编译环境变量或编译参数不合法（非编译型运行时、不在白名单内或超出数量和长度限制）
+	ErrInvalidBuildConfig = errors.New("invalid build config: build_env and build_args are only supported for compiled runtimes and must match the runtime allowlist")
+	// ErrInvalidResponseCache 表示响应缓存配置无效（有效期必须在 1 到 86400 秒之间，缓存键表达式最多 16 个合法字段路径）
+	ErrInvalidResponseCache = errors.New("invalid response cache: ttl_seconds must be between 1 and 86400 and cache_key_expression must contain at most 16 valid field paths")
+	// ErrInvalidKeepWarm 表示常驻预热实例数无效（不能为负数，且不能超过上限）
+	ErrInvalidKeepWarm = errors.New("invalid keep_warm: must be between 0 and 50")
+	// ErrInvalidWarmupPayload 表示预热载荷无效（必须是合法的 JSON，且不能超过大小上限）
+	ErrInvalidWarmupPayload = errors.New("invalid warmup_payload: must be valid JSON of at most 64KB")
+	// ErrInvalidDeprecation 表示弃用配置无效（需要说明或下线时间，说明不能过长，拒绝调用需要下线时间）
+	ErrInvalidDeprecation = errors.New("invalid deprecation: message (at most 512 characters) or sunset_at is required, and reject_after_sunset requires sunset_at")
+	// ErrInvalidInitHandler 表示初始化函数配置无效（仅支持 python3.11 和 nodejs20，名称必须是合法标识符）
+	ErrInvalidInitHandler = errors.New("invalid init_handler: only supported for python3.11 and nodejs20, must be a function identifier")
+	// ErrInvalidEmptyResponse 表示无输出默认响应体无效（必须为合法 JSON 或 "none"）
+	ErrInvalidEmptyResponse = errors.New("invalid empty_response: must be valid JSON or \"none\"")
+	// ErrInvalidMaintenanceWindow 表示维护窗口配置无效
+	ErrInvalidMaintenanceWindow = errors.New("invalid maintenance window: schedule must be a valid cron expression and duration_sec must be between 1 and 604800")
+	// ErrInvalidDataVolume 表示数据卷配置无效（名称格式错误、重复或数量超过上限）
+	ErrInvalidDataVolume = errors.New("invalid data_volumes: names must be unique lowercase identifiers, at most 8 volumes")
+	// ErrDataVolumeNotRegistered 表示函数请求挂载的数据卷未在部署配置中注册
+	ErrDataVolumeNotRegistered = errors.New("data volume not registered")
+	// ErrInvalidSlotAlias 表示蓝绿槽位别名（blue/green）没有将 100% 流量指向单个版本
+	ErrInvalidSlotAlias = errors.New("invalid slot alias: blue/green aliases must route 100% of traffic to a single version")
+	// ErrSlotSwapConflict 表示蓝绿切换时线上槽位已被并发修改
+	ErrSlotSwapConflict = errors.New("live slot changed concurrently")
+	// ErrInvalidAllowedEnvironments 表示允许调用环境列表无效（名称为空、重复或数量超过上限）
+	ErrInvalidAllowedEnvironments = errors.New("invalid allowed_environments: names must be unique and non-empty, at most 16 environments")
+	// ErrEnvironmentNotAllowed 表示函数不允许在请求的环境中调用
+	ErrEnvironmentNotAllowed = errors.New("function is not allowed to be invoked in this environment")
+	// ErrRuntimeNotAllowed 表示函数的运行时被环境的运行时策略禁止
+	ErrRuntimeNotAllowed = errors.New("runtime is not allowed in this environment")
+	// ErrInvalidMaxReuse 表示容器最大复用次数无效（必须在 0 到 1000000 之间）
+	ErrInvalidMaxReuse = errors.New("invalid max_reuse: must be between 0 and 1000000")
+	// ErrInvalidStopGracePeriod 表示容器停止宽限期无效（必须在 0 到 120 秒之间）
+	ErrInvalidStopGracePeriod = errors.New("invalid stop_grace_period_sec: must be between 0 and 120")
+	// ErrInvalidMaxPayloadBytes 表示调用请求体大小上限无效（必须在 0 到 64MB 之间）
+	ErrInvalidMaxPayloadBytes = errors.New("invalid max_payload_bytes: must be between 0 and 67108864")
+	// ErrPayloadTooLarge 表示调用请求体超过大小上限
+	ErrPayloadTooLarge = errors.New("request payload too large")
+	// ErrInvalidCustomImage 表示自定义镜像配置无效
+	ErrInvalidCustomImage = errors.New("invalid custom image: custom_image must be a valid image reference, working_dir an absolute path, custom_command and args at most 64 items without NUL characters, and custom_command, working_dir and args require custom_image")
+	// ErrCustomImageNotAllowed 表示自定义镜像不在运维方配置的白名单内（或执行器不支持自定义镜像）
+	ErrCustomImageNotAllowed = errors.New("custom image is not in the allowlist")
+	// ErrInvalidCircuitBreaker 表示熔断配置无效
+	ErrInvalidCircuitBreaker = errors.New("invalid circuit_breaker: error_rate_percent must be between 1 and 100, window_sec at most 3600, cooldown_sec at most 86400, and no value may be negative")
+	// ErrInvalidDLQBulkRetry 表示死信批量重试的条件无效
+	ErrInvalidDLQBulkRetry = errors.New("invalid bulk retry: status must be pending or retrying, since must be before until, limit between 0 and 1000, concurrency between 0 and 20")
+	// ErrInvalidBatchInvoke 表示批量调用请求无效
+	ErrInvalidBatchInvoke = errors.New("invalid batch invoke: payloads must contain 1 to 100 items and max_parallel must be between 0 and 50")
+	// ErrInvalidAsyncResultWait 表示查询异步调用结果的 wait 参数无效
+	ErrInvalidAsyncResultWait = errors.New("invalid wait: must be a duration such as 30s or a number of seconds, between 0 and 50s")
+	// ErrInvalidCursor 表示分页游标无效（格式错误或不是由服务端生成）
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidPriority 表示调用优先级无效（必须为 high、normal 或 low）
+	ErrInvalidPriority = errors.New("invalid priority: must be one of high, normal, low")
+	// ErrRecursionLimitExceeded 表示调用链超出最大深度，或同一函数在调用链中出现次数过多（疑似无限递归）
+	ErrRecursionLimitExceeded = errors.New("recursion limit exceeded")
+	// ErrInvalidPipe 表示管道调用的 then 参数无效（函数名称不能为空，管道最多 4 个函数）
+	ErrInvalidPipe = errors.New("invalid then: comma-separated function names, at most 4 functions per pipe")
+	// ErrInvalidVersionRetention 表示版本保留数无效（必须为 -1、0 或 1 到 1000）
+	ErrInvalidVersionRetention = errors.New("invalid version_retention: must be -1 (keep all), 0 (use global setting) or between 1 and 1000")
+	// ErrExecOutputUnavailable 表示调用未在本节点执行中，无法订阅实时输出
+	ErrExecOutputUnavailable = errors.New("no live output for this invocation on this node")
+	// ErrInvalidLayerOverride 表示 X-Nimbus-Layers 请求头格式无效
+	ErrInvalidLayerOverride = errors.New("invalid X-Nimbus-Layers header: expected comma-separated layer:version pairs (max 10, no duplicates)")
+	// ErrLayerOverrideUnsupported 表示当前调度器不支持调用时覆盖函数层
+	ErrLayerOverrideUnsupported = errors.New("layer override is not supported by this scheduler")
+	// ErrInvalidLayerContent 表示层内容不是合法的 ZIP 压缩包
+	ErrInvalidLayerContent = errors.New("invalid layer content: must be a zip archive")
+	// ErrTooManyLayers 表示函数挂载的层数超过上限
+	ErrTooManyLayers = errors.New("too many layers")
+	// ErrLayersTooLarge 表示函数挂载的层解压后的总大小超过上限
+	ErrLayersTooLarge = errors.New("combined unpacked layer size exceeds limit")
+	// ErrFunctionInMaintenance 表示函数处于维护窗口内，暂不接受调用
+	ErrFunctionInMaintenance = errors.New("function is in maintenance")
+	// ErrFunctionPaused 表示函数已暂停，同步调用被拒绝（异步调用进入暂停队列）
+	ErrFunctionPaused = errors.New("function is paused")
+	// ErrFunctionUnavailable 表示函数存在但当前状态（下线、构建中、失败等）不接受调用
+	ErrFunctionUnavailable = errors.New("function is temporarily unavailable")
+	// ErrFunctionSunset 表示函数已过下线时间且配置为拒绝调用
+	ErrFunctionSunset = errors.New("function has been retired after its sunset date")
+	// ErrKillSwitchEngaged 表示全局或函数级紧急停止开关已开启，调用被拒绝
+	ErrKillSwitchEngaged = errors.New("invocations stopped by kill switch")
+	// ErrInvalidKillSwitchTTL 表示紧急停止开关的有效期无效（必须在 0 到 604800 秒之间）
+	ErrInvalidKillSwitchTTL = errors.New("invalid ttl_seconds: must be between 0 and 604800")
 
 	// ========== 调用相关错误 ==========
 
 	// ErrInvocationNotFound 表示请求的调用记录不存在
 	ErrInvocationNotFound = errors.New("invocation not found")
+	// ErrArtifactNotFound 表示调用没有指定名称的产物文件
+	ErrArtifactNotFound = errors.New("artifact not found")
+	// ErrInvalidInvocationSearch 表示调用记录搜索条件无效
+	ErrInvalidInvocationSearch = errors.New("invalid invocation search")
+	// ErrInvocationPayloadSearchDisabled 表示部署未开启调用输入输出的子串搜索
+	ErrInvocationPayloadSearchDisabled = errors.New("invocation payload search is disabled (storage.postgres.invocation_payload_search)")
 	// ErrInvocationTimeout 表示函数调用执行超时
 	ErrInvocationTimeout = errors.New("invocation timed out")
 	// ErrInvocationFailed 表示函数调用执行失败
 	ErrInvocationFailed = errors.New("invocation failed")
 	// ErrInvocationCancelled 表示函数调用被取消
 	ErrInvocationCancelled = errors.New("invocation cancelled")
+	// ErrAsyncQueueUnavailable 表示异步调用队列不可用（如本地队列已满且 Redis 不可用）
+	ErrAsyncQueueUnavailable = errors.New("async invocation queue unavailable")
+	// ErrConcurrencyLimitExceeded 表示没有可用的并发配额（共享容量已被占满），调用被限流
+	ErrConcurrencyLimitExceeded = errors.New("concurrency limit exceeded")
+	// ErrQueueTimeout 表示在排队超时时间内未能获取到可用的执行实例
+	ErrQueueTimeout = errors.New("queue timeout")
+	// ErrContainerStartFailed 表示执行函数的容器创建或启动失败（平台故障，与函数代码无关）
+	ErrContainerStartFailed = errors.New("container start failed")
+	// ErrInvalidCostTags 表示成本标签格式无效或数量、长度超过上限
+	ErrInvalidCostTags = errors.New("invalid cost tags: expected up to 8 comma-separated key=value pairs (key <= 32, value <= 64 chars of [A-Za-z0-9-_./:])")
+	// ErrRateLimitExceeded 表示调用超出函数的限流配置
+	ErrRateLimitExceeded = errors.New("rate limit exceeded")
+
+	// ========== 影子流量相关错误 ==========
+
+	// ErrShadowConfigNotFound 表示函数未配置影子流量
+	ErrShadowConfigNotFound = errors.New("shadow config not found")
+	// ErrInvalidShadowConfig 表示影子流量配置无效（百分比需在 0-100 之间，且目标不能是主函数当前代码）
+	ErrInvalidShadowConfig = errors.New("invalid shadow config: percentage must be between 0 and 100 and target must differ from the primary")
 
 	// ========== 虚拟机相关错误 ==========
 
@@ -122,6 +239,10 @@ var (
 	ErrInvalidTemplateCategory = errors.New("invalid template category")
 	// ErrInvalidTemplateID 表示模板 ID 无效
 	ErrInvalidTemplateID = errors.New("invalid template id")
+	// ErrInvalidTemplateVariable 表示模板变量定义无效（名称、类型或默认值）
+	ErrInvalidTemplateVariable = errors.New("invalid template variable")
+	// ErrInvalidTemplateDocument 表示导入的模板文档格式无效
+	ErrInvalidTemplateDocument = errors.New("invalid template document")
 
 	// ========== 版本管理相关错误 ==========
 
@@ -143,3 +264,31 @@ var (
 	// ErrCannotDeleteLatest 表示无法删除 latest 别名
 	ErrCannotDeleteLatest = errors.New("cannot delete 'latest' alias")
 )
+
+// IsTransientPlatformError 判断执行器返回的错误是否为可重试的瞬时平台故障。
+// 仅包括发生在函数代码开始执行之前的故障（获取实例排队超时、容器或虚拟机启动失败、存储连接中断），
+// 重试不会导致函数代码被重复执行；函数异常、超时和配置错误都不属于此类。
+//
+// 参数:
+//   - err: 执行器返回的错误
+//
+// 返回值:
+//   - bool: 可安全重试时返回 true
+func IsTransientPlatformError(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, target := range []error{
+		ErrQueueTimeout,
+		ErrContainerStartFailed,
+		ErrVMStartFailed,
+		ErrNoAvailableVM,
+		ErrVMPoolExhausted,
+		ErrStorageConnection,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
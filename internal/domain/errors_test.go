@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsTransientPlatformError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{ErrQueueTimeout, true},
+		{fmt.Errorf("%w: docker create: exit status 125", ErrContainerStartFailed), true},
+		{fmt.Errorf("%w: boot timeout", ErrVMStartFailed), true},
+		{context.DeadlineExceeded, false},
+		{ErrLayerOverrideUnsupported, false},
+		{errors.New("unsupported runtime: cobol"), false},
+	}
+	for _, c := range cases {
+		if got := IsTransientPlatformError(c.err); got != c.want {
+			t.Errorf("IsTransientPlatformError(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
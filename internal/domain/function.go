@@ -4,7 +4,16 @@
 package domain
 
 import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"hash/fnv"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -24,6 +33,8 @@ const (
 	RuntimeGo124 Runtime = "go1.24"
 	// RuntimeWasm 表示 WebAssembly 运行时环境
 	RuntimeWasm Runtime = "wasm"
+	// RuntimeBun 表示 Bun 1.x 运行时环境（原生支持 JavaScript 和 TypeScript）
+	RuntimeBun Runtime = "bun1"
 )
 
 // 代码大小限制常量
@@ -80,7 +91,7 @@ func GetCodeSizeInfo(code string) (size int, limit int, percentage float64) {
 // 返回 true 表示该运行时是受支持的，返回 false 表示不受支持。
 func (r Runtime) IsValid() bool {
 	switch r {
-	case RuntimePython311, RuntimeNodeJS20, RuntimeGo124, RuntimeWasm:
+	case RuntimePython311, RuntimeNodeJS20, RuntimeGo124, RuntimeWasm, RuntimeBun:
 		return true
 	default:
 		return false
@@ -107,26 +118,56 @@ const (
 	FunctionStatusBuilding FunctionStatus = "building"
 	// FunctionStatusFailed 表示函数构建或部署失败
 	FunctionStatusFailed FunctionStatus = "failed"
+	// FunctionStatusDegraded 表示函数连续多次初始化失败，处于明显异常状态。
+	// 该状态是告警标记而不是下线：函数仍可调用，下一次成功调用后自动恢复为 active，
+	// 因此不能阻断调用，否则函数永远没有机会恢复
+	FunctionStatusDegraded FunctionStatus = "degraded"
+	// FunctionStatusPaused 表示函数已暂停：同步调用被拒绝，异步调用进入暂停队列，恢复后依次执行
+	FunctionStatusPaused FunctionStatus = "paused"
+	// FunctionStatusCircuitOpen 表示函数错误率超过熔断阈值被自动下线，冷却期结束后自动恢复并试探
+	FunctionStatusCircuitOpen FunctionStatus = "circuit_open"
 )
 
-// CanInvoke 检查当前状态是否可以调用函数
+// CanInvoke 检查当前状态是否可以调用函数（degraded 的函数仍可调用，见 FunctionStatusDegraded）
 func (s FunctionStatus) CanInvoke() bool {
-	return s == FunctionStatusActive
+	return s == FunctionStatusActive || s == FunctionStatusDegraded
+}
+
+// RetryAfterSeconds 返回函数因当前状态不可调用时建议客户端等待的秒数（用于 Retry-After 响应头）。
+// 创建、更新、构建中的函数很快会恢复可用，其余状态需要人工处理，建议等待更久。
+func (s FunctionStatus) RetryAfterSeconds() int {
+	switch s {
+	case FunctionStatusCreating, FunctionStatusUpdating, FunctionStatusBuilding:
+		return 30
+	default:
+		return 300
+	}
 }
 
 // CanUpdate 检查当前状态是否可以更新函数
 func (s FunctionStatus) CanUpdate() bool {
-	return s == FunctionStatusActive || s == FunctionStatusFailed || s == FunctionStatusOffline
+	return s == FunctionStatusActive || s == FunctionStatusFailed || s == FunctionStatusOffline || s == FunctionStatusDegraded ||
+		s == FunctionStatusCircuitOpen
+}
+
+// CanInvokeAsync 检查当前状态是否接受异步调用（暂停的函数接受异步调用但暂不执行）
+func (s FunctionStatus) CanInvokeAsync() bool {
+	return s.CanInvoke() || s == FunctionStatusPaused
 }
 
 // CanOffline 检查当前状态是否可以下线
 func (s FunctionStatus) CanOffline() bool {
-	return s == FunctionStatusActive
+	return s == FunctionStatusActive || s == FunctionStatusDegraded || s == FunctionStatusPaused || s == FunctionStatusCircuitOpen
 }
 
-// CanOnline 检查当前状态是否可以上线
+// CanOnline 检查当前状态是否可以上线（熔断的函数可以手动提前恢复）
 func (s FunctionStatus) CanOnline() bool {
-	return s == FunctionStatusOffline
+	return s == FunctionStatusOffline || s == FunctionStatusPaused || s == FunctionStatusCircuitOpen
+}
+
+// CanPause 检查当前状态是否可以暂停
+func (s FunctionStatus) CanPause() bool {
+	return s == FunctionStatusActive || s == FunctionStatusDegraded
 }
 
 // Function 表示一个无服务器函数实体。
@@ -158,6 +199,21 @@ type Function struct {
 	TimeoutSec int `json:"timeout_sec"`
 	// MaxConcurrency 是函数的最大并发执行数（0 表示无限制）
 	MaxConcurrency int `json:"max_concurrency"`
+	// ReservedConcurrency 是为函数独占预留的并发槽位数（0 表示不预留）
+	// 预留槽位从共享容量中扣除，其他函数的突发流量无法占用
+	ReservedConcurrency int `json:"reserved_concurrency"`
+	// KeepWarm 是常驻预热实例数（0 表示不常驻）
+	// 后台协调器会为函数的运行时/内存规格始终保持至少这么多热实例，实例老化后自动重建
+	KeepWarm int `json:"keep_warm"`
+	// WarmupPayload 是预热载荷（可选），常驻预热实例创建后以该载荷执行一次函数，
+	// 确认实例可以正常处理请求；执行失败的实例被销毁，不会交给真实调用
+	WarmupPayload json.RawMessage `json:"warmup_payload,omitempty"`
+	// InitHandler 是初始化函数名称（可选，仅解释型运行时），运行时进程启动时执行一次，
+	// 返回值通过 context 传给每次 handler 调用，用于建立连接池、加载模型等昂贵的准备工作
+	InitHandler string `json:"init_handler,omitempty"`
+	// EmptyResponse 是函数成功执行但没有输出时返回的默认响应体（JSON 文本）
+	// 为空时使用全局默认值，EmptyResponseNone 表示返回空响应体
+	EmptyResponse string `json:"empty_response,omitempty"`
 	// EnvVars 是函数的环境变量配置
 	EnvVars map[string]string `json:"env_vars,omitempty"`
 	// Status 是函数的当前状态
@@ -182,6 +238,48 @@ type Function struct {
 	LastDeployedAt *time.Time `json:"last_deployed_at,omitempty"`
 	// StateConfig 是状态配置（可选），用于启用有状态函数功能
 	StateConfig *StateConfig `json:"state_config,omitempty"`
+	// RateLimit 是调用限流配置（可选），为空表示不限流
+	RateLimit *RateLimitConfig `json:"rate_limit,omitempty"`
+	// ResponseCache 是响应缓存配置（可选），为空表示不缓存
+	ResponseCache *ResponseCacheConfig `json:"response_cache,omitempty"`
+	// BuildEnv 是编译时注入的环境变量（仅编译型运行时），随函数保存以保证重新编译结果一致
+	BuildEnv map[string]string `json:"build_env,omitempty"`
+	// BuildArgs 是追加到编译命令的参数（仅编译型运行时），如 -ldflags=-X main.version=1.2.0
+	BuildArgs []string `json:"build_args,omitempty"`
+	// MaintenanceWindows 是维护窗口配置（可选），窗口内的同步调用被拒绝，异步调用延迟到窗口结束后执行
+	MaintenanceWindows []MaintenanceWindow `json:"maintenance_windows,omitempty"`
+	// Deprecation 是弃用配置（可选），设置后调用响应携带 Deprecation/Sunset 响应头，为空表示未弃用
+	Deprecation *FunctionDeprecation `json:"deprecation,omitempty"`
+	// DataVolumes 是挂载到函数容器中的共享数据卷名称（可选），只读挂载到 DataVolumeMountDir/<名称>
+	DataVolumes []string `json:"data_volumes,omitempty"`
+	// MaxReuse 是单个预热容器执行该函数后允许的最大复用次数（可选），0 表示使用容器池设置；
+	// 用于让存在内存泄漏的函数更频繁地回收容器
+	MaxReuse int `json:"max_reuse,omitempty"`
+	// StopGracePeriodSec 是销毁执行过该函数的预热容器时的停止宽限期（秒，可选），
+	// 大于 0 时先发送 SIGTERM 并最多等待该时长让函数进程清理（刷新缓冲、关闭连接），0 表示立即强制删除
+	StopGracePeriodSec int `json:"stop_grace_period_sec,omitempty"`
+	// MaxPayloadBytes 是调用请求体的大小上限（字节，可选），0 表示使用网关的全局设置
+	MaxPayloadBytes int64 `json:"max_payload_bytes,omitempty"`
+	// CircuitBreaker 是函数级熔断配置（可选），错误率超过阈值时自动下线为 circuit_open，为空表示不熔断
+	CircuitBreaker *CircuitBreakerConfig `json:"circuit_breaker,omitempty"`
+	// CustomImage 是替换运行时镜像的自定义镜像（可选，仅 Docker 模式），必须在部署配置的白名单内；
+	// 镜像需遵循与运行时相同的输入约定：从 stdin 读取调用输入，向 stdout 写出结果
+	CustomImage string `json:"custom_image,omitempty"`
+	// CustomCommand 是替换自定义镜像 ENTRYPOINT 和 CMD 的命令（可选），为空表示使用镜像自身的入口点
+	CustomCommand []string `json:"custom_command,omitempty"`
+	// WorkingDir 是自定义镜像容器内的工作目录（可选，绝对路径），为空表示使用镜像的 WORKDIR
+	WorkingDir string `json:"working_dir,omitempty"`
+	// Args 是追加在命令之后的参数（可选）；未设置 CustomCommand 时替换镜像的 CMD，与 docker run 一致
+	Args []string `json:"args,omitempty"`
+	// Priority 是调用在调度队列中的优先级（可选），为空表示按触发来源取默认优先级
+	Priority InvocationPriority `json:"priority,omitempty"`
+	// VersionRetention 是保留的最新版本数（可选），0 表示使用全局设置，-1 表示保留全部版本；
+	// 被别名或影子流量引用的版本始终保留
+	VersionRetention int `json:"version_retention,omitempty"`
+	// AllowedEnvironments 是允许调用该函数的环境名称（可选），为空表示所有环境均可调用
+	AllowedEnvironments []string `json:"allowed_environments,omitempty"`
+	// LiveSlot 是蓝绿部署中当前承接流量的槽位（blue 或 green），为空表示未启用蓝绿部署
+	LiveSlot string `json:"live_slot,omitempty"`
 	// CreatedAt 是函数的创建时间
 	CreatedAt time.Time `json:"created_at"`
 	// UpdatedAt 是函数的最后更新时间
@@ -260,6 +358,50 @@ type CreateFunctionRequest struct {
 	TimeoutSec int `json:"timeout_sec,omitempty"`
 	// MaxConcurrency 是最大并发数，可选，默认 0（无限制）
 	MaxConcurrency int `json:"max_concurrency,omitempty"`
+	// ReservedConcurrency 是预留并发数，可选，默认 0（不预留）
+	ReservedConcurrency int `json:"reserved_concurrency,omitempty"`
+	// KeepWarm 是常驻预热实例数，可选，默认 0（不常驻）
+	KeepWarm int `json:"keep_warm,omitempty"`
+	// WarmupPayload 是预热载荷，可选，常驻预热实例创建后以该载荷执行一次函数
+	WarmupPayload json.RawMessage `json:"warmup_payload,omitempty"`
+	// InitHandler 是初始化函数名称，可选，仅支持 python3.11 和 nodejs20
+	InitHandler string `json:"init_handler,omitempty"`
+	// EmptyResponse 是无输出时的默认响应体，可选，默认使用全局配置
+	EmptyResponse string `json:"empty_response,omitempty"`
+	// RateLimit 是调用限流配置，可选，默认不限流
+	RateLimit *RateLimitConfig `json:"rate_limit,omitempty"`
+	// ResponseCache 是响应缓存配置，可选，默认不缓存
+	ResponseCache *ResponseCacheConfig `json:"response_cache,omitempty"`
+	// BuildEnv 是编译时注入的环境变量，可选，仅编译型运行时
+	BuildEnv map[string]string `json:"build_env,omitempty"`
+	// BuildArgs 是追加到编译命令的参数，可选，仅编译型运行时
+	BuildArgs []string `json:"build_args,omitempty"`
+	// MaintenanceWindows 是维护窗口配置，可选
+	MaintenanceWindows []MaintenanceWindow `json:"maintenance_windows,omitempty"`
+	// DataVolumes 是挂载的共享数据卷名称，可选，必须是运维方已注册的数据卷
+	DataVolumes []string `json:"data_volumes,omitempty"`
+	// MaxReuse 是预热容器的最大复用次数，可选，0 表示使用容器池设置
+	MaxReuse int `json:"max_reuse,omitempty"`
+	// StopGracePeriodSec 是容器停止宽限期（秒），可选，0 表示立即强制删除
+	StopGracePeriodSec int `json:"stop_grace_period_sec,omitempty"`
+	// MaxPayloadBytes 是调用请求体的大小上限（字节），可选，0 表示使用网关的全局设置
+	MaxPayloadBytes int64 `json:"max_payload_bytes,omitempty"`
+	// CircuitBreaker 是函数级熔断配置，可选，为空表示不熔断
+	CircuitBreaker *CircuitBreakerConfig `json:"circuit_breaker,omitempty"`
+	// CustomImage 是自定义镜像，可选，为空表示使用运行时镜像
+	CustomImage string `json:"custom_image,omitempty"`
+	// CustomCommand 是自定义镜像的命令，可选，为空表示使用镜像自身的入口点
+	CustomCommand []string `json:"custom_command,omitempty"`
+	// WorkingDir 是自定义镜像容器内的工作目录，可选
+	WorkingDir string `json:"working_dir,omitempty"`
+	// Args 是追加在命令之后的参数，可选
+	Args []string `json:"args,omitempty"`
+	// Priority 是调用优先级（high/normal/low），可选，为空表示按触发来源取默认值
+	Priority InvocationPriority `json:"priority,omitempty"`
+	// VersionRetention 是保留的最新版本数，可选，0 表示使用全局设置，-1 表示保留全部版本
+	VersionRetention int `json:"version_retention,omitempty"`
+	// AllowedEnvironments 是允许调用的环境名称，可选，为空表示所有环境
+	AllowedEnvironments []string `json:"allowed_environments,omitempty"`
 	// EnvVars 是环境变量配置，可选
 	EnvVars map[string]string `json:"env_vars,omitempty"`
 	// CronExpression 是定时任务表达式（可选）
@@ -280,8 +422,8 @@ func (r *CreateFunctionRequest) Validate() error {
 	if !r.Runtime.IsValid() {
 		return ErrInvalidRuntime
 	}
-	if r.Handler == "" {
-		return ErrInvalidHandler
+	if err := ValidateHandler(r.Runtime, r.Handler); err != nil {
+		return err
 	}
 	if r.Code == "" {
 		return ErrInvalidCode
@@ -300,6 +442,9 @@ func (r *CreateFunctionRequest) Validate() error {
 	if err := ValidateCronExpression(r.CronExpression); err != nil {
 		return err
 	}
+	if err := ValidateHTTPPath(r.HTTPPath); err != nil {
+		return err
+	}
 	// 如果未指定内存，设置默认值为 256MB
 	if r.MemoryMB == 0 {
 		r.MemoryMB = 256
@@ -316,9 +461,188 @@ func (r *CreateFunctionRequest) Validate() error {
 	if r.TimeoutSec < 1 || r.TimeoutSec > 300 {
 		return ErrInvalidTimeout
 	}
+	if r.RateLimit != nil {
+		if err := r.RateLimit.Validate(); err != nil {
+			return err
+		}
+	}
+	if r.ResponseCache != nil {
+		if err := r.ResponseCache.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := ValidateKeepWarm(r.KeepWarm); err != nil {
+		return err
+	}
+	if err := ValidateWarmupPayload(r.WarmupPayload); err != nil {
+		return err
+	}
+	if err := ValidateEmptyResponse(r.EmptyResponse); err != nil {
+		return err
+	}
+	if err := ValidateInitHandler(r.Runtime, r.InitHandler); err != nil {
+		return err
+	}
+	if err := ValidateMaintenanceWindows(r.MaintenanceWindows); err != nil {
+		return err
+	}
+	if err := ValidateDataVolumes(r.DataVolumes); err != nil {
+		return err
+	}
+	if err := ValidateAllowedEnvironments(r.AllowedEnvironments); err != nil {
+		return err
+	}
+	if err := ValidateVersionRetention(r.VersionRetention); err != nil {
+		return err
+	}
+	if err := ValidateMaxReuse(r.MaxReuse); err != nil {
+		return err
+	}
+	if err := ValidateStopGracePeriod(r.StopGracePeriodSec); err != nil {
+		return err
+	}
+	if err := ValidateMaxPayloadBytes(r.MaxPayloadBytes); err != nil {
+		return err
+	}
+	if r.CircuitBreaker != nil {
+		if err := r.CircuitBreaker.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := ValidateCustomImage(r.CustomImage, r.CustomCommand, r.WorkingDir, r.Args); err != nil {
+		return err
+	}
+	if err := ValidatePriority(r.Priority); err != nil {
+		return err
+	}
+	if err := ValidateBuildConfig(r.Runtime, r.BuildEnv, r.BuildArgs); err != nil {
+		return err
+	}
+	return ValidateReservedConcurrency(r.ReservedConcurrency, r.MaxConcurrency)
+}
+
+// ValidateReservedConcurrency 验证预留并发数。
+// 预留并发数不能为负数；设置了最大并发数时，预留并发数不能超过最大并发数。
+func ValidateReservedConcurrency(reserved, maxConcurrency int) error {
+	if reserved < 0 {
+		return ErrInvalidReservedConcurrency
+	}
+	if maxConcurrency > 0 && reserved > maxConcurrency {
+		return ErrInvalidReservedConcurrency
+	}
+	return nil
+}
+
+// MaxKeepWarm 是单个函数允许的常驻预热实例数上限
+const MaxKeepWarm = 50
+
+// ValidateKeepWarm 验证常驻预热实例数，必须在 [0, MaxKeepWarm] 范围内。
+func ValidateKeepWarm(n int) error {
+	if n < 0 || n > MaxKeepWarm {
+		return ErrInvalidKeepWarm
+	}
+	return nil
+}
+
+// MaxWarmupPayloadBytes 是预热载荷的大小上限（字节）
+const MaxWarmupPayloadBytes = 64 * 1024
+
+// EnvWarmup 是预热试执行时注入函数环境的变量（值为 "1"），函数可据此跳过有副作用的逻辑
+const EnvWarmup = "NIMBUS_WARMUP"
+
+// ValidateWarmupPayload 验证预热载荷，为空表示不预热执行，否则必须是不超过 MaxWarmupPayloadBytes 的合法 JSON。
+func ValidateWarmupPayload(payload json.RawMessage) error {
+	if len(payload) == 0 {
+		return nil
+	}
+	if len(payload) > MaxWarmupPayloadBytes || !json.Valid(payload) {
+		return ErrInvalidWarmupPayload
+	}
 	return nil
 }
 
+// DefaultHandlerFile 是 handler 未指定入口文件时使用的文件名（不含扩展名）
+const DefaultHandlerFile = "handler"
+
+// validHandler 匹配函数入口点：可选的入口文件路径（不含扩展名，目录以 / 分隔）加 "." 和函数名，
+// 如 "handler"、"index.handler"、"src/main.handler"
+var validHandler = regexp.MustCompile(`^(?:[A-Za-z0-9_-]+(?:/[A-Za-z0-9_-]+)*\.)?[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// ValidateHandler 验证函数入口点格式，必须是函数名或 "文件路径.函数名"，总长度不超过 256。
+// Python 的函数名不允许包含 "$"。
+func ValidateHandler(runtime Runtime, handler string) error {
+	if handler == "" || len(handler) > 256 || !validHandler.MatchString(handler) {
+		return ErrInvalidHandler
+	}
+	if runtime == RuntimePython311 && strings.Contains(handler, "$") {
+		return ErrInvalidHandler
+	}
+	return nil
+}
+
+// ParseHandler 将入口点拆分为入口文件路径（不含扩展名）和函数名。
+// 未指定文件时（如 "handler"）入口文件为 DefaultHandlerFile。
+//
+// 参数:
+//   - handler: 入口点，如 "src/main.handler"
+//
+// 返回值:
+//   - string: 入口文件路径，如 "src/main"
+//   - string: 函数名，如 "handler"
+func ParseHandler(handler string) (string, string) {
+	i := strings.LastIndex(handler, ".")
+	if i < 0 {
+		return DefaultHandlerFile, handler
+	}
+	return handler[:i], handler[i+1:]
+}
+
+// validInitHandler 匹配初始化函数名称：与 handler 定义在同一份代码中的函数标识符
+var validInitHandler = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]{0,63}$`)
+
+// ValidateInitHandler 验证初始化函数配置。
+// 初始化函数只支持解释型运行时（python3.11、nodejs20），名称必须是合法的函数标识符。
+func ValidateInitHandler(runtime Runtime, name string) error {
+	if name == "" {
+		return nil
+	}
+	if runtime != RuntimePython311 && runtime != RuntimeNodeJS20 {
+		return ErrInvalidInitHandler
+	}
+	if !validInitHandler.MatchString(name) || (runtime == RuntimePython311 && strings.Contains(name, "$")) {
+		return ErrInvalidInitHandler
+	}
+	return nil
+}
+
+// EmptyResponseNone 表示函数无输出时返回空响应体（Content-Length: 0）
+const EmptyResponseNone = "none"
+
+// ValidateEmptyResponse 验证无输出时的默认响应体，必须为空、EmptyResponseNone 或合法 JSON。
+func ValidateEmptyResponse(s string) error {
+	if s == "" || s == EmptyResponseNone || json.Valid([]byte(s)) {
+		return nil
+	}
+	return ErrInvalidEmptyResponse
+}
+
+// ResolveEmptyResponse 计算函数无输出时实际返回的响应体。
+// 函数级配置优先于全局默认值；结果为 EmptyResponseNone 或未配置时返回 nil（空响应体）。
+//
+// 参数:
+//   - fnValue: 函数的 EmptyResponse 配置
+//   - globalDefault: 全局默认响应体
+func ResolveEmptyResponse(fnValue, globalDefault string) json.RawMessage {
+	value := fnValue
+	if value == "" {
+		value = globalDefault
+	}
+	if value == "" || value == EmptyResponseNone {
+		return nil
+	}
+	return json.RawMessage(value)
+}
+
 // UpdateFunctionRequest 表示更新函数的请求结构体。
 // 所有字段都是指针类型，允许部分更新（只更新非 nil 的字段）。
 type UpdateFunctionRequest struct {
@@ -336,6 +660,52 @@ type UpdateFunctionRequest struct {
 	TimeoutSec *int `json:"timeout_sec,omitempty"`
 	// MaxConcurrency 是更新后的最大并发数
 	MaxConcurrency *int `json:"max_concurrency,omitempty"`
+	// ReservedConcurrency 是更新后的预留并发数
+	ReservedConcurrency *int `json:"reserved_concurrency,omitempty"`
+	// KeepWarm 是更新后的常驻预热实例数，0 表示取消常驻
+	KeepWarm *int `json:"keep_warm,omitempty"`
+	// WarmupPayload 是更新后的预热载荷，未提供时不变，null 表示取消预热载荷
+	WarmupPayload json.RawMessage `json:"warmup_payload,omitempty"`
+	// InitHandler 是更新后的初始化函数名称，空字符串表示取消初始化函数
+	InitHandler *string `json:"init_handler,omitempty"`
+	// EmptyResponse 是更新后的无输出默认响应体，空字符串表示使用全局配置
+	EmptyResponse *string `json:"empty_response,omitempty"`
+	// RateLimit 是更新后的调用限流配置，requests_per_second 为 0 表示取消限流
+	RateLimit *RateLimitConfig `json:"rate_limit,omitempty"`
+	// ResponseCache 是更新后的响应缓存配置，ttl_seconds 为 0 表示取消缓存
+	ResponseCache *ResponseCacheConfig `json:"response_cache,omitempty"`
+	// BuildEnv 是更新后的编译环境变量，空对象表示清除；变更后重新编译
+	BuildEnv *map[string]string `json:"build_env,omitempty"`
+	// BuildArgs 是更新后的编译参数，空数组表示清除；变更后重新编译
+	BuildArgs *[]string `json:"build_args,omitempty"`
+	// Deprecation 是更新后的弃用配置，空对象表示取消弃用
+	Deprecation *FunctionDeprecation `json:"deprecation,omitempty"`
+	// MaintenanceWindows 是更新后的维护窗口配置，空数组表示取消所有维护窗口
+	MaintenanceWindows *[]MaintenanceWindow `json:"maintenance_windows,omitempty"`
+	// DataVolumes 是更新后的共享数据卷名称，空数组表示取消所有挂载
+	DataVolumes *[]string `json:"data_volumes,omitempty"`
+	// MaxReuse 是更新后的预热容器最大复用次数，0 表示使用容器池设置
+	MaxReuse *int `json:"max_reuse,omitempty"`
+	// StopGracePeriodSec 是更新后的容器停止宽限期（秒），0 表示立即强制删除
+	StopGracePeriodSec *int `json:"stop_grace_period_sec,omitempty"`
+	// MaxPayloadBytes 是更新后的调用请求体大小上限（字节），0 表示使用网关的全局设置
+	MaxPayloadBytes *int64 `json:"max_payload_bytes,omitempty"`
+	// CircuitBreaker 是更新后的熔断配置，空对象表示关闭熔断
+	CircuitBreaker *CircuitBreakerConfig `json:"circuit_breaker,omitempty"`
+	// CustomImage 是更新后的自定义镜像，空字符串表示改回运行时镜像（需同时清空命令、工作目录和参数）
+	CustomImage *string `json:"custom_image,omitempty"`
+	// CustomCommand 是更新后的自定义镜像命令，空数组表示使用镜像自身的入口点
+	CustomCommand *[]string `json:"custom_command,omitempty"`
+	// WorkingDir 是更新后的工作目录，空字符串表示使用镜像的 WORKDIR
+	WorkingDir *string `json:"working_dir,omitempty"`
+	// Args 是更新后的参数，空数组表示不追加参数
+	Args *[]string `json:"args,omitempty"`
+	// Priority 是更新后的调用优先级，空字符串表示按触发来源取默认值
+	Priority *InvocationPriority `json:"priority,omitempty"`
+	// VersionRetention 是更新后的版本保留数，0 表示使用全局设置，-1 表示保留全部版本
+	VersionRetention *int `json:"version_retention,omitempty"`
+	// AllowedEnvironments 是更新后的允许调用环境，空数组表示所有环境
+	AllowedEnvironments *[]string `json:"allowed_environments,omitempty"`
 	// EnvVars 是更新后的环境变量配置
 	EnvVars *map[string]string `json:"env_vars,omitempty"`
 	// CronExpression 是更新后的定时任务表达式
@@ -346,6 +716,82 @@ type UpdateFunctionRequest struct {
 	HTTPMethods *[]string `json:"http_methods,omitempty"`
 }
 
+// FieldChange 表示函数某个字段在更新前后的取值。
+type FieldChange struct {
+	// Old 是更新前的值
+	Old interface{} `json:"old"`
+	// New 是更新后的值
+	New interface{} `json:"new"`
+}
+
+// DiffFunctions 比较更新前后的函数配置，返回发生变化的字段。
+// 代码内容可能很大，因此代码变更以 code_hash 的形式体现。
+//
+// 参数:
+//   - before: 更新前的函数
+//   - after: 应用更新后的函数
+//
+// 返回值:
+//   - map[string]FieldChange: 以 JSON 字段名为键的变更集合，无变更时为空 map
+func DiffFunctions(before, after *Function) map[string]FieldChange {
+	changes := make(map[string]FieldChange)
+	add := func(field string, oldVal, newVal interface{}) {
+		if !reflect.DeepEqual(oldVal, newVal) {
+			changes[field] = FieldChange{Old: oldVal, New: newVal}
+		}
+	}
+
+	add("description", before.Description, after.Description)
+	add("tags", normalizeStrings(before.Tags), normalizeStrings(after.Tags))
+	add("handler", before.Handler, after.Handler)
+	add("code_hash", before.CodeHash, after.CodeHash)
+	add("memory_mb", before.MemoryMB, after.MemoryMB)
+	add("timeout_sec", before.TimeoutSec, after.TimeoutSec)
+	add("max_concurrency", before.MaxConcurrency, after.MaxConcurrency)
+	add("reserved_concurrency", before.ReservedConcurrency, after.ReservedConcurrency)
+	add("keep_warm", before.KeepWarm, after.KeepWarm)
+	add("warmup_payload", string(before.WarmupPayload), string(after.WarmupPayload))
+	add("init_handler", before.InitHandler, after.InitHandler)
+	add("empty_response", before.EmptyResponse, after.EmptyResponse)
+	add("rate_limit", before.RateLimit, after.RateLimit)
+	add("maintenance_windows", before.MaintenanceWindows, after.MaintenanceWindows)
+	add("deprecation", before.Deprecation, after.Deprecation)
+	add("data_volumes", normalizeStrings(before.DataVolumes), normalizeStrings(after.DataVolumes))
+	add("max_reuse", before.MaxReuse, after.MaxReuse)
+	add("stop_grace_period_sec", before.StopGracePeriodSec, after.StopGracePeriodSec)
+	add("max_payload_bytes", before.MaxPayloadBytes, after.MaxPayloadBytes)
+	add("circuit_breaker", before.CircuitBreaker, after.CircuitBreaker)
+	add("custom_image", before.CustomImage, after.CustomImage)
+	add("custom_command", normalizeStrings(before.CustomCommand), normalizeStrings(after.CustomCommand))
+	add("working_dir", before.WorkingDir, after.WorkingDir)
+	add("args", normalizeStrings(before.Args), normalizeStrings(after.Args))
+	add("priority", before.Priority, after.Priority)
+	add("version_retention", before.VersionRetention, after.VersionRetention)
+	add("allowed_environments", normalizeStrings(before.AllowedEnvironments), normalizeStrings(after.AllowedEnvironments))
+	add("env_vars", normalizeEnvVars(before.EnvVars), normalizeEnvVars(after.EnvVars))
+	add("cron_expression", before.CronExpression, after.CronExpression)
+	add("http_path", before.HTTPPath, after.HTTPPath)
+	add("http_methods", normalizeStrings(before.HTTPMethods), normalizeStrings(after.HTTPMethods))
+
+	return changes
+}
+
+// normalizeStrings 将 nil 切片统一为空切片，避免 nil 与空切片被视为变更。
+func normalizeStrings(v []string) []string {
+	if v == nil {
+		return []string{}
+	}
+	return v
+}
+
+// normalizeEnvVars 将 nil map 统一为空 map，避免 nil 与空 map 被视为变更。
+func normalizeEnvVars(v map[string]string) map[string]string {
+	if v == nil {
+		return map[string]string{}
+	}
+	return v
+}
+
 // FunctionRepository 定义了函数存储的接口。
 // 该接口抽象了函数的持久化操作，允许不同的存储实现（如数据库、内存等）。
 type FunctionRepository interface {
@@ -375,6 +821,47 @@ type FunctionFilter struct {
 	Status FunctionStatus `json:"status,omitempty"`
 }
 
+// FunctionCursor 是函数列表游标分页的位置，指向一页的首条或末条函数。
+// 游标分页按 (created_at, id) 倒序排列，位置不受其他函数的创建、删除影响。
+type FunctionCursor struct {
+	// CreatedAt 是函数的创建时间
+	CreatedAt time.Time
+	// ID 是函数 ID，创建时间相同时用于区分先后
+	ID string
+}
+
+// NewFunctionCursor 返回指向函数的游标。
+func NewFunctionCursor(fn *Function) *FunctionCursor {
+	return &FunctionCursor{CreatedAt: fn.CreatedAt, ID: fn.ID}
+}
+
+// Encode 将游标编码为不透明的 URL 安全字符串。
+func (c *FunctionCursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseFunctionCursor 解析 Encode 生成的游标。
+//
+// 返回值:
+//   - *FunctionCursor: 解析后的游标
+//   - error: 游标格式无效时返回 ErrInvalidCursor
+func ParseFunctionCursor(s string) (*FunctionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &FunctionCursor{CreatedAt: createdAt, ID: id}, nil
+}
+
 // ==================== 批量操作相关类型 ====================
 
 // BulkDeleteRequest 表示批量删除函数的请求
@@ -422,10 +909,71 @@ type InvokeRequest struct {
 	Debug bool `json:"debug,omitempty"`
 	// Alias 指定使用的别名（如 "prod", "canary"），为空则使用 "latest"
 	Alias string `json:"alias,omitempty"`
+	// RoutingKey 是按别名调用时的路由键（从 X-Routing-Key 请求头解析），非空时按哈希而不是随机选择版本
+	RoutingKey string `json:"-"`
 	// Version 指定使用的版本号，优先级高于 Alias
 	Version int `json:"version,omitempty"`
 	// SessionKey 会话标识，用于有状态函数的状态隔离和会话亲和性路由
 	SessionKey string `json:"session_key,omitempty"`
+	// Shadow 表示本次调用是影子流量回放（内部使用，不会再次触发影子流量）
+	Shadow bool `json:"-"`
+	// PathParameters 是从自定义 HTTP 路由模板中提取的路径参数（可选）
+	PathParameters map[string]string `json:"path_parameters,omitempty"`
+	// CostTags 是调用方附加的成本标签（从 X-Nimbus-Cost-Tags 请求头解析），记录在调用记录上
+	CostTags map[string]string `json:"-"`
+	// Layers 是本次调用临时使用的层（从 X-Nimbus-Layers 请求头解析），非 nil 时替代函数配置的层
+	Layers []LayerOverride `json:"-"`
+	// EnvConfig 是调用所在环境的函数配置（从 X-Nimbus-Environment 请求头或 env 查询参数解析），
+	// 非 nil 时调度器在执行前将其内存、超时和环境变量覆盖到函数配置上，见 Function.ApplyEnvConfig
+	EnvConfig *FunctionEnvConfig `json:"-"`
+	// InstancePin 是实例亲和提示（内部使用），相同提示的连续调用优先复用同一个预热实例，
+	// 如工作流执行 ID，使同一次执行的多个任务状态在同一个实例上运行
+	InstancePin string `json:"-"`
+	// Trigger 是调用的触发来源（内部使用），为空表示 HTTP 调用；与函数配置共同决定调度优先级
+	Trigger TriggerType `json:"-"`
+	// Pipe 是管道调用的步骤信息（内部使用），记录在调用记录上
+	Pipe *InvocationPipe `json:"-"`
+	// CallChain 是发起本次调用的上游函数 ID（从 X-Nimbus-Call-Chain 请求头解析），用于拦截无限递归
+	CallChain []string `json:"-"`
+	// WorkflowExecutionID 是发起调用的工作流执行 ID（内部使用），记录在调用记录上用于关联工作流执行
+	WorkflowExecutionID string `json:"-"`
+	// WorkflowState 是发起调用的工作流状态名称（内部使用），记录在调用记录上
+	WorkflowState string `json:"-"`
+	// OutputSink 接收执行期间的容器输出（内部使用，流式调用），为 nil 表示不转发；
+	// 在执行协程中同步调用，不应阻塞
+	OutputSink func(ExecOutputChunk) `json:"-"`
+}
+
+// TriggerSource 返回调用的触发来源。影子流量回放为 TriggerShadow，其余调用未设置时为 TriggerHTTP。
+func (r *InvokeRequest) TriggerSource() TriggerType {
+	if r.Shadow {
+		return TriggerShadow
+	}
+	if r.Trigger == "" {
+		return TriggerHTTP
+	}
+	return r.Trigger
+}
+
+// EventPayload 返回传给函数的事件。
+// 没有路径参数时为原始 Payload；有路径参数时包装为
+// {"pathParameters": {...}, "body": <Payload>}。
+func (r *InvokeRequest) EventPayload() json.RawMessage {
+	if len(r.PathParameters) == 0 {
+		return r.Payload
+	}
+	body := r.Payload
+	if len(body) == 0 {
+		body = json.RawMessage("null")
+	}
+	event, err := json.Marshal(struct {
+		PathParameters map[string]string `json:"pathParameters"`
+		Body           json.RawMessage   `json:"body"`
+	}{r.PathParameters, body})
+	if err != nil {
+		return r.Payload
+	}
+	return event
 }
 
 // InvokeResponse 表示函数调用响应结构体。
@@ -439,8 +987,12 @@ type InvokeResponse struct {
 	Body json.RawMessage `json:"body,omitempty"`
 	// Error 是函数执行过程中的错误信息
 	Error string `json:"error,omitempty"`
+	// ErrorType 是错误分类，如 init_error 表示运行时未能加载函数
+	ErrorType string `json:"error_type,omitempty"`
 	// DurationMs 是函数执行耗时（单位：毫秒）
 	DurationMs int64 `json:"duration_ms"`
+	// QueueWaitMs 是等待可用执行实例的排队耗时（单位：毫秒），不计入 DurationMs
+	QueueWaitMs int64 `json:"queue_wait_ms,omitempty"`
 	// ColdStart 表示本次调用是否为冷启动
 	ColdStart bool `json:"cold_start"`
 	// BilledTimeMs 是计费时长（单位：毫秒），按最小计费单位向上取整
@@ -449,10 +1001,49 @@ type InvokeResponse struct {
 	Version int `json:"version,omitempty"`
 	// AliasUsed 是调用时使用的别名（如果有）
 	AliasUsed string `json:"alias_used,omitempty"`
+	// RoutedVersion 是按别名权重路由选中的版本号，仅按别名调用时返回
+	RoutedVersion int `json:"routed_version,omitempty"`
 	// SessionKey 是本次调用使用的会话标识（如果有）
 	SessionKey string `json:"session_key,omitempty"`
+	// Directives 是函数通过 X-Nimbus-* 响应头下发的平台处理指令（如果有）
+	Directives *ResponseDirectives `json:"directives,omitempty"`
+	// PartialOutput 是函数超时前已产生的输出（仅超时），只记录到调用记录，不返回给调用方
+	PartialOutput *PartialOutput `json:"-"`
+	// Meta 是函数通过响应信封返回的调用元数据（如果有），默认不返回给调用方
+	Meta json.RawMessage `json:"-"`
+	// Artifacts 是函数写入产物目录的文件（如果有），返回时包含下载地址
+	Artifacts []InvocationArtifact `json:"artifacts,omitempty"`
 }
 
+// 调用错误类型常量
+const (
+	// InvokeErrorTypeInit 表示函数运行时初始化失败（如入口函数不存在、导入错误）
+	InvokeErrorTypeInit = "init_error"
+	// InvokeErrorTypeTimeout 表示函数在超时时间内没有返回，执行实例已被销毁重建
+	InvokeErrorTypeTimeout = "timeout"
+	// InvokeErrorTypeCrash 表示运行时进程崩溃（如被 OOM 终止、段错误），执行实例已被销毁重建
+	InvokeErrorTypeCrash = "crash"
+)
+
+// 调用元数据响应头，同步调用、自定义 HTTP 路由和 Webhook 的响应都会携带，
+// 调用方无需解析响应体即可获取执行信息
+const (
+	// HeaderInvocationID 是本次调用的调用记录 ID
+	HeaderInvocationID = "X-Nimbus-Invocation-Id"
+	// HeaderColdStart 表示本次调用是否为冷启动（"true" 或 "false"）
+	HeaderColdStart = "X-Nimbus-Cold-Start"
+	// HeaderDurationMs 是函数执行耗时（毫秒）
+	HeaderDurationMs = "X-Nimbus-Duration-Ms"
+	// HeaderBilledMs 是计费时长（毫秒）
+	HeaderBilledMs = "X-Nimbus-Billed-Ms"
+	// HeaderResponseCache 表示同步调用是否命中响应缓存（"hit" 或 "miss"），仅配置了响应缓存的函数携带
+	HeaderResponseCache = "X-Nimbus-Cache"
+	// HeaderMeta 是函数返回的调用元数据（紧凑 JSON），仅在请求携带 HeaderIncludeMeta 时返回
+	HeaderMeta = "X-Nimbus-Meta"
+	// HeaderIncludeMeta 是调用方请求返回调用元数据的请求头（"true"）
+	HeaderIncludeMeta = "X-Nimbus-Include-Meta"
+)
+
 // ==================== 版本管理相关类型 ====================
 
 // FunctionVersion 表示函数的一个不可变版本快照。
@@ -478,6 +1069,53 @@ type FunctionVersion struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// MaxContainerReuse 是函数级容器复用次数的上限
+const MaxContainerReuse = 1000000
+
+// ValidateMaxReuse 验证函数的容器最大复用次数，必须在 [0, MaxContainerReuse] 范围内。
+func ValidateMaxReuse(n int) error {
+	if n < 0 || n > MaxContainerReuse {
+		return ErrInvalidMaxReuse
+	}
+	return nil
+}
+
+// MaxStopGracePeriodSec 是函数容器停止宽限期的上限（秒）
+const MaxStopGracePeriodSec = 120
+
+// ValidateStopGracePeriod 验证函数的容器停止宽限期，必须在 [0, MaxStopGracePeriodSec] 范围内。
+func ValidateStopGracePeriod(sec int) error {
+	if sec < 0 || sec > MaxStopGracePeriodSec {
+		return ErrInvalidStopGracePeriod
+	}
+	return nil
+}
+
+// MaxPayloadBytesLimit 是函数可配置的调用请求体大小上限（64MB），避免单个函数的设置使网关内存失去保护
+const MaxPayloadBytesLimit = 64 << 20
+
+// ValidateMaxPayloadBytes 验证函数的调用请求体大小上限，必须在 [0, MaxPayloadBytesLimit] 范围内。
+func ValidateMaxPayloadBytes(n int64) error {
+	if n < 0 || n > MaxPayloadBytesLimit {
+		return ErrInvalidMaxPayloadBytes
+	}
+	return nil
+}
+
+// VersionRetentionKeepAll 表示函数保留全部版本，不参与版本压缩
+const VersionRetentionKeepAll = -1
+
+// MaxVersionRetention 是单个函数版本保留数的上限
+const MaxVersionRetention = 1000
+
+// ValidateVersionRetention 验证函数的版本保留数，必须为 -1、0 或 [1, MaxVersionRetention]。
+func ValidateVersionRetention(n int) error {
+	if n < VersionRetentionKeepAll || n > MaxVersionRetention {
+		return ErrInvalidVersionRetention
+	}
+	return nil
+}
+
 // ==================== 别名与流量分配相关类型 ====================
 
 // FunctionAlias 表示函数别名，用于流量管理和灰度发布。
@@ -513,6 +1151,48 @@ type VersionWeight struct {
 	Weight int `json:"weight"`
 }
 
+// HeaderRoutingKey 是按别名调用时的路由键请求头。
+// 携带相同路由键的调用按别名权重一致地命中同一版本，便于用固定的用户或会话标识做金丝雀测试。
+const HeaderRoutingKey = "X-Routing-Key"
+
+// RoutingBucket 将路由键一致地映射到 [0, 100) 的桶号，供 SelectWeightedVersion 使用。
+// 参数:
+//   - functionID: 函数 ID
+//   - aliasName: 别名名称
+//   - key: 路由键
+//
+// 返回值:
+//   - int: 桶号，相同参数总是得到相同结果
+func RoutingBucket(functionID, aliasName, key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(functionID + "\x00" + aliasName + "\x00" + key))
+	return int(h.Sum32() % 100)
+}
+
+// SelectWeightedVersion 按桶号在累计权重中选择版本。
+// 版本按配置顺序占据连续的桶区间，调整权重时只有边界附近的桶改变版本，
+// 同一路由键在金丝雀权重逐步增加时不会在版本之间来回切换。
+// 参数:
+//   - weights: 版本权重列表，权重之和应为 100
+//   - bucket: [0, 100) 的桶号，随机路由时为随机数，指定路由键时为 RoutingBucket 的结果
+//
+// 返回值:
+//   - int: 选中的版本号，权重列表为空时返回 0
+func SelectWeightedVersion(weights []VersionWeight, bucket int) int {
+	if len(weights) == 0 {
+		return 0
+	}
+	cumulative := 0
+	for _, w := range weights {
+		cumulative += w.Weight
+		if bucket < cumulative {
+			return w.Version
+		}
+	}
+	// 权重之和不足 100 时兜底返回第一个版本
+	return weights[0].Version
+}
+
 // CreateAliasRequest 表示创建别名的请求。
 type CreateAliasRequest struct {
 	// Name 是别名名称，必填
@@ -564,6 +1244,8 @@ type LayerVersion struct {
 	ContentHash string `json:"content_hash"`
 	// SizeBytes 是内容大小（字节）
 	SizeBytes int64 `json:"size_bytes"`
+	// UnpackedSizeBytes 是解压后的总大小（字节），0 表示未知（早于该字段创建的版本）
+	UnpackedSizeBytes int64 `json:"unpacked_size_bytes"`
 	// CreatedAt 是版本创建时间
 	CreatedAt time.Time `json:"created_at"`
 }
@@ -580,6 +1262,33 @@ type FunctionLayer struct {
 	Order int `json:"order"`
 }
 
+const (
+	// DefaultMaxLayersPerFunction 是单个函数默认最多挂载的层数
+	DefaultMaxLayersPerFunction = 5
+	// DefaultMaxLayersUnpackedMB 是单个函数挂载的层默认最大解压总大小（MB）
+	DefaultMaxLayersUnpackedMB = 250
+)
+
+// ZipUnpackedSize 根据 ZIP 中央目录计算层内容解压后的总大小，不需要实际解压。
+//
+// 参数:
+//   - content: ZIP 格式的层内容
+//
+// 返回值:
+//   - int64: 所有文件解压后的总字节数
+//   - error: 内容不是合法的 ZIP 时返回 ErrInvalidLayerContent
+func ZipUnpackedSize(content []byte) (int64, error) {
+	reader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return 0, ErrInvalidLayerContent
+	}
+	var total int64
+	for _, f := range reader.File {
+		total += int64(f.UncompressedSize64)
+	}
+	return total, nil
+}
+
 // RuntimeLayerInfo 表示运行时加载层所需的信息。
 // 用于在函数执行时传递层内容到执行器。
 type RuntimeLayerInfo struct {
@@ -593,6 +1302,62 @@ type RuntimeLayerInfo struct {
 	Order int `json:"order"`
 }
 
+// HeaderLayers 是调用时临时覆盖函数层的请求头，格式为 "layer:version,layer:version"，
+// layer 可以是层 ID 或名称，加载顺序与请求头中的顺序一致
+const HeaderLayers = "X-Nimbus-Layers"
+
+// ProductionEnvironment 是生产环境的名称，该环境中不允许调用时覆盖函数层
+const ProductionEnvironment = "prod"
+
+// MaxLayerOverrides 是单次调用覆盖层数量的上限
+const MaxLayerOverrides = 10
+
+// LayerOverride 表示调用时指定的一个层版本，仅对本次调用生效。
+type LayerOverride struct {
+	// Layer 是层 ID 或名称（解析后为层 ID）
+	Layer string `json:"layer"`
+	// Version 是层版本号
+	Version int `json:"version"`
+}
+
+// ParseLayerOverrides 解析 X-Nimbus-Layers 请求头。
+// 同一个层不能出现多次，空白项被忽略。
+//
+// 参数:
+//   - header: 请求头的值
+//
+// 返回值:
+//   - []LayerOverride: 按加载顺序排列的层版本，请求头为空时返回 nil
+//   - error: 格式错误、层重复或数量超过上限时返回 ErrInvalidLayerOverride
+func ParseLayerOverrides(header string) ([]LayerOverride, error) {
+	if strings.TrimSpace(header) == "" {
+		return nil, nil
+	}
+	var overrides []LayerOverride
+	seen := make(map[string]struct{})
+	for _, item := range strings.Split(header, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		layer, version, ok := strings.Cut(item, ":")
+		layer = strings.TrimSpace(layer)
+		v, err := strconv.Atoi(strings.TrimSpace(version))
+		if !ok || layer == "" || err != nil || v <= 0 {
+			return nil, ErrInvalidLayerOverride
+		}
+		if _, dup := seen[layer]; dup {
+			return nil, ErrInvalidLayerOverride
+		}
+		seen[layer] = struct{}{}
+		overrides = append(overrides, LayerOverride{Layer: layer, Version: v})
+	}
+	if len(overrides) > MaxLayerOverrides {
+		return nil, ErrInvalidLayerOverride
+	}
+	return overrides, nil
+}
+
 // CreateLayerRequest 表示创建层的请求。
 type CreateLayerRequest struct {
 	// Name 是层名称，必填
@@ -620,6 +1385,71 @@ type Environment struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// HeaderEnvironment 是调用方指定调用环境的请求头，也可以使用 env 查询参数
+const HeaderEnvironment = "X-Nimbus-Environment"
+
+// MaxAllowedEnvironments 是单个函数允许调用环境列表的长度上限
+const MaxAllowedEnvironments = 16
+
+// ValidateAllowedEnvironments 验证函数的允许调用环境列表：名称不能为空或重复，数量不超过上限。
+// 环境是否存在由调用方检查。
+func ValidateAllowedEnvironments(names []string) error {
+	if len(names) > MaxAllowedEnvironments {
+		return ErrInvalidAllowedEnvironments
+	}
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		if name == "" {
+			return ErrInvalidAllowedEnvironments
+		}
+		if _, dup := seen[name]; dup {
+			return ErrInvalidAllowedEnvironments
+		}
+		seen[name] = struct{}{}
+	}
+	return nil
+}
+
+// EnvironmentAllowed 判断函数是否允许在指定环境中调用，允许列表为空时所有环境均可调用。
+func (f *Function) EnvironmentAllowed(env string) bool {
+	if len(f.AllowedEnvironments) == 0 {
+		return true
+	}
+	for _, name := range f.AllowedEnvironments {
+		if name == env {
+			return true
+		}
+	}
+	return false
+}
+
+// RuntimePolicy 是环境的运行时准入策略，由平台配置（environments.<name>）下发。
+// 函数部署到该环境（允许调用环境包含该环境，或请求指定了该环境）或在该环境中调用时都会检查。
+type RuntimePolicy struct {
+	// AllowedRuntimes 是允许的运行时，为空表示不限制
+	AllowedRuntimes []Runtime `json:"allowed_runtimes,omitempty"`
+	// BlockedRuntimes 是禁止的运行时，优先于 AllowedRuntimes
+	BlockedRuntimes []Runtime `json:"blocked_runtimes,omitempty"`
+}
+
+// Allows 判断策略是否允许指定的运行时。
+func (p RuntimePolicy) Allows(runtime Runtime) bool {
+	for _, blocked := range p.BlockedRuntimes {
+		if blocked == runtime {
+			return false
+		}
+	}
+	if len(p.AllowedRuntimes) == 0 {
+		return true
+	}
+	for _, allowed := range p.AllowedRuntimes {
+		if allowed == runtime {
+			return true
+		}
+	}
+	return false
+}
+
 // FunctionEnvConfig 表示函数在特定环境下的配置。
 type FunctionEnvConfig struct {
 	// FunctionID 是函数 ID
@@ -642,6 +1472,95 @@ type FunctionEnvConfig struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// 环境调用的调试请求头
+const (
+	// HeaderDebug 为 "1" 时调用响应附带 HeaderEffectiveConfig
+	HeaderDebug = "X-Nimbus-Debug"
+	// HeaderEffectiveConfig 是按环境覆盖后实际生效的函数配置（JSON），见 EffectiveInvokeConfig
+	HeaderEffectiveConfig = "X-Nimbus-Effective-Config"
+)
+
+// ApplyEnvConfig 将函数在某个环境下的配置覆盖到函数上：内存和超时大于 0 时覆盖，
+// 环境变量与函数自身的环境变量合并，同名时环境配置优先。cfg 为 nil 时不做任何修改。
+// 只用于调度器为单次调用加载的函数副本，不应写回存储。
+func (f *Function) ApplyEnvConfig(cfg *FunctionEnvConfig) {
+	if cfg == nil {
+		return
+	}
+	if cfg.MemoryMB != nil && *cfg.MemoryMB > 0 {
+		f.MemoryMB = *cfg.MemoryMB
+	}
+	if cfg.TimeoutSec != nil && *cfg.TimeoutSec > 0 {
+		f.TimeoutSec = *cfg.TimeoutSec
+	}
+	if len(cfg.EnvVars) > 0 {
+		merged := make(map[string]string, len(f.EnvVars)+len(cfg.EnvVars))
+		for k, v := range f.EnvVars {
+			merged[k] = v
+		}
+		for k, v := range cfg.EnvVars {
+			merged[k] = v
+		}
+		f.EnvVars = merged
+	}
+}
+
+// EffectiveInvokeConfig 描述按环境覆盖后一次调用实际生效的函数配置，用于调试。
+// 只包含环境变量名，不包含取值。
+type EffectiveInvokeConfig struct {
+	// Environment 是调用所在的环境
+	Environment string `json:"environment"`
+	// MemoryMB 是生效的内存规格（单位：MB）
+	MemoryMB int `json:"memory_mb"`
+	// TimeoutSec 是生效的超时时间（单位：秒）
+	TimeoutSec int `json:"timeout_sec"`
+	// EnvVarNames 是生效的环境变量名，按字母排序
+	EnvVarNames []string `json:"env_var_names"`
+	// Alias 是调用使用的别名（环境的 active_alias 或调用方指定的别名），未使用时为空
+	Alias string `json:"alias,omitempty"`
+	// Overrides 是被环境配置覆盖的字段（memory_mb、timeout_sec、env_vars、alias）
+	Overrides []string `json:"overrides"`
+}
+
+// NewEffectiveInvokeConfig 计算函数在环境配置覆盖后生效的配置。
+//
+// 参数:
+//   - fn: 函数自身的配置（不会被修改）
+//   - cfg: 函数在调用环境下的配置
+//   - alias: 调用最终使用的别名
+//
+// 返回值:
+//   - *EffectiveInvokeConfig: 生效的配置
+func NewEffectiveInvokeConfig(fn *Function, cfg *FunctionEnvConfig, alias string) *EffectiveInvokeConfig {
+	effective := *fn
+	effective.ApplyEnvConfig(cfg)
+	out := &EffectiveInvokeConfig{
+		Environment: cfg.EnvironmentName,
+		MemoryMB:    effective.MemoryMB,
+		TimeoutSec:  effective.TimeoutSec,
+		EnvVarNames: make([]string, 0, len(effective.EnvVars)),
+		Alias:       alias,
+		Overrides:   []string{},
+	}
+	for k := range effective.EnvVars {
+		out.EnvVarNames = append(out.EnvVarNames, k)
+	}
+	sort.Strings(out.EnvVarNames)
+	if effective.MemoryMB != fn.MemoryMB {
+		out.Overrides = append(out.Overrides, "memory_mb")
+	}
+	if effective.TimeoutSec != fn.TimeoutSec {
+		out.Overrides = append(out.Overrides, "timeout_sec")
+	}
+	if len(cfg.EnvVars) > 0 {
+		out.Overrides = append(out.Overrides, "env_vars")
+	}
+	if cfg.ActiveAlias != "" && alias == cfg.ActiveAlias {
+		out.Overrides = append(out.Overrides, "alias")
+	}
+	return out
+}
+
 // CreateEnvironmentRequest 表示创建环境的请求。
 type CreateEnvironmentRequest struct {
 	// Name 是环境名称，必填
@@ -705,6 +1624,91 @@ const (
 	DLQStatusDiscarded = "discarded"
 )
 
+const (
+	// DefaultDLQBulkRetryLimit 是批量重试未指定数量时单次最多重试的消息数
+	DefaultDLQBulkRetryLimit = 100
+	// MaxDLQBulkRetryLimit 是批量重试单次最多重试的消息数
+	MaxDLQBulkRetryLimit = 1000
+	// DefaultDLQBulkRetryConcurrency 是批量重试默认的并发调用数
+	DefaultDLQBulkRetryConcurrency = 5
+	// MaxDLQBulkRetryConcurrency 是批量重试允许的最大并发调用数
+	MaxDLQBulkRetryConcurrency = 20
+	// MaxDLQBulkRetryFailures 是批量重试结果中最多列出的失败消息数
+	MaxDLQBulkRetryFailures = 100
+)
+
+// DLQBulkRetryRequest 表示按条件批量重试死信消息的请求。
+type DLQBulkRetryRequest struct {
+	// FunctionID 只重试该函数的消息（可选，支持函数 ID 或名称）
+	FunctionID string `json:"function_id,omitempty"`
+	// Status 只重试该状态的消息，pending（默认）或 retrying（重试中途网关退出遗留的消息）
+	Status string `json:"status,omitempty"`
+	// Since 只重试此时间及之后进入死信队列的消息（可选）
+	Since *time.Time `json:"since,omitempty"`
+	// Until 只重试此时间之前进入死信队列的消息（可选）
+	Until *time.Time `json:"until,omitempty"`
+	// Limit 是本次最多重试的消息数，0 表示 DefaultDLQBulkRetryLimit，最大 MaxDLQBulkRetryLimit
+	Limit int `json:"limit,omitempty"`
+	// Concurrency 是并发调用数，0 表示 DefaultDLQBulkRetryConcurrency，最大 MaxDLQBulkRetryConcurrency
+	Concurrency int `json:"concurrency,omitempty"`
+	// Confirm 必须为 true，避免误操作触发大量调用
+	Confirm bool `json:"confirm"`
+}
+
+// Normalize 校验请求并填充默认值。
+//
+// 返回值:
+//   - error: 条件无效时返回 ErrInvalidDLQBulkRetry
+func (r *DLQBulkRetryRequest) Normalize() error {
+	if r.Status == "" {
+		r.Status = DLQStatusPending
+	}
+	if r.Status != DLQStatusPending && r.Status != DLQStatusRetrying {
+		return ErrInvalidDLQBulkRetry
+	}
+	if r.Since != nil && r.Until != nil && !r.Since.Before(*r.Until) {
+		return ErrInvalidDLQBulkRetry
+	}
+	if r.Limit < 0 || r.Limit > MaxDLQBulkRetryLimit || r.Concurrency < 0 || r.Concurrency > MaxDLQBulkRetryConcurrency {
+		return ErrInvalidDLQBulkRetry
+	}
+	if r.Limit == 0 {
+		r.Limit = DefaultDLQBulkRetryLimit
+	}
+	if r.Concurrency == 0 {
+		r.Concurrency = DefaultDLQBulkRetryConcurrency
+	}
+	return nil
+}
+
+// DLQRetryFailure 表示批量重试中一条失败的消息。
+type DLQRetryFailure struct {
+	// ID 是死信消息 ID
+	ID string `json:"id"`
+	// FunctionID 是消息所属的函数 ID
+	FunctionID string `json:"function_id"`
+	// Error 是重试失败或跳过的原因
+	Error string `json:"error"`
+}
+
+// DLQBulkRetryResult 表示批量重试的汇总结果。
+type DLQBulkRetryResult struct {
+	// Matched 是符合条件的消息总数
+	Matched int `json:"matched"`
+	// Attempted 是实际发起调用的消息数
+	Attempted int `json:"attempted"`
+	// Resolved 是重试成功、已标记为 resolved 的消息数
+	Resolved int `json:"resolved"`
+	// Failed 是重试失败、保持 pending 的消息数
+	Failed int `json:"failed"`
+	// Skipped 是未发起调用的消息数（函数已删除或不可调用、请求被取消）
+	Skipped int `json:"skipped"`
+	// Remaining 是超出本次数量上限、留待下次重试的消息数
+	Remaining int `json:"remaining"`
+	// Failures 列出失败和跳过的消息，最多 MaxDLQBulkRetryFailures 条
+	Failures []DLQRetryFailure `json:"failures,omitempty"`
+}
+
 // ==================== 有状态函数相关类型 ====================
 
 // StateConfig 状态配置，用于启用和配置函数的状态管理功能。
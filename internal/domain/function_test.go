@@ -2,7 +2,15 @@
 package domain
 
 import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 // TestCreateFunctionRequest_Validate 测试 CreateFunctionRequest 的验证方法。
@@ -174,6 +182,7 @@ func TestRuntime_IsValid(t *testing.T) {
 		{RuntimePython311, true},  // Python 3.11 应该是有效的
 		{RuntimeNodeJS20, true},   // Node.js 20 应该是有效的
 		{RuntimeGo124, true},      // Go 1.24 应该是有效的
+		{RuntimeBun, true},        // Bun 1.x 应该是有效的
 		{Runtime("python3.10"), false}, // Python 3.10 不受支持
 		{Runtime("nodejs18"), false},   // Node.js 18 不受支持
 		{Runtime("java"), false},       // Java 不受支持
@@ -447,3 +456,482 @@ func TestCreateFunctionRequest_Validate_CronExpression(t *testing.T) {
 		})
 	}
 }
+
+// TestDiffFunctions 测试函数更新前后的字段差异计算
+func TestDiffFunctions(t *testing.T) {
+	base := Function{
+		Description: "old",
+		Handler:     "handler.main",
+		CodeHash:    "aaa",
+		MemoryMB:    128,
+		TimeoutSec:  30,
+	}
+
+	tests := []struct {
+		name       string
+		mutate     func(fn *Function)
+		wantFields []string
+	}{
+		{
+			name:       "no changes",
+			mutate:     func(fn *Function) {},
+			wantFields: nil,
+		},
+		{
+			name:       "nil and empty slices are equal",
+			mutate:     func(fn *Function) { fn.Tags = []string{}; fn.EnvVars = map[string]string{} },
+			wantFields: nil,
+		},
+		{
+			name: "memory and code changed",
+			mutate: func(fn *Function) {
+				fn.MemoryMB = 256
+				fn.CodeHash = "bbb"
+			},
+			wantFields: []string{"memory_mb", "code_hash"},
+		},
+		{
+			name:       "tags changed",
+			mutate:     func(fn *Function) { fn.Tags = []string{"team-a"} },
+			wantFields: []string{"tags"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after := base
+			tt.mutate(&after)
+			changes := DiffFunctions(&base, &after)
+			if len(changes) != len(tt.wantFields) {
+				t.Fatalf("DiffFunctions() returned %d changes, want %d: %v", len(changes), len(tt.wantFields), changes)
+			}
+			for _, field := range tt.wantFields {
+				if _, ok := changes[field]; !ok {
+					t.Errorf("DiffFunctions() missing change for %q", field)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveEmptyResponse(t *testing.T) {
+	tests := []struct {
+		name          string
+		fnValue       string
+		globalDefault string
+		want          string
+	}{
+		{name: "unset", want: ""},
+		{name: "global default", globalDefault: `{}`, want: `{}`},
+		{name: "function overrides global", fnValue: `{"status":"ok"}`, globalDefault: `{}`, want: `{"status":"ok"}`},
+		{name: "function opts out", fnValue: EmptyResponseNone, globalDefault: `{}`, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveEmptyResponse(tt.fnValue, tt.globalDefault); string(got) != tt.want {
+				t.Errorf("ResolveEmptyResponse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if err := ValidateEmptyResponse("{not json"); err != ErrInvalidEmptyResponse {
+		t.Errorf("ValidateEmptyResponse() error = %v, want ErrInvalidEmptyResponse", err)
+	}
+}
+
+func TestValidateInitHandler(t *testing.T) {
+	for _, tc := range []struct {
+		runtime Runtime
+		name    string
+		wantErr bool
+	}{
+		{RuntimePython311, "", false},
+		{RuntimeGo124, "", false},
+		{RuntimePython311, "setup", false},
+		{RuntimeNodeJS20, "$init", false},
+		{RuntimePython311, "$init", true},
+		{RuntimeNodeJS20, "setup.init", true},
+		{RuntimeGo124, "setup", true},
+		{RuntimeWasm, "setup", true},
+	} {
+		err := ValidateInitHandler(tc.runtime, tc.name)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ValidateInitHandler(%s, %q) error = %v, wantErr %v", tc.runtime, tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestAllowedEnvironments(t *testing.T) {
+	fn := &Function{}
+	if !fn.EnvironmentAllowed("prod") {
+		t.Error("EnvironmentAllowed() = false with empty allowlist, want true")
+	}
+	fn.AllowedEnvironments = []string{"dev", "staging"}
+	if !fn.EnvironmentAllowed("dev") || fn.EnvironmentAllowed("prod") {
+		t.Error("EnvironmentAllowed() did not honor the allowlist")
+	}
+
+	if err := ValidateAllowedEnvironments([]string{"dev", "staging"}); err != nil {
+		t.Errorf("ValidateAllowedEnvironments() error = %v", err)
+	}
+	for _, names := range [][]string{{""}, {"dev", "dev"}} {
+		if err := ValidateAllowedEnvironments(names); err != ErrInvalidAllowedEnvironments {
+			t.Errorf("ValidateAllowedEnvironments(%q) error = %v, want ErrInvalidAllowedEnvironments", names, err)
+		}
+	}
+}
+
+func TestValidateVersionRetention(t *testing.T) {
+	for _, n := range []int{VersionRetentionKeepAll, 0, 1, MaxVersionRetention} {
+		if err := ValidateVersionRetention(n); err != nil {
+			t.Errorf("ValidateVersionRetention(%d) error = %v", n, err)
+		}
+	}
+	for _, n := range []int{-2, MaxVersionRetention + 1} {
+		if err := ValidateVersionRetention(n); err != ErrInvalidVersionRetention {
+			t.Errorf("ValidateVersionRetention(%d) error = %v, want ErrInvalidVersionRetention", n, err)
+		}
+	}
+}
+
+func TestValidateStopGracePeriod(t *testing.T) {
+	for _, sec := range []int{0, 1, MaxStopGracePeriodSec} {
+		if err := ValidateStopGracePeriod(sec); err != nil {
+			t.Errorf("ValidateStopGracePeriod(%d) error = %v", sec, err)
+		}
+	}
+	for _, sec := range []int{-1, MaxStopGracePeriodSec + 1} {
+		if err := ValidateStopGracePeriod(sec); err != ErrInvalidStopGracePeriod {
+			t.Errorf("ValidateStopGracePeriod(%d) error = %v, want ErrInvalidStopGracePeriod", sec, err)
+		}
+	}
+
+	req := CreateFunctionRequest{Name: "graceful", Runtime: "python3.11", Handler: "handler.main", Code: "def main(event): return {}", StopGracePeriodSec: MaxStopGracePeriodSec + 1}
+	if err := req.Validate(); err != ErrInvalidStopGracePeriod {
+		t.Errorf("Validate() error = %v, want ErrInvalidStopGracePeriod", err)
+	}
+
+	changes := DiffFunctions(&Function{StopGracePeriodSec: 0}, &Function{StopGracePeriodSec: 10})
+	if c, ok := changes["stop_grace_period_sec"]; !ok || c.Old != 0 || c.New != 10 {
+		t.Errorf("stop_grace_period_sec change = %+v, want 0 -> 10", c)
+	}
+}
+
+func TestValidateMaxPayloadBytes(t *testing.T) {
+	for _, n := range []int64{0, 1, MaxPayloadBytesLimit} {
+		if err := ValidateMaxPayloadBytes(n); err != nil {
+			t.Errorf("ValidateMaxPayloadBytes(%d) error = %v", n, err)
+		}
+	}
+	for _, n := range []int64{-1, MaxPayloadBytesLimit + 1} {
+		if err := ValidateMaxPayloadBytes(n); err != ErrInvalidMaxPayloadBytes {
+			t.Errorf("ValidateMaxPayloadBytes(%d) error = %v, want ErrInvalidMaxPayloadBytes", n, err)
+		}
+	}
+
+	req := CreateFunctionRequest{Name: "capped", Runtime: "python3.11", Handler: "handler.main", Code: "def main(event): return {}", MaxPayloadBytes: -1}
+	if err := req.Validate(); err != ErrInvalidMaxPayloadBytes {
+		t.Errorf("Validate() error = %v, want ErrInvalidMaxPayloadBytes", err)
+	}
+
+	changes := DiffFunctions(&Function{}, &Function{MaxPayloadBytes: 1024})
+	if c, ok := changes["max_payload_bytes"]; !ok || c.Old != int64(0) || c.New != int64(1024) {
+		t.Errorf("max_payload_bytes change = %+v, want 0 -> 1024", c)
+	}
+}
+
+func TestParseLayerOverrides(t *testing.T) {
+	overrides, err := ParseLayerOverrides(" numpy:3 , utils:7,, ")
+	if err != nil {
+		t.Fatalf("ParseLayerOverrides() error = %v", err)
+	}
+	want := []LayerOverride{{Layer: "numpy", Version: 3}, {Layer: "utils", Version: 7}}
+	if !reflect.DeepEqual(overrides, want) {
+		t.Errorf("ParseLayerOverrides() = %v, want %v", overrides, want)
+	}
+
+	if overrides, err := ParseLayerOverrides(""); err != nil || overrides != nil {
+		t.Errorf("ParseLayerOverrides(\"\") = %v, %v, want nil, nil", overrides, err)
+	}
+
+	for _, header := range []string{"numpy", "numpy:", ":3", "numpy:0", "numpy:x", "numpy:1,numpy:2"} {
+		if _, err := ParseLayerOverrides(header); err != ErrInvalidLayerOverride {
+			t.Errorf("ParseLayerOverrides(%q) error = %v, want ErrInvalidLayerOverride", header, err)
+		}
+	}
+}
+
+// TestFunctionStatusPaused 测试暂停状态的状态转换规则：
+// 暂停的函数拒绝同步调用、接受异步调用，并可恢复上线或下线。
+func TestFunctionStatusPaused(t *testing.T) {
+	s := FunctionStatusPaused
+	if s.CanInvoke() {
+		t.Error("paused function should not accept sync invocations")
+	}
+	if !s.CanInvokeAsync() {
+		t.Error("paused function should accept async invocations")
+	}
+	if !s.CanOnline() || !s.CanOffline() {
+		t.Error("paused function should be resumable and offlinable")
+	}
+	if s.CanPause() || s.CanUpdate() {
+		t.Error("paused function should not be paused again or updated")
+	}
+	for _, st := range []FunctionStatus{FunctionStatusActive, FunctionStatusDegraded} {
+		if !st.CanPause() {
+			t.Errorf("%s function should be pausable", st)
+		}
+	}
+	if FunctionStatusOffline.CanInvokeAsync() || FunctionStatusOffline.CanPause() {
+		t.Error("offline function should reject async invocations and pausing")
+	}
+}
+
+// TestFunctionStatusDegraded 测试 degraded 状态的状态转换规则：
+// degraded 只是告警标记，函数仍接受调用（成功调用后才能恢复），并可更新、暂停和下线。
+func TestFunctionStatusDegraded(t *testing.T) {
+	s := FunctionStatusDegraded
+	if !s.CanInvoke() || !s.CanInvokeAsync() {
+		t.Error("degraded function should accept invocations so it can recover")
+	}
+	if !s.CanUpdate() || !s.CanPause() || !s.CanOffline() {
+		t.Error("degraded function should be updatable, pausable and offlinable")
+	}
+	if s.CanOnline() {
+		t.Error("degraded function is already online")
+	}
+}
+
+func TestInvokeRequestTriggerSource(t *testing.T) {
+	if got := (&InvokeRequest{}).TriggerSource(); got != TriggerHTTP {
+		t.Errorf("TriggerSource() = %q, want http", got)
+	}
+	if got := (&InvokeRequest{Trigger: TriggerCron}).TriggerSource(); got != TriggerCron {
+		t.Errorf("TriggerSource() = %q, want cron", got)
+	}
+	// 影子流量回放总是记录为 shadow，不计入统计和计费
+	if got := (&InvokeRequest{Trigger: TriggerHTTP, Shadow: true}).TriggerSource(); got != TriggerShadow {
+		t.Errorf("TriggerSource() for shadow replay = %q, want shadow", got)
+	}
+}
+
+func TestRuntimePolicyAllows(t *testing.T) {
+	if !(RuntimePolicy{}).Allows(RuntimeWasm) {
+		t.Error("empty policy should allow every runtime")
+	}
+
+	blocked := RuntimePolicy{BlockedRuntimes: []Runtime{RuntimeWasm}}
+	if blocked.Allows(RuntimeWasm) || !blocked.Allows(RuntimeGo124) {
+		t.Error("blocked runtime should be rejected, others allowed")
+	}
+
+	// 禁止列表优先于允许列表
+	allowed := RuntimePolicy{AllowedRuntimes: []Runtime{RuntimePython311, RuntimeWasm}, BlockedRuntimes: []Runtime{RuntimeWasm}}
+	if !allowed.Allows(RuntimePython311) || allowed.Allows(RuntimeNodeJS20) || allowed.Allows(RuntimeWasm) {
+		t.Error("allow list should restrict runtimes and blocked list should take precedence")
+	}
+}
+
+func TestValidateAndParseHandler(t *testing.T) {
+	for _, h := range []string{"handler", "index.handler", "src/main.handler", "lib/v2/entry-point.$main"} {
+		if err := ValidateHandler(RuntimeNodeJS20, h); err != nil {
+			t.Errorf("ValidateHandler(%q) = %v, want nil", h, err)
+		}
+	}
+	for _, h := range []string{"", "src/.handler", "../main.handler", "/abs.handler", "src//main.handler", "main.", "a.b.handler", "main.1handler"} {
+		if err := ValidateHandler(RuntimeNodeJS20, h); err != ErrInvalidHandler {
+			t.Errorf("ValidateHandler(%q) = %v, want ErrInvalidHandler", h, err)
+		}
+	}
+	if err := ValidateHandler(RuntimePython311, "main.$handler"); err != ErrInvalidHandler {
+		t.Errorf("python handler with $ = %v, want ErrInvalidHandler", err)
+	}
+
+	if file, symbol := ParseHandler("src/main.handler"); file != "src/main" || symbol != "handler" {
+		t.Errorf("ParseHandler(src/main.handler) = %q, %q", file, symbol)
+	}
+	if file, symbol := ParseHandler("handle"); file != DefaultHandlerFile || symbol != "handle" {
+		t.Errorf("ParseHandler(handle) = %q, %q", file, symbol)
+	}
+}
+
+func TestZipUnpackedSize(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, size := range map[string]int{"lib/a.py": 1000, "lib/b.py": 2500} {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write(bytes.Repeat([]byte("x"), size))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	size, err := ZipUnpackedSize(buf.Bytes())
+	if err != nil || size != 3500 {
+		t.Errorf("ZipUnpackedSize() = %d, %v, want 3500", size, err)
+	}
+	if _, err := ZipUnpackedSize([]byte("not a zip")); err != ErrInvalidLayerContent {
+		t.Errorf("ZipUnpackedSize(invalid) error = %v, want ErrInvalidLayerContent", err)
+	}
+}
+
+// TestFunctionStatusRetryAfter 测试不可调用状态的建议重试间隔：过渡状态较短，需要人工处理的状态较长。
+func TestFunctionStatusRetryAfter(t *testing.T) {
+	for _, s := range []FunctionStatus{FunctionStatusCreating, FunctionStatusUpdating, FunctionStatusBuilding} {
+		if got := s.RetryAfterSeconds(); got != 30 {
+			t.Errorf("%s.RetryAfterSeconds() = %d, want 30", s, got)
+		}
+	}
+	for _, s := range []FunctionStatus{FunctionStatusOffline, FunctionStatusInactive, FunctionStatusFailed, FunctionStatusPaused} {
+		if got := s.RetryAfterSeconds(); got != 300 {
+			t.Errorf("%s.RetryAfterSeconds() = %d, want 300", s, got)
+		}
+	}
+}
+
+// TestValidateWarmupPayload 测试预热载荷校验：允许为空，否则必须是大小受限的合法 JSON。
+func TestValidateWarmupPayload(t *testing.T) {
+	large := json.RawMessage(`"` + strings.Repeat("a", MaxWarmupPayloadBytes) + `"`)
+	tests := []struct {
+		name    string
+		payload json.RawMessage
+		wantErr bool
+	}{
+		{name: "empty", payload: nil},
+		{name: "object", payload: json.RawMessage(`{"ping":true}`)},
+		{name: "invalid json", payload: json.RawMessage(`{ping}`), wantErr: true},
+		{name: "too large", payload: large, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateWarmupPayload(tt.payload)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateWarmupPayload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDLQBulkRetryRequestNormalize(t *testing.T) {
+	req := &DLQBulkRetryRequest{}
+	if err := req.Normalize(); err != nil {
+		t.Fatalf("Normalize() = %v", err)
+	}
+	if req.Status != DLQStatusPending || req.Limit != DefaultDLQBulkRetryLimit || req.Concurrency != DefaultDLQBulkRetryConcurrency {
+		t.Errorf("defaults not applied: %+v", req)
+	}
+
+	since := time.Now()
+	until := since.Add(-time.Hour)
+	for _, bad := range []DLQBulkRetryRequest{
+		{Status: DLQStatusResolved},
+		{Limit: MaxDLQBulkRetryLimit + 1},
+		{Concurrency: MaxDLQBulkRetryConcurrency + 1},
+		{Since: &since, Until: &until},
+	} {
+		if err := bad.Normalize(); !errors.Is(err, ErrInvalidDLQBulkRetry) {
+			t.Errorf("%+v: err = %v, want ErrInvalidDLQBulkRetry", bad, err)
+		}
+	}
+}
+
+func TestFunctionCursor(t *testing.T) {
+	fn := &Function{ID: "a1b2", CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 123456000, time.UTC)}
+	encoded := NewFunctionCursor(fn).Encode()
+
+	c, err := ParseFunctionCursor(encoded)
+	if err != nil {
+		t.Fatalf("ParseFunctionCursor() = %v", err)
+	}
+	if c.ID != fn.ID || !c.CreatedAt.Equal(fn.CreatedAt) {
+		t.Errorf("round trip = %+v, want %s at %s", c, fn.ID, fn.CreatedAt)
+	}
+
+	for _, bad := range []string{"not base64!", "bm8tc2VwYXJhdG9y", "MjAyNnxhYmM"} {
+		if _, err := ParseFunctionCursor(bad); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%q: err = %v, want ErrInvalidCursor", bad, err)
+		}
+	}
+}
+
+func TestWeightedRouting(t *testing.T) {
+	weights := []VersionWeight{{Version: 3, Weight: 90}, {Version: 4, Weight: 10}}
+	for bucket, want := range map[int]int{0: 3, 89: 3, 90: 4, 99: 4} {
+		if got := SelectWeightedVersion(weights, bucket); got != want {
+			t.Errorf("SelectWeightedVersion(bucket=%d) = %d, want %d", bucket, got, want)
+		}
+	}
+	if got := SelectWeightedVersion(nil, 50); got != 0 {
+		t.Errorf("SelectWeightedVersion(nil) = %d, want 0", got)
+	}
+
+	// 相同路由键总是得到相同桶号，不同路由键大致均匀分布
+	b := RoutingBucket("fn-1", "canary", "user-42")
+	if b < 0 || b >= 100 || RoutingBucket("fn-1", "canary", "user-42") != b {
+		t.Fatalf("RoutingBucket() = %d, want stable value in [0, 100)", b)
+	}
+	canary := 0
+	for i := 0; i < 1000; i++ {
+		if SelectWeightedVersion(weights, RoutingBucket("fn-1", "canary", fmt.Sprintf("user-%d", i))) == 4 {
+			canary++
+		}
+	}
+	if canary < 50 || canary > 150 {
+		t.Errorf("canary hits = %d of 1000, want about 100", canary)
+	}
+}
+
+func TestApplyEnvConfig(t *testing.T) {
+	mem, timeout := 1024, 60
+	base := &Function{MemoryMB: 256, TimeoutSec: 30, EnvVars: map[string]string{"A": "1", "B": "2"}}
+	cfg := &FunctionEnvConfig{
+		EnvironmentName: "prod",
+		EnvVars:         map[string]string{"B": "prod", "C": "3"},
+		MemoryMB:        &mem,
+		TimeoutSec:      &timeout,
+		ActiveAlias:     "stable",
+	}
+
+	fn := *base
+	fn.ApplyEnvConfig(cfg)
+	if fn.MemoryMB != 1024 || fn.TimeoutSec != 60 {
+		t.Errorf("ApplyEnvConfig() memory/timeout = %d/%d, want 1024/60", fn.MemoryMB, fn.TimeoutSec)
+	}
+	want := map[string]string{"A": "1", "B": "prod", "C": "3"}
+	if !reflect.DeepEqual(fn.EnvVars, want) {
+		t.Errorf("ApplyEnvConfig() env vars = %v, want %v", fn.EnvVars, want)
+	}
+	if base.EnvVars["B"] != "2" || len(base.EnvVars) != 2 {
+		t.Errorf("ApplyEnvConfig() mutated the original env vars: %v", base.EnvVars)
+	}
+
+	unchanged := *base
+	unchanged.ApplyEnvConfig(nil)
+	unchanged.ApplyEnvConfig(&FunctionEnvConfig{})
+	if unchanged.MemoryMB != 256 || unchanged.TimeoutSec != 30 || len(unchanged.EnvVars) != 2 {
+		t.Errorf("ApplyEnvConfig() with empty config changed the function: %+v", unchanged)
+	}
+
+	got := NewEffectiveInvokeConfig(base, cfg, "stable")
+	if got.Environment != "prod" || got.MemoryMB != 1024 || got.TimeoutSec != 60 || got.Alias != "stable" {
+		t.Errorf("NewEffectiveInvokeConfig() = %+v", got)
+	}
+	if !reflect.DeepEqual(got.EnvVarNames, []string{"A", "B", "C"}) {
+		t.Errorf("NewEffectiveInvokeConfig() env var names = %v", got.EnvVarNames)
+	}
+	if !reflect.DeepEqual(got.Overrides, []string{"memory_mb", "timeout_sec", "env_vars", "alias"}) {
+		t.Errorf("NewEffectiveInvokeConfig() overrides = %v", got.Overrides)
+	}
+	if base.MemoryMB != 256 {
+		t.Errorf("NewEffectiveInvokeConfig() mutated the function")
+	}
+
+	got = NewEffectiveInvokeConfig(base, &FunctionEnvConfig{EnvironmentName: "dev"}, "")
+	if len(got.Overrides) != 0 || got.MemoryMB != 256 {
+		t.Errorf("NewEffectiveInvokeConfig() without overrides = %+v", got)
+	}
+}
@@ -0,0 +1,127 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了HTTP 路由相关的类型。
+package domain
+
+import "strings"
+
+// RouteTemplate 描述一个带路径参数的自定义 HTTP 路由，如 "/orders/{orderId}"。
+type RouteTemplate struct {
+	// FunctionID 是路由绑定的函数 ID
+	FunctionID string `json:"function_id"`
+	// Path 是路由模板
+	Path string `json:"path"`
+}
+
+// IsPathTemplate 判断自定义 HTTP 路径是否包含路径参数。
+func IsPathTemplate(path string) bool {
+	return strings.Contains(path, "{")
+}
+
+// ValidateHTTPPath 验证自定义 HTTP 路由路径。
+// 路径参数必须占据完整的路径段（如 "/orders/{orderId}"），名称由字母、数字和下划线组成且不能重名。
+func ValidateHTTPPath(path string) error {
+	if !IsPathTemplate(path) && !strings.Contains(path, "}") {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, seg := range strings.Split(path, "/") {
+		name, isParam := pathParamName(seg)
+		if !isParam {
+			if strings.ContainsAny(seg, "{}") {
+				return ErrInvalidHTTPPath
+			}
+			continue
+		}
+		if name == "" || seen[name] {
+			return ErrInvalidHTTPPath
+		}
+		for i, c := range name {
+			if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+				return ErrInvalidHTTPPath
+			}
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// pathParamName 判断路径段是否为 {name} 形式的参数段，并返回参数名。
+func pathParamName(seg string) (string, bool) {
+	if len(seg) >= 2 && seg[0] == '{' && seg[len(seg)-1] == '}' {
+		return seg[1 : len(seg)-1], true
+	}
+	return "", false
+}
+
+// MatchPathTemplate 将请求路径与路由模板按段匹配，参数段匹配任意非空段。
+//
+// 参数:
+//   - template: 路由模板，如 "/orders/{orderId}/items/{itemId}"
+//   - path: 请求路径
+//
+// 返回值:
+//   - map[string]string: 提取出的路径参数
+//   - bool: 是否匹配
+func MatchPathTemplate(template, path string) (map[string]string, bool) {
+	tsegs := strings.Split(template, "/")
+	psegs := strings.Split(path, "/")
+	if len(tsegs) != len(psegs) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, tseg := range tsegs {
+		if name, ok := pathParamName(tseg); ok {
+			if psegs[i] == "" {
+				return nil, false
+			}
+			params[name] = psegs[i]
+			continue
+		}
+		if tseg != psegs[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// MatchRoute 在多个路由模板中查找与请求路径匹配的路由。
+//
+// 多个模板同时匹配时的优先级：从左到右逐段比较，第一个不同的段上静态段优先于参数段
+// （如 "/orders/latest" 优先于 "/orders/{id}"，"/a/{x}/c" 优先于 "/a/{x}/{y}"）；
+// 结构完全相同的模板按模板字符串的字典序、再按函数 ID 选择第一个，保证结果稳定。
+//
+// 返回值:
+//   - *RouteTemplate: 匹配的路由，没有匹配时为 nil
+//   - map[string]string: 提取出的路径参数
+func MatchRoute(routes []RouteTemplate, path string) (*RouteTemplate, map[string]string) {
+	var best *RouteTemplate
+	var bestParams map[string]string
+	for i := range routes {
+		params, ok := MatchPathTemplate(routes[i].Path, path)
+		if !ok {
+			continue
+		}
+		if best == nil || routeTemplateLess(&routes[i], best) {
+			best = &routes[i]
+			bestParams = params
+		}
+	}
+	return best, bestParams
+}
+
+// routeTemplateLess 判断路由 a 是否优先于路由 b（两者段数相同）。
+func routeTemplateLess(a, b *RouteTemplate) bool {
+	asegs := strings.Split(a.Path, "/")
+	bsegs := strings.Split(b.Path, "/")
+	for i := range asegs {
+		_, aParam := pathParamName(asegs[i])
+		_, bParam := pathParamName(bsegs[i])
+		if aParam != bParam {
+			return !aParam
+		}
+	}
+	if a.Path != b.Path {
+		return a.Path < b.Path
+	}
+	return a.FunctionID < b.FunctionID
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMatchRoute(t *testing.T) {
+	routes := []RouteTemplate{
+		{FunctionID: "items", Path: "/orders/{orderId}/items/{itemId}"},
+		{FunctionID: "order", Path: "/orders/{orderId}"},
+		{FunctionID: "latest", Path: "/orders/latest"},
+		{FunctionID: "b", Path: "/users/{name}"},
+		{FunctionID: "a", Path: "/users/{id}"},
+	}
+
+	tests := []struct {
+		path       string
+		wantFn     string
+		wantParams map[string]string
+	}{
+		{"/orders/42/items/7", "items", map[string]string{"orderId": "42", "itemId": "7"}},
+		{"/orders/42", "order", map[string]string{"orderId": "42"}},
+		{"/orders/latest", "latest", map[string]string{}},
+		{"/users/alice", "a", map[string]string{"id": "alice"}}, // 结构相同按模板字典序
+		{"/orders/", "", nil},
+		{"/orders/42/items", "", nil},
+	}
+	for _, tt := range tests {
+		route, params := MatchRoute(routes, tt.path)
+		if tt.wantFn == "" {
+			if route != nil {
+				t.Errorf("MatchRoute(%q) = %s, want no match", tt.path, route.FunctionID)
+			}
+			continue
+		}
+		if route == nil || route.FunctionID != tt.wantFn {
+			t.Errorf("MatchRoute(%q) = %+v, want %s", tt.path, route, tt.wantFn)
+			continue
+		}
+		if len(params) != len(tt.wantParams) {
+			t.Errorf("MatchRoute(%q) params = %v, want %v", tt.path, params, tt.wantParams)
+		}
+		for k, v := range tt.wantParams {
+			if params[k] != v {
+				t.Errorf("MatchRoute(%q) params[%s] = %q, want %q", tt.path, k, params[k], v)
+			}
+		}
+	}
+
+	for _, path := range []string{"/a/{id}/b/{id}", "/a/x{id}", "/a/{1id}", "/a/{}"} {
+		if err := ValidateHTTPPath(path); err != ErrInvalidHTTPPath {
+			t.Errorf("ValidateHTTPPath(%q) = %v, want ErrInvalidHTTPPath", path, err)
+		}
+	}
+	if err := ValidateHTTPPath("/orders/{order_id}/items/{itemId2}"); err != nil {
+		t.Errorf("ValidateHTTPPath() = %v, want nil", err)
+	}
+
+	req := &InvokeRequest{Payload: json.RawMessage(`{"qty":1}`), PathParameters: map[string]string{"orderId": "42"}}
+	if got := string(req.EventPayload()); got != `{"pathParameters":{"orderId":"42"},"body":{"qty":1}}` {
+		t.Errorf("EventPayload() = %s", got)
+	}
+}
@@ -3,7 +3,9 @@ package domain
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // InvocationStatus 表示函数调用的状态类型。
@@ -24,6 +26,8 @@ const (
 	InvocationStatusTimeout InvocationStatus = "timeout"
 	// InvocationStatusCancelled 表示调用被取消
 	InvocationStatusCancelled InvocationStatus = "cancelled"
+	// InvocationStatusSkipped 表示调用因函数处于维护窗口而被跳过，未实际执行
+	InvocationStatusSkipped InvocationStatus = "skipped"
 )
 
 // TriggerType 表示触发函数调用的方式类型。
@@ -37,8 +41,108 @@ const (
 	TriggerEvent TriggerType = "event"
 	// TriggerCron 表示通过定时任务触发
 	TriggerCron TriggerType = "cron"
+	// TriggerWebhook 表示通过 Webhook 触发
+	TriggerWebhook TriggerType = "webhook"
+	// TriggerShadow 表示影子流量回放，不计入调用统计、指标和计费
+	TriggerShadow TriggerType = "shadow"
 )
 
+// InvocationPriority 表示调用在调度队列中的优先级。
+// 工作协程总是先取出高优先级的调用，使交互式流量在资源紧张时优先获得工作协程和预热实例。
+type InvocationPriority string
+
+// 调用优先级常量定义
+const (
+	// PriorityHigh 是同步 HTTP 调用的默认优先级
+	PriorityHigh InvocationPriority = "high"
+	// PriorityNormal 是 Webhook 和异步 HTTP 调用的默认优先级
+	PriorityNormal InvocationPriority = "normal"
+	// PriorityLow 是定时任务和事件（队列）触发调用的默认优先级
+	PriorityLow InvocationPriority = "low"
+)
+
+// Priorities 按出队顺序（从高到低）列出所有调用优先级
+var Priorities = []InvocationPriority{PriorityHigh, PriorityNormal, PriorityLow}
+
+// ValidatePriority 验证函数配置的调用优先级，空字符串表示按触发来源取默认值。
+func ValidatePriority(p InvocationPriority) error {
+	switch p {
+	case "", PriorityHigh, PriorityNormal, PriorityLow:
+		return nil
+	}
+	return ErrInvalidPriority
+}
+
+// PriorityFor 返回调用在调度队列中的优先级。
+// 函数配置了 Priority 时使用函数配置；否则按触发来源取默认值：
+// 同步 HTTP 调用为 high，Webhook 和异步 HTTP 调用为 normal，定时任务、事件触发和影子流量回放为 low。
+//
+// 参数:
+//   - fn: 被调用的函数
+//   - trigger: 触发来源，为空时按 HTTP 处理
+//   - sync: 是否为同步调用
+//
+// 返回值:
+//   - InvocationPriority: 调用优先级
+func PriorityFor(fn *Function, trigger TriggerType, sync bool) InvocationPriority {
+	if fn != nil && fn.Priority != "" {
+		return fn.Priority
+	}
+	switch trigger {
+	case TriggerCron, TriggerEvent, TriggerShadow:
+		return PriorityLow
+	case TriggerWebhook:
+		return PriorityNormal
+	}
+	if sync {
+		return PriorityHigh
+	}
+	return PriorityNormal
+}
+
+// MaxPipeFunctions 是一条管道调用（?then=）最多包含的函数数，包括第一个函数
+const MaxPipeFunctions = 4
+
+// InvocationPipe 记录管道调用中的一个步骤。
+// 管道调用依次执行多个函数，前一个函数的输出作为后一个函数的输入，
+// 同一条管道的所有调用记录共享管道 ID，便于审计调用之间的关联。
+type InvocationPipe struct {
+	// ID 是管道标识，同一条管道的所有调用记录相同
+	ID string `json:"id"`
+	// Chain 是管道中依次执行的函数名称，包括第一个函数
+	Chain []string `json:"chain"`
+	// Step 是本次调用在管道中的位置，从 0 开始
+	Step int `json:"step"`
+	// PreviousInvocationID 是上一个步骤的调用 ID，第一个步骤为空
+	PreviousInvocationID string `json:"previous_invocation_id,omitempty"`
+}
+
+// ParsePipeChain 解析 then 查询参数中以逗号分隔的后续函数名称或 ID。
+// 参数为空时返回 nil；名称为空或管道超过 MaxPipeFunctions 个函数时返回 ErrInvalidPipe。
+//
+// 参数:
+//   - then: then 查询参数，如 "resize,upload"
+//
+// 返回值:
+//   - []string: 依次执行的后续函数名称或 ID
+//   - error: 参数无效时返回错误信息
+func ParsePipeChain(then string) ([]string, error) {
+	if then == "" {
+		return nil, nil
+	}
+	names := strings.Split(then, ",")
+	if len(names)+1 > MaxPipeFunctions {
+		return nil, ErrInvalidPipe
+	}
+	for i, name := range names {
+		names[i] = strings.TrimSpace(name)
+		if names[i] == "" {
+			return nil, ErrInvalidPipe
+		}
+	}
+	return names, nil
+}
+
 // Invocation 表示一次函数调用记录。
 // 该结构体记录了函数调用的完整信息，包括输入、输出、执行时间和计费信息。
 type Invocation struct {
@@ -74,12 +178,30 @@ type Invocation struct {
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
 	// DurationMs 是调用的实际执行时长（单位：毫秒）
 	DurationMs int64 `json:"duration_ms"`
+	// QueueWaitMs 是等待可用执行实例的排队耗时（单位：毫秒），与执行时长分开记录
+	QueueWaitMs int64 `json:"queue_wait_ms"`
 	// BilledTimeMs 是计费时长（单位：毫秒），按最小计费单位向上取整
 	BilledTimeMs int64 `json:"billed_time_ms"`
 	// MemoryUsedMB 是调用执行过程中使用的内存（单位：MB）
 	MemoryUsedMB int `json:"memory_used_mb"`
 	// RetryCount 是调用的重试次数
 	RetryCount int `json:"retry_count"`
+	// Progress 是函数上报的最新执行进度（未上报时为空）
+	Progress *InvocationProgress `json:"progress,omitempty"`
+	// Meta 是函数通过响应信封 {"result": ..., "meta": {...}} 返回的调用元数据（仅在调用详情中返回）
+	Meta json.RawMessage `json:"meta,omitempty"`
+	// CostTags 是调用方通过 X-Nimbus-Cost-Tags 请求头附加的成本标签，用于成本分摊
+	CostTags map[string]string `json:"cost_tags,omitempty"`
+	// Pipe 是管道调用的步骤信息（仅管道调用）
+	Pipe *InvocationPipe `json:"pipe,omitempty"`
+	// CallChain 是发起本次调用的上游函数 ID，从根调用开始依次排列（仅函数嵌套调用）
+	CallChain []string `json:"call_chain,omitempty"`
+	// WorkflowExecutionID 是发起本次调用的工作流执行 ID（仅工作流任务状态调用）
+	WorkflowExecutionID string `json:"workflow_execution_id,omitempty"`
+	// WorkflowState 是发起本次调用的工作流状态名称（仅工作流任务状态调用）
+	WorkflowState string `json:"workflow_state,omitempty"`
+	// Artifacts 是函数写入产物目录的文件（仅在调用详情中返回），见 ArtifactOutputDir
+	Artifacts []InvocationArtifact `json:"artifacts,omitempty"`
 	// CreatedAt 是调用记录的创建时间
 	CreatedAt time.Time `json:"created_at"`
 }
@@ -169,6 +291,87 @@ func (i *Invocation) Timeout() {
 	i.calculateBilledTime()
 }
 
+// MaxPartialOutputBytes 是超时调用保留的部分输出中每个流（stdout/stderr）的最大字节数
+const MaxPartialOutputBytes = 16 << 10
+
+// PartialOutput 是函数超时被终止前已经产生的输出，内容不完整。
+// 超时调用的记录以它作为 output，便于定位函数卡住的位置。
+type PartialOutput struct {
+	// Partial 恒为 true，标记输出不完整
+	Partial bool `json:"partial"`
+	// Reason 是输出不完整的原因，目前只有 timeout
+	Reason string `json:"reason"`
+	// Stdout 是超时前的标准输出，超出上限时只保留末尾
+	Stdout string `json:"stdout,omitempty"`
+	// Stderr 是超时前的标准错误，超出上限时只保留末尾
+	Stderr string `json:"stderr,omitempty"`
+	// Truncated 表示至少一个流超出上限被截断
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// NewTimeoutPartialOutput 由超时前捕获的 stdout/stderr 构建部分输出。
+// 每个流最多保留末尾 MaxPartialOutputBytes 字节（卡住位置附近的输出通常在末尾）。
+//
+// 参数:
+//   - stdout: 超时前的标准输出
+//   - stderr: 超时前的标准错误
+//
+// 返回值:
+//   - *PartialOutput: 两个流都为空时返回 nil
+func NewTimeoutPartialOutput(stdout, stderr []byte) *PartialOutput {
+	if len(stdout) == 0 && len(stderr) == 0 {
+		return nil
+	}
+	out, outTruncated := tailBytes(stdout, MaxPartialOutputBytes)
+	errOut, errTruncated := tailBytes(stderr, MaxPartialOutputBytes)
+	return &PartialOutput{
+		Partial:   true,
+		Reason:    "timeout",
+		Stdout:    out,
+		Stderr:    errOut,
+		Truncated: outTruncated || errTruncated,
+	}
+}
+
+// tailBytes 保留 b 的末尾最多 max 字节，截断点向后对齐到 UTF-8 字符边界
+func tailBytes(b []byte, max int) (string, bool) {
+	if len(b) <= max {
+		return string(b), false
+	}
+	b = b[len(b)-max:]
+	for i := 0; i < len(b) && i < utf8.UTFMax; i++ {
+		if utf8.RuneStart(b[i]) {
+			b = b[i:]
+			break
+		}
+	}
+	return string(b), true
+}
+
+// TimeoutWithPartialOutput 标记调用执行超时，并将超时前已产生的输出记录为调用的 output。
+// partial 为 nil 时与 Timeout 相同。
+func (i *Invocation) TimeoutWithPartialOutput(partial *PartialOutput) {
+	i.Timeout()
+	if partial == nil {
+		return
+	}
+	if data, err := json.Marshal(partial); err == nil {
+		i.Output = data
+	}
+}
+
+// Skip 标记调用因维护窗口被跳过。
+// 调用未实际执行，不产生执行时长和计费时长。
+//
+// 参数:
+//   - reason: 跳过原因
+func (i *Invocation) Skip(reason string) {
+	now := time.Now()
+	i.Status = InvocationStatusSkipped
+	i.Error = reason
+	i.CompletedAt = &now
+}
+
 // calculateBilledTime 计算计费时长。
 //
 // 计费规则说明：
@@ -231,3 +434,24 @@ type InvocationStats struct {
 	// ColdStartRate 是冷启动率（冷启动次数 / 总调用次数）
 	ColdStartRate float64 `json:"cold_start_rate"`
 }
+
+// ==================== 批量查询相关类型 ====================
+
+// MaxBatchGetInvocations 是单次批量查询调用记录的 ID 数量上限
+const MaxBatchGetInvocations = 100
+
+// BatchGetInvocationsRequest 表示批量查询调用记录的请求
+type BatchGetInvocationsRequest struct {
+	// IDs 要查询的调用记录 ID 列表
+	IDs []string `json:"ids"`
+}
+
+// BatchGetInvocationResult 表示批量查询中单个调用 ID 的结果
+type BatchGetInvocationResult struct {
+	// ID 是请求的调用记录 ID
+	ID string `json:"id"`
+	// Found 表示调用记录是否存在
+	Found bool `json:"found"`
+	// Invocation 是调用记录，不存在时为空
+	Invocation *Invocation `json:"invocation,omitempty"`
+}
@@ -0,0 +1,46 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了调用元数据信封相关的类型。
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// MaxInvocationMetaBytes 是调用元数据的大小上限，超出时元数据被丢弃（结果照常返回）
+const MaxInvocationMetaBytes = 8 << 10
+
+// ExtractInvocationMeta 识别函数返回的响应信封 {"result": ..., "meta": {...}}，
+// 将 result 作为调用方看到的响应体，meta 作为调用元数据单独返回。
+// 只有恰好包含 result 和 meta 两个字段、且 meta 为 JSON 对象的响应才视为信封，其他响应原样返回。
+//
+// 参数:
+//   - body: 函数返回的响应体
+//
+// 返回值:
+//   - json.RawMessage: 信封中的 result，不是信封时原样返回 body
+//   - json.RawMessage: 信封中的 meta，不是信封或超过 MaxInvocationMetaBytes 时为 nil
+func ExtractInvocationMeta(body json.RawMessage) (json.RawMessage, json.RawMessage) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || len(fields) != 2 {
+		return body, nil
+	}
+	result, hasResult := fields["result"]
+	meta, hasMeta := fields["meta"]
+	if !hasResult || !hasMeta {
+		return body, nil
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(meta, &obj); err != nil || obj == nil {
+		return body, nil
+	}
+	if len(meta) > MaxInvocationMetaBytes {
+		return result, nil
+	}
+	// 紧凑化，便于存储和放入响应头
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, meta); err != nil {
+		return result, nil
+	}
+	return result, compact.Bytes()
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExtractInvocationMeta(t *testing.T) {
+	body, meta := ExtractInvocationMeta(json.RawMessage(`{"result": {"items": [1, 2]}, "meta": {"rows": 2, "cache": "miss"}}`))
+	if string(body) != `{"items": [1, 2]}` || string(meta) != `{"rows":2,"cache":"miss"}` {
+		t.Errorf("envelope split = %s, %s", body, meta)
+	}
+
+	// 不是信封的响应原样返回
+	for _, raw := range []string{
+		`{"result": 1}`,
+		`{"result": 1, "meta": "text"}`,
+		`{"result": 1, "meta": {}, "extra": true}`,
+		`[1, 2]`,
+		`"plain"`,
+	} {
+		body, meta := ExtractInvocationMeta(json.RawMessage(raw))
+		if string(body) != raw || meta != nil {
+			t.Errorf("%s: got %s, %s; want unchanged", raw, body, meta)
+		}
+	}
+
+	// 元数据过大时丢弃，结果照常返回
+	big := `{"result": "ok", "meta": {"blob": "` + strings.Repeat("x", MaxInvocationMetaBytes) + `"}}`
+	body, meta = ExtractInvocationMeta(json.RawMessage(big))
+	if string(body) != `"ok"` || meta != nil {
+		t.Errorf("oversized meta: body=%s meta len=%d", body, len(meta))
+	}
+}
@@ -0,0 +1,61 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了调用记录搜索相关的类型。
+package domain
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// 调用记录文本搜索的长度限制
+const (
+	// MinInvocationSearchText 是文本搜索字符串的最小长度，过短的子串无法利用三元组索引
+	MinInvocationSearchText = 3
+	// MaxInvocationSearchText 是文本搜索字符串的最大长度
+	MaxInvocationSearchText = 256
+)
+
+// InvocationSearchQuery 表示调用记录的搜索条件，各条件之间为"与"关系。
+type InvocationSearchQuery struct {
+	// FunctionID 限定函数
+	FunctionID string `json:"function_id,omitempty"`
+	// Status 限定调用状态
+	Status InvocationStatus `json:"status,omitempty"`
+	// Since 限定创建时间不早于该时间
+	Since *time.Time `json:"since,omitempty"`
+	// Until 限定创建时间早于该时间
+	Until *time.Time `json:"until,omitempty"`
+	// ErrorContains 是错误信息中包含的文本（大小写不敏感）
+	ErrorContains string `json:"error_contains,omitempty"`
+	// PayloadContains 是输入或输出中包含的文本（大小写不敏感），需要部署开启调用载荷搜索
+	PayloadContains string `json:"payload_contains,omitempty"`
+	// FunctionTags 限定调用所属函数必须包含的全部标签，用于 API Key 标签作用域
+	FunctionTags []string `json:"-"`
+}
+
+// Validate 验证搜索条件：状态必须有效，时间范围不能颠倒，文本搜索字符串长度在限制范围内。
+func (q *InvocationSearchQuery) Validate() error {
+	switch q.Status {
+	case "", InvocationStatusPending, InvocationStatusRunning, InvocationStatusSuccess, InvocationStatusFailed,
+		InvocationStatusTimeout, InvocationStatusCancelled, InvocationStatusSkipped:
+	default:
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidInvocationSearch, q.Status)
+	}
+	if q.Since != nil && q.Until != nil && !q.Since.Before(*q.Until) {
+		return fmt.Errorf("%w: since must be before until", ErrInvalidInvocationSearch)
+	}
+	texts := []struct{ name, text string }{
+		{"error_contains", q.ErrorContains},
+		{"payload_contains", q.PayloadContains},
+	}
+	for _, t := range texts {
+		if t.text == "" {
+			continue
+		}
+		if n := utf8.RuneCountInString(t.text); n < MinInvocationSearchText || n > MaxInvocationSearchText {
+			return fmt.Errorf("%w: %s must be %d-%d characters", ErrInvalidInvocationSearch, t.name, MinInvocationSearchText, MaxInvocationSearchText)
+		}
+	}
+	return nil
+}
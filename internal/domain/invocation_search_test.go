@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInvocationSearchQueryValidate(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+	valid := &InvocationSearchQuery{Status: InvocationStatusFailed, Since: &earlier, Until: &now, ErrorContains: "timeout", PayloadContains: "订单号"}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid query rejected: %v", err)
+	}
+	if err := (&InvocationSearchQuery{}).Validate(); err != nil {
+		t.Fatalf("empty query rejected: %v", err)
+	}
+
+	invalid := map[string]*InvocationSearchQuery{
+		"unknown status": {Status: "exploded"},
+		"reversed range": {Since: &now, Until: &earlier},
+		"short text":     {ErrorContains: "ab"},
+		"long text":      {PayloadContains: strings.Repeat("x", MaxInvocationSearchText+1)},
+	}
+	for name, q := range invalid {
+		if err := q.Validate(); !errors.Is(err, ErrInvalidInvocationSearch) {
+			t.Errorf("%s: err = %v, want ErrInvalidInvocationSearch", name, err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPriorityFor(t *testing.T) {
+	fn := &Function{}
+	cases := []struct {
+		trigger TriggerType
+		sync    bool
+		want    InvocationPriority
+	}{
+		{"", true, PriorityHigh},
+		{TriggerHTTP, true, PriorityHigh},
+		{TriggerHTTP, false, PriorityNormal},
+		{TriggerWebhook, true, PriorityNormal},
+		{TriggerCron, false, PriorityLow},
+		{TriggerEvent, false, PriorityLow},
+		{TriggerShadow, true, PriorityLow},
+	}
+	for _, c := range cases {
+		if got := PriorityFor(fn, c.trigger, c.sync); got != c.want {
+			t.Errorf("PriorityFor(%q, sync=%v) = %q, want %q", c.trigger, c.sync, got, c.want)
+		}
+	}
+
+	// 函数配置的优先级覆盖触发来源的默认值
+	fn.Priority = PriorityHigh
+	if got := PriorityFor(fn, TriggerCron, false); got != PriorityHigh {
+		t.Errorf("PriorityFor with override = %q, want high", got)
+	}
+
+	if err := ValidatePriority("urgent"); err != ErrInvalidPriority {
+		t.Errorf("ValidatePriority(urgent) = %v, want ErrInvalidPriority", err)
+	}
+}
+
+func TestParsePipeChain(t *testing.T) {
+	names, err := ParsePipeChain("resize, upload")
+	if err != nil || len(names) != 2 || names[0] != "resize" || names[1] != "upload" {
+		t.Errorf("ParsePipeChain(resize, upload) = %v, %v", names, err)
+	}
+	if names, err := ParsePipeChain(""); names != nil || err != nil {
+		t.Errorf("ParsePipeChain(\"\") = %v, %v, want nil, nil", names, err)
+	}
+
+	// 空名称和超过最大函数数的管道无效
+	for _, then := range []string{"a,,b", ",", "a,b,c,d"} {
+		if _, err := ParsePipeChain(then); err != ErrInvalidPipe {
+			t.Errorf("ParsePipeChain(%q) = %v, want ErrInvalidPipe", then, err)
+		}
+	}
+}
+
+func TestTimeoutPartialOutput(t *testing.T) {
+	if NewTimeoutPartialOutput(nil, nil) != nil {
+		t.Error("empty output should not produce a partial output")
+	}
+
+	inv := &Invocation{}
+	inv.TimeoutWithPartialOutput(NewTimeoutPartialOutput([]byte("step 1\nstep 2\n"), nil))
+	if inv.Status != InvocationStatusTimeout {
+		t.Errorf("status = %s, want timeout", inv.Status)
+	}
+	var p PartialOutput
+	if err := json.Unmarshal(inv.Output, &p); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Partial || p.Reason != "timeout" || p.Stdout != "step 1\nstep 2\n" || p.Truncated {
+		t.Errorf("partial output = %+v", p)
+	}
+
+	// 超出上限时保留末尾，且不截断多字节字符
+	long := append(bytes.Repeat([]byte("卡"), MaxPartialOutputBytes), []byte("stuck here")...)
+	p2 := NewTimeoutPartialOutput(nil, long)
+	if !p2.Truncated || len(p2.Stderr) > MaxPartialOutputBytes || !strings.HasSuffix(p2.Stderr, "stuck here") {
+		t.Errorf("truncated stderr len=%d truncated=%v", len(p2.Stderr), p2.Truncated)
+	}
+	if !utf8.ValidString(p2.Stderr) {
+		t.Error("truncated stderr is not valid UTF-8")
+	}
+}
@@ -0,0 +1,52 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了常驻预热相关的类型。
+package domain
+
+// KeepWarmTarget 描述某一运行时/内存规格需要常驻的热实例数。
+// 同规格的函数共享执行环境池，目标数为该规格下所有函数 KeepWarm 之和。
+type KeepWarmTarget struct {
+	// Runtime 是运行时类型
+	Runtime Runtime `json:"runtime"`
+	// MemoryMB 是内存规格（单位：MB）
+	MemoryMB int `json:"memory_mb"`
+	// Count 是需要常驻的热实例数
+	Count int `json:"count"`
+	// Warmups 是该规格下配置了预热载荷的函数（不参与 JSON 序列化），新建的常驻实例依次以其预热载荷执行一次
+	Warmups []*Function `json:"-"`
+}
+
+// ProvisionRequest 表示为函数预置常驻预热实例的请求，Count 会写入函数的 keep_warm。
+type ProvisionRequest struct {
+	// Count 是预置的常驻实例数，0 表示取消预置，范围 [0, MaxKeepWarm]
+	Count *int `json:"count"`
+}
+
+// ProvisionedPool 描述一个运行时/内存规格执行环境池中常驻与按需预热实例的数量。
+type ProvisionedPool struct {
+	// Runtime 是运行时类型
+	Runtime Runtime `json:"runtime"`
+	// MemoryMB 是内存规格（单位：MB）
+	MemoryMB int `json:"memory_mb"`
+	// Provisioned 是常驻目标数，即该规格下所有函数 keep_warm 之和
+	Provisioned int `json:"provisioned"`
+	// ProvisionedWarm 是空闲回收时保留的常驻预热实例数，补齐期间可能小于 Provisioned
+	ProvisionedWarm int `json:"provisioned_warm"`
+	// OnDemandWarm 是超出常驻目标、按需创建的空闲预热实例数，空闲超时后回收
+	OnDemandWarm int `json:"on_demand_warm"`
+	// Busy 是正在执行调用的实例数
+	Busy int `json:"busy"`
+	// Creating 是正在创建的实例数
+	Creating int `json:"creating"`
+	// Total 是池中的实例总数（不含正在创建的）
+	Total int `json:"total"`
+}
+
+// ProvisionResponse 表示预置常驻预热实例的结果。
+type ProvisionResponse struct {
+	// FunctionID 是函数 ID
+	FunctionID string `json:"function_id"`
+	// Count 是函数当前的常驻实例数（keep_warm）
+	Count int `json:"count"`
+	// Pool 是函数所在规格执行环境池的当前状态，补齐在后台进行
+	Pool ProvisionedPool `json:"pool"`
+}
@@ -0,0 +1,86 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了紧急停止开关相关的类型。
+package domain
+
+import "time"
+
+// 紧急停止开关的作用范围
+const (
+	// KillSwitchScopeGlobal 表示停止平台上所有函数的调用
+	KillSwitchScopeGlobal = "global"
+	// KillSwitchScopeFunction 表示停止单个函数的调用
+	KillSwitchScopeFunction = "function"
+)
+
+const (
+	// DefaultKillSwitchTTL 是未指定有效期时紧急停止开关的有效期
+	DefaultKillSwitchTTL = time.Hour
+	// MaxKillSwitchTTL 是紧急停止开关的最长有效期，到期后自动解除
+	MaxKillSwitchTTL = 7 * 24 * time.Hour
+)
+
+// KillSwitch 表示一个已开启的紧急停止开关。开启期间对应范围内的所有调用都会被拒绝。
+type KillSwitch struct {
+	// Scope 是作用范围：global 或 function
+	Scope string `json:"scope"`
+	// FunctionID 是被停止的函数 ID（函数级开关）
+	FunctionID string `json:"function_id,omitempty"`
+	// Reason 是开启原因
+	Reason string `json:"reason,omitempty"`
+	// EngagedBy 是开启开关的操作者
+	EngagedBy string `json:"engaged_by,omitempty"`
+	// EngagedAt 是开启时间
+	EngagedAt time.Time `json:"engaged_at"`
+	// ExpiresAt 是自动解除时间
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// EngageKillSwitchRequest 是开启紧急停止开关的请求。
+type EngageKillSwitchRequest struct {
+	// Reason 是开启原因，记录在开关和审计日志中
+	Reason string `json:"reason"`
+	// TTLSeconds 是有效期（秒），0 表示使用 DefaultKillSwitchTTL，最长 MaxKillSwitchTTL
+	TTLSeconds int `json:"ttl_seconds"`
+}
+
+// TTL 校验并返回开关的有效期。
+func (r *EngageKillSwitchRequest) TTL() (time.Duration, error) {
+	if r.TTLSeconds == 0 {
+		return DefaultKillSwitchTTL, nil
+	}
+	ttl := time.Duration(r.TTLSeconds) * time.Second
+	if r.TTLSeconds < 0 || ttl > MaxKillSwitchTTL {
+		return 0, ErrInvalidKillSwitchTTL
+	}
+	return ttl, nil
+}
+
+// KillSwitchError 表示调用因紧急停止开关被拒绝。
+// 可通过 errors.Is(err, ErrKillSwitchEngaged) 判断。
+type KillSwitchError struct {
+	// Switch 是拒绝调用的开关
+	Switch *KillSwitch
+}
+
+// Error 实现 error 接口。
+func (e *KillSwitchError) Error() string {
+	msg := ErrKillSwitchEngaged.Error() + " (" + e.Switch.Scope + ")"
+	if e.Switch.Reason != "" {
+		msg += ": " + e.Switch.Reason
+	}
+	return msg
+}
+
+// Is 使 errors.Is(err, ErrKillSwitchEngaged) 返回 true。
+func (e *KillSwitchError) Is(target error) bool {
+	return target == ErrKillSwitchEngaged
+}
+
+// RetryAfter 返回距离开关自动解除的秒数（至少为 1），用于 Retry-After 响应头。
+func (e *KillSwitchError) RetryAfter(now time.Time) int {
+	sec := int((e.Switch.ExpiresAt.Sub(now) + time.Second - 1) / time.Second)
+	if sec < 1 {
+		sec = 1
+	}
+	return sec
+}
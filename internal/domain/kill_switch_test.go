@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestKillSwitch(t *testing.T) {
+	req := &EngageKillSwitchRequest{}
+	if ttl, err := req.TTL(); err != nil || ttl != DefaultKillSwitchTTL {
+		t.Errorf("default TTL = %v, %v, want %v", ttl, err, DefaultKillSwitchTTL)
+	}
+	for _, sec := range []int{-1, int(MaxKillSwitchTTL/time.Second) + 1} {
+		req.TTLSeconds = sec
+		if _, err := req.TTL(); err != ErrInvalidKillSwitchTTL {
+			t.Errorf("TTL(%d) error = %v, want ErrInvalidKillSwitchTTL", sec, err)
+		}
+	}
+
+	now := time.Now()
+	err := error(&KillSwitchError{Switch: &KillSwitch{Scope: KillSwitchScopeGlobal, ExpiresAt: now.Add(90 * time.Second)}})
+	if !errors.Is(err, ErrKillSwitchEngaged) {
+		t.Error("KillSwitchError should match ErrKillSwitchEngaged")
+	}
+	var kerr *KillSwitchError
+	if !errors.As(err, &kerr) || kerr.RetryAfter(now) != 90 {
+		t.Errorf("RetryAfter = %d, want 90", kerr.RetryAfter(now))
+	}
+	if kerr.Switch.ExpiresAt = now.Add(-time.Second); kerr.RetryAfter(now) != 1 {
+		t.Errorf("RetryAfter after expiry = %d, want 1", kerr.RetryAfter(now))
+	}
+}
@@ -0,0 +1,101 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了函数生效限制相关的类型。
+package domain
+
+// 限制值的来源
+const (
+	// LimitSourceFunction 表示限制取自函数自身的配置
+	LimitSourceFunction = "function"
+	// LimitSourceEnvironment 表示限制取自函数在所查询环境下的配置
+	LimitSourceEnvironment = "environment"
+	// LimitSourcePlatform 表示函数未配置，使用平台默认值
+	LimitSourcePlatform = "platform"
+)
+
+// PlatformLimits 是平台级的限制，由网关配置决定，函数未单独配置时生效。
+type PlatformLimits struct {
+	// EphemeralStorageMB 是容器临时存储（tmpfs）的大小，0 表示执行后端不提供（如 Firecracker）
+	EphemeralStorageMB int
+	// MaxReuse 是容器池设置的单个容器最大调用次数，0 表示不限制
+	MaxReuse int
+	// MaxWebSocketMessageBytes 是 WebSocket 调用单条消息（即单次调用载荷）的最大字节数
+	MaxWebSocketMessageBytes int
+}
+
+// FunctionLimits 汇总函数的所有生效限制，包括平台默认值和环境覆盖。
+type FunctionLimits struct {
+	// Environment 是计算限制时应用的环境，为空表示未指定环境
+	Environment string `json:"environment,omitempty"`
+	// MemoryMB 是内存上限
+	MemoryMB int `json:"memory_mb"`
+	// TimeoutSec 是单次执行的超时时间
+	TimeoutSec int `json:"timeout_sec"`
+	// MaxConcurrency 是最大并发执行数，0 表示不限制
+	MaxConcurrency int `json:"max_concurrency"`
+	// ReservedConcurrency 是预留的并发槽位数
+	ReservedConcurrency int `json:"reserved_concurrency"`
+	// RateLimit 是调用限流配置，为空表示不限流
+	RateLimit *RateLimitConfig `json:"rate_limit,omitempty"`
+	// EphemeralStorageMB 是临时存储大小，0 表示执行后端不提供
+	EphemeralStorageMB int `json:"ephemeral_storage_mb,omitempty"`
+	// MaxReuse 是单个预热容器的最大复用次数，0 表示不限制
+	MaxReuse int `json:"max_reuse,omitempty"`
+	// MaxCodeBytes 是函数代码的大小上限
+	MaxCodeBytes int `json:"max_code_bytes"`
+	// MaxWebSocketMessageBytes 是 WebSocket 调用单条消息的大小上限
+	MaxWebSocketMessageBytes int `json:"max_websocket_message_bytes,omitempty"`
+	// MaxMetaBytes 是调用元数据的大小上限，超出时元数据被丢弃
+	MaxMetaBytes int `json:"max_meta_bytes"`
+	// MaxPartialOutputBytes 是超时调用每个输出流保留的最大字节数
+	MaxPartialOutputBytes int `json:"max_partial_output_bytes"`
+	// Sources 记录可由函数、环境或平台决定的限制各自的来源
+	Sources map[string]string `json:"sources"`
+}
+
+// EffectiveLimits 计算函数的生效限制。
+// 环境配置中的内存和超时覆盖函数自身的配置；函数未配置最大复用次数时使用平台设置。
+//
+// 参数:
+//   - platform: 平台级限制
+//   - envCfg: 函数在所查询环境下的配置，为 nil 表示未指定环境或该环境没有覆盖
+//
+// 返回值:
+//   - *FunctionLimits: 生效限制
+func (f *Function) EffectiveLimits(platform PlatformLimits, envCfg *FunctionEnvConfig) *FunctionLimits {
+	limits := &FunctionLimits{
+		MemoryMB:                 f.MemoryMB,
+		TimeoutSec:               f.TimeoutSec,
+		MaxConcurrency:           f.MaxConcurrency,
+		ReservedConcurrency:      f.ReservedConcurrency,
+		RateLimit:                f.RateLimit,
+		EphemeralStorageMB:       platform.EphemeralStorageMB,
+		MaxReuse:                 f.MaxReuse,
+		MaxCodeBytes:             MaxCodeSize,
+		MaxWebSocketMessageBytes: platform.MaxWebSocketMessageBytes,
+		MaxMetaBytes:             MaxInvocationMetaBytes,
+		MaxPartialOutputBytes:    MaxPartialOutputBytes,
+		Sources: map[string]string{
+			"memory_mb":   LimitSourceFunction,
+			"timeout_sec": LimitSourceFunction,
+			"max_reuse":   LimitSourceFunction,
+		},
+	}
+
+	if limits.MaxReuse == 0 {
+		limits.MaxReuse = platform.MaxReuse
+		limits.Sources["max_reuse"] = LimitSourcePlatform
+	}
+
+	if envCfg != nil {
+		limits.Environment = envCfg.EnvironmentName
+		if envCfg.MemoryMB != nil && *envCfg.MemoryMB > 0 {
+			limits.MemoryMB = *envCfg.MemoryMB
+			limits.Sources["memory_mb"] = LimitSourceEnvironment
+		}
+		if envCfg.TimeoutSec != nil && *envCfg.TimeoutSec > 0 {
+			limits.TimeoutSec = *envCfg.TimeoutSec
+			limits.Sources["timeout_sec"] = LimitSourceEnvironment
+		}
+	}
+	return limits
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestFunctionEffectiveLimits(t *testing.T) {
+	fn := &Function{MemoryMB: 256, TimeoutSec: 30, MaxConcurrency: 5}
+	platform := PlatformLimits{EphemeralStorageMB: 64, MaxReuse: 1000, MaxWebSocketMessageBytes: 1 << 20}
+
+	l := fn.EffectiveLimits(platform, nil)
+	if l.MemoryMB != 256 || l.TimeoutSec != 30 || l.MaxConcurrency != 5 || l.EphemeralStorageMB != 64 {
+		t.Errorf("limits = %+v", l)
+	}
+	if l.MaxReuse != 1000 || l.Sources["max_reuse"] != LimitSourcePlatform || l.Environment != "" {
+		t.Errorf("platform max_reuse not applied: %+v", l)
+	}
+
+	// 环境覆盖内存，未覆盖的超时仍取函数配置
+	mem := 1024
+	fn.MaxReuse = 10
+	l = fn.EffectiveLimits(platform, &FunctionEnvConfig{EnvironmentName: "prod", MemoryMB: &mem})
+	if l.Environment != "prod" || l.MemoryMB != 1024 || l.Sources["memory_mb"] != LimitSourceEnvironment {
+		t.Errorf("env override not applied: %+v", l)
+	}
+	if l.TimeoutSec != 30 || l.Sources["timeout_sec"] != LimitSourceFunction || l.MaxReuse != 10 {
+		t.Errorf("function limits lost: %+v", l)
+	}
+}
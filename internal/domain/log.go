@@ -6,6 +6,16 @@ import (
 	"time"
 )
 
+// 调用日志的来源，用于在日志流中区分调用入口
+const (
+	// LogSourceAPI 表示通过 POST /api/v1/functions/{id}/invoke 直接调用
+	LogSourceAPI = "api"
+	// LogSourceRoute 表示通过自定义 HTTP 路由调用
+	LogSourceRoute = "route"
+	// LogSourceWebhook 表示通过 Webhook 调用
+	LogSourceWebhook = "webhook"
+)
+
 // LogEntry 表示一条平台侧的日志事件。
 // 主要用于 Web 控制台 / CLI 的实时日志流。
 type LogEntry struct {
@@ -19,4 +29,6 @@ type LogEntry struct {
 	Output       json.RawMessage `json:"output,omitempty"`
 	Error        string          `json:"error,omitempty"`
 	DurationMs   int64           `json:"duration_ms,omitempty"`
+	// Source 是调用日志的来源（api/route/webhook），非调用日志为空
+	Source string `json:"source,omitempty"`
 }
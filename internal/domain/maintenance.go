@@ -0,0 +1,136 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了维护窗口相关的类型。
+package domain
+
+import (
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// MaxMaintenanceWindows 是单个函数允许配置的维护窗口数量上限
+const MaxMaintenanceWindows = 10
+
+// MaxMaintenanceWindowDuration 是单个维护窗口的最大持续时间（单位：秒，7 天）
+const MaxMaintenanceWindowDuration = 7 * 24 * 3600
+
+// maxMaintenanceLookback 查找活跃窗口时最多向后遍历的窗口起点数，
+// 避免高频 cron 表达式配合长持续时间导致遍历过多
+const maxMaintenanceLookback = 1000
+
+// MaintenanceWindow 描述函数的一个周期性维护窗口。
+// 每次 Schedule 触发时窗口开始，持续 DurationSec 秒。
+type MaintenanceWindow struct {
+	// Schedule 是窗口开始时间的 cron 表达式（6 字段格式，包含秒），如 "0 0 2 * * SUN"
+	Schedule string `json:"schedule"`
+	// DurationSec 是窗口持续时间（单位：秒）
+	DurationSec int `json:"duration_sec"`
+	// Reason 是维护原因（可选），会包含在拒绝调用的响应中
+	Reason string `json:"reason,omitempty"`
+}
+
+// ValidateMaintenanceWindows 验证维护窗口配置。
+// 窗口数量不能超过 MaxMaintenanceWindows，每个窗口的 cron 表达式必须有效，
+// 持续时间必须在 1 秒到 MaxMaintenanceWindowDuration 之间。
+func ValidateMaintenanceWindows(windows []MaintenanceWindow) error {
+	if len(windows) > MaxMaintenanceWindows {
+		return ErrInvalidMaintenanceWindow
+	}
+	for _, w := range windows {
+		if w.Schedule == "" || ValidateCronExpression(w.Schedule) != nil {
+			return ErrInvalidMaintenanceWindow
+		}
+		if w.DurationSec < 1 || w.DurationSec > MaxMaintenanceWindowDuration {
+			return ErrInvalidMaintenanceWindow
+		}
+	}
+	return nil
+}
+
+// ActiveMaintenanceWindow 查找在指定时刻处于生效状态的维护窗口。
+// 多个窗口同时生效（或同一窗口的多次触发相互重叠）时，返回结束时间最晚的一个。
+//
+// 参数:
+//   - windows: 函数的维护窗口配置
+//   - now: 检查的时刻
+//
+// 返回值:
+//   - *MaintenanceWindow: 生效的维护窗口，不在任何窗口内时为 nil
+//   - time.Time: 维护结束时间
+func ActiveMaintenanceWindow(windows []MaintenanceWindow, now time.Time) (*MaintenanceWindow, time.Time) {
+	parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+	var active *MaintenanceWindow
+	var until time.Time
+	for i := range windows {
+		w := &windows[i]
+		sched, err := parser.Parse(w.Schedule)
+		if err != nil || w.DurationSec <= 0 {
+			continue
+		}
+		duration := time.Duration(w.DurationSec) * time.Second
+
+		// 窗口起点落在 (now-duration, now] 内时窗口生效，
+		// 其中最晚的起点决定该窗口的结束时间
+		var latest time.Time
+		start := sched.Next(now.Add(-duration))
+		for n := 0; !start.IsZero() && !start.After(now) && n < maxMaintenanceLookback; n++ {
+			latest = start
+			start = sched.Next(start)
+		}
+		if latest.IsZero() {
+			continue
+		}
+		if end := latest.Add(duration); end.After(until) {
+			active = w
+			until = end
+		}
+	}
+	return active, until
+}
+
+// MaintenanceError 表示函数处于维护窗口内，调用被拒绝。
+// 可通过 errors.Is(err, ErrFunctionInMaintenance) 判断。
+type MaintenanceError struct {
+	// Reason 是维护原因
+	Reason string
+	// Until 是维护结束时间
+	Until time.Time
+}
+
+// Error 实现 error 接口。
+func (e *MaintenanceError) Error() string {
+	msg := ErrFunctionInMaintenance.Error() + " until " + e.Until.UTC().Format(time.RFC3339)
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
+
+// Is 使 errors.Is(err, ErrFunctionInMaintenance) 返回 true。
+func (e *MaintenanceError) Is(target error) bool {
+	return target == ErrFunctionInMaintenance
+}
+
+// RetryAfter 返回距离维护结束的秒数（向上取整，至少为 1），用于 Retry-After 响应头。
+func (e *MaintenanceError) RetryAfter(now time.Time) int {
+	d := e.Until.Sub(now)
+	sec := int((d + time.Second - 1) / time.Second)
+	if sec < 1 {
+		sec = 1
+	}
+	return sec
+}
+
+// CheckMaintenance 检查函数在指定时刻是否处于维护窗口。
+// 处于窗口内时返回 *MaintenanceError，否则返回 nil。
+func CheckMaintenance(fn *Function, now time.Time) *MaintenanceError {
+	if fn == nil || len(fn.MaintenanceWindows) == 0 {
+		return nil
+	}
+	w, until := ActiveMaintenanceWindow(fn.MaintenanceWindows, now)
+	if w == nil {
+		return nil
+	}
+	return &MaintenanceError{Reason: w.Reason, Until: until}
+}
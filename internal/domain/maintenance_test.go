@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestActiveMaintenanceWindow(t *testing.T) {
+	// 每天 02:00 开始，持续 1 小时
+	windows := []MaintenanceWindow{{Schedule: "0 0 2 * * *", DurationSec: 3600, Reason: "db upgrade"}}
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		wantUntil time.Time // 零值表示不在窗口内
+	}{
+		{"before window", day.Add(time.Hour + 59*time.Minute), time.Time{}},
+		{"window start", day.Add(2 * time.Hour), day.Add(3 * time.Hour)},
+		{"inside window", day.Add(2*time.Hour + 30*time.Minute), day.Add(3 * time.Hour)},
+		{"window end", day.Add(3 * time.Hour), time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, until := ActiveMaintenanceWindow(windows, tt.now)
+			if tt.wantUntil.IsZero() {
+				if w != nil {
+					t.Errorf("expected no active window, got until %v", until)
+				}
+				return
+			}
+			if w == nil || !until.Equal(tt.wantUntil) {
+				t.Errorf("got (%v, %v), want until %v", w, until, tt.wantUntil)
+			}
+		})
+	}
+
+	// 重叠的触发取最晚的结束时间：每 10 分钟开始、持续 30 分钟
+	overlapping := []MaintenanceWindow{{Schedule: "0 */10 * * * *", DurationSec: 1800}}
+	if _, until := ActiveMaintenanceWindow(overlapping, day.Add(25*time.Minute)); !until.Equal(day.Add(50 * time.Minute)) {
+		t.Errorf("overlapping until = %v, want %v", until, day.Add(50*time.Minute))
+	}
+
+	fn := &Function{MaintenanceWindows: windows}
+	merr := CheckMaintenance(fn, day.Add(2*time.Hour+59*time.Minute+59*time.Second+500*time.Millisecond))
+	if merr == nil || !errors.Is(merr, ErrFunctionInMaintenance) || merr.Reason != "db upgrade" {
+		t.Fatalf("CheckMaintenance() = %v", merr)
+	}
+	if got := merr.RetryAfter(day.Add(2*time.Hour + 59*time.Minute + 59*time.Second + 500*time.Millisecond)); got != 1 {
+		t.Errorf("RetryAfter() = %d, want 1", got)
+	}
+
+	if err := ValidateMaintenanceWindows([]MaintenanceWindow{{Schedule: "0 0 2 * * *", DurationSec: 0}}); err != ErrInvalidMaintenanceWindow {
+		t.Errorf("ValidateMaintenanceWindows() error = %v, want ErrInvalidMaintenanceWindow", err)
+	}
+}
@@ -0,0 +1,145 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了由自定义 HTTP 路由生成 OpenAPI 3 文档的相关结构体。
+package domain
+
+import (
+	"net/http"
+	"strings"
+)
+
+// OpenAPIVersion 是生成的 OpenAPI 文档版本
+const OpenAPIVersion = "3.0.3"
+
+// openAPIAnyMethods 是未限制 HTTP 方法的路由在文档中列出的方法
+var openAPIAnyMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// OpenAPIDocument 是 OpenAPI 3 文档（仅包含本平台用到的字段）
+type OpenAPIDocument struct {
+	OpenAPI string                     `json:"openapi"`
+	Info    OpenAPIInfo                `json:"info"`
+	Servers []OpenAPIServer            `json:"servers,omitempty"`
+	Paths   map[string]OpenAPIPathItem `json:"paths"`
+}
+
+// OpenAPIInfo 是文档的基本信息
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// OpenAPIServer 是 API 的访问地址
+type OpenAPIServer struct {
+	URL string `json:"url"`
+}
+
+// OpenAPIPathItem 是单个路径下按小写 HTTP 方法索引的操作
+type OpenAPIPathItem map[string]*OpenAPIOperation
+
+// OpenAPIOperation 描述一个路径上的一个 HTTP 方法
+type OpenAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Deprecated  bool                       `json:"deprecated,omitempty"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+}
+
+// OpenAPIParameter 描述一个请求参数
+type OpenAPIParameter struct {
+	Name     string                 `json:"name"`
+	In       string                 `json:"in"`
+	Required bool                   `json:"required"`
+	Schema   map[string]interface{} `json:"schema"`
+}
+
+// OpenAPIRequestBody 描述请求体
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse 描述一个响应
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType 描述某种媒体类型的内容结构
+type OpenAPIMediaType struct {
+	Schema map[string]interface{} `json:"schema"`
+}
+
+// BuildOpenAPIDocument 根据函数的自定义 HTTP 路由生成 OpenAPI 3 文档。
+// 每个路由的每个允许的方法生成一个操作，未限制方法的路由列出 GET/POST/PUT/PATCH/DELETE；
+// 路由模板中的参数生成必填的 path 参数。函数没有声明输入输出结构，请求体和响应体描述为任意 JSON。
+// 未配置自定义 HTTP 路由的函数被忽略。
+//
+// 参数:
+//   - title: 文档标题
+//   - version: 文档版本
+//   - functions: 要导出的函数
+//
+// 返回值:
+//   - *OpenAPIDocument: 生成的文档
+func BuildOpenAPIDocument(title, version string, functions []*Function) *OpenAPIDocument {
+	doc := &OpenAPIDocument{
+		OpenAPI: OpenAPIVersion,
+		Info:    OpenAPIInfo{Title: title, Version: version},
+		Paths:   make(map[string]OpenAPIPathItem),
+	}
+	for _, fn := range functions {
+		if fn.HTTPPath == "" {
+			continue
+		}
+		item := doc.Paths[fn.HTTPPath]
+		if item == nil {
+			item = make(OpenAPIPathItem)
+			doc.Paths[fn.HTTPPath] = item
+		}
+		methods := fn.HTTPMethods
+		if len(methods) == 0 {
+			methods = openAPIAnyMethods
+		}
+		for _, method := range methods {
+			key := strings.ToLower(method)
+			item[key] = openAPIOperation(fn, key)
+		}
+	}
+	return doc
+}
+
+// openAPIOperation 生成函数路由上单个方法的操作描述
+func openAPIOperation(fn *Function, method string) *OpenAPIOperation {
+	anyJSON := map[string]OpenAPIMediaType{"application/json": {Schema: map[string]interface{}{}}}
+	op := &OpenAPIOperation{
+		OperationID: fn.Name + "_" + method,
+		Summary:     fn.Name,
+		Description: fn.Description,
+		Tags:        fn.Tags,
+		Deprecated:  fn.Deprecation != nil,
+		Responses: map[string]OpenAPIResponse{
+			"200": {Description: "Function result", Content: anyJSON},
+			"429": {Description: "Rate limit exceeded"},
+			"503": {Description: "Function is temporarily unavailable"},
+		},
+	}
+	for _, seg := range strings.Split(fn.HTTPPath, "/") {
+		if name, ok := pathParamName(seg); ok {
+			op.Parameters = append(op.Parameters, OpenAPIParameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   map[string]interface{}{"type": "string"},
+			})
+		}
+	}
+	switch method {
+	case "post", "put", "patch":
+		op.RequestBody = &OpenAPIRequestBody{Content: anyJSON}
+	}
+	return op
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"testing"
+)
+
+// TestBuildOpenAPIDocument 测试由自定义路由生成 OpenAPI 文档：方法展开、路径参数和请求体。
+func TestBuildOpenAPIDocument(t *testing.T) {
+	functions := []*Function{
+		{Name: "get-order", HTTPPath: "/orders/{orderId}", HTTPMethods: []string{"GET"}},
+		{Name: "any", HTTPPath: "/any"},
+		{Name: "no-route"},
+	}
+	doc := BuildOpenAPIDocument("test", "1", functions)
+
+	if len(doc.Paths) != 2 {
+		t.Fatalf("len(Paths) = %d, want 2", len(doc.Paths))
+	}
+	op := doc.Paths["/orders/{orderId}"]["get"]
+	if op == nil || len(doc.Paths["/orders/{orderId}"]) != 1 {
+		t.Fatalf("expected only a get operation on /orders/{orderId}, got %v", doc.Paths["/orders/{orderId}"])
+	}
+	if len(op.Parameters) != 1 || op.Parameters[0].Name != "orderId" || op.Parameters[0].In != "path" || !op.Parameters[0].Required {
+		t.Errorf("Parameters = %+v, want required path parameter orderId", op.Parameters)
+	}
+	if op.RequestBody != nil {
+		t.Error("get operation should not have a request body")
+	}
+
+	anyItem := doc.Paths["/any"]
+	if len(anyItem) != len(openAPIAnyMethods) {
+		t.Errorf("route without methods has %d operations, want %d", len(anyItem), len(openAPIAnyMethods))
+	}
+	if anyItem["post"] == nil || anyItem["post"].RequestBody == nil {
+		t.Error("post operation should have a request body")
+	}
+	if anyItem["post"].OperationID != "any_post" {
+		t.Errorf("OperationID = %q, want any_post", anyItem["post"].OperationID)
+	}
+}
@@ -0,0 +1,138 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了调用进度相关的类型。
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+)
+
+// ProgressFramePrefix 是运行时上报进度事件的 stderr 帧前缀。
+// 运行时输出形如 "__NIMBUS_PROGRESS__ {\"percent\":50}" 的单行，
+// 执行器会将其从 stderr 中剥离并解析为 InvocationProgress。
+const ProgressFramePrefix = "__NIMBUS_PROGRESS__ "
+
+// InvocationProgress 表示长时间运行的函数上报的执行进度。
+type InvocationProgress struct {
+	// Percent 是完成百分比（0-100）
+	Percent float64 `json:"percent"`
+	// Message 是进度描述（可选）
+	Message string `json:"message,omitempty"`
+	// Data 是函数自定义的进度数据（可选）
+	Data json.RawMessage `json:"data,omitempty"`
+	// UpdatedAt 是进度上报时间
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ProgressReporter 是接收函数进度事件的回调。
+type ProgressReporter func(progress *InvocationProgress)
+
+// progressReporterKey 是 ProgressReporter 在 context 中的键
+type progressReporterKey struct{}
+
+// WithProgressReporter 返回携带进度回调的 context，执行器据此转发函数上报的进度。
+func WithProgressReporter(ctx context.Context, reporter ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressReporterKey{}, reporter)
+}
+
+// ProgressReporterFromContext 从 context 中取出进度回调，未设置时返回 nil。
+func ProgressReporterFromContext(ctx context.Context) ProgressReporter {
+	reporter, _ := ctx.Value(progressReporterKey{}).(ProgressReporter)
+	return reporter
+}
+
+// ExecOutputChunk 是函数执行过程中容器实时输出的一段内容。
+type ExecOutputChunk struct {
+	// Stream 是输出流：stdout 或 stderr
+	Stream string `json:"stream"`
+	// Data 是输出内容
+	Data string `json:"data"`
+	// Timestamp 是输出时间
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// invocationIDKey 是调用 ID 在 context 中的键
+type invocationIDKey struct{}
+
+// WithInvocationID 返回携带调用 ID 的 context，执行器据此发布该调用的实时输出。
+func WithInvocationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, invocationIDKey{}, id)
+}
+
+// InvocationIDFromContext 从 context 中取出调用 ID，未设置时返回空字符串。
+func InvocationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(invocationIDKey{}).(string)
+	return id
+}
+
+// outputSinkKey 是调用输出接收函数在 context 中的键
+type outputSinkKey struct{}
+
+// WithOutputSink 返回携带输出接收函数的 context，执行器将调用的容器输出逐段交给 sink，用于流式调用。
+// sink 在执行协程中同步调用，不应阻塞。
+func WithOutputSink(ctx context.Context, sink func(ExecOutputChunk)) context.Context {
+	return context.WithValue(ctx, outputSinkKey{}, sink)
+}
+
+// OutputSinkFromContext 从 context 中取出输出接收函数，未设置时返回 nil。
+func OutputSinkFromContext(ctx context.Context) func(ExecOutputChunk) {
+	sink, _ := ctx.Value(outputSinkKey{}).(func(ExecOutputChunk))
+	return sink
+}
+
+// instancePinKey 是实例亲和提示在 context 中的键
+type instancePinKey struct{}
+
+// WithInstancePin 返回携带实例亲和提示的 context。
+// 相同提示的连续调用优先复用同一个预热实例，执行器不支持时忽略。
+func WithInstancePin(ctx context.Context, pin string) context.Context {
+	return context.WithValue(ctx, instancePinKey{}, pin)
+}
+
+// InstancePinFromContext 从 context 中取出实例亲和提示，未设置时返回空字符串。
+func InstancePinFromContext(ctx context.Context) string {
+	pin, _ := ctx.Value(instancePinKey{}).(string)
+	return pin
+}
+
+// ExecutionTracker 记录执行器是否已经开始运行函数代码。
+// 调度器据此判断失败的调用能否透明重试：函数代码开始运行之后的失败即使属于平台故障也不重试，
+// 避免非幂等的函数被执行两次。零值可用，nil 时所有方法为空操作。
+type ExecutionTracker struct {
+	started atomic.Bool
+}
+
+// MarkStarted 标记函数代码已开始运行（执行器在启动 docker exec 等运行用户代码的命令之前调用）。
+func (t *ExecutionTracker) MarkStarted() {
+	if t != nil {
+		t.started.Store(true)
+	}
+}
+
+// Reset 清除标记，调度器在每次重试前调用。
+func (t *ExecutionTracker) Reset() {
+	if t != nil {
+		t.started.Store(false)
+	}
+}
+
+// Started 判断函数代码是否已开始运行。
+func (t *ExecutionTracker) Started() bool {
+	return t != nil && t.started.Load()
+}
+
+// executionTrackerKey 是 ExecutionTracker 在 context 中的键
+type executionTrackerKey struct{}
+
+// WithExecutionTracker 返回携带执行跟踪器的 context，执行器据此上报函数代码是否已开始运行。
+func WithExecutionTracker(ctx context.Context, tracker *ExecutionTracker) context.Context {
+	return context.WithValue(ctx, executionTrackerKey{}, tracker)
+}
+
+// MarkExecutionStarted 标记 context 中的执行跟踪器：函数代码已开始运行，未设置跟踪器时不做任何事。
+func MarkExecutionStarted(ctx context.Context) {
+	tracker, _ := ctx.Value(executionTrackerKey{}).(*ExecutionTracker)
+	tracker.MarkStarted()
+}
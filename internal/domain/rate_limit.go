@@ -0,0 +1,42 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了调用限流相关的类型。
+package domain
+
+import "math"
+
+// RateLimitConfig 定义函数的调用限流配置，采用令牌桶算法。
+// 令牌以 RequestsPerSecond 的速率补充，桶容量为 Burst。
+type RateLimitConfig struct {
+	// RequestsPerSecond 是令牌补充速率（每秒请求数）
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	// Burst 是令牌桶容量，即允许的最大突发请求数，为 0 时取 RequestsPerSecond 向上取整
+	Burst int `json:"burst,omitempty"`
+	// PerKey 表示按调用方（API Key 或用户）分别计数，否则所有调用方共享同一个令牌桶
+	PerKey bool `json:"per_key,omitempty"`
+}
+
+// Validate 验证限流配置的有效性，并为 Burst 设置默认值。
+//
+// 返回值:
+//   - error: 速率不为正数或突发容量为负数时返回 ErrInvalidRateLimit
+func (c *RateLimitConfig) Validate() error {
+	if c.RequestsPerSecond <= 0 || c.Burst < 0 {
+		return ErrInvalidRateLimit
+	}
+	if c.Burst == 0 {
+		c.Burst = int(math.Ceil(c.RequestsPerSecond))
+	}
+	return nil
+}
+
+// RateLimitStatus 描述一次限流检查后令牌桶的状态，用于生成 X-RateLimit-* 响应头。
+type RateLimitStatus struct {
+	// Allowed 表示本次请求是否被放行
+	Allowed bool `json:"allowed"`
+	// Limit 是令牌桶容量
+	Limit int `json:"limit"`
+	// Remaining 是本次请求后剩余的令牌数
+	Remaining int `json:"remaining"`
+	// ResetSec 是令牌桶补满所需的秒数
+	ResetSec int `json:"reset_sec"`
+}
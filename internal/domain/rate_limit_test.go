@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestRateLimitConfigValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       RateLimitConfig
+		wantErr   bool
+		wantBurst int
+	}{
+		{name: "explicit burst", cfg: RateLimitConfig{RequestsPerSecond: 5, Burst: 20}, wantBurst: 20},
+		{name: "default burst rounds up", cfg: RateLimitConfig{RequestsPerSecond: 2.5}, wantBurst: 3},
+		{name: "zero rate", cfg: RateLimitConfig{}, wantErr: true},
+		{name: "negative burst", cfg: RateLimitConfig{RequestsPerSecond: 1, Burst: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && tt.cfg.Burst != tt.wantBurst {
+				t.Errorf("Burst = %d, want %d", tt.cfg.Burst, tt.wantBurst)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了响应缓存相关的类型。
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxResponseCacheTTLSeconds 是响应缓存的最长有效期（秒）
+	MaxResponseCacheTTLSeconds = 86400
+	// MaxCacheKeyExpressions 是缓存键表达式中最多可以包含的字段路径数
+	MaxCacheKeyExpressions = 16
+)
+
+// ResponseCacheConfig 定义纯函数（相同输入总是产生相同输出）的响应缓存配置。
+// 同步调用成功的响应按缓存键保存在 Redis 中，有效期内相同缓存键的调用直接返回缓存的响应。
+type ResponseCacheConfig struct {
+	// TTLSeconds 是缓存有效期（秒），1-86400
+	TTLSeconds int `json:"ttl_seconds"`
+	// CacheKeyExpression 是参与计算缓存键的字段路径（JSONPath 子集，如 "$.user.id"、"items[0].sku"），
+	// 为空时使用整个请求载荷计算缓存键
+	CacheKeyExpression []string `json:"cache_key_expression,omitempty"`
+}
+
+// Validate 验证响应缓存配置的有效性。
+//
+// 返回值:
+//   - error: 有效期超出范围或字段路径无法解析时返回 ErrInvalidResponseCache
+func (c *ResponseCacheConfig) Validate() error {
+	if c.TTLSeconds < 1 || c.TTLSeconds > MaxResponseCacheTTLSeconds {
+		return ErrInvalidResponseCache
+	}
+	if len(c.CacheKeyExpression) > MaxCacheKeyExpressions {
+		return ErrInvalidResponseCache
+	}
+	for _, expr := range c.CacheKeyExpression {
+		if _, err := parseCacheKeyPath(expr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResponseCacheKey 计算一次调用的响应缓存键。
+// 缓存键由函数 ID、函数版本、蓝绿线上槽位和输入摘要组成，函数发布新版本或切换槽位后旧缓存自然失效。
+// 配置了 CacheKeyExpression 时只取表达式指定的字段（缺失的字段视为 null），
+// 否则使用整个载荷；JSON 载荷按键排序后再计算摘要，字段顺序不同的等价请求得到相同的缓存键。
+//
+// 参数:
+//   - fn: 被调用的函数
+//   - payload: 请求载荷
+//
+// 返回值:
+//   - string: 缓存键
+func (c *ResponseCacheConfig) ResponseCacheKey(fn *Function, payload json.RawMessage) string {
+	var doc interface{}
+	canonical := []byte(payload)
+	if err := json.Unmarshal(payload, &doc); err == nil {
+		var input interface{} = doc
+		if len(c.CacheKeyExpression) > 0 {
+			values := make([]interface{}, len(c.CacheKeyExpression))
+			for i, expr := range c.CacheKeyExpression {
+				path, _ := parseCacheKeyPath(expr)
+				values[i] = lookupCacheKeyPath(doc, path)
+			}
+			input = values
+		}
+		// encoding/json 按键排序输出 map，得到规范化的 JSON
+		if data, err := json.Marshal(input); err == nil {
+			canonical = data
+		}
+	}
+	sum := sha256.Sum256(canonical)
+	return fn.ID + ":" + strconv.Itoa(fn.Version) + ":" + fn.LiveSlot + ":" + hex.EncodeToString(sum[:])
+}
+
+// parseCacheKeyPath 将字段路径解析为对象键（string）和数组下标（int）组成的片段。
+// 支持可选的 "$" 根前缀、点号分隔的字段名和 "[n]" 数组下标。
+func parseCacheKeyPath(expr string) ([]interface{}, error) {
+	rest := strings.TrimPrefix(strings.TrimSpace(expr), "$")
+	rest = strings.TrimPrefix(rest, ".")
+	if rest == "" {
+		return nil, ErrInvalidResponseCache
+	}
+	var path []interface{}
+	for _, part := range strings.Split(rest, ".") {
+		name := part
+		var indexes []interface{}
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			name = part[:i]
+			for brackets := part[i:]; brackets != ""; {
+				end := strings.IndexByte(brackets, ']')
+				if brackets[0] != '[' || end < 0 {
+					return nil, ErrInvalidResponseCache
+				}
+				n, err := strconv.Atoi(brackets[1:end])
+				if err != nil || n < 0 {
+					return nil, ErrInvalidResponseCache
+				}
+				indexes = append(indexes, n)
+				brackets = brackets[end+1:]
+			}
+		}
+		if name == "" && len(indexes) == 0 {
+			return nil, ErrInvalidResponseCache
+		}
+		if name != "" {
+			path = append(path, name)
+		}
+		path = append(path, indexes...)
+	}
+	return path, nil
+}
+
+// lookupCacheKeyPath 按字段路径取出 JSON 文档中的值，路径不存在时返回 nil。
+func lookupCacheKeyPath(doc interface{}, path []interface{}) interface{} {
+	cur := doc
+	for _, seg := range path {
+		switch key := seg.(type) {
+		case string:
+			obj, ok := cur.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			cur = obj[key]
+		case int:
+			arr, ok := cur.([]interface{})
+			if !ok || key >= len(arr) {
+				return nil
+			}
+			cur = arr[key]
+		}
+	}
+	return cur
+}
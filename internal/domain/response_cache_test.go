@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestResponseCacheKey(t *testing.T) {
+	fn := &Function{ID: "fn-1", Version: 3}
+
+	full := &ResponseCacheConfig{TTLSeconds: 60}
+	if full.ResponseCacheKey(fn, json.RawMessage(`{"a":1,"b":2}`)) != full.ResponseCacheKey(fn, json.RawMessage(`{"b":2, "a":1}`)) {
+		t.Error("full-payload key should not depend on field order")
+	}
+	if full.ResponseCacheKey(fn, json.RawMessage(`{"a":1}`)) == full.ResponseCacheKey(fn, json.RawMessage(`{"a":2}`)) {
+		t.Error("different payloads should produce different keys")
+	}
+
+	expr := &ResponseCacheConfig{TTLSeconds: 60, CacheKeyExpression: []string{"$.user.id", "items[0].sku"}}
+	if err := expr.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	a := expr.ResponseCacheKey(fn, json.RawMessage(`{"user":{"id":7},"items":[{"sku":"x"}],"ts":1}`))
+	b := expr.ResponseCacheKey(fn, json.RawMessage(`{"user":{"id":7},"items":[{"sku":"x","qty":2}],"ts":2}`))
+	c := expr.ResponseCacheKey(fn, json.RawMessage(`{"user":{"id":8},"items":[{"sku":"x"}],"ts":1}`))
+	if a != b || a == c {
+		t.Errorf("expression keys: a=%s b=%s c=%s, want a==b!=c", a, b, c)
+	}
+	fn.Version = 4
+	if a == expr.ResponseCacheKey(fn, json.RawMessage(`{"user":{"id":7},"items":[{"sku":"x"}]}`)) {
+		t.Error("key should change with function version")
+	}
+
+	for _, cfg := range []ResponseCacheConfig{
+		{TTLSeconds: 0},
+		{TTLSeconds: MaxResponseCacheTTLSeconds + 1},
+		{TTLSeconds: 60, CacheKeyExpression: []string{"$"}},
+		{TTLSeconds: 60, CacheKeyExpression: []string{"items[x]"}},
+		{TTLSeconds: 60, CacheKeyExpression: []string{"a..b"}},
+	} {
+		if err := cfg.Validate(); err != ErrInvalidResponseCache {
+			t.Errorf("Validate(%+v) = %v, want ErrInvalidResponseCache", cfg, err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了响应指令相关的类型。
+package domain
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// 函数可在 Lambda 样式响应的 headers 中设置以下响应头，控制平台对本次结果的处理。
+// 平台读取后会从响应中移除这些头，不会透传给调用方。
+const (
+	// HeaderCacheControl 覆盖本次响应的缓存策略，值为秒数（如 "60"）或完整的 Cache-Control 值
+	HeaderCacheControl = "X-Nimbus-Cache-Control"
+	// HeaderNoRetry 为 "true" 时本次失败不再重试
+	HeaderNoRetry = "X-Nimbus-No-Retry"
+	// HeaderDLQ 为 "skip" 时本次失败不进入死信队列
+	HeaderDLQ = "X-Nimbus-Dlq"
+)
+
+// ResponseDirectives 描述函数对单次调用结果下发的平台处理指令。
+type ResponseDirectives struct {
+	// CacheControl 是覆盖后的 Cache-Control 响应头值
+	CacheControl string `json:"cache_control,omitempty"`
+	// NoRetry 表示本次失败不再重试
+	NoRetry bool `json:"no_retry,omitempty"`
+	// SkipDLQ 表示本次失败不进入死信队列
+	SkipDLQ bool `json:"skip_dlq,omitempty"`
+}
+
+// ExtractResponseDirectives 从 Lambda 样式的响应体（含 statusCode 和 headers）中
+// 读取并移除 X-Nimbus-* 指令头。响应头名称不区分大小写。
+//
+// 参数:
+//   - body: 函数返回的响应体
+//
+// 返回值:
+//   - json.RawMessage: 移除指令头后的响应体，没有指令时原样返回
+//   - *ResponseDirectives: 解析出的指令，没有指令时为 nil
+func ExtractResponseDirectives(body json.RawMessage) (json.RawMessage, *ResponseDirectives) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return body, nil
+	}
+	if _, ok := fields["statusCode"]; !ok {
+		return body, nil
+	}
+	var headers map[string]string
+	if err := json.Unmarshal(fields["headers"], &headers); err != nil || len(headers) == 0 {
+		return body, nil
+	}
+
+	var directives *ResponseDirectives
+	for name, value := range headers {
+		value = strings.TrimSpace(value)
+		switch {
+		case strings.EqualFold(name, HeaderCacheControl):
+			if _, err := strconv.Atoi(value); err == nil {
+				value = "max-age=" + value
+			}
+			if directives == nil {
+				directives = &ResponseDirectives{}
+			}
+			directives.CacheControl = value
+		case strings.EqualFold(name, HeaderNoRetry):
+			if directives == nil {
+				directives = &ResponseDirectives{}
+			}
+			directives.NoRetry, _ = strconv.ParseBool(value)
+		case strings.EqualFold(name, HeaderDLQ):
+			if directives == nil {
+				directives = &ResponseDirectives{}
+			}
+			directives.SkipDLQ = strings.EqualFold(value, "skip")
+		default:
+			continue
+		}
+		delete(headers, name)
+	}
+	if directives == nil {
+		return body, nil
+	}
+
+	fields["headers"], _ = json.Marshal(headers)
+	stripped, err := json.Marshal(fields)
+	if err != nil {
+		return body, directives
+	}
+	return stripped, directives
+}
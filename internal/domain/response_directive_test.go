@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExtractResponseDirectives(t *testing.T) {
+	body := json.RawMessage(`{"statusCode":200,"headers":{"Content-Type":"text/plain","x-nimbus-cache-control":"60","X-Nimbus-No-Retry":"true","X-Nimbus-Dlq":"skip"},"body":"ok"}`)
+
+	stripped, d := ExtractResponseDirectives(body)
+	if d == nil {
+		t.Fatal("expected directives")
+	}
+	if d.CacheControl != "max-age=60" || !d.NoRetry || !d.SkipDLQ {
+		t.Errorf("directives = %+v", d)
+	}
+
+	var got struct {
+		Headers map[string]string `json:"headers"`
+		Body    string            `json:"body"`
+	}
+	if err := json.Unmarshal(stripped, &got); err != nil {
+		t.Fatalf("unmarshal stripped body: %v", err)
+	}
+	if len(got.Headers) != 1 || got.Headers["Content-Type"] != "text/plain" || got.Body != "ok" {
+		t.Errorf("stripped body = %s", stripped)
+	}
+
+	// 非 Lambda 样式或没有指令头时原样返回
+	for _, raw := range []string{`{"headers":{"X-Nimbus-Dlq":"skip"}}`, `{"statusCode":200,"headers":{"A":"b"}}`, `"text"`} {
+		out, d := ExtractResponseDirectives(json.RawMessage(raw))
+		if d != nil || string(out) != raw {
+			t.Errorf("ExtractResponseDirectives(%s) = (%s, %+v), want unchanged", raw, out, d)
+		}
+	}
+}
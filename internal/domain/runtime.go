@@ -0,0 +1,171 @@
+package domain
+
+import "encoding/json"
+
+// ==================== 运行时契约相关类型 ====================
+
+// RuntimeContract 描述函数在某个运行时中的输入输出契约，用于指导用户编写处理函数。
+type RuntimeContract struct {
+	// Runtime 是运行时标识
+	Runtime Runtime `json:"runtime"`
+	// Language 是编程语言
+	Language string `json:"language"`
+	// HandlerFormat 说明 handler 字段的含义
+	HandlerFormat string `json:"handler_format"`
+	// HandlerSignature 是处理函数的签名
+	HandlerSignature string `json:"handler_signature"`
+	// CodeFormat 说明 code 字段的格式
+	CodeFormat string `json:"code_format"`
+	// Compiled 表示上传源代码时是否由平台编译
+	Compiled bool `json:"compiled"`
+	// DebugSupported 表示是否支持断点调试
+	DebugSupported bool `json:"debug_supported"`
+	// Image 是 Docker 运行模式下使用的运行时镜像，其他运行模式为空
+	Image string `json:"image,omitempty"`
+	// InputEnvelope 是执行器写入运行时 stdin 的输入信封的 JSON Schema
+	InputEnvelope json.RawMessage `json:"input_envelope"`
+	// Input 说明处理函数收到的输入
+	Input string `json:"input"`
+	// Output 说明处理函数的输出要求
+	Output string `json:"output"`
+	// Errors 说明错误的报告方式
+	Errors string `json:"errors"`
+	// Environment 是平台在每次调用时注入的环境变量及其含义
+	Environment map[string]string `json:"environment"`
+	// ExampleHandler 是处理函数示例（创建函数时可直接作为 code 使用）
+	ExampleHandler string `json:"example_handler"`
+}
+
+// runtimeInputEnvelope 是所有运行时共用的 stdin 输入信封 JSON Schema
+var runtimeInputEnvelope = json.RawMessage(`{
+  "type": "object",
+  "required": ["handler", "code", "payload"],
+  "properties": {
+    "handler": {"type": "string", "description": "function handler: function name, or [dir/]file.function"},
+    "code": {"type": "string", "description": "function source code, or base64 binary for compiled runtimes"},
+    "payload": {"description": "invocation payload (any JSON value)"},
+    "env": {"type": "object", "additionalProperties": {"type": "string"}, "description": "environment variables for this invocation"},
+    "files": {"type": "object", "additionalProperties": {"type": "string"}, "description": "optional multi-file source keyed by relative path; the handler's file part selects the entry file and code is ignored"}
+  }
+}`)
+
+// runtimeEnvironment 是平台在每次调用时注入的环境变量
+var runtimeEnvironment = map[string]string{
+	EnvDeadlineMs: "hard deadline of the invocation as Unix epoch milliseconds",
+	EnvTimeoutMs:  "effective timeout of the invocation in milliseconds",
+}
+
+// 所有运行时共用的输出与错误约定
+const (
+	runtimeOutputJSON = "print a single JSON value to stdout; non-JSON output is wrapped as {\"output\": \"...\"}. " +
+		"In a Lambda-style response ({\"statusCode\", \"headers\", \"body\"}), X-Nimbus-* headers are read as platform directives and stripped"
+	runtimeErrors = "exit non-zero and write {\"error\": \"...\"} to stderr; use \"error_type\": \"init_error\" when the handler cannot be loaded. " +
+		"Lines on stderr prefixed with \"__NIMBUS_PROGRESS__ \" are progress frames, not logs"
+)
+
+// RuntimeContracts 返回所有受支持运行时的契约，按运行时固定顺序排列。
+// 镜像和调试支持由调用方按实际部署填充。
+//
+// 返回值:
+//   - []RuntimeContract: 运行时契约列表
+func RuntimeContracts() []RuntimeContract {
+	contracts := []RuntimeContract{
+		{
+			Runtime:          RuntimePython311,
+			Language:         "Python 3.11",
+			HandlerFormat:    "function name, or [dir/]file.function (e.g. src/main.handler); the file selects the entry file of a multi-file function, default handler.py",
+			HandlerSignature: "def handler(event, context) -> Any",
+			CodeFormat:       "Python source code, executed with exec()",
+			Input:            "event is the payload decoded into a dict/list/scalar; context provides function_name, memory_limit_in_mb, report_progress() and get_remaining_time_in_millis()",
+			ExampleHandler: `def handler(event, context):
+    name = event.get("name", "world")
+    return {"message": f"hello {name}"}
+`,
+		},
+		{
+			Runtime:          RuntimeNodeJS20,
+			Language:         "Node.js 20",
+			HandlerFormat:    "exported function name, or [dir/]file.function (e.g. src/index.handler), resolved from module.exports or exports of the entry file, default handler.js",
+			HandlerSignature: "async function handler(event, context) => any",
+			CodeFormat:       "JavaScript source code, executed in a vm sandbox",
+			Input:            "event is the payload decoded into a JS value; context provides functionName, reportProgress() and getRemainingTimeInMillis()",
+			ExampleHandler: `exports.handler = async (event, context) => {
+  const name = event.name || 'world';
+  return { message: 'hello ' + name };
+};
+`,
+		},
+		{
+			Runtime:          RuntimeBun,
+			Language:         "Bun 1.x (JavaScript / TypeScript)",
+			HandlerFormat:    "exported function name, or [dir/]file.function (e.g. src/index.handler), resolved from the entry file's named exports or module.exports",
+			HandlerSignature: "export async function handler(event, context): Promise<any>",
+			CodeFormat:       "JavaScript or TypeScript source code (ES module or CommonJS), transpiled natively by Bun",
+			Input:            "event is the payload decoded into a JS value; context provides functionName, reportProgress() and getRemainingTimeInMillis()",
+			ExampleHandler: `export async function handler(event: { name?: string }, context: any) {
+  const name = event.name ?? 'world';
+  return { message: 'hello ' + name };
+}
+`,
+		},
+		{
+			Runtime:          RuntimeGo124,
+			Language:         "Go 1.24",
+			HandlerFormat:    "not used by the runtime, but required on create (e.g. handler)",
+			HandlerSignature: "func main() // read payload from stdin, write result to stdout",
+			CodeFormat:       "Go source code (package main, compiled by the platform) or a base64 Linux executable",
+			Compiled:         true,
+			Input:            "the raw payload JSON bytes on stdin; env vars are set in the process environment",
+			ExampleHandler: `package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+func main() {
+	var event map[string]interface{}
+	json.NewDecoder(os.Stdin).Decode(&event)
+	name, _ := event["name"].(string)
+	if name == "" {
+		name = "world"
+	}
+	json.NewEncoder(os.Stdout).Encode(map[string]string{"message": "hello " + name})
+}
+`,
+		},
+		{
+			Runtime:          RuntimeWasm,
+			Language:         "WebAssembly (Rust)",
+			HandlerFormat:    "not used by the runtime, but required on create (e.g. handle)",
+			HandlerSignature: "export alloc(size: u32) -> *mut u8 and handle(ptr: *const u8, len: u32) -> u64 (high 32 bits: output pointer, low 32 bits: output length)",
+			CodeFormat:       "Rust source code (compiled to wasm32-unknown-unknown by the platform) or a base64 Wasm module",
+			Compiled:         true,
+			Input:            "the raw payload JSON bytes written to memory returned by alloc",
+			ExampleHandler: `#[no_mangle]
+pub extern "C" fn alloc(size: u32) -> *mut u8 {
+    let mut buf = Vec::with_capacity(size as usize);
+    let ptr = buf.as_mut_ptr();
+    std::mem::forget(buf);
+    ptr
+}
+
+#[no_mangle]
+pub extern "C" fn handle(_ptr: *const u8, _len: u32) -> u64 {
+    let out = br#"{"message":"hello world"}"#.to_vec();
+    let (ptr, len) = (out.as_ptr() as u64, out.len() as u64);
+    std::mem::forget(out);
+    (ptr << 32) | len
+}
+`,
+		},
+	}
+
+	for i := range contracts {
+		contracts[i].InputEnvelope = runtimeInputEnvelope
+		contracts[i].Output = runtimeOutputJSON
+		contracts[i].Errors = runtimeErrors
+		contracts[i].Environment = runtimeEnvironment
+	}
+	return contracts
+}
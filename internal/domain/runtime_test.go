@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestRuntimeContracts 测试每个受支持的运行时都有契约，且输入信封是合法的 JSON Schema。
+func TestRuntimeContracts(t *testing.T) {
+	seen := make(map[Runtime]bool)
+	for _, c := range RuntimeContracts() {
+		if !c.Runtime.IsValid() {
+			t.Errorf("contract for unsupported runtime %q", c.Runtime)
+		}
+		if c.HandlerSignature == "" || c.ExampleHandler == "" || c.Output == "" {
+			t.Errorf("%s: incomplete contract %+v", c.Runtime, c)
+		}
+		var schema map[string]interface{}
+		if err := json.Unmarshal(c.InputEnvelope, &schema); err != nil {
+			t.Errorf("%s: invalid input envelope: %v", c.Runtime, err)
+		}
+		seen[c.Runtime] = true
+	}
+	for _, r := range []Runtime{RuntimePython311, RuntimeNodeJS20, RuntimeGo124, RuntimeWasm, RuntimeBun} {
+		if !seen[r] {
+			t.Errorf("missing contract for %s", r)
+		}
+	}
+}
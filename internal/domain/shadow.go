@@ -0,0 +1,87 @@
+// Package domain 定义了函数计算平台的核心领域模型。
+// 本文件定义了影子流量相关的类型。
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// MaxShadowTimeoutMs 是影子调用超时的上限（5 分钟）
+const MaxShadowTimeoutMs = 300000
+
+// ShadowConfig 定义函数的影子流量配置。
+// 同步调用时按比例将请求异步回放到影子目标，影子结果仅用于对比，不影响真实响应。
+type ShadowConfig struct {
+	// FunctionID 是主函数 ID
+	FunctionID string `json:"function_id"`
+	// TargetFunctionID 是影子目标函数 ID，为空表示主函数自身
+	TargetFunctionID string `json:"target_function_id,omitempty"`
+	// TargetVersion 是影子目标版本号，0 表示目标函数的当前代码
+	TargetVersion int `json:"target_version,omitempty"`
+	// Percentage 是回放到影子目标的流量百分比（0-100）
+	Percentage int `json:"percentage"`
+	// Enabled 表示是否启用影子流量
+	Enabled bool `json:"enabled"`
+	// TimeoutMs 是影子调用的超时（毫秒），0 表示使用调度器的 shadow_timeout
+	TimeoutMs int `json:"timeout_ms,omitempty"`
+	// CreatedAt 是配置创建时间
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt 是配置最后更新时间
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate 验证影子流量配置的有效性。
+//
+// 返回值:
+//   - error: 百分比或超时超出范围，或影子目标与主函数当前代码相同时返回 ErrInvalidShadowConfig
+func (c *ShadowConfig) Validate() error {
+	if c.Percentage < 0 || c.Percentage > 100 {
+		return ErrInvalidShadowConfig
+	}
+	if c.TargetVersion < 0 {
+		return ErrInvalidShadowConfig
+	}
+	if c.TimeoutMs < 0 || c.TimeoutMs > MaxShadowTimeoutMs {
+		return ErrInvalidShadowConfig
+	}
+	// 影子目标不能就是主函数的当前代码，否则对比没有意义
+	if (c.TargetFunctionID == "" || c.TargetFunctionID == c.FunctionID) && c.TargetVersion == 0 {
+		return ErrInvalidShadowConfig
+	}
+	return nil
+}
+
+// ShadowResult 记录一次影子调用与主调用的对比结果。
+type ShadowResult struct {
+	// ID 是结果记录的唯一标识符
+	ID string `json:"id"`
+	// FunctionID 是主函数 ID
+	FunctionID string `json:"function_id"`
+	// PrimaryInvocationID 是主调用 ID
+	PrimaryInvocationID string `json:"primary_invocation_id"`
+	// ShadowInvocationID 是影子调用 ID（影子调用未能提交时为空）
+	ShadowInvocationID string `json:"shadow_invocation_id,omitempty"`
+	// TargetFunctionID 是影子目标函数 ID
+	TargetFunctionID string `json:"target_function_id"`
+	// TargetVersion 是影子目标版本号
+	TargetVersion int `json:"target_version,omitempty"`
+	// PrimaryStatusCode 是主调用的状态码
+	PrimaryStatusCode int `json:"primary_status_code"`
+	// ShadowStatusCode 是影子调用的状态码
+	ShadowStatusCode int `json:"shadow_status_code"`
+	// PrimaryDurationMs 是主调用耗时（毫秒）
+	PrimaryDurationMs int64 `json:"primary_duration_ms"`
+	// ShadowDurationMs 是影子调用耗时（毫秒）
+	ShadowDurationMs int64 `json:"shadow_duration_ms"`
+	// PrimaryOutput 是主调用的输出
+	PrimaryOutput json.RawMessage `json:"primary_output,omitempty"`
+	// ShadowOutput 是影子调用的输出
+	ShadowOutput json.RawMessage `json:"shadow_output,omitempty"`
+	// ShadowError 是影子调用的错误信息
+	ShadowError string `json:"shadow_error,omitempty"`
+	// Match 表示影子调用的状态码和输出是否与主调用一致
+	Match bool `json:"match"`
+	// CreatedAt 是结果记录时间
+	CreatedAt time.Time `json:"created_at"`
+}
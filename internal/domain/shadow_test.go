@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestShadowConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ShadowConfig
+		wantErr bool
+	}{
+		{name: "other function", cfg: ShadowConfig{FunctionID: "a", TargetFunctionID: "b", Percentage: 10}},
+		{name: "own version", cfg: ShadowConfig{FunctionID: "a", TargetVersion: 2, Percentage: 100}},
+		{name: "self without version", cfg: ShadowConfig{FunctionID: "a", Percentage: 10}, wantErr: true},
+		{name: "percentage too high", cfg: ShadowConfig{FunctionID: "a", TargetFunctionID: "b", Percentage: 101}, wantErr: true},
+		{name: "negative version", cfg: ShadowConfig{FunctionID: "a", TargetFunctionID: "b", TargetVersion: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
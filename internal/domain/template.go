@@ -4,6 +4,10 @@ package domain
 
 import (
 	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -216,6 +220,158 @@ func (r *CreateFunctionFromTemplateRequest) Validate() error {
 	return nil
 }
 
+// ==================== 模板导入导出 ====================
+
+// TemplateDocumentKind 是可分享模板文档的类型标识
+const TemplateDocumentKind = "nimbus.template/v1"
+
+// 模板文档限制常量定义
+const (
+	// MaxTemplateDocumentBytes 是从远程目录导入的模板文档的最大字节数
+	MaxTemplateDocumentBytes = 4 << 20
+	// MaxTemplatesPerDocument 是单个模板文档中允许的最大模板数
+	MaxTemplatesPerDocument = 100
+)
+
+// templateVariableNamePattern 模板变量名格式，与代码中的 {{NAME}} 占位符一致
+var templateVariableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,63}$`)
+
+// TemplateDocument 是可分享的模板文档，包含一个或多个模板。
+// 文档不含 ID、时间戳等实例相关字段，可在不同部署之间导入导出。
+type TemplateDocument struct {
+	// Kind 是文档类型标识，固定为 TemplateDocumentKind
+	Kind string `json:"kind"`
+	// Templates 是文档中的模板列表
+	Templates []CreateTemplateRequest `json:"templates"`
+}
+
+// NewTemplateDocument 将模板转换为可分享的模板文档。
+// 热门标记属于本地运营数据，不会导出。
+func NewTemplateDocument(templates ...*Template) *TemplateDocument {
+	doc := &TemplateDocument{Kind: TemplateDocumentKind, Templates: make([]CreateTemplateRequest, 0, len(templates))}
+	for _, t := range templates {
+		doc.Templates = append(doc.Templates, CreateTemplateRequest{
+			Name:           t.Name,
+			DisplayName:    t.DisplayName,
+			Description:    t.Description,
+			Category:       t.Category,
+			Runtime:        t.Runtime,
+			Handler:        t.Handler,
+			Code:           t.Code,
+			Variables:      t.Variables,
+			DefaultMemory:  t.DefaultMemory,
+			DefaultTimeout: t.DefaultTimeout,
+			Tags:           t.Tags,
+			Icon:           t.Icon,
+		})
+	}
+	return doc
+}
+
+// ParseTemplateDocument 解析并校验模板文档。
+// 既接受包含 templates 列表的模板文档，也接受单个模板对象。
+// 每个模板都按创建模板的规则校验（运行时必须受支持），并校验变量定义和默认值；
+// 同一文档中的模板名称不能重复。任一模板无效时返回错误，不返回部分结果。
+//
+// 参数:
+//   - data: 文档内容（JSON）
+//
+// 返回值:
+//   - []CreateTemplateRequest: 校验通过并填充默认值的模板创建请求
+//   - error: 文档格式错误或模板无效时返回错误，可用 errors.Is 判断 ErrInvalidTemplateDocument
+func ParseTemplateDocument(data []byte) ([]CreateTemplateRequest, error) {
+	var probe struct {
+		Kind      string          `json:"kind"`
+		Templates json.RawMessage `json:"templates"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplateDocument, err)
+	}
+
+	var templates []CreateTemplateRequest
+	if probe.Kind != "" || probe.Templates != nil {
+		if probe.Kind != "" && probe.Kind != TemplateDocumentKind {
+			return nil, fmt.Errorf("%w: unsupported kind %q", ErrInvalidTemplateDocument, probe.Kind)
+		}
+		var doc TemplateDocument
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTemplateDocument, err)
+		}
+		templates = doc.Templates
+	} else {
+		var single CreateTemplateRequest
+		if err := json.Unmarshal(data, &single); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTemplateDocument, err)
+		}
+		templates = []CreateTemplateRequest{single}
+	}
+
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("%w: no templates", ErrInvalidTemplateDocument)
+	}
+	if len(templates) > MaxTemplatesPerDocument {
+		return nil, fmt.Errorf("%w: %d templates exceeds limit %d", ErrInvalidTemplateDocument, len(templates), MaxTemplatesPerDocument)
+	}
+
+	seen := make(map[string]bool, len(templates))
+	for i := range templates {
+		t := &templates[i]
+		if err := t.Validate(); err != nil {
+			return nil, fmt.Errorf("template %d (%s): %w", i, t.Name, err)
+		}
+		if err := ValidateTemplateVariables(t.Variables); err != nil {
+			return nil, fmt.Errorf("template %d (%s): %w", i, t.Name, err)
+		}
+		if seen[t.Name] {
+			return nil, fmt.Errorf("%w: duplicate template name %q", ErrInvalidTemplateDocument, t.Name)
+		}
+		seen[t.Name] = true
+		// 热门标记不随导入生效，由本地管理员设置
+		t.Popular = false
+	}
+	return templates, nil
+}
+
+// ValidateTemplateVariables 校验模板变量定义：
+// 变量名必须是合法的占位符名称且不重复，类型必须有效，默认值必须符合变量类型。
+//
+// 参数:
+//   - vars: 模板变量列表
+//
+// 返回值:
+//   - error: 变量无效时返回包装了 ErrInvalidTemplateVariable 的错误
+func ValidateTemplateVariables(vars []TemplateVariable) error {
+	seen := make(map[string]bool, len(vars))
+	for _, v := range vars {
+		if !templateVariableNamePattern.MatchString(v.Name) {
+			return fmt.Errorf("%w: invalid name %q", ErrInvalidTemplateVariable, v.Name)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("%w: duplicate name %q", ErrInvalidTemplateVariable, v.Name)
+		}
+		seen[v.Name] = true
+
+		switch v.Type {
+		case TemplateVariableTypeString:
+		case TemplateVariableTypeNumber:
+			if v.Default != "" {
+				if _, err := strconv.ParseFloat(v.Default, 64); err != nil {
+					return fmt.Errorf("%w: default of %s is not a number: %q", ErrInvalidTemplateVariable, v.Name, v.Default)
+				}
+			}
+		case TemplateVariableTypeBoolean:
+			if v.Default != "" {
+				if _, err := strconv.ParseBool(v.Default); err != nil {
+					return fmt.Errorf("%w: default of %s is not a boolean: %q", ErrInvalidTemplateVariable, v.Name, v.Default)
+				}
+			}
+		default:
+			return fmt.Errorf("%w: unknown type %q for %s", ErrInvalidTemplateVariable, v.Type, v.Name)
+		}
+	}
+	return nil
+}
+
 // TemplateRepository 定义了模板存储的接口
 type TemplateRepository interface {
 	// Create 创建一个新的模板记录
@@ -260,3 +416,100 @@ func (t *Template) UnmarshalVariables(data []byte) error {
 	}
 	return json.Unmarshal(data, &t.Variables)
 }
+
+// ==================== 模板渲染与校验 ====================
+
+// Render 使用给定的变量值替换模板代码中的 {{NAME}} 占位符。
+// 未在 vars 中提供的变量使用其默认值。
+//
+// 参数:
+//   - vars: 变量名到取值的映射，可为 nil
+//
+// 返回值:
+//   - string: 替换后的代码
+func (t *Template) Render(vars map[string]string) string {
+	code := t.Code
+	for _, v := range t.Variables {
+		value := v.Default
+		if val, ok := vars[v.Name]; ok {
+			value = val
+		}
+		code = strings.ReplaceAll(code, "{{"+v.Name+"}}", value)
+	}
+	return code
+}
+
+// SampleVariables 返回用于试编译模板的变量取值。
+// 有默认值的变量使用默认值；必填且没有默认值的变量按类型填入示例值，
+// 保证替换后的代码在语法上完整。
+func (t *Template) SampleVariables() map[string]string {
+	vars := make(map[string]string, len(t.Variables))
+	for _, v := range t.Variables {
+		if v.Default != "" || !v.Required {
+			vars[v.Name] = v.Default
+			continue
+		}
+		switch v.Type {
+		case TemplateVariableTypeNumber:
+			vars[v.Name] = "1"
+		case TemplateVariableTypeBoolean:
+			vars[v.Name] = "true"
+		default:
+			vars[v.Name] = "sample"
+		}
+	}
+	return vars
+}
+
+// 模板校验失败阶段常量定义
+const (
+	// TemplateValidationStageConfig 表示模板的函数配置校验失败（运行时、入口点、代码大小等）
+	TemplateValidationStageConfig = "config"
+	// TemplateValidationStageCompile 表示模板代码编译失败
+	TemplateValidationStageCompile = "compile"
+)
+
+// TemplateValidationResult 表示单个模板的试编译结果
+type TemplateValidationResult struct {
+	// TemplateID 是模板 ID
+	TemplateID string `json:"template_id"`
+	// Name 是模板名称
+	Name string `json:"name"`
+	// Runtime 是模板的运行时
+	Runtime Runtime `json:"runtime"`
+	// OK 表示模板当前能否成功创建函数
+	OK bool `json:"ok"`
+	// Compiled 表示是否实际执行了编译（仅编译型运行时）
+	Compiled bool `json:"compiled"`
+	// Stage 是失败所在的阶段，成功时为空
+	Stage string `json:"stage,omitempty"`
+	// Error 是失败原因
+	Error string `json:"error,omitempty"`
+	// Output 是编译输出，仅编译失败时返回
+	Output string `json:"output,omitempty"`
+	// DurationMs 是校验耗时（毫秒）
+	DurationMs int64 `json:"duration_ms"`
+}
+
+// TemplateValidationReport 表示一次批量模板校验的汇总结果
+type TemplateValidationReport struct {
+	// Total 是校验的模板总数
+	Total int `json:"total"`
+	// Passed 是校验通过的模板数
+	Passed int `json:"passed"`
+	// Failed 是校验失败的模板数
+	Failed int `json:"failed"`
+	// Results 是每个模板的校验结果
+	Results []TemplateValidationResult `json:"results"`
+}
+
+// Add 将单个模板的校验结果计入汇总。
+func (r *TemplateValidationReport) Add(result TemplateValidationResult) {
+	r.Total++
+	if result.OK {
+		r.Passed++
+	} else {
+		r.Failed++
+	}
+	r.Results = append(r.Results, result)
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTemplateRender(t *testing.T) {
+	tpl := &Template{
+		Code: `table={{TABLE}} limit={{LIMIT}} debug={{DEBUG}} name={{NAME}}`,
+		Variables: []TemplateVariable{
+			{Name: "TABLE", Type: TemplateVariableTypeString, Required: true},
+			{Name: "LIMIT", Type: TemplateVariableTypeNumber, Required: true},
+			{Name: "DEBUG", Type: TemplateVariableTypeBoolean, Default: "false"},
+			{Name: "NAME", Type: TemplateVariableTypeString},
+		},
+	}
+
+	if got, want := tpl.Render(map[string]string{"TABLE": "users", "LIMIT": "10"}), "table=users limit=10 debug=false name="; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	if got, want := tpl.Render(tpl.SampleVariables()), "table=sample limit=1 debug=false name="; got != want {
+		t.Errorf("Render(SampleVariables()) = %q, want %q", got, want)
+	}
+
+	var report TemplateValidationReport
+	report.Add(TemplateValidationResult{OK: true})
+	report.Add(TemplateValidationResult{Stage: TemplateValidationStageCompile})
+	if report.Total != 2 || report.Passed != 1 || report.Failed != 1 {
+		t.Errorf("report = %+v, want total=2 passed=1 failed=1", report)
+	}
+}
+
+func TestParseTemplateDocument(t *testing.T) {
+	tpl := &Template{
+		ID:          "t1",
+		Name:        "hello",
+		DisplayName: "Hello",
+		Category:    TemplateCategoryStarter,
+		Runtime:     RuntimePython311,
+		Handler:     "handler.main",
+		Code:        "print({{GREETING}})",
+		Variables:   []TemplateVariable{{Name: "GREETING", Type: TemplateVariableTypeString, Default: "hi"}},
+		Popular:     true,
+	}
+	data, err := json.Marshal(NewTemplateDocument(tpl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"id"`) || strings.Contains(string(data), "popular") {
+		t.Errorf("exported document should not contain instance fields: %s", data)
+	}
+
+	// 导出的文档可以原样导入，缺省的内存和超时被填充
+	templates, err := ParseTemplateDocument(data)
+	if err != nil {
+		t.Fatalf("round trip: %v", err)
+	}
+	if len(templates) != 1 || templates[0].Name != "hello" || templates[0].DefaultMemory != 256 {
+		t.Errorf("round trip = %+v", templates)
+	}
+
+	// 单个模板对象也可以导入
+	single := `{"name":"one","display_name":"One","category":"starter","runtime":"python3.11","handler":"h.main","code":"x"}`
+	if templates, err := ParseTemplateDocument([]byte(single)); err != nil || len(templates) != 1 {
+		t.Errorf("single template: %v %v", templates, err)
+	}
+
+	bad := map[string]string{
+		"not json":          `{`,
+		"unknown kind":      `{"kind":"other/v1","templates":[]}`,
+		"empty":             `{"kind":"nimbus.template/v1","templates":[]}`,
+		"bad runtime":       `{"name":"a","display_name":"A","category":"starter","runtime":"cobol","handler":"h","code":"x"}`,
+		"bad number":        `{"name":"a","display_name":"A","category":"starter","runtime":"python3.11","handler":"h","code":"x","variables":[{"name":"N","type":"number","default":"ten"}]}`,
+		"bad boolean":       `{"name":"a","display_name":"A","category":"starter","runtime":"python3.11","handler":"h","code":"x","variables":[{"name":"B","type":"boolean","default":"maybe"}]}`,
+		"bad variable name": `{"name":"a","display_name":"A","category":"starter","runtime":"python3.11","handler":"h","code":"x","variables":[{"name":"a-b","type":"string"}]}`,
+		"duplicate names":   `{"templates":[` + single + `,` + single + `]}`,
+	}
+	for name, doc := range bad {
+		if _, err := ParseTemplateDocument([]byte(doc)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
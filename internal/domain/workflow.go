@@ -68,6 +68,8 @@ const (
 	StateTypeWait StateType = "Wait"
 	// StateTypeParallel 并行状态，并行执行多个分支
 	StateTypeParallel StateType = "Parallel"
+	// StateTypeMap 映射状态，对输入数组的每个元素执行迭代器子流程
+	StateTypeMap StateType = "Map"
 	// StateTypePass 透传状态，透传输入到输出
 	StateTypePass StateType = "Pass"
 	// StateTypeFail 失败状态，以失败终止执行
@@ -106,6 +108,9 @@ type WorkflowDefinition struct {
 	StartAt string `json:"start_at"`
 	// States 状态定义映射
 	States map[string]State `json:"states"`
+	// ColocateTasks 为 true 时同一次执行的任务状态优先复用同一个预热实例，
+	// 减少循环或连续调用同一函数时反复获取和归还实例的开销（仅 Docker 模式）
+	ColocateTasks bool `json:"colocate_tasks,omitempty"`
 }
 
 // State 单个状态的定义
@@ -146,6 +151,14 @@ type State struct {
 	// Branches 并行分支列表
 	Branches []Branch `json:"branches,omitempty"`
 
+	// ===== Map 状态字段 =====
+	// ItemsPath 待迭代数组的路径（JSONPath），默认为 "$"（整个输入）
+	ItemsPath string `json:"items_path,omitempty"`
+	// Iterator 对每个元素执行的子流程
+	Iterator *Branch `json:"iterator,omitempty"`
+	// MaxConcurrency 同时处理的元素数量上限，0 表示不限制
+	MaxConcurrency int `json:"max_concurrency,omitempty"`
+
 	// ===== Pass/Fail 状态字段 =====
 	// Result 传递的结果值
 	Result json.RawMessage `json:"result,omitempty"`
@@ -245,7 +258,7 @@ type ChoiceRule struct {
 	Not *ChoiceRule `json:"not,omitempty"`
 }
 
-// Branch 并行分支定义
+// Branch 子流程定义，用作 Parallel 状态的分支或 Map 状态的迭代器
 type Branch struct {
 	// StartAt 分支起始状态
 	StartAt string `json:"start_at"`
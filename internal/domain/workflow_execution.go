@@ -118,9 +118,9 @@ type StateResult struct {
 	CaughtByState string
 }
 
-// ParallelBranchResult 并行分支执行结果
+// ParallelBranchResult 并行分支（或 Map 状态单个元素）的执行结果
 type ParallelBranchResult struct {
-	// BranchIndex 分支索引
+	// BranchIndex 分支索引（Map 状态中为元素索引）
 	BranchIndex int
 	// Output 分支输出
 	Output json.RawMessage
@@ -209,6 +209,8 @@ const (
 	ErrorTypeParameterPathFailure = "States.ParameterPathFailure"
 	// ErrorTypeBranchFailed 分支失败
 	ErrorTypeBranchFailed = "States.BranchFailed"
+	// ErrorTypeItemsPathFailure Map 状态的 ItemsPath 未指向数组
+	ErrorTypeItemsPathFailure = "States.ItemsPathFailure"
 	// ErrorTypeNoChoiceMatched 没有匹配的 Choice 条件
 	ErrorTypeNoChoiceMatched = "States.NoChoiceMatched"
 	// ErrorTypeIntrinsicFailure 内置函数失败
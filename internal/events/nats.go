@@ -226,6 +226,7 @@ func (tm *TriggerManager) RegisterTrigger(ctx context.Context, trigger *Trigger)
 				FunctionID: trigger.FunctionID,
 				Payload:    event.Data,
 				Async:      true,
+				Trigger:    domain.TriggerEvent,
 			})
 			return err
 		})
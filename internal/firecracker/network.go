@@ -45,6 +45,11 @@ type NetworkManager struct {
 	subnetLast  uint32     // 最后一个可用主机地址（含）
 	nextIP      uint32     // 下一次尝试分配的 IP（uint32）
 	netmask     string     // 子网掩码（点分十进制）
+
+	health     networkHealth   // 最近一次健康检查的结果
+	suspectTap map[string]bool // 上一次检查发现的疑似孤立 TAP 设备，连续两次发现才回收
+	stopHealth chan struct{}   // 关闭时停止健康检查协程
+	healthDone chan struct{}   // 健康检查协程退出信号
 }
 
 // NewNetworkManager 创建新的网络管理器。
@@ -73,6 +78,7 @@ func NewNetworkManager(cfg config.NetworkConfig, logger *logrus.Logger) (*Networ
 		usedIPs:     make(map[string]bool),
 		ipByVMID:    make(map[string]string),
 		tapDevices:  make(map[string]string),
+		suspectTap:  make(map[string]bool),
 		subnet:      subnet,
 		subnetFirst: first,
 		subnetLast:  last,
@@ -84,6 +90,7 @@ func NewNetworkManager(cfg config.NetworkConfig, logger *logrus.Logger) (*Networ
 	if err := nm.setupBridge(); err != nil {
 		return nil, fmt.Errorf("failed to setup bridge: %w", err)
 	}
+	nm.health.bridgeHealthy = true
 
 	return nm, nil
 }
@@ -303,6 +310,8 @@ func (nm *NetworkManager) generateMAC() string {
 // Shutdown 关闭网络管理器并清理所有资源。
 // 删除所有 TAP 设备。
 func (nm *NetworkManager) Shutdown() error {
+	nm.stopHealthCheck()
+
 	nm.mu.Lock()
 	defer nm.mu.Unlock()
 
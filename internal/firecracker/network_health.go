@@ -0,0 +1,241 @@
+//go:build linux
+// +build linux
+
+package firecracker
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// sysClassNet 是内核暴露网络接口属性的 sysfs 目录，变量形式便于测试替换
+var sysClassNet = "/sys/class/net"
+
+// 以下变量封装健康检查访问的系统网络接口和 ip 命令，变量形式便于测试替换
+var (
+	listInterfaces  = net.Interfaces
+	interfaceByName = net.InterfaceByName
+	ipCommand       = func(args ...string) error {
+		return exec.Command("ip", args...).Run()
+	}
+)
+
+// NetworkStats 表示网络管理器的资源使用情况和健康状态。
+type NetworkStats struct {
+	Bridge                string     `json:"bridge"`                  // 网桥名称
+	BridgeHealthy         bool       `json:"bridge_healthy"`          // 网桥是否存在且已启用
+	TotalIPs              int        `json:"total_ips"`               // 子网中可分配给虚拟机的 IP 总数（不含网关）
+	AllocatedIPs          int        `json:"allocated_ips"`           // 已分配的 IP 数量
+	AvailableIPs          int        `json:"available_ips"`           // 剩余可分配的 IP 数量
+	TapDevices            int        `json:"tap_devices"`             // 当前管理的 TAP 设备数量
+	OrphanedTapsReclaimed int64      `json:"orphaned_taps_reclaimed"` // 累计回收的孤立 TAP 设备数量
+	BridgeRecoveries      int64      `json:"bridge_recoveries"`       // 累计重建或重新启用网桥的次数
+	LastCheckAt           *time.Time `json:"last_check_at,omitempty"` // 最近一次健康检查时间
+	LastError             string     `json:"last_error,omitempty"`    // 最近一次健康检查的错误
+}
+
+// networkHealth 记录健康检查的累计结果，由 nm.mu 保护。
+type networkHealth struct {
+	bridgeHealthy bool
+	reclaimed     int64
+	recoveries    int64
+	lastCheck     time.Time
+	lastErr       string
+}
+
+// StartHealthCheck 启动后台网络健康检查，每个周期执行一次 CheckHealth。
+// Shutdown 时自动停止。重复调用无效。
+//
+// 参数:
+//   - interval: 检查间隔，<= 0 时不启动
+func (nm *NetworkManager) StartHealthCheck(interval time.Duration) {
+	if interval <= 0 || nm.stopHealth != nil {
+		return
+	}
+	nm.stopHealth = make(chan struct{})
+	nm.healthDone = make(chan struct{})
+
+	go func() {
+		defer close(nm.healthDone)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		nm.CheckHealth()
+		for {
+			select {
+			case <-nm.stopHealth:
+				return
+			case <-ticker.C:
+				nm.CheckHealth()
+			}
+		}
+	}()
+
+	nm.logger.WithField("interval", interval).Info("Network health check started")
+}
+
+// stopHealthCheck 停止后台健康检查并等待当前检查结束。
+func (nm *NetworkManager) stopHealthCheck() {
+	if nm.stopHealth == nil {
+		return
+	}
+	close(nm.stopHealth)
+	<-nm.healthDone
+	nm.stopHealth = nil
+}
+
+// CheckHealth 执行一次网络健康检查：
+//   - 网桥不存在时重新创建，并把已管理的 TAP 设备重新挂到网桥上；网桥未启用时重新启用
+//   - 回收挂在网桥上、不属于任何虚拟机且没有进程打开的 TAP 设备（通常由崩溃的虚拟机或进程遗留）
+//
+// 疑似孤立的 TAP 设备需要连续两次检查都被发现才会删除，避免误删其他进程刚创建、尚未被 Firecracker 打开的设备。
+//
+// 返回值:
+//   - error: 网桥无法恢复时返回错误，孤立设备删除失败只记录日志
+func (nm *NetworkManager) CheckHealth() error {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	err := nm.checkBridgeLocked()
+	nm.health.bridgeHealthy = err == nil
+	nm.health.lastCheck = time.Now()
+	nm.health.lastErr = ""
+	if err != nil {
+		nm.health.lastErr = err.Error()
+		nm.logger.WithError(err).WithField("bridge", nm.cfg.BridgeName).Error("Network bridge is unhealthy")
+		return err
+	}
+
+	nm.reclaimOrphanedTapsLocked()
+	return nil
+}
+
+// checkBridgeLocked 检查网桥是否存在且已启用，必要时重建或重新启用。
+// 必须在 nm.mu 持有状态下调用。
+func (nm *NetworkManager) checkBridgeLocked() error {
+	iface, err := interfaceByName(nm.cfg.BridgeName)
+	if err != nil {
+		nm.logger.WithField("bridge", nm.cfg.BridgeName).Warn("Network bridge missing, recreating")
+		if err := nm.setupBridge(); err != nil {
+			return fmt.Errorf("bridge %s missing and could not be recreated: %w", nm.cfg.BridgeName, err)
+		}
+		nm.health.recoveries++
+
+		// 删除网桥会把 TAP 设备从网桥上摘下，重新挂回去使运行中的虚拟机恢复连通
+		for vmID, tapName := range nm.tapDevices {
+			if err := ipCommand("link", "set", tapName, "master", nm.cfg.BridgeName); err != nil {
+				nm.logger.WithError(err).WithFields(logrus.Fields{
+					"vm_id": vmID,
+					"tap":   tapName,
+				}).Warn("Failed to reattach tap device to bridge")
+			}
+		}
+		return nil
+	}
+
+	if iface.Flags&net.FlagUp == 0 {
+		nm.logger.WithField("bridge", nm.cfg.BridgeName).Warn("Network bridge is down, bringing it up")
+		if err := ipCommand("link", "set", nm.cfg.BridgeName, "up"); err != nil {
+			return fmt.Errorf("bridge %s is down and could not be brought up: %w", nm.cfg.BridgeName, err)
+		}
+		nm.health.recoveries++
+	}
+	return nil
+}
+
+// reclaimOrphanedTapsLocked 删除连续两次检查都被判定为孤立的 TAP 设备。
+// 必须在 nm.mu 持有状态下调用。
+func (nm *NetworkManager) reclaimOrphanedTapsLocked() {
+	ifaces, err := listInterfaces()
+	if err != nil {
+		nm.logger.WithError(err).Warn("Failed to list network interfaces")
+		return
+	}
+
+	managed := make(map[string]bool, len(nm.tapDevices))
+	for _, tapName := range nm.tapDevices {
+		managed[tapName] = true
+	}
+
+	suspects := make(map[string]bool)
+	for _, iface := range ifaces {
+		name := iface.Name
+		if !strings.HasPrefix(name, "tap") || managed[name] {
+			continue
+		}
+		if tapMaster(name) != nm.cfg.BridgeName || tapInUse(name) {
+			continue
+		}
+		if !nm.suspectTap[name] {
+			suspects[name] = true
+			continue
+		}
+
+		if err := ipCommand("link", "del", name); err != nil {
+			nm.logger.WithError(err).WithField("tap", name).Warn("Failed to delete orphaned tap device")
+			suspects[name] = true
+			continue
+		}
+		nm.health.reclaimed++
+		nm.logger.WithField("tap", name).Info("Reclaimed orphaned tap device")
+	}
+	nm.suspectTap = suspects
+}
+
+// tapMaster 返回网络接口所挂载的网桥名称，未挂载时返回空字符串。
+func tapMaster(name string) string {
+	target, err := os.Readlink(filepath.Join(sysClassNet, name, "master"))
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(target)
+}
+
+// tapInUse 判断 TAP 设备是否被进程打开。
+// 没有进程持有 TAP 文件描述符时内核报告无载波（carrier 为 0 或不可读）。
+func tapInUse(name string) bool {
+	data, err := os.ReadFile(filepath.Join(sysClassNet, name, "carrier"))
+	return err == nil && strings.TrimSpace(string(data)) == "1"
+}
+
+// Healthy 返回最近一次检查时网桥是否正常。
+func (nm *NetworkManager) Healthy() bool {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	return nm.health.bridgeHealthy
+}
+
+// Stats 返回网络资源的使用情况和最近一次健康检查的结果。
+func (nm *NetworkManager) Stats() NetworkStats {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	total := int(nm.subnetLast - nm.subnetFirst + 1)
+	if gateway, err := parseIPv4ToUint32(nm.cfg.BridgeIP); err == nil && gateway >= nm.subnetFirst && gateway <= nm.subnetLast {
+		total--
+	}
+
+	stats := NetworkStats{
+		Bridge:                nm.cfg.BridgeName,
+		BridgeHealthy:         nm.health.bridgeHealthy,
+		TotalIPs:              total,
+		AllocatedIPs:          len(nm.usedIPs),
+		AvailableIPs:          total - len(nm.usedIPs),
+		TapDevices:            len(nm.tapDevices),
+		OrphanedTapsReclaimed: nm.health.reclaimed,
+		BridgeRecoveries:      nm.health.recoveries,
+		LastError:             nm.health.lastErr,
+	}
+	if !nm.health.lastCheck.IsZero() {
+		lastCheck := nm.health.lastCheck
+		stats.LastCheckAt = &lastCheck
+	}
+	return stats
+}
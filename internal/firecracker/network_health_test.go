@@ -0,0 +1,167 @@
+//go:build linux
+// +build linux
+
+package firecracker
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/config"
+)
+
+// fakeNetwork 替换健康检查访问的系统网络接口、sysfs 和 ip 命令
+type fakeNetwork struct {
+	ifaces   []net.Interface
+	commands []string
+}
+
+func newFakeNetwork(t *testing.T) *fakeNetwork {
+	t.Helper()
+	f := &fakeNetwork{}
+	origList, origByName, origIP, origSys := listInterfaces, interfaceByName, ipCommand, sysClassNet
+	t.Cleanup(func() {
+		listInterfaces, interfaceByName, ipCommand, sysClassNet = origList, origByName, origIP, origSys
+	})
+
+	sysClassNet = t.TempDir()
+	listInterfaces = func() ([]net.Interface, error) { return f.ifaces, nil }
+	interfaceByName = func(name string) (*net.Interface, error) {
+		for i := range f.ifaces {
+			if f.ifaces[i].Name == name {
+				return &f.ifaces[i], nil
+			}
+		}
+		return nil, errors.New("no such network interface")
+	}
+	ipCommand = func(args ...string) error {
+		f.commands = append(f.commands, strings.Join(args, " "))
+		return nil
+	}
+	return f
+}
+
+// addTap 登记一个 TAP 设备：master 为所挂载的网桥（为空表示未挂载），carrier 表示是否有进程打开
+func (f *fakeNetwork) addTap(t *testing.T, name, master string, carrier bool) {
+	t.Helper()
+	f.ifaces = append(f.ifaces, net.Interface{Name: name, Flags: net.FlagUp})
+	dir := filepath.Join(sysClassNet, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if master != "" {
+		if err := os.Symlink(filepath.Join("..", master), filepath.Join(dir, "master")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	value := "0"
+	if carrier {
+		value = "1"
+	}
+	if err := os.WriteFile(filepath.Join(dir, "carrier"), []byte(value+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestNetworkManager(t *testing.T) *NetworkManager {
+	t.Helper()
+	subnet, first, last, netmask, err := parseIPv4Subnet("172.16.0.0/24")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &NetworkManager{
+		cfg:         config.NetworkConfig{BridgeName: "fcbr0", BridgeIP: "172.16.0.1", SubnetCIDR: "172.16.0.0/24"},
+		logger:      logrus.New(),
+		usedIPs:     make(map[string]bool),
+		ipByVMID:    make(map[string]string),
+		tapDevices:  make(map[string]string),
+		suspectTap:  make(map[string]bool),
+		subnet:      subnet,
+		subnetFirst: first,
+		subnetLast:  last,
+		nextIP:      first,
+		netmask:     netmask,
+	}
+}
+
+func TestCheckHealthReclaimsOrphanedTaps(t *testing.T) {
+	f := newFakeNetwork(t)
+	f.ifaces = append(f.ifaces, net.Interface{Name: "fcbr0", Flags: net.FlagUp}, net.Interface{Name: "eth0", Flags: net.FlagUp})
+	f.addTap(t, "tap-managed", "fcbr0", false)
+	f.addTap(t, "tap-orphan", "fcbr0", false)
+	f.addTap(t, "tap-busy", "fcbr0", true)
+	f.addTap(t, "tap-other", "docker0", false)
+
+	nm := newTestNetworkManager(t)
+	nm.tapDevices["vm-1"] = "tap-managed"
+
+	// 第一次检查只登记疑似孤立的设备，不删除
+	if err := nm.CheckHealth(); err != nil {
+		t.Fatalf("CheckHealth() error = %v", err)
+	}
+	if len(f.commands) != 0 {
+		t.Errorf("first check commands = %v, want none", f.commands)
+	}
+	if len(nm.suspectTap) != 1 || !nm.suspectTap["tap-orphan"] {
+		t.Errorf("suspects = %v, want only tap-orphan", nm.suspectTap)
+	}
+
+	// 连续第二次发现时删除
+	if err := nm.CheckHealth(); err != nil {
+		t.Fatalf("CheckHealth() error = %v", err)
+	}
+	if len(f.commands) != 1 || f.commands[0] != "link del tap-orphan" {
+		t.Errorf("second check commands = %v, want link del tap-orphan", f.commands)
+	}
+	stats := nm.Stats()
+	if stats.OrphanedTapsReclaimed != 1 || !stats.BridgeHealthy || stats.LastCheckAt == nil {
+		t.Errorf("stats = %+v, want 1 reclaimed and healthy bridge", stats)
+	}
+}
+
+func TestCheckHealthBringsBridgeUp(t *testing.T) {
+	f := newFakeNetwork(t)
+	f.ifaces = append(f.ifaces, net.Interface{Name: "fcbr0"})
+
+	nm := newTestNetworkManager(t)
+	if err := nm.CheckHealth(); err != nil {
+		t.Fatalf("CheckHealth() error = %v", err)
+	}
+	if len(f.commands) != 1 || f.commands[0] != "link set fcbr0 up" {
+		t.Errorf("commands = %v, want link set fcbr0 up", f.commands)
+	}
+	if stats := nm.Stats(); stats.BridgeRecoveries != 1 || !stats.BridgeHealthy {
+		t.Errorf("stats = %+v, want 1 recovery and healthy bridge", stats)
+	}
+
+	// 无法启用网桥时报告不健康
+	ipCommand = func(args ...string) error { return errors.New("operation not permitted") }
+	if err := nm.CheckHealth(); err == nil {
+		t.Fatal("CheckHealth() error = nil, want bridge failure")
+	}
+	if stats := nm.Stats(); stats.BridgeHealthy || !strings.Contains(stats.LastError, "could not be brought up") {
+		t.Errorf("stats = %+v, want unhealthy bridge with error", stats)
+	}
+}
+
+func TestNetworkStatsUtilization(t *testing.T) {
+	nm := newTestNetworkManager(t)
+	nm.usedIPs["172.16.0.2"] = true
+	nm.usedIPs["172.16.0.3"] = true
+	nm.tapDevices["vm-1"] = "tap0"
+
+	// /24 子网有 254 个主机地址，除去网关后可分配 253 个
+	stats := nm.Stats()
+	if stats.TotalIPs != 253 || stats.AllocatedIPs != 2 || stats.AvailableIPs != 251 || stats.TapDevices != 1 {
+		t.Errorf("stats = %+v, want 253 total, 2 allocated, 251 available, 1 tap", stats)
+	}
+	if stats.LastCheckAt != nil {
+		t.Errorf("last check = %v, want nil before any check", stats.LastCheckAt)
+	}
+}
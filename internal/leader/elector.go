@@ -0,0 +1,150 @@
+// Package leader 提供基于 Redis 租约的领导者选举，
+// 保证多副本部署时单例后台任务（延迟汇总、定时触发、工作流恢复等）同一时刻只在一个实例上运行。
+// 领导者按租约时长的三分之一周期续约，续约失败或实例退出后租约过期，其他实例接管任务。
+package leader
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultLeaseTTL 是默认的租约时长
+const DefaultLeaseTTL = 15 * time.Second
+
+// releaseTimeout 退出时释放租约的超时时间
+const releaseTimeout = 2 * time.Second
+
+// LeaseStore 定义领导者租约的存储接口，由 storage.RedisStore 实现。
+type LeaseStore interface {
+	// AcquireLeadership 获取或续约租约，返回是否持有租约
+	AcquireLeadership(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	// ReleaseLeadership 释放租约，租约已不属于该持有者时不做任何事
+	ReleaseLeadership(ctx context.Context, name, holder string) error
+}
+
+// Elector 为一个单例后台任务竞选领导者。
+type Elector struct {
+	store  LeaseStore
+	name   string
+	id     string
+	ttl    time.Duration
+	logger *logrus.Entry
+
+	leader atomic.Bool
+}
+
+// NewElector 创建领导者选举器。
+//
+// 参数:
+//   - store: 租约存储，为 nil 时视为单实例部署，本实例始终是领导者
+//   - name: 后台任务名称，同名任务在所有实例间竞选同一个租约
+//   - ttl: 租约时长，<= 0 时使用 DefaultLeaseTTL
+//   - logger: 日志记录器
+func NewElector(store LeaseStore, name string, ttl time.Duration, logger *logrus.Logger) *Elector {
+	if ttl <= 0 {
+		ttl = DefaultLeaseTTL
+	}
+	return &Elector{
+		store:  store,
+		name:   name,
+		id:     instanceID(),
+		ttl:    ttl,
+		logger: logger.WithField("leader_job", name),
+	}
+}
+
+// instanceID 生成本实例的持有者标识：主机名、进程号和随机后缀，避免同一主机上的多个进程冲突。
+func instanceID() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// IsLeader 判断本实例当前是否持有租约。
+func (e *Elector) IsLeader() bool {
+	return e.store == nil || e.leader.Load()
+}
+
+// Campaign 只竞选并维持租约，不运行任务，阻塞直到 ctx 取消。
+// 用于已有自己的后台循环的组件：循环中通过 IsLeader 判断是否执行。
+func (e *Elector) Campaign(ctx context.Context) {
+	e.Run(ctx, func(ctx context.Context) { <-ctx.Done() })
+}
+
+// Run 竞选领导者并在持有租约期间运行 job，阻塞直到 ctx 取消。
+// 成为领导者时以新的子上下文启动 job，失去租约（续约失败或被其他实例接管）时取消子上下文并等待 job 返回，
+// 之后继续竞选。ctx 取消时停止 job 并释放租约，其他实例无需等待租约过期即可接管。
+//
+// 参数:
+//   - ctx: 控制竞选生命周期的上下文
+//   - job: 单例任务，应在其上下文取消后尽快返回
+func (e *Elector) Run(ctx context.Context, job func(ctx context.Context)) {
+	if e.store == nil {
+		job(ctx)
+		return
+	}
+
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	var (
+		jobCancel context.CancelFunc
+		jobDone   sync.WaitGroup
+	)
+	stopJob := func() {
+		if jobCancel != nil {
+			jobCancel()
+			jobDone.Wait()
+			jobCancel = nil
+		}
+	}
+	defer func() {
+		stopJob()
+		if e.leader.Swap(false) {
+			releaseCtx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+			defer cancel()
+			if err := e.store.ReleaseLeadership(releaseCtx, e.name, e.id); err != nil {
+				e.logger.WithError(err).Warn("Failed to release leadership")
+			}
+		}
+	}()
+
+	for {
+		held, err := e.store.AcquireLeadership(ctx, e.name, e.id, e.ttl)
+		if err != nil && ctx.Err() == nil {
+			e.logger.WithError(err).Warn("Failed to acquire or renew leadership")
+		}
+		switch {
+		case held && !e.leader.Load():
+			e.leader.Store(true)
+			e.logger.WithField("instance", e.id).Info("Acquired leadership, starting job")
+			jobCtx, cancel := context.WithCancel(ctx)
+			jobCancel = cancel
+			jobDone.Add(1)
+			go func() {
+				defer jobDone.Done()
+				job(jobCtx)
+			}()
+		case !held && e.leader.Load():
+			// 续约失败时租约可能仍未过期，但无法确认，立即停止任务以免与新的领导者同时运行
+			e.leader.Store(false)
+			e.logger.WithField("instance", e.id).Warn("Lost leadership, stopping job")
+			stopJob()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fakeLeaseStore 是内存中的租约存储，fail 为 true 时所有操作返回错误
+type fakeLeaseStore struct {
+	mu     sync.Mutex
+	holder string
+	fail   bool
+}
+
+func (s *fakeLeaseStore) AcquireLeadership(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return false, errors.New("redis unavailable")
+	}
+	if s.holder == "" {
+		s.holder = holder
+	}
+	return s.holder == holder, nil
+}
+
+func (s *fakeLeaseStore) ReleaseLeadership(ctx context.Context, name, holder string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.holder == holder {
+		s.holder = ""
+	}
+	return nil
+}
+
+func (s *fakeLeaseStore) setFail(fail bool) {
+	s.mu.Lock()
+	s.fail = fail
+	s.mu.Unlock()
+}
+
+func waitFor(t *testing.T, cond func() bool, msg string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestElectorSingleLeader(t *testing.T) {
+	store := &fakeLeaseStore{}
+	logger := logrus.New()
+	var running atomic.Int32
+	job := func(ctx context.Context) {
+		running.Add(1)
+		<-ctx.Done()
+		running.Add(-1)
+	}
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	a := NewElector(store, "job", 30*time.Millisecond, logger)
+	doneA := make(chan struct{})
+	go func() { a.Run(ctx1, job); close(doneA) }()
+	waitFor(t, a.IsLeader, "first instance did not become leader")
+
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	b := NewElector(store, "job", 30*time.Millisecond, logger)
+	go b.Run(ctx2, job)
+	time.Sleep(50 * time.Millisecond)
+	if b.IsLeader() || running.Load() != 1 {
+		t.Fatalf("second instance leader=%v running=%d, want false 1", b.IsLeader(), running.Load())
+	}
+
+	// 领导者退出时释放租约，另一个实例接管
+	cancel1()
+	<-doneA
+	waitFor(t, b.IsLeader, "second instance did not take over after release")
+	waitFor(t, func() bool { return running.Load() == 1 }, "job did not restart on new leader")
+}
+
+func TestElectorStepsDownOnRenewFailure(t *testing.T) {
+	store := &fakeLeaseStore{}
+	var running atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	e := NewElector(store, "job", 30*time.Millisecond, logrus.New())
+	go e.Run(ctx, func(ctx context.Context) {
+		running.Add(1)
+		<-ctx.Done()
+		running.Add(-1)
+	})
+	waitFor(t, func() bool { return running.Load() == 1 }, "job did not start")
+
+	store.setFail(true)
+	waitFor(t, func() bool { return !e.IsLeader() && running.Load() == 0 }, "job kept running after renew failure")
+
+	store.setFail(false)
+	waitFor(t, func() bool { return running.Load() == 1 }, "job did not restart after store recovered")
+}
+
+func TestElectorWithoutStore(t *testing.T) {
+	e := NewElector(nil, "job", 0, logrus.New())
+	if !e.IsLeader() {
+		t.Fatal("elector without store should always be leader")
+	}
+	ran := false
+	e.Run(context.Background(), func(ctx context.Context) { ran = true })
+	if !ran {
+		t.Fatal("job did not run without store")
+	}
+}
@@ -61,6 +61,28 @@ type Metrics struct {
 	// 标签: runtime
 	VMRestoreDuration *prometheus.HistogramVec
 
+	// VMPoolUtilization 运行时实例数占其容量上限的比例（0-1）
+	// 标签: runtime
+	VMPoolUtilization *prometheus.GaugeVec
+
+	// VMPoolPinnedWarm 由函数 keep_warm 配置固定常驻的热实例目标数
+	// 标签: runtime
+	VMPoolPinnedWarm *prometheus.GaugeVec
+
+	// VMPoolIdleReaped 因空闲超时被回收的预热实例数
+	// 标签: runtime
+	VMPoolIdleReaped *prometheus.CounterVec
+
+	// ContainerRecycled 被销毁而未放回池中的预热容器数
+	// 标签: runtime, reason（hung/crashed/max_reuse/max_invocations/max_age/pool_full）
+	ContainerRecycled *prometheus.CounterVec
+
+	// LayerCacheBytes 层解压缓存目录当前占用的字节数
+	LayerCacheBytes prometheus.Gauge
+
+	// LayerCacheEvictions 因超出容量上限被淘汰的已解压层数
+	LayerCacheEvictions prometheus.Counter
+
 	// ========== 函数相关指标 ==========
 
 	// FunctionsTotal 注册的函数总数
@@ -74,9 +96,44 @@ type Metrics struct {
 	// SchedulerQueueSize 调度器等待队列中的任务数
 	SchedulerQueueSize prometheus.Gauge
 
-	// SchedulerWorkers 调度器工作线程数量
+	// SchedulerQueueDepth 调度器等待队列中各触发来源的任务数
+	// 标签: source（http/webhook/cron/event）
+	SchedulerQueueDepth *prometheus.GaugeVec
+
+	// SchedulerWorkers 调度器工作线程数量（当前配置值）
 	SchedulerWorkers prometheus.Gauge
 
+	// SchedulerActiveWorkers 正在处理任务的调度器工作线程数量
+	SchedulerActiveWorkers prometheus.Gauge
+
+	// SchedulerPlatformRetries 调用因瞬时平台故障触发的重试次数
+	// 标签: runtime, result（retried/budget_exhausted）
+	SchedulerPlatformRetries *prometheus.CounterVec
+
+	// SchedulerRecursionRejections 因超出调用链深度或同一函数重复次数被拒绝的递归调用数
+	// 标签: function_name, reason（depth/cycle）
+	SchedulerRecursionRejections *prometheus.CounterVec
+
+	// SchedulerKillSwitchRejections 因紧急停止开关被拒绝的调用数
+	// 标签: scope（global/function）
+	SchedulerKillSwitchRejections *prometheus.CounterVec
+
+	// SchedulerConcurrencyThrottles 因超出函数最大并发数被限流的调用数
+	// 标签: function_name, result（rejected/delayed）
+	SchedulerConcurrencyThrottles *prometheus.CounterVec
+
+	// SchedulerRuntimeLoad 各运行时排队和执行中的调用数，可作为外部自动扩缩容的指标
+	// 标签: runtime, state（queued/in_flight）
+	SchedulerRuntimeLoad *prometheus.GaugeVec
+
+	// SchedulerShadowRelays 影子流量回放次数
+	// 标签: function_name, result（success/failure/timeout/dropped）
+	SchedulerShadowRelays *prometheus.CounterVec
+
+	// SchedulerShadowRelayDuration 影子调用耗时分布（秒），超时的调用按超时时间计
+	// 标签: function_name
+	SchedulerShadowRelayDuration *prometheus.HistogramVec
+
 	// ========== 状态操作相关指标 ==========
 
 	// StateOperationsTotal 状态操作总次数计数器
@@ -116,6 +173,12 @@ type Metrics struct {
 	// SnapshotSizeBytes 快照文件大小
 	// 标签: function_id
 	SnapshotSizeBytes *prometheus.GaugeVec
+
+	// ========== 出站通知相关指标 ==========
+
+	// OutboundDeliveries 出站通知（回调、告警通知等）投递次数计数器
+	// 标签: destination（目标主机）, result (success/failure/circuit_open)
+	OutboundDeliveries *prometheus.CounterVec
 }
 
 // NewMetrics 创建并注册一组 Prometheus 指标。
@@ -205,6 +268,52 @@ func NewMetrics(namespace string) *Metrics {
 			},
 			[]string{"runtime"},
 		),
+		VMPoolUtilization: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "vm_pool_utilization",
+				Help:      "Ratio of instances to the per-runtime capacity limit",
+			},
+			[]string{"runtime"},
+		),
+		VMPoolPinnedWarm: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "vm_pool_pinned_warm",
+				Help:      "Number of warm instances pinned by function keep_warm settings",
+			},
+			[]string{"runtime"},
+		),
+		VMPoolIdleReaped: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "vm_pool_idle_reaped_total",
+				Help:      "Total number of warm instances destroyed for exceeding the idle timeout",
+			},
+			[]string{"runtime"},
+		),
+		ContainerRecycled: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "container_recycled_total",
+				Help:      "Total number of pooled containers destroyed after use, by reason",
+			},
+			[]string{"runtime", "reason"},
+		),
+		LayerCacheBytes: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "layer_cache_bytes",
+				Help:      "Current size in bytes of the extracted layer cache",
+			},
+		),
+		LayerCacheEvictions: promauto.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "layer_cache_evictions_total",
+				Help:      "Total number of extracted layers evicted from the layer cache",
+			},
+		),
 		FunctionsTotal: promauto.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
@@ -226,6 +335,14 @@ func NewMetrics(namespace string) *Metrics {
 				Help:      "Current scheduler queue size",
 			},
 		),
+		SchedulerQueueDepth: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "scheduler_queue_depth",
+				Help:      "Current scheduler queue size by trigger source",
+			},
+			[]string{"source"},
+		),
 		SchedulerWorkers: promauto.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
@@ -233,6 +350,70 @@ func NewMetrics(namespace string) *Metrics {
 				Help:      "Number of scheduler workers",
 			},
 		),
+		SchedulerActiveWorkers: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "scheduler_active_workers",
+				Help:      "Number of scheduler workers currently processing an invocation",
+			},
+		),
+		SchedulerPlatformRetries: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "scheduler_platform_retries_total",
+				Help:      "Total number of invocation retries caused by transient platform failures",
+			},
+			[]string{"runtime", "result"},
+		),
+		SchedulerRecursionRejections: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "scheduler_recursion_rejections_total",
+				Help:      "Total number of invocations rejected for exceeding the call depth or recursion limit",
+			},
+			[]string{"function_name", "reason"},
+		),
+		SchedulerKillSwitchRejections: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "scheduler_kill_switch_rejections_total",
+				Help:      "Total number of invocations rejected by an engaged kill switch",
+			},
+			[]string{"scope"},
+		),
+		SchedulerConcurrencyThrottles: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "scheduler_concurrency_throttles_total",
+				Help:      "Total number of invocations throttled by a function's max_concurrency",
+			},
+			[]string{"function_name", "result"},
+		),
+		SchedulerRuntimeLoad: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "scheduler_runtime_load",
+				Help:      "Number of queued and in-flight invocations per runtime",
+			},
+			[]string{"runtime", "state"},
+		),
+		SchedulerShadowRelays: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "scheduler_shadow_relays_total",
+				Help:      "Total number of shadow traffic relays by result",
+			},
+			[]string{"function_name", "result"},
+		),
+		SchedulerShadowRelayDuration: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "scheduler_shadow_relay_duration_seconds",
+				Help:      "Duration of shadow traffic invocations in seconds",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"function_name"},
+		),
 		// 状态操作指标
 		StateOperationsTotal: promauto.NewCounterVec(
 			prometheus.CounterOpts{
@@ -310,6 +491,15 @@ func NewMetrics(namespace string) *Metrics {
 			},
 			[]string{"function_id"},
 		),
+		// 出站通知指标
+		OutboundDeliveries: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "outbound_deliveries_total",
+				Help:      "Total number of outbound notification deliveries by destination and result",
+			},
+			[]string{"destination", "result"},
+		),
 	}
 }
 
@@ -341,6 +531,65 @@ func (m *Metrics) UpdatePoolStats(runtime string, warm, busy, total int) {
 	m.VMPoolSize.WithLabelValues(runtime).Set(float64(total))
 }
 
+// UpdatePoolUtilization 更新运行时实例池的容量使用率。
+func (m *Metrics) UpdatePoolUtilization(runtime string, used, limit int) {
+	if limit <= 0 {
+		return
+	}
+	m.VMPoolUtilization.WithLabelValues(runtime).Set(float64(used) / float64(limit))
+}
+
+// UpdatePoolPinnedWarm 更新运行时的常驻预热实例目标数。
+func (m *Metrics) UpdatePoolPinnedWarm(runtime string, pinned int) {
+	m.VMPoolPinnedWarm.WithLabelValues(runtime).Set(float64(pinned))
+}
+
+// RecordPoolIdleReaped 记录因空闲超时被回收的预热实例数。
+func (m *Metrics) RecordPoolIdleReaped(runtime string, count int) {
+	m.VMPoolIdleReaped.WithLabelValues(runtime).Add(float64(count))
+}
+
+// RecordContainerRecycle 记录一次容器回收及其原因。
+func (m *Metrics) RecordContainerRecycle(runtime, reason string) {
+	m.ContainerRecycled.WithLabelValues(runtime, reason).Inc()
+}
+
+// RecordLayerCache 记录层解压缓存的当前大小和本次淘汰的层数。
+func (m *Metrics) RecordLayerCache(sizeBytes int64, evicted int) {
+	m.LayerCacheBytes.Set(float64(sizeBytes))
+	m.LayerCacheEvictions.Add(float64(evicted))
+}
+
+// RecordPlatformRetry 记录一次平台故障重试，result 为 retried 或 budget_exhausted。
+func (m *Metrics) RecordPlatformRetry(runtime, result string) {
+	m.SchedulerPlatformRetries.WithLabelValues(runtime, result).Inc()
+}
+
+// RecordRecursionRejection 记录一次被拒绝的递归调用，reason 为 depth 或 cycle。
+func (m *Metrics) RecordRecursionRejection(functionName, reason string) {
+	m.SchedulerRecursionRejections.WithLabelValues(functionName, reason).Inc()
+}
+
+// RecordKillSwitchRejection 记录一次被紧急停止开关拒绝的调用，scope 为 global 或 function。
+func (m *Metrics) RecordKillSwitchRejection(scope string) {
+	m.SchedulerKillSwitchRejections.WithLabelValues(scope).Inc()
+}
+
+// RecordConcurrencyThrottle 记录一次因超出函数最大并发数被限流的调用，
+// result 为 rejected（同步调用返回 429）或 delayed（等待槽位后重新入队）。
+func (m *Metrics) RecordConcurrencyThrottle(functionName, result string) {
+	m.SchedulerConcurrencyThrottles.WithLabelValues(functionName, result).Inc()
+}
+
+// RecordShadowRelay 记录一次影子流量回放，result 为 success、failure、timeout 或 dropped。
+// 被丢弃的回放没有执行，不记录耗时。
+func (m *Metrics) RecordShadowRelay(functionName, result string, durationMs float64) {
+	m.SchedulerShadowRelays.WithLabelValues(functionName, result).Inc()
+	if result != "dropped" {
+		m.SchedulerShadowRelayDuration.WithLabelValues(functionName).Observe(durationMs / 1000)
+	}
+}
+
 // RecordVMBoot 记录虚拟机启动耗时。
 func (m *Metrics) RecordVMBoot(runtime string, durationMs float64, fromSnapshot bool) {
 	snapshotStr := "false"
@@ -402,3 +651,8 @@ func (m *Metrics) RecordSnapshotRestore(runtime string, durationMs float64) {
 func (m *Metrics) UpdateSnapshotSize(functionID string, sizeBytes int64) {
 	m.SnapshotSizeBytes.WithLabelValues(functionID).Set(float64(sizeBytes))
 }
+
+// RecordOutboundDelivery 记录一次出站通知投递结果。
+func (m *Metrics) RecordOutboundDelivery(destination, result string) {
+	m.OutboundDeliveries.WithLabelValues(destination, result).Inc()
+}
@@ -0,0 +1,333 @@
+// Package outbound 提供平台向外部地址发送 HTTP 通知（回调、告警通知等）的统一客户端。
+// 客户端负责连接/总超时、带抖动的指数退避重试，以及按目标主机的熔断，
+// 避免持续向不可用的端点发送请求，并按目标主机上报投递结果指标。
+package outbound
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/config"
+)
+
+// ErrCircuitOpen 表示目标主机的熔断器处于打开状态，请求未发出
+var ErrCircuitOpen = errors.New("outbound circuit open for destination")
+
+// 投递结果，用作指标标签
+const (
+	// ResultSuccess 投递成功（2xx 响应）
+	ResultSuccess = "success"
+	// ResultFailure 重试耗尽或不可重试的失败
+	ResultFailure = "failure"
+	// ResultCircuitOpen 熔断器打开，请求被直接拒绝
+	ResultCircuitOpen = "circuit_open"
+)
+
+// Options 出站客户端配置。
+type Options struct {
+	// ConnectTimeout 建立 TCP 连接的超时时间
+	ConnectTimeout time.Duration
+	// Timeout 单次请求的总超时时间（含读取响应）
+	Timeout time.Duration
+	// MaxRetries 首次请求失败后的最大重试次数
+	MaxRetries int
+	// BaseBackoff 首次重试前的退避时间，之后每次翻倍
+	BaseBackoff time.Duration
+	// MaxBackoff 单次退避时间上限
+	MaxBackoff time.Duration
+	// BreakerThreshold 连续失败多少次后打开熔断器，<= 0 表示不熔断
+	BreakerThreshold int
+	// BreakerCooldown 熔断器打开后多久允许一次试探请求
+	BreakerCooldown time.Duration
+}
+
+// DefaultOptions 返回默认的出站客户端配置。
+func DefaultOptions() Options {
+	return Options{
+		ConnectTimeout:   3 * time.Second,
+		Timeout:          10 * time.Second,
+		MaxRetries:       3,
+		BaseBackoff:      500 * time.Millisecond,
+		MaxBackoff:       10 * time.Second,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
+}
+
+// OptionsFromConfig 将部署配置转换为客户端配置。
+func OptionsFromConfig(cfg config.OutboundConfig) Options {
+	return Options{
+		ConnectTimeout:   cfg.ConnectTimeout,
+		Timeout:          cfg.Timeout,
+		MaxRetries:       cfg.MaxRetries,
+		BaseBackoff:      cfg.BaseBackoff,
+		MaxBackoff:       cfg.MaxBackoff,
+		BreakerThreshold: cfg.BreakerThreshold,
+		BreakerCooldown:  cfg.BreakerCooldown,
+	}
+}
+
+// Recorder 记录出站投递结果（可选），由指标收集器实现。
+type Recorder interface {
+	// RecordOutboundDelivery 记录一次投递结果
+	// 参数 destination: 目标主机；result: ResultSuccess/ResultFailure/ResultCircuitOpen
+	RecordOutboundDelivery(destination, result string)
+}
+
+// Response 出站请求的最终结果。
+type Response struct {
+	// StatusCode 最后一次请求的响应状态码，未收到响应时为 0
+	StatusCode int
+	// Attempts 实际发出的请求次数
+	Attempts int
+}
+
+// Client 出站 HTTP 客户端，可被多个协程并发使用。
+type Client struct {
+	opts     Options
+	http     *http.Client
+	recorder Recorder
+	logger   *logrus.Logger
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+
+	// sleep 用于等待退避时间，测试中可替换
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// New 创建出站客户端。
+// 参数:
+//   - opts: 客户端配置，零值字段使用 DefaultOptions 中的对应值
+//   - recorder: 投递结果记录器，可为 nil
+//   - logger: 日志记录器
+func New(opts Options, recorder Recorder, logger *logrus.Logger) *Client {
+	def := DefaultOptions()
+	if opts.ConnectTimeout <= 0 {
+		opts.ConnectTimeout = def.ConnectTimeout
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = def.Timeout
+	}
+	if opts.MaxRetries < 0 {
+		opts.MaxRetries = 0
+	}
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = def.BaseBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = def.MaxBackoff
+	}
+	if opts.BreakerCooldown <= 0 {
+		opts.BreakerCooldown = def.BreakerCooldown
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: opts.ConnectTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = opts.ConnectTimeout
+
+	return &Client{
+		opts:     opts,
+		http:     &http.Client{Timeout: opts.Timeout, Transport: transport},
+		recorder: recorder,
+		logger:   logger,
+		breakers: make(map[string]*breaker),
+		sleep:    sleepContext,
+	}
+}
+
+// PostJSON 以 application/json 发送 POST 请求。
+// 网络错误、429 和 5xx 响应会按退避策略重试；其他 4xx 响应视为不可重试的失败。
+//
+// 参数:
+//   - ctx: 上下文，取消时停止重试
+//   - target: 目标地址（http/https）
+//   - body: 请求体
+//   - headers: 附加请求头，可为 nil
+//
+// 返回值:
+//   - *Response: 最后一次请求的结果
+//   - error: 未能成功投递时返回错误；熔断器打开时返回 ErrCircuitOpen
+func (c *Client) PostJSON(ctx context.Context, target string, body []byte, headers map[string]string) (*Response, error) {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid outbound url: %q", target)
+	}
+	host := u.Host
+
+	br := c.breaker(host)
+	if !br.allow(time.Now()) {
+		c.record(host, ResultCircuitOpen)
+		return &Response{}, ErrCircuitOpen
+	}
+
+	resp := &Response{}
+	var lastErr error
+	reachable := false
+	for attempt := 0; attempt <= c.opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if err := c.sleep(ctx, c.backoff(attempt)); err != nil {
+				lastErr = err
+				break
+			}
+		}
+
+		resp.Attempts++
+		status, retryable, err := c.do(ctx, target, body, headers)
+		resp.StatusCode = status
+		if err == nil {
+			br.success()
+			c.record(host, ResultSuccess)
+			return resp, nil
+		}
+		lastErr = err
+		if !retryable {
+			// 4xx 响应说明目标可达，只是拒绝了请求，不计入熔断
+			reachable = status != 0
+			break
+		}
+		if c.logger != nil {
+			c.logger.WithFields(logrus.Fields{
+				"destination": host,
+				"attempt":     resp.Attempts,
+				"error":       err.Error(),
+			}).Debug("Outbound delivery failed, retrying")
+		}
+	}
+
+	if reachable {
+		br.success()
+	} else {
+		br.failure(time.Now(), c.opts.BreakerThreshold, c.opts.BreakerCooldown)
+	}
+	c.record(host, ResultFailure)
+	return resp, lastErr
+}
+
+// do 发送单次请求，返回状态码、失败是否可重试以及错误。
+func (c *Client) do(ctx context.Context, target string, body []byte, headers map[string]string) (int, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "nimbus-outbound/1.0")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// 上下文取消时不再重试
+		return 0, ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, false, nil
+	}
+	retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return resp.StatusCode, retryable, fmt.Errorf("outbound request returned status %d", resp.StatusCode)
+}
+
+// backoff 计算第 attempt 次重试前的退避时间（指数退避 + 抖动）。
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.opts.BaseBackoff << (attempt - 1)
+	if d <= 0 || d > c.opts.MaxBackoff {
+		d = c.opts.MaxBackoff
+	}
+	// 在 [d/2, d) 区间内随机，避免多个通知同时重试
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// breaker 返回目标主机的熔断器，不存在时创建。
+func (c *Client) breaker(host string) *breaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	br, ok := c.breakers[host]
+	if !ok {
+		br = &breaker{}
+		c.breakers[host] = br
+	}
+	return br
+}
+
+func (c *Client) record(host, result string) {
+	if c.recorder != nil {
+		c.recorder.RecordOutboundDelivery(host, result)
+	}
+}
+
+// sleepContext 等待 d，上下文取消时提前返回错误。
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ==================== 熔断器 ====================
+
+// breaker 单个目标主机的熔断器。
+// 连续失败达到阈值后打开，冷却时间过后放行一次试探请求（半开），
+// 试探成功则关闭，失败则重新打开。
+type breaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// allow 判断是否允许发送请求。
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openUntil.IsZero() {
+		return true
+	}
+	if now.Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// success 记录一次成功投递，关闭熔断器。
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.openUntil = time.Time{}
+	b.probing = false
+}
+
+// failure 记录一次失败投递，连续失败达到阈值或试探失败时打开熔断器。
+func (b *breaker) failure(now time.Time, threshold int, cooldown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if threshold <= 0 {
+		return
+	}
+	if b.probing || b.failures >= threshold {
+		b.openUntil = now.Add(cooldown)
+		b.probing = false
+	}
+}
@@ -0,0 +1,115 @@
+package outbound
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeRecorder struct {
+	results []string
+}
+
+func (r *fakeRecorder) RecordOutboundDelivery(destination, result string) {
+	r.results = append(r.results, result)
+}
+
+func newTestClient(opts Options, rec Recorder) *Client {
+	c := New(opts, rec, nil)
+	c.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+	return c
+}
+
+func TestPostJSONRetriesServerErrors(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	rec := &fakeRecorder{}
+	c := newTestClient(Options{MaxRetries: 3}, rec)
+	resp, err := c.PostJSON(context.Background(), srv.URL, []byte(`{}`), nil)
+	if err != nil {
+		t.Fatalf("PostJSON() error = %v", err)
+	}
+	if resp.Attempts != 3 || resp.StatusCode != http.StatusOK {
+		t.Errorf("PostJSON() = %+v, want 3 attempts and status 200", resp)
+	}
+	if len(rec.results) != 1 || rec.results[0] != ResultSuccess {
+		t.Errorf("recorded %v, want [success]", rec.results)
+	}
+}
+
+func TestPostJSONDoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(Options{MaxRetries: 3, BreakerThreshold: 1}, nil)
+	for i := 0; i < 2; i++ {
+		if _, err := c.PostJSON(context.Background(), srv.URL, nil, nil); err == nil {
+			t.Fatal("PostJSON() error = nil, want error for 400")
+		}
+	}
+	// 4xx 不重试，也不触发熔断
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("server received %d requests, want 2", got)
+	}
+}
+
+func TestPostJSONCircuitBreaker(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	rec := &fakeRecorder{}
+	c := newTestClient(Options{MaxRetries: 1, BreakerThreshold: 2, BreakerCooldown: time.Hour}, rec)
+	for i := 0; i < 2; i++ {
+		if _, err := c.PostJSON(context.Background(), srv.URL, nil, nil); err == nil || err == ErrCircuitOpen {
+			t.Fatalf("PostJSON() #%d error = %v, want delivery failure", i, err)
+		}
+	}
+	if _, err := c.PostJSON(context.Background(), srv.URL, nil, nil); err != ErrCircuitOpen {
+		t.Errorf("PostJSON() error = %v, want ErrCircuitOpen", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 4 {
+		t.Errorf("server received %d requests, want 4", got)
+	}
+	if rec.results[len(rec.results)-1] != ResultCircuitOpen {
+		t.Errorf("recorded %v, want last result circuit_open", rec.results)
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	b := &breaker{}
+	now := time.Now()
+	b.failure(now, 1, time.Minute)
+	if b.allow(now) {
+		t.Fatal("allow() = true while open")
+	}
+	later := now.Add(2 * time.Minute)
+	if !b.allow(later) {
+		t.Fatal("allow() = false after cooldown, want probe")
+	}
+	if b.allow(later) {
+		t.Fatal("allow() = true during probe, want only one probe")
+	}
+	b.success()
+	if !b.allow(later) {
+		t.Fatal("allow() = false after successful probe")
+	}
+}
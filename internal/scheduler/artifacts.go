@@ -0,0 +1,33 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+	"github.com/oriys/nimbus/internal/storage"
+)
+
+// saveInvocationArtifacts 持久化执行器收集的调用产物，并在响应和调用记录上保留不含内容的产物列表（带下载地址）。
+// 保存失败时丢弃产物并记录警告，不影响调用结果。
+func saveInvocationArtifacts(store *storage.PostgresStore, inv *domain.Invocation, resp *domain.InvokeResponse, logger *logrus.Entry) {
+	if len(resp.Artifacts) == 0 {
+		return
+	}
+	now := time.Now()
+	for i := range resp.Artifacts {
+		resp.Artifacts[i].URL = domain.ArtifactURL(inv.ID, resp.Artifacts[i].Name)
+		resp.Artifacts[i].CreatedAt = now
+	}
+	if err := store.SaveInvocationArtifacts(inv.ID, resp.Artifacts); err != nil {
+		logger.WithError(err).WithField("artifacts", len(resp.Artifacts)).Warn("Failed to save invocation artifacts")
+		resp.Artifacts = nil
+		return
+	}
+	// 内容已持久化，响应只携带元数据，避免大文件随结果在内存中传递
+	for i := range resp.Artifacts {
+		resp.Artifacts[i].Data = nil
+	}
+	inv.Artifacts = resp.Artifacts
+}
@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+	"github.com/oriys/nimbus/internal/storage"
+)
+
+// redisOverflowTimeout 推送溢出调用到 Redis 的超时时间，避免 Redis 故障时阻塞请求
+const redisOverflowTimeout = 2 * time.Second
+
+// pushOverflowInvocation 将本地队列溢出的调用ID推送到 Redis 备用队列。
+//
+// 参数:
+//   - redis: Redis 存储实例，可为 nil
+//   - invocationID: 调用ID
+//
+// 返回值:
+//   - error: Redis 未配置或推送失败时返回错误
+func pushOverflowInvocation(redis *storage.RedisStore, invocationID string) error {
+	if redis == nil {
+		return fmt.Errorf("redis not configured")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisOverflowTimeout)
+	defer cancel()
+	return redis.PushInvocation(ctx, invocationID)
+}
+
+// rejectAsyncInvocation 在异步调用无法入队（本地队列已满且 Redis 不可用）时将调用记录标记为失败，
+// 避免残留 pending 记录。
+//
+// 参数:
+//   - store: PostgreSQL 存储，用于更新调用记录
+//   - logger: 日志记录器
+//   - inv: 被拒绝的调用
+//   - queue: 不可用的队列名称，如 "async queue"、"paused queue"
+//   - cause: 入队失败的原因
+func rejectAsyncInvocation(store *storage.PostgresStore, logger *logrus.Logger, inv *domain.Invocation, queue string, cause error) {
+	logger.WithFields(logrus.Fields{
+		"invocation_id": inv.ID,
+		"function_id":   inv.FunctionID,
+		"error":         cause.Error(),
+	}).Warnf("Async invocation rejected: %s unavailable", queue)
+
+	inv.Fail(queue + " unavailable: " + cause.Error())
+	if err := store.UpdateInvocation(inv); err != nil {
+		logger.WithError(err).Warn("Failed to mark rejected invocation as failed")
+	}
+}
@@ -0,0 +1,329 @@
+// Package scheduler 提供函数调度器的实现。
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+	"github.com/oriys/nimbus/internal/storage"
+)
+
+// circuitRecoveryInterval 检查熔断函数冷却时间是否结束的周期
+const circuitRecoveryInterval = 15 * time.Second
+
+// maxCircuitRecoveryBatch 每个周期最多恢复的熔断函数数
+const maxCircuitRecoveryBatch = 1000
+
+// circuitBucket 是一秒内的调用结果计数
+type circuitBucket struct {
+	second int64
+	total  int
+	failed int
+}
+
+// circuitWindow 是单个函数的滑动窗口，按秒聚合，占用内存与窗口秒数成正比而与调用量无关。
+type circuitWindow struct {
+	buckets []circuitBucket
+	// probing 表示函数刚从熔断中恢复，下一次调用的结果决定恢复正常还是再次熔断
+	probing bool
+}
+
+// circuitOpenDedup 是熔断去重时间：函数在此时间内已被其他实例熔断时，本实例不再重复下线和告警
+const circuitOpenDedup = 10 * time.Second
+
+// circuitStateTimeout 是访问共享熔断状态的超时时间
+const circuitStateTimeout = 500 * time.Millisecond
+
+// circuitFunctionStore 更新熔断函数的状态并记录告警，由 storage.PostgresStore 实现。
+type circuitFunctionStore interface {
+	UpdateFunctionStatus(id string, status domain.FunctionStatus, statusMessage, taskID string) error
+	CreateAlert(alert *domain.Alert) error
+	ListFunctionsWithFilter(filter *domain.FunctionFilter, offset, limit int) ([]*domain.Function, int, error)
+}
+
+// circuitStateStore 存放多个网关实例共享的熔断统计窗口和熔断状态，由 storage.RedisStore 实现。
+type circuitStateStore interface {
+	RecordCircuitResult(ctx context.Context, functionID string, failed bool, window time.Duration) (int, int, bool, error)
+	OpenCircuit(ctx context.Context, functionID string, openedAt time.Time, dedup time.Duration) (bool, error)
+	ProbeCircuit(ctx context.Context, functionID string) error
+	GetCircuitState(ctx context.Context, functionID string) (*domain.CircuitState, error)
+	ClearCircuit(ctx context.Context, functionID string) error
+}
+
+// circuitBreakerTracker 实现函数级熔断：统计开启了熔断的函数在滑动窗口内的错误率，
+// 达到阈值时将函数下线为 circuit_open 并产生告警；冷却时间结束后恢复为 active 并试探。
+// 配置了 Redis 时统计窗口、熔断时间和试探状态按函数 ID 存放在 Redis 中，所有实例共同判断；
+// 未配置 Redis 或 Redis 不可用时退化为只统计本实例执行的调用。
+type circuitBreakerTracker struct {
+	store  circuitFunctionStore
+	states circuitStateStore
+	logger *logrus.Logger
+
+	mu      sync.Mutex
+	windows map[string]*circuitWindow
+}
+
+// newCircuitBreakerTracker 创建函数熔断跟踪器。
+//
+// 参数:
+//   - store: PostgreSQL 存储实例，用于更新函数状态和记录告警，为 nil 时不熔断
+//   - redis: Redis 存储实例，用于在实例间共享熔断状态，为 nil 时各实例独立判断
+//   - logger: 日志记录器
+func newCircuitBreakerTracker(store *storage.PostgresStore, redis *storage.RedisStore, logger *logrus.Logger) *circuitBreakerTracker {
+	t := &circuitBreakerTracker{
+		logger:  logger,
+		windows: make(map[string]*circuitWindow),
+	}
+	if store != nil {
+		t.store = store
+	}
+	if redis != nil {
+		t.states = redis
+	}
+	return t
+}
+
+// record 记录一次已完成调用的结果，达到熔断条件时下线函数。
+// 执行器自身的错误（如排队超时）不是函数的问题，调用方不应记录。
+//
+// 参数:
+//   - fn: 被调用的函数
+//   - failed: 调用是否失败或超时
+func (t *circuitBreakerTracker) record(fn *domain.Function, failed bool) {
+	cb := fn.CircuitBreaker
+	if cb == nil || t.store == nil {
+		t.mu.Lock()
+		delete(t.windows, fn.ID)
+		t.mu.Unlock()
+		return
+	}
+
+	if t.states != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), circuitStateTimeout)
+		total, failedCount, probe, err := t.states.RecordCircuitResult(ctx, fn.ID, failed, cb.Window())
+		cancel()
+		if err == nil {
+			if probe {
+				t.probed(fn, failed)
+			} else if cb.Trips(total, failedCount) {
+				t.trip(fn, total, failedCount)
+			}
+			return
+		}
+		t.logger.WithError(err).WithField("function_id", fn.ID).Warn("Shared circuit breaker state unavailable, using local window")
+	}
+
+	now := time.Now()
+	t.mu.Lock()
+	w := t.windows[fn.ID]
+	if w == nil {
+		w = &circuitWindow{}
+		t.windows[fn.ID] = w
+	}
+
+	if w.probing {
+		w.probing = false
+		t.mu.Unlock()
+		t.probed(fn, failed)
+		return
+	}
+
+	second := now.Unix()
+	if n := len(w.buckets); n > 0 && w.buckets[n-1].second == second {
+		w.buckets[n-1].total++
+		if failed {
+			w.buckets[n-1].failed++
+		}
+	} else {
+		b := circuitBucket{second: second, total: 1}
+		if failed {
+			b.failed = 1
+		}
+		w.buckets = append(w.buckets, b)
+	}
+
+	// 丢弃窗口之外的桶
+	cutoff := now.Add(-cb.Window()).Unix()
+	drop := 0
+	for drop < len(w.buckets) && w.buckets[drop].second <= cutoff {
+		drop++
+	}
+	w.buckets = w.buckets[drop:]
+
+	total, failedCount := 0, 0
+	for _, b := range w.buckets {
+		total += b.total
+		failedCount += b.failed
+	}
+	trips := cb.Trips(total, failedCount)
+	if trips {
+		delete(t.windows, fn.ID)
+	}
+	t.mu.Unlock()
+
+	if trips {
+		t.trip(fn, total, failedCount)
+	}
+}
+
+// probed 处理试探调用的结果：成功则恢复正常，失败则立即再次熔断。
+func (t *circuitBreakerTracker) probed(fn *domain.Function, failed bool) {
+	if failed {
+		t.open(fn, "冷却结束后的试探调用失败", 100, 1)
+		return
+	}
+	t.logger.WithFields(logrus.Fields{
+		"function_id":   fn.ID,
+		"function_name": fn.Name,
+	}).Info("Circuit breaker probe succeeded, function recovered")
+}
+
+// trip 因窗口内错误率达到阈值熔断函数。
+func (t *circuitBreakerTracker) trip(fn *domain.Function, total, failedCount int) {
+	cb := fn.CircuitBreaker
+	reason := fmt.Sprintf("最近 %s 内 %d 次调用中 %d 次失败，错误率达到熔断阈值 %d%%", cb.Window(), total, failedCount, cb.ErrorRatePercent)
+	t.open(fn, reason, float64(failedCount*100)/float64(total), total)
+}
+
+// open 将函数下线为 circuit_open 并记录告警。函数已不可调用（已熔断、被手动下线等），
+// 或刚被其他实例熔断时不做任何事。
+func (t *circuitBreakerTracker) open(fn *domain.Function, reason string, errorRate float64, invocations int) {
+	if !fn.Status.CanInvoke() {
+		return
+	}
+	if t.states != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), circuitStateTimeout)
+		opened, err := t.states.OpenCircuit(ctx, fn.ID, time.Now(), circuitOpenDedup)
+		cancel()
+		if err != nil {
+			// 未能记录熔断时间时，恢复检查以函数的更新时间作为熔断时间
+			t.logger.WithError(err).WithField("function_id", fn.ID).Warn("Failed to record shared circuit breaker state")
+		} else if !opened {
+			return
+		}
+	}
+	cooldown := fn.CircuitBreaker.Cooldown()
+	msg := fmt.Sprintf("函数已熔断：%s，%s 后自动恢复", reason, cooldown)
+	if err := t.store.UpdateFunctionStatus(fn.ID, domain.FunctionStatusCircuitOpen, msg, ""); err != nil {
+		t.logger.WithError(err).WithField("function_id", fn.ID).Warn("Failed to open circuit breaker")
+		return
+	}
+	fn.Status = domain.FunctionStatusCircuitOpen
+
+	if err := t.store.CreateAlert(&domain.Alert{
+		RuleName:     "circuit_breaker",
+		FunctionID:   fn.ID,
+		FunctionName: fn.Name,
+		Severity:     domain.AlertSeverityCritical,
+		Status:       domain.AlertStatusActive,
+		Message:      msg,
+		Value:        errorRate,
+		Threshold:    float64(fn.CircuitBreaker.ErrorRatePercent),
+	}); err != nil {
+		t.logger.WithError(err).WithField("function_id", fn.ID).Warn("Failed to record circuit breaker alert")
+	}
+
+	t.logger.WithFields(logrus.Fields{
+		"function_id":   fn.ID,
+		"function_name": fn.Name,
+		"error_rate":    errorRate,
+		"invocations":   invocations,
+		"cooldown":      cooldown.String(),
+	}).Warn("Circuit breaker opened, function taken offline")
+}
+
+// run 按 circuitRecoveryInterval 周期恢复冷却结束的熔断函数，直到 ctx 取消。
+func (t *circuitBreakerTracker) run(ctx context.Context) {
+	if t.store == nil {
+		return
+	}
+	ticker := time.NewTicker(circuitRecoveryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.recover()
+		}
+	}
+}
+
+// recover 将冷却时间已结束的熔断函数恢复为 active，并将其标记为试探状态。
+// 熔断时间取自 Redis 中的共享熔断状态，函数的其他修改不会重置冷却时间；
+// 没有共享状态（未配置 Redis 或熔断时 Redis 不可用）时取函数的更新时间（熔断时写入）。
+// 两者都是持久化的，因此网关重启后仍能按时恢复。
+func (t *circuitBreakerTracker) recover() {
+	fns, _, err := t.store.ListFunctionsWithFilter(&domain.FunctionFilter{Status: domain.FunctionStatusCircuitOpen}, 0, maxCircuitRecoveryBatch)
+	if err != nil {
+		t.logger.WithError(err).Warn("Failed to list circuit-open functions")
+		return
+	}
+
+	now := time.Now()
+	for _, fn := range fns {
+		// 熔断期间关闭了熔断配置的函数立即恢复
+		if fn.CircuitBreaker != nil && now.Sub(t.openedAt(fn)) < fn.CircuitBreaker.Cooldown() {
+			continue
+		}
+		if err := t.store.UpdateFunctionStatus(fn.ID, domain.FunctionStatusActive, "", ""); err != nil {
+			t.logger.WithError(err).WithField("function_id", fn.ID).Warn("Failed to close circuit breaker")
+			continue
+		}
+
+		t.startProbe(fn)
+
+		t.logger.WithFields(logrus.Fields{
+			"function_id":   fn.ID,
+			"function_name": fn.Name,
+		}).Info("Circuit breaker cooldown elapsed, probing function")
+	}
+}
+
+// openedAt 返回函数最近一次熔断的时间。
+func (t *circuitBreakerTracker) openedAt(fn *domain.Function) time.Time {
+	if t.states == nil {
+		return fn.UpdatedAt
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), circuitStateTimeout)
+	defer cancel()
+	state, err := t.states.GetCircuitState(ctx, fn.ID)
+	if err != nil {
+		t.logger.WithError(err).WithField("function_id", fn.ID).Warn("Failed to get shared circuit breaker state")
+		return fn.UpdatedAt
+	}
+	if state == nil || state.OpenedAt.IsZero() {
+		return fn.UpdatedAt
+	}
+	return state.OpenedAt
+}
+
+// startProbe 将恢复的函数标记为试探状态；熔断期间关闭了熔断配置的函数清除熔断状态。
+// 共享状态不可用时在本实例内试探。
+func (t *circuitBreakerTracker) startProbe(fn *domain.Function) {
+	probing := fn.CircuitBreaker != nil
+	if t.states != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), circuitStateTimeout)
+		var err error
+		if probing {
+			err = t.states.ProbeCircuit(ctx, fn.ID)
+		} else {
+			err = t.states.ClearCircuit(ctx, fn.ID)
+		}
+		cancel()
+		if err == nil {
+			return
+		}
+		t.logger.WithError(err).WithField("function_id", fn.ID).Warn("Failed to update shared circuit breaker state")
+	}
+
+	t.mu.Lock()
+	t.windows[fn.ID] = &circuitWindow{probing: probing}
+	t.mu.Unlock()
+}
@@ -0,0 +1,217 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// fakeCircuitFunctions 记录熔断跟踪器对函数状态和告警的更新
+type fakeCircuitFunctions struct {
+	mu       sync.Mutex
+	statuses []domain.FunctionStatus
+	alerts   int
+	open     []*domain.Function
+}
+
+func (s *fakeCircuitFunctions) UpdateFunctionStatus(id string, status domain.FunctionStatus, statusMessage, taskID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses = append(s.statuses, status)
+	return nil
+}
+
+func (s *fakeCircuitFunctions) CreateAlert(*domain.Alert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.alerts++
+	return nil
+}
+
+func (s *fakeCircuitFunctions) ListFunctionsWithFilter(*domain.FunctionFilter, int, int) ([]*domain.Function, int, error) {
+	return s.open, len(s.open), nil
+}
+
+// fakeCircuitStates 是内存中的共享熔断状态，语义与 Redis 实现一致（窗口不过期）
+type fakeCircuitStates struct {
+	mu     sync.Mutex
+	total  map[string]int
+	failed map[string]int
+	states map[string]*domain.CircuitState
+	err    error
+}
+
+func newFakeCircuitStates() *fakeCircuitStates {
+	return &fakeCircuitStates{total: map[string]int{}, failed: map[string]int{}, states: map[string]*domain.CircuitState{}}
+}
+
+func (s *fakeCircuitStates) RecordCircuitResult(_ context.Context, id string, failed bool, _ time.Duration) (int, int, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, 0, false, s.err
+	}
+	if st := s.states[id]; st != nil && st.State == domain.CircuitStateProbing {
+		delete(s.states, id)
+		return 0, 0, true, nil
+	}
+	s.total[id]++
+	if failed {
+		s.failed[id]++
+	}
+	return s.total[id], s.failed[id], false, nil
+}
+
+func (s *fakeCircuitStates) OpenCircuit(_ context.Context, id string, openedAt time.Time, dedup time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return false, s.err
+	}
+	if st := s.states[id]; st != nil && st.State == domain.CircuitStateOpen && st.OpenedAt.After(openedAt.Add(-dedup)) {
+		return false, nil
+	}
+	s.states[id] = &domain.CircuitState{State: domain.CircuitStateOpen, OpenedAt: openedAt}
+	delete(s.total, id)
+	delete(s.failed, id)
+	return true, nil
+}
+
+func (s *fakeCircuitStates) ProbeCircuit(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.states[id] == nil {
+		s.states[id] = &domain.CircuitState{}
+	}
+	s.states[id].State = domain.CircuitStateProbing
+	return nil
+}
+
+func (s *fakeCircuitStates) GetCircuitState(_ context.Context, id string) (*domain.CircuitState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.states[id], nil
+}
+
+func (s *fakeCircuitStates) ClearCircuit(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, id)
+	return nil
+}
+
+func newTestCircuitTracker(store *fakeCircuitFunctions, states *fakeCircuitStates) *circuitBreakerTracker {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	t := &circuitBreakerTracker{store: store, logger: logger, windows: make(map[string]*circuitWindow)}
+	if states != nil {
+		t.states = states
+	}
+	return t
+}
+
+func circuitFunction() *domain.Function {
+	return &domain.Function{
+		ID:             "fn-1",
+		Name:           "demo",
+		Status:         domain.FunctionStatusActive,
+		CircuitBreaker: &domain.CircuitBreakerConfig{ErrorRatePercent: 50, MinInvocations: 4, CooldownSec: 60},
+	}
+}
+
+func TestCircuitBreakerSharedWindow(t *testing.T) {
+	store := &fakeCircuitFunctions{}
+	states := newFakeCircuitStates()
+	a, b := newTestCircuitTracker(store, states), newTestCircuitTracker(store, states)
+
+	// 两个实例各执行两次失败调用，合计达到最少调用数后熔断
+	fnA, fnB := circuitFunction(), circuitFunction()
+	a.record(fnA, true)
+	b.record(fnB, true)
+	a.record(fnA, true)
+	if len(store.statuses) != 0 {
+		t.Fatalf("opened after 3 invocations, want at least 4")
+	}
+	b.record(fnB, true)
+	if len(store.statuses) != 1 || store.statuses[0] != domain.FunctionStatusCircuitOpen || store.alerts != 1 {
+		t.Fatalf("statuses = %v, alerts = %d, want one circuit_open with one alert", store.statuses, store.alerts)
+	}
+	if st := states.states["fn-1"]; st == nil || st.State != domain.CircuitStateOpen {
+		t.Fatalf("shared state = %+v, want open", st)
+	}
+
+	// 另一个实例同时达到熔断条件时不重复下线和告警
+	if a.open(fnA, "test", 100, 4); len(store.statuses) != 1 || store.alerts != 1 {
+		t.Errorf("statuses = %v, alerts = %d, want duplicate open skipped", store.statuses, store.alerts)
+	}
+}
+
+func TestCircuitBreakerRecoverUsesSharedOpenedAt(t *testing.T) {
+	now := time.Now()
+	fn := circuitFunction()
+	fn.Status = domain.FunctionStatusCircuitOpen
+	store := &fakeCircuitFunctions{open: []*domain.Function{fn}}
+	states := newFakeCircuitStates()
+	tr := newTestCircuitTracker(store, states)
+
+	// 熔断后函数被修改：冷却时间仍从熔断时间开始计算
+	fn.UpdatedAt = now
+	states.states[fn.ID] = &domain.CircuitState{State: domain.CircuitStateOpen, OpenedAt: now.Add(-2 * time.Minute)}
+	tr.recover()
+	if len(store.statuses) != 1 || store.statuses[0] != domain.FunctionStatusActive {
+		t.Fatalf("statuses = %v, want recovered despite recent update", store.statuses)
+	}
+	if st := states.states[fn.ID]; st == nil || st.State != domain.CircuitStateProbing {
+		t.Fatalf("shared state = %+v, want probing", st)
+	}
+
+	// 试探调用可由任一实例执行，失败时立即再次熔断
+	other := newTestCircuitTracker(store, states)
+	probe := circuitFunction()
+	other.record(probe, true)
+	if got := store.statuses[len(store.statuses)-1]; got != domain.FunctionStatusCircuitOpen {
+		t.Fatalf("status after failed probe = %s, want circuit_open", got)
+	}
+
+	// 熔断时间未超过冷却时间时不恢复，即使函数很久没有修改
+	store.statuses = nil
+	fn.UpdatedAt = now.Add(-time.Hour)
+	tr.recover()
+	if len(store.statuses) != 0 {
+		t.Errorf("statuses = %v, want still open within cooldown", store.statuses)
+	}
+}
+
+func TestCircuitBreakerLocalFallback(t *testing.T) {
+	store := &fakeCircuitFunctions{}
+	states := newFakeCircuitStates()
+	states.err = errors.New("connection refused")
+	tr := newTestCircuitTracker(store, states)
+
+	// Redis 不可用时按本实例的窗口判断
+	fn := circuitFunction()
+	for i := 0; i < 4; i++ {
+		tr.record(fn, true)
+	}
+	if len(store.statuses) != 1 || store.statuses[0] != domain.FunctionStatusCircuitOpen {
+		t.Fatalf("statuses = %v, want opened from local window", store.statuses)
+	}
+
+	// 没有共享熔断时间时以函数的更新时间作为熔断时间
+	fn.UpdatedAt = time.Now()
+	store.open = []*domain.Function{fn}
+	store.statuses = nil
+	tr.recover()
+	if len(store.statuses) != 0 {
+		t.Errorf("statuses = %v, want still open within cooldown from UpdatedAt", store.statuses)
+	}
+}
@@ -0,0 +1,142 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+	"github.com/oriys/nimbus/internal/metrics"
+	"github.com/oriys/nimbus/internal/storage"
+)
+
+const (
+	// concurrencyRedisTimeout 是占用和释放并发槽位的 Redis 超时，超时视为 Redis 不可用
+	concurrencyRedisTimeout = 200 * time.Millisecond
+	// concurrencyLeaseGrace 是并发槽位租约在函数超时之外的宽限时间，
+	// 覆盖冷启动、结果回写等执行之外的耗时；调度器崩溃未释放的槽位在租约过期后回收
+	concurrencyLeaseGrace = 30 * time.Second
+)
+
+// 并发限流结果，用作指标标签
+const (
+	concurrencyThrottleRejected = "rejected" // 同步调用直接返回 429
+	concurrencyThrottleDelayed  = "delayed"  // 稍后重新入队等待槽位
+)
+
+// concurrencyLimiter 在执行前强制函数的最大并发数（Function.MaxConcurrency）。
+//
+// 槽位存放在 Redis 中，所有网关实例共享同一组槽位；每个槽位是以调用 ID 为成员的租约，
+// 调用结束时释放，实例崩溃时随租约过期自动回收。MaxConcurrency 为 0 时不限制。
+// 未配置 Redis 或 Redis 不可用时放行调用，避免限流本身成为故障点。
+type concurrencyLimiter struct {
+	redis          *storage.RedisStore
+	metrics        *metrics.Metrics
+	logger         *logrus.Logger
+	defaultTimeout time.Duration // 函数未设置超时时使用的默认超时，用于计算租约时长
+	queueTimeout   time.Duration // 同步调用等待槽位的最长时间，为 0 时立即拒绝
+}
+
+// newConcurrencyLimiter 创建函数并发限制器。
+//
+// 参数:
+//   - redis: Redis 存储，可为 nil（不限制）
+//   - m: 指标收集器，可为 nil
+//   - logger: 日志记录器
+//   - defaultTimeout: 函数默认超时
+//   - queueTimeout: 同步调用等待槽位的最长时间
+func newConcurrencyLimiter(redis *storage.RedisStore, m *metrics.Metrics, logger *logrus.Logger, defaultTimeout, queueTimeout time.Duration) *concurrencyLimiter {
+	return &concurrencyLimiter{
+		redis:          redis,
+		metrics:        m,
+		logger:         logger,
+		defaultTimeout: defaultTimeout,
+		queueTimeout:   queueTimeout,
+	}
+}
+
+// acquire 为调用占用函数的并发槽位。
+//
+// 返回值:
+//   - func(): 释放槽位的函数，调用结束后必须调用
+//   - bool: 是否占用成功，函数已达到最大并发数时为 false
+func (l *concurrencyLimiter) acquire(fn *domain.Function, invocationID string) (func(), bool) {
+	if fn.MaxConcurrency <= 0 || l.redis == nil {
+		return func() {}, true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), concurrencyRedisTimeout)
+	defer cancel()
+	ok, inFlight, err := l.redis.AcquireConcurrencySlot(ctx, fn.ID, invocationID, fn.MaxConcurrency, l.leaseTTL(fn))
+	if err != nil {
+		l.logger.WithError(err).WithField("function_id", fn.ID).Warn("Concurrency slot acquire failed, allowing invocation")
+		return func() {}, true
+	}
+	if !ok {
+		l.logger.WithFields(logrus.Fields{
+			"function_id":     fn.ID,
+			"invocation_id":   invocationID,
+			"in_flight":       inFlight,
+			"max_concurrency": fn.MaxConcurrency,
+		}).Debug("Function concurrency limit reached")
+		return nil, false
+	}
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), concurrencyRedisTimeout)
+		defer cancel()
+		if err := l.redis.ReleaseConcurrencySlot(ctx, fn.ID, invocationID); err != nil {
+			// 释放失败的槽位在租约过期后回收
+			l.logger.WithError(err).WithField("function_id", fn.ID).Warn("Concurrency slot release failed")
+		}
+	}, true
+}
+
+// leaseTTL 返回函数并发槽位的租约时长：函数超时加上宽限时间。
+func (l *concurrencyLimiter) leaseTTL(fn *domain.Function) time.Duration {
+	timeout := time.Duration(fn.TimeoutSec) * time.Second
+	if timeout <= 0 {
+		timeout = l.defaultTimeout
+	}
+	return timeout + concurrencyLeaseGrace
+}
+
+// queueWait 返回同步调用因等待并发槽位可能额外耗费的时间，调用方据此延长等待结果的超时。
+func (l *concurrencyLimiter) queueWait(fn *domain.Function) time.Duration {
+	if fn.MaxConcurrency <= 0 || l.redis == nil {
+		return 0
+	}
+	return l.queueTimeout
+}
+
+// shouldDelay 判断被限流的调用是否应稍后重试而不是立即拒绝，并记录限流指标。
+// 异步调用始终重试；同步调用仅在创建后未超过排队超时时重试。
+func (l *concurrencyLimiter) shouldDelay(inv *domain.Invocation, fn *domain.Function, sync bool) bool {
+	delay := !sync || time.Since(inv.CreatedAt) < l.queueTimeout
+	if l.metrics != nil {
+		result := concurrencyThrottleRejected
+		if delay {
+			result = concurrencyThrottleDelayed
+		}
+		l.metrics.RecordConcurrencyThrottle(fn.Name, result)
+	}
+	return delay
+}
+
+// saturated 判断函数当前执行中的调用数是否已达到最大并发数，不占用槽位。
+// 未设置最大并发数、未配置 Redis 或 Redis 出错时返回 false。
+func (l *concurrencyLimiter) saturated(fn *domain.Function) bool {
+	if fn.MaxConcurrency <= 0 || l.redis == nil {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), concurrencyRedisTimeout)
+	defer cancel()
+	inFlight, err := l.redis.CountConcurrencySlots(ctx, fn.ID)
+	if err != nil {
+		l.logger.WithError(err).WithField("function_id", fn.ID).Warn("Concurrency slot count failed, allowing invocation")
+		return false
+	}
+	return inFlight >= int64(fn.MaxConcurrency)
+}
@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+func TestConcurrencyLimiter(t *testing.T) {
+	l := newConcurrencyLimiter(nil, nil, logrus.New(), 30*time.Second, 2*time.Second)
+	fn := &domain.Function{ID: "fn", Name: "fn", MaxConcurrency: 1}
+
+	// 未配置 Redis 时不限制
+	release, ok := l.acquire(fn, "inv-1")
+	if !ok {
+		t.Fatal("acquire without redis should be allowed")
+	}
+	release()
+	if got := l.queueWait(fn); got != 0 {
+		t.Fatalf("queueWait without redis = %v, want 0", got)
+	}
+
+	if got := l.leaseTTL(fn); got != 30*time.Second+concurrencyLeaseGrace {
+		t.Fatalf("leaseTTL with default timeout = %v", got)
+	}
+	fn.TimeoutSec = 10
+	if got := l.leaseTTL(fn); got != 10*time.Second+concurrencyLeaseGrace {
+		t.Fatalf("leaseTTL = %v", got)
+	}
+
+	fresh := &domain.Invocation{CreatedAt: time.Now()}
+	stale := &domain.Invocation{CreatedAt: time.Now().Add(-3 * time.Second)}
+	if !l.shouldDelay(stale, fn, false) {
+		t.Fatal("async invocation should always be delayed")
+	}
+	if !l.shouldDelay(fresh, fn, true) {
+		t.Fatal("sync invocation within queue timeout should be delayed")
+	}
+	if l.shouldDelay(stale, fn, true) {
+		t.Fatal("sync invocation past queue timeout should be rejected")
+	}
+
+	// 排队超时为 0 时同步调用立即拒绝
+	l.queueTimeout = 0
+	if l.shouldDelay(fresh, fn, true) {
+		t.Fatal("sync invocation should be rejected without queue timeout")
+	}
+}
@@ -19,6 +19,7 @@ type CronManager struct {
 	logger   *logrus.Logger
 	mu       sync.Mutex
 	entries  map[string]cron.EntryID // functionID -> cronEntryID
+	isLeader func() bool             // 判断本实例是否负责触发定时任务，为 nil 时总是触发
 }
 
 // NewCronManager 创建一个新的 CronManager
@@ -41,6 +42,12 @@ func (cm *CronManager) Start() error {
 	return cm.ReloadAll()
 }
 
+// SetLeaderCheck 设置领导者判断函数。多副本部署时只有领导者实例触发定时任务，
+// 避免每个实例各触发一次。需在 Start 之前调用。
+func (cm *CronManager) SetLeaderCheck(isLeader func() bool) {
+	cm.isLeader = isLeader
+}
+
 // ReloadAll 从数据库重新加载所有定时任务
 func (cm *CronManager) ReloadAll() error {
 	cm.mu.Lock()
@@ -109,6 +116,9 @@ func (cm *CronManager) RemoveFunction(functionID string) {
 // 调用此方法前必须持有 cm.mu 锁
 func (cm *CronManager) addFunction(fn *domain.Function) {
 	entryID, err := cm.cron.AddFunc(fn.CronExpression, func() {
+		if cm.isLeader != nil && !cm.isLeader() {
+			return
+		}
 		cm.logger.WithFields(logrus.Fields{
 			"function_id":   fn.ID,
 			"function_name": fn.Name,
@@ -127,6 +137,7 @@ func (cm *CronManager) addFunction(fn *domain.Function) {
 			FunctionID: fn.ID,
 			Payload:    payloadBytes,
 			Async:      true,
+			Trigger:    domain.TriggerCron,
 		}
 
 		if _, err := cm.invoker(req); err != nil {
@@ -49,6 +49,27 @@ type LayerExecutor interface {
 	ExecuteWithLayers(ctx context.Context, fn *domain.Function, payload json.RawMessage, layers []domain.RuntimeLayerInfo) (*domain.InvokeResponse, error)
 }
 
+// DiagnosticExecutor 定义了支持执行环境诊断的执行器接口。
+type DiagnosticExecutor interface {
+	// Diagnose 在函数的执行容器中运行内置探针，返回实际执行环境信息。
+	Diagnose(ctx context.Context, fn *domain.Function, layers []domain.RuntimeLayerInfo) (*domain.FunctionDiagnostics, error)
+}
+
+// InstancePinExecutor 定义了支持实例亲和提示的执行器接口。
+type InstancePinExecutor interface {
+	// ReleaseInstancePin 将与亲和提示绑定的实例立即归还实例池。
+	ReleaseInstancePin(pin string)
+}
+
+// ExecOutputExecutor 定义了支持订阅调用实时输出的执行器接口。
+type ExecOutputExecutor interface {
+	// SubscribeExecOutput 订阅执行中调用的容器实时输出，调用结束时关闭通道。
+	SubscribeExecOutput(requestID string) (<-chan domain.ExecOutputChunk, func(), error)
+}
+
+// ErrDiagnosticsUnsupported 表示当前执行器不支持执行环境诊断
+var ErrDiagnosticsUnsupported = errors.New("execution environment diagnostics not supported")
+
 // DockerScheduler 是基于 Docker 容器的函数调度器。
 // 与 Scheduler 不同，它使用 Docker 容器而非 Firecracker 虚拟机来执行函数，
 // 适用于开发环境或不支持 Firecracker 的平台（如 macOS、Windows）。
@@ -60,8 +81,20 @@ type DockerScheduler struct {
 	metrics  *metrics.Metrics         // 指标收集器，用于记录调度器性能指标
 	logger   *logrus.Logger           // 日志记录器
 
-	workQueue chan *dockerWorkItem    // 工作队列，存放待处理的调用请求
-	wg        sync.WaitGroup          // 等待组，用于优雅关闭时等待所有工作协程完成
+	initFailures *initFailureTracker    // 连续初始化失败跟踪器，用于标记 degraded 函数
+	breakers     *circuitBreakerTracker // 函数级熔断跟踪器，错误率过高时下线函数
+	shadow       *shadowMirror          // 影子流量回放器
+	retrier      *platformRetrier       // 瞬时平台故障重试器
+	reservations *reservationTracker    // 函数预留并发跟踪器
+	concurrency  *concurrencyLimiter    // 函数最大并发数限制器
+	load         *runtimeLoadTracker    // 按运行时统计的排队与执行中调用数
+	keepWarm     *keepWarmReconciler    // 常驻预热协调器，执行器不支持常驻预热时为 nil
+	router       *TrafficRouter         // 别名流量路由器，与 Firecracker 调度器共用实现
+
+	workQueue   *priorityQueue[*dockerWorkItem] // 工作队列，按调用优先级分道存放待处理的调用请求
+	slotWaiters *slotWaitlist[*dockerWorkItem]  // 已出队但等待并发槽位的工作项
+	workers     *workerPool                     // 工作协程池，支持运行时扩缩容
+	wg          sync.WaitGroup                  // 等待组，用于优雅关闭时等待所有工作协程完成
 
 	ctx    context.Context            // 调度器上下文，用于控制生命周期
 	cancel context.CancelFunc         // 取消函数，用于停止调度器
@@ -73,6 +106,10 @@ type dockerWorkItem struct {
 	invocation *domain.Invocation              // 调用记录，包含调用ID、输入参数等
 	function   *domain.Function                // 函数定义，包含运行时、处理器、超时配置等
 	resultCh   chan *domain.InvokeResponse     // 结果通道，用于同步调用时返回执行结果；异步调用时为 nil
+	layers     []domain.LayerOverride          // 调用时覆盖的层，非 nil 时替代函数配置的层
+	pin        string                          // 实例亲和提示，相同提示的调用优先复用同一个预热容器
+	priority   domain.InvocationPriority       // 调度优先级，由触发来源和函数配置决定
+	output     func(domain.ExecOutputChunk)    // 流式调用的输出接收函数，nil 表示不转发
 }
 
 // NewDockerScheduler 创建一个新的基于 Docker 的函数调度器实例。
@@ -98,17 +135,33 @@ func NewDockerScheduler(
 	// 创建可取消的上下文，用于控制调度器的生命周期
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &DockerScheduler{
-		cfg:       cfg,
-		store:     store,
-		redis:     redis,
-		executor:  executor,
-		metrics:   m,
-		logger:    logger,
-		workQueue: make(chan *dockerWorkItem, cfg.QueueSize), // 创建带缓冲的工作队列
-		ctx:       ctx,
-		cancel:    cancel,
+	s := &DockerScheduler{
+		cfg:          cfg,
+		store:        store,
+		redis:        redis,
+		executor:     executor,
+		metrics:      m,
+		logger:       logger,
+		initFailures: newInitFailureTracker(cfg.InitFailureThreshold, store, logger),
+		breakers:     newCircuitBreakerTracker(store, redis, logger),
+		router:       NewTrafficRouter(store, logger),
+		shadow:       newShadowMirror(cfg, store, m, logger),
+		retrier:      newPlatformRetrier(cfg.PlatformRetries, cfg.PlatformRetryBackoff, cfg.PlatformRetryRate, m),
+		workQueue:    newPriorityQueue[*dockerWorkItem](cfg.QueueSize), // 创建按优先级分道的工作队列
+		slotWaiters:  newSlotWaitlist[*dockerWorkItem](cfg.QueueSize),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+	s.workers = newWorkerPool(&s.wg, m, s.worker)
+	// 并发总容量等于工作协程数量，预留槽位从中扣除
+	s.reservations = newReservationTracker(store, s.workers.size, logger)
+	s.concurrency = newConcurrencyLimiter(redis, m, logger, cfg.DefaultTimeout, cfg.ConcurrencyQueueTimeout)
+	s.load = newRuntimeLoadTracker()
+	if keeper, ok := executor.(WarmKeeper); ok && store != nil {
+		s.keepWarm = newKeepWarmReconciler(store, keeper, logger)
 	}
+
+	return s
 }
 
 // Start 启动 Docker 调度器，开始处理函数调用请求。
@@ -118,19 +171,72 @@ func NewDockerScheduler(
 //   - error: 启动过程中的错误，当前实现始终返回 nil
 func (s *DockerScheduler) Start() error {
 	// 启动指定数量的工作协程
-	for i := 0; i < s.cfg.Workers; i++ {
-		s.wg.Add(1)
-		go s.worker(i) // 每个工作协程使用唯一的ID
-	}
-	// 如果启用了指标收集，初始化工作协程数量指标并启动指标上报协程
+	s.workers.scale(s.cfg.Workers)
+	// 如果启用了指标收集，启动指标上报协程
 	if s.metrics != nil {
-		s.metrics.SchedulerWorkers.Set(float64(s.cfg.Workers))
 		go s.metricsWorker()
 	}
+	// 常驻预热、空闲回收和镜像检查管理的是本实例的容器和镜像，每个实例各自运行，不参与领导者选举
+	// 执行器支持常驻预热时，启动 keep_warm 协调协程
+	if s.keepWarm != nil {
+		go s.keepWarm.run(s.ctx)
+	}
+	// 执行器支持空闲回收时，启动空闲预热容器回收协程
+	if reaper, ok := s.executor.(IdleReaper); ok {
+		go runIdleReaper(s.ctx, reaper)
+	}
+	// 检查运行时镜像是否存在，缺失时仅告警并通过 MissingImages 报告给就绪探针
+	if checker, ok := s.executor.(ImageChecker); ok {
+		checkImagesOnStart(s.ctx, checker)
+	}
 	s.logger.WithField("workers", s.cfg.Workers).Info("Docker scheduler started")
 	return nil
 }
 
+// ScaleWorkers 在运行时调整工作协程数量。
+// 缩容时多余的协程会在处理完当前任务后退出。
+//
+// 参数:
+//   - n: 目标工作协程数量，必须在 [1, MaxWorkers] 范围内
+//
+// 返回值:
+//   - error: 数量超出范围时返回 ErrInvalidWorkerCount，
+//     低于函数预留并发总和时返回 domain.ErrReservedConcurrencyExceedsCapacity
+func (s *DockerScheduler) ScaleWorkers(n int) error {
+	if err := validateWorkerCount(n, s.cfg.MaxWorkers); err != nil {
+		return err
+	}
+	if n < s.reservations.reservedTotalNow() {
+		return domain.ErrReservedConcurrencyExceedsCapacity
+	}
+	previous := s.workers.size()
+	s.workers.scale(n)
+	s.logger.WithFields(logrus.Fields{
+		"previous": previous,
+		"workers":  n,
+	}).Info("Docker scheduler workers scaled")
+	return nil
+}
+
+// WorkerStats 返回工作协程池和队列的当前状态。
+func (s *DockerScheduler) WorkerStats() WorkerStats {
+	return WorkerStats{
+		ConfiguredWorkers: s.workers.size(),
+		ActiveWorkers:     s.workers.active(),
+		MaxWorkers:        s.cfg.MaxWorkers,
+		QueueLength:       s.workQueue.len(),
+		QueueCap:          s.workQueue.cap(),
+		QueueByPriority:   s.workQueue.depthByPriority(),
+		QueueBySource:     s.workQueue.depthBySource(),
+		ReservationStats:  s.reservations.stats(),
+	}
+}
+
+// ScalingMetrics 返回供外部自动扩缩容器使用的负载信号（排队与执行中的调用数及其与并发容量之比）。
+func (s *DockerScheduler) ScalingMetrics() ScalingMetrics {
+	return s.load.scalingMetrics(s.workers.size())
+}
+
 // metricsWorker 定期收集并上报调度器队列大小指标。
 // 该方法在独立的协程中运行，每秒更新一次队列大小。
 func (s *DockerScheduler) metricsWorker() {
@@ -143,8 +249,9 @@ func (s *DockerScheduler) metricsWorker() {
 			// 调度器停止，退出指标收集循环
 			return
 		case <-ticker.C:
-			// 更新队列大小指标
-			s.metrics.SchedulerQueueSize.Set(float64(len(s.workQueue)))
+			// 更新队列大小和各触发来源的排队深度指标
+			s.workQueue.reportDepth(s.metrics)
+			s.load.report(s.metrics)
 		}
 	}
 }
@@ -159,12 +266,15 @@ func (s *DockerScheduler) metricsWorker() {
 // 返回值:
 //   - error: 停止过程中的错误，当前实现始终返回 nil
 func (s *DockerScheduler) Stop() error {
+	s.workers.close()   // 停止接受扩容请求
 	s.cancel()          // 发送取消信号
-	close(s.workQueue)  // 关闭工作队列，通知工作协程退出
+	s.workQueue.close() // 关闭工作队列，通知工作协程退出
 	s.wg.Wait()         // 等待所有工作协程完成
+	s.shadow.close()    // 关闭影子回放队列
 	// 重置指标
 	if s.metrics != nil {
 		s.metrics.SchedulerQueueSize.Set(0)
+		s.metrics.SchedulerQueueDepth.Reset()
 		s.metrics.SchedulerWorkers.Set(0)
 	}
 	s.logger.Info("Docker scheduler stopped")
@@ -185,47 +295,94 @@ func (s *DockerScheduler) Stop() error {
 //
 // 返回值:
 //   - *domain.InvokeResponse: 函数执行结果，包含状态码、响应体、执行时间等
-//   - error: 调用过程中的错误，如函数不存在、队列已满等
+//   - error: 调用过程中的错误，如函数不存在、队列已满等；
+//     函数处于维护窗口内时返回 *domain.MaintenanceError；
+//     紧急停止开关开启时返回 *domain.KillSwitchError
 func (s *DockerScheduler) Invoke(req *domain.InvokeRequest) (*domain.InvokeResponse, error) {
+	// 紧急停止开关开启时立即拒绝调用
+	if err := checkKillSwitch(s.redis, s.metrics, s.logger, req.FunctionID); err != nil {
+		return nil, err
+	}
+
 	// 从存储中获取函数定义
 	fn, err := s.store.GetFunctionByID(req.FunctionID)
 	if err != nil {
 		return nil, err
 	}
+	// 按调用所在环境覆盖内存、超时和环境变量
+	fn.ApplyEnvConfig(req.EnvConfig)
+
+	// 拦截疑似无限递归的嵌套调用
+	if err := checkRecursion(s.cfg, s.metrics, s.logger, fn, req.CallChain); err != nil {
+		return nil, err
+	}
+
+	// 指定版本时使用版本快照中的代码（如影子流量回放到指定版本）
+	version, err := applyRequestedVersion(s.store.GetFunctionVersion, fn, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 未指定版本时按别名或蓝绿槽位选择版本
+	slot := ""
+	if version == 0 {
+		version, slot, err = applyAliasRouting(s.router, s.store.GetFunctionVersion, s.logger, fn, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// 未选中版本快照时执行函数当前代码，记录为函数当前版本
+	if version == 0 {
+		version = fn.Version
+	}
 
 	// 创建调用记录，用于追踪调用状态和持久化
-	inv := domain.NewInvocation(fn.ID, fn.Name, domain.TriggerHTTP, req.Payload)
+	inv := domain.NewInvocation(fn.ID, fn.Name, req.TriggerSource(), req.EventPayload())
 	inv.ID = uuid.New().String()
+	inv.CostTags = req.CostTags
+	inv.Pipe = req.Pipe
+	inv.CallChain = req.CallChain
+	inv.WorkflowExecutionID = req.WorkflowExecutionID
+	inv.WorkflowState = req.WorkflowState
+	inv.Version = version
+	inv.AliasUsed = slot
 
 	// 持久化调用记录
 	if err := s.store.CreateInvocation(inv); err != nil {
 		return nil, fmt.Errorf("failed to create invocation: %w", err)
 	}
 
+	// 处于维护窗口内时不执行，记录为 skipped 并返回维护错误
+	if merr := domain.CheckMaintenance(fn, time.Now()); merr != nil {
+		skipForMaintenance(s.store, s.logger, inv, merr)
+		return nil, merr
+	}
+
 	// 创建工作项，包含结果通道用于接收执行结果
 	resultCh := make(chan *domain.InvokeResponse, 1)
 	item := &dockerWorkItem{
 		invocation: inv,
 		function:   fn,
 		resultCh:   resultCh,
+		layers:     req.Layers,
+		pin:        req.InstancePin,
+		priority:   domain.PriorityFor(fn, inv.TriggerType, true),
+		output:     req.OutputSink,
 	}
 
-	// 非阻塞方式提交工作项到队列
-	select {
-	case s.workQueue <- item:
-		// 成功提交到队列
-	default:
-		// 队列已满，返回错误
+	// 非阻塞方式提交工作项到队列，对应优先级的队列已满时返回错误
+	if !s.enqueue(item) {
 		return nil, fmt.Errorf("work queue is full")
 	}
 
-	// 计算超时时间：函数配置的超时 + 5秒缓冲
-	timeout := time.Duration(fn.TimeoutSec)*time.Second + 5*time.Second
+	// 计算超时时间：函数配置的超时 + 5秒缓冲 + 等待并发槽位的时间
+	timeout := time.Duration(fn.TimeoutSec)*time.Second + 5*time.Second + s.concurrency.queueWait(fn)
 
 	// 等待执行结果或超时
 	select {
 	case resp := <-resultCh:
-		// 成功获取执行结果
+		// 成功获取执行结果，按配置将请求回放到影子目标
+		s.shadow.mirror(fn, req, resp, s.Invoke)
 		return resp, nil
 	case <-time.After(timeout):
 		// 超时处理：更新调用状态并返回超时响应
@@ -254,16 +411,46 @@ func (s *DockerScheduler) Invoke(req *domain.InvokeRequest) (*domain.InvokeRespo
 // 返回值:
 //   - string: 调用ID，可用于后续查询调用状态和结果
 //   - error: 调用过程中的错误，如函数不存在、队列和Redis都不可用等
+//
+// 函数处于维护窗口内时，调用记录保持 pending，待窗口结束后再提交到工作队列。
 func (s *DockerScheduler) InvokeAsync(req *domain.InvokeRequest) (string, error) {
+	// 紧急停止开关开启时立即拒绝调用
+	if err := checkKillSwitch(s.redis, s.metrics, s.logger, req.FunctionID); err != nil {
+		return "", err
+	}
+
 	// 从存储中获取函数定义
 	fn, err := s.store.GetFunctionByID(req.FunctionID)
 	if err != nil {
 		return "", err
 	}
+	// 按调用所在环境覆盖内存、超时和环境变量
+	fn.ApplyEnvConfig(req.EnvConfig)
+
+	// 拦截疑似无限递归的嵌套调用
+	if err := checkRecursion(s.cfg, s.metrics, s.logger, fn, req.CallChain); err != nil {
+		return "", err
+	}
+
+	// 按别名或蓝绿槽位选择版本
+	version, slot, err := applyAliasRouting(s.router, s.store.GetFunctionVersion, s.logger, fn, req)
+	if err != nil {
+		return "", err
+	}
+	if version == 0 {
+		version = fn.Version
+	}
 
 	// 创建调用记录
-	inv := domain.NewInvocation(fn.ID, fn.Name, domain.TriggerHTTP, req.Payload)
+	inv := domain.NewInvocation(fn.ID, fn.Name, req.TriggerSource(), req.EventPayload())
 	inv.ID = uuid.New().String()
+	inv.CostTags = req.CostTags
+	inv.Pipe = req.Pipe
+	inv.CallChain = req.CallChain
+	inv.WorkflowExecutionID = req.WorkflowExecutionID
+	inv.WorkflowState = req.WorkflowState
+	inv.Version = version
+	inv.AliasUsed = slot
 
 	// 持久化调用记录
 	if err := s.store.CreateInvocation(inv); err != nil {
@@ -275,45 +462,190 @@ func (s *DockerScheduler) InvokeAsync(req *domain.InvokeRequest) (string, error)
 		invocation: inv,
 		function:   fn,
 		resultCh:   nil, // 异步调用不需要等待结果
+		priority:   domain.PriorityFor(fn, inv.TriggerType, false),
 	}
 
-	// 尝试提交到工作队列
-	select {
-	case s.workQueue <- item:
-		// 成功提交到队列
-		return inv.ID, nil
-	default:
-		// 队列已满，将调用ID推送到Redis作为备用队列
-		// 后续可由其他工作进程从Redis拉取并处理
-		if err := s.redis.PushInvocation(context.Background(), inv.ID); err != nil {
-			return "", fmt.Errorf("queue full and redis push failed: %w", err)
+	// 函数已暂停时放入暂停队列，恢复后再执行
+	if fn.Status == domain.FunctionStatusPaused {
+		if err := holdPausedInvocation(s.redis, s.store, s.logger, inv); err != nil {
+			return "", err
 		}
 		return inv.ID, nil
 	}
+
+	// 处于维护窗口内时延迟到窗口结束后再提交
+	if merr := domain.CheckMaintenance(fn, time.Now()); merr != nil {
+		deferUntilMaintenanceEnds(s.ctx, s.store, s.logger, inv, merr, func() {
+			s.enqueueAsync(item)
+		})
+		return inv.ID, nil
+	}
+
+	if err := s.enqueueAsync(item); err != nil {
+		return "", err
+	}
+	return inv.ID, nil
+}
+
+// ResumePaused 在后台按入队顺序提交函数暂停期间积压的异步调用。
+// 工作队列已满时等待空位，不会推送到 Redis 备用队列。
+//
+// 参数:
+//   - functionID: 已恢复的函数 ID
+func (s *DockerScheduler) ResumePaused(functionID string) {
+	if s.redis == nil {
+		return
+	}
+	go drainPausedInvocations(s.ctx, s.redis, s.store, s.logger, functionID, func(inv *domain.Invocation, fn *domain.Function) bool {
+		item := &dockerWorkItem{invocation: inv, function: fn, priority: domain.PriorityFor(fn, inv.TriggerType, false)}
+		return submitWhenReady(s.ctx, func() bool {
+			return s.enqueue(item)
+		})
+	})
+}
+
+// enqueueAsync 将异步调用提交到工作队列，队列已满时推送到 Redis 备用队列。
+// 两者都不可用时将调用标记为失败并返回 domain.ErrAsyncQueueUnavailable。
+func (s *DockerScheduler) enqueueAsync(item *dockerWorkItem) error {
+	inv := item.invocation
+	if s.enqueue(item) {
+		// 成功提交到队列
+		return nil
+	}
+	// 队列已满，将调用ID推送到Redis作为备用队列
+	// 后续可由其他工作进程从Redis拉取并处理
+	if err := pushOverflowInvocation(s.redis, inv.ID); err != nil {
+		rejectAsyncInvocation(s.store, s.logger, inv, "async queue", err)
+		return fmt.Errorf("%w: queue full and redis push failed: %v", domain.ErrAsyncQueueUnavailable, err)
+	}
+	return nil
+}
+
+// enqueue 以非阻塞方式将工作项提交到对应优先级的工作队列，队列已满时返回 false。
+func (s *DockerScheduler) enqueue(item *dockerWorkItem) bool {
+	if !s.workQueue.push(item.priority, item.invocation.TriggerType, item) {
+		return false
+	}
+	s.load.enqueued(string(item.function.Runtime))
+	return true
 }
 
 // worker 是 Docker 调度器的工作协程主循环。
-// 它持续从工作队列获取任务并处理，直到调度器停止或队列关闭。
+// 它持续从工作队列获取任务并处理，直到调度器停止、队列关闭或协程被缩容。
 //
 // 参数:
 //   - id: 工作协程的唯一标识符，用于日志和追踪
-func (s *DockerScheduler) worker(id int) {
-	defer s.wg.Done() // 协程退出时通知等待组
+//   - stop: 缩容时关闭的停止通道
+func (s *DockerScheduler) worker(id int, stop <-chan struct{}) {
+	for {
+		// 按优先级取出工作项；收到停止信号、协程被缩容或工作队列已关闭时退出循环
+		// 取出的工作项已占用并发槽位（预留容量优先）
+		item, release, ok := s.nextItem(stop)
+		if !ok {
+			return
+		}
+		runtime := string(item.function.Runtime)
+		// 占用函数自身的并发槽位，超出最大并发数时限流
+		releaseConcurrency, ok := s.concurrency.acquire(item.function, item.invocation.ID)
+		if !ok {
+			release()
+			s.throttleConcurrency(item)
+			continue
+		}
+		// 处理工作项
+		s.workers.markBusy()
+		s.load.started(runtime)
+		s.processItem(id, item)
+		s.load.finished(runtime)
+		s.workers.markIdle()
+		releaseConcurrency()
+		release()
+	}
+}
 
+// nextItem 取出下一个可以执行的工作项，并为其占用一个并发槽位。
+// 优先执行等待列表中已能获得槽位的工作项；从工作队列取出的工作项没有可用槽位时放入等待列表，
+// 继续取下一个工作项，使共享槽位占满时预留了并发的函数仍能被调度。
+// 收到停止信号、协程被缩容或工作队列已关闭时返回 false。
+func (s *DockerScheduler) nextItem(stop <-chan struct{}) (*dockerWorkItem, func(), bool) {
 	for {
-		select {
-		case <-s.ctx.Done():
-			// 收到停止信号，退出循环
+		if item, release, ok := s.slotWaiters.take(s.acquireSlot); ok {
+			s.load.dequeued(string(item.function.Runtime))
+			return item, release, true
+		}
+		item, ok := s.workQueue.pop(s.ctx.Done(), stop)
+		if !ok {
+			return nil, nil, false
+		}
+		if release, ok := s.acquireSlot(item); ok {
+			s.load.dequeued(string(item.function.Runtime))
+			return item, release, true
+		}
+		if !s.slotWaiters.park(item) {
+			s.load.dequeued(string(item.function.Runtime))
+			s.rejectNoSlot(item)
+		}
+	}
+}
+
+// acquireSlot 为工作项的函数占用一个并发槽位，预留容量优先。
+func (s *DockerScheduler) acquireSlot(item *dockerWorkItem) (func(), bool) {
+	return s.reservations.acquire(item.function.ID, item.function.ReservedConcurrency)
+}
+
+// rejectNoSlot 处理等待列表已满、无法继续等待并发槽位的工作项。
+// 同步调用返回 429；异步调用转入 Redis 溢出队列，Redis 不可用时标记为失败。
+func (s *DockerScheduler) rejectNoSlot(item *dockerWorkItem) {
+	if item.resultCh != nil {
+		s.throttle(item)
+		return
+	}
+	if err := pushOverflowInvocation(s.redis, item.invocation.ID); err != nil {
+		rejectAsyncInvocation(s.store, s.logger, item.invocation, "async queue", err)
+	}
+}
+
+// throttle 处理因达到并发上限而无法执行的工作项。
+// 同步调用立即返回 429；异步调用在短暂延迟后重新入队。
+func (s *DockerScheduler) throttle(item *dockerWorkItem) {
+	inv := item.invocation
+	if item.resultCh != nil {
+		inv.Fail(domain.ErrConcurrencyLimitExceeded.Error())
+		s.store.UpdateInvocation(inv)
+		item.resultCh <- &domain.InvokeResponse{
+			RequestID:  inv.ID,
+			StatusCode: 429, // Too Many Requests
+			Error:      domain.ErrConcurrencyLimitExceeded.Error(),
+		}
+		return
+	}
+
+	time.AfterFunc(throttledRetryDelay, func() {
+		if s.ctx.Err() != nil || s.enqueue(item) {
 			return
-		case item, ok := <-s.workQueue:
-			if !ok {
-				// 工作队列已关闭，退出循环
-				return
-			}
-			// 处理工作项
-			s.processItem(id, item)
 		}
+		if err := pushOverflowInvocation(s.redis, inv.ID); err != nil {
+			rejectAsyncInvocation(s.store, s.logger, inv, "async queue", err)
+		}
+	})
+}
+
+// throttleConcurrency 处理因函数达到最大并发数而无法执行的工作项。
+// 异步调用和仍在排队超时内的同步调用在短暂延迟后重新入队；其他同步调用返回 429。
+func (s *DockerScheduler) throttleConcurrency(item *dockerWorkItem) {
+	delay := s.concurrency.shouldDelay(item.invocation, item.function, item.resultCh != nil)
+	if !delay || item.resultCh == nil {
+		// 同步调用返回 429，异步调用由 throttle 延迟重新入队
+		s.throttle(item)
+		return
 	}
+	// 排队中的同步调用重新入队失败（队列已满）时返回 429
+	time.AfterFunc(throttledRetryDelay, func() {
+		if s.ctx.Err() != nil || s.enqueue(item) {
+			return
+		}
+		s.throttle(item)
+	})
 }
 
 // processItem 处理单个工作项，执行函数调用的完整流程。
@@ -358,55 +690,56 @@ func (s *DockerScheduler) processItem(workerID int, item *dockerWorkItem) {
 	s.store.UpdateInvocation(inv)
 	span.AddEvent("invocation.started")
 
-	// 获取函数关联的层
-	functionLayers, err := s.store.GetFunctionLayers(fn.ID)
-	if err != nil {
-		logger.WithError(err).Warn("Failed to get function layers")
-		functionLayers = nil
-	}
-
-	// 获取每个层的内容
+	// 获取函数关联的层及其内容，调用时指定了层时只加载指定的层
 	var layerInfos []domain.RuntimeLayerInfo
-	for _, fl := range functionLayers {
-		content, err := s.store.GetLayerVersionContent(fl.LayerID, fl.LayerVersion)
-		if err != nil {
-			logger.WithError(err).WithFields(logrus.Fields{
-				"layer_id":      fl.LayerID,
-				"layer_version": fl.LayerVersion,
-			}).Error("Failed to get layer content")
-			continue
-		}
-		layerInfos = append(layerInfos, domain.RuntimeLayerInfo{
-			LayerID: fl.LayerID,
-			Version: fl.LayerVersion,
-			Content: content,
-			Order:   fl.Order,
-		})
-		logger.WithFields(logrus.Fields{
-			"layer_id":      fl.LayerID,
-			"layer_version": fl.LayerVersion,
-			"layer_size":    len(content),
-		}).Debug("Layer content loaded")
+	if item.layers != nil {
+		layerInfos = s.loadLayerOverrides(item.layers, logger)
+	} else {
+		layerInfos = s.loadLayers(fn.ID, logger)
 	}
 
 	// 创建带函数超时的执行上下文
 	execCtx, cancel := context.WithTimeout(ctx, time.Duration(fn.TimeoutSec)*time.Second)
 	defer cancel()
+	// 函数通过 stderr 进度帧上报的进度记录到调用记录上
+	execCtx = domain.WithProgressReporter(execCtx, newProgressReporter(s.store, inv, logger))
+	// 执行器据此发布调用的实时输出
+	execCtx = domain.WithInvocationID(execCtx, inv.ID)
+	// 执行器据此向函数环境注入调用链，函数嵌套调用时原样传回
+	execCtx = domain.WithCallChain(execCtx, executionCallChain(inv))
+	if item.pin != "" {
+		execCtx = domain.WithInstancePin(execCtx, item.pin)
+	}
+	if item.output != nil {
+		execCtx = domain.WithOutputSink(execCtx, item.output)
+	}
+	// 执行器据此上报函数代码是否已开始运行，已开始运行的失败不透明重试
+	tracker := &domain.ExecutionTracker{}
+	execCtx = domain.WithExecutionTracker(execCtx, tracker)
 
 	// 通过 Docker 执行器执行函数
 	span.AddEvent("execution.start")
 
 	var resp *domain.InvokeResponse
-	// 如果有层且执行器支持层，使用 ExecuteWithLayers
-	if len(layerInfos) > 0 {
-		if layerExec, ok := s.executor.(LayerExecutor); ok {
-			resp, err = layerExec.ExecuteWithLayers(execCtx, fn, inv.Input, layerInfos)
+	// 获取容器超时、容器启动失败等瞬时平台故障透明重试，函数代码开始运行后的任何失败都不重试
+	retries, err := s.retrier.do(execCtx, string(fn.Runtime), logger, tracker, func() error {
+		var err error
+		// 如果有层且执行器支持层，使用 ExecuteWithLayers
+		if len(layerInfos) > 0 {
+			if layerExec, ok := s.executor.(LayerExecutor); ok {
+				resp, err = layerExec.ExecuteWithLayers(execCtx, fn, inv.Input, layerInfos)
+			} else {
+				logger.Warn("Executor does not support layers, executing without layers")
+				resp, err = s.executor.Execute(execCtx, fn, inv.Input)
+			}
 		} else {
-			logger.Warn("Executor does not support layers, executing without layers")
 			resp, err = s.executor.Execute(execCtx, fn, inv.Input)
 		}
-	} else {
-		resp, err = s.executor.Execute(execCtx, fn, inv.Input)
+		return err
+	})
+	inv.RetryCount = retries
+	if retries > 0 {
+		span.SetAttributes(attribute.Int("invocation.platform_retries", retries))
 	}
 
 	if err != nil {
@@ -418,11 +751,17 @@ func (s *DockerScheduler) processItem(workerID int, item *dockerWorkItem) {
 		// 区分超时错误和其他错误
 		statusCode := 500
 		errType := "executor_error"
-		if errors.Is(err, context.DeadlineExceeded) {
+		errMsg := fmt.Sprintf("execution failed: %v", err)
+		if errors.Is(err, domain.ErrQueueTimeout) {
+			// 排队超时：未能在限定时间内获取执行实例，快速失败以便调用方退避
+			statusCode = 503 // Service Unavailable
+			errType = "queue_timeout"
+			errMsg = err.Error()
+		} else if errors.Is(err, context.DeadlineExceeded) {
 			statusCode = 504 // Gateway Timeout
 			errType = "timeout"
 		}
-		s.fail(item, fmt.Sprintf("execution failed: %v", err), statusCode, errType)
+		s.fail(item, errMsg, statusCode, errType)
 		return
 	}
 	span.AddEvent("execution.complete")
@@ -438,10 +777,22 @@ func (s *DockerScheduler) processItem(workerID int, item *dockerWorkItem) {
 		attribute.Int64("invocation.duration_ms", resp.DurationMs),
 	)
 
+	// 拆分函数返回的响应信封：result 作为响应体，meta 只记录在调用记录上
+	if resp.StatusCode == 200 {
+		resp.Body, resp.Meta = domain.ExtractInvocationMeta(resp.Body)
+		inv.Meta = resp.Meta
+	}
+	// 读取并移除函数下发的 X-Nimbus-* 平台指令，避免透传给调用方
+	resp.Body, resp.Directives = domain.ExtractResponseDirectives(resp.Body)
+	// 保存函数写入 /output 的产物文件
+	saveInvocationArtifacts(s.store, inv, resp, logger)
+
 	// 更新调用记录
 	if resp.StatusCode == 200 {
 		// 函数执行成功
 		inv.Complete(resp.Body, 0)
+		s.initFailures.recordSuccess(fn)
+		s.breakers.record(fn, false)
 	} else {
 		// 函数执行返回错误，记录详细日志
 		logger.WithFields(logrus.Fields{
@@ -452,32 +803,247 @@ func (s *DockerScheduler) processItem(workerID int, item *dockerWorkItem) {
 			"function_id":  fn.ID,
 			"function_name": fn.Name,
 		}).Error("Function returned error status")
-		inv.Fail(resp.Error)
+		if resp.ErrorType == domain.InvokeErrorTypeTimeout {
+			// 函数挂起未在超时时间内返回，执行实例已被销毁；超时前的输出作为不完整的 output 记录
+			inv.TimeoutWithPartialOutput(resp.PartialOutput)
+		} else {
+			inv.Fail(resp.Error)
+		}
+		if resp.ErrorType == domain.InvokeErrorTypeInit {
+			s.initFailures.recordFailure(fn, resp.Error)
+		}
+		s.breakers.record(fn, true)
 	}
 	inv.DurationMs = resp.DurationMs
+	inv.QueueWaitMs = resp.QueueWaitMs
 	inv.BilledTimeMs = resp.BilledTimeMs
 	s.store.UpdateInvocation(inv)
 
-	// 记录调用指标
-	if s.metrics != nil {
+	// 记录调用指标，影子流量回放只记录对比结果，不计入调用指标
+	if s.metrics != nil && inv.TriggerType != domain.TriggerShadow {
 		statusStr := strconv.Itoa(resp.StatusCode)
 		s.metrics.RecordInvocation(fn.ID, fn.Name, string(fn.Runtime), statusStr, float64(resp.DurationMs), inv.ColdStart)
 		// 记录非 2xx 状态码的错误
 		if resp.StatusCode < 200 || resp.StatusCode > 299 {
-			s.metrics.RecordError(fn.ID, fn.Name, "function_error")
+			errType := "function_error"
+			if resp.ErrorType != "" {
+				errType = resp.ErrorType
+			}
+			s.metrics.RecordError(fn.ID, fn.Name, errType)
 		}
 	}
 
 	// 如果是同步调用，通过结果通道返回响应
 	if item.resultCh != nil {
 		resp.RequestID = inv.ID
+		resp.Version = inv.Version
+		resp.AliasUsed = inv.AliasUsed
+		resp.RoutedVersion = routedVersion(inv)
 		item.resultCh <- resp
 	}
 
-	logger.WithFields(logrus.Fields{
+	completed := logger.WithFields(logrus.Fields{
 		"duration_ms": resp.DurationMs,
 		"status_code": resp.StatusCode,
-	}).Info("Invocation completed")
+	})
+	if len(resp.Meta) > 0 {
+		completed = completed.WithField("meta", string(resp.Meta))
+	}
+	completed.Info("Invocation completed")
+}
+
+// loadLayers 加载函数关联的层及其内容。
+// 单个层加载失败时记录日志并跳过，不影响其他层。
+func (s *DockerScheduler) loadLayers(functionID string, logger *logrus.Entry) []domain.RuntimeLayerInfo {
+	functionLayers, err := s.store.GetFunctionLayers(functionID)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get function layers")
+		return nil
+	}
+
+	var layerInfos []domain.RuntimeLayerInfo
+	for _, fl := range functionLayers {
+		content, err := s.store.GetLayerVersionContent(fl.LayerID, fl.LayerVersion)
+		if err != nil {
+			logger.WithError(err).WithFields(logrus.Fields{
+				"layer_id":      fl.LayerID,
+				"layer_version": fl.LayerVersion,
+			}).Error("Failed to get layer content")
+			continue
+		}
+		layerInfos = append(layerInfos, domain.RuntimeLayerInfo{
+			LayerID: fl.LayerID,
+			Version: fl.LayerVersion,
+			Content: content,
+			Order:   fl.Order,
+		})
+		logger.WithFields(logrus.Fields{
+			"layer_id":      fl.LayerID,
+			"layer_version": fl.LayerVersion,
+			"layer_size":    len(content),
+		}).Debug("Layer content loaded")
+	}
+	return layerInfos
+}
+
+// loadLayerOverrides 加载调用时指定的层版本内容，加载顺序与指定顺序一致。
+// 单个层加载失败时记录日志并跳过。
+func (s *DockerScheduler) loadLayerOverrides(overrides []domain.LayerOverride, logger *logrus.Entry) []domain.RuntimeLayerInfo {
+	layerInfos := make([]domain.RuntimeLayerInfo, 0, len(overrides))
+	for i, o := range overrides {
+		content, err := s.store.GetLayerVersionContent(o.Layer, o.Version)
+		if err != nil {
+			logger.WithError(err).WithFields(logrus.Fields{
+				"layer_id":      o.Layer,
+				"layer_version": o.Version,
+			}).Error("Failed to get override layer content")
+			continue
+		}
+		layerInfos = append(layerInfos, domain.RuntimeLayerInfo{
+			LayerID: o.Layer,
+			Version: o.Version,
+			Content: content,
+			Order:   i,
+		})
+	}
+	logger.WithField("layers", overrides).Info("Using layer override for invocation")
+	return layerInfos
+}
+
+// SubscribeExecOutput 订阅执行中调用的容器实时输出（stdout/stderr）。
+//
+// 参数:
+//   - requestID: 调用 ID
+//
+// 返回值:
+//   - <-chan domain.ExecOutputChunk: 输出通道，调用结束时关闭
+//   - func(): 取消订阅函数
+//   - error: 调用未在本节点执行中或执行器不支持时返回 domain.ErrExecOutputUnavailable
+func (s *DockerScheduler) SubscribeExecOutput(requestID string) (<-chan domain.ExecOutputChunk, func(), error) {
+	streamer, ok := s.executor.(ExecOutputExecutor)
+	if !ok {
+		return nil, nil, domain.ErrExecOutputUnavailable
+	}
+	return streamer.SubscribeExecOutput(requestID)
+}
+
+// ReleaseInstancePin 将与实例亲和提示绑定的预热容器立即归还容器池。
+// 执行器不支持实例亲和时不做任何操作。
+//
+// 参数:
+//   - pin: 实例亲和提示
+func (s *DockerScheduler) ReleaseInstancePin(pin string) {
+	if pinner, ok := s.executor.(InstancePinExecutor); ok {
+		pinner.ReleaseInstancePin(pin)
+	}
+}
+
+// Diagnose 在函数的执行容器中运行内置诊断探针，返回实际执行环境信息。
+// 探针与函数调用走相同的容器路径，并加载函数关联的层，但不执行用户代码。
+//
+// 参数:
+//   - ctx: 上下文
+//   - fn: 要诊断的函数
+//
+// 返回值:
+//   - *domain.FunctionDiagnostics: 执行环境诊断信息
+//   - error: 执行器不支持诊断时返回 ErrDiagnosticsUnsupported
+func (s *DockerScheduler) Diagnose(ctx context.Context, fn *domain.Function) (*domain.FunctionDiagnostics, error) {
+	diagExec, ok := s.executor.(DiagnosticExecutor)
+	if !ok {
+		return nil, ErrDiagnosticsUnsupported
+	}
+
+	logger := s.logger.WithFields(logrus.Fields{
+		"function_id":   fn.ID,
+		"function_name": fn.Name,
+	})
+	layers := s.loadLayers(fn.ID, logger)
+	return diagExec.Diagnose(ctx, fn, layers)
+}
+
+// MissingImages 返回本地缺失的运行时镜像（运行时 -> 镜像）以及已配置的运行时总数。
+// 执行器不支持镜像检查时返回 nil 和 0。
+func (s *DockerScheduler) MissingImages() (map[string]string, int) {
+	checker, ok := s.executor.(ImageChecker)
+	if !ok {
+		return nil, 0
+	}
+	return checker.MissingImages(), checker.RuntimeCount()
+}
+
+// RuntimeImages 返回各运行时配置的镜像（运行时 -> 镜像），执行器不支持时返回 nil。
+func (s *DockerScheduler) RuntimeImages() map[string]string {
+	checker, ok := s.executor.(ImageChecker)
+	if !ok {
+		return nil
+	}
+	return checker.RuntimeImages()
+}
+
+// ProvisionReporter 定义了能够报告常驻与按需预热实例数的执行器接口（可选实现）。
+type ProvisionReporter interface {
+	// ProvisionStats 返回各规格执行环境池的常驻与按需预热实例数，未启用池化时第二个返回值为 false
+	ProvisionStats() ([]domain.ProvisionedPool, bool)
+}
+
+// ErrProvisioningUnsupported 表示当前执行器不支持预置常驻实例
+var ErrProvisioningUnsupported = errors.New("provisioned concurrency requires docker pool mode")
+
+// ReconcileKeepWarm 立即按函数的 keep_warm 配置协调常驻预热实例，不等待协调完成。
+// 函数的 keep_warm 变化、函数被删除或下线后调用，无需等待下一个协调周期。
+// 执行器不支持常驻预热时不做任何操作。
+func (s *DockerScheduler) ReconcileKeepWarm() {
+	if s.keepWarm != nil {
+		s.keepWarm.trigger()
+	}
+}
+
+// ProvisionStats 返回各运行时/内存规格容器池的常驻与按需预热实例数。
+//
+// 返回值:
+//   - []domain.ProvisionedPool: 各规格容器池的实例数
+//   - error: 执行器不支持或未启用池化模式时返回 ErrProvisioningUnsupported
+func (s *DockerScheduler) ProvisionStats() ([]domain.ProvisionedPool, error) {
+	reporter, ok := s.executor.(ProvisionReporter)
+	if !ok || s.keepWarm == nil {
+		return nil, ErrProvisioningUnsupported
+	}
+	stats, ok := reporter.ProvisionStats()
+	if !ok {
+		return nil, ErrProvisioningUnsupported
+	}
+	return stats, nil
+}
+
+// DataVolumeRegistry 定义了能够列出已注册共享数据卷的执行器接口（可选实现）。
+type DataVolumeRegistry interface {
+	// DataVolumes 返回已注册的共享数据卷名称
+	DataVolumes() []string
+}
+
+// DataVolumes 返回执行器已注册的共享数据卷名称，执行器不支持挂载数据卷时返回 nil。
+func (s *DockerScheduler) DataVolumes() []string {
+	registry, ok := s.executor.(DataVolumeRegistry)
+	if !ok {
+		return nil
+	}
+	return registry.DataVolumes()
+}
+
+// CustomImagePolicy 定义了能够检查自定义镜像白名单的执行器接口（可选实现）。
+type CustomImagePolicy interface {
+	// CustomImageAllowed 检查镜像是否在白名单内
+	CustomImageAllowed(image string) bool
+}
+
+// CustomImageAllowed 检查函数能否使用指定的自定义镜像，执行器不支持自定义镜像时返回 false。
+func (s *DockerScheduler) CustomImageAllowed(image string) bool {
+	policy, ok := s.executor.(CustomImagePolicy)
+	if !ok {
+		return false
+	}
+	return policy.CustomImageAllowed(image)
 }
 
 // fail 处理工作项执行失败的情况。
@@ -498,7 +1064,7 @@ func (s *DockerScheduler) fail(item *dockerWorkItem, errMsg string, statusCode i
 	s.store.UpdateInvocation(item.invocation)
 
 	// 记录错误指标
-	if s.metrics != nil {
+	if s.metrics != nil && item.invocation.TriggerType != domain.TriggerShadow {
 		s.metrics.RecordInvocation(
 			item.function.ID,
 			item.function.Name,
@@ -522,3 +1088,26 @@ func (s *DockerScheduler) fail(item *dockerWorkItem, errMsg string, statusCode i
 		}
 	}
 }
+
+// RunCircuitRecovery 周期性恢复冷却结束的熔断函数并开始试探，阻塞直到 ctx 取消。
+// 熔断状态由所有实例共享，多副本部署时由调用方通过领导者选举保证只在一个实例上运行。
+func (s *DockerScheduler) RunCircuitRecovery(ctx context.Context) {
+	s.breakers.run(ctx)
+}
+
+// CheckInvoke 检查函数当前是否允许调用（紧急停止开关、维护窗口、最大并发数），不执行函数。
+// 用于命中响应缓存等不经过调度器执行就返回结果的调用路径。
+func (s *DockerScheduler) CheckInvoke(fn *domain.Function) error {
+	return checkInvokeGates(s.redis, s.metrics, s.logger, s.concurrency, fn)
+}
+
+// InvalidateShadowConfig 使函数的影子流量配置缓存失效，用于影子配置修改或删除后立即生效。
+func (s *DockerScheduler) InvalidateShadowConfig(functionID string) {
+	s.shadow.invalidate(functionID)
+}
+
+// InvalidateAliases 使函数所有别名的路由缓存失效，
+// 用于别名修改或蓝绿切换后立即生效。
+func (s *DockerScheduler) InvalidateAliases(functionID string) {
+	s.router.InvalidateFunctionCache(functionID)
+}
@@ -0,0 +1,70 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// PoolPlanner 定义了能够预演执行环境分配的执行器接口（可选实现）。
+type PoolPlanner interface {
+	// PlanAcquire 返回执行环境池对一次调用的分配决策，不实际分配执行环境
+	PlanAcquire(runtime string, memoryMB int, dataVolumes []string) *domain.PoolPlan
+}
+
+// DryRun 预演一次调用的调度过程：依次检查维护窗口、并发槽位、工作队列和执行环境池，
+// 返回调用会如何被处理，但不创建调用记录也不执行函数。
+// 并发槽位按实际调度的逻辑占用后立即释放。
+//
+// 参数:
+//   - req: 调用请求，只使用其中的函数 ID 和触发来源
+//
+// 返回值:
+//   - *domain.DryRunResult: 调度预演结果（RateLimit 由调用方按请求身份填充）
+//   - error: 函数不存在等错误
+func (s *DockerScheduler) DryRun(req *domain.InvokeRequest) (*domain.DryRunResult, error) {
+	fn, err := s.store.GetFunctionByID(req.FunctionID)
+	if err != nil {
+		return nil, err
+	}
+
+	priority := domain.PriorityFor(fn, req.TriggerSource(), true)
+	lane := s.workQueue.lane(priority)
+	result := &domain.DryRunResult{
+		FunctionID:    fn.ID,
+		FunctionName:  fn.Name,
+		Runtime:       fn.Runtime,
+		MemoryMB:      fn.MemoryMB,
+		Priority:      priority,
+		QueueDepth:    len(lane),
+		QueueCapacity: cap(lane),
+	}
+	if planner, ok := s.executor.(PoolPlanner); ok {
+		result.Pool = planner.PlanAcquire(string(fn.Runtime), fn.MemoryMB, fn.DataVolumes)
+	}
+
+	if merr := domain.CheckMaintenance(fn, time.Now()); merr != nil {
+		until := merr.Until
+		result.MaintenanceUntil = &until
+		result.Throttle = domain.DryRunThrottleMaintenance
+		return result, nil
+	}
+
+	// 同步调用在对应优先级的队列已满时立即被拒绝
+	if result.QueueDepth >= result.QueueCapacity {
+		result.Throttle = domain.DryRunThrottleQueueFull
+		return result, nil
+	}
+
+	slot, ok := s.reservations.probe(fn.ID, fn.ReservedConcurrency)
+	if !ok {
+		// 没有可用槽位时同步调用会被拒绝，异步调用会被推迟重试
+		result.Throttle = domain.DryRunThrottleConcurrency
+		return result, nil
+	}
+	result.ConcurrencySlot = slot
+
+	result.WouldExecute = true
+	result.ColdStart = result.Pool != nil && result.Pool.Decision == domain.PoolDecisionCold
+	return result, nil
+}
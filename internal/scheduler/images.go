@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// imageCheckTimeout 启动时检查运行时镜像的超时时间
+	imageCheckTimeout = 10 * time.Second
+	// imageRecheckInterval 存在缺失镜像时重新检查的周期，镜像构建或拉取后最多在一个周期内恢复就绪
+	imageRecheckInterval = 30 * time.Second
+)
+
+// ImageChecker 定义了能够检查运行时镜像是否可用的执行器接口（可选实现）。
+type ImageChecker interface {
+	// CheckImages 检查所有运行时镜像，返回缺失镜像（运行时 -> 镜像）
+	CheckImages(ctx context.Context) map[string]string
+	// MissingImages 返回最近一次检查发现的缺失镜像
+	MissingImages() map[string]string
+	// RuntimeCount 返回已配置的运行时数量
+	RuntimeCount() int
+	// RuntimeImages 返回各运行时配置的镜像（运行时 -> 镜像）
+	RuntimeImages() map[string]string
+}
+
+// checkImagesOnStart 在启动时同步检查一次运行时镜像，
+// 存在缺失镜像时在后台周期性重新检查，直到全部可用或 ctx 取消。
+func checkImagesOnStart(ctx context.Context, checker ImageChecker) {
+	checkCtx, cancel := context.WithTimeout(ctx, imageCheckTimeout)
+	missing := checker.CheckImages(checkCtx)
+	cancel()
+	if len(missing) == 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(imageRecheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			checkCtx, cancel := context.WithTimeout(ctx, imageCheckTimeout)
+			missing := checker.CheckImages(checkCtx)
+			cancel()
+			if len(missing) == 0 {
+				return
+			}
+		}
+	}()
+}
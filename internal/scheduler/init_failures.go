@@ -0,0 +1,81 @@
+// Package scheduler 提供函数调度器的实现。
+package scheduler
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+	"github.com/oriys/nimbus/internal/storage"
+)
+
+// initFailureTracker 统计每个函数的连续初始化失败次数。
+// 连续失败达到阈值时将函数标记为 degraded，成功调用后恢复为 active。
+type initFailureTracker struct {
+	threshold int
+	store     *storage.PostgresStore
+	logger    *logrus.Logger
+
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// newInitFailureTracker 创建初始化失败跟踪器。
+//
+// 参数:
+//   - threshold: 连续失败阈值，小于等于 0 时使用默认值 3
+//   - store: PostgreSQL 存储实例，用于更新函数状态
+//   - logger: 日志记录器
+func newInitFailureTracker(threshold int, store *storage.PostgresStore, logger *logrus.Logger) *initFailureTracker {
+	if threshold <= 0 {
+		threshold = 3
+	}
+	return &initFailureTracker{
+		threshold: threshold,
+		store:     store,
+		logger:    logger,
+		counts:    make(map[string]int),
+	}
+}
+
+// recordFailure 记录一次初始化失败，达到阈值时将函数标记为 degraded。
+func (t *initFailureTracker) recordFailure(fn *domain.Function, errMsg string) {
+	t.mu.Lock()
+	t.counts[fn.ID]++
+	count := t.counts[fn.ID]
+	t.mu.Unlock()
+
+	if count < t.threshold || fn.Status == domain.FunctionStatusDegraded {
+		return
+	}
+
+	msg := "连续初始化失败: " + errMsg
+	if err := t.store.UpdateFunctionStatus(fn.ID, domain.FunctionStatusDegraded, msg, ""); err != nil {
+		t.logger.WithError(err).WithField("function_id", fn.ID).Warn("Failed to mark function as degraded")
+		return
+	}
+	fn.Status = domain.FunctionStatusDegraded
+	t.logger.WithFields(logrus.Fields{
+		"function_id":   fn.ID,
+		"function_name": fn.Name,
+		"failures":      count,
+	}).Warn("Function marked as degraded after repeated init failures")
+}
+
+// recordSuccess 清除函数的初始化失败计数，degraded 状态的函数恢复为 active。
+func (t *initFailureTracker) recordSuccess(fn *domain.Function) {
+	t.mu.Lock()
+	delete(t.counts, fn.ID)
+	t.mu.Unlock()
+
+	if fn.Status != domain.FunctionStatusDegraded {
+		return
+	}
+	if err := t.store.UpdateFunctionStatus(fn.ID, domain.FunctionStatusActive, "", ""); err != nil {
+		t.logger.WithError(err).WithField("function_id", fn.ID).Warn("Failed to restore degraded function")
+		return
+	}
+	fn.Status = domain.FunctionStatusActive
+	t.logger.WithField("function_id", fn.ID).Info("Degraded function recovered after successful invocation")
+}
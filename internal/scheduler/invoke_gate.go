@@ -0,0 +1,37 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+	"github.com/oriys/nimbus/internal/metrics"
+	"github.com/oriys/nimbus/internal/storage"
+)
+
+// checkInvokeGates 检查不执行函数就返回结果的调用（例如命中响应缓存）同样需要遵守的调用闸门，
+// 依次为紧急停止开关、维护窗口和函数最大并发数。熔断由函数状态体现，由调用方在此之前检查。
+//
+// 参数:
+//   - redis: Redis 存储，可为 nil（不检查开关和并发数）
+//   - m: 指标收集器，可为 nil
+//   - logger: 日志记录器
+//   - limiter: 函数并发限制器
+//   - fn: 被调用的函数
+//
+// 返回值:
+//   - error: *domain.KillSwitchError、*domain.MaintenanceError，
+//     或函数已达到最大并发数时的 domain.ErrConcurrencyLimitExceeded
+func checkInvokeGates(redis *storage.RedisStore, m *metrics.Metrics, logger *logrus.Logger, limiter *concurrencyLimiter, fn *domain.Function) error {
+	if err := checkKillSwitch(redis, m, logger, fn.ID); err != nil {
+		return err
+	}
+	if merr := domain.CheckMaintenance(fn, time.Now()); merr != nil {
+		return merr
+	}
+	if limiter.saturated(fn) {
+		return domain.ErrConcurrencyLimitExceeded
+	}
+	return nil
+}
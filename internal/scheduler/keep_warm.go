@@ -0,0 +1,93 @@
+// Package scheduler 提供函数调度器的实现。
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+	"github.com/oriys/nimbus/internal/storage"
+)
+
+// keepWarmInterval 常驻预热协调的周期，实例老化被回收后最多在一个周期内补齐
+const keepWarmInterval = 30 * time.Second
+
+// WarmKeeper 定义了支持常驻预热实例的执行环境池接口（可选实现）。
+type WarmKeeper interface {
+	// SetKeepWarm 设置各运行时/内存规格的常驻预热目标，并补齐缺少的热实例
+	SetKeepWarm(ctx context.Context, targets []domain.KeepWarmTarget)
+}
+
+// keepWarmReconciler 定期从存储加载函数的 keep_warm 配置，
+// 交给执行环境池协调，使其始终保持目标数量的热实例。
+type keepWarmReconciler struct {
+	store  *storage.PostgresStore
+	keeper WarmKeeper
+	logger *logrus.Logger
+	kick   chan struct{} // 请求立即协调，见 trigger
+}
+
+// newKeepWarmReconciler 创建常驻预热协调器。
+func newKeepWarmReconciler(store *storage.PostgresStore, keeper WarmKeeper, logger *logrus.Logger) *keepWarmReconciler {
+	return &keepWarmReconciler{
+		store:  store,
+		keeper: keeper,
+		logger: logger,
+		kick:   make(chan struct{}, 1),
+	}
+}
+
+// run 立即执行一次协调，之后按 keepWarmInterval 周期或收到 trigger 请求时执行，直到 ctx 取消。
+func (r *keepWarmReconciler) run(ctx context.Context) {
+	ticker := time.NewTicker(keepWarmInterval)
+	defer ticker.Stop()
+
+	for {
+		r.reconcile(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.kick:
+		}
+	}
+}
+
+// trigger 请求立即执行一次协调，不等待协调完成。
+// 协调进行中时收到的多次请求合并为一次，在当前协调结束后执行。
+func (r *keepWarmReconciler) trigger() {
+	select {
+	case r.kick <- struct{}{}:
+	default:
+	}
+}
+
+// reconcile 执行一次常驻预热协调。
+func (r *keepWarmReconciler) reconcile(ctx context.Context) {
+	targets, err := r.store.ListKeepWarmTargets()
+	if err != nil {
+		r.logger.WithError(err).Warn("Failed to load keep-warm targets")
+		return
+	}
+	// 预热载荷加载失败时仍然补齐实例，只是不做试执行
+	warmups, err := r.store.ListWarmupFunctions()
+	if err != nil {
+		r.logger.WithError(err).Warn("Failed to load warm-up functions")
+	}
+	r.keeper.SetKeepWarm(ctx, attachWarmups(targets, warmups))
+}
+
+// attachWarmups 将配置了预热载荷的函数挂到其运行时/内存规格对应的常驻预热目标上。
+func attachWarmups(targets []domain.KeepWarmTarget, warmups []*domain.Function) []domain.KeepWarmTarget {
+	for _, fn := range warmups {
+		for i := range targets {
+			if targets[i].Runtime == fn.Runtime && targets[i].MemoryMB == fn.MemoryMB {
+				targets[i].Warmups = append(targets[i].Warmups, fn)
+				break
+			}
+		}
+	}
+	return targets
+}
@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+func TestAttachWarmups(t *testing.T) {
+	targets := []domain.KeepWarmTarget{
+		{Runtime: domain.RuntimePython311, MemoryMB: 128, Count: 2},
+		{Runtime: domain.RuntimePython311, MemoryMB: 256, Count: 1},
+	}
+	a := &domain.Function{Name: "a", Runtime: domain.RuntimePython311, MemoryMB: 128}
+	b := &domain.Function{Name: "b", Runtime: domain.RuntimePython311, MemoryMB: 128}
+	orphan := &domain.Function{Name: "orphan", Runtime: domain.RuntimeNodeJS20, MemoryMB: 128}
+
+	got := attachWarmups(targets, []*domain.Function{a, b, orphan})
+	if len(got[0].Warmups) != 2 || got[0].Warmups[0] != a || got[0].Warmups[1] != b {
+		t.Errorf("128MB warmups = %v, want [a b]", got[0].Warmups)
+	}
+	if len(got[1].Warmups) != 0 {
+		t.Errorf("256MB warmups = %v, want none", got[1].Warmups)
+	}
+}
+
+func TestKeepWarmReconcilerTrigger(t *testing.T) {
+	r := newKeepWarmReconciler(nil, nil, nil)
+	// 未处理的请求合并为一次，不阻塞调用方
+	r.trigger()
+	r.trigger()
+	if len(r.kick) != 1 {
+		t.Fatalf("pending kicks = %d, want 1", len(r.kick))
+	}
+	<-r.kick
+	r.trigger()
+	if len(r.kick) != 1 {
+		t.Errorf("pending kicks after drain = %d, want 1", len(r.kick))
+	}
+}
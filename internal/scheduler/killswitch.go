@@ -0,0 +1,47 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+	"github.com/oriys/nimbus/internal/metrics"
+	"github.com/oriys/nimbus/internal/storage"
+)
+
+// killSwitchCheckTimeout 是检查紧急停止开关的 Redis 超时，超时视为开关未开启。
+const killSwitchCheckTimeout = 50 * time.Millisecond
+
+// checkKillSwitch 在调用路径的最开始检查全局和函数级紧急停止开关。
+// 开关存放在 Redis 中，所有网关实例共享；Redis 不可用或超时时放行调用，避免紧急开关本身成为故障点。
+//
+// 参数:
+//   - redis: Redis 存储，为 nil 时不检查
+//   - m: 指标收集器，可为 nil
+//   - logger: 日志记录器
+//   - functionID: 被调用的函数 ID
+//
+// 返回值:
+//   - error: 开关已开启时返回 *domain.KillSwitchError
+func checkKillSwitch(redis *storage.RedisStore, m *metrics.Metrics, logger *logrus.Logger, functionID string) error {
+	if redis == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), killSwitchCheckTimeout)
+	defer cancel()
+
+	ks, err := redis.CheckKillSwitch(ctx, functionID)
+	if err != nil {
+		logger.WithError(err).WithField("function_id", functionID).Warn("Kill switch check failed, allowing invocation")
+		return nil
+	}
+	if ks == nil {
+		return nil
+	}
+	if m != nil {
+		m.RecordKillSwitchRejection(ks.Scope)
+	}
+	return &domain.KillSwitchError{Switch: ks}
+}
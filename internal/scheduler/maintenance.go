@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+	"github.com/oriys/nimbus/internal/storage"
+)
+
+// errStoppedDuringMaintenance 表示调度器在维护窗口结束前停止，延迟的异步调用无法提交
+var errStoppedDuringMaintenance = errors.New("scheduler stopped before maintenance window ended")
+
+// skipForMaintenance 将处于维护窗口内的同步调用标记为 skipped 并持久化，
+// 使被跳过的调用在调用记录中与失败调用区分开。
+func skipForMaintenance(store *storage.PostgresStore, logger *logrus.Logger, inv *domain.Invocation, merr *domain.MaintenanceError) {
+	logger.WithFields(logrus.Fields{
+		"invocation_id": inv.ID,
+		"function_id":   inv.FunctionID,
+		"until":         merr.Until,
+	}).Info("Invocation skipped: function is in maintenance")
+
+	inv.Skip(merr.Error())
+	if err := store.UpdateInvocation(inv); err != nil {
+		logger.WithError(err).Warn("Failed to mark invocation as skipped")
+	}
+}
+
+// deferUntilMaintenanceEnds 将处于维护窗口内的异步调用延迟到窗口结束后再提交。
+// 调用记录在等待期间保持 pending 状态；调度器在窗口结束前停止时标记为失败。
+//
+// 参数:
+//   - ctx: 调度器上下文
+//   - store: 存储，用于持久化被放弃的调用
+//   - logger: 日志记录器
+//   - inv: 被延迟的调用记录
+//   - merr: 维护窗口信息
+//   - submit: 窗口结束后提交调用的函数
+func deferUntilMaintenanceEnds(ctx context.Context, store *storage.PostgresStore, logger *logrus.Logger, inv *domain.Invocation, merr *domain.MaintenanceError, submit func()) {
+	logger.WithFields(logrus.Fields{
+		"invocation_id": inv.ID,
+		"function_id":   inv.FunctionID,
+		"until":         merr.Until,
+	}).Info("Async invocation deferred until maintenance window ends")
+
+	abandon := func() {
+		logger.WithField("invocation_id", inv.ID).Warn("Deferred invocation abandoned: scheduler stopped")
+		inv.Fail(errStoppedDuringMaintenance.Error())
+		if err := store.UpdateInvocation(inv); err != nil {
+			logger.WithError(err).Warn("Failed to mark deferred invocation as failed")
+		}
+	}
+
+	timer := time.NewTimer(time.Until(merr.Until))
+	go func() {
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			abandon()
+		case <-timer.C:
+			if ctx.Err() != nil {
+				abandon()
+				return
+			}
+			submit()
+		}
+	}()
+}
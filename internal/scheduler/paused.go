@@ -0,0 +1,113 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+	"github.com/oriys/nimbus/internal/storage"
+)
+
+// holdPausedInvocation 将已暂停函数的异步调用放入函数的暂停队列，调用记录保持 pending。
+// Redis 不可用时无法保存调用，将调用记录标记为失败并返回 domain.ErrAsyncQueueUnavailable。
+func holdPausedInvocation(redis *storage.RedisStore, store *storage.PostgresStore, logger *logrus.Logger, inv *domain.Invocation) error {
+	err := fmt.Errorf("redis not configured")
+	if redis != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), redisOverflowTimeout)
+		err = redis.PushPausedInvocation(ctx, inv.FunctionID, inv.ID)
+		cancel()
+	}
+	if err != nil {
+		rejectAsyncInvocation(store, logger, inv, "paused queue", err)
+		return fmt.Errorf("%w: paused queue unavailable: %v", domain.ErrAsyncQueueUnavailable, err)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"invocation_id": inv.ID,
+		"function_id":   inv.FunctionID,
+	}).Info("Async invocation held: function is paused")
+	return nil
+}
+
+// drainPausedInvocations 按入队顺序取出函数暂停队列中的调用并逐个提交，
+// 直到队列为空、函数再次被暂停或下线、或 ctx 取消。
+//
+// 参数:
+//   - ctx: 调度器上下文
+//   - redis: Redis 存储
+//   - store: 存储，用于加载函数和调用记录
+//   - logger: 日志记录器
+//   - functionID: 函数 ID
+//   - submit: 提交调用到工作队列，阻塞直到提交成功；返回 false 表示调度器已停止
+//
+// 返回值:
+//   - int: 已提交的调用数
+func drainPausedInvocations(ctx context.Context, redis *storage.RedisStore, store *storage.PostgresStore, logger *logrus.Logger,
+	functionID string, submit func(inv *domain.Invocation, fn *domain.Function) bool) int {
+	log := logger.WithField("function_id", functionID)
+	drained := 0
+	for ctx.Err() == nil {
+		// 每次提交前重新加载函数，恢复期间再次暂停或下线时停止排空，剩余调用继续保留
+		fn, err := store.GetFunctionByID(functionID)
+		if err != nil {
+			log.WithError(err).Warn("Stopped draining paused invocations: failed to load function")
+			break
+		}
+		if !fn.Status.CanInvoke() {
+			log.WithField("status", fn.Status).Info("Stopped draining paused invocations: function is not active")
+			break
+		}
+
+		id, err := redis.PopPausedInvocation(ctx, functionID)
+		if err != nil {
+			log.WithError(err).Warn("Stopped draining paused invocations: failed to pop from paused queue")
+			break
+		}
+		if id == "" {
+			break
+		}
+
+		inv, err := store.GetInvocationByID(id)
+		if err != nil {
+			log.WithError(err).WithField("invocation_id", id).Warn("Dropped paused invocation: failed to load invocation")
+			continue
+		}
+		// 暂停期间被取消的调用不再执行
+		if inv.Status != domain.InvocationStatusPending {
+			continue
+		}
+
+		if !submit(inv, fn) {
+			requeueCtx, cancel := context.WithTimeout(context.Background(), redisOverflowTimeout)
+			if err := redis.RequeuePausedInvocation(requeueCtx, functionID, id); err != nil {
+				log.WithError(err).WithField("invocation_id", id).Warn("Failed to return invocation to paused queue")
+			}
+			cancel()
+			break
+		}
+		drained++
+	}
+
+	if drained > 0 {
+		log.WithField("drained", drained).Info("Drained paused invocations")
+	}
+	return drained
+}
+
+// submitWhenReady 反复尝试非阻塞提交，工作队列已满时间隔 throttledRetryDelay 重试，
+// 使排空暂停队列不会把调用挤到 Redis 备用队列。调度器停止时返回 false。
+func submitWhenReady(ctx context.Context, trySubmit func() bool) bool {
+	for ctx.Err() == nil {
+		if trySubmit() {
+			return true
+		}
+		select {
+		case <-time.After(throttledRetryDelay):
+		case <-ctx.Done():
+		}
+	}
+	return false
+}
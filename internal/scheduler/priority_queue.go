@@ -0,0 +1,164 @@
+package scheduler
+
+import (
+	"sync"
+
+	"github.com/oriys/nimbus/internal/domain"
+	"github.com/oriys/nimbus/internal/metrics"
+)
+
+// queueSources 是上报排队深度指标的触发来源，没有排队时上报 0
+var queueSources = []domain.TriggerType{domain.TriggerHTTP, domain.TriggerWebhook, domain.TriggerCron, domain.TriggerEvent}
+
+// priorityQueue 是按调用优先级分道的工作队列。
+// 每个优先级一条独立的有界通道，容量均为队列大小，因此后台调用积压不会占满同步调用的排队空间；
+// 工作协程总是先取出高优先级通道中的工作项，使交互式流量在资源紧张时优先获得工作协程和实例。
+type priorityQueue[T any] struct {
+	lanes []chan queueEntry[T] // 按 domain.Priorities 顺序（从高到低）排列的通道
+
+	mu       sync.Mutex
+	bySource map[domain.TriggerType]int // 各触发来源当前排队的工作项数
+}
+
+// queueEntry 是队列中的一个工作项及其触发来源。
+type queueEntry[T any] struct {
+	item   T
+	source domain.TriggerType
+}
+
+// newPriorityQueue 创建每个优先级容量均为 size 的优先级队列。
+func newPriorityQueue[T any](size int) *priorityQueue[T] {
+	q := &priorityQueue[T]{
+		lanes:    make([]chan queueEntry[T], len(domain.Priorities)),
+		bySource: make(map[domain.TriggerType]int),
+	}
+	for i := range q.lanes {
+		q.lanes[i] = make(chan queueEntry[T], size)
+	}
+	return q
+}
+
+// lane 返回优先级对应的通道，未知优先级按 normal 处理。
+func (q *priorityQueue[T]) lane(p domain.InvocationPriority) chan queueEntry[T] {
+	for i, candidate := range domain.Priorities {
+		if candidate == p {
+			return q.lanes[i]
+		}
+	}
+	return q.lane(domain.PriorityNormal)
+}
+
+// push 以非阻塞方式将工作项放入对应优先级的通道，通道已满时返回 false。
+func (q *priorityQueue[T]) push(p domain.InvocationPriority, source domain.TriggerType, item T) bool {
+	// 先计数再入队，避免出队早于计数导致深度为负
+	q.track(source, 1)
+	select {
+	case q.lane(p) <- queueEntry[T]{item: item, source: source}:
+		return true
+	default:
+		q.track(source, -1)
+		return false
+	}
+}
+
+// pop 取出优先级最高的工作项，队列为空时阻塞等待。
+// done 或 stop 关闭、或队列已关闭时返回 false。
+func (q *priorityQueue[T]) pop(done, stop <-chan struct{}) (T, bool) {
+	var zero T
+	// 按优先级从高到低检查是否有等待的工作项
+	for _, lane := range q.lanes {
+		select {
+		case e, ok := <-lane:
+			return q.taken(e, ok)
+		default:
+		}
+	}
+
+	// 所有通道为空时等待任意通道的新工作项（通道数量与 domain.Priorities 一致）
+	select {
+	case <-done:
+		return zero, false
+	case <-stop:
+		return zero, false
+	case e, ok := <-q.lanes[0]:
+		return q.taken(e, ok)
+	case e, ok := <-q.lanes[1]:
+		return q.taken(e, ok)
+	case e, ok := <-q.lanes[2]:
+		return q.taken(e, ok)
+	}
+}
+
+// taken 记录工作项出队并返回工作项，通道已关闭时返回 false。
+func (q *priorityQueue[T]) taken(e queueEntry[T], ok bool) (T, bool) {
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	q.track(e.source, -1)
+	return e.item, true
+}
+
+// track 调整触发来源的排队计数。
+func (q *priorityQueue[T]) track(source domain.TriggerType, delta int) {
+	q.mu.Lock()
+	q.bySource[source] += delta
+	if q.bySource[source] <= 0 {
+		delete(q.bySource, source)
+	}
+	q.mu.Unlock()
+}
+
+// close 关闭所有通道，通知工作协程退出。
+func (q *priorityQueue[T]) close() {
+	for _, lane := range q.lanes {
+		close(lane)
+	}
+}
+
+// len 返回所有优先级排队的工作项总数。
+func (q *priorityQueue[T]) len() int {
+	n := 0
+	for _, lane := range q.lanes {
+		n += len(lane)
+	}
+	return n
+}
+
+// cap 返回所有优先级通道的总容量。
+func (q *priorityQueue[T]) cap() int {
+	n := 0
+	for _, lane := range q.lanes {
+		n += cap(lane)
+	}
+	return n
+}
+
+// depthByPriority 返回各优先级当前排队的工作项数。
+func (q *priorityQueue[T]) depthByPriority() map[string]int {
+	depths := make(map[string]int, len(q.lanes))
+	for i, p := range domain.Priorities {
+		depths[string(p)] = len(q.lanes[i])
+	}
+	return depths
+}
+
+// depthBySource 返回各触发来源当前排队的工作项数。
+func (q *priorityQueue[T]) depthBySource() map[string]int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	depths := make(map[string]int, len(q.bySource))
+	for source, n := range q.bySource {
+		depths[string(source)] = n
+	}
+	return depths
+}
+
+// reportDepth 上报队列总长度和各触发来源的排队深度指标。
+func (q *priorityQueue[T]) reportDepth(m *metrics.Metrics) {
+	m.SchedulerQueueSize.Set(float64(q.len()))
+	depths := q.depthBySource()
+	for _, source := range queueSources {
+		m.SchedulerQueueDepth.WithLabelValues(string(source)).Set(float64(depths[string(source)]))
+	}
+}
@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	q := newPriorityQueue[string](2)
+	done := make(chan struct{})
+
+	q.push(domain.PriorityLow, domain.TriggerCron, "cron-1")
+	q.push(domain.PriorityNormal, domain.TriggerWebhook, "webhook-1")
+	q.push(domain.PriorityHigh, domain.TriggerHTTP, "http-1")
+	q.push(domain.PriorityLow, domain.TriggerEvent, "event-1")
+
+	if q.len() != 4 || q.cap() != 6 {
+		t.Fatalf("len=%d cap=%d, want 4 6", q.len(), q.cap())
+	}
+	if got := q.depthBySource(); got["cron"] != 1 || got["event"] != 1 || got["http"] != 1 || got["webhook"] != 1 {
+		t.Fatalf("depthBySource = %v", got)
+	}
+	if got := q.depthByPriority(); got["high"] != 1 || got["normal"] != 1 || got["low"] != 2 {
+		t.Fatalf("depthByPriority = %v", got)
+	}
+
+	// 低优先级通道已满不影响高优先级入队
+	if q.push(domain.PriorityLow, domain.TriggerCron, "cron-2") {
+		t.Fatal("push to full low lane succeeded")
+	}
+	if !q.push(domain.PriorityHigh, domain.TriggerHTTP, "http-2") {
+		t.Fatal("push to high lane failed while low lane is full")
+	}
+
+	// 按优先级从高到低出队，同一优先级内先进先出
+	want := []string{"http-1", "http-2", "webhook-1", "cron-1", "event-1"}
+	for _, w := range want {
+		got, ok := q.pop(done, nil)
+		if !ok || got != w {
+			t.Fatalf("pop = %q %v, want %q", got, ok, w)
+		}
+	}
+	if got := q.depthBySource(); len(got) != 0 {
+		t.Fatalf("depthBySource after drain = %v, want empty", got)
+	}
+
+	// 停止信号或队列关闭时返回 false
+	close(done)
+	if _, ok := q.pop(done, nil); ok {
+		t.Fatal("pop after done returned an item")
+	}
+	q.close()
+	if _, ok := q.pop(nil, nil); ok {
+		t.Fatal("pop after close returned an item")
+	}
+}
@@ -0,0 +1,38 @@
+// Package scheduler 提供函数调度器的实现。
+package scheduler
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+	"github.com/oriys/nimbus/internal/storage"
+)
+
+// progressFlushInterval 进度写入存储的最小间隔，避免频繁上报进度的函数压垮数据库。
+// 间隔内被跳过的进度保留在调用记录上，随调用完成时的 UpdateInvocation 一并写入。
+const progressFlushInterval = 500 * time.Millisecond
+
+// newProgressReporter 创建记录函数执行进度的回调。
+// 最新进度保存在 inv.Progress 上，并按 progressFlushInterval 节流写入存储，
+// 供 GET /api/v1/invocations/{id}/progress 在执行过程中查询。
+//
+// 参数:
+//   - store: PostgreSQL 存储
+//   - inv: 正在执行的调用记录
+//   - logger: 日志记录器
+func newProgressReporter(store *storage.PostgresStore, inv *domain.Invocation, logger *logrus.Entry) domain.ProgressReporter {
+	var lastFlush time.Time
+	return func(progress *domain.InvocationProgress) {
+		inv.Progress = progress
+		// 完成进度总是立即写入，其余进度按间隔节流
+		if progress.Percent < 100 && time.Since(lastFlush) < progressFlushInterval {
+			return
+		}
+		lastFlush = time.Now()
+		if err := store.UpdateInvocationProgress(inv.ID, progress); err != nil {
+			logger.WithError(err).Debug("Failed to record invocation progress")
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package scheduler
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/config"
+	"github.com/oriys/nimbus/internal/domain"
+	"github.com/oriys/nimbus/internal/metrics"
+)
+
+// checkRecursion 在创建调用记录前检查调用链，拦截超出最大深度或同一函数重复出现过多的疑似无限递归调用。
+// 被拒绝的调用不会创建调用记录，并计入 scheduler_recursion_rejections_total 指标。
+//
+// 参数:
+//   - cfg: 调度器配置，提供 MaxCallDepth 和 MaxFunctionRepeats
+//   - m: 指标收集器，可为 nil
+//   - logger: 日志记录器
+//   - fn: 被调用的函数
+//   - chain: 上游函数 ID，从根调用开始依次排列
+//
+// 返回值:
+//   - error: 超出限制时返回 *domain.RecursionError
+func checkRecursion(cfg config.SchedulerConfig, m *metrics.Metrics, logger *logrus.Logger, fn *domain.Function, chain []string) error {
+	err := domain.CheckRecursion(chain, fn.ID, cfg.MaxCallDepth, cfg.MaxFunctionRepeats)
+	if err == nil {
+		return nil
+	}
+	rerr := err.(*domain.RecursionError)
+	logger.WithFields(logrus.Fields{
+		"function":   fn.Name,
+		"reason":     rerr.Reason,
+		"count":      rerr.Count,
+		"limit":      rerr.Limit,
+		"call_chain": chain,
+	}).Warn("Rejected recursive invocation")
+	if m != nil {
+		m.RecordRecursionRejection(fn.Name, rerr.Reason)
+	}
+	return err
+}
+
+// executionCallChain 返回执行调用时注入函数环境的调用链：上游调用链加上当前函数。
+func executionCallChain(inv *domain.Invocation) []string {
+	chain := make([]string, 0, len(inv.CallChain)+1)
+	chain = append(chain, inv.CallChain...)
+	return append(chain, inv.FunctionID)
+}
@@ -0,0 +1,250 @@
+// Package scheduler 提供函数调度器的实现。
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// reservationRefreshInterval 从存储重新加载全部预留配置的间隔
+	reservationRefreshInterval = 10 * time.Second
+	// throttledRetryDelay 被推迟的调用重新入队的延迟
+	throttledRetryDelay = 200 * time.Millisecond
+)
+
+// 并发槽位类型
+const (
+	slotReserved = "reserved" // 函数自己的预留槽位
+	slotShared   = "shared"   // 所有函数共享的槽位
+)
+
+// reservationLoader 加载所有函数的预留并发配置。
+type reservationLoader interface {
+	ListReservedConcurrency() (map[string]int, error)
+}
+
+// ReservationStats 描述预留并发与共享容量的使用情况。
+type ReservationStats struct {
+	ReservedCapacity  int `json:"reserved_capacity"`  // 所有函数预留的并发槽位总数
+	SharedCapacity    int `json:"shared_capacity"`    // 扣除预留后其他函数可共享的槽位数
+	AvailableCapacity int `json:"available_capacity"` // 当前空闲的共享槽位数
+}
+
+// reservationTracker 实现函数级预留并发。
+//
+// 调度器的并发容量等于工作协程数量。设置了 ReservedConcurrency 的函数独占相应数量的槽位，
+// 超出预留部分与其他函数一起竞争共享槽位；共享容量 = 总容量 - 所有预留之和。
+// 这样其他函数的突发流量最多占满共享槽位，不会挤占 SLA 敏感函数的预留容量。
+type reservationTracker struct {
+	loader   reservationLoader
+	capacity func() int
+	logger   *logrus.Logger
+
+	mu            sync.Mutex
+	reserved      map[string]int // 函数 ID -> 预留槽位数
+	reservedTotal int            // 预留槽位总数
+	reservedInUse map[string]int // 函数 ID -> 正在使用的预留槽位数
+	sharedInUse   int            // 正在使用的共享槽位数
+	loadedAt      time.Time      // 最近一次从存储加载的时间
+}
+
+// newReservationTracker 创建预留并发跟踪器。
+//
+// 参数:
+//   - loader: 预留配置加载器，可为 nil（仅使用调用时函数上的预留值）
+//   - capacity: 返回当前并发总容量的函数
+//   - logger: 日志记录器
+func newReservationTracker(loader reservationLoader, capacity func() int, logger *logrus.Logger) *reservationTracker {
+	return &reservationTracker{
+		loader:        loader,
+		capacity:      capacity,
+		logger:        logger,
+		reserved:      make(map[string]int),
+		reservedInUse: make(map[string]int),
+	}
+}
+
+// acquire 为函数占用一个并发槽位。
+// 优先使用函数自己的预留槽位，预留槽位用尽后使用共享槽位。
+//
+// 参数:
+//   - functionID: 函数 ID
+//   - reserved: 函数当前的预留并发数（调用时从存储读取的最新值）
+//
+// 返回值:
+//   - func(): 释放槽位的函数，ok 为 false 时为 nil
+//   - bool: 是否获取成功
+func (t *reservationTracker) acquire(functionID string, reserved int) (func(), bool) {
+	release, _, ok := t.acquireSlot(functionID, reserved)
+	return release, ok
+}
+
+// probe 判断函数当前能否获得并发槽位：按 acquire 的逻辑占用后立即释放。
+// 返回会占用的槽位类型（slotReserved 或 slotShared），无可用槽位时 ok 为 false。
+func (t *reservationTracker) probe(functionID string, reserved int) (string, bool) {
+	release, slot, ok := t.acquireSlot(functionID, reserved)
+	if ok {
+		release()
+	}
+	return slot, ok
+}
+
+// acquireSlot 实现 acquire，额外返回占用的槽位类型。
+func (t *reservationTracker) acquireSlot(functionID string, reserved int) (func(), string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.refreshLocked()
+	t.setLocked(functionID, reserved)
+
+	if t.reservedInUse[functionID] < t.reserved[functionID] {
+		t.reservedInUse[functionID]++
+		return t.releaser(func() { t.reservedInUse[functionID]-- }), slotReserved, true
+	}
+
+	if t.sharedInUse < t.sharedCapacityLocked() {
+		t.sharedInUse++
+		return t.releaser(func() { t.sharedInUse-- }), slotShared, true
+	}
+	return nil, "", false
+}
+
+// releaser 包装释放逻辑，保证只释放一次。
+func (t *reservationTracker) releaser(release func()) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mu.Lock()
+			release()
+			t.mu.Unlock()
+		})
+	}
+}
+
+// stats 返回预留与共享容量的当前使用情况。
+func (t *reservationTracker) stats() ReservationStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	shared := t.sharedCapacityLocked()
+	available := shared - t.sharedInUse
+	if available < 0 {
+		available = 0
+	}
+	return ReservationStats{
+		ReservedCapacity:  t.reservedTotal,
+		SharedCapacity:    shared,
+		AvailableCapacity: available,
+	}
+}
+
+// reservedTotalNow 返回最新的预留槽位总数，会按需从存储刷新。
+func (t *reservationTracker) reservedTotalNow() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.refreshLocked()
+	return t.reservedTotal
+}
+
+// sharedCapacityLocked 计算共享容量，调用方需持有锁。
+func (t *reservationTracker) sharedCapacityLocked() int {
+	shared := t.capacity() - t.reservedTotal
+	if shared < 0 {
+		return 0
+	}
+	return shared
+}
+
+// setLocked 更新单个函数的预留值，调用方需持有锁。
+func (t *reservationTracker) setLocked(functionID string, reserved int) {
+	if reserved < 0 {
+		reserved = 0
+	}
+	t.reservedTotal += reserved - t.reserved[functionID]
+	if reserved == 0 {
+		delete(t.reserved, functionID)
+	} else {
+		t.reserved[functionID] = reserved
+	}
+}
+
+// refreshLocked 距上次加载超过刷新间隔时从存储重新加载全部预留配置，调用方需持有锁。
+// 加载失败时保留旧配置，避免存储抖动导致预留失效。
+func (t *reservationTracker) refreshLocked() {
+	if t.loader == nil || time.Since(t.loadedAt) < reservationRefreshInterval {
+		return
+	}
+	t.loadedAt = time.Now()
+
+	reservations, err := t.loader.ListReservedConcurrency()
+	if err != nil {
+		t.logger.WithError(err).Warn("Failed to load reserved concurrency, keeping previous reservations")
+		return
+	}
+
+	total := 0
+	for _, n := range reservations {
+		total += n
+	}
+	t.reserved = reservations
+	t.reservedTotal = total
+
+	if capacity := t.capacity(); total > capacity {
+		t.logger.WithFields(logrus.Fields{
+			"reserved": total,
+			"capacity": capacity,
+		}).Warn("Reserved concurrency exceeds scheduler capacity, shared capacity is exhausted")
+	}
+}
+
+// slotWaitlist 保存已从工作队列取出、但暂时没有可用并发槽位的工作项，按出队顺序排列。
+//
+// 工作协程不阻塞等待槽位：没有槽位的工作项放入等待列表后继续从队列取下一个工作项，
+// 因此共享槽位占满时，预留了并发的函数仍能被空闲的工作协程及时执行。
+// 工作协程每次取工作项前先检查等待列表，槽位释放后等待最久且能获得槽位的工作项优先执行。
+type slotWaitlist[T any] struct {
+	mu    sync.Mutex
+	items []T
+	size  int // 等待列表容量，超出时由调用方限流
+}
+
+// newSlotWaitlist 创建容量为 size 的等待列表。
+func newSlotWaitlist[T any](size int) *slotWaitlist[T] {
+	return &slotWaitlist[T]{size: size}
+}
+
+// park 将工作项放入等待列表，列表已满时返回 false。
+func (l *slotWaitlist[T]) park(item T) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.items) >= l.size {
+		return false
+	}
+	l.items = append(l.items, item)
+	return true
+}
+
+// take 按等待顺序返回第一个能获得并发槽位的工作项及释放槽位的函数。
+// 没有能获得槽位的工作项时 ok 为 false。
+func (l *slotWaitlist[T]) take(acquire func(T) (func(), bool)) (T, func(), bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, item := range l.items {
+		if release, ok := acquire(item); ok {
+			l.items = append(l.items[:i], l.items[i+1:]...)
+			return item, release, true
+		}
+	}
+	var zero T
+	return zero, nil, false
+}
+
+// len 返回等待中的工作项数。
+func (l *slotWaitlist[T]) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.items)
+}
@@ -0,0 +1,155 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+type staticReservations map[string]int
+
+func (r staticReservations) ListReservedConcurrency() (map[string]int, error) {
+	out := make(map[string]int, len(r))
+	for k, v := range r {
+		out[k] = v
+	}
+	return out, nil
+}
+
+func TestReservationTracker(t *testing.T) {
+	capacity := 4
+	tr := newReservationTracker(staticReservations{"critical": 2}, func() int { return capacity }, logrus.New())
+
+	// 共享容量 = 4 - 2 = 2，其他函数最多占用 2 个槽位
+	var releases []func()
+	for i := 0; i < 2; i++ {
+		release, ok := tr.acquire("noisy", 0)
+		if !ok {
+			t.Fatalf("noisy acquire %d failed", i)
+		}
+		releases = append(releases, release)
+	}
+	if _, ok := tr.acquire("noisy", 0); ok {
+		t.Fatalf("noisy function consumed reserved capacity")
+	}
+
+	// 关键函数仍能使用自己的预留槽位
+	for i := 0; i < 2; i++ {
+		if _, ok := tr.acquire("critical", 2); !ok {
+			t.Fatalf("critical acquire %d failed", i)
+		}
+	}
+	// 预留用尽且共享容量已满
+	if _, ok := tr.acquire("critical", 2); ok {
+		t.Fatalf("critical acquired beyond reservation with no shared capacity")
+	}
+
+	stats := tr.stats()
+	if stats.ReservedCapacity != 2 || stats.SharedCapacity != 2 || stats.AvailableCapacity != 0 {
+		t.Fatalf("stats = %+v, want reserved=2 shared=2 available=0", stats)
+	}
+
+	// 释放共享槽位后关键函数可以溢出到共享容量；重复释放不会多归还槽位
+	releases[0]()
+	releases[0]()
+	if _, ok := tr.acquire("critical", 2); !ok {
+		t.Fatalf("critical could not burst into shared capacity")
+	}
+	if _, ok := tr.acquire("noisy", 0); ok {
+		t.Fatalf("double release returned an extra slot")
+	}
+}
+
+func TestReservationTrackerUpdatesFromFunction(t *testing.T) {
+	tr := newReservationTracker(nil, func() int { return 3 }, logrus.New())
+
+	// 调用时携带的最新预留值会立即生效
+	if _, ok := tr.acquire("critical", 3); !ok {
+		t.Fatalf("critical acquire failed")
+	}
+	if _, ok := tr.acquire("other", 0); ok {
+		t.Fatalf("shared capacity should be zero when everything is reserved")
+	}
+	if got := tr.reservedTotalNow(); got != 3 {
+		t.Fatalf("reservedTotalNow() = %d, want 3", got)
+	}
+}
+
+// TestNextItemWaitsForSharedSlot 测试共享槽位占满时，已出队的非预留调用进入等待列表而不是被限流，
+// 预留了并发的函数仍能被调度，共享槽位释放后等待的调用按顺序执行。
+func TestNextItemWaitsForSharedSlot(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := &DockerScheduler{
+		ctx:          ctx,
+		workQueue:    newPriorityQueue[*dockerWorkItem](4),
+		slotWaiters:  newSlotWaitlist[*dockerWorkItem](4),
+		reservations: newReservationTracker(staticReservations{"critical": 1}, func() int { return 2 }, logrus.New()),
+		load:         newRuntimeLoadTracker(),
+	}
+	newItem := func(fnID string, reserved int) *dockerWorkItem {
+		return &dockerWorkItem{
+			invocation: &domain.Invocation{ID: "inv-" + fnID, FunctionID: fnID, TriggerType: domain.TriggerHTTP},
+			function:   &domain.Function{ID: fnID, Runtime: domain.RuntimePython311, ReservedConcurrency: reserved},
+			resultCh:   make(chan *domain.InvokeResponse, 1),
+			priority:   domain.PriorityHigh,
+		}
+	}
+
+	// 占满唯一的共享槽位（容量 2，预留 1）
+	held, ok := s.reservations.acquire("busy", 0)
+	if !ok {
+		t.Fatal("failed to occupy the shared slot")
+	}
+
+	noisy := newItem("noisy", 0)
+	critical := newItem("critical", 1)
+	s.enqueue(noisy)
+	s.enqueue(critical)
+
+	item, releaseCritical, ok := s.nextItem(nil)
+	if !ok || item != critical {
+		t.Fatalf("nextItem() = %v, want the reserved function's item", item)
+	}
+	select {
+	case resp := <-noisy.resultCh:
+		t.Fatalf("waiting sync invocation was throttled: %+v", resp)
+	default:
+	}
+	if s.slotWaiters.len() != 1 {
+		t.Fatalf("waiting items = %d, want 1", s.slotWaiters.len())
+	}
+
+	// 共享槽位释放后，等待的调用先于队列中的新调用执行
+	later := newItem("later", 0)
+	s.enqueue(later)
+	held()
+	item, releaseNoisy, ok := s.nextItem(nil)
+	if !ok || item != noisy {
+		t.Fatalf("nextItem() after release = %v, want the waiting item", item)
+	}
+	releaseNoisy()
+	releaseCritical()
+	if item, _, ok := s.nextItem(nil); !ok || item != later {
+		t.Fatalf("nextItem() = %v, want the queued item", item)
+	}
+	if s.slotWaiters.len() != 0 {
+		t.Fatalf("waiting items = %d, want 0", s.slotWaiters.len())
+	}
+}
+
+func TestSlotWaitlistFull(t *testing.T) {
+	l := newSlotWaitlist[string](1)
+	if !l.park("a") || l.park("b") {
+		t.Fatal("park should accept one item and reject when full")
+	}
+	if _, _, ok := l.take(func(string) (func(), bool) { return nil, false }); ok {
+		t.Fatal("take returned an item without a slot")
+	}
+	if item, _, ok := l.take(func(string) (func(), bool) { return func() {}, true }); !ok || item != "a" {
+		t.Fatalf("take = %q %v, want a", item, ok)
+	}
+}
@@ -0,0 +1,146 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+	"github.com/oriys/nimbus/internal/metrics"
+)
+
+// 平台故障重试结果，用作指标标签
+const (
+	platformRetryRetried   = "retried"          // 已退避并重试
+	platformRetryExhausted = "budget_exhausted" // 全局重试配额耗尽，放弃重试
+	platformRetryStarted   = "code_started"     // 函数代码已开始运行，重试可能重复执行，放弃重试
+)
+
+// maxPlatformRetryBackoff 单次重试退避时间的上限
+const maxPlatformRetryBackoff = 2 * time.Second
+
+// platformRetrier 对调用执行中的瞬时平台故障进行有限次数的透明重试。
+//
+// 只重试 domain.IsTransientPlatformError 判定的故障（获取实例排队超时、容器或虚拟机启动失败等），
+// 这些故障都发生在函数代码开始执行之前，重试不会让函数代码重复执行；函数异常和超时从不重试。
+// 执行器通过 domain.ExecutionTracker 上报函数代码是否已开始运行，已开始运行的尝试即使返回平台故障也不重试，
+// 保证非幂等函数不会被执行两次。
+// 每次重试前按指数退避等待，且所有调用共享一个令牌桶，平台大面积故障时重试速率受限，不会放大负载。
+type platformRetrier struct {
+	maxRetries int           // 每次调用最多重试的次数
+	backoff    time.Duration // 首次重试前的等待时间，之后每次翻倍
+	rate       float64       // 令牌桶每秒补充的重试配额
+	burst      float64       // 令牌桶容量
+	metrics    *metrics.Metrics
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// newPlatformRetrier 创建平台故障重试器。
+//
+// 参数:
+//   - maxRetries: 每次调用最多重试的次数，小于等于 0 时不重试
+//   - backoff: 首次重试前的等待时间
+//   - rate: 整个调度器每秒允许的重试次数
+//   - m: 指标收集器，可为 nil
+func newPlatformRetrier(maxRetries int, backoff time.Duration, rate float64, m *metrics.Metrics) *platformRetrier {
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	burst := rate
+	if burst < 1 {
+		burst = 1
+	}
+	return &platformRetrier{
+		maxRetries: maxRetries,
+		backoff:    backoff,
+		rate:       rate,
+		burst:      burst,
+		metrics:    m,
+		tokens:     burst,
+		now:        time.Now,
+	}
+}
+
+// allow 从全局令牌桶中消耗一个重试配额，配额不足时返回 false。
+func (r *platformRetrier) allow() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if !r.last.IsZero() {
+		r.tokens += now.Sub(r.last).Seconds() * r.rate
+		if r.tokens > r.burst {
+			r.tokens = r.burst
+		}
+	}
+	r.last = now
+	if r.tokens < 1 {
+		return false
+	}
+	r.tokens--
+	return true
+}
+
+// do 执行 fn，遇到瞬时平台故障时退避后重试。
+// 上下文已结束、重试次数用完或全局配额耗尽时返回最后一次的错误。
+//
+// 参数:
+//   - ctx: 调用上下文，退避等待期间取消时立即返回
+//   - runtime: 函数运行时，用于指标标签
+//   - logger: 日志记录器
+//   - tracker: 执行跟踪器，每次尝试前重置；为 nil 表示 fn 不会运行函数代码（如只获取执行实例）
+//   - fn: 执行一次调用，返回执行器的错误
+//
+// 返回值:
+//   - int: 实际重试的次数
+//   - error: 最后一次执行的错误
+func (r *platformRetrier) do(ctx context.Context, runtime string, logger *logrus.Entry, tracker *domain.ExecutionTracker, fn func() error) (int, error) {
+	backoff := r.backoff
+	for retries := 0; ; retries++ {
+		tracker.Reset()
+		err := fn()
+		if err == nil || retries >= r.maxRetries || ctx.Err() != nil || !domain.IsTransientPlatformError(err) {
+			return retries, err
+		}
+		if tracker.Started() {
+			r.record(runtime, platformRetryStarted)
+			logger.WithError(err).Warn("Platform failure after function code started, not retrying")
+			return retries, err
+		}
+		if !r.allow() {
+			r.record(runtime, platformRetryExhausted)
+			logger.WithError(err).Warn("Platform retry budget exhausted, not retrying")
+			return retries, err
+		}
+		r.record(runtime, platformRetryRetried)
+		logger.WithError(err).WithFields(logrus.Fields{
+			"attempt": retries + 1,
+			"backoff": backoff.String(),
+		}).Warn("Transient platform failure, retrying invocation")
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return retries, err
+		}
+		backoff *= 2
+		if backoff > maxPlatformRetryBackoff {
+			backoff = maxPlatformRetryBackoff
+		}
+	}
+}
+
+// record 记录一次重试决策的指标。
+func (r *platformRetrier) record(runtime, result string) {
+	if r.metrics != nil {
+		r.metrics.RecordPlatformRetry(runtime, result)
+	}
+}
@@ -0,0 +1,110 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+func TestPlatformRetrier(t *testing.T) {
+	logger := logrus.NewEntry(logrus.New())
+	transient := fmt.Errorf("%w: docker start: exit status 1", domain.ErrContainerStartFailed)
+
+	// 瞬时故障重试后成功
+	r := newPlatformRetrier(2, time.Millisecond, 100, nil)
+	calls := 0
+	retries, err := r.do(context.Background(), "python3.11", logger, nil, func() error {
+		calls++
+		if calls < 3 {
+			return transient
+		}
+		return nil
+	})
+	if err != nil || retries != 2 || calls != 3 {
+		t.Fatalf("retries=%d calls=%d err=%v, want 2 3 nil", retries, calls, err)
+	}
+
+	// 重试次数用完后返回最后一次的错误
+	calls = 0
+	retries, err = r.do(context.Background(), "python3.11", logger, nil, func() error {
+		calls++
+		return transient
+	})
+	if !errors.Is(err, domain.ErrContainerStartFailed) || retries != 2 || calls != 3 {
+		t.Fatalf("retries=%d calls=%d err=%v, want 2 3 container start failed", retries, calls, err)
+	}
+
+	// 非平台故障从不重试
+	calls = 0
+	retries, err = r.do(context.Background(), "python3.11", logger, nil, func() error {
+		calls++
+		return context.DeadlineExceeded
+	})
+	if retries != 0 || calls != 1 || err != context.DeadlineExceeded {
+		t.Fatalf("retries=%d calls=%d err=%v, want 0 1 deadline exceeded", retries, calls, err)
+	}
+}
+
+func TestPlatformRetrierBudget(t *testing.T) {
+	logger := logrus.NewEntry(logrus.New())
+	now := time.Unix(0, 0)
+	r := newPlatformRetrier(5, time.Millisecond, 1, nil)
+	r.now = func() time.Time { return now }
+
+	// 每秒只补充 1 个配额：第一次调用重试一次后配额耗尽
+	calls := 0
+	retries, err := r.do(context.Background(), "go1.x", logger, nil, func() error {
+		calls++
+		return domain.ErrQueueTimeout
+	})
+	if retries != 1 || calls != 2 || !errors.Is(err, domain.ErrQueueTimeout) {
+		t.Fatalf("retries=%d calls=%d err=%v, want 1 2 queue timeout", retries, calls, err)
+	}
+
+	// 一秒后配额恢复
+	now = now.Add(time.Second)
+	if !r.allow() {
+		t.Fatalf("retry budget was not refilled")
+	}
+	if r.allow() {
+		t.Fatalf("retry budget exceeded burst")
+	}
+}
+
+func TestPlatformRetrierExecutionStarted(t *testing.T) {
+	logger := logrus.NewEntry(logrus.New())
+	r := newPlatformRetrier(2, time.Millisecond, 100, nil)
+	tracker := &domain.ExecutionTracker{}
+	ctx := domain.WithExecutionTracker(context.Background(), tracker)
+
+	// 函数代码开始运行之后的平台故障不重试，避免非幂等函数被执行两次
+	calls := 0
+	retries, err := r.do(ctx, "python3.11", logger, tracker, func() error {
+		calls++
+		domain.MarkExecutionStarted(ctx)
+		return domain.ErrStorageConnection
+	})
+	if retries != 0 || calls != 1 || !errors.Is(err, domain.ErrStorageConnection) {
+		t.Fatalf("retries=%d calls=%d err=%v, want 0 1 storage connection", retries, calls, err)
+	}
+
+	// 函数代码运行之前的故障照常重试，每次尝试前重置标记
+	calls = 0
+	retries, err = r.do(ctx, "python3.11", logger, tracker, func() error {
+		calls++
+		if calls == 1 {
+			return domain.ErrContainerStartFailed
+		}
+		domain.MarkExecutionStarted(ctx)
+		return nil
+	})
+	if err != nil || retries != 1 || calls != 2 {
+		t.Fatalf("retries=%d calls=%d err=%v, want 1 2 nil", retries, calls, err)
+	}
+}
@@ -1,6 +1,3 @@
-//go:build linux
-// +build linux
-
 // Package scheduler 提供函数调度器的实现。
 package scheduler
 
@@ -15,10 +12,15 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// aliasLoader 加载函数别名，由 storage.PostgresStore 实现。
+type aliasLoader interface {
+	GetFunctionAlias(functionID, name string) (*domain.FunctionAlias, error)
+}
+
 // TrafficRouter 负责根据别名配置进行流量路由。
-// 支持加权随机选择，实现金丝雀发布和 A/B 测试。
+// 支持加权随机选择，实现金丝雀发布和 A/B 测试。Firecracker 和 Docker 调度器共用同一实现。
 type TrafficRouter struct {
-	store    *storage.PostgresStore
+	store    aliasLoader
 	cache    map[string]*cachedAlias // functionID:aliasName -> alias
 	cacheMu  sync.RWMutex
 	cacheTTL time.Duration
@@ -44,13 +46,17 @@ func NewTrafficRouter(store *storage.PostgresStore, logger *logrus.Logger) *Traf
 }
 
 // SelectVersion 根据别名选择要执行的版本号
+// routingKey 非空时按路由键哈希选择，相同路由键总是命中同一版本；否则加权随机选择
 // 返回选中的版本号
-func (r *TrafficRouter) SelectVersion(ctx context.Context, functionID, aliasName string) (int, error) {
+func (r *TrafficRouter) SelectVersion(ctx context.Context, functionID, aliasName, routingKey string) (int, error) {
 	alias, err := r.getAlias(ctx, functionID, aliasName)
 	if err != nil {
 		return 0, err
 	}
 
+	if routingKey != "" {
+		return domain.SelectWeightedVersion(alias.RoutingConfig.Weights, domain.RoutingBucket(functionID, aliasName, routingKey)), nil
+	}
 	return r.weightedSelect(alias.RoutingConfig.Weights), nil
 }
 
@@ -92,20 +98,8 @@ func (r *TrafficRouter) weightedSelect(weights []domain.VersionWeight) int {
 		return weights[0].Version
 	}
 
-	// 生成 0-99 的随机数
-	random := r.rng.Intn(100)
-
-	// 累计权重选择
-	cumulative := 0
-	for _, w := range weights {
-		cumulative += w.Weight
-		if random < cumulative {
-			return w.Version
-		}
-	}
-
-	// 兜底返回第一个
-	return weights[0].Version
+	// 生成 0-99 的随机数，按累计权重选择
+	return domain.SelectWeightedVersion(weights, r.rng.Intn(100))
 }
 
 // InvalidateCache 使指定别名的缓存失效
@@ -0,0 +1,141 @@
+// Package scheduler 提供函数调度器的实现。
+package scheduler
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/oriys/nimbus/internal/metrics"
+)
+
+// RuntimeLoad 描述单个运行时的调度负载。
+type RuntimeLoad struct {
+	Runtime  string `json:"runtime"`   // 运行时
+	Queued   int    `json:"queued"`    // 在本地工作队列中等待的调用数
+	InFlight int    `json:"in_flight"` // 正在执行的调用数
+	Load     int    `json:"load"`      // 排队与执行中的调用数之和
+}
+
+// ScalingMetrics 描述供外部自动扩缩容器（Kubernetes HPA/KEDA）使用的扩缩容信号。
+type ScalingMetrics struct {
+	Workers     int           `json:"workers"`     // 当前配置的工作协程数量，即本实例的并发容量
+	Queued      int           `json:"queued"`      // 排队中的调用总数
+	InFlight    int           `json:"in_flight"`   // 执行中的调用总数
+	Load        int           `json:"load"`        // 排队与执行中的调用总数
+	Utilization float64       `json:"utilization"` // 负载与并发容量之比，大于 1 表示有调用在排队等待
+	Runtimes    []RuntimeLoad `json:"runtimes"`    // 各运行时的负载，按运行时名称排序
+}
+
+// DesiredReplicas 按目标利用率计算维持当前负载所需的实例数（至少为 1）。
+// 例如负载为 30、每个实例 10 个工作协程、目标利用率 0.75 时需要 4 个实例。
+//
+// 参数:
+//   - target: 目标利用率，取值 (0, 1]，非法值按 1 处理
+func (m ScalingMetrics) DesiredReplicas(target float64) int {
+	if target <= 0 || target > 1 {
+		target = 1
+	}
+	if m.Workers <= 0 {
+		return 1
+	}
+	n := int(math.Ceil(float64(m.Load) / (float64(m.Workers) * target)))
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// runtimeLoadTracker 按运行时统计排队和执行中的调用数。
+type runtimeLoadTracker struct {
+	mu       sync.Mutex
+	queued   map[string]int
+	inFlight map[string]int
+}
+
+// newRuntimeLoadTracker 创建运行时负载统计器。
+func newRuntimeLoadTracker() *runtimeLoadTracker {
+	return &runtimeLoadTracker{
+		queued:   make(map[string]int),
+		inFlight: make(map[string]int),
+	}
+}
+
+// enqueued 记录一个调用进入工作队列。
+func (t *runtimeLoadTracker) enqueued(runtime string) {
+	t.mu.Lock()
+	t.queued[runtime]++
+	t.mu.Unlock()
+}
+
+// dequeued 记录一个调用离开工作队列。
+func (t *runtimeLoadTracker) dequeued(runtime string) {
+	t.mu.Lock()
+	if t.queued[runtime] > 0 {
+		t.queued[runtime]--
+	}
+	t.mu.Unlock()
+}
+
+// started 记录一个调用开始执行。
+func (t *runtimeLoadTracker) started(runtime string) {
+	t.mu.Lock()
+	t.inFlight[runtime]++
+	t.mu.Unlock()
+}
+
+// finished 记录一个调用执行结束。
+func (t *runtimeLoadTracker) finished(runtime string) {
+	t.mu.Lock()
+	if t.inFlight[runtime] > 0 {
+		t.inFlight[runtime]--
+	}
+	t.mu.Unlock()
+}
+
+// snapshot 返回各运行时的负载，结果按运行时名称排序。
+// includeIdle 为 false 时没有负载的运行时不出现。
+func (t *runtimeLoadTracker) snapshot(includeIdle bool) []RuntimeLoad {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	loads := make([]RuntimeLoad, 0, len(t.queued)+len(t.inFlight))
+	seen := make(map[string]bool)
+	for _, counts := range []map[string]int{t.queued, t.inFlight} {
+		for rt := range counts {
+			if seen[rt] {
+				continue
+			}
+			seen[rt] = true
+			l := RuntimeLoad{Runtime: rt, Queued: t.queued[rt], InFlight: t.inFlight[rt]}
+			l.Load = l.Queued + l.InFlight
+			if includeIdle || l.Load > 0 {
+				loads = append(loads, l)
+			}
+		}
+	}
+	sort.Slice(loads, func(i, j int) bool { return loads[i].Runtime < loads[j].Runtime })
+	return loads
+}
+
+// scalingMetrics 根据工作协程数量和各运行时负载汇总扩缩容信号。
+func (t *runtimeLoadTracker) scalingMetrics(workers int) ScalingMetrics {
+	m := ScalingMetrics{Workers: workers, Runtimes: t.snapshot(false)}
+	for _, l := range m.Runtimes {
+		m.Queued += l.Queued
+		m.InFlight += l.InFlight
+	}
+	m.Load = m.Queued + m.InFlight
+	if workers > 0 {
+		m.Utilization = float64(m.Load) / float64(workers)
+	}
+	return m
+}
+
+// report 上报各运行时排队和执行中的调用数指标，负载降为 0 的运行时上报 0。
+func (t *runtimeLoadTracker) report(m *metrics.Metrics) {
+	for _, l := range t.snapshot(true) {
+		m.SchedulerRuntimeLoad.WithLabelValues(l.Runtime, "queued").Set(float64(l.Queued))
+		m.SchedulerRuntimeLoad.WithLabelValues(l.Runtime, "in_flight").Set(float64(l.InFlight))
+	}
+}
@@ -0,0 +1,50 @@
+package scheduler
+
+import "testing"
+
+func TestRuntimeLoadTracker(t *testing.T) {
+	tr := newRuntimeLoadTracker()
+	tr.enqueued("python3.11")
+	tr.enqueued("python3.11")
+	tr.enqueued("nodejs20")
+	tr.dequeued("python3.11")
+	tr.started("python3.11")
+	tr.dequeued("nodejs20")
+	tr.started("nodejs20")
+	tr.finished("nodejs20")
+
+	m := tr.scalingMetrics(2)
+	if m.Queued != 1 || m.InFlight != 1 || m.Load != 2 || m.Utilization != 1 {
+		t.Fatalf("scalingMetrics = %+v, want queued=1 in_flight=1 load=2 utilization=1", m)
+	}
+	// 没有负载的运行时不出现
+	if len(m.Runtimes) != 1 || m.Runtimes[0].Runtime != "python3.11" {
+		t.Fatalf("runtimes = %+v, want only python3.11", m.Runtimes)
+	}
+	// 计数不会变为负数
+	tr.finished("nodejs20")
+	tr.dequeued("nodejs20")
+	if got := tr.snapshot(true); len(got) != 2 || got[0].Load != 0 {
+		t.Errorf("snapshot(true) = %+v, want idle nodejs20 with zero load", got)
+	}
+}
+
+func TestScalingMetricsDesiredReplicas(t *testing.T) {
+	tests := []struct {
+		load, workers int
+		target        float64
+		want          int
+	}{
+		{load: 30, workers: 10, target: 0.75, want: 4},
+		{load: 10, workers: 10, target: 1, want: 1},
+		{load: 0, workers: 10, target: 0.5, want: 1},
+		{load: 11, workers: 10, target: 2, want: 2},
+		{load: 5, workers: 0, target: 1, want: 1},
+	}
+	for _, tt := range tests {
+		m := ScalingMetrics{Workers: tt.workers, Load: tt.load}
+		if got := m.DesiredReplicas(tt.target); got != tt.want {
+			t.Errorf("DesiredReplicas(load=%d, workers=%d, target=%v) = %d, want %d", tt.load, tt.workers, tt.target, got, tt.want)
+		}
+	}
+}
@@ -48,9 +48,18 @@ type Scheduler struct {
 	metrics   *metrics.Metrics         // 指标收集器，用于记录调度器性能指标
 	logger    *logrus.Logger           // 日志记录器
 
-	workQueue chan *workItem           // 工作队列，存放待处理的调用请求
-	workers   []*worker                // 工作协程列表
-	wg        sync.WaitGroup           // 等待组，用于优雅关闭时等待所有工作协程完成
+	initFailures *initFailureTracker    // 连续初始化失败跟踪器，用于标记 degraded 函数
+	breakers     *circuitBreakerTracker // 函数级熔断跟踪器，错误率过高时下线函数
+	shadow       *shadowMirror          // 影子流量回放器
+	retrier      *platformRetrier       // 瞬时平台故障重试器
+	reservations *reservationTracker    // 函数预留并发跟踪器
+	concurrency  *concurrencyLimiter    // 函数最大并发数限制器
+	load         *runtimeLoadTracker    // 按运行时统计的排队与执行中调用数
+
+	workQueue   *priorityQueue[*workItem] // 工作队列，按调用优先级分道存放待处理的调用请求
+	slotWaiters *slotWaitlist[*workItem]  // 已出队但等待并发槽位的工作项
+	workers     *workerPool               // 工作协程池，支持运行时扩缩容
+	wg          sync.WaitGroup            // 等待组，用于优雅关闭时等待所有工作协程完成
 
 	ctx    context.Context             // 调度器上下文，用于控制生命周期
 	cancel context.CancelFunc          // 取消函数，用于停止调度器
@@ -63,6 +72,7 @@ type workItem struct {
 	function   *domain.Function                // 函数定义，包含运行时、处理器、超时配置等
 	version    *domain.FunctionVersion         // 要执行的版本（如果指定了版本/别名）
 	resultCh   chan *domain.InvokeResponse     // 结果通道，用于同步调用时返回执行结果；异步调用时为 nil
+	priority   domain.InvocationPriority       // 调度优先级，由触发来源和函数配置决定
 }
 
 // worker 表示一个工作协程。
@@ -97,17 +107,30 @@ func NewScheduler(
 
 	// 初始化调度器实例
 	s := &Scheduler{
-		cfg:       cfg,
-		store:     store,
-		redis:     redis,
-		pool:      pool,
-		router:    NewTrafficRouter(store, logger),
-		metrics:   m,
-		logger:    logger,
-		workQueue: make(chan *workItem, cfg.QueueSize), // 创建带缓冲的工作队列
-		ctx:       ctx,
-		cancel:    cancel,
+		cfg:          cfg,
+		store:        store,
+		redis:        redis,
+		pool:         pool,
+		router:       NewTrafficRouter(store, logger),
+		metrics:      m,
+		logger:       logger,
+		initFailures: newInitFailureTracker(cfg.InitFailureThreshold, store, logger),
+		breakers:     newCircuitBreakerTracker(store, redis, logger),
+		shadow:       newShadowMirror(cfg, store, m, logger),
+		retrier:      newPlatformRetrier(cfg.PlatformRetries, cfg.PlatformRetryBackoff, cfg.PlatformRetryRate, m),
+		workQueue:    newPriorityQueue[*workItem](cfg.QueueSize), // 创建按优先级分道的工作队列
+		slotWaiters:  newSlotWaitlist[*workItem](cfg.QueueSize),
+		ctx:          ctx,
+		cancel:       cancel,
 	}
+	s.workers = newWorkerPool(&s.wg, m, func(id int, stop <-chan struct{}) {
+		w := &worker{id: id, scheduler: s}
+		w.run(stop)
+	})
+	// 并发总容量等于工作协程数量，预留槽位从中扣除
+	s.reservations = newReservationTracker(store, s.workers.size, logger)
+	s.concurrency = newConcurrencyLimiter(redis, m, logger, cfg.DefaultTimeout, cfg.ConcurrencyQueueTimeout)
+	s.load = newRuntimeLoadTracker()
 
 	return s
 }
@@ -119,23 +142,62 @@ func NewScheduler(
 //   - error: 启动过程中的错误，当前实现始终返回 nil
 func (s *Scheduler) Start() error {
 	// 启动工作协程池
-	s.workers = make([]*worker, s.cfg.Workers)
-	for i := 0; i < s.cfg.Workers; i++ {
-		w := &worker{id: i, scheduler: s}
-		s.workers[i] = w
-		s.wg.Add(1)
-		go w.run() // 启动工作协程
-	}
-	// 如果启用了指标收集，初始化工作协程数量指标并启动指标上报协程
+	s.workers.scale(s.cfg.Workers)
+	// 如果启用了指标收集，启动指标上报协程
 	if s.metrics != nil {
-		s.metrics.SchedulerWorkers.Set(float64(s.cfg.Workers))
 		go s.metricsWorker()
 	}
+	// 启动 keep_warm 协调协程，由虚拟机池维持常驻预热实例；虚拟机池属于本实例，每个实例各自协调
+	go newKeepWarmReconciler(s.store, s.pool, s.logger).run(s.ctx)
 
 	s.logger.WithField("workers", s.cfg.Workers).Info("Scheduler started")
 	return nil
 }
 
+// ScaleWorkers 在运行时调整工作协程数量。
+// 缩容时多余的协程会在处理完当前任务后退出。
+//
+// 参数:
+//   - n: 目标工作协程数量，必须在 [1, MaxWorkers] 范围内
+//
+// 返回值:
+//   - error: 数量超出范围时返回 ErrInvalidWorkerCount，
+//     低于函数预留并发总和时返回 domain.ErrReservedConcurrencyExceedsCapacity
+func (s *Scheduler) ScaleWorkers(n int) error {
+	if err := validateWorkerCount(n, s.cfg.MaxWorkers); err != nil {
+		return err
+	}
+	if n < s.reservations.reservedTotalNow() {
+		return domain.ErrReservedConcurrencyExceedsCapacity
+	}
+	previous := s.workers.size()
+	s.workers.scale(n)
+	s.logger.WithFields(logrus.Fields{
+		"previous": previous,
+		"workers":  n,
+	}).Info("Scheduler workers scaled")
+	return nil
+}
+
+// WorkerStats 返回工作协程池和队列的当前状态。
+func (s *Scheduler) WorkerStats() WorkerStats {
+	return WorkerStats{
+		ConfiguredWorkers: s.workers.size(),
+		ActiveWorkers:     s.workers.active(),
+		MaxWorkers:        s.cfg.MaxWorkers,
+		QueueLength:       s.workQueue.len(),
+		QueueCap:          s.workQueue.cap(),
+		QueueByPriority:   s.workQueue.depthByPriority(),
+		QueueBySource:     s.workQueue.depthBySource(),
+		ReservationStats:  s.reservations.stats(),
+	}
+}
+
+// ScalingMetrics 返回供外部自动扩缩容器使用的负载信号（排队与执行中的调用数及其与并发容量之比）。
+func (s *Scheduler) ScalingMetrics() ScalingMetrics {
+	return s.load.scalingMetrics(s.workers.size())
+}
+
 // metricsWorker 定期收集并上报调度器队列大小指标。
 // 该方法在独立的协程中运行，每秒更新一次队列大小。
 func (s *Scheduler) metricsWorker() {
@@ -148,8 +210,9 @@ func (s *Scheduler) metricsWorker() {
 			// 调度器停止，退出指标收集循环
 			return
 		case <-ticker.C:
-			// 更新队列大小指标
-			s.metrics.SchedulerQueueSize.Set(float64(len(s.workQueue)))
+			// 更新队列大小和各触发来源的排队深度指标
+			s.workQueue.reportDepth(s.metrics)
+			s.load.report(s.metrics)
 		}
 	}
 }
@@ -164,12 +227,15 @@ func (s *Scheduler) metricsWorker() {
 // 返回值:
 //   - error: 停止过程中的错误，当前实现始终返回 nil
 func (s *Scheduler) Stop() error {
+	s.workers.close()   // 停止接受扩容请求
 	s.cancel()          // 发送取消信号
-	close(s.workQueue)  // 关闭工作队列，通知工作协程退出
+	s.workQueue.close() // 关闭工作队列，通知工作协程退出
 	s.wg.Wait()         // 等待所有工作协程完成
+	s.shadow.close()    // 关闭影子回放队列
 	// 重置指标
 	if s.metrics != nil {
 		s.metrics.SchedulerQueueSize.Set(0)
+		s.metrics.SchedulerQueueDepth.Reset()
 		s.metrics.SchedulerWorkers.Set(0)
 	}
 	s.logger.Info("Scheduler stopped")
@@ -191,13 +257,32 @@ func (s *Scheduler) Stop() error {
 //
 // 返回值:
 //   - *domain.InvokeResponse: 函数执行结果，包含状态码、响应体、执行时间等
-//   - error: 调用过程中的错误，如函数不存在、队列已满等
+//   - error: 调用过程中的错误，如函数不存在、队列已满等；
+//     函数处于维护窗口内时返回 *domain.MaintenanceError；
+//     紧急停止开关开启时返回 *domain.KillSwitchError
 func (s *Scheduler) Invoke(req *domain.InvokeRequest) (*domain.InvokeResponse, error) {
+	// 紧急停止开关开启时立即拒绝调用
+	if err := checkKillSwitch(s.redis, s.metrics, s.logger, req.FunctionID); err != nil {
+		return nil, err
+	}
+
+	// 虚拟机在初始化阶段加载层，不支持按调用覆盖
+	if req.Layers != nil {
+		return nil, domain.ErrLayerOverrideUnsupported
+	}
+
 	// 从存储中获取函数定义
 	fn, err := s.store.GetFunctionByID(req.FunctionID)
 	if err != nil {
 		return nil, err
 	}
+	// 按调用所在环境覆盖内存、超时和环境变量
+	fn.ApplyEnvConfig(req.EnvConfig)
+
+	// 拦截疑似无限递归的嵌套调用
+	if err := checkRecursion(s.cfg, s.metrics, s.logger, fn, req.CallChain); err != nil {
+		return nil, err
+	}
 
 	// 解析版本
 	version, aliasUsed, versionData, err := s.resolveVersion(fn, req)
@@ -206,8 +291,13 @@ func (s *Scheduler) Invoke(req *domain.InvokeRequest) (*domain.InvokeResponse, e
 	}
 
 	// 创建调用记录，用于追踪调用状态和持久化
-	inv := domain.NewInvocation(fn.ID, fn.Name, domain.TriggerHTTP, req.Payload)
+	inv := domain.NewInvocation(fn.ID, fn.Name, req.TriggerSource(), req.EventPayload())
 	inv.ID = uuid.New().String()
+	inv.CostTags = req.CostTags
+	inv.Pipe = req.Pipe
+	inv.CallChain = req.CallChain
+	inv.WorkflowExecutionID = req.WorkflowExecutionID
+	inv.WorkflowState = req.WorkflowState
 	inv.Version = version
 	inv.AliasUsed = aliasUsed
 	inv.SessionKey = req.SessionKey // 设置会话标识（有状态函数）
@@ -217,6 +307,12 @@ func (s *Scheduler) Invoke(req *domain.InvokeRequest) (*domain.InvokeResponse, e
 		return nil, fmt.Errorf("failed to create invocation: %w", err)
 	}
 
+	// 处于维护窗口内时不执行，记录为 skipped 并返回维护错误
+	if merr := domain.CheckMaintenance(fn, time.Now()); merr != nil {
+		skipForMaintenance(s.store, s.logger, inv, merr)
+		return nil, merr
+	}
+
 	// 创建工作项，包含结果通道用于接收执行结果
 	resultCh := make(chan *domain.InvokeResponse, 1)
 	item := &workItem{
@@ -224,14 +320,11 @@ func (s *Scheduler) Invoke(req *domain.InvokeRequest) (*domain.InvokeResponse, e
 		function:   fn,
 		version:    versionData,
 		resultCh:   resultCh,
+		priority:   domain.PriorityFor(fn, inv.TriggerType, true),
 	}
 
-	// 非阻塞方式提交工作项到队列
-	select {
-	case s.workQueue <- item:
-		// 成功提交到队列
-	default:
-		// 队列已满，返回错误
+	// 非阻塞方式提交工作项到队列，对应优先级的队列已满时返回错误
+	if !s.enqueue(item) {
 		return nil, fmt.Errorf("work queue is full")
 	}
 
@@ -244,19 +337,21 @@ func (s *Scheduler) Invoke(req *domain.InvokeRequest) (*domain.InvokeResponse, e
 	// 等待执行结果或超时
 	select {
 	case resp := <-resultCh:
-		// 成功获取执行结果
+		// 成功获取执行结果，按配置将请求回放到影子目标
+		s.shadow.mirror(fn, req, resp, s.Invoke)
 		return resp, nil
-	case <-time.After(timeout + 5*time.Second):
+	case <-time.After(timeout + 5*time.Second + s.concurrency.queueWait(fn)):
 		// 超时处理：更新调用状态并返回超时响应
 		inv.Timeout()
 		s.store.UpdateInvocation(inv)
 		return &domain.InvokeResponse{
-			RequestID:  inv.ID,
-			StatusCode: 504, // Gateway Timeout
-			Error:      "function execution timed out",
-			Version:    version,
-			AliasUsed:  aliasUsed,
-			SessionKey: req.SessionKey,
+			RequestID:     inv.ID,
+			StatusCode:    504, // Gateway Timeout
+			Error:         "function execution timed out",
+			Version:       version,
+			AliasUsed:     aliasUsed,
+			RoutedVersion: routedVersion(inv),
+			SessionKey:    req.SessionKey,
 		}, nil
 	}
 }
@@ -277,12 +372,26 @@ func (s *Scheduler) Invoke(req *domain.InvokeRequest) (*domain.InvokeResponse, e
 // 返回值:
 //   - string: 调用ID，可用于后续查询调用状态和结果
 //   - error: 调用过程中的错误，如函数不存在、队列和Redis都不可用等
+//
+// 函数处于维护窗口内时，调用记录保持 pending，待窗口结束后再提交到工作队列。
 func (s *Scheduler) InvokeAsync(req *domain.InvokeRequest) (string, error) {
+	// 紧急停止开关开启时立即拒绝调用
+	if err := checkKillSwitch(s.redis, s.metrics, s.logger, req.FunctionID); err != nil {
+		return "", err
+	}
+
 	// 从存储中获取函数定义
 	fn, err := s.store.GetFunctionByID(req.FunctionID)
 	if err != nil {
 		return "", err
 	}
+	// 按调用所在环境覆盖内存、超时和环境变量
+	fn.ApplyEnvConfig(req.EnvConfig)
+
+	// 拦截疑似无限递归的嵌套调用
+	if err := checkRecursion(s.cfg, s.metrics, s.logger, fn, req.CallChain); err != nil {
+		return "", err
+	}
 
 	// 解析版本
 	version, aliasUsed, versionData, err := s.resolveVersion(fn, req)
@@ -291,8 +400,13 @@ func (s *Scheduler) InvokeAsync(req *domain.InvokeRequest) (string, error) {
 	}
 
 	// 创建调用记录
-	inv := domain.NewInvocation(fn.ID, fn.Name, domain.TriggerHTTP, req.Payload)
+	inv := domain.NewInvocation(fn.ID, fn.Name, req.TriggerSource(), req.EventPayload())
 	inv.ID = uuid.New().String()
+	inv.CostTags = req.CostTags
+	inv.Pipe = req.Pipe
+	inv.CallChain = req.CallChain
+	inv.WorkflowExecutionID = req.WorkflowExecutionID
+	inv.WorkflowState = req.WorkflowState
 	inv.Version = version
 	inv.AliasUsed = aliasUsed
 	inv.SessionKey = req.SessionKey // 设置会话标识（有状态函数）
@@ -308,21 +422,84 @@ func (s *Scheduler) InvokeAsync(req *domain.InvokeRequest) (string, error) {
 		function:   fn,
 		version:    versionData,
 		resultCh:   nil, // 异步调用不需要等待结果
+		priority:   domain.PriorityFor(fn, inv.TriggerType, false),
 	}
 
-	// 尝试提交到工作队列
-	select {
-	case s.workQueue <- item:
-		// 成功提交到队列
-		return inv.ID, nil
-	default:
-		// 队列已满，将调用ID推送到Redis作为备用队列
-		// 后续可由其他工作进程从Redis拉取并处理
-		if err := s.redis.PushInvocation(context.Background(), inv.ID); err != nil {
-			return "", fmt.Errorf("work queue is full and failed to push to redis: %w", err)
+	// 函数已暂停时放入暂停队列，恢复后再执行
+	if fn.Status == domain.FunctionStatusPaused {
+		if err := holdPausedInvocation(s.redis, s.store, s.logger, inv); err != nil {
+			return "", err
 		}
 		return inv.ID, nil
 	}
+
+	// 处于维护窗口内时延迟到窗口结束后再提交
+	if merr := domain.CheckMaintenance(fn, time.Now()); merr != nil {
+		deferUntilMaintenanceEnds(s.ctx, s.store, s.logger, inv, merr, func() {
+			s.enqueueAsync(item)
+		})
+		return inv.ID, nil
+	}
+
+	if err := s.enqueueAsync(item); err != nil {
+		return "", err
+	}
+	return inv.ID, nil
+}
+
+// ResumePaused 在后台按入队顺序提交函数暂停期间积压的异步调用。
+// 调用记录中的版本在提交时重新加载，版本已不存在时调用标记为失败；工作队列已满时等待空位，不会推送到 Redis 备用队列。
+//
+// 参数:
+//   - functionID: 已恢复的函数 ID
+func (s *Scheduler) ResumePaused(functionID string) {
+	if s.redis == nil {
+		return
+	}
+	go drainPausedInvocations(s.ctx, s.redis, s.store, s.logger, functionID, func(inv *domain.Invocation, fn *domain.Function) bool {
+		item := &workItem{invocation: inv, function: fn, priority: domain.PriorityFor(fn, inv.TriggerType, false)}
+		if inv.Version > 0 {
+			versionData, err := s.store.GetFunctionVersion(fn.ID, inv.Version)
+			if err != nil {
+				// 版本已删除时不退回执行最新代码，直接标记失败
+				inv.Fail(fmt.Sprintf("version %d not found: %v", inv.Version, err))
+				if uerr := s.store.UpdateInvocation(inv); uerr != nil {
+					s.logger.WithError(uerr).Warn("Failed to mark paused invocation as failed")
+				}
+				return true
+			}
+			item.version = versionData
+		}
+		return submitWhenReady(s.ctx, func() bool {
+			return s.enqueue(item)
+		})
+	})
+}
+
+// enqueueAsync 将异步调用提交到工作队列，队列已满时推送到 Redis 备用队列。
+// 两者都不可用时将调用标记为失败并返回 domain.ErrAsyncQueueUnavailable。
+func (s *Scheduler) enqueueAsync(item *workItem) error {
+	inv := item.invocation
+	if s.enqueue(item) {
+		// 成功提交到队列
+		return nil
+	}
+	// 队列已满，将调用ID推送到Redis作为备用队列
+	// 后续可由其他工作进程从Redis拉取并处理
+	if err := pushOverflowInvocation(s.redis, inv.ID); err != nil {
+		rejectAsyncInvocation(s.store, s.logger, inv, "async queue", err)
+		return fmt.Errorf("%w: work queue is full and failed to push to redis: %v", domain.ErrAsyncQueueUnavailable, err)
+	}
+	return nil
+}
+
+// enqueue 以非阻塞方式将工作项提交到对应优先级的工作队列，队列已满时返回 false。
+func (s *Scheduler) enqueue(item *workItem) bool {
+	if !s.workQueue.push(item.priority, item.invocation.TriggerType, item) {
+		return false
+	}
+	s.load.enqueued(string(item.function.Runtime))
+	return true
 }
 
 // resolveVersion 解析要执行的版本
@@ -339,14 +516,17 @@ func (s *Scheduler) resolveVersion(fn *domain.Function, req *domain.InvokeReques
 		return req.Version, "", versionData, nil
 	}
 
-	// 使用别名解析版本
+	// 使用别名解析版本，未指定别名时使用蓝绿部署的线上槽位
 	aliasName := req.Alias
+	if aliasName == "" {
+		aliasName = fn.LiveSlot
+	}
 	if aliasName == "" {
 		aliasName = "latest" // 默认使用 latest 别名
 	}
 
 	// 尝试通过路由器选择版本
-	version, err = s.router.SelectVersion(ctx, fn.ID, aliasName)
+	version, err = s.router.SelectVersion(ctx, fn.ID, aliasName, req.RoutingKey)
 	if err != nil {
 		// 如果别名不存在，回退到函数当前版本
 		s.logger.WithFields(logrus.Fields{
@@ -373,6 +553,29 @@ func (s *Scheduler) Router() *TrafficRouter {
 	return s.router
 }
 
+// RunCircuitRecovery 周期性恢复冷却结束的熔断函数并开始试探，阻塞直到 ctx 取消。
+// 熔断状态由所有实例共享，多副本部署时由调用方通过领导者选举保证只在一个实例上运行。
+func (s *Scheduler) RunCircuitRecovery(ctx context.Context) {
+	s.breakers.run(ctx)
+}
+
+// CheckInvoke 检查函数当前是否允许调用（紧急停止开关、维护窗口、最大并发数），不执行函数。
+// 用于命中响应缓存等不经过调度器执行就返回结果的调用路径。
+func (s *Scheduler) CheckInvoke(fn *domain.Function) error {
+	return checkInvokeGates(s.redis, s.metrics, s.logger, s.concurrency, fn)
+}
+
+// InvalidateShadowConfig 使函数的影子流量配置缓存失效，用于影子配置修改或删除后立即生效。
+func (s *Scheduler) InvalidateShadowConfig(functionID string) {
+	s.shadow.invalidate(functionID)
+}
+
+// InvalidateAliases 使函数所有别名的路由缓存失效，
+// 用于别名修改或蓝绿切换后立即生效。
+func (s *Scheduler) InvalidateAliases(functionID string) {
+	s.router.InvalidateFunctionCache(functionID)
+}
+
 // SetSnapshotManager 设置快照管理器
 func (s *Scheduler) SetSnapshotManager(mgr *snapshot.Manager) {
 	s.snapshotMgr = mgr
@@ -410,24 +613,120 @@ func (s *Scheduler) OnFunctionUpdated(ctx context.Context, fn *domain.Function)
 }
 
 // run 是工作协程的主循环。
-// 它持续从工作队列获取任务并处理，直到调度器停止或队列关闭。
-func (w *worker) run() {
-	defer w.scheduler.wg.Done() // 协程退出时通知等待组
+// 它持续从工作队列获取任务并处理，直到调度器停止、队列关闭或协程被缩容。
+//
+// 参数:
+//   - stop: 缩容时关闭的停止通道
+func (w *worker) run(stop <-chan struct{}) {
+	for {
+		// 按优先级取出工作项；收到停止信号、协程被缩容或工作队列已关闭时退出循环
+		// 取出的工作项已占用并发槽位（预留容量优先）
+		item, release, ok := w.scheduler.nextItem(stop)
+		if !ok {
+			return
+		}
+		runtime := string(item.function.Runtime)
+		// 占用函数自身的并发槽位，超出最大并发数时限流
+		releaseConcurrency, ok := w.scheduler.concurrency.acquire(item.function, item.invocation.ID)
+		if !ok {
+			release()
+			w.scheduler.throttleConcurrency(item)
+			continue
+		}
+		// 处理工作项
+		w.scheduler.workers.markBusy()
+		w.scheduler.load.started(runtime)
+		w.process(item)
+		w.scheduler.load.finished(runtime)
+		w.scheduler.workers.markIdle()
+		releaseConcurrency()
+		release()
+	}
+}
 
+// nextItem 取出下一个可以执行的工作项，并为其占用一个并发槽位。
+// 优先执行等待列表中已能获得槽位的工作项；从工作队列取出的工作项没有可用槽位时放入等待列表，
+// 继续取下一个工作项，使共享槽位占满时预留了并发的函数仍能被调度。
+// 收到停止信号、协程被缩容或工作队列已关闭时返回 false。
+func (s *Scheduler) nextItem(stop <-chan struct{}) (*workItem, func(), bool) {
 	for {
-		select {
-		case <-w.scheduler.ctx.Done():
-			// 收到停止信号，退出循环
+		if item, release, ok := s.slotWaiters.take(s.acquireSlot); ok {
+			s.load.dequeued(string(item.function.Runtime))
+			return item, release, true
+		}
+		item, ok := s.workQueue.pop(s.ctx.Done(), stop)
+		if !ok {
+			return nil, nil, false
+		}
+		if release, ok := s.acquireSlot(item); ok {
+			s.load.dequeued(string(item.function.Runtime))
+			return item, release, true
+		}
+		if !s.slotWaiters.park(item) {
+			s.load.dequeued(string(item.function.Runtime))
+			s.rejectNoSlot(item)
+		}
+	}
+}
+
+// acquireSlot 为工作项的函数占用一个并发槽位，预留容量优先。
+func (s *Scheduler) acquireSlot(item *workItem) (func(), bool) {
+	return s.reservations.acquire(item.function.ID, item.function.ReservedConcurrency)
+}
+
+// rejectNoSlot 处理等待列表已满、无法继续等待并发槽位的工作项。
+// 同步调用返回 429；异步调用转入 Redis 溢出队列，Redis 不可用时标记为失败。
+func (s *Scheduler) rejectNoSlot(item *workItem) {
+	if item.resultCh != nil {
+		s.throttle(item)
+		return
+	}
+	if err := pushOverflowInvocation(s.redis, item.invocation.ID); err != nil {
+		rejectAsyncInvocation(s.store, s.logger, item.invocation, "async queue", err)
+	}
+}
+
+// throttle 处理因达到并发上限而无法执行的工作项。
+// 同步调用立即返回 429；异步调用在短暂延迟后重新入队。
+func (s *Scheduler) throttle(item *workItem) {
+	inv := item.invocation
+	if item.resultCh != nil {
+		inv.Fail(domain.ErrConcurrencyLimitExceeded.Error())
+		s.store.UpdateInvocation(inv)
+		item.resultCh <- &domain.InvokeResponse{
+			RequestID:  inv.ID,
+			StatusCode: 429, // Too Many Requests
+			Error:      domain.ErrConcurrencyLimitExceeded.Error(),
+		}
+		return
+	}
+
+	time.AfterFunc(throttledRetryDelay, func() {
+		if s.ctx.Err() != nil || s.enqueue(item) {
 			return
-		case item, ok := <-w.scheduler.workQueue:
-			if !ok {
-				// 工作队列已关闭，退出循环
-				return
-			}
-			// 处理工作项
-			w.process(item)
 		}
+		if err := pushOverflowInvocation(s.redis, inv.ID); err != nil {
+			rejectAsyncInvocation(s.store, s.logger, inv, "async queue", err)
+		}
+	})
+}
+
+// throttleConcurrency 处理因函数达到最大并发数而无法执行的工作项。
+// 异步调用和仍在排队超时内的同步调用在短暂延迟后重新入队；其他同步调用返回 429。
+func (s *Scheduler) throttleConcurrency(item *workItem) {
+	delay := s.concurrency.shouldDelay(item.invocation, item.function, item.resultCh != nil)
+	if !delay || item.resultCh == nil {
+		// 同步调用返回 429，异步调用由 throttle 延迟重新入队
+		s.throttle(item)
+		return
 	}
+	// 排队中的同步调用重新入队失败（队列已满）时返回 429
+	time.AfterFunc(throttledRetryDelay, func() {
+		if s.ctx.Err() != nil || s.enqueue(item) {
+			return
+		}
+		s.throttle(item)
+	})
 }
 
 // process 处理单个工作项，执行函数调用的完整流程。
@@ -478,7 +777,15 @@ func (w *worker) process(item *workItem) {
 
 	// 从虚拟机池获取可用虚拟机
 	// coldStart 表示是否是冷启动（新创建的虚拟机）
-	pvm, coldStart, err := w.scheduler.pool.AcquireVM(acquireCtx, string(fn.Runtime))
+	// 虚拟机启动失败等瞬时平台故障透明重试
+	var pvm *vmpool.PooledVM
+	var coldStart bool
+	retries, err := w.scheduler.retrier.do(acquireCtx, string(fn.Runtime), logger, nil, func() error {
+		var err error
+		pvm, coldStart, err = w.scheduler.pool.AcquireVM(acquireCtx, string(fn.Runtime))
+		return err
+	})
+	inv.RetryCount = retries
 	if err != nil {
 		// 获取虚拟机失败，记录错误并返回失败响应
 		span.RecordError(err)
@@ -533,6 +840,10 @@ func (w *worker) process(item *workItem) {
 		}).Debug("Layer content loaded")
 	}
 
+	// 注入调用截止时间：执行超时从初始化完成后开始计时，此处的截止时间略早于实际终止时间
+	timeout := time.Duration(fn.TimeoutSec) * time.Second
+	envVars := domain.InvocationEnv(fn.EnvVars, time.Now().Add(timeout), timeout)
+
 	// 构建函数初始化负载
 	// 如果指定了版本，使用版本数据；否则使用函数当前代码
 	var initPayload *fc.InitPayload
@@ -543,7 +854,7 @@ func (w *worker) process(item *workItem) {
 			Handler:       item.version.Handler,
 			Code:          item.version.Code,
 			Runtime:       string(fn.Runtime),
-			EnvVars:       fn.EnvVars, // 环境变量使用函数级别的
+			EnvVars:       envVars, // 环境变量使用函数级别的
 			MemoryLimitMB: fn.MemoryMB,
 			TimeoutSec:    fn.TimeoutSec,
 			Layers:        layerInfos,
@@ -556,7 +867,7 @@ func (w *worker) process(item *workItem) {
 			Handler:       fn.Handler,
 			Code:          fn.Code,
 			Runtime:       string(fn.Runtime),
-			EnvVars:       fn.EnvVars,
+			EnvVars:       envVars,
 			MemoryLimitMB: fn.MemoryMB,
 			TimeoutSec:    fn.TimeoutSec,
 			Layers:        layerInfos,
@@ -570,7 +881,9 @@ func (w *worker) process(item *workItem) {
 		span.SetStatus(codes.Error, "failed to initialize function")
 		logger.WithError(err).Error("Failed to initialize function")
 		w.scheduler.pool.ReleaseVM(string(fn.Runtime), pvm.VM.ID)
-		w.fail(item, fmt.Sprintf("failed to initialize function: %v", err), 500, "init_failed")
+		errMsg := fmt.Sprintf("failed to initialize function: %v", err)
+		w.scheduler.initFailures.recordFailure(fn, errMsg)
+		w.fail(item, errMsg, 500, domain.InvokeErrorTypeInit)
 		return
 	}
 	span.AddEvent("function.init.complete")
@@ -616,17 +929,26 @@ func (w *worker) process(item *workItem) {
 	}
 
 	// ========== 阶段5：更新调用记录 ==========
+	// 拆分函数返回的响应信封：result 作为响应体，meta 只记录在调用记录上
+	output := resp.Output
+	if resp.Success {
+		output, inv.Meta = domain.ExtractInvocationMeta(output)
+	}
+	// 读取并移除函数下发的 X-Nimbus-* 平台指令，避免透传给调用方
+	output, directives := domain.ExtractResponseDirectives(output)
 	if resp.Success {
 		// 函数执行成功
-		inv.Complete(resp.Output, resp.MemoryUsedMB)
+		inv.Complete(output, resp.MemoryUsedMB)
+		w.scheduler.initFailures.recordSuccess(fn)
 	} else {
 		// 函数执行返回错误
 		inv.Fail(resp.Error)
 	}
+	w.scheduler.breakers.record(fn, !resp.Success)
 	w.scheduler.store.UpdateInvocation(inv)
 
-	// 记录调用指标
-	if w.scheduler.metrics != nil {
+	// 记录调用指标，影子流量回放只记录对比结果，不计入调用指标
+	if w.scheduler.metrics != nil && inv.TriggerType != domain.TriggerShadow {
 		statusCode := 200
 		if !resp.Success {
 			statusCode = 500
@@ -650,24 +972,31 @@ func (w *worker) process(item *workItem) {
 		}
 
 		item.resultCh <- &domain.InvokeResponse{
-			RequestID:    inv.ID,
-			StatusCode:   statusCode,
-			Body:         resp.Output,
-			Error:        resp.Error,
-			DurationMs:   inv.DurationMs,
-			ColdStart:    coldStart,
-			BilledTimeMs: inv.BilledTimeMs,
-			Version:      inv.Version,
-			AliasUsed:    inv.AliasUsed,
-			SessionKey:   inv.SessionKey,
+			RequestID:     inv.ID,
+			StatusCode:    statusCode,
+			Body:          output,
+			Error:         resp.Error,
+			DurationMs:    inv.DurationMs,
+			ColdStart:     coldStart,
+			BilledTimeMs:  inv.BilledTimeMs,
+			Version:       inv.Version,
+			AliasUsed:     inv.AliasUsed,
+			RoutedVersion: routedVersion(inv),
+			SessionKey:    inv.SessionKey,
+			Directives:    directives,
+			Meta:          inv.Meta,
 		}
 	}
 
-	logger.WithFields(logrus.Fields{
+	completed := logger.WithFields(logrus.Fields{
 		"duration_ms": inv.DurationMs,
 		"cold_start":  coldStart,
 		"success":     resp.Success,
-	}).Info("Invocation completed")
+	})
+	if len(inv.Meta) > 0 {
+		completed = completed.WithField("meta", string(inv.Meta))
+	}
+	completed.Info("Invocation completed")
 }
 
 // fail 处理工作项执行失败的情况。
@@ -688,7 +1017,7 @@ func (w *worker) fail(item *workItem, errMsg string, statusCode int, errorType s
 	w.scheduler.store.UpdateInvocation(item.invocation)
 
 	// 记录错误指标
-	if w.scheduler.metrics != nil {
+	if w.scheduler.metrics != nil && item.invocation.TriggerType != domain.TriggerShadow {
 		w.scheduler.metrics.RecordInvocation(
 			item.function.ID,
 			item.function.Name,
@@ -702,16 +1031,23 @@ func (w *worker) fail(item *workItem, errMsg string, statusCode int, errorType s
 
 	// 如果是同步调用，通过结果通道返回错误响应
 	if item.resultCh != nil {
+		// 仅对外暴露初始化失败的错误类型，其余类型仅用于指标分类
+		respErrorType := ""
+		if errorType == domain.InvokeErrorTypeInit {
+			respErrorType = errorType
+		}
 		item.resultCh <- &domain.InvokeResponse{
-			RequestID:    item.invocation.ID,
-			StatusCode:   statusCode,
-			Error:        errMsg,
-			DurationMs:   item.invocation.DurationMs,
-			ColdStart:    item.invocation.ColdStart,
-			BilledTimeMs: item.invocation.BilledTimeMs,
-			Version:      item.invocation.Version,
-			AliasUsed:    item.invocation.AliasUsed,
-			SessionKey:   item.invocation.SessionKey,
+			RequestID:     item.invocation.ID,
+			StatusCode:    statusCode,
+			Error:         errMsg,
+			ErrorType:     respErrorType,
+			DurationMs:    item.invocation.DurationMs,
+			ColdStart:     item.invocation.ColdStart,
+			BilledTimeMs:  item.invocation.BilledTimeMs,
+			Version:       item.invocation.Version,
+			AliasUsed:     item.invocation.AliasUsed,
+			RoutedVersion: routedVersion(item.invocation),
+			SessionKey:    item.invocation.SessionKey,
 		}
 	}
 }
@@ -723,9 +1059,9 @@ func (w *worker) fail(item *workItem, errMsg string, statusCode int, errorType s
 //   - SchedulerStats: 包含队列长度、队列容量和工作协程数量的统计信息
 func (s *Scheduler) Stats() SchedulerStats {
 	return SchedulerStats{
-		QueueLength: len(s.workQueue), // 当前队列中的任务数
-		QueueCap:    cap(s.workQueue), // 队列最大容量
-		Workers:     len(s.workers),   // 工作协程数量
+		QueueLength: s.workQueue.len(), // 当前队列中的任务数
+		QueueCap:    s.workQueue.cap(), // 队列最大容量
+		Workers:     s.workers.size(), // 工作协程数量
 	}
 }
 
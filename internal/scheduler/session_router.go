@@ -97,6 +97,9 @@ func (r *SessionRouter) RouteSession(ctx context.Context, fn *domain.Function, s
 		}
 		// VM 已不存活，清理旧绑定
 		r.redis.Del(ctx, redisKey)
+	} else if err != nil && err != redis.Nil {
+		// Redis 不可用时降级为仅使用一致性哈希路由
+		r.logger.WithError(err).WithField("function_id", fn.ID).Warn("Redis unavailable, falling back to hash-ring session routing")
 	}
 
 	// 使用一致性哈希选择 VM
@@ -105,8 +108,10 @@ func (r *SessionRouter) RouteSession(ctx context.Context, fn *domain.Function, s
 	r.hashRingMu.RUnlock()
 
 	if vmID != "" && r.pool.IsVMAlive(vmID) {
-		// 绑定会话到 VM
-		r.bindSession(ctx, fn.ID, sessionKey, vmID, fn.StateConfig.SessionTimeout)
+		// 绑定会话到 VM，Redis 不可用时仅保留本地缓存绑定
+		if err := r.bindSession(ctx, fn.ID, sessionKey, vmID, fn.StateConfig.SessionTimeout); err != nil {
+			r.logger.WithError(err).WithField("function_id", fn.ID).Warn("Failed to persist session binding to redis")
+		}
 		r.updateCache(cacheKey, vmID)
 		return vmID, nil
 	}
@@ -0,0 +1,258 @@
+// Package scheduler 提供函数调度器的实现。
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/config"
+	"github.com/oriys/nimbus/internal/domain"
+	"github.com/oriys/nimbus/internal/metrics"
+	"github.com/oriys/nimbus/internal/storage"
+)
+
+// shadowJob 是一次待回放的影子调用，由主调用路径提交、影子工作协程执行。
+type shadowJob struct {
+	fn      *domain.Function
+	cfg     *domain.ShadowConfig
+	req     *domain.InvokeRequest
+	primary *domain.InvokeResponse
+	invoke  func(*domain.InvokeRequest) (*domain.InvokeResponse, error)
+}
+
+// shadowMirror 负责将同步调用按比例回放到影子目标，并记录对比结果。
+// 主调用路径按缓存的影子配置采样，只把命中采样的任务非阻塞地放入有界队列，
+// 执行影子调用和记录结果都由固定数量的后台工作协程完成，影子目标变慢或不可用不会拖慢真实调用。
+type shadowMirror struct {
+	loadConfig     func(functionID string) (*domain.ShadowConfig, error) // 读取影子流量配置
+	record         func(*domain.ShadowResult) error                      // 保存对比结果
+	metrics        *metrics.Metrics
+	logger         *logrus.Logger
+	defaultTimeout time.Duration // 影子配置未指定超时时使用的超时
+	jobs           chan shadowJob
+
+	configs   map[string]*cachedShadowConfig // functionID -> 影子配置缓存
+	configMu  sync.RWMutex
+	configTTL time.Duration
+}
+
+// cachedShadowConfig 缓存的影子流量配置，config 为 nil 表示函数未配置影子流量
+type cachedShadowConfig struct {
+	config    *domain.ShadowConfig
+	expiresAt time.Time
+}
+
+// shadowConfigTTL 影子流量配置的缓存时间。配置修改后本实例立即失效，
+// 其他副本最多在一个 TTL 后生效。
+const shadowConfigTTL = 30 * time.Second
+
+// newShadowMirror 创建影子流量回放器并启动后台工作协程。
+//
+// 参数:
+//   - cfg: 调度器配置，提供影子工作协程数、队列容量和默认超时
+//   - store: PostgreSQL 存储，用于读取影子配置和保存对比结果
+//   - m: 指标收集器，可为 nil
+//   - logger: 日志记录器
+func newShadowMirror(cfg config.SchedulerConfig, store *storage.PostgresStore, m *metrics.Metrics, logger *logrus.Logger) *shadowMirror {
+	mirror := &shadowMirror{
+		loadConfig:     store.GetShadowConfig,
+		record:         store.CreateShadowResult,
+		metrics:        m,
+		logger:         logger,
+		defaultTimeout: cfg.ShadowTimeout,
+		jobs:           make(chan shadowJob, cfg.ShadowQueueSize),
+		configs:        make(map[string]*cachedShadowConfig),
+		configTTL:      shadowConfigTTL,
+	}
+	for i := 0; i < cfg.ShadowWorkers; i++ {
+		go mirror.run()
+	}
+	return mirror
+}
+
+// mirror 按函数的影子流量配置对本次调用采样，命中时提交到影子回放队列。
+// 配置读取走缓存，未配置影子流量的函数不访问数据库也不入队；队列已满时直接丢弃并计入指标，从不阻塞调用方。
+//
+// 参数:
+//   - fn: 主函数
+//   - req: 主调用请求
+//   - primary: 主调用响应
+//   - invoke: 执行影子调用的同步调用函数
+func (m *shadowMirror) mirror(fn *domain.Function, req *domain.InvokeRequest, primary *domain.InvokeResponse, invoke func(*domain.InvokeRequest) (*domain.InvokeResponse, error)) {
+	// 影子调用本身不再触发影子流量，避免级联放大
+	if req.Shadow || primary == nil {
+		return
+	}
+	cfg := m.config(fn.ID)
+	if cfg == nil || !cfg.Enabled || cfg.Percentage <= 0 {
+		return
+	}
+	if rand.Intn(100) >= cfg.Percentage {
+		return
+	}
+
+	select {
+	case m.jobs <- shadowJob{fn: fn, cfg: cfg, req: req, primary: primary, invoke: invoke}:
+	default:
+		m.logger.WithField("function_id", fn.ID).Debug("Shadow invocation dropped: queue full")
+		m.recordMetric(fn, shadowResultDropped, 0)
+	}
+}
+
+// config 返回函数的影子流量配置（带缓存），未配置或读取失败时返回 nil。
+// 读取失败同样缓存一个 TTL，避免数据库异常时每次调用都重试查询。
+func (m *shadowMirror) config(functionID string) *domain.ShadowConfig {
+	m.configMu.RLock()
+	if cached, ok := m.configs[functionID]; ok && time.Now().Before(cached.expiresAt) {
+		m.configMu.RUnlock()
+		return cached.config
+	}
+	m.configMu.RUnlock()
+
+	cfg, err := m.loadConfig(functionID)
+	if err != nil {
+		if !errors.Is(err, domain.ErrShadowConfigNotFound) {
+			m.logger.WithError(err).WithField("function_id", functionID).Warn("Failed to load shadow config")
+		}
+		cfg = nil
+	}
+
+	m.configMu.Lock()
+	m.configs[functionID] = &cachedShadowConfig{config: cfg, expiresAt: time.Now().Add(m.configTTL)}
+	m.configMu.Unlock()
+	return cfg
+}
+
+// invalidate 使函数的影子流量配置缓存失效，配置修改或删除后调用。
+func (m *shadowMirror) invalidate(functionID string) {
+	m.configMu.Lock()
+	delete(m.configs, functionID)
+	m.configMu.Unlock()
+}
+
+// close 关闭影子回放队列，工作协程处理完已提交的任务后退出。
+func (m *shadowMirror) close() {
+	close(m.jobs)
+}
+
+// run 是影子工作协程的主循环。
+func (m *shadowMirror) run() {
+	for job := range m.jobs {
+		m.process(job)
+	}
+}
+
+// 影子回放结果常量，用于指标标签
+const (
+	shadowResultSuccess = "success"
+	shadowResultFailure = "failure"
+	shadowResultTimeout = "timeout"
+	shadowResultDropped = "dropped"
+)
+
+// process 按任务携带的影子流量配置执行一次影子调用并记录对比结果。
+// 影子调用超过配置的超时后不再等待，工作协程立即处理下一个任务；
+// 超时的调用仍受目标函数自身超时约束，结束后其结果被丢弃。
+func (m *shadowMirror) process(job shadowJob) {
+	fn := job.fn
+	cfg := job.cfg
+
+	targetID := cfg.TargetFunctionID
+	if targetID == "" {
+		targetID = fn.ID
+	}
+	shadowReq := &domain.InvokeRequest{
+		FunctionID:     targetID,
+		Payload:        job.req.Payload,
+		Version:        cfg.TargetVersion,
+		Shadow:         true,
+		PathParameters: job.req.PathParameters,
+	}
+
+	result := &domain.ShadowResult{
+		FunctionID:          fn.ID,
+		PrimaryInvocationID: job.primary.RequestID,
+		TargetFunctionID:    targetID,
+		TargetVersion:       cfg.TargetVersion,
+		PrimaryStatusCode:   job.primary.StatusCode,
+		PrimaryDurationMs:   job.primary.DurationMs,
+		PrimaryOutput:       job.primary.Body,
+	}
+
+	timeout := m.defaultTimeout
+	if cfg.TimeoutMs > 0 {
+		timeout = time.Duration(cfg.TimeoutMs) * time.Millisecond
+	}
+
+	type outcome struct {
+		resp *domain.InvokeResponse
+		err  error
+	}
+	done := make(chan outcome, 1)
+	start := time.Now()
+	go func() {
+		resp, err := job.invoke(shadowReq)
+		done <- outcome{resp: resp, err: err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	status := shadowResultSuccess
+	select {
+	case out := <-done:
+		if out.err != nil {
+			status = shadowResultFailure
+			result.ShadowError = out.err.Error()
+		} else {
+			result.ShadowInvocationID = out.resp.RequestID
+			result.ShadowStatusCode = out.resp.StatusCode
+			result.ShadowDurationMs = out.resp.DurationMs
+			result.ShadowOutput = out.resp.Body
+			result.ShadowError = out.resp.Error
+			result.Match = out.resp.StatusCode == job.primary.StatusCode && jsonEqual(out.resp.Body, job.primary.Body)
+			if out.resp.Error != "" || out.resp.StatusCode >= 500 {
+				status = shadowResultFailure
+			}
+		}
+	case <-timer.C:
+		status = shadowResultTimeout
+		result.ShadowError = fmt.Sprintf("shadow invocation timed out after %s", timeout)
+		result.ShadowDurationMs = timeout.Milliseconds()
+	}
+	m.recordMetric(fn, status, time.Since(start))
+
+	if err := m.record(result); err != nil {
+		m.logger.WithError(err).WithField("function_id", fn.ID).Warn("Failed to record shadow result")
+	}
+}
+
+// recordMetric 记录一次影子回放的结果和耗时。
+func (m *shadowMirror) recordMetric(fn *domain.Function, result string, duration time.Duration) {
+	if m.metrics != nil {
+		m.metrics.RecordShadowRelay(fn.Name, result, float64(duration.Milliseconds()))
+	}
+}
+
+// jsonEqual 按语义比较两段 JSON（忽略键顺序和空白差异）。
+// 任一方不是合法 JSON 时退化为字节比较。
+func jsonEqual(a, b json.RawMessage) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	var va, vb interface{}
+	if err := json.Unmarshal(a, &va); err != nil {
+		return string(a) == string(b)
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		return string(a) == string(b)
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,138 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+func TestJSONEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{name: "key order ignored", a: `{"a":1,"b":2}`, b: `{"b":2, "a":1}`, want: true},
+		{name: "different values", a: `{"a":1}`, b: `{"a":2}`, want: false},
+		{name: "both empty", a: ``, b: ``, want: true},
+		{name: "one empty", a: `{}`, b: ``, want: false},
+		{name: "invalid json falls back to bytes", a: `not json`, b: `not json`, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jsonEqual(json.RawMessage(tt.a), json.RawMessage(tt.b)); got != tt.want {
+				t.Errorf("jsonEqual(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShadowMirrorTimeoutAndDrop(t *testing.T) {
+	results := make(chan *domain.ShadowResult, 4)
+	m := &shadowMirror{
+		loadConfig: func(functionID string) (*domain.ShadowConfig, error) {
+			return &domain.ShadowConfig{FunctionID: functionID, Percentage: 100, Enabled: true, TimeoutMs: 20}, nil
+		},
+		record: func(res *domain.ShadowResult) error {
+			results <- res
+			return nil
+		},
+		logger:         logrus.New(),
+		defaultTimeout: time.Second,
+		jobs:           make(chan shadowJob, 1),
+		configs:        make(map[string]*cachedShadowConfig),
+		configTTL:      time.Minute,
+	}
+
+	fn := &domain.Function{ID: "fn-1", Name: "demo"}
+	req := &domain.InvokeRequest{FunctionID: fn.ID}
+	primary := &domain.InvokeResponse{RequestID: "inv-1", StatusCode: 200}
+	release := make(chan struct{})
+	slow := func(*domain.InvokeRequest) (*domain.InvokeResponse, error) {
+		<-release
+		return &domain.InvokeResponse{StatusCode: 200}, nil
+	}
+	defer close(release)
+
+	// 没有工作协程消费时，第二个任务因队列已满被丢弃，调用方不阻塞
+	m.mirror(fn, req, primary, slow)
+	m.mirror(fn, req, primary, slow)
+	if got := len(m.jobs); got != 1 {
+		t.Fatalf("queued jobs = %d, want 1", got)
+	}
+
+	// 影子调用超过配置的超时后记录超时结果，工作协程不再等待
+	start := time.Now()
+	m.process(<-m.jobs)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("process took %v, want it to give up after the shadow timeout", elapsed)
+	}
+	res := <-results
+	if res.ShadowError == "" || res.Match {
+		t.Errorf("result = %+v, want timeout error and no match", res)
+	}
+
+	// 影子调用本身不再回放
+	m.mirror(fn, &domain.InvokeRequest{FunctionID: fn.ID, Shadow: true}, primary, slow)
+	if got := len(m.jobs); got != 0 {
+		t.Errorf("queued jobs for shadow request = %d, want 0", got)
+	}
+}
+
+func TestShadowMirrorConfigCache(t *testing.T) {
+	loads := 0
+	cfg := &domain.ShadowConfig{FunctionID: "fn-1", Percentage: 100, Enabled: true}
+	m := &shadowMirror{
+		loadConfig: func(functionID string) (*domain.ShadowConfig, error) {
+			loads++
+			if functionID != cfg.FunctionID {
+				return nil, domain.ErrShadowConfigNotFound
+			}
+			return cfg, nil
+		},
+		logger:    logrus.New(),
+		jobs:      make(chan shadowJob, 8),
+		configs:   make(map[string]*cachedShadowConfig),
+		configTTL: time.Minute,
+	}
+	primary := &domain.InvokeResponse{RequestID: "inv-1", StatusCode: 200}
+	invoke := func(*domain.InvokeRequest) (*domain.InvokeResponse, error) { return primary, nil }
+
+	// 未配置影子流量的函数不入队，未配置的结果同样被缓存
+	other := &domain.Function{ID: "fn-2"}
+	m.mirror(other, &domain.InvokeRequest{FunctionID: other.ID}, primary, invoke)
+	m.mirror(other, &domain.InvokeRequest{FunctionID: other.ID}, primary, invoke)
+	if got := len(m.jobs); got != 0 {
+		t.Fatalf("queued jobs for function without shadow config = %d, want 0", got)
+	}
+	if loads != 1 {
+		t.Fatalf("config loads = %d, want 1 (negative result cached)", loads)
+	}
+
+	// 已配置的函数在 TTL 内只读取一次配置
+	fn := &domain.Function{ID: "fn-1"}
+	m.mirror(fn, &domain.InvokeRequest{FunctionID: fn.ID}, primary, invoke)
+	m.mirror(fn, &domain.InvokeRequest{FunctionID: fn.ID}, primary, invoke)
+	if got := len(m.jobs); got != 2 {
+		t.Fatalf("queued jobs = %d, want 2", got)
+	}
+	if loads != 2 {
+		t.Fatalf("config loads = %d, want 2", loads)
+	}
+
+	// 配置被禁用并使缓存失效后不再入队
+	cfg = &domain.ShadowConfig{FunctionID: "fn-1", Percentage: 100, Enabled: false}
+	m.invalidate(fn.ID)
+	m.mirror(fn, &domain.InvokeRequest{FunctionID: fn.ID}, primary, invoke)
+	if got := len(m.jobs); got != 2 {
+		t.Errorf("queued jobs after disabling = %d, want 2", got)
+	}
+	if loads != 3 {
+		t.Errorf("config loads after invalidate = %d, want 3", loads)
+	}
+}
@@ -0,0 +1,94 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// applyAliasRouting 按别名选择版本，并将该版本的代码应用到函数定义上。
+// 显式指定的别名（req.Alias）优先，其次使用函数的线上蓝绿槽位。别名通过共享的 TrafficRouter 解析：
+// 别名配置带缓存，修改后由 InvalidateAliases 失效；请求携带路由键时按哈希一致地选择版本，否则加权随机选择。
+// 与 Firecracker 调度器的 resolveVersion 一致，别名不存在时回退到函数当前代码。
+//
+// 参数:
+//   - router: 流量路由器
+//   - loadVersion: 加载版本快照的函数
+//   - logger: 日志记录器
+//   - fn: 函数定义，命中别名时其代码字段被替换为版本快照
+//   - req: 调用请求
+//
+// 返回值:
+//   - int: 命中的版本号，未命中时为 0
+//   - string: 命中的别名名称，未命中时为空
+//   - error: 别名没有可路由的版本或指向的版本不存在时返回错误
+func applyAliasRouting(router *TrafficRouter, loadVersion func(functionID string, version int) (*domain.FunctionVersion, error), logger *logrus.Logger, fn *domain.Function, req *domain.InvokeRequest) (int, string, error) {
+	name := req.Alias
+	if name == "" {
+		name = fn.LiveSlot
+	}
+	if name == "" {
+		return 0, "", nil
+	}
+
+	version, err := router.SelectVersion(context.Background(), fn.ID, name, req.RoutingKey)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"function_id": fn.ID,
+			"alias":       name,
+			"error":       err.Error(),
+		}).Debug("Alias not found, falling back to current function version")
+		return 0, "", nil
+	}
+	if version <= 0 {
+		return 0, "", fmt.Errorf("alias %s has no routable version", name)
+	}
+
+	versionData, err := loadVersion(fn.ID, version)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to load version %d for alias %s: %w", version, name, err)
+	}
+	applyVersionSnapshot(fn, versionData)
+	return version, name, nil
+}
+
+// applyRequestedVersion 将调用显式指定的版本（如影子流量回放到指定版本）的代码应用到函数定义上。
+//
+// 参数:
+//   - loadVersion: 加载版本快照的函数
+//   - fn: 函数定义，指定了版本时其代码字段被替换为版本快照
+//   - req: 调用请求
+//
+// 返回值:
+//   - int: 指定的版本号，未指定时为 0
+//   - error: 指定的版本不存在时返回错误
+func applyRequestedVersion(loadVersion func(functionID string, version int) (*domain.FunctionVersion, error), fn *domain.Function, req *domain.InvokeRequest) (int, error) {
+	if req.Version <= 0 {
+		return 0, nil
+	}
+	versionData, err := loadVersion(fn.ID, req.Version)
+	if err != nil {
+		return 0, fmt.Errorf("version %d not found: %w", req.Version, err)
+	}
+	applyVersionSnapshot(fn, versionData)
+	return req.Version, nil
+}
+
+// applyVersionSnapshot 用版本快照中的代码替换函数定义的代码字段。
+func applyVersionSnapshot(fn *domain.Function, versionData *domain.FunctionVersion) {
+	fn.Handler = versionData.Handler
+	fn.Code = versionData.Code
+	fn.Binary = versionData.Binary
+	fn.CodeHash = versionData.CodeHash
+}
+
+// routedVersion 返回调用按别名路由选中的版本号，未使用别名时返回 0。
+func routedVersion(inv *domain.Invocation) int {
+	if inv.AliasUsed == "" {
+		return 0
+	}
+	return inv.Version
+}
@@ -0,0 +1,142 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+func TestApplyRequestedVersion(t *testing.T) {
+	snapshot := &domain.FunctionVersion{Version: 3, Handler: "v3.handler", Code: "v3 code", CodeHash: "hash-v3"}
+	var loaded []int
+	loadVersion := func(functionID string, version int) (*domain.FunctionVersion, error) {
+		loaded = append(loaded, version)
+		if version != snapshot.Version {
+			return nil, errors.New("not found")
+		}
+		return snapshot, nil
+	}
+	newFn := func() *domain.Function {
+		return &domain.Function{ID: "fn-1", Handler: "live.handler", Code: "live code", CodeHash: "hash-live", Version: 5}
+	}
+
+	// 未指定版本时不加载快照，保持函数当前代码
+	fn := newFn()
+	version, err := applyRequestedVersion(loadVersion, fn, &domain.InvokeRequest{FunctionID: fn.ID})
+	if err != nil || version != 0 || len(loaded) != 0 || fn.Code != "live code" {
+		t.Errorf("no version: got %d, %v, loaded %v, code %q", version, err, loaded, fn.Code)
+	}
+
+	// 指定版本时执行版本快照中的代码
+	fn = newFn()
+	version, err = applyRequestedVersion(loadVersion, fn, &domain.InvokeRequest{FunctionID: fn.ID, Version: 3, Shadow: true})
+	if err != nil || version != 3 {
+		t.Fatalf("version 3: got %d, %v", version, err)
+	}
+	if fn.Handler != "v3.handler" || fn.Code != "v3 code" || fn.CodeHash != "hash-v3" {
+		t.Errorf("function code not replaced by snapshot: %+v", fn)
+	}
+
+	// 指定的版本不存在时返回错误，不退回执行当前代码
+	fn = newFn()
+	if _, err := applyRequestedVersion(loadVersion, fn, &domain.InvokeRequest{FunctionID: fn.ID, Version: 9}); err == nil {
+		t.Error("missing version: expected error")
+	}
+	if fn.Code != "live code" {
+		t.Errorf("missing version changed function code to %q", fn.Code)
+	}
+}
+
+// fakeAliases 是内存中的别名表，并记录加载次数
+type fakeAliases struct {
+	aliases map[string]*domain.FunctionAlias
+	loads   int
+}
+
+func (s *fakeAliases) GetFunctionAlias(functionID, name string) (*domain.FunctionAlias, error) {
+	s.loads++
+	if alias, ok := s.aliases[functionID+":"+name]; ok {
+		return alias, nil
+	}
+	return nil, domain.ErrAliasNotFound
+}
+
+func newTestTrafficRouter(store aliasLoader) *TrafficRouter {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	return &TrafficRouter{
+		store:    store,
+		cache:    make(map[string]*cachedAlias),
+		cacheTTL: time.Minute,
+		rng:      rand.New(rand.NewSource(1)),
+		logger:   logger,
+	}
+}
+
+func TestApplyAliasRouting(t *testing.T) {
+	aliases := &fakeAliases{aliases: map[string]*domain.FunctionAlias{
+		"fn-1:prod": {Name: "prod", RoutingConfig: domain.RoutingConfig{Weights: []domain.VersionWeight{{Version: 3, Weight: 100}}}},
+	}}
+	router := newTestTrafficRouter(aliases)
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	loadVersion := func(functionID string, version int) (*domain.FunctionVersion, error) {
+		return &domain.FunctionVersion{Version: version, Code: fmt.Sprintf("v%d code", version)}, nil
+	}
+	newFn := func() *domain.Function {
+		return &domain.Function{ID: "fn-1", Code: "live code", Version: 5}
+	}
+
+	// 别名命中时执行别名指向版本的代码
+	fn := newFn()
+	version, alias, err := applyAliasRouting(router, loadVersion, logger, fn, &domain.InvokeRequest{Alias: "prod"})
+	if err != nil || version != 3 || alias != "prod" || fn.Code != "v3 code" {
+		t.Fatalf("prod alias: got %d, %q, %v, code %q", version, alias, err, fn.Code)
+	}
+
+	// 别名不存在时与 Firecracker 调度器一致，回退到函数当前代码
+	fn = newFn()
+	version, alias, err = applyAliasRouting(router, loadVersion, logger, fn, &domain.InvokeRequest{Alias: "missing"})
+	if err != nil || version != 0 || alias != "" || fn.Code != "live code" {
+		t.Errorf("missing alias: got %d, %q, %v, code %q", version, alias, err, fn.Code)
+	}
+
+	// 未指定别名时使用线上槽位；槽位别名不存在时同样回退
+	fn = newFn()
+	fn.LiveSlot = domain.SlotBlue
+	if version, _, err := applyAliasRouting(router, loadVersion, logger, fn, &domain.InvokeRequest{}); err != nil || version != 0 {
+		t.Errorf("missing live slot: got %d, %v, want fallback", version, err)
+	}
+
+	// 别名指向的版本不存在时返回错误
+	failing := func(string, int) (*domain.FunctionVersion, error) { return nil, errors.New("not found") }
+	if _, _, err := applyAliasRouting(router, failing, logger, newFn(), &domain.InvokeRequest{Alias: "prod"}); err == nil {
+		t.Error("missing version: expected error")
+	}
+}
+
+func TestDockerSchedulerInvalidateAliases(t *testing.T) {
+	aliases := &fakeAliases{aliases: map[string]*domain.FunctionAlias{
+		"fn-1:prod": {Name: "prod", RoutingConfig: domain.RoutingConfig{Weights: []domain.VersionWeight{{Version: 3, Weight: 100}}}},
+	}}
+	s := &DockerScheduler{router: newTestTrafficRouter(aliases)}
+	s.router.SelectVersion(context.Background(), "fn-1", "prod", "")
+	s.router.SelectVersion(context.Background(), "fn-1", "prod", "")
+	if aliases.loads != 1 {
+		t.Fatalf("alias loads = %d, want 1 (cached)", aliases.loads)
+	}
+
+	// 别名修改后立即生效，而不是等待缓存过期
+	aliases.aliases["fn-1:prod"] = &domain.FunctionAlias{Name: "prod", RoutingConfig: domain.RoutingConfig{Weights: []domain.VersionWeight{{Version: 4, Weight: 100}}}}
+	s.InvalidateAliases("fn-1")
+	if version, err := s.router.SelectVersion(context.Background(), "fn-1", "prod", ""); err != nil || version != 4 {
+		t.Errorf("after invalidation: got %d, %v, want 4", version, err)
+	}
+}
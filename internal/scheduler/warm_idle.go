@@ -0,0 +1,30 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+)
+
+// idleReapInterval 空闲预热实例回收的检查周期，实例最多比空闲超时多存活一个周期
+const idleReapInterval = 15 * time.Second
+
+// IdleReaper 定义了支持按空闲时间回收预热实例的执行环境池接口（可选实现）。
+type IdleReaper interface {
+	// ReapIdle 回收空闲超时的预热实例，返回回收数量
+	ReapIdle(ctx context.Context) int
+}
+
+// runIdleReaper 按 idleReapInterval 周期回收空闲预热实例，直到 ctx 取消。
+func runIdleReaper(ctx context.Context, reaper IdleReaper) {
+	ticker := time.NewTicker(idleReapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reaper.ReapIdle(ctx)
+		}
+	}
+}
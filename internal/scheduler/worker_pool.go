@@ -0,0 +1,131 @@
+// Package scheduler 提供函数调度器的实现。
+package scheduler
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/oriys/nimbus/internal/metrics"
+)
+
+// ErrInvalidWorkerCount 表示请求的工作协程数量超出允许范围
+var ErrInvalidWorkerCount = errors.New("invalid worker count")
+
+// WorkerStats 描述调度器工作协程池的运行状态，以及预留并发对容量的占用情况。
+type WorkerStats struct {
+	ConfiguredWorkers int `json:"configured_workers"` // 当前配置的工作协程数量
+	ActiveWorkers     int `json:"active_workers"`     // 正在处理任务的工作协程数量
+	MaxWorkers        int `json:"max_workers"`        // 允许扩容到的最大工作协程数量
+	QueueLength       int `json:"queue_length"`       // 当前队列中等待处理的任务数量
+	QueueCap          int `json:"queue_cap"`          // 队列的最大容量（各优先级通道容量之和）
+
+	QueueByPriority map[string]int `json:"queue_by_priority"` // 各优先级（high/normal/low）排队的任务数量
+	QueueBySource   map[string]int `json:"queue_by_source"`   // 各触发来源（http/webhook/cron/event）排队的任务数量
+
+	ReservationStats // 预留并发与共享容量的使用情况
+}
+
+// workerPool 管理可在运行时扩缩容的工作协程。
+// 每个工作协程持有独立的停止通道，缩容时关闭多余协程的通道，
+// 协程在完成当前任务后退出；空闲协程阻塞在队列上，不消耗 CPU。
+type workerPool struct {
+	wg      *sync.WaitGroup
+	metrics *metrics.Metrics
+	run     func(id int, stop <-chan struct{})
+
+	mu     sync.Mutex
+	stops  []chan struct{}
+	nextID int
+	closed bool
+
+	busy int64 // 正在处理任务的协程数量（原子操作）
+}
+
+// newWorkerPool 创建工作协程池。
+//
+// 参数:
+//   - wg: 调度器的等待组，用于优雅关闭时等待所有协程退出
+//   - m: 指标收集器，可为 nil
+//   - run: 工作协程主循环，stop 通道关闭时应退出
+func newWorkerPool(wg *sync.WaitGroup, m *metrics.Metrics, run func(id int, stop <-chan struct{})) *workerPool {
+	return &workerPool{
+		wg:      wg,
+		metrics: m,
+		run:     run,
+	}
+}
+
+// scale 将工作协程数量调整为 n。
+// 扩容时立即启动新协程，缩容时通知多余协程在处理完当前任务后退出。
+func (p *workerPool) scale(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return
+	}
+
+	for len(p.stops) < n {
+		stop := make(chan struct{})
+		p.stops = append(p.stops, stop)
+		p.wg.Add(1)
+		go func(id int) {
+			defer p.wg.Done()
+			p.run(id, stop)
+		}(p.nextID)
+		p.nextID++
+	}
+	for len(p.stops) > n {
+		last := len(p.stops) - 1
+		close(p.stops[last])
+		p.stops = p.stops[:last]
+	}
+
+	if p.metrics != nil {
+		p.metrics.SchedulerWorkers.Set(float64(len(p.stops)))
+	}
+}
+
+// close 标记协程池已关闭，之后的扩容请求将被忽略。
+func (p *workerPool) close() {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+}
+
+// size 返回当前配置的工作协程数量。
+func (p *workerPool) size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.stops)
+}
+
+// active 返回正在处理任务的工作协程数量。
+func (p *workerPool) active() int {
+	return int(atomic.LoadInt64(&p.busy))
+}
+
+// markBusy 标记一个工作协程开始处理任务。
+func (p *workerPool) markBusy() {
+	atomic.AddInt64(&p.busy, 1)
+	if p.metrics != nil {
+		p.metrics.SchedulerActiveWorkers.Inc()
+	}
+}
+
+// markIdle 标记一个工作协程完成任务。
+func (p *workerPool) markIdle() {
+	atomic.AddInt64(&p.busy, -1)
+	if p.metrics != nil {
+		p.metrics.SchedulerActiveWorkers.Dec()
+	}
+}
+
+// validateWorkerCount 检查工作协程数量是否在 [1, max] 范围内。
+func validateWorkerCount(n, max int) error {
+	if n < 1 || n > max {
+		return ErrInvalidWorkerCount
+	}
+	return nil
+}
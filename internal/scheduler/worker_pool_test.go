@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestWorkerPool_Scale 测试工作协程池的扩容与缩容
+func TestWorkerPool_Scale(t *testing.T) {
+	var wg sync.WaitGroup
+	var running int64
+
+	p := newWorkerPool(&wg, nil, func(id int, stop <-chan struct{}) {
+		atomic.AddInt64(&running, 1)
+		<-stop
+		atomic.AddInt64(&running, -1)
+	})
+
+	waitRunning := func(want int64) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for atomic.LoadInt64(&running) != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("running = %d, want %d", atomic.LoadInt64(&running), want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	p.scale(4)
+	if got := p.size(); got != 4 {
+		t.Fatalf("size() = %d, want 4", got)
+	}
+	waitRunning(4)
+
+	p.scale(1)
+	if got := p.size(); got != 1 {
+		t.Fatalf("size() = %d, want 1", got)
+	}
+	waitRunning(1)
+
+	p.scale(0)
+	wg.Wait()
+
+	p.close()
+	p.scale(3)
+	if got := p.size(); got != 0 {
+		t.Fatalf("size() after close = %d, want 0", got)
+	}
+}
+
+// TestValidateWorkerCount 测试工作协程数量范围校验
+func TestValidateWorkerCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       int
+		max     int
+		wantErr bool
+	}{
+		{name: "within range", n: 5, max: 10, wantErr: false},
+		{name: "equal to max", n: 10, max: 10, wantErr: false},
+		{name: "zero", n: 0, max: 10, wantErr: true},
+		{name: "above max", n: 11, max: 10, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWorkerCount(tt.n, tt.max)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateWorkerCount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
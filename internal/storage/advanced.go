@@ -99,6 +99,21 @@ func (s *PostgresStore) ListAlerts(status, functionID string) ([]*domain.Alert,
 	return result, nil
 }
 
+// CreateAlert 记录一条告警实例，未设置 ID 和触发时间时自动生成
+func (s *PostgresStore) CreateAlert(alert *domain.Alert) error {
+	alertsMu.Lock()
+	defer alertsMu.Unlock()
+
+	if alert.ID == "" {
+		alert.ID = uuid.New().String()
+	}
+	if alert.FiredAt.IsZero() {
+		alert.FiredAt = time.Now()
+	}
+	alerts[alert.ID] = alert
+	return nil
+}
+
 // ResolveAlert 解决告警
 func (s *PostgresStore) ResolveAlert(id string) error {
 	alertsMu.Lock()
@@ -128,6 +143,18 @@ func (s *PostgresStore) ListNotificationChannels() ([]*domain.NotificationChanne
 	return result, nil
 }
 
+// GetNotificationChannel 获取通知渠道
+func (s *PostgresStore) GetNotificationChannel(id string) (*domain.NotificationChannel, error) {
+	channelsMu.RLock()
+	defer channelsMu.RUnlock()
+
+	ch, ok := channels[id]
+	if !ok {
+		return nil, errors.New("notification channel not found")
+	}
+	return ch, nil
+}
+
 // CreateNotificationChannel 创建通知渠道
 func (s *PostgresStore) CreateNotificationChannel(ch *domain.NotificationChannel) error {
 	channelsMu.Lock()
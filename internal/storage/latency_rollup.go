@@ -0,0 +1,250 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ==================== 函数延迟汇总 ====================
+
+// latencyRollupBucket 是延迟汇总的时间桶大小
+const latencyRollupBucket = time.Hour
+
+// latencyRollupBackfill 是首次汇总时回溯的时长，覆盖统计接口支持的最长统计周期（7 天）
+const latencyRollupBackfill = 8 * 24 * time.Hour
+
+// latencyAggregate 是一段时间内调用统计的可合并汇总。
+// 分位数按调用数加权合并，跨多个时间桶时为近似值。
+type latencyAggregate struct {
+	total        int64
+	success      int64
+	failed       int64
+	timeout      int64
+	coldStarts   int64
+	sumDuration  float64
+	sumColdStart float64
+	min          float64
+	max          float64
+	p50          float64 // 加权分位数之和（分位数 × 调用数），合并后再除以总调用数
+	p95          float64
+	p99          float64
+}
+
+// merge 将另一段汇总合并到当前汇总。
+func (a *latencyAggregate) merge(b latencyAggregate) {
+	if b.total == 0 {
+		return
+	}
+	if a.total == 0 || b.min < a.min {
+		a.min = b.min
+	}
+	if b.max > a.max {
+		a.max = b.max
+	}
+	a.total += b.total
+	a.success += b.success
+	a.failed += b.failed
+	a.timeout += b.timeout
+	a.coldStarts += b.coldStarts
+	a.sumDuration += b.sumDuration
+	a.sumColdStart += b.sumColdStart
+	a.p50 += b.p50
+	a.p95 += b.p95
+	a.p99 += b.p99
+}
+
+// stats 将汇总转换为函数统计数据。
+func (a *latencyAggregate) stats() *FunctionStats {
+	stats := &FunctionStats{
+		TotalInvocations: a.total,
+		SuccessCount:     a.success,
+		FailedCount:      a.failed,
+		TimeoutCount:     a.timeout,
+		ColdStartCount:   a.coldStarts,
+		MinLatencyMs:     a.min,
+		MaxLatencyMs:     a.max,
+		TotalDurationMs:  int64(a.sumDuration),
+	}
+	if a.total > 0 {
+		n := float64(a.total)
+		stats.AvgLatencyMs = a.sumDuration / n
+		stats.P50LatencyMs = a.p50 / n
+		stats.P95LatencyMs = a.p95 / n
+		stats.P99LatencyMs = a.p99 / n
+		stats.SuccessRate = float64(a.success) / n * 100
+		stats.ErrorRate = float64(a.failed+a.timeout) / n * 100
+		stats.ColdStartRate = float64(a.coldStarts) / n * 100
+	}
+	if a.coldStarts > 0 {
+		stats.AvgColdStartMs = a.sumColdStart / float64(a.coldStarts)
+	}
+	return stats
+}
+
+// aggregateInvocations 按调用记录实时计算函数在 [from, to) 内的统计，用于尚未汇总的时间段。
+func (s *PostgresStore) aggregateInvocations(functionID string, from, to time.Time) (latencyAggregate, error) {
+	var a latencyAggregate
+	if !from.Before(to) {
+		return a, nil
+	}
+	query := `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'success' OR status = 'completed'),
+			COUNT(*) FILTER (WHERE status = 'failed'),
+			COUNT(*) FILTER (WHERE status = 'timeout'),
+			COUNT(*) FILTER (WHERE cold_start = true),
+			COALESCE(SUM(duration_ms), 0),
+			COALESCE(SUM(duration_ms) FILTER (WHERE cold_start = true), 0),
+			COALESCE(MIN(duration_ms), 0),
+			COALESCE(MAX(duration_ms), 0),
+			COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY duration_ms), 0) * COUNT(*),
+			COALESCE(PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY duration_ms), 0) * COUNT(*),
+			COALESCE(PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY duration_ms), 0) * COUNT(*)
+		FROM invocations
+		WHERE function_id = $1 AND created_at >= $2 AND created_at < $3 AND trigger_type <> 'shadow'
+	`
+	err := s.db.QueryRow(query, functionID, from, to).Scan(
+		&a.total, &a.success, &a.failed, &a.timeout, &a.coldStarts,
+		&a.sumDuration, &a.sumColdStart, &a.min, &a.max, &a.p50, &a.p95, &a.p99,
+	)
+	return a, err
+}
+
+// aggregateRollups 读取函数在 [from, to) 内已汇总的时间桶并合并。
+func (s *PostgresStore) aggregateRollups(functionID string, from, to time.Time) (latencyAggregate, error) {
+	var a latencyAggregate
+	if !from.Before(to) {
+		return a, nil
+	}
+	query := `
+		SELECT
+			COALESCE(SUM(total), 0),
+			COALESCE(SUM(success), 0),
+			COALESCE(SUM(failed), 0),
+			COALESCE(SUM(timeout), 0),
+			COALESCE(SUM(cold_starts), 0),
+			COALESCE(SUM(sum_duration_ms), 0),
+			COALESCE(SUM(sum_cold_start_ms), 0),
+			COALESCE(MIN(min_ms), 0),
+			COALESCE(MAX(max_ms), 0),
+			COALESCE(SUM(p50_ms * total), 0),
+			COALESCE(SUM(p95_ms * total), 0),
+			COALESCE(SUM(p99_ms * total), 0)
+		FROM function_latency_rollups
+		WHERE function_id = $1 AND bucket_start >= $2 AND bucket_start < $3
+	`
+	err := s.db.QueryRow(query, functionID, from, to).Scan(
+		&a.total, &a.success, &a.failed, &a.timeout, &a.coldStarts,
+		&a.sumDuration, &a.sumColdStart, &a.min, &a.max, &a.p50, &a.p95, &a.p99,
+	)
+	return a, err
+}
+
+// latestRollupBucket 返回最新已汇总时间桶的起始时间，尚未汇总过时返回零值。
+func (s *PostgresStore) latestRollupBucket() (time.Time, error) {
+	var latest sql.NullTime
+	if err := s.db.QueryRow(`SELECT MAX(bucket_start) FROM function_latency_rollups`).Scan(&latest); err != nil {
+		return time.Time{}, err
+	}
+	return latest.Time, nil
+}
+
+// RollupFunctionLatency 将已结束的小时时间桶内的调用统计和延迟分位数汇总到 function_latency_rollups 表。
+// 每次从最新已汇总的时间桶开始重新汇总，以纳入在时间桶结束后才完成的调用；
+// 首次汇总时回溯 latencyRollupBackfill。
+//
+// 参数:
+//   - now: 当前时间，只汇总在此之前已结束的时间桶
+//
+// 返回值:
+//   - int64: 写入或更新的汇总行数
+//   - error: 执行失败时返回错误信息
+func (s *PostgresStore) RollupFunctionLatency(now time.Time) (int64, error) {
+	until := now.Truncate(latencyRollupBucket)
+	from := until.Add(-latencyRollupBackfill)
+	latest, err := s.latestRollupBucket()
+	if err != nil {
+		return 0, err
+	}
+	if latest.After(from) {
+		from = latest
+	}
+
+	query := `
+		INSERT INTO function_latency_rollups (
+			function_id, bucket_start, total, success, failed, timeout, cold_starts,
+			sum_duration_ms, sum_cold_start_ms, min_ms, max_ms, p50_ms, p95_ms, p99_ms, rolled_up_at
+		)
+		SELECT
+			function_id,
+			date_trunc('hour', created_at),
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'success' OR status = 'completed'),
+			COUNT(*) FILTER (WHERE status = 'failed'),
+			COUNT(*) FILTER (WHERE status = 'timeout'),
+			COUNT(*) FILTER (WHERE cold_start = true),
+			COALESCE(SUM(duration_ms), 0),
+			COALESCE(SUM(duration_ms) FILTER (WHERE cold_start = true), 0),
+			COALESCE(MIN(duration_ms), 0),
+			COALESCE(MAX(duration_ms), 0),
+			COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY duration_ms), 0),
+			COALESCE(PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY duration_ms), 0),
+			COALESCE(PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY duration_ms), 0),
+			NOW()
+		FROM invocations
+		WHERE created_at >= $1 AND created_at < $2 AND trigger_type <> 'shadow'
+		GROUP BY function_id, date_trunc('hour', created_at)
+		ON CONFLICT (function_id, bucket_start) DO UPDATE SET
+			total = EXCLUDED.total,
+			success = EXCLUDED.success,
+			failed = EXCLUDED.failed,
+			timeout = EXCLUDED.timeout,
+			cold_starts = EXCLUDED.cold_starts,
+			sum_duration_ms = EXCLUDED.sum_duration_ms,
+			sum_cold_start_ms = EXCLUDED.sum_cold_start_ms,
+			min_ms = EXCLUDED.min_ms,
+			max_ms = EXCLUDED.max_ms,
+			p50_ms = EXCLUDED.p50_ms,
+			p95_ms = EXCLUDED.p95_ms,
+			p99_ms = EXCLUDED.p99_ms,
+			rolled_up_at = EXCLUDED.rolled_up_at
+	`
+	result, err := s.db.Exec(query, from, until)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// RunLatencyRollup 立即执行一次延迟汇总，之后按 interval 周期执行，直到 ctx 取消。
+//
+// 参数:
+//   - ctx: 控制汇总任务生命周期的上下文
+//   - interval: 汇总间隔
+//   - logger: 日志记录器
+func (s *PostgresStore) RunLatencyRollup(ctx context.Context, interval time.Duration, logger *logrus.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		rows, err := s.RollupFunctionLatency(start)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to roll up function latency")
+		} else {
+			logger.WithFields(logrus.Fields{
+				"rows":        rows,
+				"duration_ms": time.Since(start).Milliseconds(),
+			}).Debug("Function latency rolled up")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ==================== 测试用 database/sql 驱动 ====================
+
+// stubResult 是桩数据库对一条 SQL 的响应
+type stubResult struct {
+	rows     [][]driver.Value
+	affected int64
+	err      error
+}
+
+// stubDB 按 SQL 片段返回预设结果的数据库驱动，并记录执行过的语句和参数
+type stubDB struct {
+	respond func(query string) stubResult
+
+	mu    sync.Mutex
+	calls []stubCall
+}
+
+type stubCall struct {
+	query string
+	args  []driver.Value
+}
+
+func newStubStore(respond func(query string) stubResult) (*PostgresStore, *stubDB) {
+	db := &stubDB{respond: respond}
+	return &PostgresStore{db: sql.OpenDB(db)}, db
+}
+
+func (d *stubDB) Connect(context.Context) (driver.Conn, error) { return &stubConn{db: d}, nil }
+func (d *stubDB) Driver() driver.Driver                        { return nil }
+
+func (d *stubDB) call(query string, args []driver.Value) stubResult {
+	d.mu.Lock()
+	d.calls = append(d.calls, stubCall{query: query, args: args})
+	d.mu.Unlock()
+	return d.respond(query)
+}
+
+type stubConn struct{ db *stubDB }
+
+func (c *stubConn) Prepare(query string) (driver.Stmt, error) {
+	return &stubStmt{db: c.db, query: query}, nil
+}
+func (c *stubConn) Close() error              { return nil }
+func (c *stubConn) Begin() (driver.Tx, error) { return nil, errors.New("transactions not supported") }
+
+type stubStmt struct {
+	db    *stubDB
+	query string
+}
+
+func (s *stubStmt) Close() error  { return nil }
+func (s *stubStmt) NumInput() int { return -1 }
+
+func (s *stubStmt) Exec(args []driver.Value) (driver.Result, error) {
+	res := s.db.call(s.query, args)
+	if res.err != nil {
+		return nil, res.err
+	}
+	return driver.RowsAffected(res.affected), nil
+}
+
+func (s *stubStmt) Query(args []driver.Value) (driver.Rows, error) {
+	res := s.db.call(s.query, args)
+	if res.err != nil {
+		return nil, res.err
+	}
+	return &stubRows{rows: res.rows}, nil
+}
+
+type stubRows struct {
+	rows [][]driver.Value
+	next int
+}
+
+func (r *stubRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+func (r *stubRows) Close() error { return nil }
+
+func (r *stubRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+// aggregateRow 返回聚合查询的一行结果：total/success/failed/timeout/cold_starts、
+// 总耗时、冷启动总耗时、最小/最大耗时，以及按调用数加权的 p50/p95/p99
+func aggregateRow(total, success, failed, timeout, cold int64, sum, sumCold, min, max, p50, p95, p99 float64) []driver.Value {
+	return []driver.Value{total, success, failed, timeout, cold, sum, sumCold, min, max, p50, p95, p99}
+}
+
+// ==================== 测试 ====================
+
+func TestRollupFunctionLatency(t *testing.T) {
+	now := time.Date(2026, 3, 10, 14, 25, 0, 0, time.UTC)
+	latest := now.Add(-3 * time.Hour).Truncate(time.Hour)
+
+	store, db := newStubStore(func(query string) stubResult {
+		if strings.Contains(query, "MAX(bucket_start)") {
+			return stubResult{rows: [][]driver.Value{{latest}}}
+		}
+		return stubResult{affected: 7}
+	})
+	rows, err := store.RollupFunctionLatency(now)
+	if err != nil {
+		t.Fatalf("RollupFunctionLatency() error = %v", err)
+	}
+	if rows != 7 {
+		t.Errorf("rows = %d, want 7", rows)
+	}
+	// 从最新已汇总的时间桶重新汇总到当前小时开始
+	insert := db.calls[len(db.calls)-1]
+	if !strings.Contains(insert.query, "INSERT INTO function_latency_rollups") {
+		t.Fatalf("last query = %q, want rollup insert", insert.query)
+	}
+	if from, until := insert.args[0].(time.Time), insert.args[1].(time.Time); !from.Equal(latest) || !until.Equal(now.Truncate(time.Hour)) {
+		t.Errorf("rollup range = [%v, %v), want [%v, %v)", from, until, latest, now.Truncate(time.Hour))
+	}
+
+	// 首次汇总时回溯 latencyRollupBackfill
+	store, db = newStubStore(func(query string) stubResult {
+		if strings.Contains(query, "MAX(bucket_start)") {
+			return stubResult{rows: [][]driver.Value{{nil}}}
+		}
+		return stubResult{affected: 1}
+	})
+	if _, err := store.RollupFunctionLatency(now); err != nil {
+		t.Fatalf("first RollupFunctionLatency() error = %v", err)
+	}
+	if from := db.calls[len(db.calls)-1].args[0].(time.Time); !from.Equal(now.Truncate(time.Hour).Add(-latencyRollupBackfill)) {
+		t.Errorf("first rollup from = %v, want backfill start", from)
+	}
+}
+
+func TestRollupFunctionLatencyErrors(t *testing.T) {
+	now := time.Now()
+	boom := errors.New("connection reset")
+
+	store, _ := newStubStore(func(query string) stubResult { return stubResult{err: boom} })
+	if _, err := store.RollupFunctionLatency(now); !errors.Is(err, boom) {
+		t.Errorf("latest bucket failure: error = %v, want %v", err, boom)
+	}
+
+	store, _ = newStubStore(func(query string) stubResult {
+		if strings.Contains(query, "MAX(bucket_start)") {
+			return stubResult{rows: [][]driver.Value{{nil}}}
+		}
+		return stubResult{err: boom}
+	})
+	if _, err := store.RollupFunctionLatency(now); !errors.Is(err, boom) {
+		t.Errorf("insert failure: error = %v, want %v", err, boom)
+	}
+}
+
+func TestGetFunctionStatsMergesRollups(t *testing.T) {
+	latest := time.Now().Truncate(time.Hour).Add(-time.Hour)
+	invocationQueries := 0
+	store, _ := newStubStore(func(query string) stubResult {
+		switch {
+		case strings.Contains(query, "MAX(bucket_start)"):
+			return stubResult{rows: [][]driver.Value{{latest}}}
+		case strings.Contains(query, "FROM function_latency_rollups"):
+			// 已汇总的时间桶：2 次调用，均为成功
+			return stubResult{rows: [][]driver.Value{aggregateRow(2, 2, 0, 0, 1, 40, 15, 15, 25, 40, 50, 60)}}
+		default:
+			// 周期开头和最近未汇总的部分各 1 次调用
+			invocationQueries++
+			if invocationQueries == 1 {
+				return stubResult{rows: [][]driver.Value{aggregateRow(1, 0, 1, 0, 0, 10, 0, 10, 10, 10, 10, 10)}}
+			}
+			return stubResult{rows: [][]driver.Value{aggregateRow(1, 0, 0, 1, 0, 30, 0, 30, 30, 30, 30, 30)}}
+		}
+	})
+
+	stats, err := store.GetFunctionStats("fn-1", 3)
+	if err != nil {
+		t.Fatalf("GetFunctionStats() error = %v", err)
+	}
+	if invocationQueries != 2 {
+		t.Errorf("live invocation queries = %d, want 2 (period head and recent data)", invocationQueries)
+	}
+	if stats.TotalInvocations != 4 || stats.SuccessCount != 2 || stats.FailedCount != 1 || stats.TimeoutCount != 1 {
+		t.Errorf("counts = %+v, want 4 total, 2 success, 1 failed, 1 timeout", stats)
+	}
+	if stats.MinLatencyMs != 10 || stats.MaxLatencyMs != 30 || stats.AvgLatencyMs != 20 {
+		t.Errorf("latency min/max/avg = %v/%v/%v, want 10/30/20", stats.MinLatencyMs, stats.MaxLatencyMs, stats.AvgLatencyMs)
+	}
+	// 分位数按调用数加权合并：(10 + 40 + 30) / 4
+	if stats.P50LatencyMs != 20 {
+		t.Errorf("p50 = %v, want 20", stats.P50LatencyMs)
+	}
+	if stats.ErrorRate != 50 || stats.AvgColdStartMs != 15 {
+		t.Errorf("error rate = %v, avg cold start = %v, want 50 and 15", stats.ErrorRate, stats.AvgColdStartMs)
+	}
+}
+
+func TestGetFunctionStatsQueryError(t *testing.T) {
+	store, _ := newStubStore(func(query string) stubResult {
+		if strings.Contains(query, "MAX(bucket_start)") {
+			return stubResult{rows: [][]driver.Value{{nil}}}
+		}
+		return stubResult{err: errors.New("statement timeout")}
+	})
+
+	stats, err := store.GetFunctionStats("fn-1", 24)
+	if err != nil {
+		t.Fatalf("GetFunctionStats() error = %v, want empty stats without error", err)
+	}
+	if stats.TotalInvocations != 0 || math.IsNaN(stats.AvgLatencyMs) {
+		t.Errorf("stats = %+v, want empty stats", stats)
+	}
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+func TestCreateLogEntrySource(t *testing.T) {
+	store, db := newStubStore(func(query string) stubResult { return stubResult{affected: 1} })
+
+	entry := &domain.LogEntry{Timestamp: time.Now(), Level: "INFO", FunctionID: "fn-1", Message: "函数调用开始 (webhook)", Source: domain.LogSourceWebhook}
+	if err := store.CreateLogEntry(context.Background(), entry); err != nil {
+		t.Fatalf("CreateLogEntry() error = %v", err)
+	}
+	call := db.calls[len(db.calls)-1]
+	if !strings.Contains(call.query, "source") || call.args[len(call.args)-1] != domain.LogSourceWebhook {
+		t.Errorf("insert = %q with args %v, want source %q", call.query, call.args, domain.LogSourceWebhook)
+	}
+}
+
+func TestListLogEntriesSourceFilter(t *testing.T) {
+	ts := time.Date(2026, 3, 10, 14, 0, 0, 0, time.UTC)
+	store, db := newStubStore(func(query string) stubResult {
+		return stubResult{rows: [][]driver.Value{
+			{ts, "INFO", "fn-1", "demo", "函数调用完成 (route)", "req-1", nil, []byte(`"ok"`), nil, int64(12), domain.LogSourceRoute},
+		}}
+	})
+
+	entries, err := store.ListLogEntries(context.Background(), ListLogEntriesOptions{FunctionID: "fn-1", Source: domain.LogSourceRoute})
+	if err != nil {
+		t.Fatalf("ListLogEntries() error = %v", err)
+	}
+	call := db.calls[len(db.calls)-1]
+	if !strings.Contains(call.query, "function_id = $1 AND source = $2") || call.args[1] != domain.LogSourceRoute {
+		t.Errorf("query = %q with args %v, want source filter", call.query, call.args)
+	}
+	if len(entries) != 1 || entries[0].Source != domain.LogSourceRoute || entries[0].DurationMs != 12 || string(entries[0].Output) != `"ok"` {
+		t.Errorf("entries = %+v, want one route entry", entries)
+	}
+
+	// 未指定来源时不过滤
+	if _, err := store.ListLogEntries(context.Background(), ListLogEntriesOptions{}); err != nil {
+		t.Fatalf("ListLogEntries() error = %v", err)
+	}
+	if q := db.calls[len(db.calls)-1].query; strings.Contains(q, "source =") {
+		t.Errorf("query = %q, want no source filter", q)
+	}
+}
@@ -25,7 +25,8 @@ import (
 // PostgresStore 是 PostgreSQL 存储的封装结构体。
 // 提供函数、调用记录和 API 密钥的持久化存储功能。
 type PostgresStore struct {
-	db *sql.DB // 数据库连接池
+	db            *sql.DB // 数据库连接池
+	payloadSearch bool    // 是否开启调用输入输出的子串搜索（需要额外的三元组索引）
 }
 
 // NewPostgresStore 创建并初始化一个新的 PostgreSQL 存储实例。
@@ -64,7 +65,7 @@ func NewPostgresStore(cfg config.PostgresConfig) (*PostgresStore, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	store := &PostgresStore{db: db}
+	store := &PostgresStore{db: db, payloadSearch: cfg.InvocationPayloadSearch}
 	// 执行数据库迁移，创建所需的表结构
 	if err := store.migrate(); err != nil {
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
@@ -408,6 +409,9 @@ func (s *PostgresStore) migrate() error {
 		`INSERT INTO system_settings (key, value, description)
 		 SELECT 'dlq_retention_days', '90', '死信队列保留天数'
 		 WHERE NOT EXISTS (SELECT 1 FROM system_settings WHERE key = 'dlq_retention_days')`,
+		`INSERT INTO system_settings (key, value, description)
+		 SELECT 'version_retention_count', '20', '每个函数保留的最新版本数（0 表示保留全部）'
+		 WHERE NOT EXISTS (SELECT 1 FROM system_settings WHERE key = 'version_retention_count')`,
 		// 配额设置
 		`INSERT INTO system_settings (key, value, description)
 		 SELECT 'quota_max_functions', '100', '最大函数数量'
@@ -595,6 +599,154 @@ func (s *PostgresStore) migrate() error {
 		)`,
 		`CREATE INDEX IF NOT EXISTS idx_deps_source_id ON function_dependencies(source_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_deps_target_id ON function_dependencies(target_id)`,
+
+		// ==================== API Key 标签作用域 ====================
+		// 为 api_keys 表添加标签选择器，非空时该 Key 只能访问带有全部这些标签的函数
+		`ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tag_selector TEXT[] DEFAULT '{}'`,
+
+		// ==================== 影子流量表 ====================
+		// 创建 function_shadow_configs 表 - 存储函数的影子流量配置
+		`CREATE TABLE IF NOT EXISTS function_shadow_configs (
+			function_id VARCHAR(36) PRIMARY KEY REFERENCES functions(id) ON DELETE CASCADE,
+			target_function_id VARCHAR(36),
+			target_version INTEGER NOT NULL DEFAULT 0,
+			percentage INTEGER NOT NULL DEFAULT 0,
+			enabled BOOLEAN NOT NULL DEFAULT TRUE,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+		)`,
+		// 创建 shadow_results 表 - 存储影子调用与主调用的对比结果
+		`CREATE TABLE IF NOT EXISTS shadow_results (
+			id VARCHAR(36) PRIMARY KEY,
+			function_id VARCHAR(36) NOT NULL REFERENCES functions(id) ON DELETE CASCADE,
+			primary_invocation_id VARCHAR(36) NOT NULL,
+			shadow_invocation_id VARCHAR(36),
+			target_function_id VARCHAR(36) NOT NULL,
+			target_version INTEGER NOT NULL DEFAULT 0,
+			primary_status_code INTEGER NOT NULL DEFAULT 0,
+			shadow_status_code INTEGER NOT NULL DEFAULT 0,
+			primary_duration_ms BIGINT NOT NULL DEFAULT 0,
+			shadow_duration_ms BIGINT NOT NULL DEFAULT 0,
+			primary_output JSONB,
+			shadow_output JSONB,
+			shadow_error TEXT,
+			match BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_shadow_results_function_id ON shadow_results(function_id, created_at DESC)`,
+
+		// ==================== 预留并发 ====================
+		// 为 functions 表添加预留并发数，预留槽位从调度器共享容量中扣除
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS reserved_concurrency INTEGER DEFAULT 0`,
+
+		// ==================== 排队耗时 ====================
+		// 为 invocations 表添加排队耗时，与执行时长分开记录
+		`ALTER TABLE invocations ADD COLUMN IF NOT EXISTS queue_wait_ms BIGINT DEFAULT 0`,
+
+		// ==================== 调用限流 ====================
+		// 为 functions 表添加限流配置（令牌桶速率与容量）
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS rate_limit JSONB`,
+
+		// ==================== 响应缓存 ====================
+		// 为 functions 表添加响应缓存配置（有效期与缓存键表达式）
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS response_cache JSONB`,
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS build_env JSONB`,
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS build_args TEXT[]`,
+		// 常驻预热实例创建后用于试执行函数的预热载荷
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS warmup_payload JSONB`,
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS deprecation JSONB`,
+		`ALTER TABLE function_shadow_configs ADD COLUMN IF NOT EXISTS timeout_ms INTEGER NOT NULL DEFAULT 0`,
+
+		// ==================== 函数层上限 ====================
+		// 为 layer_versions 表添加解压后的总大小，用于校验函数挂载层的解压总大小
+		`ALTER TABLE layer_versions ADD COLUMN IF NOT EXISTS unpacked_size_bytes BIGINT DEFAULT 0`,
+
+		// ==================== 日志来源 ====================
+		// 为 logs 表添加调用日志的来源（api/route/webhook），用于按调用入口过滤日志
+		`ALTER TABLE logs ADD COLUMN IF NOT EXISTS source VARCHAR(16)`,
+
+		// ==================== 常驻预热 ====================
+		// 为 functions 表添加常驻预热实例数
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS keep_warm INTEGER DEFAULT 0`,
+
+		// ==================== 调用进度 ====================
+		// 为 invocations 表添加函数上报的最新执行进度
+		`ALTER TABLE invocations ADD COLUMN IF NOT EXISTS progress JSONB`,
+
+		// ==================== 调用成本标签 ====================
+		// 为 invocations 表添加调用方附加的成本标签，并为按标签汇总用量建立索引
+		`ALTER TABLE invocations ADD COLUMN IF NOT EXISTS cost_tags JSONB`,
+		`CREATE INDEX IF NOT EXISTS idx_invocations_cost_tags ON invocations USING GIN (cost_tags)`,
+		// 管道调用（?then=）的步骤信息，同一条管道的调用记录共享管道 ID
+		`ALTER TABLE invocations ADD COLUMN IF NOT EXISTS pipe JSONB`,
+		// 函数嵌套调用的上游调用链（函数 ID 数组），用于拦截和审计递归调用
+		`ALTER TABLE invocations ADD COLUMN IF NOT EXISTS call_chain JSONB`,
+		// 实际执行的函数版本号，用于按版本对比调用指标（金丝雀分析）
+		`ALTER TABLE invocations ADD COLUMN IF NOT EXISTS version INTEGER DEFAULT 0`,
+		`CREATE INDEX IF NOT EXISTS idx_invocations_function_version ON invocations(function_id, version, created_at DESC)`,
+		// 发起调用的工作流执行和状态名称，用于从工作流执行追溯实际的函数调用
+		`ALTER TABLE invocations ADD COLUMN IF NOT EXISTS workflow_execution_id TEXT`,
+		`ALTER TABLE invocations ADD COLUMN IF NOT EXISTS workflow_state TEXT DEFAULT ''`,
+		`ALTER TABLE invocations ADD COLUMN IF NOT EXISTS meta JSONB`,
+		`CREATE INDEX IF NOT EXISTS idx_invocations_workflow_execution ON invocations(workflow_execution_id, created_at) WHERE workflow_execution_id IS NOT NULL`,
+
+		// ==================== 无输出默认响应 ====================
+		// 为 functions 表添加函数无输出时的默认响应体
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS empty_response TEXT DEFAULT ''`,
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS maintenance_windows JSONB`,
+
+		// ==================== 共享数据卷 ====================
+		// 为 functions 表添加函数挂载的共享数据卷名称
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS data_volumes TEXT[] DEFAULT '{}'`,
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS live_slot TEXT DEFAULT ''`,
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS init_handler TEXT DEFAULT ''`,
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS allowed_environments TEXT[] DEFAULT '{}'`,
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS version_retention INTEGER DEFAULT 0`,
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS max_reuse INTEGER DEFAULT 0`,
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS stop_grace_period_sec INTEGER DEFAULT 0`,
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS max_payload_bytes BIGINT DEFAULT 0`,
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS custom_image TEXT DEFAULT ''`,
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS custom_command TEXT[] DEFAULT '{}'`,
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS working_dir TEXT DEFAULT ''`,
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS args TEXT[] DEFAULT '{}'`,
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS circuit_breaker JSONB`,
+		`ALTER TABLE functions ADD COLUMN IF NOT EXISTS priority TEXT DEFAULT ''`,
+		// 函数列表游标分页按 (created_at, id) 排序
+		`CREATE INDEX IF NOT EXISTS idx_functions_created_id ON functions(created_at DESC, id DESC)`,
+
+		// ==================== 函数延迟汇总 ====================
+		// 按小时汇总的函数调用统计与延迟分位数，统计接口读取汇总结果，避免扫描大量调用记录
+		`CREATE TABLE IF NOT EXISTS function_latency_rollups (
+			function_id VARCHAR(36) NOT NULL REFERENCES functions(id) ON DELETE CASCADE,
+			bucket_start TIMESTAMP WITH TIME ZONE NOT NULL,
+			total BIGINT NOT NULL DEFAULT 0,
+			success BIGINT NOT NULL DEFAULT 0,
+			failed BIGINT NOT NULL DEFAULT 0,
+			timeout BIGINT NOT NULL DEFAULT 0,
+			cold_starts BIGINT NOT NULL DEFAULT 0,
+			sum_duration_ms DOUBLE PRECISION NOT NULL DEFAULT 0,
+			sum_cold_start_ms DOUBLE PRECISION NOT NULL DEFAULT 0,
+			min_ms DOUBLE PRECISION NOT NULL DEFAULT 0,
+			max_ms DOUBLE PRECISION NOT NULL DEFAULT 0,
+			p50_ms DOUBLE PRECISION NOT NULL DEFAULT 0,
+			p95_ms DOUBLE PRECISION NOT NULL DEFAULT 0,
+			p99_ms DOUBLE PRECISION NOT NULL DEFAULT 0,
+			rolled_up_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (function_id, bucket_start)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_function_latency_rollups_bucket ON function_latency_rollups(bucket_start)`,
+
+		// ==================== 调用产物 ====================
+		// 函数写入 /output 目录的文件，随调用记录一起删除
+		`CREATE TABLE IF NOT EXISTS invocation_artifacts (
+			invocation_id VARCHAR(36) NOT NULL REFERENCES invocations(id) ON DELETE CASCADE,
+			name TEXT NOT NULL,
+			content_type TEXT NOT NULL DEFAULT '',
+			size BIGINT NOT NULL DEFAULT 0,
+			content BYTEA NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (invocation_id, name)
+		)`,
 	}
 
 	// 依次执行所有迁移语句
@@ -603,6 +755,35 @@ func (s *PostgresStore) migrate() error {
 			return err
 		}
 	}
+
+	// 可选迁移：依赖数据库扩展或权限，失败时不影响启动
+	optionalMigrations := []string{
+		// ==================== 函数代码搜索 ====================
+		// 为函数代码创建三元组索引，加速 ILIKE 子串搜索；扩展不可用时搜索退化为顺序扫描
+		`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+		`CREATE INDEX IF NOT EXISTS idx_functions_code_trgm ON functions USING GIN (code gin_trgm_ops)`,
+		// ==================== 调用记录搜索 ====================
+		// 错误信息的三元组索引只覆盖有错误的调用
+		`CREATE INDEX IF NOT EXISTS idx_invocations_error_trgm ON invocations USING GIN (error gin_trgm_ops) WHERE error IS NOT NULL`,
+	}
+	for _, m := range optionalMigrations {
+		if _, err := s.db.Exec(m); err != nil {
+			break
+		}
+	}
+
+	// 调用输入输出的子串搜索按需开启：三元组索引覆盖全部调用记录，体积和写入开销较大
+	if s.payloadSearch {
+		payloadMigrations := []string{
+			`CREATE INDEX IF NOT EXISTS idx_invocations_input_trgm ON invocations USING GIN ((input::text) gin_trgm_ops)`,
+			`CREATE INDEX IF NOT EXISTS idx_invocations_output_trgm ON invocations USING GIN ((output::text) gin_trgm_ops)`,
+		}
+		for _, m := range payloadMigrations {
+			if _, err := s.db.Exec(m); err != nil {
+				break
+			}
+		}
+	}
 	return nil
 }
 
@@ -652,13 +833,14 @@ func (s *PostgresStore) CreateFunction(fn *domain.Function) error {
 
 	// SQL: 插入函数记录到 functions 表
 	query := `
-		INSERT INTO functions (id, name, description, tags, pinned, runtime, handler, code, "binary", code_hash, memory_mb, timeout_sec, max_concurrency, env_vars, status, status_message, task_id, version, cron_expression, http_path, http_methods, webhook_enabled, webhook_key, last_deployed_at, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26)
+		INSERT INTO functions (id, name, description, tags, pinned, runtime, handler, code, "binary", code_hash, memory_mb, timeout_sec, max_concurrency, reserved_concurrency, env_vars, status, status_message, task_id, version, cron_expression, http_path, http_methods, webhook_enabled, webhook_key, last_deployed_at, created_at, updated_at, rate_limit, keep_warm, empty_response, maintenance_windows, data_volumes, init_handler, allowed_environments, version_retention, max_reuse, priority, response_cache, build_env, build_args, warmup_payload, deprecation, stop_grace_period_sec, circuit_breaker, max_payload_bytes, custom_image, custom_command, working_dir, args)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47, $48, $49)
 	`
 	_, err := s.db.Exec(query,
 		fn.ID, fn.Name, fn.Description, pq.Array(fn.Tags), fn.Pinned, fn.Runtime, fn.Handler, fn.Code, fn.Binary, fn.CodeHash,
-		fn.MemoryMB, fn.TimeoutSec, fn.MaxConcurrency, envVarsJSON, fn.Status, fn.StatusMessage, fn.TaskID, fn.Version,
+		fn.MemoryMB, fn.TimeoutSec, fn.MaxConcurrency, fn.ReservedConcurrency, envVarsJSON, fn.Status, fn.StatusMessage, fn.TaskID, fn.Version,
 		fn.CronExpression, fn.HTTPPath, httpMethodsJSON, fn.WebhookEnabled, webhookKey, fn.LastDeployedAt, fn.CreatedAt, fn.UpdatedAt,
+		rateLimitJSON(fn.RateLimit), fn.KeepWarm, fn.EmptyResponse, maintenanceWindowsJSON(fn.MaintenanceWindows), pq.Array(fn.DataVolumes), fn.InitHandler, pq.Array(fn.AllowedEnvironments), fn.VersionRetention, fn.MaxReuse, fn.Priority, responseCacheJSON(fn.ResponseCache), buildEnvJSON(fn.BuildEnv), pq.Array(fn.BuildArgs), warmupPayloadJSON(fn.WarmupPayload), deprecationJSON(fn.Deprecation), fn.StopGracePeriodSec, circuitBreakerJSON(fn.CircuitBreaker), fn.MaxPayloadBytes, fn.CustomImage, pq.Array(fn.CustomCommand), fn.WorkingDir, pq.Array(fn.Args),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create function: %w", err)
@@ -677,7 +859,7 @@ func (s *PostgresStore) CreateFunction(fn *domain.Function) error {
 func (s *PostgresStore) GetFunctionByID(id string) (*domain.Function, error) {
 	// SQL: 根据 ID 查询函数的所有字段
 	query := `
-		SELECT id, name, description, tags, pinned, runtime, handler, code, "binary", code_hash, memory_mb, timeout_sec, max_concurrency, env_vars, status, status_message, task_id, version, cron_expression, http_path, http_methods, webhook_enabled, webhook_key, last_deployed_at, state_config, created_at, updated_at
+		SELECT id, name, description, tags, pinned, runtime, handler, code, "binary", code_hash, memory_mb, timeout_sec, max_concurrency, reserved_concurrency, env_vars, status, status_message, task_id, version, cron_expression, http_path, http_methods, webhook_enabled, webhook_key, last_deployed_at, state_config, rate_limit, keep_warm, empty_response, maintenance_windows, data_volumes, COALESCE(live_slot, ''), COALESCE(init_handler, ''), allowed_environments, COALESCE(version_retention, 0), COALESCE(max_reuse, 0), COALESCE(priority, ''), response_cache, build_env, build_args, warmup_payload, deprecation, COALESCE(stop_grace_period_sec, 0), COALESCE(max_payload_bytes, 0), circuit_breaker, COALESCE(custom_image, ''), custom_command, COALESCE(working_dir, ''), args, created_at, updated_at
 		FROM functions WHERE id = $1
 	`
 	return s.scanFunction(s.db.QueryRow(query, id))
@@ -694,7 +876,7 @@ func (s *PostgresStore) GetFunctionByID(id string) (*domain.Function, error) {
 func (s *PostgresStore) GetFunctionByName(name string) (*domain.Function, error) {
 	// SQL: 根据名称查询函数的所有字段
 	query := `
-		SELECT id, name, description, tags, pinned, runtime, handler, code, "binary", code_hash, memory_mb, timeout_sec, max_concurrency, env_vars, status, status_message, task_id, version, cron_expression, http_path, http_methods, webhook_enabled, webhook_key, last_deployed_at, state_config, created_at, updated_at
+		SELECT id, name, description, tags, pinned, runtime, handler, code, "binary", code_hash, memory_mb, timeout_sec, max_concurrency, reserved_concurrency, env_vars, status, status_message, task_id, version, cron_expression, http_path, http_methods, webhook_enabled, webhook_key, last_deployed_at, state_config, rate_limit, keep_warm, empty_response, maintenance_windows, data_volumes, COALESCE(live_slot, ''), COALESCE(init_handler, ''), allowed_environments, COALESCE(version_retention, 0), COALESCE(max_reuse, 0), COALESCE(priority, ''), response_cache, build_env, build_args, warmup_payload, deprecation, COALESCE(stop_grace_period_sec, 0), COALESCE(max_payload_bytes, 0), circuit_breaker, COALESCE(custom_image, ''), custom_command, COALESCE(working_dir, ''), args, created_at, updated_at
 		FROM functions WHERE name = $1
 	`
 	return s.scanFunction(s.db.QueryRow(query, name))
@@ -711,7 +893,7 @@ func (s *PostgresStore) GetFunctionByName(name string) (*domain.Function, error)
 func (s *PostgresStore) GetFunctionByWebhookKey(webhookKey string) (*domain.Function, error) {
 	// SQL: 根据 Webhook 密钥查询函数的所有字段
 	query := `
-		SELECT id, name, description, tags, pinned, runtime, handler, code, "binary", code_hash, memory_mb, timeout_sec, max_concurrency, env_vars, status, status_message, task_id, version, cron_expression, http_path, http_methods, webhook_enabled, webhook_key, last_deployed_at, state_config, created_at, updated_at
+		SELECT id, name, description, tags, pinned, runtime, handler, code, "binary", code_hash, memory_mb, timeout_sec, max_concurrency, reserved_concurrency, env_vars, status, status_message, task_id, version, cron_expression, http_path, http_methods, webhook_enabled, webhook_key, last_deployed_at, state_config, rate_limit, keep_warm, empty_response, maintenance_windows, data_volumes, COALESCE(live_slot, ''), COALESCE(init_handler, ''), allowed_environments, COALESCE(version_retention, 0), COALESCE(max_reuse, 0), COALESCE(priority, ''), response_cache, build_env, build_args, warmup_payload, deprecation, COALESCE(stop_grace_period_sec, 0), COALESCE(max_payload_bytes, 0), circuit_breaker, COALESCE(custom_image, ''), custom_command, COALESCE(working_dir, ''), args, created_at, updated_at
 		FROM functions WHERE webhook_key = $1 AND webhook_enabled = TRUE
 	`
 	return s.scanFunction(s.db.QueryRow(query, webhookKey))
@@ -737,7 +919,7 @@ func (s *PostgresStore) ListFunctions(offset, limit int) ([]*domain.Function, in
 
 	// SQL: 分页查询函数列表，置顶函数优先，按创建时间倒序排列
 	query := `
-		SELECT id, name, description, tags, pinned, runtime, handler, code, "binary", code_hash, memory_mb, timeout_sec, max_concurrency, env_vars, status, status_message, task_id, version, cron_expression, http_path, http_methods, webhook_enabled, webhook_key, last_deployed_at, state_config, created_at, updated_at
+		SELECT id, name, description, tags, pinned, runtime, handler, code, "binary", code_hash, memory_mb, timeout_sec, max_concurrency, reserved_concurrency, env_vars, status, status_message, task_id, version, cron_expression, http_path, http_methods, webhook_enabled, webhook_key, last_deployed_at, state_config, rate_limit, keep_warm, empty_response, maintenance_windows, data_volumes, COALESCE(live_slot, ''), COALESCE(init_handler, ''), allowed_environments, COALESCE(version_retention, 0), COALESCE(max_reuse, 0), COALESCE(priority, ''), response_cache, build_env, build_args, warmup_payload, deprecation, COALESCE(stop_grace_period_sec, 0), COALESCE(max_payload_bytes, 0), circuit_breaker, COALESCE(custom_image, ''), custom_command, COALESCE(working_dir, ''), args, created_at, updated_at
 		FROM functions ORDER BY pinned DESC, created_at DESC LIMIT $1 OFFSET $2
 	`
 	rows, err := s.db.Query(query, limit, offset)
@@ -770,7 +952,49 @@ func (s *PostgresStore) ListFunctions(offset, limit int) ([]*domain.Function, in
 //   - int: 符合条件的函数总数（用于分页计算）
 //   - error: 查询失败时返回错误信息
 func (s *PostgresStore) ListFunctionsWithFilter(filter *domain.FunctionFilter, offset, limit int) ([]*domain.Function, int, error) {
-	// 构建动态 WHERE 条件
+	conditions, args := functionFilterConditions(filter)
+	argIndex := len(args) + 1
+
+	// 构建 WHERE 子句
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// SQL: 查询符合条件的函数总数
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM functions %s", whereClause)
+	var total int
+	err := s.db.QueryRow(countQuery, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// SQL: 分页查询函数列表，置顶函数优先，按更新时间倒序排列
+	selectQuery := fmt.Sprintf(`
+		SELECT id, name, description, tags, pinned, runtime, handler, code, "binary", code_hash, memory_mb, timeout_sec, max_concurrency, reserved_concurrency, env_vars, status, status_message, task_id, version, cron_expression, http_path, http_methods, webhook_enabled, webhook_key, last_deployed_at, state_config, rate_limit, keep_warm, empty_response, maintenance_windows, data_volumes, COALESCE(live_slot, ''), COALESCE(init_handler, ''), allowed_environments, COALESCE(version_retention, 0), COALESCE(max_reuse, 0), COALESCE(priority, ''), response_cache, build_env, build_args, warmup_payload, deprecation, COALESCE(stop_grace_period_sec, 0), COALESCE(max_payload_bytes, 0), circuit_breaker, COALESCE(custom_image, ''), custom_command, COALESCE(working_dir, ''), args, created_at, updated_at
+		FROM functions %s ORDER BY pinned DESC, updated_at DESC LIMIT $%d OFFSET $%d
+	`, whereClause, argIndex, argIndex+1)
+	args = append(args, limit, offset)
+
+	rows, err := s.db.Query(selectQuery, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var functions []*domain.Function
+	for rows.Next() {
+		fn, err := s.scanFunctionRow(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		functions = append(functions, fn)
+	}
+	return functions, total, nil
+}
+
+// functionFilterConditions 将函数筛选条件转换为 WHERE 条件和参数，参数占位符从 $1 开始编号。
+func functionFilterConditions(filter *domain.FunctionFilter) ([]string, []interface{}) {
 	var conditions []string
 	var args []interface{}
 	argIndex := 1
@@ -802,26 +1026,130 @@ func (s *PostgresStore) ListFunctionsWithFilter(filter *domain.FunctionFilter, o
 		args = append(args, string(filter.Status))
 		argIndex++
 	}
+	return conditions, args
+}
+
+// ListFunctionsByCursor 按游标分页查询函数列表，按 (created_at, id) 倒序排列（最新创建的在前）。
+// 与偏移分页不同，翻页期间其他函数的创建、删除不会导致重复或遗漏；置顶不影响游标分页的顺序。
+//
+// 参数:
+//   - filter: 筛选条件
+//   - after: 返回排在该游标之后（更早创建）的函数，为 nil 时从第一页开始
+//   - before: 返回排在该游标之前（更晚创建）的函数，用于向前翻页，与 after 互斥
+//   - limit: 返回的最大记录数
+//
+// 返回值:
+//   - []*domain.Function: 函数列表，始终按倒序排列
+//   - bool: 翻页方向上是否还有更多函数
+//   - int: 符合筛选条件的函数总数
+//   - error: 查询失败时返回错误信息
+func (s *PostgresStore) ListFunctionsByCursor(filter *domain.FunctionFilter, after, before *domain.FunctionCursor, limit int) ([]*domain.Function, bool, int, error) {
+	conditions, args := functionFilterConditions(filter)
 
-	// 构建 WHERE 子句
 	whereClause := ""
 	if len(conditions) > 0 {
 		whereClause = "WHERE " + strings.Join(conditions, " AND ")
 	}
-
-	// SQL: 查询符合条件的函数总数
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM functions %s", whereClause)
 	var total int
-	err := s.db.QueryRow(countQuery, args...).Scan(&total)
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM functions "+whereClause, args...).Scan(&total); err != nil {
+		return nil, false, 0, err
+	}
+
+	order := "DESC"
+	if after != nil {
+		args = append(args, after.CreatedAt, after.ID)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	} else if before != nil {
+		// 向前翻页时正序取紧邻游标的记录，再反转为倒序
+		args = append(args, before.CreatedAt, before.ID)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) > ($%d, $%d)", len(args)-1, len(args)))
+		order = "ASC"
+	}
+	whereClause = ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// 多取一条用于判断是否还有下一页
+	args = append(args, limit+1)
+	selectQuery := fmt.Sprintf(`
+		SELECT id, name, description, tags, pinned, runtime, handler, code, "binary", code_hash, memory_mb, timeout_sec, max_concurrency, reserved_concurrency, env_vars, status, status_message, task_id, version, cron_expression, http_path, http_methods, webhook_enabled, webhook_key, last_deployed_at, state_config, rate_limit, keep_warm, empty_response, maintenance_windows, data_volumes, COALESCE(live_slot, ''), COALESCE(init_handler, ''), allowed_environments, COALESCE(version_retention, 0), COALESCE(max_reuse, 0), COALESCE(priority, ''), response_cache, build_env, build_args, warmup_payload, deprecation, COALESCE(stop_grace_period_sec, 0), COALESCE(max_payload_bytes, 0), circuit_breaker, COALESCE(custom_image, ''), custom_command, COALESCE(working_dir, ''), args, created_at, updated_at
+		FROM functions %s ORDER BY created_at %s, id %s LIMIT $%d
+	`, whereClause, order, order, len(args))
+
+	rows, err := s.db.Query(selectQuery, args...)
 	if err != nil {
+		return nil, false, 0, err
+	}
+	defer rows.Close()
+
+	functions := make([]*domain.Function, 0, limit+1)
+	for rows.Next() {
+		fn, err := s.scanFunctionRow(rows)
+		if err != nil {
+			return nil, false, 0, err
+		}
+		functions = append(functions, fn)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, 0, err
+	}
+
+	hasMore := len(functions) > limit
+	if hasMore {
+		functions = functions[:limit]
+	}
+	if order == "ASC" {
+		for i, j := 0, len(functions)-1; i < j; i, j = i+1, j-1 {
+			functions[i], functions[j] = functions[j], functions[i]
+		}
+	}
+	return functions, hasMore, total, nil
+}
+
+// SearchFunctions 按代码内容（可选包括入口点和环境变量）搜索函数。
+// 使用大小写不敏感的子串匹配，code 列上的三元组索引可加速查询。
+//
+// 参数:
+//   - q: 搜索条件
+//   - offset: 跳过的记录数
+//   - limit: 返回的最大记录数
+//
+// 返回值:
+//   - []*domain.Function: 命中的函数列表，按名称排序
+//   - int: 命中的函数总数
+//   - error: 查询失败时返回错误
+func (s *PostgresStore) SearchFunctions(q *domain.CodeSearchQuery, offset, limit int) ([]*domain.Function, int, error) {
+	pattern := "%" + escapeLikePattern(q.Query) + "%"
+	fields := []string{"code ILIKE $1"}
+	if q.IncludeHandler {
+		fields = append(fields, "handler ILIKE $1")
+	}
+	if q.IncludeEnv {
+		fields = append(fields, "env_vars::text ILIKE $1")
+	}
+	conditions := []string{"(" + strings.Join(fields, " OR ") + ")"}
+	args := []interface{}{pattern}
+
+	if len(q.Tags) > 0 {
+		args = append(args, pq.Array(q.Tags))
+		conditions = append(conditions, fmt.Sprintf("tags @> $%d", len(args)))
+	}
+	if q.Runtime != "" {
+		args = append(args, string(q.Runtime))
+		conditions = append(conditions, fmt.Sprintf("runtime = $%d", len(args)))
+	}
+	whereClause := "WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM functions "+whereClause, args...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
-	// SQL: 分页查询函数列表，置顶函数优先，按更新时间倒序排列
 	selectQuery := fmt.Sprintf(`
-		SELECT id, name, description, tags, pinned, runtime, handler, code, "binary", code_hash, memory_mb, timeout_sec, max_concurrency, env_vars, status, status_message, task_id, version, cron_expression, http_path, http_methods, webhook_enabled, webhook_key, last_deployed_at, created_at, updated_at
-		FROM functions %s ORDER BY pinned DESC, updated_at DESC LIMIT $%d OFFSET $%d
-	`, whereClause, argIndex, argIndex+1)
+		SELECT id, name, description, tags, pinned, runtime, handler, code, "binary", code_hash, memory_mb, timeout_sec, max_concurrency, reserved_concurrency, env_vars, status, status_message, task_id, version, cron_expression, http_path, http_methods, webhook_enabled, webhook_key, last_deployed_at, state_config, rate_limit, keep_warm, empty_response, maintenance_windows, data_volumes, COALESCE(live_slot, ''), COALESCE(init_handler, ''), allowed_environments, COALESCE(version_retention, 0), COALESCE(max_reuse, 0), COALESCE(priority, ''), response_cache, build_env, build_args, warmup_payload, deprecation, COALESCE(stop_grace_period_sec, 0), COALESCE(max_payload_bytes, 0), circuit_breaker, COALESCE(custom_image, ''), custom_command, COALESCE(working_dir, ''), args, created_at, updated_at
+		FROM functions %s ORDER BY name LIMIT $%d OFFSET $%d
+	`, whereClause, len(args)+1, len(args)+2)
 	args = append(args, limit, offset)
 
 	rows, err := s.db.Query(selectQuery, args...)
@@ -838,7 +1166,12 @@ func (s *PostgresStore) ListFunctionsWithFilter(filter *domain.FunctionFilter, o
 		}
 		functions = append(functions, fn)
 	}
-	return functions, total, nil
+	return functions, total, rows.Err()
+}
+
+// escapeLikePattern 转义 LIKE 模式中的通配符，使搜索字符串按字面匹配。
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
 }
 
 // UpdateFunction 更新函数信息。
@@ -873,13 +1206,15 @@ func (s *PostgresStore) UpdateFunction(fn *domain.Function) error {
 		UPDATE functions SET
 			description = $2, tags = $3, pinned = $4, handler = $5, code = $6, "binary" = $7, code_hash = $8,
 			memory_mb = $9, timeout_sec = $10, max_concurrency = $11, env_vars = $12, status = $13, status_message = $14, task_id = $15,
-			version = $16, cron_expression = $17, http_path = $18, http_methods = $19, webhook_enabled = $20, webhook_key = $21, last_deployed_at = $22, state_config = $23, updated_at = $24
+			version = $16, cron_expression = $17, http_path = $18, http_methods = $19, webhook_enabled = $20, webhook_key = $21, last_deployed_at = $22, state_config = $23, updated_at = $24,
+			reserved_concurrency = $25, rate_limit = $26, keep_warm = $27, empty_response = $28, maintenance_windows = $29, data_volumes = $30, init_handler = $31, allowed_environments = $32, version_retention = $33, max_reuse = $34, priority = $35, response_cache = $36, build_env = $37, build_args = $38, warmup_payload = $39, deprecation = $40, stop_grace_period_sec = $41, circuit_breaker = $42, max_payload_bytes = $43, custom_image = $44, custom_command = $45, working_dir = $46, args = $47
 		WHERE id = $1
 	`
 	result, err := s.db.Exec(query,
 		fn.ID, fn.Description, pq.Array(fn.Tags), fn.Pinned, fn.Handler, fn.Code, fn.Binary, fn.CodeHash,
 		fn.MemoryMB, fn.TimeoutSec, fn.MaxConcurrency, envVarsJSON, fn.Status, fn.StatusMessage, fn.TaskID,
 		fn.Version, fn.CronExpression, fn.HTTPPath, httpMethodsJSON, fn.WebhookEnabled, webhookKey, fn.LastDeployedAt, stateConfigJSON, fn.UpdatedAt,
+		fn.ReservedConcurrency, rateLimitJSON(fn.RateLimit), fn.KeepWarm, fn.EmptyResponse, maintenanceWindowsJSON(fn.MaintenanceWindows), pq.Array(fn.DataVolumes), fn.InitHandler, pq.Array(fn.AllowedEnvironments), fn.VersionRetention, fn.MaxReuse, fn.Priority, responseCacheJSON(fn.ResponseCache), buildEnvJSON(fn.BuildEnv), pq.Array(fn.BuildArgs), warmupPayloadJSON(fn.WarmupPayload), deprecationJSON(fn.Deprecation), fn.StopGracePeriodSec, circuitBreakerJSON(fn.CircuitBreaker), fn.MaxPayloadBytes, fn.CustomImage, pq.Array(fn.CustomCommand), fn.WorkingDir, pq.Array(fn.Args),
 	)
 	if err != nil {
 		return err
@@ -895,6 +1230,39 @@ func (s *PostgresStore) UpdateFunction(fn *domain.Function) error {
 	return nil
 }
 
+// SetFunctionLiveSlot 以比较并交换的方式切换函数的蓝绿线上槽位。
+// 仅当当前槽位等于 expected 时才会更新，防止并发切换互相覆盖。
+//
+// 参数:
+//   - id: 函数唯一标识符
+//   - expected: 调用方读取到的当前槽位（未启用时为空字符串）
+//   - slot: 新的线上槽位
+//
+// 返回值:
+//   - error: 函数不存在时返回 ErrFunctionNotFound，槽位已被修改时返回 ErrSlotSwapConflict
+func (s *PostgresStore) SetFunctionLiveSlot(id, expected, slot string) error {
+	query := `UPDATE functions SET live_slot = $3, updated_at = $4 WHERE id = $1 AND COALESCE(live_slot, '') = $2`
+	result, err := s.db.Exec(query, id, expected, slot, time.Now())
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if affected == 0 {
+		var exists bool
+		if err := s.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM functions WHERE id = $1)`, id).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return domain.ErrFunctionNotFound
+		}
+		return domain.ErrSlotSwapConflict
+	}
+	return nil
+}
+
 // UpdateFunctionBinary 仅更新函数的编译后二进制数据。
 // 用于异步编译完成后单独更新二进制字段，避免覆盖其他并发修改。
 //
@@ -935,7 +1303,7 @@ func (s *PostgresStore) GetFunctionsByStatuses(statuses []string) ([]*domain.Fun
 	}
 
 	query := `
-		SELECT id, name, description, tags, pinned, runtime, handler, code, "binary", code_hash, memory_mb, timeout_sec, max_concurrency, env_vars, status, status_message, task_id, version, cron_expression, http_path, http_methods, webhook_enabled, webhook_key, last_deployed_at, created_at, updated_at
+		SELECT id, name, description, tags, pinned, runtime, handler, code, "binary", code_hash, memory_mb, timeout_sec, max_concurrency, reserved_concurrency, env_vars, status, status_message, task_id, version, cron_expression, http_path, http_methods, webhook_enabled, webhook_key, last_deployed_at, state_config, rate_limit, keep_warm, empty_response, maintenance_windows, data_volumes, COALESCE(live_slot, ''), COALESCE(init_handler, ''), allowed_environments, COALESCE(version_retention, 0), COALESCE(max_reuse, 0), COALESCE(priority, ''), response_cache, build_env, build_args, warmup_payload, deprecation, COALESCE(stop_grace_period_sec, 0), COALESCE(max_payload_bytes, 0), circuit_breaker, COALESCE(custom_image, ''), custom_command, COALESCE(working_dir, ''), args, created_at, updated_at
 		FROM functions WHERE status = ANY($1)
 	`
 	rows, err := s.db.Query(query, pq.Array(statuses))
@@ -983,12 +1351,60 @@ func (s *PostgresStore) DeleteFunction(id string) error {
 func (s *PostgresStore) GetFunctionByPath(path string) (*domain.Function, error) {
 	// SQL: 根据 http_path 查询函数
 	query := `
-		SELECT id, name, description, runtime, handler, code, "binary", code_hash, memory_mb, timeout_sec, env_vars, status, version, cron_expression, http_path, http_methods, created_at, updated_at
+		SELECT id, name, description, tags, pinned, runtime, handler, code, "binary", code_hash, memory_mb, timeout_sec, max_concurrency, reserved_concurrency, env_vars, status, status_message, task_id, version, cron_expression, http_path, http_methods, webhook_enabled, webhook_key, last_deployed_at, state_config, rate_limit, keep_warm, empty_response, maintenance_windows, data_volumes, COALESCE(live_slot, ''), COALESCE(init_handler, ''), allowed_environments, COALESCE(version_retention, 0), COALESCE(max_reuse, 0), COALESCE(priority, ''), response_cache, build_env, build_args, warmup_payload, deprecation, COALESCE(stop_grace_period_sec, 0), COALESCE(max_payload_bytes, 0), circuit_breaker, COALESCE(custom_image, ''), custom_command, COALESCE(working_dir, ''), args, created_at, updated_at
 		FROM functions WHERE http_path = $1
 	`
 	return s.scanFunction(s.db.QueryRow(query, path))
 }
 
+// ListRouteTemplates 列出所有带路径参数的自定义 HTTP 路由（http_path 包含 "{"）。
+func (s *PostgresStore) ListRouteTemplates() ([]domain.RouteTemplate, error) {
+	rows, err := s.db.Query(`SELECT id, http_path FROM functions WHERE http_path LIKE '%{%'`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list route templates: %w", err)
+	}
+	defer rows.Close()
+
+	var routes []domain.RouteTemplate
+	for rows.Next() {
+		var route domain.RouteTemplate
+		if err := rows.Scan(&route.FunctionID, &route.Path); err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+	return routes, rows.Err()
+}
+
+// ListRouteFunctions 列出所有配置了自定义 HTTP 路由的函数，用于导出 OpenAPI 文档。
+//
+// 返回值:
+//   - []*domain.Function: 配置了 http_path 的函数，按路径排序
+//   - error: 查询失败时返回错误
+func (s *PostgresStore) ListRouteFunctions() ([]*domain.Function, error) {
+	query := `
+		SELECT id, name, description, tags, pinned, runtime, handler, code, "binary", code_hash, memory_mb, timeout_sec, max_concurrency, reserved_concurrency, env_vars, status, status_message, task_id, version, cron_expression, http_path, http_methods, webhook_enabled, webhook_key, last_deployed_at, state_config, rate_limit, keep_warm, empty_response, maintenance_windows, data_volumes, COALESCE(live_slot, ''), COALESCE(init_handler, ''), allowed_environments, COALESCE(version_retention, 0), COALESCE(max_reuse, 0), COALESCE(priority, ''), response_cache, build_env, build_args, warmup_payload, deprecation, COALESCE(stop_grace_period_sec, 0), COALESCE(max_payload_bytes, 0), circuit_breaker, COALESCE(custom_image, ''), custom_command, COALESCE(working_dir, ''), args, created_at, updated_at
+		FROM functions
+		WHERE COALESCE(http_path, '') <> ''
+		ORDER BY http_path
+	`
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list route functions: %w", err)
+	}
+	defer rows.Close()
+
+	var functions []*domain.Function
+	for rows.Next() {
+		fn, err := s.scanFunctionRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		functions = append(functions, fn)
+	}
+	return functions, rows.Err()
+}
+
 // UpdateFunctionPin 更新函数的置顶状态。
 //
 // 参数:
@@ -1024,13 +1440,13 @@ func (s *PostgresStore) UpdateFunctionPin(id string, pinned bool) error {
 //   - error: 扫描失败或记录不存在时返回错误
 func (s *PostgresStore) scanFunction(row *sql.Row) (*domain.Function, error) {
 	fn := &domain.Function{}
-	var envVarsJSON, httpMethodsJSON, stateConfigJSON []byte
+	var envVarsJSON, httpMethodsJSON, stateConfigJSON, rateLimitJSON, maintenanceJSON, responseCacheJSON, buildEnvJSON, warmupPayload, deprecationJSON, circuitBreakerJSON []byte
 	var description, code, binary, codeHash, cronExpression, httpPath, statusMessage, taskID, webhookKey sql.NullString
 	var lastDeployedAt sql.NullTime
 	err := row.Scan(
 		&fn.ID, &fn.Name, &description, pq.Array(&fn.Tags), &fn.Pinned, &fn.Runtime, &fn.Handler, &code, &binary, &codeHash,
-		&fn.MemoryMB, &fn.TimeoutSec, &fn.MaxConcurrency, &envVarsJSON, &fn.Status, &statusMessage, &taskID, &fn.Version,
-		&cronExpression, &httpPath, &httpMethodsJSON, &fn.WebhookEnabled, &webhookKey, &lastDeployedAt, &stateConfigJSON, &fn.CreatedAt, &fn.UpdatedAt,
+		&fn.MemoryMB, &fn.TimeoutSec, &fn.MaxConcurrency, &fn.ReservedConcurrency, &envVarsJSON, &fn.Status, &statusMessage, &taskID, &fn.Version,
+		&cronExpression, &httpPath, &httpMethodsJSON, &fn.WebhookEnabled, &webhookKey, &lastDeployedAt, &stateConfigJSON, &rateLimitJSON, &fn.KeepWarm, &fn.EmptyResponse, &maintenanceJSON, pq.Array(&fn.DataVolumes), &fn.LiveSlot, &fn.InitHandler, pq.Array(&fn.AllowedEnvironments), &fn.VersionRetention, &fn.MaxReuse, &fn.Priority, &responseCacheJSON, &buildEnvJSON, pq.Array(&fn.BuildArgs), &warmupPayload, &deprecationJSON, &fn.StopGracePeriodSec, &fn.MaxPayloadBytes, &circuitBreakerJSON, &fn.CustomImage, pq.Array(&fn.CustomCommand), &fn.WorkingDir, pq.Array(&fn.Args), &fn.CreatedAt, &fn.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, domain.ErrFunctionNotFound
@@ -1075,9 +1491,92 @@ func (s *PostgresStore) scanFunction(row *sql.Row) (*domain.Function, error) {
 	if len(stateConfigJSON) > 0 {
 		json.Unmarshal(stateConfigJSON, &fn.StateConfig)
 	}
+	if len(rateLimitJSON) > 0 {
+		json.Unmarshal(rateLimitJSON, &fn.RateLimit)
+	}
+	if len(maintenanceJSON) > 0 {
+		json.Unmarshal(maintenanceJSON, &fn.MaintenanceWindows)
+	}
+	if len(responseCacheJSON) > 0 {
+		json.Unmarshal(responseCacheJSON, &fn.ResponseCache)
+	}
+	if len(buildEnvJSON) > 0 {
+		json.Unmarshal(buildEnvJSON, &fn.BuildEnv)
+	}
+	if len(warmupPayload) > 0 {
+		fn.WarmupPayload = warmupPayload
+	}
+	if len(deprecationJSON) > 0 {
+		json.Unmarshal(deprecationJSON, &fn.Deprecation)
+	}
+	if len(circuitBreakerJSON) > 0 {
+		json.Unmarshal(circuitBreakerJSON, &fn.CircuitBreaker)
+	}
 	return fn, nil
 }
 
+// rateLimitJSON 将限流配置序列化为 JSONB 参数，未配置时返回 nil 以写入 NULL。
+func rateLimitJSON(cfg *domain.RateLimitConfig) interface{} {
+	if cfg == nil {
+		return nil
+	}
+	data, _ := json.Marshal(cfg)
+	return data
+}
+
+// responseCacheJSON 将响应缓存配置序列化为 JSONB 参数，未配置时返回 nil 以写入 NULL。
+func responseCacheJSON(cfg *domain.ResponseCacheConfig) interface{} {
+	if cfg == nil {
+		return nil
+	}
+	data, _ := json.Marshal(cfg)
+	return data
+}
+
+// buildEnvJSON 将编译环境变量序列化为 JSONB 参数，未配置时返回 nil 以写入 NULL。
+func buildEnvJSON(env map[string]string) interface{} {
+	if len(env) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(env)
+	return data
+}
+
+// warmupPayloadJSON 将预热载荷转换为 JSONB 参数，未配置时返回 nil 以写入 NULL。
+func warmupPayloadJSON(payload json.RawMessage) interface{} {
+	if len(payload) == 0 {
+		return nil
+	}
+	return []byte(payload)
+}
+
+// deprecationJSON 将弃用配置序列化为 JSONB 参数，未弃用时返回 nil 以写入 NULL。
+func deprecationJSON(d *domain.FunctionDeprecation) interface{} {
+	if d == nil {
+		return nil
+	}
+	data, _ := json.Marshal(d)
+	return data
+}
+
+// circuitBreakerJSON 将熔断配置序列化为 JSONB 参数，未配置时返回 nil 以写入 NULL。
+func circuitBreakerJSON(c *domain.CircuitBreakerConfig) interface{} {
+	if c == nil {
+		return nil
+	}
+	data, _ := json.Marshal(c)
+	return data
+}
+
+// maintenanceWindowsJSON 将维护窗口配置序列化为 JSONB 参数，未配置时返回 nil 以写入 NULL。
+func maintenanceWindowsJSON(windows []domain.MaintenanceWindow) interface{} {
+	if len(windows) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(windows)
+	return data
+}
+
 // scanFunctionRow 从多行查询结果中扫描单个函数数据。
 // 内部辅助方法，用于 ListFunctions。
 //
@@ -1089,13 +1588,13 @@ func (s *PostgresStore) scanFunction(row *sql.Row) (*domain.Function, error) {
 //   - error: 扫描失败时返回错误
 func (s *PostgresStore) scanFunctionRow(rows *sql.Rows) (*domain.Function, error) {
 	fn := &domain.Function{}
-	var envVarsJSON, httpMethodsJSON, stateConfigJSON []byte
+	var envVarsJSON, httpMethodsJSON, stateConfigJSON, rateLimitJSON, maintenanceJSON, responseCacheJSON, buildEnvJSON, warmupPayload, deprecationJSON, circuitBreakerJSON []byte
 	var description, code, binary, codeHash, cronExpression, httpPath, statusMessage, taskID, webhookKey sql.NullString
 	var lastDeployedAt sql.NullTime
 	err := rows.Scan(
 		&fn.ID, &fn.Name, &description, pq.Array(&fn.Tags), &fn.Pinned, &fn.Runtime, &fn.Handler, &code, &binary, &codeHash,
-		&fn.MemoryMB, &fn.TimeoutSec, &fn.MaxConcurrency, &envVarsJSON, &fn.Status, &statusMessage, &taskID, &fn.Version,
-		&cronExpression, &httpPath, &httpMethodsJSON, &fn.WebhookEnabled, &webhookKey, &lastDeployedAt, &stateConfigJSON, &fn.CreatedAt, &fn.UpdatedAt,
+		&fn.MemoryMB, &fn.TimeoutSec, &fn.MaxConcurrency, &fn.ReservedConcurrency, &envVarsJSON, &fn.Status, &statusMessage, &taskID, &fn.Version,
+		&cronExpression, &httpPath, &httpMethodsJSON, &fn.WebhookEnabled, &webhookKey, &lastDeployedAt, &stateConfigJSON, &rateLimitJSON, &fn.KeepWarm, &fn.EmptyResponse, &maintenanceJSON, pq.Array(&fn.DataVolumes), &fn.LiveSlot, &fn.InitHandler, pq.Array(&fn.AllowedEnvironments), &fn.VersionRetention, &fn.MaxReuse, &fn.Priority, &responseCacheJSON, &buildEnvJSON, pq.Array(&fn.BuildArgs), &warmupPayload, &deprecationJSON, &fn.StopGracePeriodSec, &fn.MaxPayloadBytes, &circuitBreakerJSON, &fn.CustomImage, pq.Array(&fn.CustomCommand), &fn.WorkingDir, pq.Array(&fn.Args), &fn.CreatedAt, &fn.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -1137,6 +1636,27 @@ func (s *PostgresStore) scanFunctionRow(rows *sql.Rows) (*domain.Function, error
 	if len(stateConfigJSON) > 0 {
 		json.Unmarshal(stateConfigJSON, &fn.StateConfig)
 	}
+	if len(rateLimitJSON) > 0 {
+		json.Unmarshal(rateLimitJSON, &fn.RateLimit)
+	}
+	if len(maintenanceJSON) > 0 {
+		json.Unmarshal(maintenanceJSON, &fn.MaintenanceWindows)
+	}
+	if len(responseCacheJSON) > 0 {
+		json.Unmarshal(responseCacheJSON, &fn.ResponseCache)
+	}
+	if len(buildEnvJSON) > 0 {
+		json.Unmarshal(buildEnvJSON, &fn.BuildEnv)
+	}
+	if len(warmupPayload) > 0 {
+		fn.WarmupPayload = warmupPayload
+	}
+	if len(deprecationJSON) > 0 {
+		json.Unmarshal(deprecationJSON, &fn.Deprecation)
+	}
+	if len(circuitBreakerJSON) > 0 {
+		json.Unmarshal(circuitBreakerJSON, &fn.CircuitBreaker)
+	}
 	return fn, nil
 }
 
@@ -1158,16 +1678,45 @@ func (s *PostgresStore) CreateInvocation(inv *domain.Invocation) error {
 
 	// SQL: 插入调用记录的初始信息
 	query := `
-		INSERT INTO invocations (id, function_id, function_name, trigger_type, status, input, cold_start, retry_count, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO invocations (id, function_id, function_name, trigger_type, status, input, cold_start, retry_count, created_at, cost_tags, pipe, call_chain, version,
+		                         workflow_execution_id, workflow_state)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 	`
 	_, err := s.db.Exec(query,
 		inv.ID, inv.FunctionID, inv.FunctionName, inv.TriggerType, inv.Status,
-		inv.Input, inv.ColdStart, inv.RetryCount, inv.CreatedAt,
+		inv.Input, inv.ColdStart, inv.RetryCount, inv.CreatedAt, costTagsJSON(inv.CostTags), pipeJSON(inv.Pipe),
+		callChainJSON(inv.CallChain), inv.Version, sql.NullString{String: inv.WorkflowExecutionID, Valid: inv.WorkflowExecutionID != ""}, inv.WorkflowState,
 	)
 	return err
 }
 
+// costTagsJSON 将成本标签序列化为 JSONB 参数，没有标签时返回 nil 以写入 NULL。
+func costTagsJSON(tags map[string]string) interface{} {
+	if len(tags) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(tags)
+	return data
+}
+
+// pipeJSON 将管道步骤信息序列化为 JSONB 参数，非管道调用时返回 nil 以写入 NULL。
+func pipeJSON(pipe *domain.InvocationPipe) interface{} {
+	if pipe == nil {
+		return nil
+	}
+	data, _ := json.Marshal(pipe)
+	return data
+}
+
+// callChainJSON 将调用链序列化为 JSONB 参数，非嵌套调用时返回 nil 以写入 NULL。
+func callChainJSON(chain []string) interface{} {
+	if len(chain) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(chain)
+	return data
+}
+
 // GetInvocationByID 根据调用 ID 获取调用记录详情。
 //
 // 参数:
@@ -1181,19 +1730,21 @@ func (s *PostgresStore) GetInvocationByID(id string) (*domain.Invocation, error)
 	query := `
 		SELECT id, function_id, function_name, trigger_type, status, input, output, error,
 		       cold_start, vm_id, started_at, completed_at, duration_ms, billed_time_ms,
-		       memory_used_mb, retry_count, created_at
+		       memory_used_mb, retry_count, created_at, COALESCE(queue_wait_ms, 0), progress, cost_tags, pipe, call_chain, COALESCE(version, 0),
+		       COALESCE(workflow_execution_id, ''), COALESCE(workflow_state, ''), meta
 		FROM invocations WHERE id = $1
 	`
 	inv := &domain.Invocation{}
 	// 处理可能为空的字段
 	var vmID sql.NullString
-	var input, output []byte
+	var input, output, progress, costTags, pipe, callChain, meta []byte
 	var errStr sql.NullString
 	err := s.db.QueryRow(query, id).Scan(
 		&inv.ID, &inv.FunctionID, &inv.FunctionName, &inv.TriggerType, &inv.Status,
 		&input, &output, &errStr, &inv.ColdStart, &vmID,
 		&inv.StartedAt, &inv.CompletedAt, &inv.DurationMs, &inv.BilledTimeMs,
-		&inv.MemoryUsedMB, &inv.RetryCount, &inv.CreatedAt,
+		&inv.MemoryUsedMB, &inv.RetryCount, &inv.CreatedAt, &inv.QueueWaitMs, &progress, &costTags, &pipe, &callChain, &inv.Version,
+		&inv.WorkflowExecutionID, &inv.WorkflowState, &meta,
 	)
 	if err == sql.ErrNoRows {
 		return nil, domain.ErrInvocationNotFound
@@ -1214,55 +1765,67 @@ func (s *PostgresStore) GetInvocationByID(id string) (*domain.Invocation, error)
 	if errStr.Valid {
 		inv.Error = errStr.String
 	}
+	if progress != nil {
+		json.Unmarshal(progress, &inv.Progress)
+	}
+	if costTags != nil {
+		json.Unmarshal(costTags, &inv.CostTags)
+	}
+	if pipe != nil {
+		json.Unmarshal(pipe, &inv.Pipe)
+	}
+	if callChain != nil {
+		json.Unmarshal(callChain, &inv.CallChain)
+	}
+	if meta != nil {
+		inv.Meta = meta
+	}
 	return inv, nil
 }
 
-// ListInvocationsByFunction 分页查询指定函数的调用记录。
+// GetInvocationsByIDs 在一次查询中批量获取调用记录，用于批量轮询多个异步调用的结果。
+// 不存在的 ID 不会出现在结果中，由调用方标记。
 //
 // 参数:
-//   - functionID: 函数唯一标识符
-//   - offset: 跳过的记录数（用于分页）
-//   - limit: 返回的最大记录数
+//   - ids: 调用记录 ID 列表
 //
 // 返回值:
-//   - []*domain.Invocation: 调用记录列表
-//   - int: 调用记录总数（用于分页计算）
+//   - map[string]*domain.Invocation: 按调用 ID 索引的调用记录
 //   - error: 查询失败时返回错误信息
-func (s *PostgresStore) ListInvocationsByFunction(functionID string, offset, limit int) ([]*domain.Invocation, int, error) {
-	// SQL: 查询指定函数的调用记录总数
-	var total int
-	err := s.db.QueryRow("SELECT COUNT(*) FROM invocations WHERE function_id = $1", functionID).Scan(&total)
-	if err != nil {
-		return nil, 0, err
+func (s *PostgresStore) GetInvocationsByIDs(ids []string) (map[string]*domain.Invocation, error) {
+	invocations := make(map[string]*domain.Invocation, len(ids))
+	if len(ids) == 0 {
+		return invocations, nil
 	}
 
-	// SQL: 分页查询调用记录，按创建时间倒序排列
+	// SQL: 按 ID 列表一次查询所有调用记录
 	query := `
 		SELECT id, function_id, function_name, trigger_type, status, input, output, error,
 		       cold_start, vm_id, started_at, completed_at, duration_ms, billed_time_ms,
-		       memory_used_mb, retry_count, created_at
-		FROM invocations WHERE function_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3
+		       memory_used_mb, retry_count, created_at, COALESCE(queue_wait_ms, 0), progress, cost_tags, pipe, call_chain, COALESCE(version, 0),
+		       COALESCE(workflow_execution_id, ''), COALESCE(workflow_state, '')
+		FROM invocations WHERE id = ANY($1)
 	`
-	rows, err := s.db.Query(query, functionID, limit, offset)
+	rows, err := s.db.Query(query, pq.Array(ids))
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer rows.Close()
 
-	var invocations []*domain.Invocation
 	for rows.Next() {
 		inv := &domain.Invocation{}
 		var vmID sql.NullString
-		var input, output []byte
+		var input, output, progress, costTags, pipe, callChain []byte
 		var errStr sql.NullString
 		err := rows.Scan(
 			&inv.ID, &inv.FunctionID, &inv.FunctionName, &inv.TriggerType, &inv.Status,
 			&input, &output, &errStr, &inv.ColdStart, &vmID,
 			&inv.StartedAt, &inv.CompletedAt, &inv.DurationMs, &inv.BilledTimeMs,
-			&inv.MemoryUsedMB, &inv.RetryCount, &inv.CreatedAt,
+			&inv.MemoryUsedMB, &inv.RetryCount, &inv.CreatedAt, &inv.QueueWaitMs, &progress, &costTags, &pipe, &callChain, &inv.Version,
+			&inv.WorkflowExecutionID, &inv.WorkflowState,
 		)
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
 		if vmID.Valid {
 			inv.VMID = vmID.String
@@ -1276,41 +1839,283 @@ func (s *PostgresStore) ListInvocationsByFunction(functionID string, offset, lim
 		if errStr.Valid {
 			inv.Error = errStr.String
 		}
-		invocations = append(invocations, inv)
+		if progress != nil {
+			json.Unmarshal(progress, &inv.Progress)
+		}
+		if costTags != nil {
+			json.Unmarshal(costTags, &inv.CostTags)
+		}
+		if pipe != nil {
+			json.Unmarshal(pipe, &inv.Pipe)
+		}
+		if callChain != nil {
+			json.Unmarshal(callChain, &inv.CallChain)
+		}
+		invocations[inv.ID] = inv
 	}
-	return invocations, total, nil
+	return invocations, rows.Err()
 }
 
-// UpdateInvocation 更新调用记录。
-// 通常在调用完成后调用，更新输出结果、执行时间等信息。
+// SaveInvocationArtifacts 在一个事务中保存调用的产物文件，同名产物覆盖已有内容。
 //
 // 参数:
-//   - inv: 包含更新数据的调用记录对象
+//   - invocationID: 调用 ID
+//   - artifacts: 产物列表，需包含文件内容
 //
 // 返回值:
-//   - error: 记录不存在时返回 ErrInvocationNotFound，其他错误返回相应信息
-func (s *PostgresStore) UpdateInvocation(inv *domain.Invocation) error {
-	// JSONB 字段需要特别处理：如果传入的是“typed nil”（例如 json.RawMessage(nil)），
-	// pq 会将其当作空字符串而不是 NULL，导致 JSON 解析失败。
-	var output any
-	if len(inv.Output) == 0 {
+//   - error: 保存失败时返回错误信息，此时不保存任何产物
+func (s *PostgresStore) SaveInvocationArtifacts(invocationID string, artifacts []domain.InvocationArtifact) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO invocation_artifacts (invocation_id, name, content_type, size, content, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (invocation_id, name) DO UPDATE SET
+			content_type = EXCLUDED.content_type, size = EXCLUDED.size,
+			content = EXCLUDED.content, created_at = EXCLUDED.created_at
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, a := range artifacts {
+		if _, err := stmt.Exec(invocationID, a.Name, a.ContentType, a.Size, a.Data, a.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListInvocationArtifacts 列出调用的产物（不含文件内容），按名称排序，并填充下载地址。
+func (s *PostgresStore) ListInvocationArtifacts(invocationID string) ([]domain.InvocationArtifact, error) {
+	rows, err := s.db.Query(`
+		SELECT name, content_type, size, created_at
+		FROM invocation_artifacts WHERE invocation_id = $1 ORDER BY name
+	`, invocationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var artifacts []domain.InvocationArtifact
+	for rows.Next() {
+		var a domain.InvocationArtifact
+		if err := rows.Scan(&a.Name, &a.ContentType, &a.Size, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		a.URL = domain.ArtifactURL(invocationID, a.Name)
+		artifacts = append(artifacts, a)
+	}
+	return artifacts, rows.Err()
+}
+
+// GetInvocationArtifact 获取调用的单个产物，包含文件内容。
+//
+// 返回值:
+//   - *domain.InvocationArtifact: 产物
+//   - error: 产物不存在时返回 ErrArtifactNotFound，其他错误返回相应信息
+func (s *PostgresStore) GetInvocationArtifact(invocationID, name string) (*domain.InvocationArtifact, error) {
+	a := &domain.InvocationArtifact{Name: name}
+	err := s.db.QueryRow(`
+		SELECT content_type, size, content, created_at
+		FROM invocation_artifacts WHERE invocation_id = $1 AND name = $2
+	`, invocationID, name).Scan(&a.ContentType, &a.Size, &a.Data, &a.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrArtifactNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	a.URL = domain.ArtifactURL(invocationID, name)
+	return a, nil
+}
+
+// ListInvocationsByFunction 分页查询指定函数的调用记录。
+//
+// 参数:
+//   - functionID: 函数唯一标识符
+//   - offset: 跳过的记录数（用于分页）
+//   - limit: 返回的最大记录数
+//
+// 返回值:
+//   - []*domain.Invocation: 调用记录列表
+//   - int: 调用记录总数（用于分页计算）
+//   - error: 查询失败时返回错误信息
+func (s *PostgresStore) ListInvocationsByFunction(functionID string, offset, limit int) ([]*domain.Invocation, int, error) {
+	// SQL: 查询指定函数的调用记录总数
+	var total int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM invocations WHERE function_id = $1", functionID).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// SQL: 分页查询调用记录，按创建时间倒序排列
+	query := `
+		SELECT id, function_id, function_name, trigger_type, status, input, output, error,
+		       cold_start, vm_id, started_at, completed_at, duration_ms, billed_time_ms,
+		       memory_used_mb, retry_count, created_at, COALESCE(queue_wait_ms, 0), progress, cost_tags, pipe, call_chain, COALESCE(version, 0),
+		       COALESCE(workflow_execution_id, ''), COALESCE(workflow_state, '')
+		FROM invocations WHERE function_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3
+	`
+	rows, err := s.db.Query(query, functionID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var invocations []*domain.Invocation
+	for rows.Next() {
+		inv := &domain.Invocation{}
+		var vmID sql.NullString
+		var input, output, progress, costTags, pipe, callChain []byte
+		var errStr sql.NullString
+		err := rows.Scan(
+			&inv.ID, &inv.FunctionID, &inv.FunctionName, &inv.TriggerType, &inv.Status,
+			&input, &output, &errStr, &inv.ColdStart, &vmID,
+			&inv.StartedAt, &inv.CompletedAt, &inv.DurationMs, &inv.BilledTimeMs,
+			&inv.MemoryUsedMB, &inv.RetryCount, &inv.CreatedAt, &inv.QueueWaitMs, &progress, &costTags, &pipe, &callChain, &inv.Version,
+			&inv.WorkflowExecutionID, &inv.WorkflowState,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		if vmID.Valid {
+			inv.VMID = vmID.String
+		}
+		if input != nil {
+			inv.Input = input
+		}
+		if output != nil {
+			inv.Output = output
+		}
+		if errStr.Valid {
+			inv.Error = errStr.String
+		}
+		if progress != nil {
+			json.Unmarshal(progress, &inv.Progress)
+		}
+		if costTags != nil {
+			json.Unmarshal(costTags, &inv.CostTags)
+		}
+		if pipe != nil {
+			json.Unmarshal(pipe, &inv.Pipe)
+		}
+		if callChain != nil {
+			json.Unmarshal(callChain, &inv.CallChain)
+		}
+		invocations = append(invocations, inv)
+	}
+	return invocations, total, nil
+}
+
+// ListInvocationsByWorkflowExecution 查询工作流执行发起的所有函数调用记录，按创建时间正序排列。
+//
+// 参数:
+//   - executionID: 工作流执行 ID
+//
+// 返回值:
+//   - []*domain.Invocation: 调用记录列表（包含重试产生的每一次调用）
+//   - error: 查询失败时返回错误信息
+func (s *PostgresStore) ListInvocationsByWorkflowExecution(executionID string) ([]*domain.Invocation, error) {
+	// SQL: 按工作流执行 ID 查询调用记录
+	query := `
+		SELECT id, function_id, function_name, trigger_type, status, input, output, error,
+		       cold_start, vm_id, started_at, completed_at, duration_ms, billed_time_ms,
+		       memory_used_mb, retry_count, created_at, COALESCE(queue_wait_ms, 0), progress, cost_tags, pipe, call_chain, COALESCE(version, 0),
+		       COALESCE(workflow_execution_id, ''), COALESCE(workflow_state, '')
+		FROM invocations WHERE workflow_execution_id = $1 ORDER BY created_at ASC
+	`
+	rows, err := s.db.Query(query, executionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invocations := []*domain.Invocation{}
+	for rows.Next() {
+		inv := &domain.Invocation{}
+		var vmID sql.NullString
+		var input, output, progress, costTags, pipe, callChain []byte
+		var errStr sql.NullString
+		err := rows.Scan(
+			&inv.ID, &inv.FunctionID, &inv.FunctionName, &inv.TriggerType, &inv.Status,
+			&input, &output, &errStr, &inv.ColdStart, &vmID,
+			&inv.StartedAt, &inv.CompletedAt, &inv.DurationMs, &inv.BilledTimeMs,
+			&inv.MemoryUsedMB, &inv.RetryCount, &inv.CreatedAt, &inv.QueueWaitMs, &progress, &costTags, &pipe, &callChain, &inv.Version,
+			&inv.WorkflowExecutionID, &inv.WorkflowState,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if vmID.Valid {
+			inv.VMID = vmID.String
+		}
+		if input != nil {
+			inv.Input = input
+		}
+		if output != nil {
+			inv.Output = output
+		}
+		if errStr.Valid {
+			inv.Error = errStr.String
+		}
+		if progress != nil {
+			json.Unmarshal(progress, &inv.Progress)
+		}
+		if costTags != nil {
+			json.Unmarshal(costTags, &inv.CostTags)
+		}
+		if pipe != nil {
+			json.Unmarshal(pipe, &inv.Pipe)
+		}
+		if callChain != nil {
+			json.Unmarshal(callChain, &inv.CallChain)
+		}
+		invocations = append(invocations, inv)
+	}
+	return invocations, rows.Err()
+}
+
+// UpdateInvocation 更新调用记录。
+// 通常在调用完成后调用，更新输出结果、执行时间等信息。
+//
+// 参数:
+//   - inv: 包含更新数据的调用记录对象
+//
+// 返回值:
+//   - error: 记录不存在时返回 ErrInvocationNotFound，其他错误返回相应信息
+func (s *PostgresStore) UpdateInvocation(inv *domain.Invocation) error {
+	// JSONB 字段需要特别处理：如果传入的是“typed nil”（例如 json.RawMessage(nil)），
+	// pq 会将其当作空字符串而不是 NULL，导致 JSON 解析失败。
+	var output any
+	if len(inv.Output) == 0 {
 		output = nil
 	} else {
 		output = inv.Output
 	}
 
+	var meta any
+	if len(inv.Meta) != 0 {
+		meta = inv.Meta
+	}
+
 	// SQL: 更新调用记录的执行结果相关字段
 	query := `
 		UPDATE invocations SET
 			status = $2, output = $3, error = $4, cold_start = $5, vm_id = $6,
 			started_at = $7, completed_at = $8, duration_ms = $9, billed_time_ms = $10,
-			memory_used_mb = $11, retry_count = $12
+			memory_used_mb = $11, retry_count = $12, queue_wait_ms = $13, progress = COALESCE($14, progress), meta = $15
 		WHERE id = $1
 	`
 	result, err := s.db.Exec(query,
 		inv.ID, inv.Status, output, inv.Error, inv.ColdStart, inv.VMID,
 		inv.StartedAt, inv.CompletedAt, inv.DurationMs, inv.BilledTimeMs,
-		inv.MemoryUsedMB, inv.RetryCount,
+		inv.MemoryUsedMB, inv.RetryCount, inv.QueueWaitMs, progressJSON(inv.Progress), meta,
 	)
 	if err != nil {
 		return err
@@ -1325,6 +2130,39 @@ func (s *PostgresStore) UpdateInvocation(inv *domain.Invocation) error {
 	return nil
 }
 
+// UpdateInvocationProgress 更新调用记录上的最新执行进度。
+// 只更新 progress 字段，不影响调用的状态和结果，可在执行过程中频繁调用。
+//
+// 参数:
+//   - id: 调用记录唯一标识符
+//   - progress: 最新进度
+//
+// 返回值:
+//   - error: 记录不存在时返回 ErrInvocationNotFound，其他错误返回相应信息
+func (s *PostgresStore) UpdateInvocationProgress(id string, progress *domain.InvocationProgress) error {
+	result, err := s.db.Exec(`UPDATE invocations SET progress = $2 WHERE id = $1`, id, progressJSON(progress))
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrInvocationNotFound
+	}
+	return nil
+}
+
+// progressJSON 将调用进度序列化为 JSONB 参数，未上报进度时返回 nil 以写入 NULL。
+func progressJSON(progress *domain.InvocationProgress) interface{} {
+	if progress == nil {
+		return nil
+	}
+	data, _ := json.Marshal(progress)
+	return data
+}
+
 // ==================== 健康检查和统计方法 ====================
 
 // Ping 检查数据库连接是否正常。
@@ -1385,16 +2223,37 @@ func (s *PostgresStore) CountInvocations() (int, error) {
 //   - keyHash: 密钥的哈希值
 //   - userID: 关联的用户 ID
 //   - role: 角色权限（如 user, admin）
+//   - tagSelector: 标签选择器（可为空，表示不限制可访问的函数）
 //
 // 返回值:
 //   - error: 创建失败时返回错误信息（如哈希值重复）
-func (s *PostgresStore) CreateAPIKey(id, name, keyHash, userID, role string) error {
+func (s *PostgresStore) CreateAPIKey(id, name, keyHash, userID, role string, tagSelector []string) error {
+	if tagSelector == nil {
+		tagSelector = []string{}
+	}
 	// SQL: 插入 API 密钥记录
-	query := `INSERT INTO api_keys (id, name, key_hash, user_id, role) VALUES ($1, $2, $3, $4, $5)`
-	_, err := s.db.Exec(query, id, name, keyHash, userID, role)
+	query := `INSERT INTO api_keys (id, name, key_hash, user_id, role, tag_selector) VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := s.db.Exec(query, id, name, keyHash, userID, role, pq.Array(tagSelector))
 	return err
 }
 
+// GetAPIKeyTagSelector 获取 API 密钥的标签选择器。
+//
+// 参数:
+//   - id: 密钥记录唯一标识符
+//
+// 返回值:
+//   - []string: 标签选择器，为空表示不限制
+//   - error: 密钥不存在或查询失败时返回错误信息
+func (s *PostgresStore) GetAPIKeyTagSelector(id string) ([]string, error) {
+	var selector []string
+	err := s.db.QueryRow(`SELECT COALESCE(tag_selector, '{}') FROM api_keys WHERE id = $1`, id).Scan(pq.Array(&selector))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("api key not found")
+	}
+	return selector, err
+}
+
 // GetAPIKeyByHash 根据密钥哈希值获取 API 密钥信息。
 // 同时验证密钥是否过期。
 //
@@ -1439,6 +2298,8 @@ type APIKeyInfo struct {
 	Role      string     `json:"role"`
 	CreatedAt time.Time  `json:"created_at"`
 	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// TagSelector 标签选择器，非空时该密钥只能访问带有全部这些标签的函数
+	TagSelector []string `json:"tag_selector,omitempty"`
 }
 
 // ListAPIKeysByUser 获取指定用户的所有 API 密钥列表。
@@ -1450,7 +2311,7 @@ type APIKeyInfo struct {
 //   - []APIKeyInfo: API 密钥信息列表
 //   - error: 查询失败时返回错误信息
 func (s *PostgresStore) ListAPIKeysByUser(userID string) ([]APIKeyInfo, error) {
-	query := `SELECT id, name, user_id, role, created_at, expires_at FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`
+	query := `SELECT id, name, user_id, role, created_at, expires_at, COALESCE(tag_selector, '{}') FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`
 	rows, err := s.db.Query(query, userID)
 	if err != nil {
 		return nil, err
@@ -1460,7 +2321,7 @@ func (s *PostgresStore) ListAPIKeysByUser(userID string) ([]APIKeyInfo, error) {
 	var keys []APIKeyInfo
 	for rows.Next() {
 		var key APIKeyInfo
-		if err := rows.Scan(&key.ID, &key.Name, &key.UserID, &key.Role, &key.CreatedAt, &key.ExpiresAt); err != nil {
+		if err := rows.Scan(&key.ID, &key.Name, &key.UserID, &key.Role, &key.CreatedAt, &key.ExpiresAt, pq.Array(&key.TagSelector)); err != nil {
 			return nil, err
 		}
 		keys = append(keys, key)
@@ -1525,7 +2386,7 @@ func (s *PostgresStore) GetDashboardStats(periodHours int) (*DashboardStats, err
 			COALESCE(AVG(duration_ms), 0) as avg_latency,
 			COALESCE(PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY duration_ms), 0) as p99_latency
 		FROM invocations
-		WHERE created_at >= NOW() - INTERVAL '1 hour' * $1
+		WHERE created_at >= NOW() - INTERVAL '1 hour' * $1 AND trigger_type <> 'shadow'
 	`
 	err := s.db.QueryRow(query, periodHours).Scan(
 		&stats.TotalInvocations,
@@ -1565,7 +2426,7 @@ func (s *PostgresStore) GetInvocationTrends(periodHours int, granularityHours in
 			COUNT(*) FILTER (WHERE status = 'failed' OR status = 'timeout') as errors,
 			COALESCE(AVG(duration_ms), 0) as avg_latency
 		FROM invocations
-		WHERE created_at >= NOW() - INTERVAL '1 hour' * $1
+		WHERE created_at >= NOW() - INTERVAL '1 hour' * $1 AND trigger_type <> 'shadow'
 		GROUP BY date_trunc('hour', created_at)
 		ORDER BY hour ASC
 	`
@@ -1614,7 +2475,7 @@ func (s *PostgresStore) GetTopFunctions(periodHours int, limit int) ([]TopFuncti
 	var totalInvocations int64
 	s.db.QueryRow(`
 		SELECT COUNT(*) FROM invocations
-		WHERE created_at >= NOW() - INTERVAL '1 hour' * $1
+		WHERE created_at >= NOW() - INTERVAL '1 hour' * $1 AND trigger_type <> 'shadow'
 	`, periodHours).Scan(&totalInvocations)
 
 	query := `
@@ -1623,7 +2484,7 @@ func (s *PostgresStore) GetTopFunctions(periodHours int, limit int) ([]TopFuncti
 			function_name,
 			COUNT(*) as invocations
 		FROM invocations
-		WHERE created_at >= NOW() - INTERVAL '1 hour' * $1
+		WHERE created_at >= NOW() - INTERVAL '1 hour' * $1 AND trigger_type <> 'shadow'
 		GROUP BY function_id, function_name
 		ORDER BY invocations DESC
 		LIMIT $2
@@ -1649,6 +2510,197 @@ func (s *PostgresStore) GetTopFunctions(periodHours int, limit int) ([]TopFuncti
 	return tops, nil
 }
 
+// GetUsageReport 汇总指定时间段内的调用次数和计费时长。
+// 按标签分组时，带多个成本标签的调用会计入每个标签，没有标签的调用单独汇总。
+//
+// 参数:
+//   - periodHours: 统计最近多少小时
+//   - groupBy: 分组方式，domain.UsageGroupByTag 或 domain.UsageGroupByFunction
+//   - tagKey: 按标签分组时只统计该标签键，为空表示所有标签
+//
+// 返回值:
+//   - *domain.UsageReport: 用量汇总（Period 由调用方填充）
+//   - error: 查询失败时返回错误信息
+func (s *PostgresStore) GetUsageReport(periodHours int, groupBy, tagKey string) (*domain.UsageReport, error) {
+	report := &domain.UsageReport{
+		GroupBy: groupBy,
+		Since:   time.Now().Add(-time.Duration(periodHours) * time.Hour),
+		Groups:  []domain.UsageGroup{},
+	}
+
+	err := s.db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(billed_time_ms), 0),
+		       COUNT(*) FILTER (WHERE cost_tags IS NULL),
+		       COALESCE(SUM(billed_time_ms) FILTER (WHERE cost_tags IS NULL), 0)
+		FROM invocations
+		WHERE created_at >= $1 AND trigger_type <> 'shadow'
+	`, report.Since).Scan(&report.TotalInvocations, &report.TotalBilledTimeMs, &report.UntaggedInvocations, &report.UntaggedBilledTimeMs)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows *sql.Rows
+	switch groupBy {
+	case domain.UsageGroupByFunction:
+		rows, err = s.db.Query(`
+			SELECT function_id, function_name, COUNT(*), COALESCE(SUM(billed_time_ms), 0) AS billed
+			FROM invocations
+			WHERE created_at >= $1 AND trigger_type <> 'shadow'
+			GROUP BY function_id, function_name
+			ORDER BY billed DESC, function_name
+		`, report.Since)
+	default:
+		rows, err = s.db.Query(`
+			SELECT t.key, t.value, COUNT(*), COALESCE(SUM(i.billed_time_ms), 0) AS billed
+			FROM invocations i, jsonb_each_text(i.cost_tags) t
+			WHERE i.created_at >= $1 AND i.trigger_type <> 'shadow' AND i.cost_tags IS NOT NULL AND ($2 = '' OR t.key = $2)
+			GROUP BY t.key, t.value
+			ORDER BY billed DESC, t.key, t.value
+		`, report.Since, tagKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var g domain.UsageGroup
+		if groupBy == domain.UsageGroupByFunction {
+			err = rows.Scan(&g.FunctionID, &g.FunctionName, &g.Invocations, &g.BilledTimeMs)
+		} else {
+			err = rows.Scan(&g.TagKey, &g.TagValue, &g.Invocations, &g.BilledTimeMs)
+		}
+		if err != nil {
+			return nil, err
+		}
+		report.Groups = append(report.Groups, g)
+	}
+	return report, rows.Err()
+}
+
+// GetBilledTimeStats 统计函数在指定时间之后已完成调用的数量和平均计费时长。
+// 仅统计实际执行过的调用（success/failed/timeout），用于成本预估。
+//
+// 参数:
+//   - functionID: 函数 ID
+//   - since: 统计起点
+//
+// 返回值:
+//   - int64: 已完成调用数
+//   - float64: 平均计费时长（毫秒），没有调用时为 0
+//   - error: 查询失败时返回错误信息
+func (s *PostgresStore) GetBilledTimeStats(functionID string, since time.Time) (int64, float64, error) {
+	var count int64
+	var avg float64
+	err := s.db.QueryRow(`
+		SELECT COUNT(*), COALESCE(AVG(billed_time_ms), 0)
+		FROM invocations
+		WHERE function_id = $1 AND created_at >= $2 AND trigger_type <> 'shadow' AND status IN ('success', 'failed', 'timeout')
+	`, functionID, since).Scan(&count, &avg)
+	return count, avg, err
+}
+
+// GetFunctionBillingUsage 汇总函数在指定时间之后的调用次数、计费时长和平均执行时长。
+// 平均执行时长只统计已完成的调用（success/failed/timeout）。
+//
+// 参数:
+//   - functionID: 函数 ID
+//   - since: 统计起点
+//   - byDay: 是否同时按天（UTC）分组
+//
+// 返回值:
+//   - domain.BillingUsage: 整个时间段的用量（GBSeconds 和 Cost 由调用方计算）
+//   - []domain.BillingUsage: 按天的用量，只包含有调用的日期，byDay 为 false 时为 nil
+//   - error: 查询失败时返回错误信息
+func (s *PostgresStore) GetFunctionBillingUsage(functionID string, since time.Time, byDay bool) (domain.BillingUsage, []domain.BillingUsage, error) {
+	var total domain.BillingUsage
+	err := s.db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(billed_time_ms), 0),
+		       COALESCE(AVG(duration_ms) FILTER (WHERE status IN ('success', 'failed', 'timeout')), 0)
+		FROM invocations
+		WHERE function_id = $1 AND created_at >= $2 AND trigger_type <> 'shadow'
+	`, functionID, since).Scan(&total.Invocations, &total.BilledTimeMs, &total.AvgDurationMs)
+	if err != nil || !byDay {
+		return total, nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day,
+		       COUNT(*), COALESCE(SUM(billed_time_ms), 0),
+		       COALESCE(AVG(duration_ms) FILTER (WHERE status IN ('success', 'failed', 'timeout')), 0)
+		FROM invocations
+		WHERE function_id = $1 AND created_at >= $2 AND trigger_type <> 'shadow'
+		GROUP BY day
+		ORDER BY day
+	`, functionID, since)
+	if err != nil {
+		return total, nil, err
+	}
+	defer rows.Close()
+
+	var days []domain.BillingUsage
+	for rows.Next() {
+		var d domain.BillingUsage
+		if err := rows.Scan(&d.Date, &d.Invocations, &d.BilledTimeMs, &d.AvgDurationMs); err != nil {
+			return total, nil, err
+		}
+		days = append(days, d)
+	}
+	return total, days, rows.Err()
+}
+
+// GetVersionStats 按版本统计函数在指定时间之后已完成调用（success/failed/timeout）的
+// 调用数、错误率和执行时长分位数，用于对比两个版本（金丝雀分析）。
+//
+// 参数:
+//   - functionID: 函数 ID
+//   - versions: 需要统计的版本号
+//   - since: 统计起点
+//
+// 返回值:
+//   - map[int]*domain.VersionStats: 版本号到统计的映射，没有调用的版本不在映射中
+//   - error: 查询失败时返回错误信息
+func (s *PostgresStore) GetVersionStats(functionID string, versions []int, since time.Time) (map[int]*domain.VersionStats, error) {
+	ids := make([]int64, len(versions))
+	for i, v := range versions {
+		ids[i] = int64(v)
+	}
+	rows, err := s.db.Query(`
+		SELECT
+			version,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'success'),
+			COUNT(*) FILTER (WHERE status = 'failed'),
+			COUNT(*) FILTER (WHERE status = 'timeout'),
+			COALESCE(AVG(duration_ms), 0),
+			COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY duration_ms), 0),
+			COALESCE(PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY duration_ms), 0),
+			COALESCE(PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY duration_ms), 0)
+		FROM invocations
+		WHERE function_id = $1 AND version = ANY($2) AND created_at >= $3
+		  AND trigger_type <> 'shadow' AND status IN ('success', 'failed', 'timeout')
+		GROUP BY version
+	`, functionID, pq.Array(ids), since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make(map[int]*domain.VersionStats, len(versions))
+	for rows.Next() {
+		vs := &domain.VersionStats{}
+		if err := rows.Scan(&vs.Version, &vs.Invocations, &vs.SuccessCount, &vs.FailedCount, &vs.TimeoutCount,
+			&vs.AvgLatencyMs, &vs.P50LatencyMs, &vs.P95LatencyMs, &vs.P99LatencyMs); err != nil {
+			return nil, err
+		}
+		if vs.Invocations > 0 {
+			vs.ErrorRate = float64(vs.FailedCount+vs.TimeoutCount) / float64(vs.Invocations) * 100
+		}
+		stats[vs.Version] = vs
+	}
+	return stats, rows.Err()
+}
+
 // RecentInvocation 最近调用
 type RecentInvocation struct {
 	ID           string    `json:"id"`
@@ -1698,7 +2750,8 @@ func (s *PostgresStore) ListAllInvocations(status string, offset, limit int) ([]
 		listQuery = `
 			SELECT id, function_id, function_name, trigger_type, status, input, output, error,
 			       cold_start, vm_id, started_at, completed_at, duration_ms, billed_time_ms,
-			       memory_used_mb, retry_count, created_at
+			       memory_used_mb, retry_count, created_at, COALESCE(queue_wait_ms, 0), progress, cost_tags, pipe, call_chain, COALESCE(version, 0),
+			       COALESCE(workflow_execution_id, ''), COALESCE(workflow_state, '')
 			FROM invocations WHERE status = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3
 		`
 		listArgs = []interface{}{status, limit, offset}
@@ -1708,7 +2761,8 @@ func (s *PostgresStore) ListAllInvocations(status string, offset, limit int) ([]
 		listQuery = `
 			SELECT id, function_id, function_name, trigger_type, status, input, output, error,
 			       cold_start, vm_id, started_at, completed_at, duration_ms, billed_time_ms,
-			       memory_used_mb, retry_count, created_at
+			       memory_used_mb, retry_count, created_at, COALESCE(queue_wait_ms, 0), progress, cost_tags, pipe, call_chain, COALESCE(version, 0),
+			       COALESCE(workflow_execution_id, ''), COALESCE(workflow_state, '')
 			FROM invocations ORDER BY created_at DESC LIMIT $1 OFFSET $2
 		`
 		listArgs = []interface{}{limit, offset}
@@ -1726,42 +2780,165 @@ func (s *PostgresStore) ListAllInvocations(status string, offset, limit int) ([]
 		}
 	}
 
-	rows, err := s.db.Query(listQuery, listArgs...)
+	rows, err := s.db.Query(listQuery, listArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var invocations []*domain.Invocation
+	for rows.Next() {
+		inv := &domain.Invocation{}
+		var vmID sql.NullString
+		var input, output, progress, costTags, pipe, callChain []byte
+		var errStr sql.NullString
+		err := rows.Scan(
+			&inv.ID, &inv.FunctionID, &inv.FunctionName, &inv.TriggerType, &inv.Status,
+			&input, &output, &errStr, &inv.ColdStart, &vmID,
+			&inv.StartedAt, &inv.CompletedAt, &inv.DurationMs, &inv.BilledTimeMs,
+			&inv.MemoryUsedMB, &inv.RetryCount, &inv.CreatedAt, &inv.QueueWaitMs, &progress, &costTags, &pipe, &callChain, &inv.Version,
+			&inv.WorkflowExecutionID, &inv.WorkflowState,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		if vmID.Valid {
+			inv.VMID = vmID.String
+		}
+		if input != nil {
+			inv.Input = input
+		}
+		if output != nil {
+			inv.Output = output
+		}
+		if errStr.Valid {
+			inv.Error = errStr.String
+		}
+		if progress != nil {
+			json.Unmarshal(progress, &inv.Progress)
+		}
+		if costTags != nil {
+			json.Unmarshal(costTags, &inv.CostTags)
+		}
+		if pipe != nil {
+			json.Unmarshal(pipe, &inv.Pipe)
+		}
+		if callChain != nil {
+			json.Unmarshal(callChain, &inv.CallChain)
+		}
+		invocations = append(invocations, inv)
+	}
+	return invocations, total, nil
+}
+
+// InvocationPayloadSearchEnabled 判断是否开启了调用输入输出的子串搜索。
+func (s *PostgresStore) InvocationPayloadSearchEnabled() bool {
+	return s.payloadSearch
+}
+
+// SearchInvocations 按条件搜索调用记录。
+// 文本条件使用大小写不敏感的子串匹配，error 列和（开启载荷搜索时）input/output 列上的三元组索引可加速查询。
+//
+// 参数:
+//   - q: 搜索条件，调用方负责校验
+//   - offset: 跳过的记录数
+//   - limit: 返回的最大记录数
+//
+// 返回值:
+//   - []*domain.Invocation: 命中的调用记录，按创建时间倒序
+//   - int: 命中的总数
+//   - error: 未开启载荷搜索却指定了 PayloadContains 时返回 domain.ErrInvocationPayloadSearchDisabled
+func (s *PostgresStore) SearchInvocations(q *domain.InvocationSearchQuery, offset, limit int) ([]*domain.Invocation, int, error) {
+	if q.PayloadContains != "" && !s.payloadSearch {
+		return nil, 0, domain.ErrInvocationPayloadSearchDisabled
+	}
+
+	var conditions []string
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+	if q.FunctionID != "" {
+		add("function_id = $%d", q.FunctionID)
+	}
+	if len(q.FunctionTags) > 0 {
+		add("function_id IN (SELECT id FROM functions WHERE tags @> $%d)", pq.Array(q.FunctionTags))
+	}
+	if q.Status != "" {
+		add("status = $%d", string(q.Status))
+	}
+	if q.Since != nil {
+		add("created_at >= $%d", *q.Since)
+	}
+	if q.Until != nil {
+		add("created_at < $%d", *q.Until)
+	}
+	if q.ErrorContains != "" {
+		add("error ILIKE $%d", "%"+escapeLikePattern(q.ErrorContains)+"%")
+	}
+	if q.PayloadContains != "" {
+		args = append(args, "%"+escapeLikePattern(q.PayloadContains)+"%")
+		conditions = append(conditions, fmt.Sprintf("(input::text ILIKE $%d OR output::text ILIKE $%d)", len(args), len(args)))
+	}
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM invocations "+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	listQuery := fmt.Sprintf(`
+		SELECT id, function_id, function_name, trigger_type, status, input, output, error,
+		       cold_start, vm_id, started_at, completed_at, duration_ms, billed_time_ms,
+		       memory_used_mb, retry_count, created_at, COALESCE(queue_wait_ms, 0), progress, cost_tags, pipe, call_chain, COALESCE(version, 0),
+		       COALESCE(workflow_execution_id, ''), COALESCE(workflow_state, '')
+		FROM invocations %s ORDER BY created_at DESC LIMIT $%d OFFSET $%d
+	`, whereClause, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := s.db.Query(listQuery, args...)
 	if err != nil {
 		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var invocations []*domain.Invocation
+	invocations := make([]*domain.Invocation, 0, limit)
 	for rows.Next() {
 		inv := &domain.Invocation{}
-		var vmID sql.NullString
-		var input, output []byte
-		var errStr sql.NullString
-		err := rows.Scan(
+		var vmID, errStr sql.NullString
+		var input, output, progress, costTags, pipe, callChain []byte
+		if err := rows.Scan(
 			&inv.ID, &inv.FunctionID, &inv.FunctionName, &inv.TriggerType, &inv.Status,
 			&input, &output, &errStr, &inv.ColdStart, &vmID,
 			&inv.StartedAt, &inv.CompletedAt, &inv.DurationMs, &inv.BilledTimeMs,
-			&inv.MemoryUsedMB, &inv.RetryCount, &inv.CreatedAt,
-		)
-		if err != nil {
+			&inv.MemoryUsedMB, &inv.RetryCount, &inv.CreatedAt, &inv.QueueWaitMs, &progress, &costTags, &pipe, &callChain, &inv.Version,
+			&inv.WorkflowExecutionID, &inv.WorkflowState,
+		); err != nil {
 			return nil, 0, err
 		}
-		if vmID.Valid {
-			inv.VMID = vmID.String
+		inv.VMID = vmID.String
+		inv.Error = errStr.String
+		inv.Input = input
+		inv.Output = output
+		if progress != nil {
+			json.Unmarshal(progress, &inv.Progress)
 		}
-		if input != nil {
-			inv.Input = input
+		if costTags != nil {
+			json.Unmarshal(costTags, &inv.CostTags)
 		}
-		if output != nil {
-			inv.Output = output
+		if pipe != nil {
+			json.Unmarshal(pipe, &inv.Pipe)
 		}
-		if errStr.Valid {
-			inv.Error = errStr.String
+		if callChain != nil {
+			json.Unmarshal(callChain, &inv.CallChain)
 		}
 		invocations = append(invocations, inv)
 	}
-	return invocations, total, nil
+	return invocations, total, rows.Err()
 }
 
 // CreateLogEntry 写入一条日志记录到 logs 表。
@@ -1792,8 +2969,8 @@ func (s *PostgresStore) CreateLogEntry(ctx context.Context, entry *domain.LogEnt
 	}
 
 	query := `
-		INSERT INTO logs (ts, level, function_id, function_name, message, request_id, input, output, error, duration_ms)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO logs (ts, level, function_id, function_name, message, request_id, input, output, error, duration_ms, source)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 	_, err := s.db.ExecContext(
 		ctx,
@@ -1808,6 +2985,7 @@ func (s *PostgresStore) CreateLogEntry(ctx context.Context, entry *domain.LogEnt
 		output,
 		entry.Error,
 		entry.DurationMs,
+		entry.Source,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create log entry: %w", err)
@@ -1821,6 +2999,7 @@ type ListLogEntriesOptions struct {
 	FunctionName string
 	RequestID    string
 	Level        string
+	Source       string
 	Before       *time.Time
 	After        *time.Time
 	Limit        int
@@ -1860,6 +3039,9 @@ func (s *PostgresStore) ListLogEntries(ctx context.Context, opts ListLogEntriesO
 	if opts.Level != "" {
 		where = append(where, "level = "+arg(opts.Level))
 	}
+	if opts.Source != "" {
+		where = append(where, "source = "+arg(opts.Source))
+	}
 	if opts.Before != nil {
 		where = append(where, "ts < "+arg(*opts.Before))
 	}
@@ -1868,7 +3050,7 @@ func (s *PostgresStore) ListLogEntries(ctx context.Context, opts ListLogEntriesO
 	}
 
 	query := `
-		SELECT ts, level, function_id, function_name, message, request_id, input, output, error, duration_ms
+		SELECT ts, level, function_id, function_name, message, request_id, input, output, error, duration_ms, COALESCE(source, '')
 		FROM logs
 	`
 	if len(where) > 0 {
@@ -1900,6 +3082,7 @@ func (s *PostgresStore) ListLogEntries(ctx context.Context, opts ListLogEntriesO
 			&output,
 			&errStr,
 			&duration,
+			&entry.Source,
 		); err != nil {
 			return nil, err
 		}
@@ -1942,7 +3125,7 @@ func (s *PostgresStore) GetAllFunctionsBasicStats(periodHours int) (map[string]*
 			COUNT(*) FILTER (WHERE status = 'failed' OR status = 'timeout') as errors,
 			COALESCE(AVG(duration_ms), 0) as avg_latency
 		FROM invocations
-		WHERE created_at >= NOW() - INTERVAL '1 hour' * $1
+		WHERE created_at >= NOW() - INTERVAL '1 hour' * $1 AND trigger_type <> 'shadow'
 		GROUP BY function_id
 	`
 	rows, err := s.db.Query(query, periodHours)
@@ -1987,54 +3170,49 @@ type FunctionStats struct {
 	TimeoutCount     int64   `json:"timeout_count"`
 }
 
-// GetFunctionStats 获取单个函数的统计数据
+// GetFunctionStats 获取单个函数的统计数据。
+// 已结束的完整小时读取 function_latency_rollups 中的预汇总结果，
+// 统计周期开头不足一小时的部分和尚未汇总的最近数据按调用记录实时计算；
+// 跨多个时间段时分位数按调用数加权合并，为近似值。
 func (s *PostgresStore) GetFunctionStats(functionID string, periodHours int) (*FunctionStats, error) {
-	stats := &FunctionStats{}
+	now := time.Now()
+	from := now.Add(-time.Duration(periodHours) * time.Hour)
 
-	query := `
-		SELECT
-			COUNT(*) as total,
-			COUNT(*) FILTER (WHERE status = 'success' OR status = 'completed') as success,
-			COUNT(*) FILTER (WHERE status = 'failed') as failed,
-			COUNT(*) FILTER (WHERE status = 'timeout') as timeout,
-			COUNT(*) FILTER (WHERE cold_start = true) as cold_starts,
-			COALESCE(AVG(duration_ms), 0) as avg_latency,
-			COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY duration_ms), 0) as p50_latency,
-			COALESCE(PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY duration_ms), 0) as p95_latency,
-			COALESCE(PERCENTILE_CONT(0.99) WITHIN GROUP (ORDER BY duration_ms), 0) as p99_latency,
-			COALESCE(MIN(duration_ms), 0) as min_latency,
-			COALESCE(MAX(duration_ms), 0) as max_latency,
-			COALESCE(SUM(duration_ms), 0) as total_duration,
-			COALESCE(AVG(duration_ms) FILTER (WHERE cold_start = true), 0) as avg_cold_start
-		FROM invocations
-		WHERE function_id = $1 AND created_at >= NOW() - INTERVAL '1 hour' * $2
-	`
-	err := s.db.QueryRow(query, functionID, periodHours).Scan(
-		&stats.TotalInvocations,
-		&stats.SuccessCount,
-		&stats.FailedCount,
-		&stats.TimeoutCount,
-		&stats.ColdStartCount,
-		&stats.AvgLatencyMs,
-		&stats.P50LatencyMs,
-		&stats.P95LatencyMs,
-		&stats.P99LatencyMs,
-		&stats.MinLatencyMs,
-		&stats.MaxLatencyMs,
-		&stats.TotalDurationMs,
-		&stats.AvgColdStartMs,
-	)
-	if err != nil {
-		return stats, nil
+	// 已汇总的范围为 [rolledFrom, rolledUntil)，汇总任务未运行时全部实时计算
+	rolledFrom := from.Truncate(latencyRollupBucket)
+	if rolledFrom.Before(from) {
+		rolledFrom = rolledFrom.Add(latencyRollupBucket)
 	}
-
-	if stats.TotalInvocations > 0 {
-		stats.SuccessRate = float64(stats.SuccessCount) / float64(stats.TotalInvocations) * 100
-		stats.ErrorRate = float64(stats.FailedCount+stats.TimeoutCount) / float64(stats.TotalInvocations) * 100
-		stats.ColdStartRate = float64(stats.ColdStartCount) / float64(stats.TotalInvocations) * 100
+	rolledUntil := rolledFrom
+	if latest, err := s.latestRollupBucket(); err == nil && !latest.IsZero() {
+		if end := latest.Add(latencyRollupBucket); end.After(rolledUntil) {
+			rolledUntil = end
+		}
+	}
+	if rolledUntil.After(now) {
+		rolledUntil = now
 	}
 
-	return stats, nil
+	var total latencyAggregate
+	if rolledUntil.After(rolledFrom) {
+		head, err := s.aggregateInvocations(functionID, from, rolledFrom)
+		if err != nil {
+			return &FunctionStats{}, nil
+		}
+		rolled, err := s.aggregateRollups(functionID, rolledFrom, rolledUntil)
+		if err != nil {
+			return &FunctionStats{}, nil
+		}
+		total.merge(head)
+		total.merge(rolled)
+		from = rolledUntil
+	}
+	recent, err := s.aggregateInvocations(functionID, from, now)
+	if err != nil {
+		return &FunctionStats{}, nil
+	}
+	total.merge(recent)
+	return total.stats(), nil
 }
 
 // GetFunctionTrends 获取单个函数的趋势数据
@@ -2046,7 +3224,7 @@ func (s *PostgresStore) GetFunctionTrends(functionID string, periodHours int) ([
 			COUNT(*) FILTER (WHERE status = 'failed' OR status = 'timeout') as errors,
 			COALESCE(AVG(duration_ms), 0) as avg_latency
 		FROM invocations
-		WHERE function_id = $1 AND created_at >= NOW() - INTERVAL '1 hour' * $2
+		WHERE function_id = $1 AND created_at >= NOW() - INTERVAL '1 hour' * $2 AND trigger_type <> 'shadow'
 		GROUP BY date_trunc('hour', created_at)
 		ORDER BY hour ASC
 	`
@@ -2104,7 +3282,7 @@ func (s *PostgresStore) GetFunctionLatencyDistribution(functionID string, period
 			END as bucket,
 			COUNT(*) as count
 		FROM invocations
-		WHERE function_id = $1 AND created_at >= NOW() - INTERVAL '1 hour' * $2
+		WHERE function_id = $1 AND created_at >= NOW() - INTERVAL '1 hour' * $2 AND trigger_type <> 'shadow'
 		GROUP BY bucket
 		ORDER BY MIN(duration_ms)
 	`
@@ -2463,22 +3641,22 @@ func (s *PostgresStore) CreateLayerVersion(lv *domain.LayerVersion, content []by
 	lv.CreatedAt = time.Now()
 
 	query := `
-		INSERT INTO layer_versions (id, layer_id, version, content, content_hash, size_bytes, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO layer_versions (id, layer_id, version, content, content_hash, size_bytes, unpacked_size_bytes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
-	_, err := s.db.Exec(query, lv.ID, lv.LayerID, lv.Version, content, lv.ContentHash, lv.SizeBytes, lv.CreatedAt)
+	_, err := s.db.Exec(query, lv.ID, lv.LayerID, lv.Version, content, lv.ContentHash, lv.SizeBytes, lv.UnpackedSizeBytes, lv.CreatedAt)
 	return err
 }
 
 // GetLayerVersion 获取层版本。
 func (s *PostgresStore) GetLayerVersion(layerID string, version int) (*domain.LayerVersion, error) {
 	query := `
-		SELECT id, layer_id, version, content_hash, size_bytes, created_at
+		SELECT id, layer_id, version, content_hash, size_bytes, COALESCE(unpacked_size_bytes, 0), created_at
 		FROM layer_versions
 		WHERE layer_id = $1 AND version = $2
 	`
 	lv := &domain.LayerVersion{}
-	err := s.db.QueryRow(query, layerID, version).Scan(&lv.ID, &lv.LayerID, &lv.Version, &lv.ContentHash, &lv.SizeBytes, &lv.CreatedAt)
+	err := s.db.QueryRow(query, layerID, version).Scan(&lv.ID, &lv.LayerID, &lv.Version, &lv.ContentHash, &lv.SizeBytes, &lv.UnpackedSizeBytes, &lv.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, errors.New("layer version not found")
 	}
@@ -2498,7 +3676,7 @@ func (s *PostgresStore) GetLayerVersionContent(layerID string, version int) ([]b
 // ListLayerVersions 获取层的所有版本。
 func (s *PostgresStore) ListLayerVersions(layerID string) ([]*domain.LayerVersion, error) {
 	query := `
-		SELECT id, layer_id, version, content_hash, size_bytes, created_at
+		SELECT id, layer_id, version, content_hash, size_bytes, COALESCE(unpacked_size_bytes, 0), created_at
 		FROM layer_versions
 		WHERE layer_id = $1
 		ORDER BY version DESC
@@ -2512,7 +3690,7 @@ func (s *PostgresStore) ListLayerVersions(layerID string) ([]*domain.LayerVersio
 	var versions []*domain.LayerVersion
 	for rows.Next() {
 		lv := &domain.LayerVersion{}
-		if err := rows.Scan(&lv.ID, &lv.LayerID, &lv.Version, &lv.ContentHash, &lv.SizeBytes, &lv.CreatedAt); err != nil {
+		if err := rows.Scan(&lv.ID, &lv.LayerID, &lv.Version, &lv.ContentHash, &lv.SizeBytes, &lv.UnpackedSizeBytes, &lv.CreatedAt); err != nil {
 			return nil, err
 		}
 		versions = append(versions, lv)
@@ -3066,6 +4244,74 @@ func (s *PostgresStore) ListDLQMessages(functionID, status string, offset, limit
 	return messages, total, nil
 }
 
+// ListDLQMessagesForRetry 按批量重试条件查询死信消息，按进入队列的时间正序返回（最早的先重试）。
+//
+// 参数:
+//   - req: 已校验的批量重试条件，FunctionID 须为函数 ID，最多返回 req.Limit 条
+//
+// 返回值:
+//   - []*domain.DeadLetterMessage: 本次要重试的消息
+//   - int: 符合条件的消息总数
+//   - error: 查询失败时返回错误
+func (s *PostgresStore) ListDLQMessagesForRetry(req *domain.DLQBulkRetryRequest) ([]*domain.DeadLetterMessage, int, error) {
+	conditions := []string{"d.status = $1"}
+	args := []interface{}{req.Status}
+
+	if req.FunctionID != "" {
+		args = append(args, req.FunctionID)
+		conditions = append(conditions, fmt.Sprintf("d.function_id = $%d", len(args)))
+	}
+	if req.Since != nil {
+		args = append(args, *req.Since)
+		conditions = append(conditions, fmt.Sprintf("d.created_at >= $%d", len(args)))
+	}
+	if req.Until != nil {
+		args = append(args, *req.Until)
+		conditions = append(conditions, fmt.Sprintf("d.created_at < $%d", len(args)))
+	}
+	whereClause := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM dead_letter_queue d WHERE "+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, req.Limit)
+	rows, err := s.db.Query(fmt.Sprintf(`
+		SELECT d.id, d.function_id, f.name, d.original_request_id, d.payload, d.error, d.retry_count, d.status, d.created_at, d.last_retry_at, d.resolved_at
+		FROM dead_letter_queue d
+		LEFT JOIN functions f ON d.function_id = f.id
+		WHERE %s
+		ORDER BY d.created_at ASC
+		LIMIT $%d
+	`, whereClause, len(args)), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var messages []*domain.DeadLetterMessage
+	for rows.Next() {
+		msg := &domain.DeadLetterMessage{}
+		var functionName sql.NullString
+		var lastRetryAt, resolvedAt sql.NullTime
+
+		if err := rows.Scan(&msg.ID, &msg.FunctionID, &functionName, &msg.OriginalRequestID, &msg.Payload, &msg.Error,
+			&msg.RetryCount, &msg.Status, &msg.CreatedAt, &lastRetryAt, &resolvedAt); err != nil {
+			return nil, 0, err
+		}
+		msg.FunctionName = functionName.String
+		if lastRetryAt.Valid {
+			msg.LastRetryAt = &lastRetryAt.Time
+		}
+		if resolvedAt.Valid {
+			msg.ResolvedAt = &resolvedAt.Time
+		}
+		messages = append(messages, msg)
+	}
+	return messages, total, rows.Err()
+}
+
 // UpdateDLQMessage 更新死信消息。
 func (s *PostgresStore) UpdateDLQMessage(msg *domain.DeadLetterMessage) error {
 	query := `
@@ -3207,6 +4453,51 @@ func (s *PostgresStore) CleanupOldTasks(retentionDays int) (int64, error) {
 	return result.RowsAffected()
 }
 
+// CompactFunctionVersions 按保留策略删除函数的旧版本快照。
+// 每个函数保留版本号最大的 N 个版本（函数级 version_retention 优先，0 时使用全局值，-1 表示全部保留），
+// 被别名路由、影子流量引用的版本以及函数当前版本始终保留。
+//
+// 参数:
+//   - defaultKeep: 全局保留数，<= 0 时未单独配置的函数不压缩
+//
+// 返回值:
+//   - int64: 删除的版本数
+//   - error: 执行失败时返回错误信息
+func (s *PostgresStore) CompactFunctionVersions(defaultKeep int) (int64, error) {
+	query := `
+		WITH keep AS (
+			SELECT id,
+			       CASE WHEN version_retention > 0 THEN version_retention
+			            WHEN version_retention < 0 THEN 0
+			            ELSE $1 END AS n
+			FROM functions
+		),
+		ranked AS (
+			SELECT id, function_id, version,
+			       ROW_NUMBER() OVER (PARTITION BY function_id ORDER BY version DESC) AS rn
+			FROM function_versions
+		),
+		referenced AS (
+			SELECT a.function_id, (w->>'version')::int AS version
+			FROM function_aliases a, jsonb_array_elements(COALESCE(a.routing_config->'weights', '[]'::jsonb)) w
+			UNION
+			SELECT COALESCE(NULLIF(target_function_id, ''), function_id), target_version
+			FROM function_shadow_configs WHERE target_version > 0
+			UNION
+			SELECT id, version FROM functions
+		)
+		DELETE FROM function_versions v
+		USING ranked r, keep k
+		WHERE v.id = r.id AND k.id = v.function_id AND k.n > 0 AND r.rn > k.n
+		  AND NOT EXISTS (SELECT 1 FROM referenced x WHERE x.function_id = v.function_id AND x.version = v.version)
+	`
+	result, err := s.db.Exec(query, defaultKeep)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 // RetentionStats 保留策略统计信息
 type RetentionStats struct {
 	TotalInvocations    int64 `json:"total_invocations"`
@@ -4430,3 +5721,265 @@ func (s *PostgresStore) DeleteBreakpoint(executionID, beforeState string) error
 	}
 	return nil
 }
+
+// ==================== 影子流量管理方法 ====================
+
+// GetShadowConfig 获取函数的影子流量配置。
+//
+// 返回值:
+//   - *domain.ShadowConfig: 影子流量配置
+//   - error: 未配置时返回 domain.ErrShadowConfigNotFound
+func (s *PostgresStore) GetShadowConfig(functionID string) (*domain.ShadowConfig, error) {
+	query := `
+		SELECT function_id, COALESCE(target_function_id, ''), target_version, percentage, enabled, timeout_ms, created_at, updated_at
+		FROM function_shadow_configs
+		WHERE function_id = $1
+	`
+	c := &domain.ShadowConfig{}
+	err := s.db.QueryRow(query, functionID).Scan(
+		&c.FunctionID, &c.TargetFunctionID, &c.TargetVersion, &c.Percentage, &c.Enabled, &c.TimeoutMs, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrShadowConfigNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SaveShadowConfig 创建或更新函数的影子流量配置。
+func (s *PostgresStore) SaveShadowConfig(c *domain.ShadowConfig) error {
+	now := time.Now()
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = now
+	}
+	c.UpdatedAt = now
+
+	query := `
+		INSERT INTO function_shadow_configs (function_id, target_function_id, target_version, percentage, enabled, timeout_ms, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (function_id) DO UPDATE SET
+			target_function_id = EXCLUDED.target_function_id,
+			target_version = EXCLUDED.target_version,
+			percentage = EXCLUDED.percentage,
+			enabled = EXCLUDED.enabled,
+			timeout_ms = EXCLUDED.timeout_ms,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := s.db.Exec(query,
+		c.FunctionID, sql.NullString{String: c.TargetFunctionID, Valid: c.TargetFunctionID != ""},
+		c.TargetVersion, c.Percentage, c.Enabled, c.TimeoutMs, c.CreatedAt, c.UpdatedAt,
+	)
+	return err
+}
+
+// DeleteShadowConfig 删除函数的影子流量配置。
+func (s *PostgresStore) DeleteShadowConfig(functionID string) error {
+	result, err := s.db.Exec("DELETE FROM function_shadow_configs WHERE function_id = $1", functionID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrShadowConfigNotFound
+	}
+	return nil
+}
+
+// CreateShadowResult 保存一次影子调用的对比结果。
+func (s *PostgresStore) CreateShadowResult(res *domain.ShadowResult) error {
+	if res.ID == "" {
+		res.ID = uuid.New().String()
+	}
+	if res.CreatedAt.IsZero() {
+		res.CreatedAt = time.Now()
+	}
+
+	// JSONB 字段为空时写入 NULL，避免 typed nil 被当作空字符串
+	var primaryOutput, shadowOutput any
+	if len(res.PrimaryOutput) > 0 {
+		primaryOutput = res.PrimaryOutput
+	}
+	if len(res.ShadowOutput) > 0 {
+		shadowOutput = res.ShadowOutput
+	}
+
+	query := `
+		INSERT INTO shadow_results (
+			id, function_id, primary_invocation_id, shadow_invocation_id, target_function_id, target_version,
+			primary_status_code, shadow_status_code, primary_duration_ms, shadow_duration_ms,
+			primary_output, shadow_output, shadow_error, match, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`
+	_, err := s.db.Exec(query,
+		res.ID, res.FunctionID, res.PrimaryInvocationID,
+		sql.NullString{String: res.ShadowInvocationID, Valid: res.ShadowInvocationID != ""},
+		res.TargetFunctionID, res.TargetVersion,
+		res.PrimaryStatusCode, res.ShadowStatusCode, res.PrimaryDurationMs, res.ShadowDurationMs,
+		primaryOutput, shadowOutput, res.ShadowError, res.Match, res.CreatedAt,
+	)
+	return err
+}
+
+// ListShadowResults 分页获取函数的影子调用对比结果，按时间倒序排列。
+//
+// 参数:
+//   - functionID: 主函数 ID
+//   - mismatchOnly: 为 true 时只返回与主调用不一致的结果
+//   - offset, limit: 分页参数
+//
+// 返回值:
+//   - []*domain.ShadowResult: 结果列表
+//   - int: 符合条件的总数
+//   - error: 查询失败时返回错误
+func (s *PostgresStore) ListShadowResults(functionID string, mismatchOnly bool, offset, limit int) ([]*domain.ShadowResult, int, error) {
+	where := "WHERE function_id = $1"
+	if mismatchOnly {
+		where += " AND match = FALSE"
+	}
+
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM shadow_results "+where, functionID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, function_id, primary_invocation_id, COALESCE(shadow_invocation_id, ''), target_function_id, target_version,
+			primary_status_code, shadow_status_code, primary_duration_ms, shadow_duration_ms,
+			primary_output, shadow_output, COALESCE(shadow_error, ''), match, created_at
+		FROM shadow_results ` + where + `
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := s.db.Query(query, functionID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	results := make([]*domain.ShadowResult, 0)
+	for rows.Next() {
+		res := &domain.ShadowResult{}
+		var primaryOutput, shadowOutput []byte
+		if err := rows.Scan(
+			&res.ID, &res.FunctionID, &res.PrimaryInvocationID, &res.ShadowInvocationID, &res.TargetFunctionID, &res.TargetVersion,
+			&res.PrimaryStatusCode, &res.ShadowStatusCode, &res.PrimaryDurationMs, &res.ShadowDurationMs,
+			&primaryOutput, &shadowOutput, &res.ShadowError, &res.Match, &res.CreatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		res.PrimaryOutput = primaryOutput
+		res.ShadowOutput = shadowOutput
+		results = append(results, res)
+	}
+	return results, total, rows.Err()
+}
+
+// ==================== 预留并发操作 ====================
+
+// ListReservedConcurrency 获取所有设置了预留并发的函数。
+//
+// 返回值:
+//   - map[string]int: 函数 ID 到预留并发数的映射
+//   - error: 查询失败时返回错误
+func (s *PostgresStore) ListReservedConcurrency() (map[string]int, error) {
+	rows, err := s.db.Query(`SELECT id, reserved_concurrency FROM functions WHERE reserved_concurrency > 0`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reservations := make(map[string]int)
+	for rows.Next() {
+		var id string
+		var reserved int
+		if err := rows.Scan(&id, &reserved); err != nil {
+			return nil, err
+		}
+		reservations[id] = reserved
+	}
+	return reservations, rows.Err()
+}
+
+// SumReservedConcurrency 计算除指定函数外所有函数的预留并发总和。
+// 用于在设置某个函数的预留并发前校验总和不超过调度器容量。
+//
+// 参数:
+//   - excludeFunctionID: 排除的函数 ID，为空时统计全部函数
+func (s *PostgresStore) SumReservedConcurrency(excludeFunctionID string) (int, error) {
+	var total int
+	err := s.db.QueryRow(
+		`SELECT COALESCE(SUM(reserved_concurrency), 0) FROM functions WHERE id <> $1`,
+		excludeFunctionID,
+	).Scan(&total)
+	return total, err
+}
+
+// ==================== 常驻预热操作 ====================
+
+// ListKeepWarmTargets 按运行时和内存规格汇总可调用函数的常驻预热实例数。
+// 同一规格的函数共享执行环境池，因此目标数为该规格下所有函数 keep_warm 之和；
+// 使用自定义镜像的函数不使用执行环境池，不计入目标数。
+//
+// 返回值:
+//   - []domain.KeepWarmTarget: 各规格的常驻预热目标
+//   - error: 查询失败时返回错误
+func (s *PostgresStore) ListKeepWarmTargets() ([]domain.KeepWarmTarget, error) {
+	rows, err := s.db.Query(`
+		SELECT runtime, memory_mb, SUM(keep_warm)
+		FROM functions
+		WHERE keep_warm > 0 AND status IN ('active', 'degraded') AND COALESCE(custom_image, '') = ''
+		GROUP BY runtime, memory_mb
+		ORDER BY runtime, memory_mb
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var targets []domain.KeepWarmTarget
+	for rows.Next() {
+		var t domain.KeepWarmTarget
+		if err := rows.Scan(&t.Runtime, &t.MemoryMB, &t.Count); err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+// ListWarmupFunctions 查询配置了常驻预热和预热载荷的可调用函数，用于试执行新建的常驻实例。
+// 常驻实例不挂载共享数据卷和层、不使用自定义镜像，因此这些函数不在结果中。
+//
+// 返回值:
+//   - []*domain.Function: 需要试执行的函数，按运行时、内存规格和名称排序
+//   - error: 查询失败时返回错误
+func (s *PostgresStore) ListWarmupFunctions() ([]*domain.Function, error) {
+	query := `
+		SELECT id, name, description, tags, pinned, runtime, handler, code, "binary", code_hash, memory_mb, timeout_sec, max_concurrency, reserved_concurrency, env_vars, status, status_message, task_id, version, cron_expression, http_path, http_methods, webhook_enabled, webhook_key, last_deployed_at, state_config, rate_limit, keep_warm, empty_response, maintenance_windows, data_volumes, COALESCE(live_slot, ''), COALESCE(init_handler, ''), allowed_environments, COALESCE(version_retention, 0), COALESCE(max_reuse, 0), COALESCE(priority, ''), response_cache, build_env, build_args, warmup_payload, deprecation, COALESCE(stop_grace_period_sec, 0), COALESCE(max_payload_bytes, 0), circuit_breaker, COALESCE(custom_image, ''), custom_command, COALESCE(working_dir, ''), args, created_at, updated_at
+		FROM functions f
+		WHERE keep_warm > 0 AND warmup_payload IS NOT NULL AND status IN ('active', 'degraded')
+		  AND COALESCE(array_length(data_volumes, 1), 0) = 0 AND COALESCE(custom_image, '') = ''
+		  AND NOT EXISTS (SELECT 1 FROM function_layers fl WHERE fl.function_id = f.id)
+		ORDER BY runtime, memory_mb, name
+	`
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var functions []*domain.Function
+	for rows.Next() {
+		fn, err := s.scanFunctionRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		functions = append(functions, fn)
+	}
+	return functions, rows.Err()
+}
@@ -10,9 +10,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
 	"time"
 
 	"github.com/oriys/nimbus/internal/config"
+	"github.com/oriys/nimbus/internal/domain"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -70,15 +73,35 @@ func (s *RedisStore) Close() error {
 	return s.client.Close()
 }
 
+// Ping 检查 Redis 连接是否可用，用于就绪探针。
+//
+// 参数:
+//   - ctx: 上下文，用于超时控制
+//
+// 返回值:
+//   - error: Redis 不可用时返回错误信息
+func (s *RedisStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
 // ==================== VM 池操作相关 ====================
 
 // Redis 键前缀常量定义
 const (
-	vmPoolKeyPrefix    = "vmpool:"         // VM 池键前缀，用于存储预热池和繁忙池的 VM ID 集合
-	vmStateKeyPrefix   = "vm:state:"       // VM 状态键前缀，用于存储单个 VM 的详细状态信息
-	vmLockKeyPrefix    = "vm:lock:"        // VM 锁键前缀，用于实现分布式锁
-	functionCacheKey   = "function:cache:" // 函数缓存键前缀，用于缓存函数代码
-	invocationQueueKey = "invocation:queue" // 函数调用队列键，用于异步调用排队
+	vmPoolKeyPrefix    = "vmpool:"              // VM 池键前缀，用于存储预热池和繁忙池的 VM ID 集合
+	vmStateKeyPrefix   = "vm:state:"            // VM 状态键前缀，用于存储单个 VM 的详细状态信息
+	vmLockKeyPrefix    = "vm:lock:"             // VM 锁键前缀，用于实现分布式锁
+	functionCacheKey   = "function:cache:"      // 函数缓存键前缀，用于缓存函数代码
+	invocationQueueKey = "invocation:queue"     // 函数调用队列键，用于异步调用排队
+	rateLimitKeyPrefix = "ratelimit:"           // 限流令牌桶键前缀，用于存储令牌数和上次补充时间
+	pausedQueuePrefix  = "paused:queue:"        // 暂停队列键前缀，按函数存放暂停期间接受的异步调用 ID
+	killSwitchGlobal   = "killswitch:global"    // 全局紧急停止开关键
+	killSwitchPrefix   = "killswitch:function:" // 函数级紧急停止开关键前缀
+	respCacheKeyPrefix = "respcache:"           // 响应缓存键前缀，按函数、版本和输入摘要存放同步调用的响应
+	leaderKeyPrefix    = "leader:"              // 领导者租约键前缀，按后台任务名称存放当前持有者
+	concurrencyPrefix  = "concurrency:"         // 并发槽位键前缀，按函数存放执行中调用的租约（有序集合，分数为过期时间）
+	circuitStatePrefix = "circuit:state:"       // 熔断状态键前缀，按函数存放熔断状态和熔断时间（哈希）
+	circuitWindowKey   = "circuit:window:"      // 熔断统计窗口键前缀，按函数存放各时间桶的调用数和失败数（哈希）
 )
 
 // VMState 表示虚拟机的状态信息。
@@ -344,6 +367,64 @@ func (s *RedisStore) ReleaseLock(ctx context.Context, key string) error {
 	return s.client.Del(ctx, vmLockKeyPrefix+key).Err()
 }
 
+// ==================== 领导者选举相关 ====================
+
+// acquireLeaseScript 获取或续约领导者租约：键不存在时写入持有者，已由同一持有者持有时续期。
+// KEYS[1]: 租约键；ARGV: 持有者标识、租约时长（毫秒）。返回 1 表示持有租约。
+var acquireLeaseScript = redis.NewScript(`
+local holder = redis.call('GET', KEYS[1])
+if holder == ARGV[1] then
+  redis.call('PEXPIRE', KEYS[1], ARGV[2])
+  return 1
+end
+if holder then
+  return 0
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return 1
+`)
+
+// releaseLeaseScript 仅在租约仍由指定持有者持有时删除租约，避免误删其他实例新获得的租约。
+var releaseLeaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+  return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// AcquireLeadership 获取或续约后台任务的领导者租约。
+// 租约未被持有时获取，已由同一持有者持有时续期，由其他实例持有时返回 false。
+//
+// 参数:
+//   - ctx: 上下文
+//   - name: 后台任务名称
+//   - holder: 持有者标识（实例 ID）
+//   - ttl: 租约时长，持有者在此期间未续约则租约过期，其他实例可接管
+//
+// 返回值:
+//   - bool: 是否持有租约
+//   - error: 操作失败时返回错误信息
+func (s *RedisStore) AcquireLeadership(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	held, err := acquireLeaseScript.Run(ctx, s.client, []string{leaderKeyPrefix + name}, holder, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return held == 1, nil
+}
+
+// ReleaseLeadership 释放后台任务的领导者租约，租约已不属于该持有者时不做任何事。
+//
+// 参数:
+//   - ctx: 上下文
+//   - name: 后台任务名称
+//   - holder: 持有者标识
+//
+// 返回值:
+//   - error: 操作失败时返回错误信息
+func (s *RedisStore) ReleaseLeadership(ctx context.Context, name, holder string) error {
+	return releaseLeaseScript.Run(ctx, s.client, []string{leaderKeyPrefix + name}, holder).Err()
+}
+
 // ==================== 函数缓存相关 ====================
 
 // CacheFunction 缓存函数代码。
@@ -434,3 +515,513 @@ func (s *RedisStore) InvocationQueueLen(ctx context.Context) (int64, error) {
 	// LLEN invocation:queue - 获取列表长度
 	return s.client.LLen(ctx, invocationQueueKey).Result()
 }
+
+// ==================== 暂停队列相关 ====================
+
+// PushPausedInvocation 将调用 ID 追加到函数的暂停队列尾部。
+// 函数暂停期间接受的异步调用存放在此队列中，恢复函数时按入队顺序执行。
+//
+// 参数:
+//   - ctx: 上下文
+//   - functionID: 函数 ID
+//   - invocationID: 调用 ID
+//
+// 返回值:
+//   - error: 操作失败时返回错误信息
+func (s *RedisStore) PushPausedInvocation(ctx context.Context, functionID, invocationID string) error {
+	return s.client.RPush(ctx, pausedQueuePrefix+functionID, invocationID).Err()
+}
+
+// RequeuePausedInvocation 将调用 ID 放回函数暂停队列的头部，用于取出后未能提交的调用。
+//
+// 参数:
+//   - ctx: 上下文
+//   - functionID: 函数 ID
+//   - invocationID: 调用 ID
+//
+// 返回值:
+//   - error: 操作失败时返回错误信息
+func (s *RedisStore) RequeuePausedInvocation(ctx context.Context, functionID, invocationID string) error {
+	return s.client.LPush(ctx, pausedQueuePrefix+functionID, invocationID).Err()
+}
+
+// PopPausedInvocation 从函数暂停队列头部取出一个调用 ID。
+//
+// 参数:
+//   - ctx: 上下文
+//   - functionID: 函数 ID
+//
+// 返回值:
+//   - string: 调用 ID，队列为空时返回空字符串
+//   - error: 操作失败时返回错误信息
+func (s *RedisStore) PopPausedInvocation(ctx context.Context, functionID string) (string, error) {
+	id, err := s.client.LPop(ctx, pausedQueuePrefix+functionID).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return id, err
+}
+
+// PausedQueueLen 获取函数暂停队列的当前长度。
+//
+// 参数:
+//   - ctx: 上下文
+//   - functionID: 函数 ID
+//
+// 返回值:
+//   - int64: 暂停期间积压的异步调用数量
+//   - error: 操作失败时返回错误信息
+func (s *RedisStore) PausedQueueLen(ctx context.Context, functionID string) (int64, error) {
+	return s.client.LLen(ctx, pausedQueuePrefix+functionID).Result()
+}
+
+// ==================== 函数并发限制相关 ====================
+
+// concurrencyAcquireScript 原子地清理过期租约并在未达上限时占用一个并发槽位。
+// KEYS[1]: 并发槽位键；ARGV: 租约 ID、上限、当前时间（毫秒）、租约时长（毫秒）。
+// 返回 {是否占用成功, 占用后（或被拒绝时）执行中的调用数}。
+var concurrencyAcquireScript = redis.NewScript(`
+local limit = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+local count = redis.call('ZCARD', KEYS[1])
+if count >= limit then
+  return {0, count}
+end
+redis.call('ZADD', KEYS[1], now + ttl, ARGV[1])
+if redis.call('PTTL', KEYS[1]) < ttl then
+  redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return {1, count + 1}
+`)
+
+// AcquireConcurrencySlot 为函数的一次调用占用并发槽位，多个网关实例共享同一组槽位。
+// 每个槽位是带过期时间的租约：调用所在实例崩溃、未能释放时，租约过期后槽位自动回收。
+//
+// 参数:
+//   - ctx: 上下文
+//   - functionID: 函数 ID
+//   - leaseID: 租约 ID（调用 ID），释放时使用
+//   - limit: 函数的最大并发数
+//   - ttl: 租约时长，应不短于调用的最长执行时间
+//
+// 返回值:
+//   - bool: 是否占用成功，达到上限时为 false
+//   - int: 执行中的调用数
+//   - error: 操作失败时返回错误信息
+func (s *RedisStore) AcquireConcurrencySlot(ctx context.Context, functionID, leaseID string, limit int, ttl time.Duration) (bool, int, error) {
+	result, err := concurrencyAcquireScript.Run(ctx, s.client, []string{concurrencyPrefix + functionID},
+		leaseID, limit, time.Now().UnixMilli(), ttl.Milliseconds()).Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	if len(result) < 2 {
+		return false, 0, fmt.Errorf("unexpected concurrency script result: %v", result)
+	}
+	acquired, _ := result[0].(int64)
+	count, _ := result[1].(int64)
+	return acquired == 1, int(count), nil
+}
+
+// ReleaseConcurrencySlot 释放调用占用的并发槽位，租约已过期或不存在时不做任何事。
+func (s *RedisStore) ReleaseConcurrencySlot(ctx context.Context, functionID, leaseID string) error {
+	return s.client.ZRem(ctx, concurrencyPrefix+functionID, leaseID).Err()
+}
+
+// CountConcurrencySlots 返回函数当前执行中（租约未过期）的调用数。
+// 只有设置了最大并发数的函数占用槽位，其他函数始终返回 0。
+func (s *RedisStore) CountConcurrencySlots(ctx context.Context, functionID string) (int64, error) {
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	return s.client.ZCount(ctx, concurrencyPrefix+functionID, "("+now, "+inf").Result()
+}
+
+// ==================== 函数熔断相关 ====================
+
+// circuitWindowBuckets 是熔断统计窗口划分的时间桶数，桶宽不小于一秒
+const circuitWindowBuckets = 60
+
+// circuitRecordScript 记录一次调用结果并返回窗口内的调用数和失败数。
+// 函数处于试探状态时，本次调用即为试探调用：清除试探状态并返回 {-1, 0}，不计入窗口。
+// KEYS[1]: 熔断状态键，KEYS[2]: 统计窗口键；ARGV: 当前时间（毫秒）、窗口时长（毫秒）、桶宽（毫秒）、是否失败。
+var circuitRecordScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'state') == 'probing' then
+  redis.call('DEL', KEYS[1])
+  return {-1, 0}
+end
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local width = tonumber(ARGV[3])
+local bucket = math.floor(now / width)
+redis.call('HINCRBY', KEYS[2], bucket .. ':t', 1)
+if ARGV[4] == '1' then
+  redis.call('HINCRBY', KEYS[2], bucket .. ':f', 1)
+end
+redis.call('PEXPIRE', KEYS[2], window + width)
+local oldest = math.floor((now - window) / width)
+local total, failed = 0, 0
+local fields = redis.call('HGETALL', KEYS[2])
+for i = 1, #fields, 2 do
+  local sep = string.find(fields[i], ':', 1, true)
+  if tonumber(string.sub(fields[i], 1, sep - 1)) <= oldest then
+    redis.call('HDEL', KEYS[2], fields[i])
+  elseif string.sub(fields[i], sep + 1) == 't' then
+    total = total + tonumber(fields[i + 1])
+  else
+    failed = failed + tonumber(fields[i + 1])
+  end
+end
+return {total, failed}
+`)
+
+// circuitOpenScript 将函数标记为已熔断并清空统计窗口。
+// 函数在去重时间内已被其他实例熔断时不做任何事并返回 0。
+// KEYS[1]: 熔断状态键，KEYS[2]: 统计窗口键；ARGV: 熔断时间（毫秒）、去重时间（毫秒）。
+var circuitOpenScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+if redis.call('HGET', KEYS[1], 'state') == 'open' then
+  local opened = tonumber(redis.call('HGET', KEYS[1], 'opened_at'))
+  if opened and opened > now - tonumber(ARGV[2]) then
+    return 0
+  end
+end
+redis.call('HSET', KEYS[1], 'state', 'open', 'opened_at', ARGV[1])
+redis.call('DEL', KEYS[2])
+return 1
+`)
+
+// circuitBucketWidth 返回统计窗口的桶宽：窗口划分为 circuitWindowBuckets 个桶，桶宽不小于一秒。
+func circuitBucketWidth(window time.Duration) time.Duration {
+	width := window / circuitWindowBuckets
+	if width < time.Second {
+		width = time.Second
+	}
+	return width
+}
+
+// RecordCircuitResult 将一次已完成调用的结果计入函数的熔断统计窗口，多个网关实例共享同一窗口。
+// 窗口按时间桶聚合，占用内存与调用量无关；函数处于试探状态时本次调用作为试探调用，不计入窗口。
+//
+// 参数:
+//   - ctx: 上下文
+//   - functionID: 函数 ID
+//   - failed: 调用是否失败或超时
+//   - window: 统计窗口时长
+//
+// 返回值:
+//   - int: 窗口内的调用数
+//   - int: 窗口内失败或超时的调用数
+//   - bool: 本次调用是否为试探调用（已清除试探状态），为 true 时前两个返回值无意义
+//   - error: 操作失败时返回错误信息
+func (s *RedisStore) RecordCircuitResult(ctx context.Context, functionID string, failed bool, window time.Duration) (int, int, bool, error) {
+	width := circuitBucketWidth(window)
+	failedArg := 0
+	if failed {
+		failedArg = 1
+	}
+	result, err := circuitRecordScript.Run(ctx, s.client, []string{circuitStatePrefix + functionID, circuitWindowKey + functionID},
+		time.Now().UnixMilli(), window.Milliseconds(), width.Milliseconds(), failedArg).Slice()
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if len(result) < 2 {
+		return 0, 0, false, fmt.Errorf("unexpected circuit record script result: %v", result)
+	}
+	total, _ := result[0].(int64)
+	failedCount, _ := result[1].(int64)
+	if total < 0 {
+		return 0, 0, true, nil
+	}
+	return int(total), int(failedCount), false, nil
+}
+
+// OpenCircuit 将函数标记为已熔断，记录熔断时间并清空统计窗口。
+// 多个实例几乎同时达到熔断条件时只有第一个返回 true，其余实例不再重复下线函数和告警。
+//
+// 参数:
+//   - ctx: 上下文
+//   - functionID: 函数 ID
+//   - openedAt: 熔断时间，冷却时间从此刻开始计算
+//   - dedup: 去重时间，函数在此时间内已被熔断时返回 false
+//
+// 返回值:
+//   - bool: 是否由本次调用完成熔断
+//   - error: 操作失败时返回错误信息
+func (s *RedisStore) OpenCircuit(ctx context.Context, functionID string, openedAt time.Time, dedup time.Duration) (bool, error) {
+	opened, err := circuitOpenScript.Run(ctx, s.client, []string{circuitStatePrefix + functionID, circuitWindowKey + functionID},
+		openedAt.UnixMilli(), dedup.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return opened == 1, nil
+}
+
+// ProbeCircuit 将冷却结束的函数标记为试探状态，任一实例执行的下一次调用即为试探调用。
+func (s *RedisStore) ProbeCircuit(ctx context.Context, functionID string) error {
+	return s.client.HSet(ctx, circuitStatePrefix+functionID, "state", domain.CircuitStateProbing).Err()
+}
+
+// GetCircuitState 获取函数的熔断状态，函数未熔断也不在试探中时返回 nil。
+func (s *RedisStore) GetCircuitState(ctx context.Context, functionID string) (*domain.CircuitState, error) {
+	fields, err := s.client.HGetAll(ctx, circuitStatePrefix+functionID).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	state := &domain.CircuitState{State: fields["state"]}
+	if ms, err := strconv.ParseInt(fields["opened_at"], 10, 64); err == nil {
+		state.OpenedAt = time.UnixMilli(ms)
+	}
+	return state, nil
+}
+
+// ClearCircuit 清除函数的熔断状态和统计窗口，用于函数关闭熔断配置后恢复。
+func (s *RedisStore) ClearCircuit(ctx context.Context, functionID string) error {
+	return s.client.Del(ctx, circuitStatePrefix+functionID, circuitWindowKey+functionID).Err()
+}
+
+// ==================== 调用限流相关 ====================
+
+// tokenBucketScript 原子地补充并消耗令牌桶中的令牌。
+// KEYS[1]: 令牌桶键；ARGV: 速率（每秒）、容量、当前时间（毫秒）。
+// 返回 {是否放行, 剩余令牌数（字符串，保留小数）}。
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local data = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(data[1])
+local ts = tonumber(data[2])
+if tokens == nil or ts == nil then
+  tokens = burst
+  ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) / 1000 * rate)
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('EXPIRE', KEYS[1], math.ceil(burst / rate) + 1)
+return {allowed, tostring(tokens)}
+`)
+
+// TakeRateLimitToken 从指定令牌桶中取出一个令牌。
+// 令牌桶按配置速率持续补充，空闲一个补满周期后键自动过期。
+//
+// 参数:
+//   - ctx: 上下文
+//   - key: 令牌桶标识（如函数 ID，或函数 ID 与调用方的组合）
+//   - cfg: 限流配置
+//
+// 返回值:
+//   - *domain.RateLimitStatus: 本次请求是否放行及令牌桶状态
+//   - error: 操作失败时返回错误信息
+func (s *RedisStore) TakeRateLimitToken(ctx context.Context, key string, cfg *domain.RateLimitConfig) (*domain.RateLimitStatus, error) {
+	result, err := tokenBucketScript.Run(ctx, s.client, []string{rateLimitKeyPrefix + key},
+		cfg.RequestsPerSecond, cfg.Burst, time.Now().UnixMilli()).Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(result) < 2 {
+		return nil, fmt.Errorf("unexpected rate limit script result: %v", result)
+	}
+	allowed, _ := result[0].(int64)
+	tokensStr, _ := result[1].(string)
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token count %q: %w", tokensStr, err)
+	}
+	return newRateLimitStatus(allowed == 1, tokens, cfg), nil
+}
+
+// newRateLimitStatus 根据令牌桶中剩余的令牌数计算限流状态。
+func newRateLimitStatus(allowed bool, tokens float64, cfg *domain.RateLimitConfig) *domain.RateLimitStatus {
+	return &domain.RateLimitStatus{
+		Allowed:   allowed,
+		Limit:     cfg.Burst,
+		Remaining: int(math.Floor(tokens)),
+		ResetSec:  int(math.Ceil((float64(cfg.Burst) - tokens) / cfg.RequestsPerSecond)),
+	}
+}
+
+// PeekRateLimitToken 查询指定令牌桶的当前状态，不消耗令牌也不修改令牌桶。
+// 用于调度预演等只需判断请求是否会被放行的场景。
+//
+// 参数:
+//   - ctx: 上下文
+//   - key: 令牌桶标识
+//   - cfg: 限流配置
+//
+// 返回值:
+//   - *domain.RateLimitStatus: 下一个请求是否会被放行及令牌桶状态
+//   - error: 操作失败时返回错误信息
+func (s *RedisStore) PeekRateLimitToken(ctx context.Context, key string, cfg *domain.RateLimitConfig) (*domain.RateLimitStatus, error) {
+	data, err := s.client.HMGet(ctx, rateLimitKeyPrefix+key, "tokens", "ts").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	// 令牌桶不存在（从未使用或已过期）时视为已补满
+	tokens := float64(cfg.Burst)
+	tokensStr, ok1 := data[0].(string)
+	tsStr, ok2 := data[1].(string)
+	if ok1 && ok2 {
+		stored, err1 := strconv.ParseFloat(tokensStr, 64)
+		ts, err2 := strconv.ParseInt(tsStr, 10, 64)
+		if err1 == nil && err2 == nil {
+			elapsed := math.Max(0, float64(time.Now().UnixMilli()-ts))
+			tokens = math.Min(float64(cfg.Burst), stored+elapsed/1000*cfg.RequestsPerSecond)
+		}
+	}
+	return newRateLimitStatus(tokens >= 1, tokens, cfg), nil
+}
+
+// ==================== 响应缓存相关 ====================
+
+// CacheResponse 缓存一次同步调用的响应。
+//
+// 参数:
+//   - ctx: 上下文
+//   - key: 缓存键（见 domain.ResponseCacheConfig.ResponseCacheKey）
+//   - resp: 调用响应
+//   - ttl: 缓存有效期
+//
+// 返回值:
+//   - error: 操作失败时返回错误信息
+func (s *RedisStore) CacheResponse(ctx context.Context, key string, resp *domain.InvokeResponse, ttl time.Duration) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, respCacheKeyPrefix+key, data, ttl).Err()
+}
+
+// GetCachedResponse 获取缓存的同步调用响应。
+//
+// 参数:
+//   - ctx: 上下文
+//   - key: 缓存键
+//
+// 返回值:
+//   - *domain.InvokeResponse: 缓存的响应，未命中时返回 nil
+//   - error: 操作失败时返回错误信息
+func (s *RedisStore) GetCachedResponse(ctx context.Context, key string) (*domain.InvokeResponse, error) {
+	data, err := s.client.Get(ctx, respCacheKeyPrefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var resp domain.InvokeResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ==================== 紧急停止开关相关 ====================
+
+// killSwitchKey 返回紧急停止开关的键，functionID 为空时返回全局开关键。
+func killSwitchKey(functionID string) string {
+	if functionID == "" {
+		return killSwitchGlobal
+	}
+	return killSwitchPrefix + functionID
+}
+
+// SetKillSwitch 开启紧急停止开关，开关在 ExpiresAt 到达时由 Redis 过期自动解除。
+// 所有网关实例共享该键，开启后立即对所有实例生效。
+//
+// 参数:
+//   - ctx: 上下文
+//   - ks: 开关信息，FunctionID 为空表示全局开关
+//
+// 返回值:
+//   - error: 操作失败时返回错误信息
+func (s *RedisStore) SetKillSwitch(ctx context.Context, ks *domain.KillSwitch) error {
+	data, err := json.Marshal(ks)
+	if err != nil {
+		return err
+	}
+	ttl := time.Until(ks.ExpiresAt)
+	if ttl <= 0 {
+		return fmt.Errorf("kill switch already expired")
+	}
+	return s.client.Set(ctx, killSwitchKey(ks.FunctionID), data, ttl).Err()
+}
+
+// GetKillSwitch 获取紧急停止开关。
+//
+// 参数:
+//   - ctx: 上下文
+//   - functionID: 函数 ID，为空时获取全局开关
+//
+// 返回值:
+//   - *domain.KillSwitch: 开关信息，未开启时返回 nil
+//   - error: 操作失败时返回错误信息
+func (s *RedisStore) GetKillSwitch(ctx context.Context, functionID string) (*domain.KillSwitch, error) {
+	data, err := s.client.Get(ctx, killSwitchKey(functionID)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ks domain.KillSwitch
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, err
+	}
+	return &ks, nil
+}
+
+// ClearKillSwitch 解除紧急停止开关。
+//
+// 参数:
+//   - ctx: 上下文
+//   - functionID: 函数 ID，为空时解除全局开关
+//
+// 返回值:
+//   - bool: 开关此前是否处于开启状态
+//   - error: 操作失败时返回错误信息
+func (s *RedisStore) ClearKillSwitch(ctx context.Context, functionID string) (bool, error) {
+	n, err := s.client.Del(ctx, killSwitchKey(functionID)).Result()
+	return n > 0, err
+}
+
+// CheckKillSwitch 在一次往返中同时检查全局开关和函数级开关，全局开关优先。
+// 调用路径的最开始调用此方法。
+//
+// 参数:
+//   - ctx: 上下文
+//   - functionID: 函数 ID，可以为空（此时只检查全局开关）
+//
+// 返回值:
+//   - *domain.KillSwitch: 拒绝调用的开关，均未开启时返回 nil
+//   - error: 操作失败时返回错误信息
+func (s *RedisStore) CheckKillSwitch(ctx context.Context, functionID string) (*domain.KillSwitch, error) {
+	keys := []string{killSwitchGlobal}
+	if functionID != "" {
+		keys = append(keys, killSwitchPrefix+functionID)
+	}
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range values {
+		raw, ok := v.(string)
+		if !ok {
+			continue
+		}
+		var ks domain.KillSwitch
+		if err := json.Unmarshal([]byte(raw), &ks); err != nil {
+			return nil, err
+		}
+		return &ks, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+func TestCreateInvocationWorkflowLink(t *testing.T) {
+	store, db := newStubStore(func(query string) stubResult { return stubResult{affected: 1} })
+
+	// 工作流任务状态发起的调用记录执行 ID 和状态名称
+	inv := &domain.Invocation{ID: "inv-1", FunctionID: "fn-1", WorkflowExecutionID: "exec-1", WorkflowState: "Charge"}
+	if err := store.CreateInvocation(inv); err != nil {
+		t.Fatalf("CreateInvocation() error = %v", err)
+	}
+	args := db.calls[0].args
+	if args[13] != "exec-1" || args[14] != "Charge" {
+		t.Errorf("workflow args = %v, %v, want exec-1, Charge", args[13], args[14])
+	}
+
+	// 其他调用的执行 ID 写入 NULL，不进入部分索引
+	if err := store.CreateInvocation(&domain.Invocation{ID: "inv-2", FunctionID: "fn-1"}); err != nil {
+		t.Fatalf("CreateInvocation() error = %v", err)
+	}
+	if args := db.calls[1].args; args[13] != nil || args[14] != "" {
+		t.Errorf("workflow args = %v, %v, want NULL and empty state", args[13], args[14])
+	}
+}
+
+func TestListInvocationsByWorkflowExecution(t *testing.T) {
+	created := time.Date(2026, 3, 10, 14, 0, 0, 0, time.UTC)
+	row := func(id, state string, retry int64) []driver.Value {
+		return []driver.Value{
+			id, "fn-1", "charge", "workflow", "success", []byte(`{}`), []byte(`"ok"`), nil,
+			false, nil, nil, nil, int64(10), int64(100),
+			int64(0), retry, created, int64(0), nil, nil, nil, nil, int64(3),
+			"exec-1", state,
+		}
+	}
+	store, db := newStubStore(func(query string) stubResult {
+		return stubResult{rows: [][]driver.Value{row("inv-1", "Charge", 0), row("inv-2", "Charge", 1)}}
+	})
+
+	invocations, err := store.ListInvocationsByWorkflowExecution("exec-1")
+	if err != nil {
+		t.Fatalf("ListInvocationsByWorkflowExecution() error = %v", err)
+	}
+	call := db.calls[0]
+	if !strings.Contains(call.query, "WHERE workflow_execution_id = $1 ORDER BY created_at ASC") || call.args[0] != "exec-1" {
+		t.Errorf("query = %q with args %v, want filter by execution in creation order", call.query, call.args)
+	}
+	// 重试产生的每一次调用都单独返回
+	if len(invocations) != 2 || invocations[1].RetryCount != 1 {
+		t.Fatalf("invocations = %+v, want both attempts", invocations)
+	}
+	if inv := invocations[0]; inv.WorkflowExecutionID != "exec-1" || inv.WorkflowState != "Charge" || inv.Version != 3 || string(inv.Output) != `"ok"` {
+		t.Errorf("invocation = %+v, want workflow link, version and output", inv)
+	}
+
+	// 没有调用时返回空列表而不是 nil
+	store, _ = newStubStore(func(query string) stubResult { return stubResult{} })
+	invocations, err = store.ListInvocationsByWorkflowExecution("exec-2")
+	if err != nil || invocations == nil || len(invocations) != 0 {
+		t.Errorf("empty execution = %v (err %v), want empty list", invocations, err)
+	}
+}
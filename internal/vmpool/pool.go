@@ -14,6 +14,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/oriys/nimbus/internal/config"
+	"github.com/oriys/nimbus/internal/domain"
 	fc "github.com/oriys/nimbus/internal/firecracker"
 	"github.com/oriys/nimbus/internal/metrics"
 	"github.com/oriys/nimbus/internal/storage"
@@ -56,6 +57,7 @@ type RuntimePool struct {
 	warmVMs chan *PooledVM       // 预热虚拟机的缓冲通道
 	mu      sync.Mutex           // 保护 allVMs 的互斥锁
 	allVMs  map[string]*PooledVM // 所有虚拟机的映射（ID -> VM）
+	pinned  int                  // 函数 keep_warm 配置固定常驻的热实例数（受 mu 保护）
 }
 
 // NewPool 创建新的虚拟机池。
@@ -138,6 +140,7 @@ func (p *Pool) metricsWorker() {
 			stats := p.GetStats()
 			for runtime, st := range stats {
 				p.metrics.UpdatePoolStats(runtime, st.WarmVMs, st.BusyVMs, st.TotalVMs)
+				p.metrics.UpdatePoolPinnedWarm(runtime, st.PinnedWarm)
 			}
 		}
 	}
@@ -224,7 +227,10 @@ func (p *Pool) AcquireVM(ctx context.Context, runtime string) (*PooledVM, bool,
 	// 创建新虚拟机（冷启动）
 	pvm, err := p.createVM(ctx, runtime)
 	if err != nil {
-		return nil, false, err
+		if ctx.Err() != nil {
+			return nil, false, err
+		}
+		return nil, false, fmt.Errorf("%w: %v", domain.ErrVMStartFailed, err)
 	}
 
 	pool.mu.Lock()
@@ -423,19 +429,23 @@ func (p *Pool) scalingWorker() {
 }
 
 // checkScaling 检查并执行扩缩容操作。
-// 当预热虚拟机数量低于最小阈值时，创建新的预热虚拟机。
+// 当预热虚拟机数量低于最小阈值（或常驻预热目标）时，创建新的预热虚拟机。
 func (p *Pool) checkScaling() {
 	for runtime, pool := range p.pools {
 		warmCount := len(pool.warmVMs)
 
 		pool.mu.Lock()
 		totalCount := len(pool.allVMs)
+		pinned := pool.pinned
 		pool.mu.Unlock()
 
+		minWarm := max(pool.config.MinWarm, pinned)
+		targetWarm := max(pool.config.TargetWarm, pinned)
+
 		// 如果预热虚拟机不足且未达到上限，则扩容
-		if warmCount < pool.config.MinWarm && totalCount < pool.config.MaxTotal {
+		if warmCount < minWarm && totalCount < pool.config.MaxTotal {
 			// 计算需要创建的数量
-			toCreate := pool.config.TargetWarm - warmCount
+			toCreate := targetWarm - warmCount
 			if toCreate > pool.config.MaxTotal-totalCount {
 				toCreate = pool.config.MaxTotal - totalCount
 			}
@@ -452,6 +462,28 @@ func (p *Pool) checkScaling() {
 	}
 }
 
+// SetKeepWarm 设置各运行时的常驻预热实例数，并立即检查扩容。
+// 虚拟机池按运行时划分，同一运行时不同内存规格的目标数合并计算；
+// 老化的虚拟机由健康检查回收，随后由扩缩容协程补齐。
+//
+// 参数:
+//   - ctx: 上下文（虚拟机创建使用池自身的生命周期）
+//   - targets: 常驻预热目标，未出现的运行时不再固定常驻
+func (p *Pool) SetKeepWarm(ctx context.Context, targets []domain.KeepWarmTarget) {
+	pinned := make(map[string]int)
+	for _, t := range targets {
+		pinned[string(t.Runtime)] += t.Count
+	}
+
+	for runtime, pool := range p.pools {
+		pool.mu.Lock()
+		pool.pinned = pinned[runtime]
+		pool.mu.Unlock()
+	}
+
+	p.checkScaling()
+}
+
 // GetStats 获取所有运行时的池状态统计。
 func (p *Pool) GetStats() map[string]PoolStats {
 	stats := make(map[string]PoolStats)
@@ -468,10 +500,11 @@ func (p *Pool) GetStats() map[string]PoolStats {
 			}
 		}
 		stats[runtime] = PoolStats{
-			WarmVMs:  warmCount,
-			BusyVMs:  busyCount,
-			TotalVMs: len(pool.allVMs),
-			MaxVMs:   pool.config.MaxTotal,
+			WarmVMs:    warmCount,
+			BusyVMs:    busyCount,
+			TotalVMs:   len(pool.allVMs),
+			MaxVMs:     pool.config.MaxTotal,
+			PinnedWarm: pool.pinned,
 		}
 		pool.mu.Unlock()
 	}
@@ -481,10 +514,11 @@ func (p *Pool) GetStats() map[string]PoolStats {
 
 // PoolStats 表示池的状态统计信息。
 type PoolStats struct {
-	WarmVMs  int `json:"warm_vms"`  // 预热虚拟机数量
-	BusyVMs  int `json:"busy_vms"`  // 忙碌虚拟机数量
-	TotalVMs int `json:"total_vms"` // 总虚拟机数量
-	MaxVMs   int `json:"max_vms"`   // 最大虚拟机数量
+	WarmVMs    int `json:"warm_vms"`    // 预热虚拟机数量
+	BusyVMs    int `json:"busy_vms"`    // 忙碌虚拟机数量
+	TotalVMs   int `json:"total_vms"`   // 总虚拟机数量
+	MaxVMs     int `json:"max_vms"`     // 最大虚拟机数量
+	PinnedWarm int `json:"pinned_warm"` // 由函数 keep_warm 固定常驻的热实例数
 }
 
 // IsVMAlive 检查指定 VM 是否存活。
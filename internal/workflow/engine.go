@@ -21,6 +21,12 @@ type Scheduler interface {
 	InvokeAsync(req *domain.InvokeRequest) (string, error)
 }
 
+// InstancePinReleaser 定义了支持实例亲和提示的调度器接口（可选）。
+// 调度器实现该接口时，开启 colocate_tasks 的工作流执行结束后立即归还暂留的实例。
+type InstancePinReleaser interface {
+	ReleaseInstancePin(pin string)
+}
+
 // Config 工作流引擎配置
 type Config struct {
 	// Workers Worker Pool 的工作线程数
@@ -33,6 +39,8 @@ type Config struct {
 	RecoveryEnabled bool
 	// RecoveryInterval 恢复检查间隔
 	RecoveryInterval time.Duration
+	// IsLeader 判断本实例是否负责执行恢复，多副本部署时避免同一执行被多个实例恢复；为 nil 时总是执行
+	IsLeader func() bool
 }
 
 // DefaultConfig 返回默认配置
@@ -182,18 +190,27 @@ func (e *Engine) recoveryLoop() {
 	defer ticker.Stop()
 
 	// 启动时立即检查一次
-	e.recoverPendingExecutions()
+	if e.isRecoveryLeader() {
+		e.recoverPendingExecutions()
+	}
 
 	for {
 		select {
 		case <-e.ctx.Done():
 			return
 		case <-ticker.C:
-			e.recoverPendingExecutions()
+			if e.isRecoveryLeader() {
+				e.recoverPendingExecutions()
+			}
 		}
 	}
 }
 
+// isRecoveryLeader 判断本实例是否负责执行恢复
+func (e *Engine) isRecoveryLeader() bool {
+	return e.config.IsLeader == nil || e.config.IsLeader()
+}
+
 // recoverPendingExecutions 恢复待处理的执行
 func (e *Engine) recoverPendingExecutions() {
 	executions, err := e.store.ListPendingExecutions(100)
@@ -378,6 +395,15 @@ func (e *Engine) executeWorkflowTask(task *executionTask) {
 		}
 	}
 
+	// 开启任务同置时以执行 ID 作为实例亲和提示，执行结束（含暂停）后归还暂留的实例
+	stateCtx := e.ctx
+	if workflow.Definition.ColocateTasks {
+		stateCtx = domain.WithInstancePin(stateCtx, exec.ID)
+		if releaser, ok := e.scheduler.(InstancePinReleaser); ok {
+			defer releaser.ReleaseInstancePin(exec.ID)
+		}
+	}
+
 	// 状态机主循环
 	var currentState string
 	var currentInput json.RawMessage
@@ -435,7 +461,7 @@ func (e *Engine) executeWorkflowTask(task *executionTask) {
 		log.WithField("state", currentState).Debug("Executing state")
 
 		// 执行状态
-		result := e.executor.ExecuteState(e.ctx, exec, currentState, &state, currentInput)
+		result := e.executor.ExecuteState(stateCtx, exec, currentState, &state, currentInput)
 
 		// 处理执行结果
 		if result.Error != nil {
@@ -14,9 +14,15 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// stateRecorder 保存状态执行记录，由 *storage.PostgresStore 实现
+type stateRecorder interface {
+	CreateStateExecution(stateExec *domain.StateExecution) error
+	UpdateStateExecution(stateExec *domain.StateExecution) error
+}
+
 // Executor 状态执行器
 type Executor struct {
-	store     *storage.PostgresStore
+	store     stateRecorder
 	scheduler Scheduler
 	logger    *logrus.Logger
 	evaluator *Evaluator
@@ -74,6 +80,8 @@ func (e *Executor) ExecuteState(ctx context.Context, exec *domain.WorkflowExecut
 		result = e.executeWaitState(ctx, state, processedInput)
 	case domain.StateTypeParallel:
 		result = e.executeParallelState(ctx, exec, stateName, state, processedInput)
+	case domain.StateTypeMap:
+		result = e.executeMapState(ctx, exec, stateName, state, processedInput)
 	case domain.StateTypePass:
 		result = e.executePassState(state, processedInput)
 	case domain.StateTypeFail:
@@ -154,8 +162,11 @@ func (e *Executor) executeTaskState(ctx context.Context, exec *domain.WorkflowEx
 
 		// 调用函数
 		resp, err := e.scheduler.Invoke(&domain.InvokeRequest{
-			FunctionID: state.FunctionID,
-			Payload:    input,
+			FunctionID:          state.FunctionID,
+			Payload:             input,
+			InstancePin:         domain.InstancePinFromContext(ctx),
+			WorkflowExecutionID: exec.ID,
+			WorkflowState:       stateName,
 		})
 
 		if err != nil {
@@ -395,6 +406,184 @@ func (e *Executor) executeParallelState(ctx context.Context, exec *domain.Workfl
 
 // executeBranch 执行并行分支
 func (e *Executor) executeBranch(ctx context.Context, exec *domain.WorkflowExecution, parentState string, branchIndex int, branch *domain.Branch, input json.RawMessage) *domain.ParallelBranchResult {
+	return e.runSubStates(ctx, exec, fmt.Sprintf("%s.Branch[%d]", parentState, branchIndex), branchIndex, branch, input)
+}
+
+// executeMapState 执行 Map 状态
+// 对 ItemsPath 指向的数组中的每个元素运行迭代器子流程，并按原顺序将结果汇总为数组。
+// 单个元素失败时按 Map 状态的 Retry 策略重试该元素，仍失败则取消其余元素，整个状态失败（可被 Catch 捕获）。
+func (e *Executor) executeMapState(ctx context.Context, exec *domain.WorkflowExecution, stateName string, state *domain.State, input json.RawMessage) *domain.StateResult {
+	if state.Iterator == nil {
+		return &domain.StateResult{
+			Error:     fmt.Errorf("map state %s has no iterator", stateName),
+			ErrorCode: "States.InvalidState",
+		}
+	}
+
+	items, err := mapItems(input, state.ItemsPath)
+	if err != nil {
+		return &domain.StateResult{
+			Error:     err,
+			ErrorCode: domain.ErrorTypeItemsPathFailure,
+		}
+	}
+	if len(items) == 0 {
+		return &domain.StateResult{
+			Output:    json.RawMessage("[]"),
+			NextState: e.getNextState(state),
+		}
+	}
+
+	concurrency := state.MaxConcurrency
+	if concurrency <= 0 || concurrency > len(items) {
+		concurrency = len(items)
+	}
+
+	// 任一元素最终失败时取消其余元素
+	mapCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	itemOutputs := make([]json.RawMessage, len(items))
+	var failed *domain.ParallelBranchResult
+	var failOnce sync.Once
+	var wg sync.WaitGroup
+
+	// 通过信号量限制同时处理的元素数量
+	sem := make(chan struct{}, concurrency)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i, item := range items {
+			select {
+			case sem <- struct{}{}:
+			case <-mapCtx.Done():
+			}
+			if mapCtx.Err() != nil {
+				break
+			}
+			wg.Add(1)
+			go func(index int, item json.RawMessage) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				result := e.executeMapItem(mapCtx, exec, stateName, index, state, item)
+				if result.Error != nil {
+					failOnce.Do(func() {
+						failed = result
+						cancel()
+					})
+					return
+				}
+				itemOutputs[index] = result.Output
+			}(i, item)
+		}
+		wg.Wait()
+	}()
+
+	// 等待所有元素完成或上下文取消
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return &domain.StateResult{
+			Error:     ctx.Err(),
+			ErrorCode: domain.ErrorTypeTimeout,
+		}
+	}
+
+	if failed != nil {
+		// 检查是否有 Catch
+		if catchNext := e.findCatch(state.Catch, failed.ErrorCode); catchNext != "" {
+			return &domain.StateResult{
+				Error:         failed.Error,
+				ErrorCode:     failed.ErrorCode,
+				CaughtByState: catchNext,
+			}
+		}
+		return &domain.StateResult{
+			Error:     failed.Error,
+			ErrorCode: failed.ErrorCode,
+		}
+	}
+
+	output, err := json.Marshal(itemOutputs)
+	if err != nil {
+		return &domain.StateResult{
+			Error:     err,
+			ErrorCode: domain.ErrorTypeBranchFailed,
+		}
+	}
+
+	return &domain.StateResult{
+		Output:    output,
+		NextState: e.getNextState(state),
+	}
+}
+
+// executeMapItem 对单个元素执行迭代器子流程，失败时按 Map 状态的 Retry 策略重试
+func (e *Executor) executeMapItem(ctx context.Context, exec *domain.WorkflowExecution, stateName string, index int, state *domain.State, item json.RawMessage) *domain.ParallelBranchResult {
+	prefix := fmt.Sprintf("%s.Item[%d]", stateName, index)
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			interval := e.calculateRetryInterval(state.Retry, attempt)
+			e.logger.WithFields(logrus.Fields{
+				"execution_id": exec.ID,
+				"state":        stateName,
+				"item":         index,
+				"attempt":      attempt,
+				"interval":     interval,
+			}).Info("Retrying map item")
+
+			select {
+			case <-ctx.Done():
+				return &domain.ParallelBranchResult{
+					BranchIndex: index,
+					Error:       ctx.Err(),
+					ErrorCode:   domain.ErrorTypeTimeout,
+				}
+			case <-time.After(interval):
+			}
+		}
+
+		result := e.runSubStates(ctx, exec, prefix, index, state.Iterator, item)
+		if result.Error == nil || !e.shouldRetry(state.Retry, result.ErrorCode, attempt) {
+			return result
+		}
+	}
+}
+
+// mapItems 从输入中取出 Map 状态待迭代的数组
+func mapItems(input json.RawMessage, itemsPath string) ([]json.RawMessage, error) {
+	var data interface{}
+	if err := json.Unmarshal(input, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal input: %w", err)
+	}
+
+	value, err := getJSONPathValue(data, itemsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply ItemsPath: %w", err)
+	}
+	arr, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("ItemsPath %q does not reference an array", itemsPath)
+	}
+
+	items := make([]json.RawMessage, len(arr))
+	for i, v := range arr {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal item %d: %w", i, err)
+		}
+		items[i] = raw
+	}
+	return items, nil
+}
+
+// runSubStates 从子流程的起始状态开始依次执行，直到终止状态或出错。
+// 子状态被 Catch 捕获时，与顶层流程一致地以错误信息作为输入转到捕获状态。
+//
+// 参数:
+//   - prefix: 状态执行记录名称前缀，如 "Parallel.Branch[0]"、"Map.Item[3]"
+//   - index: 分支或元素索引
+func (e *Executor) runSubStates(ctx context.Context, exec *domain.WorkflowExecution, prefix string, index int, branch *domain.Branch, input json.RawMessage) *domain.ParallelBranchResult {
 	currentState := branch.StartAt
 	currentInput := input
 
@@ -402,7 +591,7 @@ func (e *Executor) executeBranch(ctx context.Context, exec *domain.WorkflowExecu
 		// 检查上下文
 		if ctx.Err() != nil {
 			return &domain.ParallelBranchResult{
-				BranchIndex: branchIndex,
+				BranchIndex: index,
 				Error:       ctx.Err(),
 				ErrorCode:   domain.ErrorTypeTimeout,
 			}
@@ -412,18 +601,27 @@ func (e *Executor) executeBranch(ctx context.Context, exec *domain.WorkflowExecu
 		state, ok := branch.States[currentState]
 		if !ok {
 			return &domain.ParallelBranchResult{
-				BranchIndex: branchIndex,
-				Error:       fmt.Errorf("state %s not found in branch %d", currentState, branchIndex),
+				BranchIndex: index,
+				Error:       fmt.Errorf("state %s not found in %s", currentState, prefix),
 				ErrorCode:   "States.InvalidState",
 			}
 		}
 
 		// 执行状态
-		result := e.ExecuteState(ctx, exec, fmt.Sprintf("%s.Branch[%d].%s", parentState, branchIndex, currentState), &state, currentInput)
+		result := e.ExecuteState(ctx, exec, fmt.Sprintf("%s.%s", prefix, currentState), &state, currentInput)
 
 		if result.Error != nil {
+			// 被 Catch 捕获时转到捕获状态继续执行
+			if result.CaughtByState != "" {
+				currentState = result.CaughtByState
+				currentInput, _ = json.Marshal(map[string]interface{}{
+					"Error": result.ErrorCode,
+					"Cause": result.Error.Error(),
+				})
+				continue
+			}
 			return &domain.ParallelBranchResult{
-				BranchIndex: branchIndex,
+				BranchIndex: index,
 				Error:       result.Error,
 				ErrorCode:   result.ErrorCode,
 			}
@@ -432,7 +630,7 @@ func (e *Executor) executeBranch(ctx context.Context, exec *domain.WorkflowExecu
 		// 检查是否为终止状态
 		if result.NextState == "" {
 			return &domain.ParallelBranchResult{
-				BranchIndex: branchIndex,
+				BranchIndex: index,
 				Output:      result.Output,
 			}
 		}
@@ -0,0 +1,194 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/oriys/nimbus/internal/domain"
+)
+
+// fakeStateRecorder 丢弃所有状态执行记录
+type fakeStateRecorder struct{}
+
+func (fakeStateRecorder) CreateStateExecution(*domain.StateExecution) error { return nil }
+func (fakeStateRecorder) UpdateStateExecution(*domain.StateExecution) error { return nil }
+
+// fakeScheduler 将数字载荷乘以 2 返回，载荷等于 failOn 时返回函数错误，并记录调用请求和最大同时调用数
+type fakeScheduler struct {
+	failOn   int
+	delay    time.Duration
+	inflight int32
+	peak     int32
+	calls    int32
+	mu       sync.Mutex
+	reqs     []*domain.InvokeRequest
+}
+
+func (s *fakeScheduler) Invoke(req *domain.InvokeRequest) (*domain.InvokeResponse, error) {
+	atomic.AddInt32(&s.calls, 1)
+	n := atomic.AddInt32(&s.inflight, 1)
+	defer atomic.AddInt32(&s.inflight, -1)
+	s.mu.Lock()
+	if n > s.peak {
+		s.peak = n
+	}
+	s.reqs = append(s.reqs, req)
+	s.mu.Unlock()
+	time.Sleep(s.delay)
+
+	var v int
+	if err := json.Unmarshal(req.Payload, &v); err != nil {
+		return nil, err
+	}
+	if s.failOn != 0 && v == s.failOn {
+		return &domain.InvokeResponse{RequestID: "req", StatusCode: 500, Error: fmt.Sprintf("item %d failed", v)}, nil
+	}
+	body, _ := json.Marshal(v * 2)
+	return &domain.InvokeResponse{RequestID: "req", StatusCode: 200, Body: body}, nil
+}
+
+func (s *fakeScheduler) InvokeAsync(*domain.InvokeRequest) (string, error) { return "", nil }
+
+func newTestExecutor(s Scheduler) *Executor {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	return &Executor{
+		store:     fakeStateRecorder{},
+		scheduler: s,
+		logger:    logger,
+		evaluator: NewEvaluator(),
+		jsonpath:  NewJSONPathProcessor(),
+	}
+}
+
+// doubleMapState 返回对 items_path 数组中每个元素调用 double 函数的 Map 状态
+func doubleMapState(itemsPath string, maxConcurrency int) *domain.State {
+	return &domain.State{
+		Type:           domain.StateTypeMap,
+		ItemsPath:      itemsPath,
+		MaxConcurrency: maxConcurrency,
+		Next:           "Done",
+		Iterator: &domain.Branch{
+			StartAt: "Double",
+			States: map[string]domain.State{
+				"Double": {Type: domain.StateTypeTask, FunctionID: "double", End: true},
+			},
+		},
+	}
+}
+
+func TestExecuteMapStateItemsPath(t *testing.T) {
+	e := newTestExecutor(&fakeScheduler{})
+	exec := &domain.WorkflowExecution{ID: "exec-1"}
+
+	result := e.executeMapState(context.Background(), exec, "Map", doubleMapState("$.items", 0), json.RawMessage(`{"items":[1,2,3],"other":true}`))
+	if result.Error != nil {
+		t.Fatalf("executeMapState() error = %v", result.Error)
+	}
+	if string(result.Output) != `[2,4,6]` {
+		t.Errorf("output = %s, want [2,4,6] in item order", result.Output)
+	}
+	if result.NextState != "Done" {
+		t.Errorf("next state = %q, want Done", result.NextState)
+	}
+
+	// items_path 不指向数组时状态失败
+	result = e.executeMapState(context.Background(), exec, "Map", doubleMapState("$.other", 0), json.RawMessage(`{"items":[1],"other":true}`))
+	if result.Error == nil || result.ErrorCode != domain.ErrorTypeItemsPathFailure {
+		t.Errorf("non-array items_path: error = %v, code = %q, want %q", result.Error, result.ErrorCode, domain.ErrorTypeItemsPathFailure)
+	}
+}
+
+func TestExecuteMapStateMaxConcurrency(t *testing.T) {
+	s := &fakeScheduler{delay: 20 * time.Millisecond}
+	e := newTestExecutor(s)
+
+	result := e.executeMapState(context.Background(), &domain.WorkflowExecution{ID: "exec-1"}, "Map", doubleMapState("$", 2), json.RawMessage(`[1,2,3,4,5,6]`))
+	if result.Error != nil {
+		t.Fatalf("executeMapState() error = %v", result.Error)
+	}
+	if string(result.Output) != `[2,4,6,8,10,12]` {
+		t.Errorf("output = %s, want [2,4,6,8,10,12]", result.Output)
+	}
+	if s.peak > 2 {
+		t.Errorf("peak concurrent items = %d, want at most 2", s.peak)
+	}
+	if s.calls != 6 {
+		t.Errorf("invocations = %d, want 6", s.calls)
+	}
+}
+
+func TestExecuteMapStateItemFailure(t *testing.T) {
+	e := newTestExecutor(&fakeScheduler{failOn: 2})
+	exec := &domain.WorkflowExecution{ID: "exec-1"}
+
+	state := doubleMapState("$", 1)
+	result := e.executeMapState(context.Background(), exec, "Map", state, json.RawMessage(`[1,2,3]`))
+	if result.Error == nil || result.ErrorCode != domain.ErrorTypeTaskFailed {
+		t.Fatalf("error = %v, code = %q, want %q", result.Error, result.ErrorCode, domain.ErrorTypeTaskFailed)
+	}
+	if result.CaughtByState != "" {
+		t.Errorf("caught by = %q, want empty without Catch", result.CaughtByState)
+	}
+
+	// 配置 Catch 时失败被捕获
+	state.Catch = []domain.CatchConfig{{ErrorEquals: []string{"States.ALL"}, Next: "Recover"}}
+	result = e.executeMapState(context.Background(), exec, "Map", state, json.RawMessage(`[1,2,3]`))
+	if result.CaughtByState != "Recover" {
+		t.Errorf("caught by = %q, want Recover", result.CaughtByState)
+	}
+}
+
+func TestExecuteMapStateEmptyArray(t *testing.T) {
+	s := &fakeScheduler{}
+	e := newTestExecutor(s)
+
+	result := e.executeMapState(context.Background(), &domain.WorkflowExecution{ID: "exec-1"}, "Map", doubleMapState("$.items", 0), json.RawMessage(`{"items":[]}`))
+	if result.Error != nil {
+		t.Fatalf("executeMapState() error = %v", result.Error)
+	}
+	if string(result.Output) != `[]` || result.NextState != "Done" {
+		t.Errorf("result = %s -> %q, want [] -> Done", result.Output, result.NextState)
+	}
+	if s.calls != 0 {
+		t.Errorf("invocations = %d, want 0", s.calls)
+	}
+}
+
+func TestExecuteMapItemRetry(t *testing.T) {
+	s := &fakeScheduler{failOn: 7}
+	e := newTestExecutor(s)
+
+	state := doubleMapState("$", 0)
+	state.Retry = &domain.RetryPolicy{ErrorEquals: []string{"States.ALL"}, MaxAttempts: 2, IntervalSeconds: 0}
+	result := e.executeMapItem(context.Background(), &domain.WorkflowExecution{ID: "exec-1"}, "Map", 0, state, json.RawMessage(`7`))
+	if result.Error == nil {
+		t.Fatal("executeMapItem() error = nil, want item failure")
+	}
+	if s.calls != 3 {
+		t.Errorf("invocations = %d, want 3 (1 attempt + 2 retries)", s.calls)
+	}
+}
+
+func TestExecuteTaskStateLinksInvocation(t *testing.T) {
+	s := &fakeScheduler{}
+	e := newTestExecutor(s)
+	exec := &domain.WorkflowExecution{ID: "exec-1"}
+
+	state := &domain.State{Type: domain.StateTypeTask, FunctionID: "double", Next: "Done"}
+	result := e.executeTaskState(context.Background(), exec, "Charge", state, json.RawMessage(`4`), &domain.StateExecution{})
+	if result.Error != nil {
+		t.Fatalf("executeTaskState() error = %v", result.Error)
+	}
+	// 调用请求携带工作流执行 ID 和状态名称，调度器据此记录在调用记录上
+	if len(s.reqs) != 1 || s.reqs[0].WorkflowExecutionID != "exec-1" || s.reqs[0].WorkflowState != "Charge" {
+		t.Errorf("requests = %+v, want one request linked to exec-1/Charge", s.reqs)
+	}
+}
@@ -90,6 +90,16 @@ else
     error "Failed to build nimbus-runtime-nodejs20:latest"
 fi
 
+# Bun runtime
+info "Building nimbus-runtime-bun1:latest..."
+if docker build -t nimbus-runtime-bun1:latest \
+    -f "$RUNTIME_DIR/Dockerfile.bun1" \
+    "$RUNTIME_DIR" > /dev/null 2>&1; then
+    success "nimbus-runtime-bun1:latest"
+else
+    error "Failed to build nimbus-runtime-bun1:latest"
+fi
+
 # Go runtime
 info "Building nimbus-runtime-go1.24:latest..."
 if docker build -t nimbus-runtime-go1.24:latest \
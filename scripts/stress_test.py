@@ -35,7 +35,7 @@ def _xorshift32(x: int) -> int:
     return x & 0xFFFFFFFF
 
 
-def handler(event):
+def handler(event, context):
     # Tunables (kept within sane bounds to avoid OOM/timeouts by default)
     n = int(event.get("n", 2500))
     loops = int(event.get("loops", 15))
@@ -1202,14 +1202,15 @@ export default function FunctionDetail() {
                 fn.status === 'active' ? 'text-green-400 bg-green-400/10' :
                 fn.status === 'offline' ? 'text-gray-400 bg-gray-400/10' :
                 fn.status === 'creating' || fn.status === 'updating' || fn.status === 'building' ? 'text-blue-400 bg-blue-400/10' :
-                fn.status === 'failed' ? 'text-red-400 bg-red-400/10' : 'text-gray-400 bg-gray-400/10'
+                fn.status === 'failed' ? 'text-red-400 bg-red-400/10' :
+                fn.status === 'degraded' ? 'text-orange-400 bg-orange-400/10' : 'text-gray-400 bg-gray-400/10'
               )}>
                 {(fn.status === 'creating' || fn.status === 'updating' || fn.status === 'building') && (
                   <Loader2 className="w-3 h-3 animate-spin" />
                 )}
                 {fn.status === 'active' && <CheckCircle2 className="w-3 h-3" />}
                 {fn.status === 'offline' && <PauseCircle className="w-3 h-3" />}
-                {fn.status === 'failed' && <XCircle className="w-3 h-3" />}
+                {(fn.status === 'failed' || fn.status === 'degraded') && <XCircle className="w-3 h-3" />}
                 {STATUS_LABELS[fn.status as FunctionStatus] || fn.status}
               </span>
             </div>
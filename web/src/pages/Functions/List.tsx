@@ -64,6 +64,7 @@ const StatusBadge = memo(function StatusBadge({ status }: { status: FunctionStat
     inactive: { icon: Clock, className: 'text-yellow-400 bg-yellow-400/10', label: '未激活' },
     building: { icon: Loader2, className: 'text-blue-400 bg-blue-400/10', label: '构建中' },
     failed: { icon: AlertCircle, className: 'text-red-400 bg-red-400/10', label: '失败' },
+    degraded: { icon: AlertCircle, className: 'text-orange-400 bg-orange-400/10', label: '初始化异常' },
   }
   const { icon: Icon, className, label } = config[status] || config.inactive
   const isAnimating = status === 'creating' || status === 'updating' || status === 'building'
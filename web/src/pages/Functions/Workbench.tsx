@@ -40,13 +40,19 @@ import type { WatchExpression, ConsoleEntry } from '../../components/Debug'
 type SidebarTab = 'config' | 'invocations' | 'metrics' | 'debug'
 type BottomTab = 'logs' | 'test' | 'output' | 'debug-console'
 
-// 根据运行时获取文件名
-const getDebugFilePath = (runtime: string): string => {
-  // 这些路径需要匹配 debug_handler.go 中容器内的用户代码文件路径
-  if (runtime.includes('python')) return '/tmp/handler.py'
-  if (runtime.includes('node')) return '/tmp/handler.js'
+// 合法的入口点格式：[目录/]文件.函数名 或 函数名，与 domain.ValidateHandler 一致
+const HANDLER_PATTERN = /^(?:[A-Za-z0-9_-]+(?:\/[A-Za-z0-9_-]+)*\.)?[A-Za-z_$][A-Za-z0-9_$]*$/
+
+// 根据运行时和入口点获取文件名
+const getDebugFilePath = (runtime: string, handler = ''): string => {
+  // 这些路径需要匹配 debug_handler.go 中容器内的用户代码文件路径（入口点中的文件部分，默认 handler）
+  const dot = handler.lastIndexOf('.')
+  const valid = HANDLER_PATTERN.test(handler) && !(runtime.includes('python') && handler.includes('$'))
+  const file = valid && dot > 0 ? handler.slice(0, dot) : 'handler'
+  if (runtime.includes('python')) return `/tmp/${file}.py`
+  if (runtime.includes('node')) return `/tmp/${file}.js`
   if (runtime.includes('go')) return '/tmp/handler.go'
-  return '/tmp/handler.py'
+  return `/tmp/${file}.py`
 }
 
 export default function FunctionWorkbench() {
@@ -270,7 +276,7 @@ export default function FunctionWorkbench() {
     if (!model) return
 
     // 获取当前文件的断点（根据运行时动态确定路径）
-    const filePath = getDebugFilePath(fn.runtime)
+    const filePath = getDebugFilePath(fn.runtime, fn.handler)
     const fileBps = breakpointManager.getBreakpointsByPath(filePath)
 
     // 构建装饰
@@ -366,7 +372,7 @@ export default function FunctionWorkbench() {
       if (e.target.type === monaco.editor.MouseTargetType.GUTTER_GLYPH_MARGIN) {
         const line = e.target.position?.lineNumber
         if (line && fnRef.current) {
-          const filePath = getDebugFilePath(fnRef.current.runtime)
+          const filePath = getDebugFilePath(fnRef.current.runtime, fnRef.current.handler)
           breakpointManager.toggleBreakpoint(filePath, line)
         }
       }
@@ -472,7 +478,7 @@ export default function FunctionWorkbench() {
       addDebugOutput('system', `调试器已初始化 (支持 ${Object.keys(caps).filter(k => (caps as any)[k]).length} 项能力)`)
 
       // 发送断点
-      const filePath = getDebugFilePath(fn.runtime)
+      const filePath = getDebugFilePath(fn.runtime, fn.handler)
       const bps = breakpointManager.toSourceBreakpoints(filePath)
       if (bps.length > 0) {
         const verified = await debugService.setBreakpoints(filePath, bps)
@@ -678,7 +684,7 @@ export default function FunctionWorkbench() {
     breakpointManager.updateBreakpointCondition(id, condition, hitCondition, logMessage)
     // 如果调试会话已连接，重新设置断点
     if (debugService.isConnected() && fn) {
-      const filePath = getDebugFilePath(fn.runtime)
+      const filePath = getDebugFilePath(fn.runtime, fn.handler)
       const bps = breakpointManager.toSourceBreakpoints(filePath)
       debugService.setBreakpoints(filePath, bps).then((verified) => {
         breakpointManager.updateVerificationStatus(filePath, verified)
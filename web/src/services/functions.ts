@@ -103,6 +103,16 @@ export const functionService = {
     return api.post(`/v1/functions/${id}/online`)
   },
 
+  // 暂停函数（异步调用排队，恢复后执行）
+  pause: async (id: string): Promise<Function> => {
+    return api.post(`/v1/functions/${id}/pause`)
+  },
+
+  // 获取暂停队列积压数量
+  pausedBacklog: async (id: string): Promise<{ function_id: string; status: string; backlog: number }> => {
+    return api.get(`/v1/functions/${id}/paused-backlog`)
+  },
+
   // 置顶/取消置顶函数
   pin: async (id: string): Promise<Function> => {
     return api.post(`/v1/functions/${id}/pin`)
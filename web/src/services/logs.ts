@@ -11,6 +11,7 @@ export interface LogEntry {
   output?: unknown
   error?: string
   duration_ms?: number
+  source?: 'api' | 'route' | 'webhook' | string
 }
 
 export interface ListLogsParams {
@@ -20,6 +21,7 @@ export interface ListLogsParams {
   function_name?: string
   request_id?: string
   level?: string
+  source?: string
   before?: string
   after?: string
 }
@@ -2,7 +2,7 @@
 
 export type Runtime = 'python3.11' | 'nodejs20' | 'go1.24' | 'wasm' | 'rust1.75'
 
-export type FunctionStatus = 'creating' | 'active' | 'updating' | 'offline' | 'inactive' | 'building' | 'failed'
+export type FunctionStatus = 'creating' | 'active' | 'updating' | 'offline' | 'inactive' | 'building' | 'failed' | 'degraded' | 'paused'
 
 export type FunctionTaskType = 'create' | 'update'
 export type FunctionTaskStatus = 'pending' | 'running' | 'completed' | 'failed'
@@ -33,6 +33,11 @@ export interface Function {
   memory_mb: number
   timeout_sec: number
   max_concurrency?: number  // 最大并发数 (0 表示无限制)
+  reserved_concurrency?: number  // 预留并发数 (0 表示不预留)
+  keep_warm?: number  // 常驻预热实例数 (0 表示不常驻)
+  empty_response?: string  // 无输出时的默认响应体 (JSON 文本，"none" 表示空响应)
+  rate_limit?: RateLimitConfig  // 调用限流配置
+  maintenance_windows?: MaintenanceWindow[]  // 维护窗口
   env_vars?: Record<string, string>
   status: FunctionStatus
   status_message?: string
@@ -53,6 +58,18 @@ export interface Function {
   updated_at: string
 }
 
+export interface RateLimitConfig {
+  requests_per_second: number  // 令牌补充速率，更新时为 0 表示取消限流
+  burst?: number  // 令牌桶容量
+  per_key?: boolean  // 是否按调用方分别计数
+}
+
+export interface MaintenanceWindow {
+  schedule: string  // 窗口开始时间的 cron 表达式 (6 字段，包含秒)
+  duration_sec: number  // 窗口持续时间 (秒)
+  reason?: string  // 维护原因
+}
+
 export interface CreateFunctionRequest {
   name: string
   tags?: string[]  // 函数标签
@@ -63,6 +80,11 @@ export interface CreateFunctionRequest {
   memory_mb?: number
   timeout_sec?: number
   max_concurrency?: number  // 最大并发数 (0 表示无限制)
+  reserved_concurrency?: number  // 预留并发数 (0 表示不预留)
+  keep_warm?: number  // 常驻预热实例数 (0 表示不常驻)
+  empty_response?: string  // 无输出时的默认响应体 (JSON 文本，"none" 表示空响应)
+  rate_limit?: RateLimitConfig  // 调用限流配置
+  maintenance_windows?: MaintenanceWindow[]  // 维护窗口
   env_vars?: Record<string, string>
   cron_expression?: string
   http_path?: string
@@ -76,6 +98,11 @@ export interface UpdateFunctionRequest {
   memory_mb?: number
   timeout_sec?: number
   max_concurrency?: number  // 最大并发数 (0 表示无限制)
+  reserved_concurrency?: number  // 预留并发数 (0 表示不预留)
+  keep_warm?: number  // 常驻预热实例数 (0 表示不常驻)
+  empty_response?: string  // 无输出时的默认响应体 (JSON 文本，"none" 表示空响应)
+  rate_limit?: RateLimitConfig  // 调用限流配置
+  maintenance_windows?: MaintenanceWindow[]  // 维护窗口
   env_vars?: Record<string, string>
   cron_expression?: string
   http_path?: string
@@ -106,6 +133,8 @@ export const STATUS_COLORS: Record<FunctionStatus, string> = {
   'inactive': 'bg-gray-100 text-gray-800',
   'building': 'bg-yellow-100 text-yellow-800',
   'failed': 'bg-red-100 text-red-800',
+  'degraded': 'bg-orange-100 text-orange-800',
+  'paused': 'bg-yellow-100 text-yellow-800',
 }
 
 export const STATUS_LABELS: Record<FunctionStatus, string> = {
@@ -116,6 +145,8 @@ export const STATUS_LABELS: Record<FunctionStatus, string> = {
   'inactive': '未激活',
   'building': '构建中',
   'failed': '失败',
+  'degraded': '初始化异常',
+  'paused': '已暂停',
 }
 
 export const TASK_STATUS_COLORS: Record<FunctionTaskStatus, string> = {
@@ -1,6 +1,6 @@
 // 调用相关类型定义
 
-export type InvocationStatus = 'pending' | 'running' | 'success' | 'failed' | 'timeout' | 'cancelled'
+export type InvocationStatus = 'pending' | 'running' | 'success' | 'failed' | 'timeout' | 'cancelled' | 'skipped'
 
 export interface Invocation {
   id: string
@@ -11,13 +11,22 @@ export interface Invocation {
   output?: Record<string, unknown> | null
   error?: string
   duration_ms: number
+  queue_wait_ms?: number
   billed_time_ms: number
   cold_start: boolean
+  progress?: InvocationProgress | null
   started_at?: string
   completed_at?: string
   created_at: string
 }
 
+export interface InvocationProgress {
+  percent: number
+  message?: string
+  data?: unknown
+  updated_at: string
+}
+
 export interface InvokeRequest {
   payload: unknown
 }
@@ -27,9 +36,18 @@ export interface InvokeResponse {
   status_code: number
   body?: unknown
   error?: string
+  error_type?: string
   duration_ms: number
+  queue_wait_ms?: number
   cold_start: boolean
   billed_time_ms: number
+  directives?: ResponseDirectives  // 函数通过 X-Nimbus-* 响应头下发的平台指令
+}
+
+export interface ResponseDirectives {
+  cache_control?: string
+  no_retry?: boolean
+  skip_dlq?: boolean
 }
 
 export interface InvokeAsyncResponse {
@@ -44,4 +62,5 @@ export const INVOCATION_STATUS_COLORS: Record<InvocationStatus, string> = {
   'failed': 'bg-red-100 text-red-800',
   'timeout': 'bg-orange-100 text-orange-800',
   'cancelled': 'bg-gray-100 text-gray-800',
+  'skipped': 'bg-yellow-100 text-yellow-800',
 }
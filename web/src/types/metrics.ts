@@ -26,6 +26,7 @@ export interface PoolStats {
   busy_vms: number
   total_vms: number
   max_vms: number
+  pinned_warm?: number  // 由函数 keep_warm 固定常驻的热实例数
 }
 
 export interface SystemStatus {
@@ -10,7 +10,7 @@ export type ExecutionStatus = 'pending' | 'running' | 'succeeded' | 'failed' | '
 export type StateExecutionStatus = 'pending' | 'running' | 'succeeded' | 'failed' | 'skipped'
 
 // 状态类型
-export type StateType = 'Task' | 'Choice' | 'Wait' | 'Parallel' | 'Pass' | 'Fail' | 'Succeed'
+export type StateType = 'Task' | 'Choice' | 'Wait' | 'Parallel' | 'Map' | 'Pass' | 'Fail' | 'Succeed'
 
 // 重试策略
 export interface RetryPolicy {
@@ -75,7 +75,7 @@ export interface ChoiceRule {
   next: string
 }
 
-// 分支 (Parallel 用)
+// 分支 (Parallel 分支 / Map 迭代器用)
 export interface Branch {
   start_at: string
   states: Record<string, State>
@@ -103,6 +103,10 @@ export interface State {
   timestamp_path?: string
   // Parallel 字段
   branches?: Branch[]
+  // Map 字段
+  items_path?: string
+  iterator?: Branch
+  max_concurrency?: number
   // Pass/Fail 字段
   result?: unknown
   result_path?: string
@@ -230,6 +234,7 @@ export const STATE_TYPE_COLORS: Record<StateType, string> = {
   'Choice': 'bg-yellow-500',
   'Wait': 'bg-purple-500',
   'Parallel': 'bg-green-500',
+  'Map': 'bg-teal-500',
   'Pass': 'bg-gray-500',
   'Fail': 'bg-red-500',
   'Succeed': 'bg-emerald-500',
@@ -241,6 +246,7 @@ export const STATE_TYPE_LABELS: Record<StateType, string> = {
   'Choice': '条件',
   'Wait': '等待',
   'Parallel': '并行',
+  'Map': '映射',
   'Pass': '透传',
   'Fail': '失败',
   'Succeed': '成功',